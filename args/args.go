@@ -21,6 +21,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloudwego/thriftgo/version"
@@ -28,7 +29,9 @@ import (
 	"github.com/cloudwego/thriftgo/generator"
 	"github.com/cloudwego/thriftgo/generator/backend"
 	"github.com/cloudwego/thriftgo/generator/golang"
+	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
+	"github.com/cloudwego/thriftgo/semantic"
 )
 
 // StringSlice implements the flag.Value interface on string slices
@@ -45,19 +48,75 @@ func (ss *StringSlice) Set(value string) error {
 	return nil
 }
 
+// StatsFormat is the format of --stats, which is "text" when the flag is given
+// without a value and empty when it is not given.
+type StatsFormat string
+
+// String implements the flag.Value interface.
+func (f *StatsFormat) String() string {
+	return string(*f)
+}
+
+// Set implements the flag.Value interface.
+func (f *StatsFormat) Set(value string) error {
+	switch value {
+	case "true", "text":
+		*f = "text"
+	case "json":
+		*f = "json"
+	case "false":
+		*f = ""
+	default:
+		return fmt.Errorf("expect text or json, got %q", value)
+	}
+	return nil
+}
+
+// IsBoolFlag allows --stats to be given without a value.
+func (f *StatsFormat) IsBoolFlag() bool {
+	return true
+}
+
 // Arguments contains command line arguments for thriftgo.
 type Arguments struct {
-	AskVersion      bool
-	Recursive       bool
-	Verbose         bool
-	Quiet           bool
-	CheckKeyword    bool
-	OutputPath      string
-	Includes        StringSlice
-	Plugins         StringSlice
-	Langs           StringSlice
-	IDL             string
-	PluginTimeLimit time.Duration
+	AskVersion            bool
+	Recursive             bool
+	Verbose               bool
+	Quiet                 bool
+	CheckKeyword          bool
+	WarnFieldIDGaps       bool
+	StrictExceptionFields bool
+	ImplicitFieldIDs      semantic.CheckLevel
+	Dialect               parser.Dialect
+	TranslateSenums       bool
+	InheritTypedefAnnos   bool
+	StrictIncludePaths    bool
+	Timing                bool
+	OutputPath            string
+	OutputTemplate        string
+	Includes              StringSlice
+	Excludes              StringSlice
+	Plugins               StringSlice
+	Langs                 StringSlice
+	IDL                   string
+	Merge                 bool
+	IDLs                  []string // the IDLs merged by --merge, the first of which is IDL
+	PluginTimeLimit       time.Duration
+	IDLCacheDir           string
+	FileHeader            string
+	PostFormat            string
+	NoFormat              bool
+	NamespaceFallback     string
+	ReservedNames         string
+	WarningsAsErrors      bool
+	Verify                bool
+	Clean                 bool
+	DryRun                bool
+	ListBackends          string
+	Stats                 StatsFormat
+	Config                string
+
+	warnings int32 // number of warnings logged by the functions from MakeLogFunc
 }
 
 // Output returns an output path for generated codes for the target language.
@@ -100,6 +159,20 @@ func (a *Arguments) Targets() (specs []*generator.LangSpec, err error) {
 		if err != nil {
 			return nil, err
 		}
+		if a.FileHeader != "" && desc.Name == "go" {
+			desc.Options = append(desc.Options, plugin.Option{Name: "file_header", Desc: a.FileHeader})
+		}
+		if a.NamespaceFallback != "" && desc.Name == "go" {
+			desc.Options = append(desc.Options, plugin.Option{Name: "namespace_fallback", Desc: a.NamespaceFallback})
+		}
+		if a.ReservedNames != "" && desc.Name == "go" {
+			desc.Options = append(desc.Options, plugin.Option{Name: "reserved_names", Desc: a.ReservedNames})
+		}
+		if desc.Name == "go" {
+			for _, pattern := range a.Excludes {
+				desc.Options = append(desc.Options, plugin.Option{Name: "exclude", Desc: pattern})
+			}
+		}
 		opts, err := a.checkOptions(desc.Options)
 		if err != nil {
 			return nil, err
@@ -126,14 +199,14 @@ func (a *Arguments) checkOptions(opts []plugin.Option) ([]plugin.Option, error)
 			found := false
 			for _, opt := range opts {
 				if opt.Name == "template" {
-					log.Printf("[WARN] EnableNestedStruct is only available under the \"slim\" and \"raw_struct\" template, so adapt the template to \"slim\"")
+					a.MakeLogFunc().Warn("EnableNestedStruct is only available under the \"slim\" and \"raw_struct\" template, so adapt the template to \"slim\"")
 					opt.Desc = "slim"
 					found = true
 					break
 				}
 			}
 			if !found {
-				log.Printf("[WARN] EnableNestedStruct is only available under the \"slim\" and \"raw_struct\"  template, so adapt the template to \"slim\"")
+				a.MakeLogFunc().Warn("EnableNestedStruct is only available under the \"slim\" and \"raw_struct\"  template, so adapt the template to \"slim\"")
 				opts = append(opts, plugin.Option{Name: "template", Desc: "slim"})
 			}
 
@@ -143,8 +216,15 @@ func (a *Arguments) checkOptions(opts []plugin.Option) ([]plugin.Option, error)
 }
 
 // MakeLogFunc creates logging functions according to command line flags.
+// The warnings are counted even if they are suppressed by -q.
 func (a *Arguments) MakeLogFunc() backend.LogFunc {
 	logs := backend.DummyLogFunc()
+	logs.Warn = func(v ...interface{}) {
+		atomic.AddInt32(&a.warnings, 1)
+	}
+	logs.MultiWarn = func(ws []string) {
+		atomic.AddInt32(&a.warnings, int32(len(ws)))
+	}
 
 	if !a.Quiet {
 		if a.Verbose {
@@ -156,9 +236,11 @@ func (a *Arguments) MakeLogFunc() backend.LogFunc {
 
 		logger := log.New(os.Stderr, "[WARN] ", 0)
 		logs.Warn = func(v ...interface{}) {
+			atomic.AddInt32(&a.warnings, 1)
 			logger.Println(v...)
 		}
 		logs.MultiWarn = func(ws []string) {
+			atomic.AddInt32(&a.warnings, int32(len(ws)))
 			for _, w := range ws {
 				logger.Println(w)
 			}
@@ -168,6 +250,15 @@ func (a *Arguments) MakeLogFunc() backend.LogFunc {
 	return logs
 }
 
+// CheckWarnings returns an error if --warnings-as-errors is set and any warning
+// has been logged by the functions from MakeLogFunc.
+func (a *Arguments) CheckWarnings() error {
+	if n := atomic.LoadInt32(&a.warnings); a.WarningsAsErrors && n > 0 {
+		return fmt.Errorf("%d warning(s) treated as errors by --warnings-as-errors", n)
+	}
+	return nil
+}
+
 // BuildFlags initializes command line flags.
 func (a *Arguments) BuildFlags() *flag.FlagSet {
 	f := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
@@ -185,10 +276,13 @@ func (a *Arguments) BuildFlags() *flag.FlagSet {
 
 	f.StringVar(&a.OutputPath, "o", "", "")
 	f.StringVar(&a.OutputPath, "out", "", "")
+	f.StringVar(&a.OutputTemplate, "out-template", "", "")
 
 	f.Var(&a.Includes, "i", "")
 	f.Var(&a.Includes, "include", "")
 
+	f.Var(&a.Excludes, "exclude", "")
+
 	f.Var(&a.Langs, "g", "")
 	f.Var(&a.Langs, "gen", "")
 
@@ -197,8 +291,43 @@ func (a *Arguments) BuildFlags() *flag.FlagSet {
 
 	f.BoolVar(&a.CheckKeyword, "check-keywords", true, "")
 
+	f.BoolVar(&a.WarnFieldIDGaps, "warn-field-id-gaps", false, "")
+
+	f.BoolVar(&a.StrictExceptionFields, "strict-exception-fields", false, "")
+	f.Var(&a.ImplicitFieldIDs, "implicit-field-ids", "")
+	f.Var(&a.Dialect, "dialect", "")
+	f.BoolVar(&a.TranslateSenums, "translate-senums", false, "")
+	f.BoolVar(&a.InheritTypedefAnnos, "inherit-typedef-annotations", false, "")
+	f.BoolVar(&a.StrictIncludePaths, "strict-include-paths", false, "")
+
+	f.BoolVar(&a.WarningsAsErrors, "warnings-as-errors", false, "")
+	f.BoolVar(&a.WarningsAsErrors, "Werror", false, "")
+
+	f.BoolVar(&a.Timing, "timing", false, "")
+
 	f.DurationVar(&a.PluginTimeLimit, "plugin-time-limit", time.Minute, "")
 
+	f.StringVar(&a.IDLCacheDir, "idl-cache-dir", "", "")
+
+	f.StringVar(&a.FileHeader, "file-header", "", "")
+
+	f.StringVar(&a.PostFormat, "post-format", "", "")
+	f.BoolVar(&a.NoFormat, "no-format", false, "")
+
+	f.StringVar(&a.NamespaceFallback, "namespace-fallback", "", "")
+	f.StringVar(&a.ReservedNames, "reserved-names", "", "")
+
+	f.BoolVar(&a.Merge, "merge", false, "")
+
+	f.BoolVar(&a.Verify, "verify", false, "")
+	f.BoolVar(&a.Clean, "clean", false, "")
+	f.BoolVar(&a.DryRun, "dry-run", false, "")
+
+	f.StringVar(&a.ListBackends, "list-backends", "", "")
+	f.Var(&a.Stats, "stats", "")
+
+	f.StringVar(&a.Config, "config", "", "")
+
 	f.Usage = help
 	return f
 }
@@ -210,29 +339,78 @@ func (a *Arguments) Parse(argv []string) error {
 		return err
 	}
 
-	if a.AskVersion {
+	if a.AskVersion || a.ListBackends != "" {
 		return nil
 	}
 
 	rest := f.Args()
-	if len(rest) != 1 {
+	if len(rest) > 1 && !a.Merge || len(rest) == 0 && a.Config == "" {
 		return fmt.Errorf("require exactly 1 argument for the IDL parameter, got: %d", len(rest))
 	}
+	if len(rest) > 0 {
+		a.IDL = rest[0]
+	}
 
-	a.IDL = rest[0]
+	if a.Config != "" {
+		if err := a.loadConfig(f); err != nil {
+			return err
+		}
+		if a.IDL == "" {
+			return fmt.Errorf("require the IDL parameter on the command line or as %q in config %s", configIDL, a.Config)
+		}
+	}
+	if a.Merge {
+		a.IDLs = append([]string{a.IDL}, rest[1:]...)
+	}
+
+	if a.OutputTemplate != "" {
+		if strings.Contains(a.OutputPath, "{namespace") {
+			return fmt.Errorf("--out-template can not be used with {namespace} or {namespaceUnderscore} in --out")
+		}
+		if _, err := backend.ParseOutputTemplate(a.OutputTemplate); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func help() {
 	println("Version:", version.ThriftgoVersion)
 	println(`Usage: thriftgo [options] file
+       thriftgo [options] --merge file...
 Options:
   --version           Print the compiler version and exit.
+  --list-backends fmt Print the backends with their options and exit. The only format is 'json',
+                      which prints a list of objects with the name, lang and options of each
+                      backend. An option has a name, desc, type ('bool', 'string' or unknown when
+                      omitted) and whether it is boolean.
   -h, --help          Print help message and exit.
-  -i, --include dir   Add a search path for includes.
+  --config file       Read the flags from a YAML or JSON file that maps the long names of flags,
+                      e.g. 'out' and 'include', to their values, with a list of strings for the
+                      flags that can be repeated and 'idl' for the IDL. Relative paths are resolved
+                      against the working directory. A flag given on the command line replaces the
+                      value in the file, including the lists. Unknown keys are errors.
+  -i, --include dir   Add a search path for includes. An include is searched in the directory of
+                      the IDL including it, then in the search paths in the order they are given,
+                      and at last in the working directory. The first match is used, and the
+                      places tried are logged with -v.
+  --strict-include-paths
+                      Report an error when an include is found at more than one place in the
+                      search order instead of using the first one.
   -o, --out dir	      Set the output location for generated files. Default path is ./gen-*, the code will be genereated at ./gen-*/xxxnamespace.
 					  If you don't want the path ends with namespace, you can use {namespace} or {namespaceUnderscore}, such as /gen-*/{namespace}/data
-  -r, --recurse       Generate codes for includes recursively.
+  --out-template tpl  Compute the output directory of the files generated for each IDL under the
+                      output location with a go template, e.g. '{{.Dir}}/gen/{{.Namespace}}'. The
+                      fields are .Dir, .DirBase, .FileBase and .FileName of the IDL, .Namespace with
+                      '/' as the separator and .Lang. A directory outside the output location is an
+                      error. It replaces the namespace path appended to the output location.
+  -r, --recurse       Generate codes for includes recursively. Without it, only the given IDL is
+                      generated and the packages of its includes are imported as if they were
+                      generated separately with the same options.
+  --exclude glob      Skip generating codes for the includes whose resolved paths match the glob
+                      in recursive mode. They are still used to resolve types. '*' does not match
+                      '/' while '**' matches any number of directories, e.g. 'vendor/**'.
+                      Can be set multiple times. Same as the 'exclude' option of the go backend.
   -v, --verbose       Output detail logs.
   -q, --quiet         Suppress all warnings and informatic logs.
   -g, --gen STR       Specify the target language.
@@ -244,15 +422,85 @@ Options:
   -p, --plugin STR    Specify an external plugin to invoke.
                       STR has the form plugin[=path][:key1=val1[,key2[,key3=val3]]].
   --check-keywords    Check if any identifier using a keyword in common languages. 
+  --warn-field-id-gaps
+                      Warn if the field IDs of a struct, union or exception do not start at 1
+                      or are not contiguous.
+  --strict-exception-fields
+                      Report an error instead of a warning when an exception, or a typedef of it,
+                      is used as the type of a field in a struct or a union.
+  --implicit-field-ids level
+                      Report the fields of structs, unions and exceptions without explicit field
+                      IDs, whose IDs change when the fields before them are reordered. The level is
+                      'off' (default), 'warn' or 'error'.
+  --dialect name      Parse the IDLs in the dialect 'apache' (default) or 'fb'. The constructs of
+                      the other dialect are errors: the senums of apache, and the stream<T> and
+                      sink<T, R> responses and the structured annotations of fb.
+  --translate-senums  Translate the deprecated senums into string typedefs and constants
+                      instead of reporting them as errors.
+  --inherit-typedef-annotations
+                      Merge the annotations of the typedefs into the fields that use them. The
+                      annotations of a field override the ones of its typedefs with the same
+                      keys, and a typedef overrides the ones of the typedef it refers to.
+  --warnings-as-errors, -Werror
+                      Exit with an error after generating codes if any warning was reported,
+                      even if the warnings are suppressed by -q.
+  --timing            Print the time spent in each phase (parse, semantic, codegen, plugins
+                      and write) to stderr when finished. Suppressed by -q.
   --plugin-time-limit Set the execution time limit for plugins. Naturally 0 means no limit.
-
-Available generators (and options): go
+  --idl-cache-dir dir Set the cache location for URL-form includes (e.g. include "https://...").
+                      Default path is thriftgo/idl under the user cache directory.
+  --file-header path  Prepend the rendered template in the file to every generated Go file.
+                      The template can refer to .IDL, .Version and .Date. The date is taken from
+                      SOURCE_DATE_EPOCH when it is set, to make the output reproducible.
+                      Same as the 'file_header' option of the go backend.
+  --post-format cmd   Pipe every generated file through the command before writing it, e.g.
+                      'gofumpt'. The command reads the file from stdin and writes the result to
+                      stdout. It is split by spaces and not run in a shell. If it fails, a warning
+                      with its stderr is printed and the file is written unformatted.
+  --no-format         Write the generated files as they are, without the formatting of the backend
+                      or --post-format, to see the output of a broken template. A warning is printed
+                      for each file that the backend fails to format. For the go backend it
+                      behaves like 'go:no_fmt' except for the warnings and --post-format.
+  --namespace-fallback tpl
+                      Compute the go namespace of the IDLs without 'namespace go' with the template,
+                      e.g. 'acme.{{.FileBase}}'. The template can refer to .FileBase, .FileName, .Dir
+                      and .DirBase of the IDL. Same as the 'namespace_fallback' option of the go backend.
+  --reserved-names names
+                      Escape the go names derived from the IDL that are in the comma separated list,
+                      e.g. 'ID,Table,Column', with a suffix, '_' by default. Same as the
+                      'reserved_names' option of the go backend, see also 'reserved_suffix'.
+  --merge             Merge the IDLs given on the command line into one before generating codes,
+                      as if their definitions were written in the first one, so that they are
+                      generated as one package and refer to each other without includes. It is an
+                      error if a name is defined in more than one of them, if they declare different
+                      namespaces for a language or if one of them is included by the others.
+  --verify            Do not write the generated files. Instead, check the checksums of the files
+                      on the disk that would be written, see the 'gen_checksum' and 'gen_idl_checksum'
+                      options of the go backend, and exit with an error listing those that have been
+                      edited by hand or are stale since their IDL has changed.
+  --stats[=json]      Print the number of typedefs, constants, enums, enum values, structs, unions,
+                      exceptions, fields, services and methods in the IDL, and in each of its
+                      includes with -r, sorted by file, with the total, and exit without generating
+                      codes. The format is 'text' (default) or 'json'.
+  --clean             Remove the files generated by thriftgo for the IDL that are no longer generated
+                      from the directories that the generated files are written to. The generated
+                      files record the IDL in a 'Generated from:' line below their 'Code generated by
+                      thriftgo' header, and only the files with the line for the same IDL are removed,
+                      so the files generated for other IDLs or without --clean are kept, and listed
+                      in a notice if they have no such line. With --verify, exit with an error listing
+                      the files to remove instead.
+  --dry-run           Do not write or remove any file. Instead, print the files that would be written
+                      and, with --clean, removed.
+
+Available generators (and options): go, idl
 `)
 	// print backend options
 	b := new(golang.GoBackend)
 	name, lang := b.Name(), b.Lang()
 	println(fmt.Sprintf("  %s (%s):", name, lang))
 	println(align(b.Options()))
+	println("  idl (Thrift): re-emits the IDL as canonical thrift source. The comments that do not belong to a")
+	println("               definition, a field, an enum value or a function are dropped.")
 
 }
 
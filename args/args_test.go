@@ -15,9 +15,11 @@
 package args
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
 )
 
 func TestArgs(t *testing.T) {
@@ -177,6 +179,25 @@ func TestArgs(t *testing.T) {
 			test.Assert(t, a.Plugins.String() == "[a b]")
 		}
 	})
+	t.Run("out-template", func(t *testing.T) {
+		{
+			var a Arguments
+			err := a.Parse([]string{"bin", "--out-template", "{{.Dir}}/gen/{{.Namespace}}", "idl-path"})
+			test.Assert(t, err == nil, err)
+			test.Assert(t, a.OutputTemplate == "{{.Dir}}/gen/{{.Namespace}}")
+		}
+		for _, args := range [][]string{
+			{"--out-template", "{{.Dir"},
+			{"--out-template", "{{.Unknown}}"},
+			{"--out-template", "../{{.Namespace}}"},
+			{"--out-template", "/{{.Namespace}}"},
+			{"--out-template", "{{.Namespace}}", "--out", "gen/{namespace}"},
+		} {
+			var a Arguments
+			err := a.Parse(append(append([]string{"bin"}, args...), "idl-path"))
+			test.Assert(t, err != nil, args)
+		}
+	})
 	t.Run("all", func(t *testing.T) {
 		var a Arguments
 		err := a.Parse([]string{"bin", "--recurse", "--g", "a", "--g", "b", "--out", "./out", "--include", "a", "--include", "b", "--verbose", "--plugin", "a", "--plugin", "b", "--quiet", "idl-path"})
@@ -190,4 +211,59 @@ func TestArgs(t *testing.T) {
 		test.Assert(t, a.Plugins.String() == "[a b]")
 		test.Assert(t, a.Langs.String() == "[a b]")
 	})
+	t.Run("merge", func(t *testing.T) {
+		var a Arguments
+		err := a.Parse([]string{"bin", "a.thrift", "b.thrift"})
+		test.Assert(t, err != nil && err.Error() == "require exactly 1 argument for the IDL parameter, got: 2", err)
+		a = Arguments{}
+		test.Assert(t, a.Parse([]string{"bin", "--merge", "a.thrift", "b.thrift"}) == nil)
+		test.Assert(t, a.Merge && a.IDL == "a.thrift" && strings.Join(a.IDLs, ",") == "a.thrift,b.thrift", a.IDLs)
+		a = Arguments{}
+		test.Assert(t, a.Parse([]string{"bin", "a.thrift"}) == nil)
+		test.Assert(t, len(a.IDLs) == 0, a.IDLs)
+	})
+	t.Run("implicit-field-ids", func(t *testing.T) {
+		var a Arguments
+		test.Assert(t, a.Parse([]string{"bin", "idl-path"}) == nil)
+		test.Assert(t, a.ImplicitFieldIDs == semantic.CheckOff)
+		test.Assert(t, a.Parse([]string{"bin", "--implicit-field-ids", "error", "idl-path"}) == nil)
+		test.Assert(t, a.ImplicitFieldIDs == semantic.CheckError)
+		err := a.Parse([]string{"bin", "--implicit-field-ids", "on", "idl-path"})
+		test.Assert(t, err != nil && strings.Contains(err.Error(), `expect one of off, warn, error, got "on"`), err)
+	})
+	t.Run("stats", func(t *testing.T) {
+		var a Arguments
+		test.Assert(t, a.Parse([]string{"bin", "idl-path"}) == nil)
+		test.Assert(t, a.Stats == "")
+		test.Assert(t, a.Parse([]string{"bin", "--stats", "idl-path"}) == nil)
+		test.Assert(t, a.Stats == "text" && a.IDL == "idl-path", a.Stats)
+		a = Arguments{}
+		test.Assert(t, a.Parse([]string{"bin", "--stats=json", "idl-path"}) == nil)
+		test.Assert(t, a.Stats == "json", a.Stats)
+		err := a.Parse([]string{"bin", "--stats=yaml", "idl-path"})
+		test.Assert(t, err != nil && strings.Contains(err.Error(), `expect text or json, got "yaml"`), err)
+	})
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	for _, flag := range []string{"--warnings-as-errors", "-Werror"} {
+		var a Arguments
+		test.Assert(t, a.Parse([]string{"bin", flag, "-q", "idl-path"}) == nil)
+		test.Assert(t, a.WarningsAsErrors && a.Quiet)
+
+		logs := a.MakeLogFunc()
+		logs.Info("not a warning")
+		test.Assert(t, a.CheckWarnings() == nil)
+
+		// suppressed by -q but still counted
+		logs.Warn("a")
+		logs.MultiWarn([]string{"b", "c"})
+		err := a.CheckWarnings()
+		test.Assert(t, err != nil && err.Error() == "3 warning(s) treated as errors by --warnings-as-errors", err)
+	}
+
+	var a Arguments
+	test.Assert(t, a.Parse([]string{"bin", "-q", "idl-path"}) == nil)
+	a.MakeLogFunc().Warn("a")
+	test.Assert(t, a.CheckWarnings() == nil)
 }
@@ -0,0 +1,136 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package args
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configIDL is the key of a config file for the IDL argument.
+const configIDL = "idl"
+
+// flagAliases maps the short names of flags to the names used in config files.
+var flagAliases = map[string]string{
+	"r":      "recurse",
+	"v":      "verbose",
+	"q":      "quiet",
+	"o":      "out",
+	"i":      "include",
+	"g":      "gen",
+	"p":      "plugin",
+	"Werror": "warnings-as-errors",
+}
+
+// unconfigurable lists the flags that are actions of a single invocation rather
+// than settings, so they can only be given on the command line.
+var unconfigurable = map[string]bool{
+	"config":        true,
+	"version":       true,
+	"list-backends": true,
+	"merge":         true,
+	"verify":        true,
+	"clean":         true,
+	"dry-run":       true,
+	"stats":         true,
+}
+
+// listFlags are the flags that can be given more than once.
+var listFlags = map[string]bool{
+	"include": true,
+	"exclude": true,
+	"gen":     true,
+	"plugin":  true,
+}
+
+// loadConfig applies the config file to the flags that are not given on the
+// command line. The config file is a YAML or JSON mapping from the long names of
+// flags to their values, with a list for the flags that can be repeated, and the
+// "idl" key for the IDL argument.
+func (a *Arguments) loadConfig(f *flag.FlagSet) error {
+	bs, err := ioutil.ReadFile(a.Config)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(bs, &doc); err != nil {
+		return fmt.Errorf("config %s: %w", a.Config, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %s:%d: expect a mapping from flags to values", a.Config, root.Line)
+	}
+
+	given := make(map[string]bool)
+	f.Visit(func(fl *flag.Flag) {
+		if name, ok := flagAliases[fl.Name]; ok {
+			given[name] = true
+		} else {
+			given[fl.Name] = true
+		}
+	})
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		key := k.Value
+		if seen[key] {
+			return fmt.Errorf("config %s:%d: duplicated key %q", a.Config, k.Line, key)
+		}
+		seen[key] = true
+
+		if key != configIDL && (f.Lookup(key) == nil || flagAliases[key] != "" || unconfigurable[key]) {
+			return fmt.Errorf("config %s:%d: unknown key %q", a.Config, k.Line, key)
+		}
+		var values []string
+		switch {
+		case v.Kind == yaml.ScalarNode && v.Tag != "!!null":
+			values = []string{v.Value}
+		case v.Kind == yaml.SequenceNode && listFlags[key]:
+			for _, e := range v.Content {
+				if e.Kind != yaml.ScalarNode || e.Tag == "!!null" {
+					return fmt.Errorf("config %s:%d: expect a list of strings for %q", a.Config, e.Line, key)
+				}
+				values = append(values, e.Value)
+			}
+		case listFlags[key]:
+			return fmt.Errorf("config %s:%d: expect a string or a list of strings for %q", a.Config, v.Line, key)
+		default:
+			return fmt.Errorf("config %s:%d: expect a single value for %q", a.Config, v.Line, key)
+		}
+
+		if key == configIDL {
+			if a.IDL == "" {
+				a.IDL = values[0]
+			}
+			continue
+		}
+		if given[key] {
+			continue
+		}
+		for _, value := range values {
+			if err = f.Set(key, value); err != nil {
+				return fmt.Errorf("config %s:%d: invalid value %q for %q: %w", a.Config, v.Line, value, key, err)
+			}
+		}
+	}
+	return nil
+}
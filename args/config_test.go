@@ -0,0 +1,82 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package args
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo-config")
+	test.Assert(t, err == nil, err)
+	defer os.RemoveAll(dir)
+	config := func(content string) string {
+		path := filepath.Join(dir, "thriftgo.yaml")
+		test.Assert(t, ioutil.WriteFile(path, []byte(content), 0o644) == nil)
+		return path
+	}
+
+	path := config(`
+idl: main.thrift
+out: gen
+recurse: true
+include: [idl, third_party]
+gen:
+  - go:package_prefix=example.com/gen,gen_setter
+plugin: my-plugin
+plugin-time-limit: 30s
+`)
+	var a Arguments
+	test.Assert(t, a.Parse([]string{"bin", "--config", path}) == nil)
+	test.Assert(t, a.IDL == "main.thrift" && a.OutputPath == "gen" && a.Recursive, a)
+	test.Assert(t, a.Includes.String() == "[idl third_party]", a.Includes)
+	test.Assert(t, a.Langs.String() == "[go:package_prefix=example.com/gen,gen_setter]", a.Langs)
+	test.Assert(t, a.Plugins.String() == "[my-plugin]", a.Plugins)
+	test.Assert(t, a.PluginTimeLimit == 30*time.Second, a.PluginTimeLimit)
+
+	// the command line replaces the values in the config, including lists
+	a = Arguments{}
+	test.Assert(t, a.Parse([]string{"bin", "--config", path, "-o", "out", "-i", "other", "-r=false", "x.thrift"}) == nil)
+	test.Assert(t, a.IDL == "x.thrift" && a.OutputPath == "out" && !a.Recursive, a)
+	test.Assert(t, a.Includes.String() == "[other]", a.Includes)
+	test.Assert(t, a.Langs.String() == "[go:package_prefix=example.com/gen,gen_setter]", a.Langs)
+
+	// the same as JSON
+	a = Arguments{}
+	test.Assert(t, a.Parse([]string{"bin", "--config", config(`{"idl": "main.thrift", "include": "idl", "warnings-as-errors": true}`)}) == nil)
+	test.Assert(t, a.IDL == "main.thrift" && a.Includes.String() == "[idl]" && a.WarningsAsErrors, a)
+
+	for _, c := range []struct{ content, err string }{
+		{"incldue: [idl]", `thriftgo.yaml:1: unknown key "incldue"`},
+		{"o: out", `thriftgo.yaml:1: unknown key "o"`},
+		{"verify: true", `thriftgo.yaml:1: unknown key "verify"`},
+		{"out: [a, b]", `thriftgo.yaml:1: expect a single value for "out"`},
+		{"recurse: yes", `thriftgo.yaml:1: invalid value "yes" for "recurse"`},
+		{"out: a\nout: b", `thriftgo.yaml:2: duplicated key "out"`},
+		{"- out", `thriftgo.yaml:1: expect a mapping from flags to values`},
+		{"out: a", `require the IDL parameter on the command line or as "idl" in config`},
+	} {
+		a = Arguments{}
+		err := a.Parse([]string{"bin", "--config", config(c.content)})
+		test.Assert(t, err != nil && strings.Contains(err.Error(), c.err), c.content, err)
+	}
+}
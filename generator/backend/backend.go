@@ -60,3 +60,28 @@ type Backend interface {
 type PostProcessor interface {
 	PostProcess(path string, content []byte) ([]byte, error)
 }
+
+// FormatChecker is an optional extension for the Backend interface
+// to report the generated files that it can not format when they are
+// written without the post process, e.g. with --no-format.
+type FormatChecker interface {
+	// CheckFormat returns the error of formatting the file, or nil if
+	// the file can be formatted or is not formatted by the backend.
+	CheckFormat(path string, content []byte) error
+}
+
+// OptionTyper is an optional extension for the Backend interface
+// to tell tools how to present its options.
+type OptionTyper interface {
+	// OptionType returns the type of the value of the option, such as "bool"
+	// and "string", or an empty string if it is unknown.
+	OptionType(name string) string
+}
+
+// OptionResolver is an optional extension for the Backend interface
+// to tell plugins the options in effect for a generation.
+type OptionResolver interface {
+	// ResolveOptions returns the value of each option of the backend, including
+	// the defaults of those not given, after applying the given options.
+	ResolveOptions(opts []plugin.Option) (map[string]string, error)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// OutputDirData is the data of the template given by --out-template.
+type OutputDirData struct {
+	Dir       string // Directory of the IDL with '/' as the separator, e.g. "idl/user", or ".".
+	DirBase   string // Last element of the directory, e.g. "user".
+	FileBase  string // Base name of the IDL without the extension, e.g. "user_service".
+	FileName  string // Base name of the IDL, e.g. "user_service.thrift".
+	Namespace string // Namespace of the IDL for the language with '/' as the separator, e.g. "acme/user".
+	Lang      string // The target language, e.g. "go".
+}
+
+// NewOutputDirData creates the data of the template for an IDL. The namespace
+// is the one used to lay out the generated files of the language.
+func NewOutputDirData(filename, namespace, lang string) *OutputDirData {
+	base := filepath.Base(filename)
+	data := &OutputDirData{
+		FileBase:  strings.TrimSuffix(base, filepath.Ext(base)),
+		FileName:  base,
+		Dir:       ".",
+		Namespace: namespace,
+		Lang:      lang,
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		data.Dir, data.DirBase = filepath.ToSlash(dir), filepath.Base(dir)
+	}
+	return data
+}
+
+// ParseOutputTemplate parses the template given by --out-template, e.g.
+// '{{.Dir}}/gen/{{.Namespace}}', and checks it with placeholder data, which the
+// errors name as such rather than as an IDL of the user.
+func ParseOutputTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("out-template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	data := NewOutputDirData("idl/user/user_service.thrift", "acme/user", "go")
+	var buf strings.Builder
+	if err = tpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	dir := strings.TrimSpace(buf.String())
+	if _, err = OutputPath("", dir, data.FileName); err != nil {
+		return nil, fmt.Errorf("invalid output template: it computes %q for the placeholder IDL %s/%s "+
+			"with namespace %q, which is not inside the output root", dir, data.Dir, data.FileName, data.Namespace)
+	}
+	return tpl, nil
+}
+
+// OutputDir returns the output directory under root computed by the template for
+// an IDL. It is an error if the directory is not inside root.
+func OutputDir(tpl *template.Template, root string, data *OutputDirData) (string, error) {
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return OutputPath(root, strings.TrimSpace(buf.String()), path.Join(data.Dir, data.FileName))
+}
+
+// OutputPath joins root with rel, a path relative to root computed for the IDL
+// src. It is an error if the path is not inside root.
+func OutputPath(root, rel, src string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || strings.HasPrefix(filepath.ToSlash(clean), "/") ||
+		clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q for %s escapes the output root", rel, src)
+	}
+	return filepath.Join(root, clean), nil
+}
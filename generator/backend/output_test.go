@@ -0,0 +1,57 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputDir(t *testing.T) {
+	tpl, err := ParseOutputTemplate("{{.Dir}}/gen/{{.Lang}}/{{.Namespace}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ idl, ns, dir string }{
+		{"svc/user/user.thrift", "acme/user", "out/svc/user/gen/go/acme/user"},
+		{"user.thrift", "user", "out/gen/go/user"},
+	} {
+		dir, err := OutputDir(tpl, "out", NewOutputDirData(c.idl, c.ns, "go"))
+		if err != nil || dir != filepath.FromSlash(c.dir) {
+			t.Fatalf("expect %s for %s, got %q, %v", c.dir, c.idl, dir, err)
+		}
+	}
+
+	// the IDLs outside the working directory can not be laid out by their directories
+	_, err = OutputDir(tpl, "out", NewOutputDirData("../idl/user.thrift", "user", "go"))
+	if err == nil || !strings.Contains(err.Error(), "escapes the output root") {
+		t.Fatalf("expect an error for ../idl/user.thrift, got %v", err)
+	}
+
+	for _, text := range []string{"{{.Dir", "{{.Unknown}}", "../{{.Namespace}}", "/abs/{{.Namespace}}", "{{.Dir}}/../../.."} {
+		if _, err := ParseOutputTemplate(text); err == nil || !strings.HasPrefix(err.Error(), "invalid output template: ") {
+			t.Fatalf("expect an error for %q, got %v", text, err)
+		}
+	}
+
+	// the errors of the check do not pass the placeholder data off as an IDL of the user
+	_, err = ParseOutputTemplate("../{{.Namespace}}")
+	expect := `invalid output template: it computes "../acme/user" for the placeholder IDL ` +
+		`idl/user/user_service.thrift with namespace "acme/user", which is not inside the output root`
+	if err == nil || err.Error() != expect {
+		t.Fatalf("expect %q, got %v", expect, err)
+	}
+}
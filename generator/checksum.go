@@ -0,0 +1,128 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// ChecksumPrefix starts the line that holds the checksum of a generated file.
+// A backend asks for a checksum by emitting a line with only the prefix, which
+// is completed by Persist once the content of the file is final.
+const ChecksumPrefix = "// Checksum:"
+
+const checksumAlgorithm = "sha256:"
+
+// IDLChecksumPrefix starts the line that holds the checksum of the IDL that a
+// file is generated from. Verify reports the file as stale if the line differs
+// from the one that would be generated from the current IDL.
+const IDLChecksumPrefix = "// IDL checksum:"
+
+// findChecksum returns the start and the end of the checksum line, or -1 if
+// there is none.
+func findChecksum(content []byte) (start, end int) {
+	return findLine(content, ChecksumPrefix)
+}
+
+// findLine returns the start and the end, including the line break, of the
+// first line that begins with the prefix. It returns -1 if there is none.
+func findLine(content []byte, prefix string) (start, end int) {
+	p := []byte(prefix)
+	for start < len(content) {
+		end = bytes.IndexByte(content[start:], '\n') + 1
+		if end == 0 {
+			end = len(content)
+		} else {
+			end += start
+		}
+		if bytes.HasPrefix(content[start:end], p) {
+			return start, end
+		}
+		start = end
+	}
+	return -1, -1
+}
+
+// Checksum computes the checksum of the content with the checksum line removed.
+func Checksum(content []byte) string {
+	h := sha256.New()
+	if start, end := findChecksum(content); start >= 0 {
+		h.Write(content[:start])
+		h.Write(content[end:])
+	} else {
+		h.Write(content)
+	}
+	return checksumAlgorithm + hex.EncodeToString(h.Sum(nil))
+}
+
+// SealChecksum fills the checksum line of the content with its checksum. The
+// content is returned unchanged if it has no checksum line.
+func SealChecksum(content []byte) []byte {
+	start, end := findChecksum(content)
+	if start < 0 {
+		return content
+	}
+	line := ChecksumPrefix + " " + Checksum(content)
+	if end > start && content[end-1] == '\n' {
+		line += "\n"
+	}
+	res := make([]byte, 0, len(content)+len(line)-(end-start))
+	res = append(res, content[:start]...)
+	res = append(res, line...)
+	return append(res, content[end:]...)
+}
+
+// VerifyChecksum reports whether the content has a checksum line and whether
+// the checksum in it matches the content.
+func VerifyChecksum(content []byte) (found, ok bool) {
+	start, end := findChecksum(content)
+	if start < 0 {
+		return false, false
+	}
+	sum := bytes.TrimSpace(content[start+len(ChecksumPrefix) : end])
+	return true, string(sum) == Checksum(content)
+}
+
+// IDLChecksum computes the checksum of the sources of the IDLs, which are
+// usually an IDL and the ones it includes in the order of a depth-first search.
+// The sources are the ones seen by the parser, so the checksum does not depend
+// on the line endings or a UTF-8 BOM, and it is an error if an IDL is not parsed
+// in this process.
+func IDLChecksum(asts ...*parser.Thrift) (string, error) {
+	h := sha256.New()
+	for _, t := range asts {
+		sum, ok := t.SourceDigest()
+		if !ok {
+			return "", fmt.Errorf("checksum of '%s': the source is unknown", t.Filename)
+		}
+		h.Write(sum)
+	}
+	return checksumAlgorithm + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// idlChecksumLine returns the IDL checksum line of the content without the line
+// break, or an empty string if there is none.
+func idlChecksumLine(content []byte) string {
+	start, end := findLine(content, IDLChecksumPrefix)
+	if start < 0 {
+		return ""
+	}
+	return string(bytes.TrimSpace(content[start:end]))
+}
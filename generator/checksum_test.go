@@ -0,0 +1,137 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/generator"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+func TestChecksum(t *testing.T) {
+	content := "// Checksum:\n// Code generated by thriftgo. DO NOT EDIT.\n\npackage a\n"
+	sealed := generator.SealChecksum([]byte(content))
+	lines := strings.SplitN(string(sealed), "\n", 2)
+	test.Assert(t, strings.HasPrefix(lines[0], "// Checksum: sha256:") && len(lines[0]) == len("// Checksum: sha256:")+64, lines[0])
+	test.Assert(t, lines[1] == content[len("// Checksum:\n"):], string(sealed))
+
+	// the checksum line is excluded from the hash, so sealing again is stable
+	test.Assert(t, string(generator.SealChecksum(sealed)) == string(sealed))
+	test.Assert(t, generator.Checksum(sealed) == generator.Checksum([]byte(content)))
+
+	found, ok := generator.VerifyChecksum(sealed)
+	test.Assert(t, found && ok)
+	edited := strings.Replace(string(sealed), "package a", "package b", 1)
+	found, ok = generator.VerifyChecksum([]byte(edited))
+	test.Assert(t, found && !ok)
+	found, _ = generator.VerifyChecksum([]byte("package a\n"))
+	test.Assert(t, !found)
+	test.Assert(t, string(generator.SealChecksum([]byte("package a\n"))) == "package a\n")
+
+	dir, err := ioutil.TempDir("", "thriftgo-checksum")
+	test.Assert(t, err == nil, err)
+	defer os.RemoveAll(dir)
+	name := func(s string) *string {
+		s = filepath.Join(dir, s)
+		return &s
+	}
+	res := &plugin.Response{Contents: []*plugin.Generated{
+		{Name: name("sealed.go"), Content: content},
+		{Name: name("edited.go"), Content: content},
+		{Name: name("stripped.go"), Content: content},
+		{Name: name("plain.go"), Content: "package a\n"},
+		{Name: name("missing.go"), Content: content},
+	}}
+	write := func(file, content string) {
+		test.Assert(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0o644) == nil)
+	}
+	write("sealed.go", string(sealed))
+	write("edited.go", edited)
+	write("stripped.go", content[len("// Checksum:\n"):])
+	write("plain.go", "package b\n")
+
+	var g generator.Generator
+	err = g.Verify(res)
+	test.Assert(t, err != nil && err.Error() == "2 generated file(s) have been edited by hand:\n\t"+
+		*name("edited.go")+"\n\t"+*name("stripped.go"), err)
+
+	res.Contents = res.Contents[:1]
+	test.Assert(t, g.Verify(res) == nil)
+}
+
+func TestIDLChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo-idl-checksum")
+	test.Assert(t, err == nil, err)
+	defer os.RemoveAll(dir)
+	write := func(file, content string) {
+		test.Assert(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0o644) == nil)
+	}
+	checksum := func() string {
+		ast, err := parser.ParseFile(filepath.Join(dir, "main.thrift"), nil, true)
+		test.Assert(t, err == nil, err)
+		var asts []*parser.Thrift
+		for t := range ast.DepthFirstSearch() {
+			asts = append(asts, t)
+		}
+		sum, err := generator.IDLChecksum(asts...)
+		test.Assert(t, err == nil, err)
+		return sum
+	}
+	write("main.thrift", "include \"base.thrift\"\n\nstruct A {\n    1: base.B b\n}\n")
+	write("base.thrift", "struct B {\n    1: i64 id\n}\n")
+	sum := checksum()
+	test.Assert(t, strings.HasPrefix(sum, "sha256:") && len(sum) == len("sha256:")+64, sum)
+
+	// the line endings and the BOM do not change the checksum
+	write("main.thrift", "\uFEFFinclude \"base.thrift\"\r\n\r\nstruct A {\r\n    1: base.B b\r\n}\r\n")
+	test.Assert(t, checksum() == sum)
+
+	// changing an included IDL does
+	write("base.thrift", "struct B {\n    1: i32 id\n}\n")
+	changed := checksum()
+	test.Assert(t, changed != sum)
+
+	gen := func(sum string) string {
+		return "// IDL checksum: " + sum + "\n// Code generated by thriftgo. DO NOT EDIT.\n\npackage a\n"
+	}
+	name := filepath.Join(dir, "a.go")
+	write("a.go", gen(sum))
+	res := &plugin.Response{Contents: []*plugin.Generated{{Name: &name, Content: gen(changed)}}}
+	var g generator.Generator
+	err = g.Verify(res)
+	test.Assert(t, err != nil && err.Error() == "1 generated file(s) are stale, their IDL has changed since:\n\t"+name, err)
+
+	write("a.go", gen(changed))
+	test.Assert(t, g.Verify(res) == nil)
+
+	// the sources are the parsed ones, not the files on the disk
+	ast, err := parser.ParseString(filepath.Join(dir, "other.thrift"), "struct O {}")
+	test.Assert(t, err == nil, err)
+	parsed, err := generator.IDLChecksum(ast)
+	test.Assert(t, err == nil, err)
+	write("other.thrift", "struct P {}")
+	sum, err = generator.IDLChecksum(ast)
+	test.Assert(t, err == nil && sum == parsed, err)
+
+	_, err = generator.IDLChecksum(&parser.Thrift{Filename: "unknown.thrift"})
+	test.Assert(t, err != nil && err.Error() == "checksum of 'unknown.thrift': the source is unknown", err)
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+// GeneratedMarker starts the line in the header of the files generated by
+// thriftgo. Clean only removes the files that have it.
+const GeneratedMarker = "// Code generated by thriftgo"
+
+// OwnerPrefix starts the line that Clean adds below GeneratedMarker in the
+// header of the generated files to record the IDL that they are generated
+// for. Clean only removes the files whose line has the same IDL, so that it
+// keeps the files of other IDLs that are generated into the same directories.
+const OwnerPrefix = "// Generated from:"
+
+// Clean finds the files generated by thriftgo for the IDL in the directories
+// that the response writes to but that are not in the response, i.e. that are
+// no longer generated, and removes them unless dryRun is true. It returns the
+// files in the lexical order. Only the files with GeneratedMarker in their
+// header and an OwnerPrefix line for the IDL are removed, and never the files
+// of the subdirectories. Clean then records the IDL in the header of the
+// files in the response, which Persist writes.
+//
+// Clean also returns the files in those directories that have GeneratedMarker
+// but no OwnerPrefix line, e.g. the ones generated by the versions of thriftgo
+// before it, which it keeps because it can not tell whose they are.
+func (g *Generator) Clean(res *plugin.Response, idl string, dryRun bool) (removed, unowned []string, err error) {
+	if err := res.GetError(); err != "" {
+		return nil, nil, errors.New(err)
+	}
+	owner := OwnerPrefix + " " + filepath.ToSlash(filepath.Clean(idl))
+	produced := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for i, c := range res.Contents {
+		full, err := fullPath(i, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		full = filepath.Clean(full)
+		produced[full] = true
+		dirs[filepath.Dir(full)] = true
+	}
+
+	var stale []string
+	for dir := range dirs {
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read directory '%s': %w", dir, err)
+		}
+		for _, e := range entries {
+			full := filepath.Join(dir, e.Name())
+			if !e.Mode().IsRegular() || produced[full] {
+				continue
+			}
+			generated, line, err := ownerLine(full)
+			if err != nil {
+				return nil, nil, err
+			}
+			if line == owner {
+				stale = append(stale, full)
+			} else if generated && line == "" {
+				unowned = append(unowned, full)
+			}
+		}
+	}
+	sort.Strings(stale)
+	sort.Strings(unowned)
+	for _, c := range res.Contents {
+		c.Content = setOwner(c.Content, owner)
+	}
+	if dryRun {
+		return stale, unowned, nil
+	}
+	for _, full := range stale {
+		if err := os.Remove(full); err != nil {
+			return nil, nil, fmt.Errorf("failed to remove file '%s': %w", full, err)
+		}
+	}
+	return stale, unowned, nil
+}
+
+// ownerLine tells whether the header of the file, i.e. the lines before the
+// package clause, has the GeneratedMarker line, and returns the OwnerPrefix
+// line that follows it, or an empty string if there is none.
+func ownerLine(file string) (generated bool, owner string, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read file '%s': %w", file, err)
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Bytes()
+		if bytes.HasPrefix(line, []byte(GeneratedMarker)) {
+			if s.Scan() && bytes.HasPrefix(s.Bytes(), []byte(OwnerPrefix)) {
+				return true, string(bytes.TrimSpace(s.Bytes())), nil
+			}
+			return true, "", nil
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false, "", nil
+		}
+	}
+	// a line too long for the scanner is not in the header of a generated file
+	return false, "", nil
+}
+
+// setOwner puts the owner line below the GeneratedMarker line of the header of
+// the content, replacing the one that is there. The content is returned
+// unchanged if its header has no GeneratedMarker line.
+func setOwner(content, owner string) string {
+	for start := 0; start < len(content); {
+		end := strings.IndexByte(content[start:], '\n') + 1
+		if end == 0 {
+			return content
+		}
+		end += start
+		line := content[start:end]
+		if strings.HasPrefix(line, "package ") {
+			return content
+		}
+		if strings.HasPrefix(line, GeneratedMarker) {
+			rest := content[end:]
+			if strings.HasPrefix(rest, OwnerPrefix) {
+				if i := strings.IndexByte(rest, '\n'); i >= 0 {
+					rest = rest[i+1:]
+				} else {
+					rest = ""
+				}
+			}
+			return content[:end] + owner + "\n" + rest
+		}
+		start = end
+	}
+	return content
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/generator"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+func TestClean(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo-clean")
+	test.Assert(t, err == nil, err)
+	defer os.RemoveAll(dir)
+	name := func(s string) string {
+		return filepath.Join(dir, filepath.FromSlash(s))
+	}
+	write := func(file, content string) {
+		test.Assert(t, os.MkdirAll(filepath.Dir(name(file)), 0o755) == nil)
+		test.Assert(t, ioutil.WriteFile(name(file), []byte(content), 0o644) == nil)
+	}
+	generated := "// Code generated by thriftgo (0.3.0). DO NOT EDIT.\n"
+	owned := generated + generator.OwnerPrefix + " idl/a.thrift\n\npackage a\n"
+	write("a/kept.go", owned)
+	write("a/stale.go", owned)
+	write("a/sealed.go", "// Checksum: sha256:00\n// header\n"+owned)
+	write("a/other.go", generated+generator.OwnerPrefix+" idl/b.thrift\n\npackage a\n")
+	write("a/unowned.go", generated+"\npackage a\n")
+	write("a/hand.go", "// Code generated by hand.\n"+generator.OwnerPrefix+" idl/a.thrift\n\npackage a\n")
+	write("a/quoted.go", "package a\n\n"+owned)
+	write("a/sub/stale.go", owned)
+	write("b/stale.go", owned)
+
+	kept := name("a/kept.go")
+	res := &plugin.Response{Contents: []*plugin.Generated{
+		{Name: &kept, Content: generated + "\npackage a\n"},
+	}}
+	var g generator.Generator
+	stale := []string{name("a/sealed.go"), name("a/stale.go")}
+	removed, unowned, err := g.Clean(res, "./idl/a.thrift", true)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, strings.Join(removed, ",") == strings.Join(stale, ","), removed)
+	// the generated files without an owner line are kept but reported
+	test.Assert(t, len(unowned) == 1 && unowned[0] == name("a/unowned.go"), unowned)
+	for _, f := range stale {
+		_, err = os.Stat(f)
+		test.Assert(t, err == nil, err)
+	}
+	test.Assert(t, res.Contents[0].Content == owned, res.Contents[0].Content)
+
+	removed, _, err = g.Clean(res, "idl/a.thrift", false)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, strings.Join(removed, ",") == strings.Join(stale, ","), removed)
+	for _, f := range stale {
+		_, err = os.Stat(f)
+		test.Assert(t, os.IsNotExist(err), err)
+	}
+	for _, f := range []string{"a/kept.go", "a/other.go", "a/unowned.go", "a/hand.go", "a/quoted.go", "a/sub/stale.go", "b/stale.go"} {
+		_, err = os.Stat(name(f))
+		test.Assert(t, err == nil, err)
+	}
+	// the owner line is replaced rather than repeated
+	test.Assert(t, res.Contents[0].Content == owned, res.Contents[0].Content)
+
+	// the files of another IDL in the same directory are its own
+	removed, _, err = g.Clean(res, "idl/b.thrift", false)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(removed) == 1 && removed[0] == name("a/other.go"), removed)
+
+	msg := "failed"
+	_, _, err = g.Clean(&plugin.Response{Error: &msg}, "a.thrift", false)
+	test.Assert(t, err != nil && err.Error() == msg, err)
+}
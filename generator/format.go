@@ -0,0 +1,65 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ExternalFormatter formats generated files with an external command, such as
+// gofumpt. The command reads a file from stdin and writes the result to stdout.
+type ExternalFormatter struct {
+	command string
+	name    string
+	args    []string
+}
+
+// NewExternalFormatter creates an ExternalFormatter for the command line, which
+// is split by spaces into the program and its arguments. No shell is involved.
+func NewExternalFormatter(command string) (*ExternalFormatter, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("post-format: empty command")
+	}
+	return &ExternalFormatter{command: command, name: fields[0], args: fields[1:]}, nil
+}
+
+// Format runs the command with content as its stdin and returns its stdout.
+// The path is only used in the error, which contains the stderr of the command.
+func (f *ExternalFormatter) Format(path string, content []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(f.name, f.args...)
+	// exec copies the buffers in separated goroutines, so a command that writes
+	// before reading all of its input can not block on a full pipe
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil && stdout.Len() == 0 && len(content) > 0 {
+		err = errors.New("no output, the command must write the result to stdout")
+	}
+	if err != nil {
+		msg := fmt.Sprintf("post-format %q failed on %s: %s", f.command, path, err)
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			msg += ":\n" + s
+		}
+		return nil, errors.New(msg)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/generator"
+	"github.com/cloudwego/thriftgo/generator/backend"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+func TestExternalFormatter(t *testing.T) {
+	for _, name := range []string{"tr", "cat", "ls"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s is not available", name)
+		}
+	}
+
+	_, err := generator.NewExternalFormatter("  ")
+	test.Assert(t, err != nil)
+
+	f, err := generator.NewExternalFormatter("tr a-z A-Z")
+	test.Assert(t, err == nil, err)
+	out, err := f.Format("a.go", []byte("package a\n"))
+	test.Assert(t, err == nil && string(out) == "PACKAGE A\n", string(out), err)
+
+	// larger than the buffers of pipes
+	big := bytes.Repeat([]byte("// 0123456789abcdef\n"), 1<<16)
+	f, _ = generator.NewExternalFormatter("cat")
+	out, err = f.Format("big.go", big)
+	test.Assert(t, err == nil && bytes.Equal(out, big), err)
+
+	f, _ = generator.NewExternalFormatter("ls /nonexistent-thriftgo")
+	_, err = f.Format("a.go", []byte("package a\n"))
+	test.Assert(t, err != nil && strings.HasPrefix(err.Error(), `post-format "ls /nonexistent-thriftgo" failed on a.go: exit status`), err)
+	test.Assert(t, strings.Contains(err.Error(), ":\n"), "expect the stderr in the error", err)
+
+	f, _ = generator.NewExternalFormatter("tr -d a-z")
+	_, err = f.Format("a.go", []byte("abc"))
+	test.Assert(t, err != nil && strings.Contains(err.Error(), "no output"), err)
+}
+
+// upperBackend generates the files given by name and content, and formats them
+// into upper case unless they contain "!".
+type upperBackend struct {
+	files map[string]string
+}
+
+func (b *upperBackend) Name() string                         { return "upper" }
+func (b *upperBackend) Lang() string                         { return "upper" }
+func (b *upperBackend) Options() []plugin.Option             { return nil }
+func (b *upperBackend) BuiltinPlugins() []*plugin.Desc       { return nil }
+func (b *upperBackend) GetPlugin(*plugin.Desc) plugin.Plugin { return nil }
+
+func (b *upperBackend) Generate(req *plugin.Request, log backend.LogFunc) *plugin.Response {
+	res := plugin.NewResponse()
+	for name, content := range b.files {
+		name := filepath.Join(req.OutputPath, name)
+		res.Contents = append(res.Contents, &plugin.Generated{Name: &name, Content: content})
+	}
+	return res
+}
+
+func (b *upperBackend) PostProcess(path string, content []byte) ([]byte, error) {
+	if err := b.CheckFormat(path, content); err != nil {
+		return content, nil
+	}
+	return bytes.ToUpper(content), nil
+}
+
+func (b *upperBackend) CheckFormat(path string, content []byte) error {
+	if bytes.Contains(content, []byte("!")) {
+		return errors.New("unexpected '!'")
+	}
+	return nil
+}
+
+func TestNoFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo-no-format")
+	test.Assert(t, err == nil, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{"a": "a!", "b": "b", "c": "c!"}
+	var g generator.Generator
+	test.Assert(t, g.RegisterBackend(&upperBackend{files: files}) == nil)
+
+	for _, noFormat := range []bool{false, true} {
+		var warns []string
+		log := backend.DummyLogFunc()
+		log.Warn = func(v ...interface{}) { warns = append(warns, fmt.Sprint(v...)) }
+		args := &generator.Arguments{
+			Out:      &generator.LangSpec{Language: "upper"},
+			Req:      &plugin.Request{OutputPath: dir},
+			Log:      log,
+			NoFormat: noFormat,
+		}
+		test.Assert(t, g.Persist(g.Generate(args)) == nil)
+
+		for name, content := range files {
+			bs, err := ioutil.ReadFile(filepath.Join(dir, name))
+			test.Assert(t, err == nil, err)
+			if !noFormat && name == "b" {
+				content = "B"
+			}
+			test.Assert(t, string(bs) == content, noFormat, name, string(bs))
+		}
+		if noFormat {
+			// every file that fails is reported, not only the first one
+			test.Assert(t, len(warns) == 2, warns)
+			for _, w := range warns {
+				test.Assert(t, strings.HasSuffix(w, " is written unformatted: unexpected '!'"), w)
+			}
+		} else {
+			test.Assert(t, len(warns) == 0, warns)
+		}
+	}
+}
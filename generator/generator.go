@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/cloudwego/thriftgo/generator/backend"
 	"github.com/cloudwego/thriftgo/plugin"
@@ -40,6 +41,16 @@ type Arguments struct {
 	Out *LangSpec
 	Req *plugin.Request
 	Log backend.LogFunc
+
+	// Timing records the durations of the codegen, plugins and write phases if it is not nil.
+	Timing *Timing
+
+	// PostFormat is a command to format each file before it is written, see ExternalFormatter.
+	PostFormat string
+
+	// NoFormat makes Persist write the files without formatting them and report
+	// the files that the backend fails to format, see backend.FormatChecker.
+	NoFormat bool
 }
 
 // Generator controls the code generation.
@@ -50,6 +61,9 @@ type Generator struct {
 	files    *FileManager
 	log      backend.LogFunc
 	pp       backend.PostProcessor
+	timing   *Timing
+	format   *ExternalFormatter
+	noFormat bool
 }
 
 // Name returns "thriftgo".
@@ -81,6 +95,42 @@ func (g *Generator) AllBackend() []backend.Backend {
 	return g.backends
 }
 
+// BackendInfo describes a backend and its options for tools.
+type BackendInfo struct {
+	Name    string        `json:"name"`
+	Lang    string        `json:"lang"`
+	Options []*OptionInfo `json:"options"`
+}
+
+// OptionInfo describes an option of a backend. The Type is empty if the
+// backend does not implement backend.OptionTyper.
+type OptionInfo struct {
+	Name    string `json:"name"`
+	Desc    string `json:"desc"`
+	Type    string `json:"type,omitempty"`
+	Boolean bool   `json:"boolean"`
+}
+
+// DescribeBackends returns the descriptions of all registered backends in the
+// order of their registration.
+func (g *Generator) DescribeBackends() []*BackendInfo {
+	infos := make([]*BackendInfo, 0, len(g.backends))
+	for _, b := range g.backends {
+		info := &BackendInfo{Name: b.Name(), Lang: b.Lang(), Options: []*OptionInfo{}}
+		typer, _ := b.(backend.OptionTyper)
+		for _, opt := range b.Options() {
+			o := &OptionInfo{Name: opt.Name, Desc: opt.Desc}
+			if typer != nil {
+				o.Type = typer.OptionType(opt.Name)
+			}
+			o.Boolean = o.Type == "bool"
+			info.Options = append(info.Options, o)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
 func (g *Generator) validateRequest(req *plugin.Request) error {
 	// TODO(lushaojie): validate request
 	return nil
@@ -115,6 +165,16 @@ func (g *Generator) Generate(args *Arguments) (res *plugin.Response) {
 
 	g.files = NewFileManager(log)
 	g.log = log
+	g.timing = args.Timing
+	g.format = nil
+	g.noFormat = args.NoFormat
+	if args.PostFormat != "" {
+		f, err := NewExternalFormatter(args.PostFormat)
+		if err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+		g.format = f
+	}
 
 	be := g.GetBackend(out.Language)
 	if be == nil {
@@ -130,7 +190,14 @@ func (g *Generator) Generate(args *Arguments) (res *plugin.Response) {
 	}
 
 	req.GeneratorParameters = plugin.Pack(out.Options)
+	opts, err := resolveOptions(be, out.Options)
+	if err != nil {
+		return plugin.BuildErrorResponse(err.Error())
+	}
+	req.GeneratorOptions = opts
+	stop := g.timing.Start("codegen")
 	res = be.Generate(req, log)
+	stop()
 	log.MultiWarn(res.Warnings)
 	if res.GetError() != "" {
 		return res
@@ -141,6 +208,9 @@ func (g *Generator) Generate(args *Arguments) (res *plugin.Response) {
 		return plugin.BuildErrorResponse(err.Error())
 	}
 
+	if len(out.SDKPlugins) > 0 || len(g.plugins) > 0 {
+		defer g.timing.Start("plugins")()
+	}
 	if len(out.SDKPlugins) > 0 {
 		for _, sdk := range out.SDKPlugins {
 			req.PluginParameters = sdk.GetPluginParameters()
@@ -173,33 +243,57 @@ func (g *Generator) Generate(args *Arguments) (res *plugin.Response) {
 	return res
 }
 
+// resolveOptions returns the options in effect for the backend. For a backend
+// that does not implement backend.OptionResolver, they are the given options
+// with the last value of each.
+func resolveOptions(be backend.Backend, opts []plugin.Option) (map[string]string, error) {
+	if r, ok := be.(backend.OptionResolver); ok {
+		return r.ResolveOptions(opts)
+	}
+	res := make(map[string]string, len(opts))
+	for _, o := range opts {
+		res[o.Name] = o.Desc
+	}
+	return res, nil
+}
+
 // Persist writes generated files into the disk. Each files in the Contents
 // slice must have a legal name.
 func (g *Generator) Persist(res *plugin.Response) error {
 	if err := res.GetError(); err != "" {
 		return errors.New(err)
 	}
+	defer g.timing.Start("write")()
 	for i, c := range res.Contents {
-		full := c.GetName()
-		if full == "" {
-			return fmt.Errorf("file name not found for the %dth generated item", i)
-		}
-		if !filepath.IsAbs(full) && dir_utils.HasGlobalWd() {
-			wd, err := dir_utils.Getwd()
-			if err != nil {
-				return err
-			}
-			full = filepath.Join(wd, full)
+		full, err := fullPath(i, c)
+		if err != nil {
+			return err
 		}
 
 		content := []byte(c.Content)
-		if g.pp != nil {
+		if g.noFormat {
+			// report the errors without losing the content that causes them
+			if fc, ok := g.pp.(backend.FormatChecker); ok {
+				if err := fc.CheckFormat(full, content); err != nil {
+					g.log.Warn(fmt.Sprintf("%s is written unformatted: %s", full, err))
+				}
+			}
+		} else if g.pp != nil {
 			processed, err := g.pp.PostProcess(full, content)
 			if err != nil {
 				return err
 			}
 			content = processed
 		}
+		if g.format != nil && !g.noFormat {
+			// keep the unformatted content so that a broken formatter does not lose codes
+			if formatted, err := g.format.Format(full, content); err != nil {
+				g.log.Warn(err.Error())
+			} else {
+				content = formatted
+			}
+		}
+		content = SealChecksum(content)
 
 		g.log.Info("Write", full)
 		path := filepath.Dir(full)
@@ -212,3 +306,65 @@ func (g *Generator) Persist(res *plugin.Response) error {
 	}
 	return nil
 }
+
+// Verify checks the checksums of the files on the disk that would be written by
+// Persist for the response and returns an error listing the files whose content
+// no longer matches their checksum, i.e. that have been edited since they were
+// generated. It also lists the files whose IDL checksum line differs from the
+// one in the response, i.e. that are stale because their IDL has changed since
+// they were generated. Files without the checksum lines and missing files are
+// ignored.
+func (g *Generator) Verify(res *plugin.Response) error {
+	if err := res.GetError(); err != "" {
+		return errors.New(err)
+	}
+	var edited, stale []string
+	for i, c := range res.Contents {
+		full, err := fullPath(i, c)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(full)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file '%s': %w", full, err)
+		}
+		if found, ok := VerifyChecksum(content); found && !ok {
+			edited = append(edited, full)
+		} else if start, _ := findChecksum([]byte(c.Content)); !found && start >= 0 {
+			// the checksum line itself has been removed
+			edited = append(edited, full)
+		}
+		if line := idlChecksumLine(content); line != "" && line != idlChecksumLine([]byte(c.Content)) {
+			stale = append(stale, full)
+		}
+	}
+	var msgs []string
+	if len(edited) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d generated file(s) have been edited by hand:\n\t%s", len(edited), strings.Join(edited, "\n\t")))
+	}
+	if len(stale) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d generated file(s) are stale, their IDL has changed since:\n\t%s", len(stale), strings.Join(stale, "\n\t")))
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+func fullPath(i int, c *plugin.Generated) (string, error) {
+	full := c.GetName()
+	if full == "" {
+		return "", fmt.Errorf("file name not found for the %dth generated item", i)
+	}
+	if !filepath.IsAbs(full) && dir_utils.HasGlobalWd() {
+		wd, err := dir_utils.Getwd()
+		if err != nil {
+			return "", err
+		}
+		full = filepath.Join(wd, full)
+	}
+	return full, nil
+}
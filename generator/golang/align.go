@@ -76,7 +76,9 @@ func (d *sizeDiff) percent() float64 {
 	return float64(d.arranged-d.original) / float64(d.original) * 100
 }
 
-func reorderFields(s *parser.StructLike) *sizeDiff {
+// reorderFields sorts the fields of s by their sizes. When bitset is true, the
+// optional scalars are values instead of pointers (gen_presence=bitset).
+func reorderFields(s *parser.StructLike, bitset bool) *sizeDiff {
 	if len(s.Fields) == 0 {
 		return nil
 	}
@@ -88,7 +90,7 @@ func reorderFields(s *parser.StructLike) *sizeDiff {
 	var a1, a2 align
 	sizes := make(map[*parser.Field]int, len(fs))
 	for _, f := range fs {
-		if NeedRedirect(f) {
+		if NeedRedirect(f) && !(bitset && IsBaseType(f.Type)) {
 			sizes[f] = pointerSize
 		} else {
 			sizes[f] = sizeof[f.Type.Category]
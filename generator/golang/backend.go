@@ -17,7 +17,9 @@ package golang
 import (
 	"fmt"
 	"go/format"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -28,10 +30,12 @@ import (
 	ref_tpl "github.com/cloudwego/thriftgo/generator/golang/templates/ref"
 	reflection_tpl "github.com/cloudwego/thriftgo/generator/golang/templates/reflection"
 
+	"github.com/cloudwego/thriftgo/generator"
 	"github.com/cloudwego/thriftgo/generator/backend"
 	"github.com/cloudwego/thriftgo/generator/golang/templates"
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
+	"github.com/cloudwego/thriftgo/semantic"
 )
 
 // GoBackend generates go codes.
@@ -42,12 +46,45 @@ type GoBackend struct {
 	refTpl           *template.Template
 	reflectionTpl    *template.Template
 	reflectionRefTpl *template.Template
+	registryTpl      *template.Template
+	indexTpl         *template.Template
+	outTpl           *template.Template
 	req              *plugin.Request
 	res              *plugin.Response
 	log              backend.LogFunc
 
-	utils *CodeUtils
-	funcs template.FuncMap
+	utils      *CodeUtils
+	funcs      template.FuncMap
+	registries map[string]bool     // the output paths that have a type registry
+	indexes    map[string][]*Scope // the scopes of the IDLs in each output path for gen_index
+	idlSums    map[*parser.Thrift]string
+	subpkgs    subpackages // the IDLs split out by go.package
+
+	filenameFunc FilenameFunc
+}
+
+// FilenameFunc computes the path of the go file generated for an IDL, relative
+// to the output location, from the IDL and its go namespace with '/' as the
+// separator, e.g. "acme/user". The other files generated for the IDL, such as
+// the -ref.go and the -reflection.go files, are written beside it.
+type FilenameFunc func(ast *parser.Thrift, namespace string) (string, error)
+
+// DefaultFilename is the layout used when no FilenameFunc is set: the file is
+// named after the IDL in the directory of the namespace, e.g.
+// "acme/user/user_service.go" for user_service.thrift.
+func DefaultFilename(ast *parser.Thrift, namespace string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(ast.Filename), filepath.Ext(ast.Filename)) + ".go"
+	if strings.HasSuffix(name, "_test.go") {
+		name = strings.ReplaceAll(name, "_test.go", "_test_.go")
+	}
+	return path.Join(namespace, name), nil
+}
+
+// SetFilenameFunc sets the function that computes the paths of the generated
+// files when thriftgo is used as a library. It can not be used with
+// --out-template or with {namespace} and {namespaceUnderscore} in --out.
+func (g *GoBackend) SetFilenameFunc(f FilenameFunc) {
+	g.filenameFunc = f
 }
 
 // Name implements the Backend interface.
@@ -71,6 +108,24 @@ func (g *GoBackend) Options() (opts []plugin.Option) {
 	return opts
 }
 
+// OptionType implements the backend.OptionTyper interface.
+func (g *GoBackend) OptionType(name string) string {
+	for _, p := range allParams {
+		if p.name == name {
+			if p.boolean {
+				return "bool"
+			}
+			return "string"
+		}
+	}
+	return ""
+}
+
+// ResolveOptions implements the backend.OptionResolver interface.
+func (g *GoBackend) ResolveOptions(opts []plugin.Option) (map[string]string, error) {
+	return resolveOptions(opts)
+}
+
 // BuiltinPlugins implements the Backend interface.
 func (g *GoBackend) BuiltinPlugins() []*plugin.Desc {
 	return nil
@@ -83,6 +138,11 @@ func (g *GoBackend) GetPlugin(desc *plugin.Desc) plugin.Plugin {
 
 // Generate implements the Backend interface.
 func (g *GoBackend) Generate(req *plugin.Request, log backend.LogFunc) *plugin.Response {
+	// The AST is pruned and rewritten below, so work on a copy of it to leave the
+	// request intact for the plugins.
+	cp := *req
+	cp.AST = req.AST.DeepCopy()
+	req = &cp
 	g.req = req
 	g.res = plugin.NewResponse()
 	g.log = log
@@ -98,6 +158,29 @@ func (g *GoBackend) Generate(req *plugin.Request, log backend.LogFunc) *plugin.R
 
 		g.log.Warn(fmt.Sprintf("structs:%d->%d (%.1f%% Trimmed),  fields:%d->%d (%.1f%% Trimmed).", tr.StructsTotal, tr.StructsLeft(), tr.StructTrimmedPercentage(), tr.FieldsTotal, tr.FieldsLeft(), tr.FieldTrimmedPercentage()))
 	}
+	if p := g.utils.Profile(); p != "" {
+		if err := pruneProfile(req.AST, p); err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+	}
+	if ss := g.utils.OnlyServices(); len(ss) > 0 {
+		if err := pruneServices(req.AST, ss); err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+	}
+	if g.utils.Features().InheritTypedefAnnotations {
+		if err := semantic.InheritTypedefAnnotations(req.AST); err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+	}
+	if err := g.utils.collectPackageNames(req.AST); err != nil {
+		return plugin.BuildErrorResponse(err.Error())
+	}
+	subpkgs, err := splitPackages(g.utils, req.AST)
+	if err != nil {
+		return plugin.BuildErrorResponse(err.Error())
+	}
+	g.subpkgs = subpkgs
 	g.prepareTemplates()
 	g.fillRequisitions()
 	if !g.utils.Features().ThriftStreaming {
@@ -117,6 +200,112 @@ func (g *GoBackend) prepareUtilities() {
 	if g.err != nil {
 		return
 	}
+	if text := g.req.GetOutputTemplate(); text != "" {
+		if g.outTpl, g.err = backend.ParseOutputTemplate(text); g.err != nil {
+			return
+		}
+		if g.err = g.setImportDirs(); g.err != nil {
+			return
+		}
+	}
+	if g.filenameFunc != nil && (g.outTpl != nil || strings.Contains(g.req.OutputPath, "{namespace")) {
+		g.err = fmt.Errorf("a FilenameFunc can not be used with --out-template or {namespace} in --out")
+		return
+	}
+	if f := g.utils.Features(); g.utils.Runtime() == apacheRuntime {
+		var name string
+		switch {
+		case f.CtxRW:
+			name = "ctx_rw"
+		case f.KeepUnknownFields:
+			name = "keep_unknown_fields"
+		case f.NoDefaultSerdes:
+			name = "no_default_serdes"
+		case g.utils.Template() != defaultTemplate:
+			name = "template=" + g.utils.Template()
+		}
+		if name != "" {
+			g.err = fmt.Errorf("runtime=apache can not be used with %s", name)
+			return
+		}
+	}
+	if f := g.utils.Features(); f.NoDefaultSerdes || g.utils.Template() != defaultTemplate {
+		var name string
+		switch {
+		case f.GenWriteTo:
+			name = "gen_write_to"
+		case f.GenBufferReuse:
+			name = "gen_buffer_reuse"
+		case f.GenByteSize:
+			name = "gen_byte_size"
+		case f.CtxRW:
+			name = "ctx_rw"
+		}
+		if name != "" {
+			g.err = fmt.Errorf("%s requires the Read and Write methods, which are not generated with no_default_serdes or a template other than the default one", name)
+			return
+		}
+	}
+
+	if f := g.utils.Features(); g.utils.PresenceBitset() {
+		var name string
+		switch {
+		case f.FrugalTag:
+			name = "frugal_tag"
+		case g.utils.Template() != defaultTemplate:
+			name = "template=" + g.utils.Template()
+		}
+		if name != "" {
+			g.err = fmt.Errorf("gen_presence=bitset can not be used with %s, which does not know the presence bits", name)
+			return
+		}
+	}
+	if ok, _ := g.utils.GoVersionAtLeast("1.9"); !ok {
+		// type aliases are introduced in go 1.9
+		if f := g.utils.Features(); f.TypedefAsType {
+			g.err = fmt.Errorf("typedef_as_type generates type aliases for typedefs of structs, which require go 1.9, but go_version is %s", g.utils.GoVersion())
+			return
+		} else if f.TypedefAsTypeAlias {
+			g.err = fmt.Errorf("use_type_alias generates type aliases, which require go 1.9, but go_version is %s, set use_type_alias=false to generate type definitions", g.utils.GoVersion())
+			return
+		} else if f.CodeRef || f.CodeRefSlim || f.ExpCodeRef {
+			g.err = fmt.Errorf("code_ref, code_ref_slim and exp_code_ref generate type aliases, which require go 1.9, but go_version is %s", g.utils.GoVersion())
+			return
+		}
+	}
+	if f := g.utils.Features(); f.GenOptionalAccessors && f.GenerateSetter {
+		g.err = fmt.Errorf("gen_optional_accessors can not be used with gen_setter, both of them generate the SetXXX methods")
+		return
+	}
+	if f := g.utils.Features(); (f.GenEnumSQL || f.EnumSQLAsString) && !f.ScanValueForEnum {
+		g.err = fmt.Errorf("gen_enum_sql and enum_sql_as_string can not be used with scan_value_for_enum=false, they change the Scan and Value methods that it generates")
+		return
+	}
+	if f := g.utils.Features(); f.GenByteSize && f.WithFieldMask {
+		g.err = fmt.Errorf("gen_byte_size can not be used with with_field_mask, BytesLength does not know the fields that a field mask leaves out")
+		return
+	}
+	if f := g.utils.Features(); len(g.utils.Protocols()) > 0 {
+		var name string
+		switch {
+		case f.WithFieldMask:
+			name = "with_field_mask, which the Append and Read methods do not know"
+		case f.KeepUnknownFields:
+			name = "keep_unknown_fields, which the Append and Read methods do not know"
+		case f.GenBufferReuse && g.utils.Protocols()[0] == "Binary":
+			name = "gen_buffer_reuse, both of them generate the AppendBinary methods"
+		case g.utils.Template() == "raw_struct":
+			name = "template=raw_struct, which does not generate the methods of structs"
+		}
+		if name != "" {
+			g.err = fmt.Errorf("protocols can not be used with %s", name)
+			return
+		}
+	}
+	if g.utils.Features().GenTypeRegistry && g.utils.Template() == "raw_struct" {
+		g.err = fmt.Errorf("gen_type_registry registers the New functions of structs, which are not generated with template=raw_struct")
+		return
+	}
 
 	g.funcs = g.utils.BuildFuncMap()
 	g.funcs["Version"] = func() string { return g.req.Version }
@@ -139,11 +328,18 @@ func (g *GoBackend) prepareTemplates() {
 	for _, tpl := range tpls {
 		all = template.Must(all.Parse(tpl))
 	}
+	if dir := g.utils.TemplateDir(); dir != "" {
+		if g.err = overrideTemplates(all, dir); g.err != nil {
+			return
+		}
+	}
 	g.tpl = all
 
 	g.refTpl = template.Must(template.New("thrift-ref").Funcs(g.funcs).Parse(ref_tpl.File))
 	g.reflectionTpl = template.Must(template.New("thrift-reflection").Funcs(g.funcs).Parse(reflection_tpl.File))
 	g.reflectionRefTpl = template.Must(template.New("thrift-reflection-util").Funcs(g.funcs).Parse(reflection_tpl.FileRef))
+	g.registryTpl = template.Must(template.New("thrift-type-registry").Funcs(g.funcs).Parse(templates.TypeRegistry))
+	g.indexTpl = template.Must(template.New("thrift-package-index").Funcs(g.funcs).Parse(templates.PackageIndex))
 }
 
 func (g *GoBackend) fillRequisitions() {
@@ -158,6 +354,9 @@ func (g *GoBackend) executeTemplates() {
 	}
 
 	processed := make(map[*parser.Thrift]bool)
+	g.registries = make(map[string]bool)
+	g.indexes = make(map[string][]*Scope)
+	g.idlSums = make(map[*parser.Thrift]string)
 
 	var trees chan *parser.Thrift
 	if g.req.Recursive {
@@ -169,22 +368,60 @@ func (g *GoBackend) executeTemplates() {
 	}
 
 	for ast := range trees {
-		if processed[ast] {
+		if processed[ast] || g.subpkgs.parentOf(ast) != ast {
 			continue
 		}
 		processed[ast] = true
+		if ast != g.req.AST && g.utils.IsExcluded(ast.Filename) {
+			g.log.Info("Excluded", ast.Filename)
+			continue
+		}
 		g.log.Info("Processing", ast.Filename)
 
 		if g.err = g.renderOneFile(ast); g.err != nil {
 			break
 		}
+		// the declarations moved by go.package are generated with their IDL
+		for _, sub := range g.subpkgs[ast] {
+			if g.err = g.renderOneFile(sub); g.err != nil {
+				return
+			}
+		}
+	}
+	if g.err == nil && g.utils.Features().GenIndex {
+		g.err = g.renderIndexes()
 	}
 }
 
+// renderIndexes generates the index of each package once all its IDLs are known.
+func (g *GoBackend) renderIndexes() error {
+	paths := make([]string, 0, len(g.indexes))
+	for path := range g.indexes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		scopes := g.indexes[path]
+		index := &packageIndex{Scope: scopes[0], Scopes: scopes}
+		if err := g.renderWith(scopes[0], index, g.indexTpl, filepath.Join(path, IndexFilename)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packageIndex is the data of the template of gen_index.
+type packageIndex struct {
+	*Scope
+	Scopes []*Scope // the scopes of the IDLs of the package
+}
+
 func (g *GoBackend) renderOneFile(ast *parser.Thrift) error {
 	keepName := g.utils.Features().KeepCodeRefName
-	path := g.utils.CombineOutputPath(g.req.OutputPath, ast)
-	filename := filepath.Join(path, g.utils.GetFilename(ast))
+	path, filename, err := g.outputFile(ast)
+	if err != nil {
+		return err
+	}
 	localScope, refScope, err := BuildRefScope(g.utils, ast)
 	if err != nil {
 		return err
@@ -197,6 +434,17 @@ func (g *GoBackend) renderOneFile(ast *parser.Thrift) error {
 	if err != nil {
 		return err
 	}
+	// the IDLs of a package share one registry
+	if g.utils.Features().GenTypeRegistry && !g.registries[path] && localScope.hasRegistrableTypes() {
+		g.registries[path] = true
+		err = g.renderByTemplate(localScope, g.registryTpl, filepath.Join(path, TypeRegistryFilename))
+		if err != nil {
+			return err
+		}
+	}
+	if g.utils.Features().GenIndex && localScope.hasRegistrableTypes() {
+		g.indexes[path] = append(g.indexes[path], localScope)
+	}
 	if g.utils.Features().WithReflection {
 		err = g.renderByTemplate(refScope, g.reflectionRefTpl, ToReflectionRefFilename(keepName, filename))
 		if err != nil {
@@ -207,6 +455,83 @@ func (g *GoBackend) renderOneFile(ast *parser.Thrift) error {
 	return nil
 }
 
+// outputFile returns the directory and the path of the file generated for the
+// IDL, which are computed by the FilenameFunc if any.
+func (g *GoBackend) outputFile(ast *parser.Thrift) (dir, filename string, err error) {
+	if g.filenameFunc == nil {
+		if dir, err = g.outputDir(ast); err != nil {
+			return "", "", err
+		}
+		return dir, filepath.Join(dir, g.utils.GetFilename(ast)), nil
+	}
+	_, _, ns := g.utils.ParseNamespace(ast)
+	rel, err := g.filenameFunc(ast, ns)
+	if err == nil && !strings.HasSuffix(rel, ".go") {
+		err = fmt.Errorf("expect a .go file, got %q", rel)
+	}
+	if err == nil {
+		filename, err = backend.OutputPath(g.req.OutputPath, rel, ast.Filename)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("filename of %s: %w", ast.Filename, err)
+	}
+	return filepath.Dir(filename), filename, nil
+}
+
+// outputDir returns the directory of the files generated for the IDL, which is
+// computed by the template given by --out-template if any.
+func (g *GoBackend) outputDir(ast *parser.Thrift) (string, error) {
+	if g.outTpl == nil {
+		return g.utils.CombineOutputPath(g.req.OutputPath, ast), nil
+	}
+	_, _, ns := g.utils.ParseNamespace(ast)
+	dir, err := backend.OutputDir(g.outTpl, g.req.OutputPath, backend.NewOutputDirData(ast.Filename, ns, "go"))
+	if err != nil {
+		return "", fmt.Errorf("out-template: %w", err)
+	}
+	return dir, nil
+}
+
+// setImportDirs makes the packages imported by the directories that the
+// template given by --out-template computes for their IDLs. The IDLs of a go
+// namespace must be written to one directory that no other namespace uses, as
+// a namespace is a package in the generated codes.
+func (g *GoBackend) setImportDirs() error {
+	dirs := make(map[string]*parser.Thrift) // directory => the first IDL written to it
+	idls := make(map[string]*parser.Thrift) // go namespace => the first IDL of it
+	for ast := range g.req.AST.DepthFirstSearch() {
+		_, _, pth := g.utils.ParseNamespace(ast)
+		dir, err := backend.OutputDir(g.outTpl, "", backend.NewOutputDirData(ast.Filename, pth, "go"))
+		if err != nil {
+			return fmt.Errorf("out-template: %w", err)
+		}
+		dir = filepath.ToSlash(dir)
+		ns := g.utils.GoNamespace(ast)
+		if first, ok := idls[ns]; ok {
+			if prev := g.utils.importDirs[ns]; prev != dir {
+				return fmt.Errorf("out-template: %s and %s have the same go namespace %q but are written to different directories %q and %q",
+					first.Filename, ast.Filename, ns, prev, dir)
+			}
+			continue
+		}
+		if first, ok := dirs[dir]; ok {
+			return fmt.Errorf("out-template: %s and %s have different go namespaces %q and %q but are written to the same directory %q",
+				first.Filename, ast.Filename, g.utils.GoNamespace(first), ns, dir)
+		}
+		idls[ns], dirs[dir] = ast, ast
+		g.utils.SetImportDir(ns, dir)
+	}
+	return nil
+}
+
+// TypeRegistryFilename is the name of the file that holds the type registry of a
+// package with gen_type_registry.
+const TypeRegistryFilename = "type-registry.go"
+
+// IndexFilename is the name of the file that lists the types of a package with
+// gen_index.
+const IndexFilename = "index.go"
+
 func ToRefFilename(keepName bool, filename string) string {
 	if keepName {
 		return filename
@@ -225,6 +550,10 @@ func ToReflectionRefFilename(keepName bool, filename string) string {
 	return strings.TrimSuffix(filename, ".go") + "-reflection-ref.go"
 }
 
+func (s *Scope) hasRegistrableTypes() bool {
+	return s != nil && len(s.enums)+len(s.structs)+len(s.unions)+len(s.exceptions) > 0
+}
+
 func (s *Scope) IsEmpty() bool {
 	if len(s.constants) == 0 &&
 		len(s.typedefs) == 0 &&
@@ -240,6 +569,12 @@ func (s *Scope) IsEmpty() bool {
 }
 
 func (g *GoBackend) renderByTemplate(scope *Scope, executeTpl *template.Template, filename string) error {
+	return g.renderWith(scope, scope, executeTpl, filename)
+}
+
+// renderWith is like renderByTemplate but executes the template with data, the
+// scope being the root scope of the file.
+func (g *GoBackend) renderWith(scope *Scope, data interface{}, executeTpl *template.Template, filename string) error {
 
 	if scope == nil {
 		return nil
@@ -251,9 +586,25 @@ func (g *GoBackend) renderByTemplate(scope *Scope, executeTpl *template.Template
 		}
 	}
 
+	header, err := g.utils.FileHeader(scope.AST().Filename, g.req.Version)
+	if err != nil {
+		return err
+	}
 	var buf strings.Builder
+	if g.utils.Features().GenChecksum {
+		// filled by the generator once the content is final
+		buf.WriteString(generator.ChecksumPrefix + "\n")
+	}
+	if g.utils.Features().GenIDLChecksum {
+		sum, err := g.idlChecksum(scope.AST())
+		if err != nil {
+			return err
+		}
+		buf.WriteString(generator.IDLChecksumPrefix + " " + sum + "\n")
+	}
+	buf.WriteString(header)
 	g.utils.SetRootScope(scope)
-	err := executeTpl.ExecuteTemplate(&buf, executeTpl.Name(), scope)
+	err = executeTpl.ExecuteTemplate(&buf, executeTpl.Name(), data)
 	if err != nil {
 		return fmt.Errorf("%s: %w", filename, err)
 	}
@@ -278,6 +629,28 @@ func (g *GoBackend) renderByTemplate(scope *Scope, executeTpl *template.Template
 	return nil
 }
 
+// idlChecksum returns the checksum of the IDL and its includes, which is shared
+// by all the files generated for the IDL.
+func (g *GoBackend) idlChecksum(ast *parser.Thrift) (string, error) {
+	ast = g.subpkgs.parentOf(ast)
+	if sum, ok := g.idlSums[ast]; ok {
+		return sum, nil
+	}
+	// the IDLs split out of the others have no sources of their own
+	var asts []*parser.Thrift
+	for t := range ast.DepthFirstSearch() {
+		if g.subpkgs.parentOf(t) == t {
+			asts = append(asts, t)
+		}
+	}
+	sum, err := generator.IDLChecksum(asts...)
+	if err != nil {
+		return "", err
+	}
+	g.idlSums[ast] = sum
+	return sum, nil
+}
+
 func (g *GoBackend) buildResponse() *plugin.Response {
 	if g.err != nil {
 		return plugin.BuildErrorResponse(g.err.Error())
@@ -286,22 +659,34 @@ func (g *GoBackend) buildResponse() *plugin.Response {
 }
 
 // PostProcess implements the backend.PostProcessor interface to do
-// source formatting before writing files out.
+// source formatting before writing files out. A file that fails to be
+// formatted is written as it is with a warning.
 func (g *GoBackend) PostProcess(path string, content []byte) ([]byte, error) {
 	if g.utils.Features().NoFmt {
 		return content, nil
 	}
-	switch filepath.Ext(path) {
-	case ".go":
-		if formated, err := format.Source(content); err != nil {
-			g.log.Warn(fmt.Sprintf("Failed to format %s: %s", path, err.Error()))
-		} else {
-			content = formated
-		}
+	if formated, err := formatSource(path, content); err != nil {
+		g.log.Warn(fmt.Sprintf("Failed to format %s: %s", path, err.Error()))
+	} else {
+		content = formated
 	}
 	return content, nil
 }
 
+// CheckFormat implements the backend.FormatChecker interface. It is used
+// instead of PostProcess with --no-format, which also implies go:no_fmt.
+func (g *GoBackend) CheckFormat(path string, content []byte) error {
+	_, err := formatSource(path, content)
+	return err
+}
+
+func formatSource(path string, content []byte) ([]byte, error) {
+	if filepath.Ext(path) != ".go" {
+		return content, nil
+	}
+	return format.Source(content)
+}
+
 func (g *GoBackend) removeStreamingFunctions(ast *parser.Thrift) {
 	for _, svc := range ast.Services {
 		functions := make([]*parser.Function, 0, len(svc.Functions))
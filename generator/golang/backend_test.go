@@ -0,0 +1,2987 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/generator"
+	"github.com/cloudwego/thriftgo/generator/backend"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/plugin"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// generate writes the IDLs into a temporary directory and runs the go backend on
+// the first one. It returns the generated contents keyed by the relative file path.
+func generate(t *testing.T, idls [][2]string, opts ...string) (map[string]string, error) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	return generateIn(t, dir, idls, opts...)
+}
+
+// generateIn is like generate but writes the IDLs into dir.
+func generateIn(t *testing.T, dir string, idls [][2]string, opts ...string) (map[string]string, error) {
+	return generateFiles(t, dir, idls, true, opts...)
+}
+
+// generateFiles is like generateIn but generates the includes only if recursive
+// is true.
+func generateFiles(t *testing.T, dir string, idls [][2]string, recursive bool, opts ...string) (map[string]string, error) {
+	return generateWith(t, new(GoBackend), dir, idls, recursive, opts...)
+}
+
+// generateWith is like generateFiles but generates with the given backend.
+func generateWith(t *testing.T, be *GoBackend, dir string, idls [][2]string, recursive bool, opts ...string) (map[string]string, error) {
+	return generateRequest(t, be, dir, idls, &plugin.Request{Recursive: recursive}, opts...)
+}
+
+// generateRequest is like generateWith, but takes the fields of the request other
+// than the AST and the output path from req.
+func generateRequest(t *testing.T, be *GoBackend, dir string, idls [][2]string, req *plugin.Request, opts ...string) (map[string]string, error) {
+	for _, idl := range idls {
+		if err := ioutil.WriteFile(filepath.Join(dir, idl[0]), []byte(idl[1]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ast, err := parser.ParseFile(filepath.Join(dir, idls[0][0]), nil, true)
+	if err != nil {
+		return nil, err
+	}
+	checker := semantic.NewChecker(semantic.Options{})
+	if _, err = checker.CheckAll(ast); err != nil {
+		return nil, err
+	}
+	if err = semantic.ResolveSymbols(ast); err != nil {
+		return nil, err
+	}
+
+	nop := func(v ...interface{}) {}
+	log := backend.LogFunc{Info: nop, Warn: nop, MultiWarn: func(ws []string) {}}
+	out := &generator.LangSpec{Language: "go"}
+	for _, opt := range opts {
+		kv := strings.SplitN(opt, "=", 2)
+		out.Options = append(out.Options, plugin.Option{Name: kv[0], Desc: strings.Join(kv[1:], "")})
+	}
+	req.Language, req.Version = "go", "?"
+	req.OutputPath, req.AST = filepath.Join(dir, "gen-go"), ast
+	var g generator.Generator
+	if err = g.RegisterBackend(be); err != nil {
+		t.Fatal(err)
+	}
+	res := g.Generate(&generator.Arguments{Out: out, Req: req, Log: log})
+	if res.Error != nil {
+		return nil, errors.New(res.GetError())
+	}
+	files := make(map[string]string)
+	for _, c := range res.Contents {
+		content, err := be.PostProcess(c.GetName(), []byte(c.Content))
+		if err != nil {
+			return nil, err
+		}
+		name, _ := filepath.Rel(req.OutputPath, c.GetName())
+		files[filepath.ToSlash(name)] = string(content)
+	}
+	return files, nil
+}
+
+// cliOptions returns the options of the go backend in the argument of -g, split
+// like the command line does, in the form of the opts of generate.
+func cliOptions(t *testing.T, arg string) (opts []string) {
+	desc, err := plugin.ParseCompactArguments(arg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range desc.Options {
+		opts = append(opts, o.Name+"="+o.Desc)
+	}
+	return opts
+}
+
+func mustGenerate(t *testing.T, idls [][2]string, opts ...string) map[string]string {
+	files, err := generate(t, idls, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestImportAlias(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "a.thrift"
+include "b.thrift"
+struct S {
+	1: a.A x
+	2: b.B y
+}`},
+		{"a.thrift", `
+namespace go example.a.base (go.import.alias = "abase")
+struct A {}`},
+		{"b.thrift", `
+namespace go example.b.base
+struct B {}`},
+	}
+
+	files := mustGenerate(t, idls)
+	main := files["example/main/main.go"]
+	if !strings.Contains(main, `abase "example/a/base"`) || !strings.Contains(main, "X *abase.A") {
+		t.Fatalf("annotated alias not used:\n%s", main)
+	}
+	if !strings.Contains(main, `"example/b/base"`) || !strings.Contains(main, "Y *base.B") {
+		t.Fatalf("unexpected import of b:\n%s", main)
+	}
+
+	files = mustGenerate(t, idls, "import_alias=example.b.base=bbase")
+	main = files["example/main/main.go"]
+	if !strings.Contains(main, `bbase "example/b/base"`) || !strings.Contains(main, "Y *bbase.B") {
+		t.Fatalf("option alias not used:\n%s", main)
+	}
+
+	_, err := generate(t, idls, "import_alias=example.b.base=abase")
+	if err == nil || !strings.Contains(err.Error(), `import alias "abase"`) || strings.Contains(err.Error(), "stack") {
+		t.Fatalf("expect a conflict error, got %v", err)
+	}
+
+	for _, alias := range []string{"a-b", "type", "string", "_"} {
+		invalid := [][2]string{idls[0], {"a.thrift", `namespace go example.a.base (go.import.alias = "` + alias + `")
+struct A {}`}, idls[2]}
+		_, err = generate(t, invalid)
+		if err == nil || !strings.Contains(err.Error(), `a.thrift: invalid go.import.alias "`+alias+`"`) || strings.Contains(err.Error(), "stack") {
+			t.Fatalf("expect an invalid alias error for %q, got %v", alias, err)
+		}
+		_, err = generate(t, idls, "import_alias=example.b.base="+alias)
+		if err == nil || !strings.Contains(err.Error(), "invalid import alias") {
+			t.Fatalf("expect an invalid alias error for %q, got %v", alias, err)
+		}
+	}
+
+	_, err = generate(t, idls, "import_alias=example.b.base=x", "import_alias=example.b.base=y")
+	if err == nil || !strings.Contains(err.Error(), "conflicting import aliases") {
+		t.Fatalf("expect a conflict error, got %v", err)
+	}
+}
+
+func TestImportReplace(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "a.thrift"
+include "b.thrift"
+struct S {
+	1: a.A x
+	2: b.B y
+}`},
+		{"a.thrift", `
+namespace go example.a.base
+struct A {}`},
+		{"b.thrift", `
+namespace go example.b.base
+struct B {}`},
+	}
+
+	main := mustGenerate(t, idls,
+		"import_replace=example=internal/vendor/example",
+		"import_replace=example/b=lib/b/",
+		"import_replace=example/a/ba=wrong",
+		"import_replace=github.com/apache/thrift=internal/vendor/thrift",
+	)["example/main/main.go"]
+	for _, s := range []string{
+		`"internal/vendor/example/a/base"`,
+		`"lib/b/base"`,
+		`"internal/vendor/thrift/lib/go/thrift"`,
+		"X *base.A",
+		"Y *base0.B",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %s in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, `"example/`) || strings.Contains(main, "wrong") {
+		t.Fatalf("unexpected import path in:\n%s", main)
+	}
+
+	// a replacement of the whole path by use_package takes precedence
+	main = mustGenerate(t, idls, "import_replace=example=vendor", "use_package=example/a/base=a/base")["example/main/main.go"]
+	if !strings.Contains(main, `"a/base"`) || !strings.Contains(main, `"vendor/b/base"`) {
+		t.Fatalf("unexpected imports in:\n%s", main)
+	}
+
+	if _, err := generate(t, idls, "import_replace=example"); err == nil || !strings.Contains(err.Error(), "invalid argument for import_replace") {
+		t.Fatalf("expect an invalid argument error, got %v", err)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.service.v1 (go.package_name = "api")
+include "base.thrift"
+struct S { 1: base.B b }`},
+		{"base.thrift", `
+namespace go example.common.v2
+struct B {}`},
+	}
+
+	files := mustGenerate(t, idls)
+	main := files["example/service/v1/main.go"]
+	if !strings.HasPrefix(strings.SplitN(main, "package ", 2)[1], "api\n") {
+		t.Fatalf("expect package api in:\n%s", main)
+	}
+	if !strings.Contains(main, `"example/common/v2"`) || !strings.Contains(main, "B *v2.B") {
+		t.Fatalf("unexpected import of base in:\n%s", main)
+	}
+
+	// the import alias follows the package name
+	main = mustGenerate(t, idls, "package_name=example.common.v2=common")["example/service/v1/main.go"]
+	if !strings.Contains(main, `common "example/common/v2"`) || !strings.Contains(main, "B *common.B") {
+		t.Fatalf("expect the package name as the import alias in:\n%s", main)
+	}
+
+	for _, c := range []struct {
+		ns, opt, err string
+	}{
+		{`(go.package_name = "1api")`, "", `main.thrift: invalid go.package_name "1api", it must be a go identifier`},
+		{`(go.package_name = "a", go.package_name = "b")`, "", "main.thrift: go.package_name is set more than once"},
+		{"", "package_name=example.service.v1=a-b", `invalid package name "a-b" for namespace "example.service.v1"`},
+	} {
+		bad := [][2]string{{"main.thrift", "namespace go example.service.v1 " + c.ns}}
+		var opts []string
+		if c.opt != "" {
+			opts = append(opts, c.opt)
+		}
+		if _, err := generate(t, bad, opts...); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect %q, got %v", c.err, err)
+		}
+	}
+
+	// the IDLs of a namespace must agree on the name
+	conflict := [][2]string{
+		{"main.thrift", `
+namespace go example (go.package_name = "a")
+include "other.thrift"`},
+		{"other.thrift", `namespace go example (go.package_name = "b")`},
+	}
+	if _, err := generate(t, conflict); err == nil || !strings.Contains(err.Error(), `main.thrift: go.package_name "a" conflicts with "b" in`) {
+		t.Fatalf("expect a conflict error, got %v", err)
+	}
+	// the option takes precedence over the annotations
+	if _, err := generate(t, conflict, "package_name=example=c"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenEnumValues(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Status {
+	Running = 3
+	Idle = 1
+	Stopped = 2
+}`}}
+
+	files := mustGenerate(t, idls)
+	if strings.Contains(files["example/main.go"], "StatusValues") {
+		t.Fatal("StatusValues should not be generated by default")
+	}
+
+	files = mustGenerate(t, idls, "gen_enum_values")
+	main := files["example/main.go"]
+	for _, s := range []string{
+		"var StatusNames = []string{\n\t\"Running\",\n\t\"Idle\",\n\t\"Stopped\",\n}",
+		"func StatusValues() []Status {\n\treturn []Status{\n\t\tStatus_Running,\n\t\tStatus_Idle,\n\t\tStatus_Stopped,\n\t}\n}",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenEnumSQL(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Status {
+	Running = 1
+	Idle = 2
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if s := "func (p *Status) Scan(value interface{}) (err error) {"; !strings.Contains(main, s) {
+		t.Fatalf("expect %q of scan_value_for_enum by default in:\n%s", s, main)
+	}
+
+	main = mustGenerate(t, idls, "gen_enum_sql")["example/main.go"]
+	for _, s := range []string{
+		"func (p *Status) Scan(src interface{}) error {\n\tvar v sql.NullInt64\n",
+		"func (p Status) Value() (driver.Value, error) {\n\treturn int64(p), nil\n}",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if s := "func (p *Status) Value()"; strings.Contains(main, s) {
+		t.Fatalf("unexpected %q of scan_value_for_enum in:\n%s", s, main)
+	}
+
+	main = mustGenerate(t, idls, "enum_sql_as_string")["example/main.go"]
+	for _, s := range []string{
+		"\tcase nil:\n\t\t*p = 0\n\t\treturn nil\n",
+		"\tq, err := StatusFromString(s)\n",
+		"func (p Status) Value() (driver.Value, error) {\n\ts := p.String()\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, `"database/sql"`) {
+		t.Fatalf("unexpected import of database/sql in:\n%s", main)
+	}
+
+	// the options change the methods of scan_value_for_enum rather than adding their own
+	for _, opt := range []string{"gen_enum_sql", "enum_sql_as_string"} {
+		_, err := generate(t, idls, opt, "scan_value_for_enum=false")
+		if err == nil || !strings.Contains(err.Error(), "can not be used with scan_value_for_enum=false") {
+			t.Fatalf("expect an error for %s, got %v", opt, err)
+		}
+	}
+}
+
+func TestInheritTypedefAnnotations(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "base.thrift"
+typedef base.Email WorkEmail (go.tag = 'json:"work_email" validate:"email"')
+struct User {
+	1: base.Email email
+	2: WorkEmail work
+}
+struct Contact {
+	1: base.Email email (go.tag = 'json:"mail"')
+	2: optional list<base.Email> others
+}`},
+		{"base.thrift", `
+namespace go example.base
+typedef string Email (go.tag = 'json:"email" validate:"email"')`},
+	}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if s := `validate:"email"`; strings.Contains(main, s) {
+		t.Fatalf("unexpected %q without inherit_typedef_annotations in:\n%s", s, main)
+	}
+
+	main = mustGenerate(t, idls, "inherit_typedef_annotations")["example/main.go"]
+	for _, s := range []string{
+		"\tEmail base.Email `thrift:\"email,1\" json:\"email\" validate:\"email\"`\n\tWork  WorkEmail  `thrift:\"work,2\" json:\"work_email\" validate:\"email\"`\n",
+		"\tEmail  base.Email   `thrift:\"email,1\" json:\"mail\"`\n",
+		// the typedef of the elements does not apply to the field
+		"\tOthers []base.Email `thrift:\"others,2,optional\" json:\"others,omitempty\"`\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenServiceIface(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.svc
+include "base.thrift"
+service Foo extends base.Base {
+	void Do(1: base.Req req)
+	oneway void Notify(1: i32 x)
+}`},
+		{"base.thrift", `
+namespace go example.base
+struct Req {}
+service Base {
+	string Ping(1: Req req)
+}`},
+	}
+
+	files := mustGenerate(t, idls)
+	if strings.Contains(files["example/svc/main.go"], "FooIface") {
+		t.Fatal("FooIface should not be generated by default")
+	}
+
+	files = mustGenerate(t, idls, "gen_service_iface")
+	for name, expects := range map[string][]string{
+		"example/svc/main.go": {
+			"type FooIface interface {\n\tbase.BaseIface\n",
+			"\tDo(ctx context.Context, req *base.Req) (err error)\n",
+			"\tNotify(ctx context.Context, x int32)\n",
+		},
+		"example/base/base.go": {
+			"type BaseIface interface {\n",
+			"\tPing(ctx context.Context, req *Req) (r string, err error)\n",
+		},
+	} {
+		for _, s := range expects {
+			if !strings.Contains(files[name], s) {
+				t.Fatalf("expect %q in %s:\n%s", s, name, files[name])
+			}
+		}
+	}
+}
+
+func TestOnlyService(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.svc
+include "other.thrift"
+struct Shared {}
+struct Standalone { 1: Nested n }
+struct Nested {}
+struct FooReq { 1: Shared s }
+struct BarReq { 1: other.Other o }
+typedef BarReq BarReqAlias
+service Foo {
+	void Do(1: FooReq req, 2: Shared s)
+}
+service Bar {
+	void Do(1: BarReqAlias req, 2: Shared s)
+}
+service Empty {}`},
+		{"other.thrift", `
+namespace go example.other
+struct Other {}`},
+	}
+
+	has := func(src string, names ...string) bool {
+		for _, n := range names {
+			if !strings.Contains(src, "type "+n+" ") {
+				return false
+			}
+		}
+		return true
+	}
+
+	main := mustGenerate(t, idls, "only_service=Foo")["example/svc/main.go"]
+	if !has(main, "Foo", "FooReq", "Shared", "Standalone", "Nested") {
+		t.Fatalf("missing definitions:\n%s", main)
+	}
+	if has(main, "Bar") || has(main, "BarReq") || has(main, "BarReqAlias") || has(main, "Empty") ||
+		strings.Contains(main, `"example/other"`) {
+		t.Fatalf("unexpected definitions:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "only_service=Empty")["example/svc/main.go"]
+	if !has(main, "Empty", "Standalone", "Nested") || has(main, "Shared") || has(main, "FooReq") {
+		t.Fatalf("unexpected definitions:\n%s", main)
+	}
+
+	_, err := generate(t, idls, "only_service=Baz")
+	if err == nil || !strings.Contains(err.Error(), `service "Baz" not found`) {
+		t.Fatalf("expect an error, got %v", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "common.thrift"
+struct User {
+	1: required string name
+	2: optional common.Audit audit (profiles = "internal")
+	3: optional string email (profiles = "internal, public")
+}
+struct Secret { 1: string key } (profiles = "internal")
+service Svc {
+	User Get(1: string name)
+	Secret GetSecret() (profiles = "internal")
+}`},
+		{"common.thrift", `
+namespace go example.common
+struct Audit { 1: string who } (profiles = "internal")`},
+	}
+
+	main := mustGenerate(t, idls)["example/main/main.go"]
+	for _, s := range []string{"Audit *common.Audit", "type Secret struct", "GetSecret(ctx context.Context)"} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	main = mustGenerate(t, idls, "profile=public")["example/main/main.go"]
+	for _, s := range []string{
+		"Email *string `thrift:\"email,3,optional\" json:\"email,omitempty\"`",
+		"Get(ctx context.Context, name string) (r *User, err error)",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	for _, s := range []string{"Audit", "Secret", `"example/common"`} {
+		if strings.Contains(main, s) {
+			t.Fatalf("unexpected %q in:\n%s", s, main)
+		}
+	}
+
+	idls[0][1] = `
+namespace go example.main
+include "common.thrift"
+struct User { 1: required string name (profiles = "internal") }`
+	_, err := generate(t, idls, "profile=public")
+	if err == nil || !strings.Contains(err.Error(), `profile "public" omits the required field "name" of struct "User"`) {
+		t.Fatalf("expect an error, got %v", err)
+	}
+
+	idls[0][1] = `
+namespace go example.main
+include "common.thrift"
+struct User { 1: optional list<common.Audit> audits }`
+	_, err = generate(t, idls, "profile=public")
+	if err == nil || !strings.Contains(err.Error(), `field "audits" of struct "User" refers to "common.Audit", which is omitted by profile "public"`) {
+		t.Fatalf("expect an error, got %v", err)
+	}
+}
+
+func TestRequestIntact(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+typedef string Email (go.tag = 'json:"email"')
+struct User {
+	1: Email email
+	2: string key (profiles = "internal")
+}
+struct Page { 1: i32 n } (go.package = "page")
+service Svc { User Get(1: Page p) }
+service Admin { void Reset() (profiles = "internal") }`}}
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	req := &plugin.Request{}
+	_, err = generateRequest(t, new(GoBackend), dir, idls, req,
+		"profile=public", "only_service=Svc", "inherit_typedef_annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the AST of the request is left as it is for the plugins
+	ast := req.AST
+	if len(ast.Services) != 2 || len(ast.Structs) != 2 || len(ast.Structs[0].Fields) != 2 {
+		t.Fatalf("the AST is pruned: %v", ast)
+	}
+	if len(ast.Structs[0].Fields[0].Annotations) != 0 || len(ast.Includes) != 0 {
+		t.Fatalf("the AST is rewritten: %v", ast)
+	}
+}
+
+func TestIDLChecksum(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Page { 1: i32 n } (go.package = "page")
+struct S { 1: Page p }`}}
+
+	files := mustGenerate(t, idls, "gen_idl_checksum")
+	main, page := files["example/main.go"], files["example/page/main.go"]
+	if !strings.HasPrefix(main, "// IDL checksum: sha256:") {
+		t.Fatalf("expect the IDL checksum in:\n%s", main)
+	}
+	// the files split out of an IDL share its checksum
+	if line := main[:strings.Index(main, "\n")+1]; !strings.HasPrefix(page, line) {
+		t.Fatalf("expect %q in:\n%s", line, page)
+	}
+}
+
+func TestImplements(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct User {
+	1: required i64 id
+	2: string name
+	3: optional string email
+} (go.implements = "Entity", go.implements = "Named(name)")
+struct Group {
+	1: i64 id
+	2: optional string name
+	3: list<i64> members
+} (go.implements = "Entity")
+union Tag {
+	1: string name
+	2: i32 code
+} (go.implements = "Named(name)")`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"type Entity interface {\n\tGetID() int64\n\tGetName() string\n}",
+		"_ Entity = (*User)(nil)\n\t_ Entity = (*Group)(nil)",
+		"type Named interface {\n\tGetName() string\n}",
+		"_ Named = (*User)(nil)\n\t_ Named = (*Tag)(nil)",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	for _, c := range []struct{ idl, err string }{
+		{`struct A { 1: i64 id } (go.implements = "Entity")
+struct B { 1: i32 id } (go.implements = "Entity")`,
+			`main.thrift:2:1): the getter of "id" for the interface "Entity" is GetID() int32, but it is GetID() int64 in struct "A"`},
+		{`struct A { 1: i64 id } (go.implements = "Entity(id, name)")`,
+			`main.thrift:1:1): the field "name" listed by the interface "Entity" is not found`},
+		{`struct A { 1: i64 id } (go.implements = "Entity(id)")
+struct B { 1: i64 id } (go.implements = "Entity")`,
+			`the interface "Entity" is declared with the fields (id) by "A" but () here`},
+		{`struct A { 1: i64 id } (go.implements = "Entity")
+struct B { 1: i64 key } (go.implements = "Entity")`,
+			`the interface "Entity" has no getter shared by all of its implementations`},
+		{`struct A { 1: i64 id } (go.implements = "B")
+struct B {}`,
+			`the name of the interface "B" in go.implements is already used in go`},
+		{`struct A { 1: i64 id } (go.implements = "a.B")`,
+			`invalid interface name in go.implements "a.B"`},
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", c.idl}})
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect an error with %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestGoTags(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S {
+	1: string a (go.tag = 'gorm:"column:a"', go.tag = 'validate:"eq=\"x\""')
+	2: string b (go.tag = 'json:\"b\"')
+	3: optional string c (go.tag = 'json:"cc,omitempty" db:"c"')
+}`}}
+
+	main := mustGenerate(t, idls, "always_gen_json_tag")["example/main.go"]
+	for _, s := range []string{
+		"`thrift:\"a,1\" json:\"a\" gorm:\"column:a\" validate:\"eq=\\\"x\\\"\"`",
+		"`thrift:\"b,2\" json:\"b\"`",
+		"`thrift:\"c,3,optional\" json:\"cc,omitempty\" db:\"c\"`",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %s in:\n%s", s, main)
+		}
+	}
+
+	for _, c := range []struct{ tag, err string }{
+		{"(go.tag = 'json:\"a\"', go.tag = 'json:\"b\"')", "key 'json' is set more than once by go.tag"},
+		{"(go.tag = 'json:\"a\",db:\"b\"')", "expect a space after the value of 'json'"},
+		{"(go.tag = 'json:a')", "expect key:\"value\" at 'json:a'"},
+		{"(go.tag = 'json:\"a')", "unterminated value of 'json'"},
+		{"(go.tag = 'json:\"`a`\"')", "a struct tag can not contain a backtick"},
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", "struct S { 1: string a " + c.tag + " }"}})
+		if err == nil || !strings.Contains(err.Error(), "go.tag of field 'a': ") || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect an error with %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestExceptionError(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+typedef string Text
+exception A { 1: Text Message }
+exception B { 1: i32 code, 2: optional string msg }
+exception C { 1: string msg, 2: string detail } (go.error_message = "detail")
+exception D { 1: string msg } (go.error_message = "")
+exception E { 1: binary msg }
+service S { void f() throws (1: A a, 2: B b) }`}}
+
+	main := mustGenerate(t, idls, "typedef_as_type")["example/main.go"]
+	for _, s := range []string{
+		`return "A: " + string(p.GetMessage())`,
+		`return "B: " + p.GetMsg()`,
+		`return "C: " + p.GetDetail()`,
+		"func (p *D) Error() string {\n\treturn p.String()",
+		"func (p *E) Error() string {\n\treturn p.String()",
+		"case p.A != nil:\n\t\treturn p.A",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	for _, c := range []struct{ idl, err string }{
+		{`exception A { 1: string msg } (go.error_message = "message")`, `no field "message" for go.error_message`},
+		{`exception A { 1: i32 code } (go.error_message = "code")`, `the field "code" for go.error_message is not a string`},
+		{`exception A { 1: string msg } (go.error_message = "msg", go.error_message = "msg")`, "go.error_message is set more than once"},
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", c.idl}})
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect an error with %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestThrows(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+exception NotFound { 1: string key }
+exception Denied { 1: string reason }
+service S {
+	string Get(1: string key) throws (2: Denied d, 1: NotFound nf)
+	void Put(1: string key, 2: string Exception) throws (1: Denied d)
+	void Ping()
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"// Get returns the exceptions in its throws clause as the error: *NotFound, *Denied.\n\tGet(ctx context.Context, key string) (r string, err error)",
+		"// Put returns the exceptions in its throws clause as the error: *Denied.\n\tPut(",
+		"func (p *SGetResult) Exception() error {\n\tswitch {\n\tcase p.Nf != nil:\n\t\treturn p.Nf\n\tcase p.D != nil:\n\t\treturn p.D\n\t}\n\treturn nil\n}",
+		"func (p *SPutResult) Exception() error {",
+		"if err = _result.Exception(); err != nil {\n\t\treturn\n\t}\n\treturn _result.GetSuccess(), nil",
+		"if err = _result.Exception(); err != nil {\n\t\treturn\n\t}\n\treturn nil",
+		// the processor also matches the exceptions in the order of their IDs
+		"case *NotFound:\n\t\t\tresult.Nf = v\n\t\tcase *Denied:\n\t\t\tresult.D = v",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	for _, s := range []string{"SPingResult) Exception", "SGetArgs) Exception", "// Ping returns"} {
+		if strings.Contains(main, s) {
+			t.Fatalf("unexpected %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestFBDialect(t *testing.T) {
+	parser.SetDialect(parser.DialectFB)
+	defer parser.SetDialect(parser.DialectApache)
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+@cpp.Type{name = "Chunk"}
+struct Chunk { 1: binary data }
+service Feed { stream<Chunk> Subscribe(1: string topic) }`}}
+
+	main := mustGenerate(t, idls, "thrift_streaming")["example/main.go"]
+	if s := "Subscribe(req string, stream Feed_SubscribeServer) (err error)"; !strings.Contains(main, s) {
+		t.Fatalf("expect %q in:\n%s", s, main)
+	}
+
+	_, err := generate(t, [][2]string{{"main.thrift", "service Feed { sink<i32, i64> Publish(1: string topic) }"}})
+	if s := "service Feed: function Publish: the sinks of fbthrift are not supported"; err == nil || !strings.HasSuffix(err.Error(), s) {
+		t.Fatalf("expect %q, got %v", s, err)
+	}
+}
+
+func TestPairMaps(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+typedef map<list<i32>, string> ByInts
+struct K { 1: i32 id }
+struct S {
+	1: ByInts byInts
+	2: map<set<string>, i64> counts
+	3: map<K, string> byStruct
+}`}}
+
+	main := mustGenerate(t, idls, "gen_deep_equal")["example/main.go"]
+	for _, s := range []string{
+		"type ByInts = []struct {\n\tKey   []int32\n\tValue string\n}",
+		"Counts []struct {\n\t\tKey   []string\n\t\tValue int64\n\t}",
+		"ByStruct map[*K]string",
+		"func (p *S) LookupByInts(key []int32) (value string, ok bool) {",
+		"func (p *S) PutCounts(key []string, value int64) {",
+		"for _, kv := range p.ByInts {\n\t\tk, v := kv.Key, kv.Value",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "LookupByStruct") {
+		t.Fatalf("expect no accessors for maps with struct keys:\n%s", main)
+	}
+
+	for _, opt := range []string{"with_field_mask", "frugal_tag"} {
+		_, err := generate(t, idls, opt)
+		if err == nil || !strings.Contains(err.Error(), "can not be used with "+opt) {
+			t.Fatalf("expect an error with %s, got %v", opt, err)
+		}
+	}
+}
+
+func TestGenFieldMeta(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+typedef list<i64> Ids
+enum E { A = 1 }
+struct P { 1: i32 x }
+struct S {
+	1: required string name
+	2: optional Ids ids
+	3: map<string, P> by_name
+	4: set<E> es
+}
+struct SFields {}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "fieldmeta") {
+		t.Fatalf("unexpected field meta without gen_field_meta:\n%s", main)
+	}
+
+	for _, tpl := range []string{"default", "slim", "raw_struct"} {
+		main = mustGenerate(t, idls, "gen_field_meta", "template="+tpl)["example/main.go"]
+		for _, s := range []string{
+			"\t\"github.com/cloudwego/thriftgo/generator/golang/extension/fieldmeta\"\n",
+			"var PFields = []fieldmeta.FieldMeta{\n",
+			`{ID: 1, Name: "name", GoName: "Name", Category: fieldmeta.String, Requiredness: fieldmeta.Required},`,
+			`{ID: 2, Name: "ids", GoName: "Ids", Category: fieldmeta.List, ElemCategory: fieldmeta.I64, Requiredness: fieldmeta.Optional},`,
+			`{ID: 3, Name: "by_name", GoName: "ByName", Category: fieldmeta.Map, KeyCategory: fieldmeta.String, ElemCategory: fieldmeta.Struct, Requiredness: fieldmeta.Default},`,
+			`{ID: 4, Name: "es", GoName: "Es", Category: fieldmeta.Set, ElemCategory: fieldmeta.Enum, Requiredness: fieldmeta.Default},`,
+			// the variable does not take the name of a type
+			"type SFields struct {",
+			"var SFields_ = []fieldmeta.FieldMeta{\n",
+		} {
+			if !strings.Contains(main, s) {
+				t.Fatalf("expect %q with the %s template in:\n%s", s, tpl, main)
+			}
+		}
+	}
+}
+
+func TestUnionGetters(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct P { 1: i32 x }
+union U {
+	1: i32 a
+	2: P p
+	3: list<i32> l
+	4: string set_field
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "UFieldID") {
+		t.Fatalf("unexpected field IDs without union_getters:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "union_getters")["example/main.go"]
+	for _, s := range []string{
+		"type UFieldID int16\n",
+		"\tUFieldID_A        UFieldID = 1\n",
+		"\tUFieldID_SetField UFieldID = 4\n",
+		"func (p *U) GetSetField() UFieldID {",
+		"\tif p.IsSetP() {\n\t\treturn UFieldID_P\n\t}\n",
+		"func NewUFromA(v int32) *U {\n\treturn &U{A: &v}\n}",
+		"func NewUFromP(v *P) *U {\n\treturn &U{P: v}\n}",
+		"func NewUFromL(v []int32) *U {\n\treturn &U{L: v}\n}",
+		// the getter of the field gives way to the method
+		"func (p *U) GetSetField_() (v string) {",
+		"func (p *U) CountSetFieldsU() int {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	main = mustGenerate(t, idls, "union_getters", "gen_presence=bitset")["example/main.go"]
+	s := "func NewUFromA(v int32) *U {\n\tp := &U{}\n\tp.SetA(v)\n\treturn p\n}"
+	if !strings.Contains(main, s) {
+		t.Fatalf("expect %q in:\n%s", s, main)
+	}
+}
+
+func TestFastSkip(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: i32 a }
+struct E {}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "file_main_thrift_skip") || !strings.Contains(main, "} else if err = iprot.Skip(fieldTypeId); err != nil {") {
+		t.Fatalf("unexpected skip without fast_skip:\n%s", main)
+	}
+
+	for _, opts := range [][]string{{"fast_skip"}, {"fast_skip", "keep_unknown_fields"}} {
+		main = mustGenerate(t, idls, opts...)["example/main.go"]
+		for _, s := range []string{
+			"\"github.com/cloudwego/thriftgo/generator/golang/extension/skip\"",
+			"func file_main_thrift_skip(iprot thrift.TProtocol, fieldType thrift.TType) error {",
+			"\t\t\treturn skip.Binary(t, int(fieldType), skip.DefaultDepth)\n",
+			"\treturn iprot.Skip(fieldType)\n",
+			// the field of a different type
+			"} else if err = file_main_thrift_skip(iprot, fieldTypeId); err != nil {",
+		} {
+			if !strings.Contains(main, s) {
+				t.Fatalf("expect %q with %v in:\n%s", s, opts, main)
+			}
+		}
+	}
+	// the struct without fields, whose unknown fields are kept with keep_unknown_fields
+	main = mustGenerate(t, idls, "fast_skip")["example/main.go"]
+	if s := "if err = file_main_thrift_skip(iprot, fieldTypeId); err != nil {\n\t\t\tgoto SkipFieldTypeError"; !strings.Contains(main, s) {
+		t.Fatalf("expect %q in:\n%s", s, main)
+	}
+
+	main = mustGenerate(t, idls, "fast_skip", "runtime=apache")["example/main.go"]
+	for _, s := range []string{
+		"func file_main_thrift_skip(ctx context.Context, iprot thrift.TProtocol, fieldType thrift.TType) error {",
+		"\treturn iprot.Skip(ctx, fieldType)\n",
+		"} else if err = file_main_thrift_skip(ctx, iprot, fieldTypeId); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestCheckUnionOnRead(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: i32 a }
+union U {
+	1: i32 a
+	2: string b
+}`}}
+
+	check := "\tif c := p.CountSetFieldsU(); c > 1 {\n" +
+		"\t\terr = fmt.Errorf(\"%T read union: at most one field can be set (%d set)\", p, c)\n" +
+		"\t\tgoto CountSetFieldsError\n\t}\n\treturn nil\n"
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, check) {
+		t.Fatalf("unexpected union check without check_union_on_read:\n%s", main)
+	}
+
+	exactlyOne := "\tif c = p.CountSetFieldsU(); c != 1 {\n"
+	for _, opt := range []string{"check_union_on_write", "check_union_on_read"} {
+		main = mustGenerate(t, idls, opt)["example/main.go"]
+		if n := strings.Count(main, check); n != 1 {
+			t.Fatalf("%s: expect one %q, got %d in:\n%s", opt, check, n, main)
+		}
+		if s := "CountSetFieldsError:\n\treturn thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, err)\n}"; !strings.Contains(main, s) {
+			t.Fatalf("%s: expect %q in:\n%s", opt, s, main)
+		}
+		if !strings.Contains(main, exactlyOne) {
+			t.Fatalf("%s: expect %q in:\n%s", opt, exactlyOne, main)
+		}
+	}
+
+	// check_union_allow_zero lets Write accept a union with no field set
+	main = mustGenerate(t, idls, "check_union_allow_zero")["example/main.go"]
+	for _, s := range []string{
+		check,
+		"\tif c = p.CountSetFieldsU(); c > 1 {\n",
+		"\treturn fmt.Errorf(\"%T write union: at most one field can be set (%d set).\", p, c)\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, exactlyOne) {
+		t.Fatalf("unexpected %q in:\n%s", exactlyOne, main)
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "a.thrift"
+struct Node {
+	1: list<Node> children
+	2: optional a.Leaf leaf
+}
+union Expr {
+	1: i64 literal
+	2: Binary binary
+}
+struct Binary {
+	1: Expr left
+	2: Expr right
+}`},
+		{"a.thrift", `
+namespace go example.a
+struct Leaf { 1: i64 value }`},
+	}
+
+	res := mustGenerate(t, idls)
+	main := res["example/main.go"]
+	for _, name := range []string{"Node", "Expr", "Binary"} {
+		if s := "// " + name + " is a recursive type."; !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(res["example/a/a.go"], "is a recursive type") {
+		t.Fatal("Leaf is not recursive")
+	}
+}
+
+func TestGenerateLeaf(t *testing.T) {
+	types := [2]string{"types.thrift", `
+namespace go acme.types
+struct User { 1: i64 id }`}
+	main := [2]string{"main.thrift", `
+namespace go acme.main
+include "types.thrift"
+struct Req { 1: types.User user }`}
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the IDL that is only included is generated on its own
+	files, err := generateFiles(t, dir, [][2]string{types}, false, "package_prefix=example.com/gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || !strings.Contains(files["acme/types/types.go"], "type User struct {") {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	// the root imports the package of the include without generating it
+	files, err = generateFiles(t, dir, [][2]string{main, types}, false, "package_prefix=example.com/gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	for _, s := range []string{
+		"\"example.com/gen/acme/types\"",
+		"\tUser *types.User `thrift:\"user,1\" json:\"user\"`",
+	} {
+		if !strings.Contains(files["acme/main/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["acme/main/main.go"])
+		}
+	}
+
+	files, err = generateFiles(t, dir, [][2]string{main, types}, true, "package_prefix=example.com/gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files["acme/types/types.go"] == "" {
+		t.Fatalf("unexpected files: %v", files)
+	}
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "a.thrift"
+const map<string, i32> M = {"z": 1, "y": 2, "x": 3, "w": 4}
+struct S {
+	1: map<string, a.A> m (k1 = "1", k2 = "2", k3 = "3", k4 = "4", k5 = "5")
+	2: set<i64> s = [3, 1, 2]
+} (x1 = "1", x2 = "2", x3 = "3", x4 = "4", x5 = "5", x6 = "6")
+enum E { V1 (v1 = "1", v2 = "2", v3 = "3") }
+service Svc {
+	S Get(1: E e) throws (1: a.Err err) (m1 = "1", m2 = "2", m3 = "3")
+}`},
+		{"a.thrift", `
+namespace go example.a (n1 = "1", n2 = "2", n3 = "3")
+struct A {}
+exception Err {}`},
+	}
+	// the reflection files contain the paths of the IDLs
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	opts := []string{"with_reflection", "gen_type_meta", "gen_deep_equal", "gen_json_methods"}
+	first, err := generateIn(t, dir, idls, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(first["example/main/main-reflection.go"], "file_main_thrift_go_types") {
+		t.Fatalf("expect the reflection file, got %v", first)
+	}
+	for i := 0; i < 5; i++ {
+		files, err := generateIn(t, dir, idls, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != len(first) {
+			t.Fatalf("expect %d files, got %d", len(first), len(files))
+		}
+		for name, content := range first {
+			if files[name] != content {
+				t.Fatalf("%s differs between runs:\n%s\n----\n%s", name, content, files[name])
+			}
+		}
+	}
+}
+
+func TestDescribeBackends(t *testing.T) {
+	var g generator.Generator
+	if err := g.RegisterBackend(new(GoBackend)); err != nil {
+		t.Fatal(err)
+	}
+	infos := g.DescribeBackends()
+	if len(infos) != 1 || infos[0].Name != "go" || infos[0].Lang != "Go" || len(infos[0].Options) != len(allParams) {
+		t.Fatalf("unexpected backends: %+v", infos)
+	}
+	types := make(map[string]*generator.OptionInfo)
+	for _, o := range infos[0].Options {
+		types[o.Name] = o
+	}
+	for name, typ := range map[string]string{
+		"gen_deep_equal":     "bool",
+		"ignore_initialisms": "bool",
+		"naming_style":       "string",
+		"template":           "string",
+	} {
+		o := types[name]
+		if o == nil || o.Type != typ || o.Boolean != (typ == "bool") || o.Desc == "" {
+			t.Fatalf("expect %s to be %s, got %+v", name, typ, o)
+		}
+	}
+}
+
+type recordingPlugin struct{ req *plugin.Request }
+
+func (p *recordingPlugin) Invoke(req *plugin.Request) *plugin.Response {
+	p.req = req
+	return &plugin.Response{}
+}
+
+func (p *recordingPlugin) GetName() string { return "recording" }
+
+func (p *recordingPlugin) GetPluginParameters() []string { return nil }
+
+func TestPluginGeneratorOptions(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", "struct S { 1: i64 id }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = semantic.ResolveSymbols(ast); err != nil {
+		t.Fatal(err)
+	}
+	rec := new(recordingPlugin)
+	out := &generator.LangSpec{Language: "go", SDKPlugins: []plugin.SDKPlugin{rec}, Options: []plugin.Option{
+		{Name: "gen_setter"},
+		{Name: "use_type_alias", Desc: "false"},
+		{Name: "naming_style", Desc: "golint"},
+		{Name: "exclude", Desc: "a/**"},
+		{Name: "exclude", Desc: "b/**"},
+		{Name: "protocols", Desc: "binary"},
+		{Name: "compact"},
+	}}
+	req := &plugin.Request{Language: "go", Version: "?", OutputPath: "gen-go", AST: ast}
+	var g generator.Generator
+	if err = g.RegisterBackend(new(GoBackend)); err != nil {
+		t.Fatal(err)
+	}
+	nop := func(v ...interface{}) {}
+	log := backend.LogFunc{Info: nop, Warn: nop, MultiWarn: func(ws []string) {}}
+	if res := g.Generate(&generator.Arguments{Out: out, Req: req, Log: log}); res.Error != nil {
+		t.Fatal(res.GetError())
+	}
+
+	opts := rec.req.GetGeneratorOptions()
+	for name, value := range map[string]string{
+		"gen_setter":          "true",
+		"use_type_alias":      "false",
+		"gen_deep_equal":      "false",
+		"scan_value_for_enum": "true",
+		"ignore_initialisms":  "false",
+		"naming_style":        "golint",
+		"exclude":             "a/**,b/**",
+		"protocols":           "binary,compact",
+	} {
+		if v, ok := opts[name]; !ok || v != value {
+			t.Fatalf("expect %s=%q, got %q in %v", name, value, v, opts)
+		}
+	}
+	if _, ok := opts["template"]; ok {
+		t.Fatalf("expect no value for the options not given: %v", opts)
+	}
+
+	out.Options = []plugin.Option{{Name: "gen_setter", Desc: "yes"}}
+	res := g.Generate(&generator.Arguments{Out: out, Req: req, Log: log})
+	if res.Error == nil || !strings.Contains(res.GetError(), "gen_setter: expect a bool value") {
+		t.Fatalf("expect an error, got %v", res.Error)
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo-header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Req { 1: string name }`}}
+
+	header := write("header.tmpl", "// Copyright Example Corp.\n// Source: {{base .IDL}} by thriftgo {{.Version}}. DO NOT EDIT.\n")
+	_, err = generate(t, idls, "file_header="+header)
+	if err == nil || !strings.Contains(err.Error(), "function \"base\" not defined") {
+		t.Fatalf("expect an error, got %v", err)
+	}
+
+	header = write("header.tmpl", "/*\n * Copyright Example Corp.\n */\n// Source: {{.IDL}} by thriftgo {{.Version}}. DO NOT EDIT.\n")
+	main := mustGenerate(t, idls, "file_header="+header)["example/main.go"]
+	prefix := "/*\n * Copyright Example Corp.\n */\n// Source: "
+	if !strings.HasPrefix(main, prefix) || !strings.Contains(main, "main.thrift by thriftgo ?. DO NOT EDIT.\n\n// Code generated by thriftgo") {
+		t.Fatalf("unexpected header:\n%s", main)
+	}
+
+	// the date comes from SOURCE_DATE_EPOCH to be reproducible
+	header = write("header.tmpl", "// Generated on {{.Date}}.\n")
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	os.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	main = mustGenerate(t, idls, "file_header="+header)["example/main.go"]
+	if !strings.HasPrefix(main, "// Generated on 2023-11-14.\n") {
+		t.Fatalf("unexpected header:\n%s", main)
+	}
+	os.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err = generate(t, idls, "file_header="+header); err == nil || !strings.Contains(err.Error(), "invalid SOURCE_DATE_EPOCH") {
+		t.Fatalf("expect an error, got %v", err)
+	}
+	os.Unsetenv("SOURCE_DATE_EPOCH")
+
+	_, err = generate(t, idls, "file_header="+write("bad.tmpl", "var x = 1\n"))
+	if err == nil || !strings.Contains(err.Error(), "must only contain Go comments") {
+		t.Fatalf("expect an error, got %v", err)
+	}
+
+	_, err = generate(t, idls, "file_header="+filepath.Join(dir, "missing.tmpl"))
+	if err == nil || !strings.Contains(err.Error(), "file_header: open") {
+		t.Fatalf("expect an error, got %v", err)
+	}
+}
+
+func TestJSONOmitEmpty(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S {
+	1: optional bool flag (go.json.omitempty = "false")
+	2: optional i32 count
+	3: i32 n (go.json.omitempty = "true")
+	4: list<string> l (go.json.omitempty = "true")
+	5: optional i64 d = 5 (go.json.omitempty = "true")
+	6: string name
+	7: optional string tagged (go.json.omitempty = "false", go.tag = 'json:"t,omitempty"')
+}`}}
+
+	for _, c := range []struct {
+		opts []string
+		tags []string
+	}{
+		{[]string{"omitempty_for_optional=false"}, []string{
+			"`thrift:\"flag,1,optional\" json:\"flag\"`",
+			"`thrift:\"count,2,optional\" json:\"count\"`",
+			"`thrift:\"n,3\" json:\"n,omitempty\"`",
+			"`thrift:\"l,4\" json:\"l,omitempty\"`",
+			"`thrift:\"d,5,optional\" json:\"d,omitempty\"`",
+			"`thrift:\"name,6\" json:\"name\"`",
+			"`thrift:\"tagged,7,optional\" json:\"t,omitempty\"`",
+		}},
+		{nil, []string{
+			"`thrift:\"flag,1,optional\" json:\"flag\"`",
+			"`thrift:\"count,2,optional\" json:\"count,omitempty\"`",
+			"`thrift:\"n,3\" json:\"n,omitempty\"`",
+			"`thrift:\"name,6\" json:\"name\"`",
+		}},
+		{[]string{"always_gen_json_tag", "snake_style_json_tag"}, []string{
+			"`thrift:\"flag,1,optional\" json:\"flag\"`",
+			"`thrift:\"tagged,7,optional\" json:\"t,omitempty\"`",
+		}},
+		{[]string{"gen_json_tag=false"}, []string{
+			"`thrift:\"flag,1,optional\"`",
+			"`thrift:\"n,3\"`",
+		}},
+	} {
+		main := mustGenerate(t, idls, c.opts...)["example/main.go"]
+		for _, s := range c.tags {
+			if !strings.Contains(main, s) {
+				t.Fatalf("expect %s with %v in:\n%s", s, c.opts, main)
+			}
+		}
+	}
+
+	main := mustGenerate(t, idls, "gen_json_methods")["example/main.go"]
+	for _, s := range []string{
+		"\tif err := write(\"flag\", &p.Flag); err != nil {",
+		"\tif p.IsSetCount() {\n\t\tif err := write(\"count\", &p.Count); err != nil {",
+		"\tif p.N != 0 {\n\t\tif err := write(\"n\", &p.N); err != nil {",
+		"\tif len(p.L) != 0 {\n\t\tif err := write(\"l\", &p.L); err != nil {",
+		"\tif p.IsSetD() && p.D != 0 {\n\t\tif err := write(\"d\", &p.D); err != nil {",
+		"\tif err := write(\"name\", &p.Name); err != nil {",
+		// go.tag does not change the json of MarshalJSON
+		"\tif err := write(\"tagged\", &p.Tagged); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	for _, c := range []struct{ anno, err string }{
+		{`(go.json.omitempty = "no")`, `field "a": go.json.omitempty must be "true" or "false", got "no"`},
+		{`(go.json.omitempty = "true", go.json.omitempty = "false")`, `field "a": go.json.omitempty is set more than once`},
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", "struct S { 1: string a " + c.anno + " }"}})
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect an error with %q, got %v", c.err, err)
+		}
+	}
+}
+
+func TestGenJSONMethods(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Status { OK = 1 }
+struct Req {
+	1: required string name
+	2: optional Status status
+}
+union U { 1: i32 a; 2: string b }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "MarshalJSON") {
+		t.Fatal("MarshalJSON should not be generated by default")
+	}
+
+	main = mustGenerate(t, idls, "gen_json_methods")["example/main.go"]
+	for _, s := range []string{
+		"func (p Status) MarshalJSON() ([]byte, error) {",
+		"func (p *Status) UnmarshalJSON(data []byte) error {",
+		"func (p *Req) MarshalJSON() ([]byte, error) {",
+		"\tif p.IsSetStatus() {\n\t\tif err := write(\"status\", &p.Status); err != nil {",
+		"\tif !issetName {\n\t\treturn fmt.Errorf(\"Req: required field name is not set\")",
+		"func (p *U) UnmarshalJSON(data []byte) error {",
+		"\tif c := p.CountSetFieldsU(); c > 1 {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "unknown field") {
+		t.Fatal("unknown fields should be ignored by default")
+	}
+
+	main = mustGenerate(t, idls, "gen_json_methods", "json_disallow_unknown_fields")["example/main.go"]
+	if !strings.Contains(main, "\t\tdefault:\n\t\t\treturn fmt.Errorf(\"Req: unknown field %q\", key)") {
+		t.Fatalf("unknown fields should be rejected:\n%s", main)
+	}
+}
+
+func TestFastRead(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Kind { A = 1 }
+typedef i32 Int
+struct Point { 1: required i32 x; 2: double y; 3: Kind k }
+struct Named { 1: i32 id; 2: string name }
+struct Reversed { 2: i32 a; 1: i32 b }
+struct Opt { 1: optional i32 a }
+struct Alias { 1: Int a }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "SlowPath") {
+		t.Fatal("the fast path should not be generated by default")
+	}
+
+	main = mustGenerate(t, idls, "fast_read")["example/main.go"]
+	read := func(name string) string {
+		s := main[strings.Index(main, "func (p *"+name+") Read(iprot"):]
+		return s[:strings.Index(s, "\n}\n")]
+	}
+	for _, s := range []string{
+		"\tif fieldId != 1 || fieldTypeId != thrift.I32 {\n\t\tgoto SlowPath\n\t}",
+		"\t\tp.X = v\n\t}\n\tissetX = true",
+		"\t\tp.K = Kind(v)",
+		"SlowPath:\n\tfor fieldTypeId != thrift.STOP {",
+	} {
+		if !strings.Contains(read("Point"), s) {
+			t.Fatalf("expect %q in:\n%s", s, read("Point"))
+		}
+	}
+	for _, name := range []string{"Named", "Reversed", "Opt", "Alias"} {
+		if strings.Contains(read(name), "SlowPath") {
+			t.Fatalf("%s should use the generic reader:\n%s", name, read(name))
+		}
+	}
+
+	main = mustGenerate(t, idls, "fast_read", "keep_unknown_fields")["example/main.go"]
+	if strings.Contains(main, "SlowPath") {
+		t.Fatal("the fast path should not be generated with keep_unknown_fields")
+	}
+}
+
+func TestTypedefChain(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+include "base.thrift"
+typedef base.UserId Uid
+typedef list<Uid> UserIds
+typedef map<string, UserIds> Groups
+const Groups Default = {"a": [1, 2]}
+struct S {
+	1: map<string, UserIds> m = {"b": [3]}
+	2: Groups g
+}`}, {"base.thrift", `
+namespace go example.base
+typedef i64 Id
+typedef Id UserId`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"type Uid = base.UserId\n",
+		"type UserIds = []Uid\n",
+		"type Groups = map[string]UserIds\n",
+		"\tDefault = Groups{\n\t\t\"a\": UserIds{\n\t\t\t1,\n\t\t\t2,\n\t\t},\n\t}",
+		"\tM map[string]UserIds `thrift:\"m,1\" json:\"m\"`",
+		"\t\tM: map[string]UserIds{\n\t\t\t\"b\": UserIds{\n\t\t\t\t3,\n\t\t\t},\n\t\t},",
+		"\t_field := make(Groups, size)",
+		"\t\t\tvar _elem Uid\n\t\t\tif v, err := iprot.ReadI64(); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenWriteTo(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Req { 1: string name }
+union U { 1: i32 a }
+exception Err { 1: string msg }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "WriteTo") {
+		t.Fatal("WriteTo should not be generated by default")
+	}
+
+	main = mustGenerate(t, idls, "gen_write_to")["example/main.go"]
+	for _, s := range []string{
+		"var file_main_thrift_buffer_pool = sync.Pool{",
+		"func (p *Req) WriteTo(w io.Writer) (n int64, err error) {",
+		"func (p *U) WriteTo(w io.Writer) (n int64, err error) {",
+		"func (p *Err) WriteTo(w io.Writer) (n int64, err error) {",
+		"\tdefer func() {\n\t\tbuf.Reset()\n\t\tfile_main_thrift_buffer_pool.Put(buf)\n\t}()",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	if _, err := generate(t, idls, "gen_write_to", "template=slim"); err == nil {
+		t.Fatal("expect an error for gen_write_to with the slim template")
+	}
+}
+
+// The per-file declarations of the IDLs of the same name in one package do not collide.
+func TestSameIDLName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "b.thrift"
+include "sub/b.thrift"
+struct A { 1: b.B b1 }`},
+		{"b.thrift", "namespace go example\nstruct B { 1: i32 x }"},
+		{"sub/b.thrift", "namespace go example\nstruct C { 1: i32 y }"},
+	}
+	files, err := generateIn(t, dir, idls, "gen_buffer_reuse", "gen_write_to", "fast_skip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, b1 := files["example/b.go"], files["example/b_1.go"]
+	for _, s := range []string{"type file_b_thrift_encoder struct", "func file_b_thrift_skip("} {
+		if !strings.Contains(b, s) {
+			t.Fatalf("expect %q in:\n%s", s, b)
+		}
+	}
+	for _, s := range []string{"type file_b_1_thrift_encoder struct", "func file_b_1_thrift_skip("} {
+		if !strings.Contains(b1, s) {
+			t.Fatalf("expect %q in:\n%s", s, b1)
+		}
+	}
+}
+
+func TestGenBufferReuse(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Item { 1: i64 id }
+struct Req { 1: string name; 2: list<Item> items }`}}
+
+	main := mustGenerate(t, idls, "gen_buffer_reuse", "gen_write_to")["example/main.go"]
+	for _, s := range []string{
+		"type file_main_thrift_encoder struct {\n\tbuf   *thrift.TMemoryBuffer\n\tproto *thrift.TBinaryProtocol\n}",
+		"return &file_main_thrift_encoder{buf: buf, proto: thrift.NewTBinaryProtocolTransport(buf)}",
+		"func (p *Item) AppendBinary(b []byte) ([]byte, error) {",
+		"func (p *Req) AppendBinary(b []byte) ([]byte, error) {\n\te := file_main_thrift_encoder_pool.Get().(*file_main_thrift_encoder)\n\tdefer e.release()",
+		"\treturn append(b, e.buf.Bytes()...), nil",
+		// the encoders that have grown too large are not kept
+		"\tif e.buf.Cap() > file_main_thrift_encoder_max_size {\n\t\treturn\n\t}",
+		// WriteTo shares the encoders instead of having a pool of its own.
+		"func (p *Req) WriteTo(w io.Writer) (n int64, err error) {\n\te := file_main_thrift_encoder_pool.Get().(*file_main_thrift_encoder)",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "file_main_thrift_buffer_pool") {
+		t.Fatalf("unexpected buffer pool in:\n%s", main)
+	}
+
+	if _, err := generate(t, idls, "gen_buffer_reuse", "no_default_serdes"); err == nil || !strings.Contains(err.Error(), "gen_buffer_reuse requires the Read and Write methods") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenByteSize(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Item { 1: i64 id }
+struct Req {
+	1: string name
+	2: optional i32 age
+	3: list<i64> ids
+	4: map<string, Item> items
+	5: map<list<i32>, bool> pairs
+}
+union U { 1: i32 a }
+exception Err { 1: string msg }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "BytesLength") {
+		t.Fatal("BytesLength should not be generated by default")
+	}
+
+	for _, opts := range [][]string{{"gen_byte_size"}, {"gen_byte_size", "keep_unknown_fields"}} {
+		main = mustGenerate(t, idls, opts...)["example/main.go"]
+		for _, s := range []string{
+			"func (p *Req) BytesLength() int {\n\tl := 1 // field stop\n\tif p == nil {\n\t\treturn l\n\t}\n",
+			"func (p *U) BytesLength() int {",
+			"func (p *Err) BytesLength() int {",
+			"\tl += 3 // field begin\n\tl += 4 + len(p.Name)\n",
+			"\tif p.IsSetAge() {\n\t\tl += 3 // field begin\n\t\tl += 4\n\t}\n",
+			"\tl += 5 // element type and size\n\tl += len(p.Ids) * 8\n",
+			"\tfor k, v := range p.Items {\n\t\tl += 4 + len(k)\n\t\tl += v.BytesLength()\n\t}\n",
+			"\tl += len(p.Pairs) * 1\n\tfor _, kv := range p.Pairs {\n\t\tl += 5 // element type and size\n\t\tl += len(kv.Key) * 4\n\t}\n",
+		} {
+			if !strings.Contains(main, s) {
+				t.Fatalf("expect %q with %v in:\n%s", s, opts, main)
+			}
+		}
+		if keep := len(opts) > 1; keep != strings.Contains(main, "\tl += len(p._unknownFields)\n") {
+			t.Fatalf("unexpected size of unknown fields with %v:\n%s", opts, main)
+		}
+	}
+
+	for opts, msg := range map[string]string{
+		"gen_byte_size,template=slim":     "gen_byte_size requires the Read and Write methods",
+		"gen_byte_size,with_field_mask":   "gen_byte_size can not be used with with_field_mask",
+		"gen_byte_size,no_default_serdes": "gen_byte_size requires the Read and Write methods",
+	} {
+		_, err := generate(t, idls, strings.Split(opts, ",")...)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expect %q for %s, got: %v", msg, opts, err)
+		}
+	}
+}
+
+func TestGoPackageAnnotation(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "base.thrift"
+enum Role { USER = 1 } (go.package = "detail")
+struct Token {
+	1: Role role = Role.USER
+	2: base.Meta meta
+} (go.package = "detail")
+struct Secret { 1: Token token } (go.package = "detail.vault")
+struct User {
+	1: Token token
+	2: list<Role> roles = [Role.USER]
+	3: Secret secret
+}`},
+		{"base.thrift", `
+namespace go base
+struct Meta {}`},
+		{"other.thrift", `
+namespace go other
+include "main.thrift"
+struct Wrap { 1: main.Token token }`},
+	}
+
+	files := mustGenerate(t, idls)
+	for name, strs := range map[string][]string{
+		"example/main.go": {
+			"\t\"example/detail\"\n",
+			"\t\"example/detail/vault\"\n",
+			"\tToken  *detail.Token `thrift:\"token,1\" json:\"token\"`\n",
+			"\tRoles  []detail.Role `thrift:\"roles,2\" json:\"roles\"`\n",
+			"\tSecret *vault.Secret `thrift:\"secret,3\" json:\"secret\"`\n",
+		},
+		"example/detail/main.go": {
+			"package detail\n",
+			"\t\"base\"\n",
+			"type Role int64\n",
+			"\tRole Role       `thrift:\"role,1\" json:\"role\"`\n",
+			"\tMeta *base.Meta `thrift:\"meta,2\" json:\"meta\"`\n",
+		},
+		"example/detail/vault/main.go": {
+			"package vault\n",
+			"\t\"example/detail\"\n",
+			"\tToken *detail.Token `thrift:\"token,1\" json:\"token\"`\n",
+		},
+	} {
+		for _, s := range strs {
+			if !strings.Contains(files[name], s) {
+				t.Fatalf("expect %q in %s:\n%s", s, name, files[name])
+			}
+		}
+		if strings.Contains(files[name], "type User struct") != (name == "example/main.go") {
+			t.Fatalf("unexpected User in %s:\n%s", name, files[name])
+		}
+	}
+
+	idls[0] = [2]string{"main.thrift", `
+namespace go example
+struct Token { 1: User owner } (go.package = "detail")
+struct User { 1: Token token }`}
+	_, err := generate(t, idls)
+	msg := `go.package leads to an import cycle: struct "User" in example refers to "Token" in example.detail, and struct "Token" in example.detail refers to "User" in example`
+	if err == nil || !strings.Contains(err.Error(), msg) {
+		t.Fatalf("expect %q, got: %v", msg, err)
+	}
+
+	idls[0] = [2]string{"main.thrift", `
+namespace go example
+struct Token {} (go.package = "de-tail")`}
+	_, err = generate(t, idls)
+	if err == nil || !strings.Contains(err.Error(), `struct "Token": invalid go.package "de-tail"`) {
+		t.Fatalf("expect an invalid go.package error, got: %v", err)
+	}
+}
+
+func TestEnumMapKeys(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+include "base.thrift"
+enum Kind { A = 1 }
+typedef Kind KindAlias
+struct S {
+	1: map<Kind, string> local
+	2: map<base.Color, list<Kind>> remote
+	3: map<KindAlias, i32> aliased
+}`}, {"base.thrift", `
+namespace go example.base
+enum Color { RED = 1 }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"\tLocal   map[Kind]string       `thrift:\"local,1\" json:\"local\"`",
+		"\tRemote  map[base.Color][]Kind `thrift:\"remote,2\" json:\"remote\"`",
+		"\tAliased map[KindAlias]int32   `thrift:\"aliased,3\" json:\"aliased\"`",
+		"\t\tvar _key base.Color\n\t\tif v, err := iprot.ReadI32(); err != nil {\n\t\t\treturn err\n\t\t} else {\n\t\t\t_key = base.Color(v)\n\t\t}",
+		"\t\tvar _key KindAlias\n\t\tif v, err := iprot.ReadI32(); err != nil {\n\t\t\treturn err\n\t\t} else {\n\t\t\t_key = KindAlias(v)\n\t\t}",
+		"\tif err := oprot.WriteMapBegin(thrift.I32, thrift.LIST, len(p.Remote)); err != nil {",
+		"\t\tif err := oprot.WriteI32(int32(k)); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestCtxRW(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Node { 1: list<Node> children }
+service Svc { Node Get(1: Node n) }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "ctx.Err()") {
+		t.Fatal("context-aware methods should not be generated by default")
+	}
+
+	main = mustGenerate(t, idls, "ctx_rw")["example/main.go"]
+	for _, s := range []string{
+		"func (p *Node) Read(ctx context.Context, iprot thrift.TProtocol) (err error) {\n\tif err = ctx.Err(); err != nil {\n\t\treturn err\n\t}",
+		"func (p *Node) Write(ctx context.Context, oprot thrift.TProtocol) (err error) {\n\tif err = ctx.Err(); err != nil {\n\t\treturn err\n\t}",
+		"func (p *Node) ReadField1(ctx context.Context, iprot thrift.TProtocol) error {",
+		"\t\tif err := _elem.Read(ctx, iprot); err != nil {",
+		"\t\tif err := v.Write(ctx, oprot); err != nil {",
+		"\tif err = args.Read(ctx, iprot); err != nil {",
+		"\tif err2 = result.Write(ctx, oprot); err == nil && err2 != nil {",
+		"\tif err = p.Client_().Call(ctx, \"Get\", svcClientStruct{ctx, &_args}, svcClientStruct{ctx, &_result}); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	if _, err := generate(t, idls, "ctx_rw", "template=slim"); err == nil {
+		t.Fatal("expect an error for ctx_rw with the slim template")
+	}
+}
+
+func TestFilenameFunc(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "user_test.thrift"
+struct S { 1: user_test.U u }`},
+		{"user_test.thrift", `
+namespace go example.user
+struct U {}`},
+	}
+	run := func(f FilenameFunc, opts ...string) (map[string]string, error) {
+		dir, err := ioutil.TempDir("", "thriftgo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		be := new(GoBackend)
+		be.SetFilenameFunc(f)
+		return generateWith(t, be, dir, idls, true, opts...)
+	}
+
+	// DefaultFilename is the layout without a FilenameFunc.
+	files, err := run(DefaultFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := mustGenerate(t, idls); !reflect.DeepEqual(files, exp) {
+		t.Fatalf("expect the default layout %v, got %v", keysOf(exp), keysOf(files))
+	}
+
+	files, err = run(func(ast *parser.Thrift, ns string) (string, error) {
+		base := strings.TrimSuffix(filepath.Base(ast.Filename), ".thrift")
+		return path.Join("idl", base, "gen", path.Base(ns)+".go"), nil
+	}, "with_reflection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"idl/main/gen/main.go",
+		"idl/main/gen/main-reflection.go",
+		"idl/user_test/gen/user.go",
+		"idl/user_test/gen/user-reflection.go",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expect %s in %v", name, keysOf(files))
+		}
+	}
+
+	for rel, msg := range map[string]string{
+		"../main.go":   "escapes the output root",
+		"/tmp/main.go": "escapes the output root",
+		"main.txt":     `expect a .go file, got "main.txt"`,
+	} {
+		_, err = run(func(*parser.Thrift, string) (string, error) { return rel, nil })
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%s: unexpected error: %v", rel, err)
+		}
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example.main
+include "user_test.thrift"
+struct S { 1: user_test.U u }`},
+		{"user_test.thrift", `
+namespace go example.user
+struct U {}`},
+		{"other.thrift", `
+namespace go example.user
+struct O {}`},
+	}
+	run := func(tpl string, opts ...string) (map[string]string, error) {
+		dir, err := ioutil.TempDir("", "thriftgo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		req := &plugin.Request{Recursive: true, OutputTemplate: &tpl}
+		return generateRequest(t, new(GoBackend), dir, idls, req, opts...)
+	}
+
+	files, err := run("gen/{{.FileBase}}", "package_prefix=example.com/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	main, user := files["gen/main/main.go"], files["gen/user_test/user_test_.go"]
+	if !strings.Contains(main, "package main\n") || !strings.Contains(user, "package user\n") {
+		t.Fatalf("expect the packages named by the namespaces in %v", keysOf(files))
+	}
+	if !strings.Contains(main, `"example.com/m/gen/user_test"`) {
+		t.Fatalf("expect the include imported from its directory:\n%s", main)
+	}
+
+	idls[0][1] = strings.Replace(idls[0][1], "\n", "\ninclude \"other.thrift\"\n", 1)
+	if _, err = run("gen/{{.FileBase}}"); err == nil || !strings.Contains(err.Error(), "but are written to different directories") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = run("gen"); err == nil || !strings.Contains(err.Error(), "but are written to the same directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = run("{{.Namespace}}"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func keysOf(files map[string]string) (keys []string) {
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestTemplateDir(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Status { OK = 1 }
+struct S { 1: Status status }`}}
+
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Enum.tmpl", `
+// {{.GoName}} is overridden.
+type {{.GoName}} int64
+{{template "EnumValues" .}}
+{{define "EnumValues"}}
+{{- range .Values}}
+const {{.GoName}} {{$.GoName}} = {{.Value}}
+{{- end}}
+{{- end}}`)
+
+	main := mustGenerate(t, idls, "template_dir="+dir)["example/main.go"]
+	for _, s := range []string{
+		"// Status is overridden.\ntype Status int64\n",
+		"const Status_OK Status = 1\n",
+		"func (p *S) Read(iprot thrift.TProtocol) (err error) {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "func (p Status) String() string {") {
+		t.Fatalf("the default Enum template should be replaced:\n%s", main)
+	}
+
+	for name, content := range map[string]string{
+		"Unknown.tmpl":  `{{.GoName}}`,
+		"Typedef.tmpl":  `{{if .GoName}}`,
+		"Constant.tmpl": `{{template "Missing" .}}`,
+	} {
+		os.Remove(filepath.Join(dir, "Enum.tmpl"))
+		write(name, content)
+		if _, err := generate(t, idls, "template_dir="+dir); err == nil || !strings.Contains(err.Error(), "template_dir:") {
+			t.Fatalf("expect an error for %s, got %v", name, err)
+		}
+		os.Remove(filepath.Join(dir, name))
+	}
+}
+
+func TestConstExpr(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+include "base.thrift"
+enum Flags { READ = 1, WRITE = 2, RW = READ | WRITE }
+const i32 NEXT = base.BASE + 1
+const Flags DEFAULT_FLAGS = Flags.RW | base.Mode.X
+struct S { 1: Flags flags = Flags.READ | Flags.WRITE }`}, {"base.thrift", `
+namespace go example.base
+const i32 BASE = 0x100
+enum Mode { X = 4 }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"\tNEXT = 257\n",
+		"\tDEFAULTFLAGS = 7\n",
+		"\tFlags_RW    Flags = 3\n",
+		"\tp.Flags = 3\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	// the folded values do not refer to the included IDL
+	if strings.Contains(main, "example/base") {
+		t.Fatalf("unexpected import of the included IDL:\n%s", main)
+	}
+}
+
+func TestServiceExtends(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "base.thrift"
+service Mid extends base.Base { i32 Add(1: i32 a, 2: i32 b) }
+service Derived extends Mid {
+	void Fire()
+	string Ping(1: string s)
+}`},
+		{"base.thrift", `
+namespace go example.base
+service Base { string Ping(1: string s) }`},
+	}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"type Mid interface {\n\tbase.Base\n",
+		"type Derived interface {\n\tMid\n",
+		"\t\tBaseClient: base.NewBaseClientFactory(t, f),\n",
+		"\t\tMidClient: NewMidClientFactory(t, f),\n",
+		"self := &MidProcessor{base.NewBaseProcessor(handler)}",
+		"self := &DerivedProcessor{NewMidProcessor(handler)}",
+		`self.AddToProcessorMap("Ping", &derivedProcessorPing{handler: handler})`,
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tpl := `
+// {{.GoName}}:{{range .AllFunctions}} {{.Service.GoName}}.{{.GoName}}{{end}}`
+	if err = ioutil.WriteFile(filepath.Join(dir, "ThriftService.tmpl"), []byte(tpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	main = mustGenerate(t, idls, "template_dir="+dir)["example/main.go"]
+	for _, s := range []string{
+		"// Mid: Base.Ping Mid.Add\n",
+		"// Derived: Derived.Ping Mid.Add Derived.Fire\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenClientSingleton(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+service Base { void Ping() }
+service Foo extends Base { i32 Add(1: i32 a, 2: i32 b) }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "Singleton") {
+		t.Fatalf("unexpected singleton without gen_client_singleton:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "gen_client_singleton")["example/main.go"]
+	for _, s := range []string{
+		"func SetBaseClientTransportFactory(factory func() (thrift.TTransport, thrift.TProtocolFactory, error)) {",
+		"func GetBaseClient() (*BaseClient, error) {",
+		"func SetFooClientTransportFactory(factory func() (thrift.TTransport, thrift.TProtocolFactory, error)) {",
+		"func GetFooClient() (*FooClient, error) {",
+		"\tfooClientSingletonCurrent = &fooClientSingleton{factory: factory}\n",
+		"\t\ts.client = NewFooClientFactory(t, f)\n",
+		"\t\"sync\"\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenFutureClient(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+exception Oops { 1: string msg }
+service Base { void Ping() throws (1: Oops oops) }
+service Foo extends Base { i32 Add(1: i32 a, 2: i32 b) }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "Future") {
+		t.Fatalf("unexpected future without gen_future_client:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "gen_future_client")["example/main.go"]
+	for _, s := range []string{
+		"func (p *BaseClient) PingAsync(ctx context.Context) *BasePingFuture {",
+		"func (f *BasePingFuture) Get(ctx context.Context) (err error) {",
+		"func (p *FooClient) AddAsync(ctx context.Context, a int32, b int32) *FooAddFuture {",
+		"func (f *FooAddFuture) Get(ctx context.Context) (r int32, err error) {",
+		"		_future.r, _future.err = p.Add(ctx, a, b)\n",
+		"		return r, fmt.Errorf(\"FooAddFuture has already been awaited\")\n",
+		"	case <-f.ctx.Done():\n\t\treturn r, f.ctx.Err()\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestInitialisms(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct UserInfo {
+	1: i64 userId
+	2: string homepageUrl
+	3: string api_key
+}
+service UserService { UserInfo getByUserId(1: i64 userId) }`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if !strings.Contains(main, "\tUserId      int64  `thrift:\"userId,1\"") {
+		t.Fatalf("unexpected names without initialisms:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, cliOptions(t, "go:initialisms=k8s,url")...)["example/main.go"]
+	for _, s := range []string{
+		"\tUserID      int64  `thrift:\"userId,1\" json:\"userId\"`",
+		"\tHomepageURL string `thrift:\"homepageUrl,2\" json:\"homepageUrl\"`",
+		"\tAPIKey      string `thrift:\"api_key,3\" json:\"api_key\"`",
+		"func (p *UserInfo) GetHomepageURL() (v string) {",
+		"\tGetByUserID(ctx context.Context, userID int64) (r *UserInfo, err error)",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	_, err := generate(t, [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: i64 userId; 2: i64 userID }`}}, "initialisms=ID")
+	if err == nil || !strings.Contains(err.Error(), `field "userId" of struct "S" (`) ||
+		!strings.Contains(err.Error(), `are both named "UserID" in go with the initialisms ID`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = generate(t, idls, cliOptions(t, "go:initialisms=ID,X-Y")...)
+	if err == nil || !strings.Contains(err.Error(), `initialisms: invalid initialism "X-Y"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	for src, msg := range map[string]string{
+		"struct S {\n1: i64 my_name\n2: i64 myName\n}": `field "my_name" of struct "S" (main.thrift:2:1) and field "myName" of struct "S" (main.thrift:3:1) are both named "MyName" in go`,
+		"struct a_b {}\nunion aB {}":                   `struct "a_b" (main.thrift:1:1) and union "aB" (main.thrift:2:1) are both named "AB" in go`,
+		"const i32 a_b = 1\nenum aB {}":                `enum "aB" (main.thrift:2:1) and constant "a_b" (main.thrift:1:1) are both named "AB" in go`,
+		"service S {\nvoid get_x()\nvoid getX()\n}":    `function "get_x" of service "S" (main.thrift:2:1) and function "getX" of service "S" (main.thrift:3:1) are both named "GetX" in go`,
+		"typedef i32 a_b\nservice aB {}":               `service "aB" (main.thrift:2:1) and typedef "a_b" (main.thrift:1:1) are both named "AB" in go`,
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", src}})
+		if err == nil {
+			t.Fatalf("expect %q for %q", msg, src)
+		}
+		// trim the directory of the IDL
+		if got := regexp.MustCompile(`[^ (]*/main\.thrift`).ReplaceAllString(err.Error(), "main.thrift"); !strings.Contains(got, msg) {
+			t.Fatalf("expect %q, got: %v", msg, got)
+		}
+	}
+
+	files := mustGenerate(t, [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: i64 my_name; 2: i64 myName }`}}, "suffix_colliding_names")
+	for _, s := range []string{
+		"\tMyName  int64 `thrift:\"my_name,1\" json:\"my_name\"`",
+		"\tMyName_ int64 `thrift:\"myName,2\" json:\"myName\"`",
+	} {
+		if !strings.Contains(files["example/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+		}
+	}
+}
+
+func TestFieldNameAnnotation(t *testing.T) {
+	files := mustGenerate(t, [][2]string{{"main.thrift", `
+namespace go example
+struct S {
+	1: i64 user_id (go.name = "UID")
+	2: optional string display_name (go.name = "Nickname")
+}`}})
+	for _, s := range []string{
+		"\tUID      int64   `thrift:\"user_id,1\" json:\"user_id\"`",
+		"\tNickname *string `thrift:\"display_name,2,optional\" json:\"display_name,omitempty\"`",
+		"func (p *S) GetNickname() (v string) {",
+		"func (p *S) IsSetNickname() bool {",
+		"oprot.WriteFieldBegin(\"display_name\", thrift.STRING, 2)",
+	} {
+		if !strings.Contains(files["example/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+		}
+	}
+
+	src := "struct S {\n1: i64 user_id (go.name = \"Name\")\n2: string name\n}"
+	_, err := generate(t, [][2]string{{"main.thrift", src}})
+	msg := `field "user_id" of struct "S" (main.thrift:2:1) and field "name" of struct "S" (main.thrift:3:1) are both named "Name" in go`
+	if err == nil || !strings.Contains(regexp.MustCompile(`[^ (]*/main\.thrift`).ReplaceAllString(err.Error(), "main.thrift"), msg) {
+		t.Fatalf("expect %q, got: %v", msg, err)
+	}
+
+	files = mustGenerate(t, [][2]string{{"main.thrift", "namespace go example\n" + src}}, "suffix_colliding_names")
+	for _, s := range []string{
+		"\tName  int64  `thrift:\"user_id,1\" json:\"user_id\"`",
+		"\tName_ string `thrift:\"name,2\" json:\"name\"`",
+	} {
+		if !strings.Contains(files["example/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+		}
+	}
+
+	_, err = generate(t, [][2]string{{"main.thrift", "struct S { 1: i64 id (go.name = \"id\") }"}})
+	if err == nil || !strings.Contains(err.Error(), `go.name="id" is not an exported go identifier`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFieldNameAnnotationMethods(t *testing.T) {
+	clean := func(err error) string {
+		return regexp.MustCompile(`[^ (]*/main\.thrift`).ReplaceAllString(err.Error(), "main.thrift")
+	}
+	for _, c := range []struct{ src, msg string }{
+		{
+			"struct S {\n1: i64 user_id (go.name = \"UID\")\n2: string get_uid\n}",
+			`the getter of field "user_id" of struct "S" (main.thrift:2:1) and field "get_uid" of struct "S" (main.thrift:3:1) are both named "GetUID" in go, rename one of them with go.name`,
+		},
+		{
+			"struct S {\n1: string body (go.name = \"Read\")\n}",
+			`the method "Read" of struct "S" and field "body" of struct "S" (main.thrift:2:1) are both named "Read" in go`,
+		},
+		{
+			"struct S {\n1: optional i32 b (go.name = \"A\")\n2: optional string get_a_or_default\n}",
+			`the OrDefault getter of field "b" of struct "S" (main.thrift:2:1) and field "get_a_or_default" of struct "S" (main.thrift:3:1) are both named "GetAOrDefault" in go`,
+		},
+	} {
+		_, err := generate(t, [][2]string{{"main.thrift", c.src}}, "gen_optional_accessors")
+		if err == nil || !strings.Contains(clean(err), c.msg) {
+			t.Fatalf("expect %q, got: %v", c.msg, err)
+		}
+	}
+
+	// the names that collide without go.name are still suffixed
+	files := mustGenerate(t, [][2]string{{"main.thrift", "namespace go example\nstruct S {\n1: i64 uid\n2: string get_uid\n}"}})
+	if s := "\tGetUID_ string `thrift:\"get_uid,2\" json:\"get_uid\"`"; !strings.Contains(files["example/main.go"], s) {
+		t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+	}
+	files = mustGenerate(t, [][2]string{{"main.thrift", "namespace go example\nstruct S {\n1: i64 user_id (go.name = \"UID\")\n2: string get_uid\n}"}}, "suffix_colliding_names")
+	if s := "\tGetUID_ string `thrift:\"get_uid,2\" json:\"get_uid\"`"; !strings.Contains(files["example/main.go"], s) {
+		t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+	}
+}
+
+func TestReservedNames(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+const i32 ID = 1
+struct Table {
+	1: i64 id
+	2: optional string column
+	3: string row (go.name = "Column")
+}`}}
+	files := mustGenerate(t, idls, cliOptions(t, "go:reserved_names=ID,reserved_names=Table,Column,gen_setter")...)
+	for _, s := range []string{
+		"\tID_ = 1\n",
+		"type Table_ struct {",
+		"	ID_     int64   `thrift:\"id,1\" json:\"id\"`",
+		"	Column_ *string `thrift:\"column,2,optional\" json:\"column,omitempty\"`",
+		// the name given by go.name is not escaped
+		"	Column  string  `thrift:\"row,3\" json:\"row\"`",
+		"func (p *Table_) GetID_() (v int64) {",
+		"func (p *Table_) SetColumn_(val *string) {",
+		"func (p *Table_) IsSetColumn_() bool {",
+		"oprot.WriteStructBegin(\"Table\")",
+	} {
+		if !strings.Contains(files["example/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+		}
+	}
+
+	files = mustGenerate(t, idls, "reserved_names=Table", "reserved_names=Column", "reserved_suffix=Gen")
+	for _, s := range []string{
+		"type TableGen struct {",
+		"\tColumnGen *string `thrift:\"column,2,optional\" json:\"column,omitempty\"`",
+	} {
+		if !strings.Contains(files["example/main.go"], s) {
+			t.Fatalf("expect %q in:\n%s", s, files["example/main.go"])
+		}
+	}
+
+	for _, c := range []struct{ opt, err string }{
+		{"reserved_suffix=-", `reserved_suffix: "-" can not end a go identifier`},
+		{"reserved_names=a-b", `reserved_names: "a-b" is not a go identifier`},
+	} {
+		if _, err := generate(t, idls, c.opt); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("expect %q, got: %v", c.err, err)
+		}
+	}
+}
+
+func TestGenGetters(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Inner { 1: optional i32 x = 5; 2: string s = "s"; 3: i64 n }
+struct Outer { 1: optional Inner inner }`}}
+
+	main := mustGenerate(t, idls, "gen_getters")["example/main.go"]
+	for _, s := range []string{
+		"func (p *Inner) GetX() (v int32) {\n\tif p == nil || !p.IsSetX() {\n\t\treturn Inner_X_DEFAULT\n\t}\n",
+		"func (p *Inner) GetS() (v string) {\n\tif p != nil {\n\t\treturn p.S\n\t}\n\treturn \"s\"\n}",
+		"func (p *Inner) GetN() (v int64) {\n\tif p != nil {\n\t\treturn p.N\n\t}\n\treturn\n}",
+		"func (p *Outer) GetInner() (v *Inner) {\n\tif p == nil || !p.IsSetInner() {\n\t\treturn Outer_Inner_DEFAULT\n\t}\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestApacheRuntime(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: list<i32> l }
+service Svc { i32 Get(1: S s) }`}}
+
+	main := mustGenerate(t, idls, "runtime=cloudwego")["example/main.go"]
+	if !strings.Contains(main, "func (p *S) Read(iprot thrift.TProtocol) (err error) {") {
+		t.Fatalf("unexpected Read in:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "runtime=apache")["example/main.go"]
+	for _, s := range []string{
+		"func (p *S) Read(ctx context.Context, iprot thrift.TProtocol) (err error) {",
+		"func (p *S) ReadField1(ctx context.Context, iprot thrift.TProtocol) error {",
+		"_, size, err := iprot.ReadListBegin(ctx)",
+		"if err = iprot.Skip(ctx, fieldTypeId); err != nil {",
+		"func (p *S) Write(ctx context.Context, oprot thrift.TProtocol) (err error) {",
+		"if err = oprot.WriteFieldBegin(ctx, \"l\", thrift.LIST, 1); err != nil {",
+		"if err := oprot.WriteI32(ctx, v); err != nil {",
+		"if _, err = p.Client_().Call(ctx, \"Get\", &_args, &_result); err != nil {",
+		"if err2 := args.Read(ctx, iprot); err2 != nil {",
+		"x.Write(ctx, oprot)",
+		"return true, thrift.WrapTException(err2)",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	for opts, msg := range map[string]string{
+		"runtime=apache,runtime=cloudwego": `runtime: "cloudwego" conflicts with "apache", only one runtime can be used`,
+		"runtime=thrift":                   `runtime: unknown runtime "thrift"`,
+		"runtime=apache,ctx_rw":            "runtime=apache can not be used with ctx_rw",
+		"runtime=apache,template=slim":     "runtime=apache can not be used with template=slim",
+	} {
+		_, err := generate(t, idls, strings.Split(opts, ",")...)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expect %q for %s, got: %v", msg, opts, err)
+		}
+	}
+}
+
+func TestTypedefChainAcrossFiles(t *testing.T) {
+	files := mustGenerate(t, [][2]string{
+		{"c.thrift", `
+namespace go example.c
+include "b.thrift"
+typedef Late Early
+typedef b.EventTime Late
+typedef b.Level Priority
+struct Event {
+	1: Early at
+	2: list<Late> history
+	3: Priority priority = Priority.HIGH
+}
+const Early START = 10
+const Priority DEFAULT_PRIORITY = b.Level.LOW`},
+		{"b.thrift", `
+namespace go example.b
+include "a.thrift"
+typedef a.Timestamp EventTime
+typedef a.Severity Level`},
+		{"a.thrift", `
+namespace go example.a
+typedef i64 Timestamp
+enum Severity { LOW = 1, HIGH = 2 }`},
+	})
+	c := files["example/c/c.go"]
+	for _, s := range []string{
+		"type Late = b.EventTime\n\ntype Early = Late\n",
+		"\tAt       Early    `thrift:\"at,1\" json:\"at\"`",
+		"\tHistory  []Late   `thrift:\"history,2\" json:\"history\"`",
+		"\tDEFAULTPRIORITY = a.Severity_LOW\n",
+		"\t\tPriority: a.Severity_HIGH,\n",
+		"\t\"example/a\"\n",
+	} {
+		if !strings.Contains(c, s) {
+			t.Fatalf("expect %q in:\n%s", s, c)
+		}
+	}
+	if b := files["example/b/b.go"]; !strings.Contains(b, "type EventTime = a.Timestamp\n") {
+		t.Fatalf("unexpected typedef in:\n%s", b)
+	}
+}
+
+func TestTypedefAsType(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Inner { 1: i32 x }
+typedef i64 UserID
+typedef Inner In2
+typedef list<UserID> IDs
+struct S { 1: UserID id; 2: IDs ids; 3: In2 in }`}}
+
+	main := mustGenerate(t, idls, "typedef_as_type")["example/main.go"]
+	for _, s := range []string{
+		"type UserID int64\n",
+		"type IDs []UserID\n",
+		"type In2 = Inner\n",
+		"_field = UserID(v)",
+		"_elem = UserID(v)",
+		"if err := oprot.WriteI64(int64(p.ID)); err != nil {",
+		"if err := oprot.WriteI64(int64(v)); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestExclude(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+namespace go example
+include "a.thrift"
+include "b.thrift"
+struct S { 1: a.A a; 2: b.B b }`},
+		{"a.thrift", "namespace go example.a\nstruct A { 1: i32 x }"},
+		{"b.thrift", "namespace go example.b\nstruct B { 1: i32 y }"},
+	}
+
+	res := mustGenerate(t, idls, "exclude=**/a.thrift")
+	if _, ok := res["example/a/a.go"]; ok {
+		t.Fatal("unexpected codes for the excluded a.thrift")
+	}
+	if _, ok := res["example/b/b.go"]; !ok {
+		t.Fatal("expect codes for b.thrift")
+	}
+	if !strings.Contains(res["example/main.go"], "A *a.A `thrift:\"a,1\"") {
+		t.Fatalf("expect the field of the excluded type in:\n%s", res["example/main.go"])
+	}
+
+	if _, err := generate(t, idls, "exclude=["); err == nil || !strings.Contains(err.Error(), `exclude: invalid pattern "["`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenOptionalAccessors(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Kind { A = 1 }
+struct S {
+  1: optional i32 x
+  2: optional Kind k
+  3: optional i32 d = 4
+  4: optional binary b
+  5: optional bool x_or_default
+}`}}
+
+	main := mustGenerate(t, idls, "gen_optional_accessors")["example/main.go"]
+	for _, s := range []string{
+		"func (p *S) GetXOrDefault_(def int32) int32 {\n\tif p == nil || !p.IsSetX() {\n\t\treturn def\n\t}\n\treturn *p.X\n}",
+		"func (p *S) SetX(v int32) {\n\tp.X = &v\n}",
+		"func (p *S) GetKOrDefault(def Kind) Kind {",
+		"func (p *S) SetK(v Kind) {",
+		"func (p *S) GetXOrDefault() (v bool) {",
+		"func (p *S) GetXOrDefaultOrDefault(def bool) bool {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	for _, s := range []string{"GetDOrDefault", "GetBOrDefault"} {
+		if strings.Contains(main, s) {
+			t.Fatalf("unexpected %q in:\n%s", s, main)
+		}
+	}
+
+	if _, err := generate(t, idls, "gen_optional_accessors", "gen_setter"); err == nil || !strings.Contains(err.Error(), "can not be used with gen_setter") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRequiredness(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct Inner { 1: i32 a }
+struct S {
+  1: required i32 r
+  2: i32 d
+  3: optional i32 o
+  4: required Inner ri
+  5: Inner di
+  6: optional Inner oi
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		// The tags keep the three requiredness.
+		"`thrift:\"r,1,required\" ",
+		"`thrift:\"d,2\" ",
+		"`thrift:\"o,3,optional\" ",
+		// A required field must be present on read.
+		"var issetR bool = false",
+		"var issetRi bool = false",
+		"if !issetR {\n\t\tfieldId = 1\n\t\tgoto RequiredFieldNotSetError\n\t}",
+		"if !issetRi {\n\t\tfieldId = 4\n\t\tgoto RequiredFieldNotSetError\n\t}",
+		// Required and default fields are always written.
+		"func (p *S) writeField1(oprot thrift.TProtocol) (err error) {\n\tif err = oprot.WriteFieldBegin(\"r\", thrift.I32, 1); err != nil {",
+		"func (p *S) writeField2(oprot thrift.TProtocol) (err error) {\n\tif err = oprot.WriteFieldBegin(\"d\", thrift.I32, 2); err != nil {",
+		"func (p *S) writeField5(oprot thrift.TProtocol) (err error) {\n\tif err = oprot.WriteFieldBegin(\"di\", thrift.STRUCT, 5); err != nil {",
+		// An optional field is written only when it is set.
+		"func (p *S) writeField3(oprot thrift.TProtocol) (err error) {\n\tif p.IsSetO() {",
+		"func (p *S) writeField6(oprot thrift.TProtocol) (err error) {\n\tif p.IsSetOi() {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	// The absence of a default or an optional field is tolerated on read.
+	for _, s := range []string{"issetD", "issetO", "issetDi", "issetOi"} {
+		if strings.Contains(main, s) {
+			t.Fatalf("unexpected %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestByteAndI8(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+const map<byte, i8> CM = {1: -2}
+struct S {
+  1: byte b = 3
+  2: map<byte, i8> m
+  3: list<byte> l
+}`}}
+
+	main := mustGenerate(t, idls, "frugal_tag")["example/main.go"]
+	for _, s := range []string{
+		"CM = map[int8]int8{\n\t\t1: -2,\n\t}",
+		"B int8          `thrift:\"b,1\" frugal:\"1,default,byte\" json:\"b\"`",
+		"M map[int8]int8 `thrift:\"m,2\" frugal:\"2,default,map<byte:i8>\" json:\"m\"`",
+		"L []int8        `thrift:\"l,3\" frugal:\"3,default,list<byte>\" json:\"l\"`",
+		"if err = oprot.WriteFieldBegin(\"b\", thrift.BYTE, 1); err != nil {",
+		"if err := oprot.WriteMapBegin(thrift.BYTE, thrift.BYTE, len(p.M)); err != nil {",
+		"if err := oprot.WriteByte(k); err != nil {",
+		"if err := oprot.WriteByte(v); err != nil {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S { 1: i32 x }
+typedef S T`}}
+
+	main := mustGenerate(t, idls, "gen_json_methods")["example/main.go"]
+	if !strings.Contains(main, `return fmt.Errorf("S.%s: %w", key, err)`) {
+		t.Fatalf("expect %%w in:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "gen_json_methods", "go_version=1.12", "use_type_alias=false")["example/main.go"]
+	for _, s := range []string{
+		`return fmt.Errorf("S.%s: %v", key, err)`,
+		"type T S\n",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	for opts, msg := range map[string]string{
+		"go_version=1.8":                 "use_type_alias generates type aliases, which require go 1.9, but go_version is 1.8",
+		"go_version=go1.8.7,code_ref":    "go_version is 1.8",
+		"go_version=1.8,typedef_as_type": "typedef_as_type generates type aliases",
+		"go_version=2.1":                 `go_version: invalid go version "2.1"`,
+	} {
+		if _, err := generate(t, idls, strings.Split(opts, ",")...); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%s: unexpected error: %v", opts, err)
+		}
+	}
+}
+
+func TestGenPresence(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Kind { A = 1 }
+struct S {
+  1: optional i32 x
+  2: optional Kind k
+  3: optional i32 d = 4
+  4: optional binary b
+}
+const S CS = {"x": 1}
+service Svc { void f(1: optional i32 x) }`}}
+
+	main := mustGenerate(t, idls, "gen_presence=bitset")["example/main.go"]
+	for _, s := range []string{
+		"\t_presence [1]uint64\n}",
+		"func (p *S) SetX(val int32) {\n\tp.X = val\n\tp._presence[0] |= 1 << 0\n}",
+		"func (p *S) ClearK() {\n\tvar zero Kind\n\tp.K = zero\n\tp._presence[0] &^= 1 << 1\n}",
+		"func (p *S) IsSetX() bool {\n\treturn p._presence[0]&(1<<0) != 0\n}",
+		"B         []byte `thrift:\"b,4,optional\"",
+		"p := &S{}\n\t\tp.SetX(1)\n\t\treturn p",
+		"X *int32 `thrift:\"x,1,optional\"",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "ClearD") {
+		t.Fatalf("unexpected ClearD in:\n%s", main)
+	}
+
+	if _, err := generate(t, idls, "gen_presence=bitset", "frugal_tag"); err == nil || !strings.Contains(err.Error(), "can not be used with frugal_tag") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := generate(t, idls, "gen_presence=bits"); err == nil || !strings.Contains(err.Error(), `unknown representation "bits"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOneway(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+service Log {
+  oneway void log(1: string msg)
+  void ping()
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	for _, s := range []string{
+		"\t// Log is a oneway method: the client sends the request without waiting for a response.\n\tLog(ctx context.Context, msg string) (err error)\n",
+		"\n\tPing(ctx context.Context) (err error)\n",
+		"type LogPingResult struct {",
+		`err = p.Client_().Call(ctx, "log", &_args, nil); err != nil {`,
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	for _, s := range []string{"LogLogResult", `oprot.WriteMessageBegin("log", thrift.REPLY`} {
+		if strings.Contains(main, s) {
+			t.Fatalf("unexpected %q in:\n%s", s, main)
+		}
+	}
+}
+
+func TestGenOnewayResult(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+exception E {}
+service Svc {
+  oneway void fire(1: string msg)
+  void ping()
+  i32 get(1: i32 id) throws (1: E e)
+  oneway void notify()
+}`}}
+
+	main := mustGenerate(t, idls, "gen_oneway_result")["example/main.go"]
+	for _, m := range []string{"Fire", "Ping", "Get", "Notify"} {
+		for _, s := range []string{"type Svc" + m + "Args struct {", "type Svc" + m + "Result struct {", "func NewSvc" + m + "Result() *Svc" + m + "Result {"} {
+			if !strings.Contains(main, s) {
+				t.Fatalf("expect %q in:\n%s", s, main)
+			}
+		}
+	}
+	for _, s := range []string{
+		"type SvcFireResult struct {\n}",
+		`err = p.Client_().Call(ctx, "fire", &_args, nil); err != nil {`,
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, `oprot.WriteMessageBegin("fire", thrift.REPLY`) {
+		t.Fatalf("unexpected reply of a oneway method in:\n%s", main)
+	}
+}
+
+func TestGenSplitRead(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+struct S {
+  1: i32 a
+  -2: list<string> b
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if !strings.Contains(main, "func (p *S) ReadField1(iprot thrift.TProtocol) error {") {
+		t.Fatalf("expect exported ReadField1 by default in:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "gen_split_read")["example/main.go"]
+	for _, s := range []string{
+		"if err = p.readField1(iprot); err != nil {",
+		"if err = p.readField_2(iprot); err != nil {",
+		"func (p *S) readField1(iprot thrift.TProtocol) error {",
+		"func (p *S) readField_2(iprot thrift.TProtocol) error {",
+		`return thrift.PrependError(fmt.Sprintf("%T read field %d '%s' error: ", p, fieldId, fieldIDToName_S[fieldId]), err)`,
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "ReadField1") {
+		t.Fatalf("unexpected ReadField1 in:\n%s", main)
+	}
+}
+
+func TestDeprecatedAnnotation(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `
+namespace go example
+enum Status {
+  OK = 1
+  // legacy
+  LEGACY = 2 (deprecated = "use OK instead")
+  OLD = 3 (deprecated = "")
+} (deprecated = "use Code")
+typedef i32 Code (deprecated = "")
+// s
+struct S {
+  1: i32 a (deprecated = "use b")
+  2: i32 b
+} (deprecated = "use T")
+service Svc {
+  // get
+  i32 get(1: i32 id) (deprecated = "use fetch")
+  oneway void fire() (deprecated = "")
+}`}}
+
+	main := mustGenerate(t, idls)["example/main.go"]
+	if strings.Contains(main, "Deprecated") {
+		t.Fatalf("unexpected deprecation in:\n%s", main)
+	}
+
+	main = mustGenerate(t, idls, "deprecated_annotation=deprecated", "reserve_comments")["example/main.go"]
+	for _, s := range []string{
+		"\tStatus_OK Status = 1\n\t// legacy\n\t//\n\t// Deprecated: use OK instead\n\tStatus_LEGACY Status = 2\n",
+		"\t// Deprecated: do not use.\n\tStatus_OLD Status = 3\n",
+		"// Deprecated: use Code\ntype Status int64\n",
+		"// Deprecated: do not use.\ntype Code = int32\n",
+		"// s\n//\n// Deprecated: use T\ntype S struct {\n\t// Deprecated: use b\n\tA int32 ",
+		"\t// get\n\t//\n\t// Deprecated: use fetch\n\tGet(ctx context.Context, iD int32) (r int32, err error)\n",
+		"\t// Fire is a oneway method: the client sends the request without waiting for a response.\n\t//\n\t// Deprecated: do not use.\n\tFire(ctx context.Context) (err error)\n",
+		"// Deprecated: use fetch\nfunc (p *SvcClient) Get(ctx context.Context, iD int32) (r int32, err error) {",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	idls[0][1] = `
+namespace go example
+struct S {
+  1: i32 a (status = "deprecated")
+  2: i32 b (status = "active")
+  3: i32 c (status = "active", status = " deprecated ")
+}`
+	main = mustGenerate(t, idls, cliOptions(t, "go:deprecated_annotation=status=deprecated")...)["example/main.go"]
+	for _, s := range []string{
+		"type S struct {\n\t// Deprecated: do not use.\n\tA int32 ",
+		"\n\t// Deprecated: do not use.\n\tC int32 ",
+	} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "Deprecated: do not use.\n\tB int32 ") {
+		t.Fatalf("unexpected deprecation of b in:\n%s", main)
+	}
+
+	if _, err := generate(t, idls, "deprecated_annotation="); err == nil || !strings.Contains(err.Error(), "empty annotation key") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNamespaceFallback(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `
+include "BaseTypes.thrift"
+include "explicit.thrift"
+struct S {
+	1: BaseTypes.B b
+	2: explicit.E e
+}`},
+		{"BaseTypes.thrift", `struct B {}`},
+		{"explicit.thrift", `namespace go example.explicit
+struct E {}`},
+	}
+
+	files := mustGenerate(t, idls, "namespace_fallback=acme.{{lower .FileBase}}", "package_prefix=x")
+	for _, name := range []string{"acme/main/main.go", "acme/basetypes/BaseTypes.go", "example/explicit/explicit.go"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expect %s in %v", name, files)
+		}
+	}
+	main := files["acme/main/main.go"]
+	for _, s := range []string{"\"x/acme/basetypes\"", "\"x/example/explicit\"", "B *basetypes.B"} {
+		if !strings.Contains(main, s) {
+			t.Fatalf("expect %q in:\n%s", s, main)
+		}
+	}
+
+	if _, err := generate(t, idls, "namespace_fallback={{.Unknown}}"); err == nil || !strings.Contains(err.Error(), "namespace_fallback:") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenTypeRegistry(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `namespace go example
+include "other.thrift"
+include "types.thrift"
+enum Color { RED = 1 }
+struct S { 1: other.O o, 2: types.T t }
+union U { 1: string a }
+exception E {}
+service Svc { void f() }`},
+		{"other.thrift", `namespace go example
+struct O {}`},
+		{"types.thrift", `namespace go example.types
+typedef i64 ID
+struct T {}`},
+	}
+
+	files := mustGenerate(t, idls, "gen_type_registry")
+	for name, ss := range map[string][]string{
+		"example/main.go": {
+			`thriftTypeRegistry["example.Color"] = func() interface{} { return new(Color) }`,
+			`thriftTypeRegistry["example.S"] = func() interface{} { return NewS() }`,
+			`thriftTypeRegistry["example.U"] = func() interface{} { return NewU() }`,
+			`thriftTypeRegistry["example.E"] = func() interface{} { return NewE() }`,
+		},
+		"example/other.go":               {`thriftTypeRegistry["example.O"] = func() interface{} { return NewO() }`},
+		"example/types/types.go":         {`thriftTypeRegistry["example.types.T"] = func() interface{} { return NewT() }`},
+		"example/type-registry.go":       {"package example", "func LookupType(name string) (interface{}, bool) {"},
+		"example/types/type-registry.go": {"package types", "var thriftTypeRegistry = "},
+	} {
+		content, ok := files[name]
+		if !ok {
+			t.Fatalf("expect %s in %v", name, files)
+		}
+		for _, s := range ss {
+			if !strings.Contains(content, s) {
+				t.Fatalf("expect %q in %s:\n%s", s, name, content)
+			}
+		}
+	}
+	if main := files["example/main.go"]; strings.Contains(main, `"example.Svc`) {
+		t.Fatalf("expect no registration of the argument and result types:\n%s", main)
+	}
+	if len(files) != 5 {
+		t.Fatalf("expect one registry for each package, got %d files", len(files))
+	}
+
+	if _, ok := mustGenerate(t, idls)["example/type-registry.go"]; ok {
+		t.Fatal("expect no registry without gen_type_registry")
+	}
+	if _, err := generate(t, idls, "gen_type_registry", "template=raw_struct"); err == nil || !strings.Contains(err.Error(), "template=raw_struct") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenIndex(t *testing.T) {
+	idls := [][2]string{
+		{"main.thrift", `namespace go example
+include "other.thrift"
+include "types.thrift"
+enum Color { RED = 1 }
+struct S { 1: other.O o, 3: types.ID id }
+union U { 1: string a }
+service Svc { void f() }`},
+		{"other.thrift", `namespace go example
+exception O { 2: string msg }`},
+		{"types.thrift", `namespace go example.types
+typedef i64 ID`},
+	}
+
+	files := mustGenerate(t, idls, "gen_index")
+	index := files["example/index.go"]
+	for _, s := range []string{
+		"var ThriftTypes = []reflect.Type{\n" +
+			"\treflect.TypeOf((*O)(nil)).Elem(),\n" +
+			"\treflect.TypeOf((*Color)(nil)).Elem(),\n" +
+			"\treflect.TypeOf((*S)(nil)).Elem(),\n" +
+			"\treflect.TypeOf((*U)(nil)).Elem(),\n}",
+		"var ThriftFieldNames = map[string]string{\n" +
+			"\t\"O.2\": \"Msg\",\n" +
+			"\t\"S.1\": \"O\",\n" +
+			"\t\"S.3\": \"ID\",\n" +
+			"\t\"U.1\": \"A\",\n}",
+	} {
+		if !strings.Contains(index, s) {
+			t.Fatalf("expect %q in:\n%s", s, index)
+		}
+	}
+	// a package without types has no index
+	if _, ok := files["example/types/index.go"]; ok {
+		t.Fatal("unexpected index for the package of types.thrift")
+	}
+	if again := mustGenerate(t, idls, "gen_index")["example/index.go"]; again != index {
+		t.Fatalf("expect the same index, got:\n%s", again)
+	}
+	if _, ok := mustGenerate(t, idls)["example/index.go"]; ok {
+		t.Fatal("expect no index without gen_index")
+	}
+}
+
+func TestGenRequiredCheck(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `namespace go example
+struct Address { 1: required string city 2: required list<string> lines }
+struct User { 1: required i64 id 2: required Address address 3: optional Address billing
+	4: list<Address> history 5: map<string, Address> named 6: list<list<Address>> nested 7: string validate }`}}
+
+	code := mustGenerate(t, idls, "gen_required_check")["example/main.go"]
+	for _, s := range []string{
+		"\tfor i, v := range p.History {\n\t\tif err := v.Validate(); err != nil {\n\t\t\treturn fmt.Errorf(\"history[%d].%w\", i, err)\n",
+		"\tfor k, v := range p.Named {\n\t\tif err := v.Validate(); err != nil {\n\t\t\treturn fmt.Errorf(\"named[%v].%w\", k, err)\n",
+		"\tValidate_ string",
+		"func (p *User) Validate() error {",
+		"\tif p.Address == nil {\n\t\treturn fmt.Errorf(\"%s: required field is not set\", \"address\")\n\t}",
+		"\tif err := p.Address.Validate(); err != nil {\n\t\treturn fmt.Errorf(\"address.%w\", err)\n\t}",
+		"\tif err := p.Billing.Validate(); err != nil {",
+		"\tif p.Lines == nil {",
+	} {
+		if !strings.Contains(code, s) {
+			t.Fatalf("expect %q in:\n%s", s, code)
+		}
+	}
+	// a required field that can not be nil is always set
+	if strings.Contains(code, "p.City == nil") || strings.Contains(code, "p.ID == nil") {
+		t.Fatalf("unexpected check of a non-nillable field in:\n%s", code)
+	}
+	if strings.Contains(code, "Validate(); err != nil {\n\t\treturn thrift.PrependError") {
+		t.Fatalf("expect Write not to call Validate without required_check_on_write:\n%s", code)
+	}
+
+	code = mustGenerate(t, idls, "required_check_shallow")["example/main.go"]
+	if !strings.Contains(code, "func (p *User) Validate() error {") || strings.Contains(code, "p.Address.Validate()") {
+		t.Fatalf("expect a shallow Validate in:\n%s", code)
+	}
+
+	code = mustGenerate(t, idls, "required_check_on_write")["example/main.go"]
+	if !strings.Contains(code, "\tif err = p.Validate(); err != nil {\n\t\treturn thrift.PrependError(fmt.Sprintf(\"%T write error: \", p), err)\n\t}") {
+		t.Fatalf("expect Write to call Validate in:\n%s", code)
+	}
+
+	if code = mustGenerate(t, idls)["example/main.go"]; strings.Contains(code, ") Validate() error {") {
+		t.Fatalf("expect no Validate without gen_required_check:\n%s", code)
+	}
+}
+
+func TestProtocols(t *testing.T) {
+	idls := [][2]string{{"main.thrift", `namespace go example
+struct Item { 1: required string name 2: optional bool done 3: list<i64> ids }
+union Choice { 1: i32 a 2: Item b }`}}
+
+	// 'compact' is split from 'protocols=binary,compact' by the command line
+	code := mustGenerate(t, idls, "protocols=binary", "compact")["example/main.go"]
+	for _, s := range []string{
+		"func (p *Item) AppendBinary(b []byte) (_ []byte, err error) {",
+		"func (p *Item) ReadBinary(b []byte) (n int, err error) {",
+		"func (p *Item) AppendCompact(b []byte) (_ []byte, err error) {",
+		"func (p *Item) ReadCompact(b []byte) (n int, err error) {",
+		"\t\tif p.IsSetDone() {\n\t\t\tb = codec.Compact.AppendBoolField(b, 2, last, *p.Done)\n",
+		"\t\tb = codec.Compact.AppendListBegin(b, codec.I64, len(p.Ids))\n",
+		"if v, l, err := codec.Compact.ReadBoolField(b[n:], f); err != nil {",
+		"\tif !issetName {\n\t\treturn n, fmt.Errorf(\"%T read error: required field name is not set\", p)\n\t}",
+		"\tif c := p.CountSetFieldsChoice(); c != 1 {",
+		"\t\t\tif b, err = p.B.AppendCompact(b); err != nil {",
+	} {
+		if !strings.Contains(code, s) {
+			t.Fatalf("expect %q in:\n%s", s, code)
+		}
+	}
+
+	code = mustGenerate(t, idls, "protocols=compact")["example/main.go"]
+	if !strings.Contains(code, "ReadCompact(") || strings.Contains(code, "ReadBinary(") {
+		t.Fatalf("expect only the compact protocol in:\n%s", code)
+	}
+	if code = mustGenerate(t, idls)["example/main.go"]; strings.Contains(code, "codec.") {
+		t.Fatalf("expect no codec without protocols:\n%s", code)
+	}
+
+	for opts, msg := range map[[2]string]string{
+		{"protocols=json", ""}:                       `unknown protocol "json"`,
+		{"protocols=binary", "gen_buffer_reuse"}:     "protocols can not be used with gen_buffer_reuse",
+		{"protocols=compact", "keep_unknown_fields"}: "protocols can not be used with keep_unknown_fields",
+		{"protocols=compact", "with_field_mask"}:     "protocols can not be used with with_field_mask",
+		{"protocols=compact", "template=raw_struct"}: "protocols can not be used with template=raw_struct",
+	} {
+		if _, err := generate(t, idls, opts[:]...); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%v: expect an error containing %q, got %v", opts, msg, err)
+		}
+	}
+	mustGenerate(t, idls, "protocols=compact", "gen_buffer_reuse")
+
+	// the includes are not shadowed by the variables of the codec methods
+	idls = [][2]string{
+		{"main.thrift", "namespace go example\ninclude \"b.thrift\"\nstruct S { 1: b.B x }"},
+		{"b.thrift", "namespace go b\nstruct B {}"},
+	}
+	code = mustGenerate(t, idls, "protocols=binary")["example/main.go"]
+	for _, s := range []string{"\tb0 \"b\"\n", "_field := b0.NewB()"} {
+		if !strings.Contains(code, s) {
+			t.Fatalf("expect %q in:\n%s", s, code)
+		}
+	}
+	if code = mustGenerate(t, idls)["example/main.go"]; !strings.Contains(code, "_field := b.NewB()") {
+		t.Fatalf("expect the include as b without protocols:\n%s", code)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// errorMessageAnnotation selects the string field of an exception that the Error
+// method reports. An empty value makes the Error method report all the fields.
+const errorMessageAnnotation = "go.error_message"
+
+// buildErrorMessages finds the fields that the Error methods of the exceptions
+// report.
+func (s *Scope) buildErrorMessages() error {
+	for _, st := range s.exceptions {
+		values := st.Annotations.Get(errorMessageAnnotation)
+		if len(values) == 0 {
+			// without the annotation, the first string field named "message" or "msg" in any case
+			for _, f := range st.fields {
+				if name := strings.ToLower(f.Name); isErrorMessage(f.Field) && (name == "message" || name == "msg") {
+					st.errorMessage = f
+					break
+				}
+			}
+			continue
+		}
+		what := s.describeStructLike(st)
+		if len(values) > 1 {
+			return fmt.Errorf("%s: %s is set more than once", what, errorMessageAnnotation)
+		}
+		if values[0] == "" {
+			continue
+		}
+		f := st.Field(values[0])
+		if f == nil {
+			return fmt.Errorf("%s: no field %q for %s", what, values[0], errorMessageAnnotation)
+		}
+		if !isErrorMessage(f.Field) {
+			return fmt.Errorf("%s: the field %q for %s is not a string", what, values[0], errorMessageAnnotation)
+		}
+		st.errorMessage = f
+	}
+	return nil
+}
+
+func isErrorMessage(f *parser.Field) bool {
+	return f.Type.Category == parser.Category_String
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// BinaryProtocol is the type of Binary.
+type BinaryProtocol struct{}
+
+// Binary encodes and decodes values with the binary protocol, which writes the
+// numbers in big endian with fixed widths and the type of each field and
+// element.
+var Binary BinaryProtocol
+
+// AppendFieldBegin appends the header of a field. The last field ID is not used
+// by the binary protocol.
+func (BinaryProtocol) AppendFieldBegin(b []byte, typeID byte, id, last int16) []byte {
+	return append(b, typeID, byte(uint16(id)>>8), byte(id))
+}
+
+// AppendBoolField appends the header and the value of a bool field.
+func (p BinaryProtocol) AppendBoolField(b []byte, id, last int16, v bool) []byte {
+	return p.AppendBool(p.AppendFieldBegin(b, BOOL, id, last), v)
+}
+
+// AppendFieldStop appends the end of the fields of a struct.
+func (BinaryProtocol) AppendFieldStop(b []byte) []byte {
+	return append(b, STOP)
+}
+
+// AppendBool appends a bool.
+func (BinaryProtocol) AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// AppendI8 appends an i8.
+func (BinaryProtocol) AppendI8(b []byte, v int8) []byte {
+	return append(b, byte(v))
+}
+
+// AppendI16 appends an i16.
+func (BinaryProtocol) AppendI16(b []byte, v int16) []byte {
+	return append(b, byte(uint16(v)>>8), byte(v))
+}
+
+// AppendI32 appends an i32.
+func (BinaryProtocol) AppendI32(b []byte, v int32) []byte {
+	u := uint32(v)
+	return append(b, byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+// AppendI64 appends an i64.
+func (BinaryProtocol) AppendI64(b []byte, v int64) []byte {
+	u := uint64(v)
+	return append(b, byte(u>>56), byte(u>>48), byte(u>>40), byte(u>>32),
+		byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+// AppendDouble appends a double.
+func (p BinaryProtocol) AppendDouble(b []byte, v float64) []byte {
+	return p.AppendI64(b, int64(math.Float64bits(v)))
+}
+
+// AppendString appends a string.
+func (p BinaryProtocol) AppendString(b []byte, v string) []byte {
+	return append(p.AppendI32(b, int32(len(v))), v...)
+}
+
+// AppendBinary appends a binary.
+func (p BinaryProtocol) AppendBinary(b []byte, v []byte) []byte {
+	return append(p.AppendI32(b, int32(len(v))), v...)
+}
+
+// AppendListBegin appends the header of a list.
+func (p BinaryProtocol) AppendListBegin(b []byte, elemType byte, size int) []byte {
+	return p.AppendI32(append(b, elemType), int32(size))
+}
+
+// AppendSetBegin appends the header of a set.
+func (p BinaryProtocol) AppendSetBegin(b []byte, elemType byte, size int) []byte {
+	return p.AppendListBegin(b, elemType, size)
+}
+
+// AppendMapBegin appends the header of a map.
+func (p BinaryProtocol) AppendMapBegin(b []byte, keyType, valueType byte, size int) []byte {
+	return p.AppendI32(append(b, keyType, valueType), int32(size))
+}
+
+// ReadFieldBegin reads the header of a field. The last field ID is not used by
+// the binary protocol.
+func (BinaryProtocol) ReadFieldBegin(b []byte, last int16) (Field, int, error) {
+	if len(b) < 1 {
+		return Field{}, 0, ErrTruncated
+	}
+	if b[0] == STOP {
+		return Field{}, 1, nil
+	}
+	if len(b) < 3 {
+		return Field{}, 0, ErrTruncated
+	}
+	return Field{Type: b[0], ID: int16(binary.BigEndian.Uint16(b[1:]))}, 3, nil
+}
+
+// ReadBoolField reads the value of a bool field whose header is f.
+func (p BinaryProtocol) ReadBoolField(b []byte, f Field) (bool, int, error) {
+	return p.ReadBool(b)
+}
+
+// ReadBool reads a bool.
+func (BinaryProtocol) ReadBool(b []byte) (bool, int, error) {
+	if len(b) < 1 {
+		return false, 0, ErrTruncated
+	}
+	return b[0] != 0, 1, nil
+}
+
+// ReadI8 reads an i8.
+func (BinaryProtocol) ReadI8(b []byte) (int8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrTruncated
+	}
+	return int8(b[0]), 1, nil
+}
+
+// ReadI16 reads an i16.
+func (BinaryProtocol) ReadI16(b []byte) (int16, int, error) {
+	if len(b) < 2 {
+		return 0, 0, ErrTruncated
+	}
+	return int16(binary.BigEndian.Uint16(b)), 2, nil
+}
+
+// ReadI32 reads an i32.
+func (BinaryProtocol) ReadI32(b []byte) (int32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrTruncated
+	}
+	return int32(binary.BigEndian.Uint32(b)), 4, nil
+}
+
+// ReadI64 reads an i64.
+func (BinaryProtocol) ReadI64(b []byte) (int64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrTruncated
+	}
+	return int64(binary.BigEndian.Uint64(b)), 8, nil
+}
+
+// ReadDouble reads a double.
+func (BinaryProtocol) ReadDouble(b []byte) (float64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrTruncated
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b)), 8, nil
+}
+
+// readLength reads the length of a string or a binary.
+func (p BinaryProtocol) readLength(b []byte) (int, int, error) {
+	size, n, err := p.ReadI32(b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if size < 0 {
+		return 0, 0, ErrNegativeSize
+	}
+	if int(size) > len(b)-n {
+		return 0, 0, ErrTruncated
+	}
+	return int(size), n, nil
+}
+
+// ReadString reads a string.
+func (p BinaryProtocol) ReadString(b []byte) (string, int, error) {
+	size, n, err := p.readLength(b)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b[n : n+size]), n + size, nil
+}
+
+// ReadBinary reads a binary into a new slice.
+func (p BinaryProtocol) ReadBinary(b []byte) ([]byte, int, error) {
+	size, n, err := p.readLength(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append([]byte{}, b[n:n+size]...), n + size, nil
+}
+
+// ReadListBegin reads the header of a list.
+func (p BinaryProtocol) ReadListBegin(b []byte) (elemType byte, size, n int, err error) {
+	if len(b) < 5 {
+		return 0, 0, 0, ErrTruncated
+	}
+	size = int(int32(binary.BigEndian.Uint32(b[1:])))
+	if err = checkSize(size, len(b)-5); err != nil {
+		return 0, 0, 0, err
+	}
+	return b[0], size, 5, nil
+}
+
+// ReadSetBegin reads the header of a set.
+func (p BinaryProtocol) ReadSetBegin(b []byte) (elemType byte, size, n int, err error) {
+	return p.ReadListBegin(b)
+}
+
+// ReadMapBegin reads the header of a map.
+func (p BinaryProtocol) ReadMapBegin(b []byte) (keyType, valueType byte, size, n int, err error) {
+	if len(b) < 6 {
+		return 0, 0, 0, 0, ErrTruncated
+	}
+	size = int(int32(binary.BigEndian.Uint32(b[2:])))
+	if err = checkSize(size, len(b)-6); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return b[0], b[1], size, 6, nil
+}
+
+// Skip returns the size of a value of the type at the start of b, to discard
+// it. It fails if the value is nested deeper than DefaultDepth.
+func (p BinaryProtocol) Skip(b []byte, typeID byte) (int, error) {
+	return skip(p, b, typeID, DefaultDepth)
+}
+
+func (BinaryProtocol) fixedSize(typeID byte) int {
+	switch typeID {
+	case BOOL, BYTE:
+		return 1
+	case I16:
+		return 2
+	case I32:
+		return 4
+	case I64, DOUBLE:
+		return 8
+	}
+	return 0
+}
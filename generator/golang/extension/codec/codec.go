@@ -0,0 +1,169 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codec encodes and decodes the values of the binary and compact
+// protocols of apache thrift in byte slices. It is used by the Append and Read
+// methods that the protocols option of the go backend generates for structs,
+// unions and exceptions.
+//
+// Binary and Compact have the same methods. The Append methods append a value
+// to a slice and return the extended slice. The Read methods decode a value at
+// the start of a slice and return it with the number of bytes it takes.
+package codec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Type IDs, the same as the ones of the apache thrift library.
+const (
+	STOP   byte = 0
+	BOOL   byte = 2
+	BYTE   byte = 3
+	DOUBLE byte = 4
+	I16    byte = 6
+	I32    byte = 8
+	I64    byte = 10
+	STRING byte = 11
+	STRUCT byte = 12
+	MAP    byte = 13
+	SET    byte = 14
+	LIST   byte = 15
+)
+
+// DefaultDepth is the max nesting depth of the values that Skip discards, the
+// same as the one of the apache thrift library.
+const DefaultDepth = 64
+
+// errors .
+var (
+	ErrTruncated        = errors.New("codec: unexpected end of data")
+	ErrNegativeSize     = errors.New("codec: negative size")
+	ErrVarintOverflow   = errors.New("codec: varint overflows")
+	ErrExceedDepthLimit = errors.New("codec: depth limit exceeded")
+)
+
+// InvalidTypeError is returned when a value has a type ID that the protocol
+// does not define.
+type InvalidTypeError byte
+
+func (e InvalidTypeError) Error() string {
+	return fmt.Sprintf("codec: invalid type %d", byte(e))
+}
+
+// Field is the header of a field read by ReadFieldBegin.
+type Field struct {
+	Type byte
+	ID   int16
+
+	value bool // the value of a bool field of the compact protocol
+}
+
+// checkSize returns an error if a container of size elements can not fit in the
+// rest bytes, each element of which takes at least a byte.
+func checkSize(size, rest int) error {
+	if size < 0 {
+		return ErrNegativeSize
+	}
+	if size > rest {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// skipper is implemented by Binary and Compact to share the code of Skip.
+type skipper interface {
+	ReadFieldBegin(b []byte, last int16) (Field, int, error)
+	ReadBoolField(b []byte, f Field) (bool, int, error)
+	ReadString(b []byte) (string, int, error)
+	ReadListBegin(b []byte) (elemType byte, size, n int, err error)
+	ReadMapBegin(b []byte) (keyType, valueType byte, size, n int, err error)
+	fixedSize(typeID byte) int
+}
+
+// skip returns the size of a value of the type at the start of b.
+func skip(p skipper, b []byte, typeID byte, depth int) (int, error) {
+	if depth <= 0 {
+		return 0, ErrExceedDepthLimit
+	}
+	if size := p.fixedSize(typeID); size > 0 {
+		if len(b) < size {
+			return 0, ErrTruncated
+		}
+		return size, nil
+	}
+	switch typeID {
+	case I16, I32, I64: // varints of the compact protocol
+		_, n, err := readUvarint(b, 64)
+		return n, err
+	case STRING:
+		_, n, err := p.ReadString(b)
+		return n, err
+	case STRUCT:
+		var f Field
+		n := 0
+		for {
+			var l int
+			var err error
+			if f, l, err = p.ReadFieldBegin(b[n:], f.ID); err != nil {
+				return 0, err
+			}
+			n += l
+			if f.Type == STOP {
+				return n, nil
+			}
+			if f.Type == BOOL {
+				_, l, err = p.ReadBoolField(b[n:], f)
+			} else {
+				l, err = skip(p, b[n:], f.Type, depth-1)
+			}
+			if err != nil {
+				return 0, err
+			}
+			n += l
+		}
+	case LIST, SET:
+		elemType, size, n, err := p.ReadListBegin(b)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < size; i++ {
+			l, err := skip(p, b[n:], elemType, depth-1)
+			if err != nil {
+				return 0, err
+			}
+			n += l
+		}
+		return n, nil
+	case MAP:
+		keyType, valueType, size, n, err := p.ReadMapBegin(b)
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < size; i++ {
+			l, err := skip(p, b[n:], keyType, depth-1)
+			if err != nil {
+				return 0, err
+			}
+			n += l
+			if l, err = skip(p, b[n:], valueType, depth-1); err != nil {
+				return 0, err
+			}
+			n += l
+		}
+		return n, nil
+	}
+	return 0, InvalidTypeError(typeID)
+}
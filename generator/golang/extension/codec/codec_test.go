@@ -0,0 +1,246 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+)
+
+// protocol is implemented by Binary and Compact.
+type protocol interface {
+	skipper
+	AppendFieldBegin(b []byte, typeID byte, id, last int16) []byte
+	AppendBoolField(b []byte, id, last int16, v bool) []byte
+	AppendFieldStop(b []byte) []byte
+	AppendBool(b []byte, v bool) []byte
+	AppendI8(b []byte, v int8) []byte
+	AppendI16(b []byte, v int16) []byte
+	AppendI32(b []byte, v int32) []byte
+	AppendI64(b []byte, v int64) []byte
+	AppendDouble(b []byte, v float64) []byte
+	AppendString(b []byte, v string) []byte
+	AppendBinary(b []byte, v []byte) []byte
+	AppendListBegin(b []byte, elemType byte, size int) []byte
+	AppendSetBegin(b []byte, elemType byte, size int) []byte
+	AppendMapBegin(b []byte, keyType, valueType byte, size int) []byte
+	ReadBool(b []byte) (bool, int, error)
+	ReadI8(b []byte) (int8, int, error)
+	ReadI16(b []byte) (int16, int, error)
+	ReadI32(b []byte) (int32, int, error)
+	ReadI64(b []byte) (int64, int, error)
+	ReadDouble(b []byte) (float64, int, error)
+	ReadBinary(b []byte) ([]byte, int, error)
+	ReadSetBegin(b []byte) (elemType byte, size, n int, err error)
+	Skip(b []byte, typeID byte) (int, error)
+}
+
+var protocols = map[string]protocol{"binary": Binary, "compact": Compact}
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Replace(s, " ", "", -1))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// The expected bytes are taken from the specifications of the protocols:
+// https://github.com/apache/thrift/blob/master/doc/specs/thrift-binary-protocol.md
+// https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+func TestEncoding(t *testing.T) {
+	cases := []struct {
+		name    string
+		append  func(p protocol, b []byte) []byte
+		binary  string
+		compact string
+	}{
+		{"bool", func(p protocol, b []byte) []byte { return p.AppendBool(b, true) }, "01", "01"},
+		{"false", func(p protocol, b []byte) []byte { return p.AppendBool(b, false) }, "00", "02"},
+		{"byte", func(p protocol, b []byte) []byte { return p.AppendI8(b, -2) }, "fe", "fe"},
+		{"i16", func(p protocol, b []byte) []byte { return p.AppendI16(b, -1) }, "ffff", "01"},
+		{"i32", func(p protocol, b []byte) []byte { return p.AppendI32(b, 300) }, "0000012c", "d804"},
+		{"min i32", func(p protocol, b []byte) []byte { return p.AppendI32(b, math.MinInt32) }, "80000000", "ffffffff0f"},
+		{"i64", func(p protocol, b []byte) []byte { return p.AppendI64(b, -64) }, "ffffffffffffffc0", "7f"},
+		{"max i64", func(p protocol, b []byte) []byte { return p.AppendI64(b, math.MaxInt64) }, "7fffffffffffffff", "feffffffffffffffff01"},
+		{"double", func(p protocol, b []byte) []byte { return p.AppendDouble(b, 1) }, "3ff0000000000000", "000000000000f03f"},
+		{"string", func(p protocol, b []byte) []byte { return p.AppendString(b, "hi") }, "00000002 6869", "02 6869"},
+		{"binary", func(p protocol, b []byte) []byte { return p.AppendBinary(b, nil) }, "00000000", "00"},
+		{"list", func(p protocol, b []byte) []byte {
+			b = p.AppendListBegin(b, I32, 3)
+			for i := int32(1); i <= 3; i++ {
+				b = p.AppendI32(b, i)
+			}
+			return b
+		}, "08 00000003 00000001 00000002 00000003", "35 02 04 06"},
+		{"long list", func(p protocol, b []byte) []byte {
+			return p.AppendListBegin(b, BYTE, 15)
+		}, "03 0000000f", "f3 0f"},
+		{"set of bool", func(p protocol, b []byte) []byte {
+			return p.AppendBool(p.AppendSetBegin(b, BOOL, 1), true)
+		}, "02 00000001 01", "11 01"},
+		{"map", func(p protocol, b []byte) []byte {
+			b = p.AppendMapBegin(b, STRING, I32, 1)
+			return p.AppendI32(p.AppendString(b, "a"), 1)
+		}, "0b 08 00000001 00000001 61 00000001", "01 85 01 61 02"},
+		{"empty map", func(p protocol, b []byte) []byte {
+			return p.AppendMapBegin(b, STRING, I32, 0)
+		}, "0b 08 00000000", "00"},
+		{"fields", func(p protocol, b []byte) []byte {
+			b = p.AppendI32(p.AppendFieldBegin(b, I32, 1, 0), 1)
+			b = p.AppendBoolField(b, 2, 1, true)
+			b = p.AppendBoolField(b, 3, 2, false)
+			b = p.AppendI64(p.AppendFieldBegin(b, I64, 20, 3), 0)
+			b = p.AppendFieldStop(p.AppendFieldBegin(b, STRUCT, 5, 20))
+			return p.AppendFieldStop(b)
+		}, "08 0001 00000001 02 0002 01 02 0003 00 0a 0014 0000000000000000 0c 0005 00 00",
+			"15 02 11 12 06 28 00 0c 0a 00 00"},
+	}
+	for _, c := range cases {
+		for name, want := range map[string]string{"binary": c.binary, "compact": c.compact} {
+			p := protocols[name]
+			got := c.append(p, []byte{0xaa})
+			if !bytes.Equal(got, append([]byte{0xaa}, unhex(want)...)) {
+				t.Errorf("%s %s: got %x, want %s", name, c.name, got[1:], want)
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, p := range protocols {
+		var b []byte
+		b = p.AppendI16(p.AppendFieldBegin(b, I16, 1, 0), math.MinInt16)
+		b = p.AppendBoolField(b, 2, 1, true)
+		b = p.AppendDouble(p.AppendFieldBegin(b, DOUBLE, 3, 2), -0.5)
+		b = p.AppendFieldBegin(b, SET, 300, 3)
+		b = p.AppendSetBegin(b, STRING, 20)
+		for i := 0; i < 20; i++ {
+			b = p.AppendBinary(b, []byte{byte(i)})
+		}
+		b = p.AppendFieldBegin(b, MAP, -1, 300)
+		b = p.AppendMapBegin(b, I64, BOOL, 1)
+		b = p.AppendBool(p.AppendI64(b, math.MinInt64), false)
+		b = p.AppendI8(p.AppendFieldBegin(b, BYTE, 4, -1), 7)
+		b = p.AppendFieldStop(b)
+
+		if n, err := p.Skip(b, STRUCT); err != nil || n != len(b) {
+			t.Fatalf("%s: skip = %d, %v, want %d", name, n, err, len(b))
+		}
+
+		var last int16
+		var n int
+		next := func(typeID byte, id int16) Field {
+			f, l, err := p.ReadFieldBegin(b[n:], last)
+			if err != nil || f.Type != typeID || f.ID != id {
+				t.Fatalf("%s: field = %+v, %v, want %d of %d", name, f, err, id, typeID)
+			}
+			n, last = n+l, f.ID
+			return f
+		}
+		check := func(v interface{}, l int, err error, want interface{}) {
+			if err != nil || v != want {
+				t.Fatalf("%s: got %v, %v, want %v", name, v, err, want)
+			}
+			n += l
+		}
+
+		next(I16, 1)
+		v16, l, err := p.ReadI16(b[n:])
+		check(v16, l, err, int16(math.MinInt16))
+		f := next(BOOL, 2)
+		vb, l, err := p.ReadBoolField(b[n:], f)
+		check(vb, l, err, true)
+		next(DOUBLE, 3)
+		vd, l, err := p.ReadDouble(b[n:])
+		check(vd, l, err, -0.5)
+		next(SET, 300)
+		et, size, l, err := p.ReadSetBegin(b[n:])
+		check(size, l, err, 20)
+		if et != STRING {
+			t.Fatalf("%s: elem type = %d", name, et)
+		}
+		for i := 0; i < size; i++ {
+			v, l, err := p.ReadBinary(b[n:])
+			check(len(v), l, err, 1)
+			if v[0] != byte(i) {
+				t.Fatalf("%s: elem %d = %v", name, i, v)
+			}
+		}
+		next(MAP, -1)
+		kt, vt, size, l, err := p.ReadMapBegin(b[n:])
+		check(size, l, err, 1)
+		if kt != I64 || vt != BOOL {
+			t.Fatalf("%s: map types = %d, %d", name, kt, vt)
+		}
+		v64, l, err := p.ReadI64(b[n:])
+		check(v64, l, err, int64(math.MinInt64))
+		vb, l, err = p.ReadBool(b[n:])
+		check(vb, l, err, false)
+		next(BYTE, 4)
+		v8, l, err := p.ReadI8(b[n:])
+		check(v8, l, err, int8(7))
+		next(STOP, 0)
+		if n != len(b) {
+			t.Fatalf("%s: read %d of %d bytes", name, n, len(b))
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	nested := func(p protocol, depth int) []byte {
+		var b []byte
+		for i := 0; i < depth; i++ {
+			b = p.AppendListBegin(b, LIST, 1)
+		}
+		return p.AppendListBegin(b, I32, 0)
+	}
+	for name, p := range protocols {
+		b := p.AppendString(nil, "hello")
+		for i := 0; i < len(b); i++ {
+			if _, _, err := p.ReadString(b[:i]); err != ErrTruncated {
+				t.Errorf("%s: read %d bytes of a string: %v", name, i, err)
+			}
+		}
+		if _, err := p.Skip(nested(p, DefaultDepth-1), LIST); err != nil {
+			t.Errorf("%s: skip: %v", name, err)
+		}
+		if _, err := p.Skip(nested(p, DefaultDepth), LIST); err != ErrExceedDepthLimit {
+			t.Errorf("%s: skip: %v", name, err)
+		}
+		if _, err := p.Skip([]byte{0x01}, 0x10); err != InvalidTypeError(0x10) {
+			t.Errorf("%s: skip: %v", name, err)
+		}
+	}
+
+	if _, _, err := Binary.ReadString(unhex("ffffffff")); err != ErrNegativeSize {
+		t.Errorf("binary: read a negative size: %v", err)
+	}
+	if _, _, err := Compact.ReadString(unhex("ffffffff0f")); err != ErrNegativeSize {
+		t.Errorf("compact: read a negative size: %v", err)
+	}
+	if _, _, err := Compact.ReadI32(unhex("ffffffffff01")); err != ErrVarintOverflow {
+		t.Errorf("compact: read an overflowing varint: %v", err)
+	}
+	if _, _, err := Compact.ReadI64(unhex("ffffffffffffffffff")); err != ErrTruncated {
+		t.Errorf("compact: read a truncated varint: %v", err)
+	}
+	if _, _, err := Compact.ReadFieldBegin(unhex("1d"), 0); err != InvalidTypeError(0x0d) {
+		t.Errorf("compact: read an invalid field type: %v", err)
+	}
+}
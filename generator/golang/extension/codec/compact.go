@@ -0,0 +1,375 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// The type IDs of the compact protocol.
+const (
+	compactTrue   = 1
+	compactFalse  = 2
+	compactByte   = 3
+	compactI16    = 4
+	compactI32    = 5
+	compactI64    = 6
+	compactDouble = 7
+	compactBinary = 8
+	compactList   = 9
+	compactSet    = 10
+	compactMap    = 11
+	compactStruct = 12
+)
+
+var toCompact = [...]byte{
+	BOOL:   compactTrue,
+	BYTE:   compactByte,
+	DOUBLE: compactDouble,
+	I16:    compactI16,
+	I32:    compactI32,
+	I64:    compactI64,
+	STRING: compactBinary,
+	STRUCT: compactStruct,
+	MAP:    compactMap,
+	SET:    compactSet,
+	LIST:   compactList,
+}
+
+var fromCompact = [...]byte{
+	compactTrue:   BOOL,
+	compactFalse:  BOOL,
+	compactByte:   BYTE,
+	compactI16:    I16,
+	compactI32:    I32,
+	compactI64:    I64,
+	compactDouble: DOUBLE,
+	compactBinary: STRING,
+	compactList:   LIST,
+	compactSet:    SET,
+	compactMap:    MAP,
+	compactStruct: STRUCT,
+}
+
+func compactType(typeID byte) byte {
+	if int(typeID) < len(toCompact) {
+		return toCompact[typeID]
+	}
+	return 0
+}
+
+func thriftType(c byte) (byte, error) {
+	if int(c) < len(fromCompact) && fromCompact[c] != 0 {
+		return fromCompact[c], nil
+	}
+	return 0, InvalidTypeError(c)
+}
+
+// CompactProtocol is the type of Compact.
+type CompactProtocol struct{}
+
+// Compact encodes and decodes values with the compact protocol, which writes the
+// integers as zigzag varints, the ID of a field as the delta from the last one
+// when it is small and the value of a bool field in its header.
+var Compact CompactProtocol
+
+func appendUvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// readUvarint reads a varint of at most bits bits.
+func readUvarint(b []byte, bits uint) (uint64, int, error) {
+	var v uint64
+	max := int(bits+6) / 7
+	for i := 0; i < len(b); i++ {
+		if i == max {
+			return 0, 0, ErrVarintOverflow
+		}
+		v |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, ErrTruncated
+}
+
+func zigzag32(v int32) uint64 { return uint64(uint32(v<<1) ^ uint32(v>>31)) }
+
+func zigzag64(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func unzigzag32(u uint64) int32 { return int32(uint32(u)>>1) ^ -int32(u&1) }
+
+func unzigzag64(u uint64) int64 { return int64(u>>1) ^ -int64(u&1) }
+
+func appendFieldHeader(b []byte, c byte, id, last int16) []byte {
+	if id > last && id-last <= 15 {
+		return append(b, byte(id-last)<<4|c)
+	}
+	return appendUvarint(append(b, c), zigzag32(int32(id)))
+}
+
+// AppendFieldBegin appends the header of a field with the ID delta from the
+// last field of the struct, or 0 for the first field.
+func (CompactProtocol) AppendFieldBegin(b []byte, typeID byte, id, last int16) []byte {
+	return appendFieldHeader(b, compactType(typeID), id, last)
+}
+
+// AppendBoolField appends the header of a bool field, which holds the value.
+func (CompactProtocol) AppendBoolField(b []byte, id, last int16, v bool) []byte {
+	if v {
+		return appendFieldHeader(b, compactTrue, id, last)
+	}
+	return appendFieldHeader(b, compactFalse, id, last)
+}
+
+// AppendFieldStop appends the end of the fields of a struct.
+func (CompactProtocol) AppendFieldStop(b []byte) []byte {
+	return append(b, STOP)
+}
+
+// AppendBool appends a bool that is not the value of a field.
+func (CompactProtocol) AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, compactTrue)
+	}
+	return append(b, compactFalse)
+}
+
+// AppendI8 appends an i8.
+func (CompactProtocol) AppendI8(b []byte, v int8) []byte {
+	return append(b, byte(v))
+}
+
+// AppendI16 appends an i16.
+func (CompactProtocol) AppendI16(b []byte, v int16) []byte {
+	return appendUvarint(b, zigzag32(int32(v)))
+}
+
+// AppendI32 appends an i32.
+func (CompactProtocol) AppendI32(b []byte, v int32) []byte {
+	return appendUvarint(b, zigzag32(v))
+}
+
+// AppendI64 appends an i64.
+func (CompactProtocol) AppendI64(b []byte, v int64) []byte {
+	return appendUvarint(b, zigzag64(v))
+}
+
+// AppendDouble appends a double, which is in little endian.
+func (CompactProtocol) AppendDouble(b []byte, v float64) []byte {
+	u := math.Float64bits(v)
+	return append(b, byte(u), byte(u>>8), byte(u>>16), byte(u>>24),
+		byte(u>>32), byte(u>>40), byte(u>>48), byte(u>>56))
+}
+
+// AppendString appends a string.
+func (CompactProtocol) AppendString(b []byte, v string) []byte {
+	return append(appendUvarint(b, uint64(uint32(len(v)))), v...)
+}
+
+// AppendBinary appends a binary.
+func (CompactProtocol) AppendBinary(b []byte, v []byte) []byte {
+	return append(appendUvarint(b, uint64(uint32(len(v)))), v...)
+}
+
+// AppendListBegin appends the header of a list.
+func (CompactProtocol) AppendListBegin(b []byte, elemType byte, size int) []byte {
+	if size <= 14 {
+		return append(b, byte(size)<<4|compactType(elemType))
+	}
+	return appendUvarint(append(b, 0xf0|compactType(elemType)), uint64(uint32(size)))
+}
+
+// AppendSetBegin appends the header of a set.
+func (p CompactProtocol) AppendSetBegin(b []byte, elemType byte, size int) []byte {
+	return p.AppendListBegin(b, elemType, size)
+}
+
+// AppendMapBegin appends the header of a map. The types of an empty map are
+// omitted.
+func (CompactProtocol) AppendMapBegin(b []byte, keyType, valueType byte, size int) []byte {
+	if size == 0 {
+		return append(b, 0)
+	}
+	b = appendUvarint(b, uint64(uint32(size)))
+	return append(b, compactType(keyType)<<4|compactType(valueType))
+}
+
+// ReadFieldBegin reads the header of a field whose ID may be a delta from the
+// last field of the struct, which is 0 for the first field.
+func (CompactProtocol) ReadFieldBegin(b []byte, last int16) (Field, int, error) {
+	if len(b) < 1 {
+		return Field{}, 0, ErrTruncated
+	}
+	if b[0] == STOP {
+		return Field{}, 1, nil
+	}
+	c, delta := b[0]&0x0f, b[0]>>4
+	typeID, err := thriftType(c)
+	if err != nil {
+		return Field{}, 0, err
+	}
+	f := Field{Type: typeID, ID: last + int16(delta), value: c == compactTrue}
+	if delta != 0 {
+		return f, 1, nil
+	}
+	u, n, err := readUvarint(b[1:], 32)
+	if err != nil {
+		return Field{}, 0, err
+	}
+	f.ID = int16(unzigzag32(u))
+	return f, 1 + n, nil
+}
+
+// ReadBoolField returns the value of a bool field kept in its header f, which
+// takes no more bytes.
+func (CompactProtocol) ReadBoolField(b []byte, f Field) (bool, int, error) {
+	return f.value, 0, nil
+}
+
+// ReadBool reads a bool that is not the value of a field.
+func (CompactProtocol) ReadBool(b []byte) (bool, int, error) {
+	if len(b) < 1 {
+		return false, 0, ErrTruncated
+	}
+	return b[0] == compactTrue, 1, nil
+}
+
+// ReadI8 reads an i8.
+func (CompactProtocol) ReadI8(b []byte) (int8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrTruncated
+	}
+	return int8(b[0]), 1, nil
+}
+
+// ReadI16 reads an i16.
+func (CompactProtocol) ReadI16(b []byte) (int16, int, error) {
+	u, n, err := readUvarint(b, 32)
+	return int16(unzigzag32(u)), n, err
+}
+
+// ReadI32 reads an i32.
+func (CompactProtocol) ReadI32(b []byte) (int32, int, error) {
+	u, n, err := readUvarint(b, 32)
+	return unzigzag32(u), n, err
+}
+
+// ReadI64 reads an i64.
+func (CompactProtocol) ReadI64(b []byte) (int64, int, error) {
+	u, n, err := readUvarint(b, 64)
+	return unzigzag64(u), n, err
+}
+
+// ReadDouble reads a double.
+func (CompactProtocol) ReadDouble(b []byte) (float64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrTruncated
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), 8, nil
+}
+
+// readSize reads the size of a string, a binary or a container.
+func readSize(b []byte) (int, int, error) {
+	u, n, err := readUvarint(b, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = checkSize(int(int32(u)), len(b)-n); err != nil {
+		return 0, 0, err
+	}
+	return int(int32(u)), n, nil
+}
+
+// ReadString reads a string.
+func (CompactProtocol) ReadString(b []byte) (string, int, error) {
+	size, n, err := readSize(b)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b[n : n+size]), n + size, nil
+}
+
+// ReadBinary reads a binary into a new slice.
+func (CompactProtocol) ReadBinary(b []byte) ([]byte, int, error) {
+	size, n, err := readSize(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append([]byte{}, b[n:n+size]...), n + size, nil
+}
+
+// ReadListBegin reads the header of a list.
+func (CompactProtocol) ReadListBegin(b []byte) (elemType byte, size, n int, err error) {
+	if len(b) < 1 {
+		return 0, 0, 0, ErrTruncated
+	}
+	if elemType, err = thriftType(b[0] & 0x0f); err != nil {
+		return 0, 0, 0, err
+	}
+	if size = int(b[0] >> 4); size != 15 {
+		if err = checkSize(size, len(b)-1); err != nil {
+			return 0, 0, 0, err
+		}
+		return elemType, size, 1, nil
+	}
+	if size, n, err = readSize(b[1:]); err != nil {
+		return 0, 0, 0, err
+	}
+	return elemType, size, 1 + n, nil
+}
+
+// ReadSetBegin reads the header of a set.
+func (p CompactProtocol) ReadSetBegin(b []byte) (elemType byte, size, n int, err error) {
+	return p.ReadListBegin(b)
+}
+
+// ReadMapBegin reads the header of a map. The types of an empty map are STOP.
+func (CompactProtocol) ReadMapBegin(b []byte) (keyType, valueType byte, size, n int, err error) {
+	if size, n, err = readSize(b); err != nil || size == 0 {
+		return 0, 0, 0, n, err
+	}
+	if len(b) <= n {
+		return 0, 0, 0, 0, ErrTruncated
+	}
+	if keyType, err = thriftType(b[n] >> 4); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if valueType, err = thriftType(b[n] & 0x0f); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return keyType, valueType, size, n + 1, nil
+}
+
+// Skip returns the size of a value of the type at the start of b, to discard
+// it. It fails if the value is nested deeper than DefaultDepth.
+func (p CompactProtocol) Skip(b []byte, typeID byte) (int, error) {
+	return skip(p, b, typeID, DefaultDepth)
+}
+
+func (CompactProtocol) fixedSize(typeID byte) int {
+	switch typeID {
+	case BOOL, BYTE:
+		return 1
+	case DOUBLE:
+		return 8
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fieldmeta describes the fields of the structs, unions and exceptions
+// generated with the gen_field_meta option, e.g. for generic exporters that
+// need the fields at runtime.
+package fieldmeta
+
+import "strconv"
+
+// Category is the category of a type in the IDL, with typedefs resolved.
+// The zero value is used as the key and element categories of a field that is
+// not a container.
+type Category int8
+
+// The categories have the same values as the ones of the parser.
+const (
+	Bool Category = iota + 1
+	Byte
+	I16
+	I32
+	I64
+	Double
+	String
+	Binary
+	Map
+	List
+	Set
+	Enum
+	Struct
+	Union
+	Exception
+)
+
+var categoryNames = [...]string{
+	Bool:      "Bool",
+	Byte:      "Byte",
+	I16:       "I16",
+	I32:       "I32",
+	I64:       "I64",
+	Double:    "Double",
+	String:    "String",
+	Binary:    "Binary",
+	Map:       "Map",
+	List:      "List",
+	Set:       "Set",
+	Enum:      "Enum",
+	Struct:    "Struct",
+	Union:     "Union",
+	Exception: "Exception",
+}
+
+func (c Category) String() string {
+	if c > 0 && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return "Category(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Requiredness is the requiredness of a field.
+type Requiredness int8
+
+// The requirednesses have the same values as the ones of the parser.
+const (
+	Default Requiredness = iota
+	Required
+	Optional
+)
+
+func (r Requiredness) String() string {
+	switch r {
+	case Default:
+		return "Default"
+	case Required:
+		return "Required"
+	case Optional:
+		return "Optional"
+	}
+	return "Requiredness(" + strconv.Itoa(int(r)) + ")"
+}
+
+// FieldMeta describes a field of a struct, union or exception.
+type FieldMeta struct {
+	ID           int16
+	Name         string // the name in the IDL
+	GoName       string // the name of the field in the go struct
+	Category     Category
+	KeyCategory  Category // the key of a map
+	ElemCategory Category // the element of a list or set, or the value of a map
+	Requiredness Requiredness
+}
@@ -15,9 +15,11 @@
 package meta
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 var (
@@ -358,12 +360,15 @@ func write(ctx context.Context, oprot Protocol, tt *TypeMeta, gv reflect.Value)
 		if err := oprot.WriteMapBegin(ctx, tt.KeyType.TypeID, tt.ValueType.TypeID, gv.Len()); err != nil {
 			return err
 		}
-		iter := gv.MapRange()
-		for iter.Next() {
-			if err := write(ctx, oprot, tt.KeyType, iter.Key()); err != nil {
+		keys, err := sortedMapKeys(ctx, tt.KeyType, gv)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := write(ctx, oprot, tt.KeyType, k); err != nil {
 				return err
 			}
-			if err := write(ctx, oprot, tt.ValueType, iter.Value()); err != nil {
+			if err := write(ctx, oprot, tt.ValueType, gv.MapIndex(k)); err != nil {
 				return err
 			}
 		}
@@ -401,3 +406,46 @@ func write(ctx context.Context, oprot Protocol, tt *TypeMeta, gv reflect.Value)
 	}
 	return nil
 }
+
+// sortedMapKeys returns the keys of a map in a stable order so that the
+// serialized bytes do not change across runs. Booleans, numbers and strings are
+// sorted by their values. Other keys, such as pointers to structs, are sorted by
+// their serialized bytes.
+func sortedMapKeys(ctx context.Context, kt *TypeMeta, m reflect.Value) ([]reflect.Value, error) {
+	keys := m.MapKeys()
+	switch m.Type().Key().Kind() {
+	case reflect.Bool:
+		sort.Slice(keys, func(i, j int) bool { return !keys[i].Bool() && keys[j].Bool() })
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Int() < keys[j].Int() })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Uint() < keys[j].Uint() })
+	case reflect.Float32, reflect.Float64:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Float() < keys[j].Float() })
+	case reflect.String:
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	default:
+		encoded := make([][]byte, len(keys))
+		for i, k := range keys {
+			mem := new(MemoryTransport)
+			if err := write(ctx, NewBinaryProtocol(mem), kt, k); err != nil {
+				return nil, err
+			}
+			encoded[i] = mem.Bytes()
+		}
+		sort.Sort(byEncoding{keys, encoded})
+	}
+	return keys, nil
+}
+
+type byEncoding struct {
+	keys    []reflect.Value
+	encoded [][]byte
+}
+
+func (b byEncoding) Len() int           { return len(b.keys) }
+func (b byEncoding) Less(i, j int) bool { return bytes.Compare(b.encoded[i], b.encoded[j]) < 0 }
+func (b byEncoding) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.encoded[i], b.encoded[j] = b.encoded[j], b.encoded[i]
+}
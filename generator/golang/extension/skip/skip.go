@@ -0,0 +1,184 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package skip discards the values that the code generated with the fast_skip
+// option does not read, e.g. the fields unknown to an older IDL, without
+// decoding them.
+package skip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Type IDs.
+const (
+	TStop   = 0
+	TBool   = 2
+	TByte   = 3
+	TDouble = 4
+	TI16    = 6
+	TI32    = 8
+	TI64    = 10
+	TString = 11
+	TStruct = 12
+	TMap    = 13
+	TSet    = 14
+	TList   = 15
+)
+
+// DefaultDepth is the max nesting depth of the values that the generated code
+// skips, the same as the one of the apache thrift library.
+const DefaultDepth = 64
+
+// errors .
+var (
+	ErrExceedDepthLimit = errors.New("skip: depth limit exceeded")
+
+	ErrNegativeSize = errors.New("skip: negative size")
+)
+
+// Binary discards a value of the type encoded with the binary protocol from r.
+// Strings, binaries and the containers of fixed-width elements are discarded as
+// a whole without reading their elements one by one, so the cost of skipping a
+// large value does not depend on how many elements it has.
+func Binary(r io.Reader, typeID, maxDepth int) error {
+	s := skipper{r: r}
+	return s.skip(typeID, maxDepth)
+}
+
+type skipper struct {
+	r   io.Reader
+	buf [6]byte
+}
+
+// fixedSize returns the size of a value of the type on the wire, or 0 if it is
+// not fixed.
+func fixedSize(typeID int) int64 {
+	switch typeID {
+	case TBool, TByte:
+		return 1
+	case TI16:
+		return 2
+	case TI32:
+		return 4
+	case TDouble, TI64:
+		return 8
+	}
+	return 0
+}
+
+func (s *skipper) read(n int) error {
+	_, err := io.ReadFull(s.r, s.buf[:n])
+	return err
+}
+
+func (s *skipper) discard(n int64) error {
+	if n == 0 {
+		return nil
+	}
+	_, err := io.CopyN(ioutil.Discard, s.r, n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// size reads the size of a string or a container that starts at s.buf[off].
+func (s *skipper) size(off int) (int64, error) {
+	n := int32(binary.BigEndian.Uint32(s.buf[off:]))
+	if n < 0 {
+		return 0, ErrNegativeSize
+	}
+	return int64(n), nil
+}
+
+func (s *skipper) skip(typeID, depth int) error {
+	if depth <= 0 {
+		return ErrExceedDepthLimit
+	}
+	if n := fixedSize(typeID); n > 0 {
+		return s.discard(n)
+	}
+	switch typeID {
+	case TString:
+		if err := s.read(4); err != nil {
+			return err
+		}
+		n, err := s.size(0)
+		if err != nil {
+			return err
+		}
+		return s.discard(n)
+	case TStruct:
+		for {
+			if err := s.read(1); err != nil {
+				return err
+			}
+			fieldType := int(s.buf[0])
+			if fieldType == TStop {
+				return nil
+			}
+			if err := s.discard(2); err != nil { // the field ID
+				return err
+			}
+			if err := s.skip(fieldType, depth-1); err != nil {
+				return err
+			}
+		}
+	case TMap:
+		if err := s.read(6); err != nil {
+			return err
+		}
+		keyType, valueType := int(s.buf[0]), int(s.buf[1])
+		n, err := s.size(2)
+		if err != nil {
+			return err
+		}
+		if ks, vs := fixedSize(keyType), fixedSize(valueType); ks > 0 && vs > 0 {
+			return s.discard(n * (ks + vs))
+		}
+		for i := int64(0); i < n; i++ {
+			if err := s.skip(keyType, depth-1); err != nil {
+				return err
+			}
+			if err := s.skip(valueType, depth-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case TSet, TList:
+		if err := s.read(5); err != nil {
+			return err
+		}
+		elemType := int(s.buf[0])
+		n, err := s.size(1)
+		if err != nil {
+			return err
+		}
+		if es := fixedSize(elemType); es > 0 {
+			return s.discard(n * es)
+		}
+		for i := int64(0); i < n; i++ {
+			if err := s.skip(elemType, depth-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("skip: unknown data type %d", typeID)
+}
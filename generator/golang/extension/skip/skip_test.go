@@ -0,0 +1,161 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// encoder writes values with the binary protocol.
+type encoder struct{ bytes.Buffer }
+
+func (e *encoder) i8(v int8)   { e.WriteByte(byte(v)) }
+func (e *encoder) i16(v int16) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) i32(v int32) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) i64(v int64) { binary.Write(e, binary.BigEndian, v) }
+func (e *encoder) str(v string) {
+	e.i32(int32(len(v)))
+	e.WriteString(v)
+}
+
+func (e *encoder) field(typeID int, id int16) {
+	e.i8(int8(typeID))
+	e.i16(id)
+}
+
+// user writes the struct of the second version of an IDL, which has the fields
+// 3 to 6 that the first version does not know:
+//
+//	struct User {
+//		1: i64 id
+//		2: string name
+//		3: list<i32> scores
+//		4: map<string, list<string>> tags
+//		5: Address address
+//		6: map<i32, double> weights
+//	}
+//	struct Address { 1: set<string> lines, 2: bool primary }
+func (e *encoder) user() {
+	e.field(TI64, 1)
+	e.i64(42)
+	e.field(TString, 2)
+	e.str("alice")
+	e.field(TList, 3)
+	e.i8(TI32)
+	e.i32(1000)
+	for i := 0; i < 1000; i++ {
+		e.i32(int32(i))
+	}
+	e.field(TMap, 4)
+	e.i8(TString)
+	e.i8(TList)
+	e.i32(2)
+	for _, k := range []string{"a", "b"} {
+		e.str(k)
+		e.i8(TString)
+		e.i32(2)
+		e.str(k + "1")
+		e.str(k + "2")
+	}
+	e.field(TStruct, 5)
+	e.field(TSet, 1)
+	e.i8(TString)
+	e.i32(1)
+	e.str("somewhere")
+	e.field(TBool, 2)
+	e.i8(1)
+	e.i8(TStop)
+	e.field(TMap, 6)
+	e.i8(TI32)
+	e.i8(TDouble)
+	e.i32(3)
+	for i := 0; i < 3; i++ {
+		e.i32(int32(i))
+		e.i64(0)
+	}
+	e.i8(TStop)
+}
+
+func TestBinary(t *testing.T) {
+	var e encoder
+	e.user()
+	size := e.Len()
+	e.WriteString("next")
+
+	r := bytes.NewReader(e.Bytes())
+	if err := Binary(r, TStruct, DefaultDepth); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Size() - int64(r.Len()); n != int64(size) {
+		t.Fatalf("expect %d bytes skipped, got %d", size, n)
+	}
+
+	// a reader of the first version skips the new fields one by one
+	e.Reset()
+	e.user()
+	r = bytes.NewReader(e.Bytes())
+	var known []int16
+	for {
+		var h [3]byte
+		if _, err := io.ReadFull(r, h[:1]); err != nil {
+			t.Fatal(err)
+		}
+		if h[0] == TStop {
+			break
+		}
+		if _, err := io.ReadFull(r, h[1:]); err != nil {
+			t.Fatal(err)
+		}
+		id := int16(binary.BigEndian.Uint16(h[1:]))
+		if id <= 2 {
+			known = append(known, id)
+		}
+		if err := Binary(r, int(h[0]), DefaultDepth); err != nil {
+			t.Fatalf("skip field %d: %v", id, err)
+		}
+	}
+	if len(known) != 2 || r.Len() != 0 {
+		t.Fatalf("unexpected fields %v with %d bytes left", known, r.Len())
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	var e encoder
+	e.user()
+	full := e.Bytes()
+	for i := range full {
+		if err := Binary(bytes.NewReader(full[:i]), TStruct, DefaultDepth); err == nil {
+			t.Fatalf("expect an error for %d of %d bytes", i, len(full))
+		}
+	}
+
+	if err := Binary(bytes.NewReader(full), TStruct, 2); err != ErrExceedDepthLimit {
+		t.Fatalf("expect %v, got %v", ErrExceedDepthLimit, err)
+	}
+
+	e.Reset()
+	e.i32(-1)
+	if err := Binary(bytes.NewReader(e.Bytes()), TString, DefaultDepth); err != ErrNegativeSize {
+		t.Fatalf("expect %v, got %v", ErrNegativeSize, err)
+	}
+
+	err := Binary(bytes.NewReader(nil), 1, DefaultDepth)
+	if err == nil || err.Error() != "skip: unknown data type 1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// fieldMetaCategories returns the categories of the type t, its key and its
+// element for gen_field_meta, with typedefs resolved. The key and the element
+// are zero if t is not a container.
+func fieldMetaCategories(ast *parser.Thrift, t *parser.Type) (cats [3]parser.Category, err error) {
+	ast, t, err = semantic.Deref(ast, t)
+	if err != nil {
+		return cats, err
+	}
+	cats[0] = t.Category
+	for i, x := range []*parser.Type{t.KeyType, t.ValueType} {
+		if x == nil {
+			continue
+		}
+		if _, x, err = semantic.Deref(ast, x); err != nil {
+			return cats, err
+		}
+		cats[i+1] = x.Category
+	}
+	return cats, nil
+}
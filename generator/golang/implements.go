@@ -0,0 +1,168 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// implementsAnnotation declares a go interface that the getters of a struct-like
+// satisfy, in the form of "Name" or "Name(field1, field2, ...)".
+const implementsAnnotation = "go.implements"
+
+// Interface is a go interface declared by the go.implements annotations of the
+// struct-likes in a scope.
+type Interface struct {
+	name            Name
+	fields          []string // the fields listed by the annotations, nil if none
+	methods         []*InterfaceMethod
+	implementations []*StructLike
+}
+
+// GoName returns the name of the interface.
+func (i *Interface) GoName() Name {
+	return i.name
+}
+
+// Methods returns the getters of the interface.
+func (i *Interface) Methods() []*InterfaceMethod {
+	return i.methods
+}
+
+// Implementations returns the struct-likes declaring the interface in the order
+// of their declarations.
+func (i *Interface) Implementations() []*StructLike {
+	return i.implementations
+}
+
+// InterfaceMethod is a getter of an interface.
+type InterfaceMethod struct {
+	GoName   Name
+	TypeName TypeName
+}
+
+// parseImplements parses a value of the go.implements annotation.
+func parseImplements(value string) (name string, fields []string, err error) {
+	name = strings.TrimSpace(value)
+	if idx := strings.Index(name, "("); idx >= 0 {
+		if !strings.HasSuffix(name, ")") {
+			return "", nil, fmt.Errorf("missing ')' in %s %q", implementsAnnotation, value)
+		}
+		for _, f := range strings.Split(name[idx+1:len(name)-1], ",") {
+			if f = strings.TrimSpace(f); f == "" {
+				return "", nil, fmt.Errorf("empty field name in %s %q", implementsAnnotation, value)
+			}
+			fields = append(fields, f)
+		}
+		name = strings.TrimSpace(name[:idx])
+	}
+	if !token.IsIdentifier(name) {
+		return "", nil, fmt.Errorf("invalid interface name in %s %q", implementsAnnotation, value)
+	}
+	return name, fields, nil
+}
+
+// getter returns the method of the getter of the field.
+func getter(f *Field) *InterfaceMethod {
+	m := &InterfaceMethod{GoName: f.Getter(), TypeName: f.GoTypeName()}
+	if SupportIsSet(f.Field) {
+		m.TypeName = f.DefaultTypeName()
+	}
+	return m
+}
+
+// buildInterfaces collects the interfaces declared by the struct-likes of the
+// scope. The methods of an interface are the getters of the fields listed by its
+// declarations or, when none is listed, the getters shared by all of its
+// implementations. A getter that has different signatures in the
+// implementations is an error.
+func (s *Scope) buildInterfaces() error {
+	index := make(map[string]*Interface)
+	for _, st := range s.StructLikes() {
+		what := s.describeStructLike(st)
+		for _, v := range st.Annotations.Get(implementsAnnotation) {
+			name, fields, err := parseImplements(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", what, err)
+			}
+			iface := index[name]
+			if iface == nil {
+				if !s.globals.Reserve(name, _p("iface:"+name)) {
+					return fmt.Errorf("%s: the name of the interface %q in %s is already used in go", what, name, implementsAnnotation)
+				}
+				iface = &Interface{name: Name(name), fields: fields}
+				index[name] = iface
+				s.interfaces = append(s.interfaces, iface)
+			} else if strings.Join(iface.fields, ",") != strings.Join(fields, ",") {
+				return fmt.Errorf("%s: the interface %q is declared with the fields (%s) by %q but (%s) here",
+					what, name, strings.Join(iface.fields, ", "), iface.implementations[0].Name, strings.Join(fields, ", "))
+			}
+			iface.implementations = append(iface.implementations, st)
+		}
+	}
+
+	for _, iface := range s.interfaces {
+		first := iface.implementations[0]
+		names := iface.fields
+		if names == nil {
+			for _, f := range first.fields {
+				names = append(names, f.Name)
+			}
+		}
+		for _, name := range names {
+			var method *InterfaceMethod
+			var owner *StructLike
+			shared := true
+			for _, st := range iface.implementations {
+				var m *InterfaceMethod
+				for _, f := range st.fields {
+					if f.Name == name {
+						m = getter(f)
+						break
+					}
+				}
+				if m == nil {
+					if iface.fields != nil {
+						return fmt.Errorf("%s: the field %q listed by the interface %q is not found",
+							s.describeStructLike(st), name, iface.name)
+					}
+					shared = false
+					break
+				}
+				if method == nil {
+					method, owner = m, st
+				} else if *m != *method {
+					return fmt.Errorf("%s: the getter of %q for the interface %q is %s() %s, but it is %s() %s in %s",
+						s.describeStructLike(st), name, iface.name, m.GoName, m.TypeName,
+						method.GoName, method.TypeName, s.describeStructLike(owner))
+				}
+			}
+			if shared {
+				iface.methods = append(iface.methods, method)
+			}
+		}
+		if len(iface.methods) == 0 {
+			return fmt.Errorf("%s: the interface %q has no getter shared by all of its implementations",
+				s.describeStructLike(first), iface.name)
+		}
+	}
+	return nil
+}
+
+func (s *Scope) describeStructLike(st *StructLike) string {
+	return s.describe(fmt.Sprintf("%s %q", st.Category, st.Name), st.Position)
+}
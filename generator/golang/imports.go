@@ -72,6 +72,7 @@ func (im *importManager) init(cu *CodeUtils, ast *parser.Thrift) {
 			return fmt.Sprintf("%s%d", name, cnt-1) // zero-index
 		}),
 		replacement: cu.importReplace,
+		prefixes:    cu.importPrefixes,
 	}
 	ns := im.Namespace
 
@@ -82,10 +83,16 @@ func (im *importManager) init(cu *CodeUtils, ast *parser.Thrift) {
 		"sql":               "database/sql",
 		"strings":           "strings",
 		"bytes":             "bytes",
+		"json":              "encoding/json",
+		"io":                "io",
+		"sync":              "sync",
 		"reflect":           "reflect",
 		"thrift":            DefaultThriftLib,
 		"unknown":           DefaultUnknownLib,
 		"meta":              DefaultMetaLib,
+		"fieldmeta":         DefaultFieldMetaLib,
+		"skip":              DefaultSkipLib,
+		"codec":             DefaultCodecLib,
 		"thrift_reflection": ThriftReflectionLib,
 		"json_utils":        ThriftJSONUtilLib,
 		"fieldmask":         ThriftFieldMaskLib,
@@ -96,17 +103,41 @@ func (im *importManager) init(cu *CodeUtils, ast *parser.Thrift) {
 		ns.Add(pkg, path)
 		im.libNotUsed[pkg] = true
 	}
+	// The includes must not be shadowed by the variables of the codec methods.
+	if len(cu.Protocols()) > 0 {
+		for _, v := range codecVariables {
+			ns.Add(v, "<var>/"+v)
+			im.libNotUsed[v] = true
+		}
+	}
 }
 
+// codecVariables are the names of the parameters and variables that the Append and
+// Read methods of the protocols option refer to the types of the includes in.
+var codecVariables = []string{"b", "c", "f", "i", "k", "kv", "l", "last", "n", "size", "tmp", "v", "values"}
+
 type idHijack struct {
 	namespace.Namespace
 	replacement map[string]string
+	prefixes    map[string]string
 }
 
+// get returns the replacement of the import path id. A replacement of the whole
+// path takes precedence over the replacements of its prefixes, among which the
+// longest one wins.
 func (h *idHijack) get(id string) string {
 	if v, ok := h.replacement[id]; ok {
 		return v
 	}
+	var longest string
+	for prefix := range h.prefixes {
+		if len(prefix) > len(longest) && (id == prefix || strings.HasPrefix(id, prefix+"/")) {
+			longest = prefix
+		}
+	}
+	if longest != "" {
+		return h.prefixes[longest] + id[len(longest):]
+	}
 	return id
 }
 
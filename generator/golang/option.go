@@ -17,9 +17,11 @@ package golang
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/cloudwego/thriftgo/generator/golang/styles"
+	"github.com/cloudwego/thriftgo/plugin"
 )
 
 // Features controls the behavior of CodeUtils.
@@ -29,8 +31,9 @@ type Features struct {
 	UnmarshalEnum               bool `enum_unmarshal:"Generate UnmarshalText for enum values"`
 	GenerateSetter              bool `gen_setter:"Generate Set* methods for fields"`
 	GenDatabaseTag              bool `gen_db_tag:"Generate 'db:$field' tag"`
-	GenOmitEmptyTag             bool `omitempty_for_optional:"Generate 'omitempty' tags for optional fields."`
+	GenOmitEmptyTag             bool `omitempty_for_optional:"Generate 'omitempty' tags for optional fields. The go.json.omitempty annotation of a field overrides it."`
 	TypedefAsTypeAlias          bool `use_type_alias:"Generate type alias for typedef instead of type define."`
+	TypedefAsType               bool `typedef_as_type:"Generate typedefs of base types, enums and containers as distinct types, e.g. 'type UserID int64', and convert their values when reading and writing. Typedefs of structs, unions and exceptions are still type aliases to keep their methods. It overrides use_type_alias."`
 	ValidateSet                 bool `validate_set:"Generate codes to validate the uniqueness of set elements."`
 	ValueTypeForSIC             bool `value_type_in_container:"Generate value type for struct-like in container instead of pointer type."`
 	ScanValueForEnum            bool `scan_value_for_enum:"Generate Scan and Value methods for enums to implement interfaces in std sql library."`
@@ -41,6 +44,8 @@ type Features struct {
 	CompatibleNames             bool `compatible_names:"Add a '_' suffix if an name has a prefix 'New' or suffix 'Args' or 'Result'."`
 	ReserveComments             bool `reserve_comments:"Reserve comments of definitions in thrift file"`
 	NilSafe                     bool `nil_safe:"Generate nil-safe getters."`
+	GenOptionalAccessors        bool `gen_optional_accessors:"Generate 'GetXXXOrDefault(def T) T' and 'SetXXX(v T)' for optional scalar fields, which are pointers in go, to read them with a fallback and set them without taking an address. It can not be used with gen_setter."`
+	GenGetters                  bool `gen_getters:"Generate nil-safe getters that return the default values of fields, including the declared ones, when the receiver is nil, so that chains like 'p.GetInner().GetX()' are safe. It implies nil_safe."`
 	FrugalTag                   bool `frugal_tag:"Generate 'frugal' tags."`
 	EscapeDoubleInTag           bool `unescape_double_quote:"Unescape the double quotes in literals when generating go tags."`
 	GenerateTypeMeta            bool `gen_type_meta:"Generate and register type meta for structures."`
@@ -66,10 +71,41 @@ type Features struct {
 	EnableRefInterface          bool `enable_ref_interface:"Generate Interface field without pointer type when 'thrift.is_interface=\"true\"' annotation is set to types in referred thrift."`
 	UseOption                   bool `use_option:"Parse specific Thrift annotations into struct-style option fields. If key not match, thriftgo will just ignore it."`
 	// ForceUseOption         bool `use_option:"Forcefully parse all Thrift annotations into struct-style option fields. If parsing is not possible, an error will be thrown."`
-	NoFmt             bool `no_fmt:"To achieve faster generation speed, skipping the formatting of Golang code can improve performance by approximately 50%."`
+	NoFmt             bool `no_fmt:"To achieve faster generation speed, skipping the formatting of Golang code can improve performance by approximately 50%. See also --no-format, which checks the format of the files without changing them."`
 	SkipEmpty         bool `skip_empty:"If there's not content in file, just skip it. Later this feature will be a default feature."`
 	NoProcessor       bool `no_processor:" Do not generate default thrift processor and client. Later this feature will be a default feature."`
 	GetEnumAnnotation bool `get_enum_annotation:"Generate GetAnnotation method for enum types."`
+
+	GenEnumValues             bool `gen_enum_values:"Generate a list of names and a Values function for enum types in declaration order."`
+	GenServiceIface           bool `gen_service_iface:"Generate a transport-free interface '<Service>Iface' for each service."`
+	GenOnewayResult           bool `gen_oneway_result:"Generate an empty result struct for each oneway method like the other methods, so that every method has both argument and result types. The struct is never sent or read."`
+	GenJSONMethods            bool `gen_json_methods:"Generate MarshalJSON and UnmarshalJSON methods for structs, unions, exceptions and enums. Optional fields are omitted when not set and enums are encoded with their names."`
+	JSONDisallowUnknownFields bool `json_disallow_unknown_fields:"Make the UnmarshalJSON methods generated by gen_json_methods reject unknown fields instead of ignoring them."`
+	GenWriteTo                bool `gen_write_to:"Generate a WriteTo(io.Writer) method for structs, unions and exceptions that serializes with the binary protocol into a pooled buffer."`
+	CtxRW                     bool `ctx_rw:"Generate Read and Write methods that take a context.Context as the first parameter and return ctx.Err() once the context is done. The generated types no longer implement thrift.TStruct."`
+	FastRead                  bool `fast_read:"Generate a reader that reads fields in ID order without dispatching for structs that only have non-optional fixed-width scalar fields in ascending ID order. Ignored with keep_unknown_fields or with_field_mask."`
+	GenSplitRead              bool `gen_split_read:"Name the per-field methods that Read dispatches to readFieldN instead of ReadFieldN, so that they are unexported like the writeFieldN methods of Write."`
+	SuffixCollidingNames      bool `suffix_colliding_names:"Append underscores to the go names of IDL definitions that collide with others in the same scope, e.g. 'my_name' and 'myName', instead of reporting an error."`
+	GenClientSingleton        bool `gen_client_singleton:"Generate a Get<Service>Client accessor for each service that lazily creates a client shared by all goroutines, with the transport factory set by Set<Service>ClientTransportFactory."`
+	GenFutureClient           bool `gen_future_client:"Generate an <Method>Async variant for each client method that runs the call in a goroutine and returns a future to await the result with Get(ctx) once."`
+	GenTypeRegistry           bool `gen_type_registry:"Register the enums, structs, unions and exceptions of each package in a registry keyed by their fully-qualified thrift names, e.g. 'namespace.TypeName', and generate a LookupType function in the package to create a value of a type by its name."`
+	GenChecksum               bool `gen_checksum:"Add a checksum of the content to the header of each generated file, which 'thriftgo --verify' checks to report the files edited by hand."`
+	GenIDLChecksum            bool `gen_idl_checksum:"Add a checksum of the source of the IDL and the ones it includes to the header of each generated file, which 'thriftgo --verify' checks to report the files that are stale since their IDL has changed."`
+	GenFieldMeta              bool `gen_field_meta:"Generate a '<Struct>Fields' variable of []fieldmeta.FieldMeta for each struct, union and exception that describes the ID, IDL name, go name, category and requiredness of each field, with the categories of the keys and elements of containers."`
+	CheckUnionOnWrite         bool `check_union_on_write:"Check the number of fields set in unions on both sides: Write returns an error naming the union unless exactly one field is set, as it always does, and Read returns an INVALID_DATA protocol error naming the union if more than one field is set on the wire."`
+	CheckUnionOnRead          bool `check_union_on_read:"The same as check_union_on_write."`
+	CheckUnionAllowZero       bool `check_union_allow_zero:"Make Write accept the unions with no field set, which are written as empty structs, and only reject the ones with more than one. It implies check_union_on_write."`
+	FastSkip                  bool `fast_skip:"Make Read skip the unknown fields with the binary protocol of apache thrift by discarding their bytes from the transport, without decoding the elements of strings and containers of fixed-width types one by one. Other protocols skip them as before. Unknown fields are still dropped unless keep_unknown_fields is enabled."`
+	GenBufferReuse            bool `gen_buffer_reuse:"Generate an AppendBinary([]byte) method for structs, unions and exceptions that serializes with the binary protocol through a buffer and a protocol pooled per file, so that encoding allocates nothing once the pool is warm. The WriteTo methods of gen_write_to use the same pool."`
+	GenByteSize               bool `gen_byte_size:"Generate a BytesLength method for structs, unions and exceptions that returns the exact size of the struct serialized by Write with the binary protocol, to allocate the output buffer once before writing."`
+	UnionGetters              bool `union_getters:"Generate a '<Union>FieldID' type with a constant for each field of a union, a GetSetField method that returns the ID of the set field, and a 'New<Union>From<Field>' function for each field that creates the union with the field set."`
+	GenEnumSQL                bool `gen_enum_sql:"Generate a Scan method that implements sql.Scanner and a Value method that implements driver.Valuer for enums, which store the number of the enum in the database. A NULL is scanned as 0. It changes the methods of scan_value_for_enum, which must not be disabled."`
+	EnumSQLAsString           bool `enum_sql_as_string:"Make the Scan and Value methods of gen_enum_sql store the name of the enum instead of its number. A NULL is scanned as 0. It implies gen_enum_sql."`
+	InheritTypedefAnnotations bool `inherit_typedef_annotations:"Merge the annotations of the typedefs into the fields that use them before generating codes, so that annotations like go.tag on a typedef apply to every field of the type. The annotations of a field override the ones of its typedefs with the same keys. Same as --inherit-typedef-annotations."`
+	GenIndex                  bool `gen_index:"Generate an index.go file in each package with ThriftTypes, the reflect.Type of each enum, struct, union and exception of the IDLs in the package, and ThriftFieldNames, a map from the field-id paths '<Type>.<ID>' of their fields to the go names of the fields."`
+	GenRequiredCheck          bool `gen_required_check:"Generate a Validate method for structs, unions and exceptions, which returns an error naming the path of the first required field that is not set, like 'user.address: required field is not set'. The nested structs are checked too. A required field is only reported when its go type can be nil."`
+	RequiredCheckShallow      bool `required_check_shallow:"Make the Validate methods of gen_required_check check only the fields of their own struct, without the nested structs. It implies gen_required_check."`
+	RequiredCheckOnWrite      bool `required_check_on_write:"Make the Write methods of structs, unions and exceptions return the error of Validate before writing anything. It implies gen_required_check."`
 }
 
 var defaultFeatures = Features{
@@ -80,6 +116,7 @@ var defaultFeatures = Features{
 	GenDatabaseTag:              false,
 	GenOmitEmptyTag:             true,
 	TypedefAsTypeAlias:          true,
+	TypedefAsType:               false,
 	ValidateSet:                 true,
 	ValueTypeForSIC:             false,
 	ScanValueForEnum:            true,
@@ -90,6 +127,8 @@ var defaultFeatures = Features{
 	CompatibleNames:             false,
 	ReserveComments:             false,
 	NilSafe:                     false,
+	GenOptionalAccessors:        false,
+	GenGetters:                  false,
 	FrugalTag:                   false,
 	EscapeDoubleInTag:           true,
 	GenerateTypeMeta:            false,
@@ -108,12 +147,44 @@ var defaultFeatures = Features{
 	NoAliasTypeReflectionMethod: false,
 	EnableRefInterface:          false,
 	GetEnumAnnotation:           false,
+	GenEnumValues:               false,
+	GenServiceIface:             false,
+	GenOnewayResult:             false,
+	GenJSONMethods:              false,
+	JSONDisallowUnknownFields:   false,
+	GenWriteTo:                  false,
+	CtxRW:                       false,
+	FastRead:                    false,
+	GenSplitRead:                false,
+	GenClientSingleton:          false,
+	GenFutureClient:             false,
+	GenTypeRegistry:             false,
+	GenChecksum:                 false,
+	GenIDLChecksum:              false,
+	CheckUnionOnWrite:           false,
+	CheckUnionOnRead:            false,
+	CheckUnionAllowZero:         false,
+	FastSkip:                    false,
+	GenFieldMeta:                false,
+	GenBufferReuse:              false,
+	GenByteSize:                 false,
+	UnionGetters:                false,
+	GenEnumSQL:                  false,
+	EnumSQLAsString:             false,
+	InheritTypedefAnnotations:   false,
+	GenIndex:                    false,
+	GenRequiredCheck:            false,
+	RequiredCheckShallow:        false,
+	RequiredCheckOnWrite:        false,
+	SuffixCollidingNames:        false,
 }
 
 type param struct {
-	name   string
-	desc   string
-	action func(value string, cu *CodeUtils) error
+	name    string
+	desc    string
+	boolean bool // whether the value is "true", "false" or empty
+	list    bool // whether the value is a comma separated list, which the command line splits into arguments
+	action  func(value string, cu *CodeUtils) error
 }
 
 func (p *param) match(value string) bool {
@@ -141,6 +212,126 @@ var codeUtilsParams = []*param{
 			return nil
 		},
 	},
+	{
+		name: "import_replace",
+		desc: "Rewrite the import paths under a path. Form: 'path=repl', (e.g. 'github.com/x/y=internal/vendor/x/y'). The longest matching path wins.",
+		action: func(value string, cu *CodeUtils) error {
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("invalid argument for import_replace: '%s'", value)
+			}
+			cu.ReplaceImportPrefix(strings.TrimSuffix(parts[0], "/"), strings.TrimSuffix(parts[1], "/"))
+			return nil
+		},
+	},
+	{
+		name: "import_alias",
+		desc: "Pin the import alias for the package of a go namespace. Form: 'ns=alias', (e.g. 'example.base=exbase')",
+		action: func(value string, cu *CodeUtils) error {
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) < 2 {
+				return fmt.Errorf("invalid argument for import_alias: '%s'", value)
+			}
+			return cu.SetImportAlias(parts[0], parts[1])
+		},
+	},
+	{
+		name: "package_name",
+		desc: "Pin the name in the package clause of the files of a go namespace, which is the last element of its import path by default. Form: 'ns=name', (e.g. 'example.service.v1=api')",
+		action: func(value string, cu *CodeUtils) error {
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) < 2 {
+				return fmt.Errorf("invalid argument for package_name: '%s'", value)
+			}
+			return cu.SetPackageName(parts[0], parts[1])
+		},
+	},
+	{
+		name: "only_service",
+		desc: "Generate only the specified services and the types they reference, can be set multiple times. Types unrelated to any service are always generated.",
+		action: func(value string, cu *CodeUtils) error {
+			if value == "" {
+				return fmt.Errorf("invalid argument for only_service: '%s'", value)
+			}
+			cu.onlyServices = append(cu.onlyServices, value)
+			return nil
+		},
+	},
+	{
+		name: "profile",
+		desc: "Generate only the declarations, fields and methods for the profile. Those annotated with 'profiles = \"a,b\"' are omitted unless the list contains the profile, the others are always generated.",
+		action: func(value string, cu *CodeUtils) error {
+			if value == "" {
+				return fmt.Errorf("invalid argument for profile: '%s'", value)
+			}
+			cu.profile = value
+			return nil
+		},
+	},
+	{
+		name: "protocols",
+		desc: "Generate the Append<Protocol> and Read<Protocol> methods of structs, unions and exceptions that encode and decode them in byte slices with the protocols in the comma separated list of 'binary' and 'compact', can be set multiple times.",
+		list: true,
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetProtocols(value)
+		},
+	},
+	{
+		name: "exclude",
+		desc: "Skip generating codes for the included IDLs whose paths match the glob pattern in recursive mode, can be set multiple times. They are still used to resolve types. '**' matches any number of directories, e.g. 'vendor/**'.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.AddExclude(value)
+		},
+	},
+	{
+		name: "file_header",
+		desc: "Prepend the rendered template in the given file to every generated file. The template can refer to .IDL, .Version and .Date, which is taken from SOURCE_DATE_EPOCH when it is set.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetFileHeader(value)
+		},
+	},
+	{
+		name: "namespace_fallback",
+		desc: "Compute the go namespace of the IDLs without 'namespace go' with the template, e.g. 'acme.{{.FileBase}}'. The template can refer to .FileBase, .FileName, .Dir and .DirBase of the IDL, and use the 'lower' and 'replace' functions. Default is the file name.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetNamespaceFallback(value)
+		},
+	},
+	{
+		name: "template_dir",
+		desc: "Load templates from '<name>.tmpl' files in the directory to override the default ones with the same names. See docs/go-templates.md.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetTemplateDir(value)
+		},
+	},
+	{
+		name: "runtime",
+		desc: "Specify the runtime that the generated codes work with: 'cloudwego' (default) or 'apache'. With 'apache', the Read and Write methods take a context.Context and pass it to the thrift.TProtocol, and the processors return thrift.TException: the generated codes work with github.com/apache/thrift v0.14.0 and later.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetRuntime(value)
+		},
+	},
+	{
+		name: "gen_presence",
+		desc: "Specify how the presence of optional scalar fields is stored: 'pointer' (default) or 'bitset'. With 'bitset', the fields are values and their presence is stored in a bitset of each struct, which saves a pointer and an allocation per field. The presence is changed by the generated SetXXX and ClearXXX methods, not by assigning the fields, and kept by the generated MarshalJSON and UnmarshalJSON methods, which encode the fields that are not set like nil pointers.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetPresence(value)
+		},
+	},
+	{
+		name: "deprecated_annotation",
+		desc: "Generate a '// Deprecated:' comment for the types, fields, enum values, services and methods that have the annotation with the given key, e.g. 'deprecated'. The value of the annotation is the message of the comment. With 'key=value', e.g. 'status=deprecated', only the annotations with the given value mark the definitions, and the comment has the message 'do not use.'. The comment of a method is put on the service interfaces and the client.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetDeprecationAnnotation(value)
+		},
+	},
+	{
+		name: "go_version",
+		desc: "Specify the version of the go toolchain that the generated codes target, e.g. '1.12'. Default is '" + defaultGoVersion + "'. Codes unsupported by the version are avoided, and options requiring a later version are rejected.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetGoVersion(value)
+		},
+	},
 	{
 		name: "naming_style",
 		desc: fmt.Sprintf(
@@ -156,8 +347,9 @@ var codeUtilsParams = []*param{
 		},
 	},
 	{
-		name: "ignore_initialisms",
-		desc: "Disable spelling correction of initialisms (e.g. 'URL')",
+		name:    "ignore_initialisms",
+		desc:    "Disable spelling correction of initialisms (e.g. 'URL')",
+		boolean: true,
 		action: func(value string, cu *CodeUtils) error {
 			ignore, err := checkBool("ignore_initialisms", value)
 			if err != nil {
@@ -167,6 +359,29 @@ var codeUtilsParams = []*param{
 			return nil
 		},
 	},
+	{
+		name: "initialisms",
+		desc: "Add a comma separated list of initialisms (e.g. 'ID,URL,HTTP') to the default ones spelled in upper case in go names. It also makes the naming styles correct initialisms in camel-cased names, like 'userId' to 'UserID'.",
+		list: true,
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetInitialisms(value)
+		},
+	},
+	{
+		name: "reserved_names",
+		desc: "Add a comma separated list of go names (e.g. 'ID,Table') that the go names of the types, fields, methods and constants from the IDL must not be. Such a name is escaped with the suffix of reserved_suffix, e.g. 'ID_', and so are its getter and setter, e.g. 'GetID_'.",
+		list: true,
+		action: func(value string, cu *CodeUtils) error {
+			return cu.AddReservedNames(value)
+		},
+	},
+	{
+		name: "reserved_suffix",
+		desc: "Specify the suffix to escape the names of reserved_names with. Default is '_'.",
+		action: func(value string, cu *CodeUtils) error {
+			return cu.SetReservedSuffix(value)
+		},
+	},
 	{
 		name: "package_prefix",
 		desc: "Specify a package prefix for all generated codes.",
@@ -198,8 +413,9 @@ func (fs Features) params() (ps []*param) {
 
 		name, nth := n, i // for closure
 		p := &param{
-			name: n,
-			desc: v,
+			name:    n,
+			desc:    v,
+			boolean: true,
 			action: func(value string, cu *CodeUtils) error {
 				val, err := checkBool(name, value)
 				if err != nil {
@@ -219,6 +435,55 @@ func (fs Features) params() (ps []*param) {
 
 var allParams = append(codeUtilsParams, defaultFeatures.params()...)
 
+// resolveOptions returns the value of every boolean option, "true" or "false",
+// and the values of the other options that are given. An option given more
+// than once has its values joined by commas in order.
+func resolveOptions(opts []plugin.Option) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, p := range allParams {
+		if p.boolean {
+			res[p.name] = "false"
+		}
+	}
+	t := reflect.TypeOf(defaultFeatures)
+	x := reflect.ValueOf(defaultFeatures)
+	for i := 0; i < t.NumField(); i++ {
+		if x.Field(i).Bool() {
+			res[strings.SplitN(string(t.Field(i).Tag), ":", 2)[0]] = "true"
+		}
+	}
+
+	var last *param
+next:
+	for _, o := range opts {
+		for _, p := range allParams {
+			if !p.match(o.Name) {
+				continue
+			}
+			last = p
+			if p.boolean {
+				val, err := checkBool(p.name, o.Desc)
+				if err != nil {
+					return nil, err
+				}
+				res[p.name] = strconv.FormatBool(val)
+			} else if v, ok := res[p.name]; ok {
+				res[p.name] = v + "," + o.Desc
+			} else {
+				res[p.name] = o.Desc
+			}
+			continue next
+		}
+		// The rest of a list split by the command line, as in HandleOptions.
+		if o.Desc == "" && last != nil && last.list {
+			res[last.name] += "," + o.Name
+			continue
+		}
+		last = nil
+	}
+	return res, nil
+}
+
 func checkBool(name, value string) (bool, error) {
 	switch value {
 	case "", "true":
@@ -233,6 +498,7 @@ func checkBool(name, value string) (bool, error) {
 // HandleOptions updates the CodeUtils with options.
 func (cu *CodeUtils) HandleOptions(args []string) error {
 	var name, value string
+	var last *param
 next:
 	for _, a := range args {
 		parts := strings.SplitN(a, "=", 2)
@@ -252,9 +518,20 @@ next:
 					return err
 				}
 				cu.Info("option:", a)
+				last = p
 				continue next
 			}
 		}
+		// The rest of a list split by the command line, e.g. 'compact' in
+		// 'protocols=binary,compact'.
+		if value == "" && last != nil && last.list {
+			if err := last.action(name, cu); err != nil {
+				return err
+			}
+			cu.Info("option:", last.name+"="+name)
+			continue
+		}
+		last = nil
 		cu.Info("unsupported option:", a)
 	}
 	return nil
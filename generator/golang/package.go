@@ -0,0 +1,418 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// packageAnnotation moves an enum, a typedef, a struct, a union or an exception
+// into a subpackage of the package of its IDL, e.g. (go.package = "internal").
+const packageAnnotation = "go.package"
+
+// subpackages maps an IDL to the IDLs split out of it by go.package, in the
+// order of their first declarations.
+type subpackages map[*parser.Thrift][]*parser.Thrift
+
+// parentOf returns the IDL that ast is split out of, or ast itself.
+func (s subpackages) parentOf(ast *parser.Thrift) *parser.Thrift {
+	for p, subs := range s {
+		for _, sub := range subs {
+			if sub == ast {
+				return p
+			}
+		}
+	}
+	return ast
+}
+
+// getSubpackage returns the value of go.package in the annotations.
+func getSubpackage(annos parser.Annotations, what string) (string, error) {
+	values := annos.Get(packageAnnotation)
+	switch len(values) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", fmt.Errorf("%s: %s is set more than once", what, packageAnnotation)
+	}
+	for _, p := range strings.Split(values[0], ".") {
+		if !token.IsIdentifier(p) {
+			return "", fmt.Errorf("%s: invalid %s %q, expect a package name or names joined by dots", what, packageAnnotation, values[0])
+		}
+	}
+	return values[0], nil
+}
+
+// splitter moves the declarations annotated with go.package into synthesized
+// IDLs and rewrites the references to them.
+type splitter struct {
+	cu     *CodeUtils
+	subs   subpackages
+	parent map[*parser.Thrift]*parser.Thrift            // synthesized IDL => the IDL it is split out of
+	homes  map[*parser.Thrift]map[string]*parser.Thrift // IDL => moved declaration => synthesized IDL
+	edges  map[*parser.Thrift]map[*parser.Thrift]string // IDLs of the same file that refer to each other => the first reference
+}
+
+// splitPackages splits the declarations annotated with go.package out of the
+// AST and its includes. The declarations of each subpackage are moved into a
+// synthesized IDL whose go namespace is the one of the original IDL followed by
+// the subpackage, and which is included by the IDLs that refer to them, so they
+// are generated and imported like the declarations of any other package. It
+// fails when the IDLs split out of a file would import each other.
+func splitPackages(cu *CodeUtils, ast *parser.Thrift) (subpackages, error) {
+	s := &splitter{
+		cu:     cu,
+		subs:   make(subpackages),
+		parent: make(map[*parser.Thrift]*parser.Thrift),
+		homes:  make(map[*parser.Thrift]map[string]*parser.Thrift),
+		edges:  make(map[*parser.Thrift]map[*parser.Thrift]string),
+	}
+	var asts []*parser.Thrift
+	for t := range ast.DepthFirstSearch() {
+		asts = append(asts, t)
+	}
+	for _, t := range asts {
+		if err := s.split(t); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.subs) == 0 {
+		return nil, nil
+	}
+
+	all := append([]*parser.Thrift(nil), asts...)
+	for _, t := range asts {
+		all = append(all, s.subs[t]...)
+	}
+	for _, t := range all {
+		s.rewrite(t)
+	}
+	for _, t := range asts {
+		if err := s.checkCycle(t); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, t := range all {
+		t.Name2Category = nil
+		for _, inc := range t.Includes {
+			inc.Used = nil
+		}
+	}
+	for _, t := range append([]*parser.Thrift{ast}, all...) {
+		if err := semantic.ResolveSymbols(t); err != nil {
+			return nil, err
+		}
+	}
+	return s.subs, nil
+}
+
+// split moves the annotated declarations of t into the synthesized IDLs.
+func (s *splitter) split(t *parser.Thrift) error {
+	byPkg := make(map[string]*parser.Thrift)
+	sub := func(what, name string, annos parser.Annotations) (*parser.Thrift, error) {
+		pkg, err := getSubpackage(annos, fmt.Sprintf("%s: %s %q", t.Filename, what, name))
+		if pkg == "" || err != nil {
+			return nil, err
+		}
+		if x := byPkg[pkg]; x != nil {
+			return x, nil
+		}
+		dir := filepath.Join(append([]string{filepath.Dir(t.Filename)}, strings.Split(pkg, ".")...)...)
+		x := &parser.Thrift{
+			Filename: filepath.Join(dir, filepath.Base(t.Filename)),
+			Namespaces: []*parser.Namespace{
+				{Language: "go", Name: s.cu.GoNamespace(t) + "." + pkg},
+			},
+		}
+		for _, inc := range t.Includes {
+			x.Includes = append(x.Includes, &parser.Include{
+				Path:         inc.Path,
+				Reference:    inc.Reference,
+				Position:     inc.Position,
+				Alias:        inc.Alias,
+				ResolvedPath: inc.ResolvedPath,
+			})
+		}
+		byPkg[pkg] = x
+		s.subs[t] = append(s.subs[t], x)
+		s.parent[x] = t
+		return x, nil
+	}
+	moved := func(name string, x *parser.Thrift) {
+		if s.homes[t] == nil {
+			s.homes[t] = make(map[string]*parser.Thrift)
+		}
+		s.homes[t][name] = x
+	}
+
+	var tds []*parser.Typedef
+	for _, v := range t.Typedefs {
+		x, err := sub("typedef", v.Alias, v.Annotations)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			tds = append(tds, v)
+			continue
+		}
+		x.Typedefs = append(x.Typedefs, v)
+		moved(v.Alias, x)
+	}
+	var enums []*parser.Enum
+	for _, v := range t.Enums {
+		x, err := sub("enum", v.Name, v.Annotations)
+		if err != nil {
+			return err
+		}
+		if x == nil {
+			enums = append(enums, v)
+			continue
+		}
+		x.Enums = append(x.Enums, v)
+		moved(v.Name, x)
+	}
+	structLikes := func(ss []*parser.StructLike, target func(x *parser.Thrift) *[]*parser.StructLike) (res []*parser.StructLike, err error) {
+		for _, v := range ss {
+			x, err := sub(v.Category, v.Name, v.Annotations)
+			if err != nil {
+				return nil, err
+			}
+			if x == nil {
+				res = append(res, v)
+				continue
+			}
+			*target(x) = append(*target(x), v)
+			moved(v.Name, x)
+		}
+		return res, nil
+	}
+	var err error
+	if t.Structs, err = structLikes(t.Structs, func(x *parser.Thrift) *[]*parser.StructLike { return &x.Structs }); err != nil {
+		return err
+	}
+	if t.Unions, err = structLikes(t.Unions, func(x *parser.Thrift) *[]*parser.StructLike { return &x.Unions }); err != nil {
+		return err
+	}
+	if t.Exceptions, err = structLikes(t.Exceptions, func(x *parser.Thrift) *[]*parser.StructLike { return &x.Exceptions }); err != nil {
+		return err
+	}
+	t.Typedefs, t.Enums = tds, enums
+	return nil
+}
+
+// file returns the IDL that t is split out of, or t itself.
+func (s *splitter) file(t *parser.Thrift) *parser.Thrift {
+	if p := s.parent[t]; p != nil {
+		return p
+	}
+	return t
+}
+
+// home returns the IDL that the declaration of the name in the IDL is moved to,
+// or the IDL itself.
+func (s *splitter) home(t *parser.Thrift, name string) *parser.Thrift {
+	if x := s.homes[t][name]; x != nil {
+		return x
+	}
+	return t
+}
+
+// locate returns the name that t refers to a declaration of home with, and
+// records the reference. Before the split, an unqualified name referred to a
+// declaration of the file of t, and a qualified name to the one of an include.
+func (s *splitter) locate(t, home *parser.Thrift, name, what string) string {
+	if home == t {
+		return name
+	}
+	for _, inc := range t.Includes {
+		if inc.Reference == home {
+			return inc.RefName() + "." + name
+		}
+	}
+	alias := strings.TrimSuffix(filepath.Base(home.Filename), filepath.Ext(home.Filename))
+	if s.parent[home] != nil {
+		ns := s.cu.GoNamespace(home)
+		alias = ns[strings.LastIndex(ns, ".")+1:]
+	}
+	for used := true; used; {
+		used = false
+		for _, inc := range t.Includes {
+			if inc.RefName() == alias {
+				alias += "_"
+				used = true
+			}
+		}
+	}
+	t.Includes = append(t.Includes, &parser.Include{Path: home.Filename, Reference: home, Alias: alias})
+	if s.file(home) == s.file(t) {
+		if s.edges[t] == nil {
+			s.edges[t] = make(map[*parser.Thrift]string)
+		}
+		s.edges[t][home] = fmt.Sprintf("%s in %s refers to %q in %s", what, s.cu.GoNamespace(t), name, s.cu.GoNamespace(home))
+	}
+	return alias + "." + name
+}
+
+// rewriteType rewrites the names that typ refers to the moved declarations with.
+func (s *splitter) rewriteType(t *parser.Thrift, typ *parser.Type, what string) {
+	if typ == nil {
+		return
+	}
+	s.rewriteType(t, typ.KeyType, what)
+	s.rewriteType(t, typ.ValueType, what)
+	if typ.IsTypedef == nil && !typ.Category.IsEnum() && !typ.Category.IsStructLike() {
+		return
+	}
+	decl, name := s.file(t), typ.Name
+	if ref := typ.Reference; ref != nil {
+		decl, name = t.Includes[ref.Index].Reference, ref.Name
+	}
+	home := s.home(decl, name)
+	if typ.Reference != nil && home == decl {
+		return
+	}
+	typ.Name, typ.Reference = s.locate(t, home, name, what), nil
+}
+
+// rewriteValue rewrites the identifiers that v refers to the enums moved or to
+// the constants of the IDLs they are moved out of with.
+func (s *splitter) rewriteValue(t *parser.Thrift, v *parser.ConstValue, what string) {
+	if v == nil {
+		return
+	}
+	switch v.Type {
+	case parser.ConstType_ConstList:
+		for _, e := range v.TypedValue.List {
+			s.rewriteValue(t, e, what)
+		}
+	case parser.ConstType_ConstMap:
+		for _, e := range v.TypedValue.Map {
+			s.rewriteValue(t, e.Key, what)
+			s.rewriteValue(t, e.Value, what)
+		}
+	case parser.ConstType_ConstIdentifier:
+		x := v.Extra
+		if x == nil {
+			return
+		}
+		decl, name := s.file(t), x.Name
+		if x.Index >= 0 {
+			decl = t.Includes[x.Index].Reference
+		}
+		if x.IsEnum {
+			name = x.Sel
+		}
+		home := s.home(decl, name)
+		if x.Index >= 0 && home == decl {
+			return
+		}
+		id := s.locate(t, home, name, what)
+		if x.IsEnum {
+			id += "." + x.Name
+		}
+		v.TypedValue.Identifier = &id
+		v.Extra = nil
+	}
+}
+
+// rewrite rewrites the references of the declarations of t.
+func (s *splitter) rewrite(t *parser.Thrift) {
+	for _, v := range t.Typedefs {
+		s.rewriteType(t, v.Type, fmt.Sprintf("typedef %q", v.Alias))
+	}
+	for _, v := range t.Constants {
+		what := fmt.Sprintf("constant %q", v.Name)
+		s.rewriteType(t, v.Type, what)
+		s.rewriteValue(t, v.Value, what)
+	}
+	fields := func(fs []*parser.Field, what string) {
+		for _, f := range fs {
+			s.rewriteType(t, f.Type, what)
+			s.rewriteValue(t, f.Default, what)
+		}
+	}
+	for _, v := range t.GetStructLikes() {
+		fields(v.Fields, fmt.Sprintf("%s %q", v.Category, v.Name))
+	}
+	for _, svc := range t.Services {
+		for _, f := range svc.Functions {
+			what := fmt.Sprintf("method %q of service %q", f.Name, svc.Name)
+			s.rewriteType(t, f.FunctionType, what)
+			fields(f.Arguments, what)
+			fields(f.Throws, what)
+		}
+	}
+}
+
+// checkCycle reports the first cycle of references among the IDLs split out
+// of t, which would be an import cycle of their packages.
+func (s *splitter) checkCycle(t *parser.Thrift) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*parser.Thrift]int)
+	var path []*parser.Thrift
+	var visit func(x *parser.Thrift) error
+	visit = func(x *parser.Thrift) error {
+		state[x] = visiting
+		path = append(path, x)
+		for _, y := range append([]*parser.Thrift{t}, s.subs[t]...) {
+			if s.edges[x][y] == "" {
+				continue
+			}
+			switch state[y] {
+			case visiting:
+				var refs []string
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == y {
+						for j := i; j < len(path); j++ {
+							next := y
+							if j+1 < len(path) {
+								next = path[j+1]
+							}
+							refs = append(refs, s.edges[path[j]][next])
+						}
+						break
+					}
+				}
+				return fmt.Errorf("%s: %s leads to an import cycle: %s", t.Filename, packageAnnotation, strings.Join(refs, ", and "))
+			case 0:
+				if err := visit(y); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[x] = done
+		return nil
+	}
+	for _, x := range append([]*parser.Thrift{t}, s.subs[t]...) {
+		if state[x] == 0 {
+			if err := visit(x); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// profilesAnnotation lists the profiles that a declaration or field is generated for.
+const profilesAnnotation = "profiles"
+
+// inProfile reports whether the annotations select the profile. Nodes without
+// the profiles annotation belong to every profile.
+func inProfile(annos parser.Annotations, profile string) bool {
+	values := annos.Get(profilesAnnotation)
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		for _, p := range strings.Split(v, ",") {
+			if strings.TrimSpace(p) == profile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// omitted records the names of the declarations removed from each AST.
+type omitted map[*parser.Thrift]map[string]bool
+
+func (o omitted) add(ast *parser.Thrift, name string) {
+	if o[ast] == nil {
+		o[ast] = make(map[string]bool)
+	}
+	o[ast][name] = true
+}
+
+// check returns the name of the first omitted declaration that t refers to.
+func (o omitted) check(ast *parser.Thrift, t *parser.Type) string {
+	if t == nil {
+		return ""
+	}
+	if n := o.check(ast, t.KeyType); n != "" {
+		return n
+	}
+	if n := o.check(ast, t.ValueType); n != "" {
+		return n
+	}
+	if t.IsTypedef == nil && !t.Category.IsEnum() && !t.Category.IsStructLike() {
+		return ""
+	}
+	target, name := ast, t.Name
+	if ref := t.Reference; ref != nil {
+		target, name = ast.Includes[ref.Index].Reference, ref.Name
+	}
+	if o[target][name] {
+		return t.Name
+	}
+	return ""
+}
+
+// pruneProfile removes the declarations, fields and methods of the AST and its
+// includes whose profiles annotation does not list the profile. The IDs of the
+// remaining fields are kept. It fails when a required field is removed or when
+// a remaining node refers to a removed declaration.
+func pruneProfile(ast *parser.Thrift, profile string) error {
+	var asts []*parser.Thrift
+	for t := range ast.DepthFirstSearch() {
+		asts = append(asts, t)
+	}
+	removed := make(omitted)
+	for _, t := range asts {
+		var tds []*parser.Typedef
+		for _, v := range t.Typedefs {
+			if inProfile(v.Annotations, profile) {
+				tds = append(tds, v)
+			} else {
+				removed.add(t, v.Alias)
+			}
+		}
+		var consts []*parser.Constant
+		for _, v := range t.Constants {
+			if inProfile(v.Annotations, profile) {
+				consts = append(consts, v)
+			} else {
+				removed.add(t, v.Name)
+			}
+		}
+		var enums []*parser.Enum
+		for _, v := range t.Enums {
+			if inProfile(v.Annotations, profile) {
+				enums = append(enums, v)
+			} else {
+				removed.add(t, v.Name)
+			}
+		}
+		var svcs []*parser.Service
+		for _, v := range t.Services {
+			if !inProfile(v.Annotations, profile) {
+				removed.add(t, v.Name)
+				continue
+			}
+			var fs []*parser.Function
+			for _, f := range v.Functions {
+				if inProfile(f.Annotations, profile) {
+					fs = append(fs, f)
+				}
+			}
+			v.Functions = fs
+			svcs = append(svcs, v)
+		}
+		structLikes := func(ss []*parser.StructLike) (res []*parser.StructLike, err error) {
+			for _, s := range ss {
+				if !inProfile(s.Annotations, profile) {
+					removed.add(t, s.Name)
+					continue
+				}
+				var fs []*parser.Field
+				for _, f := range s.Fields {
+					if inProfile(f.Annotations, profile) {
+						fs = append(fs, f)
+					} else if f.Requiredness.IsRequired() {
+						return nil, fmt.Errorf("%s: profile %q omits the required field %q of %s %q, which the other profiles can not read without",
+							t.Filename, profile, f.Name, s.Category, s.Name)
+					}
+				}
+				s.Fields = fs
+				res = append(res, s)
+			}
+			return res, nil
+		}
+		var err error
+		if t.Structs, err = structLikes(t.Structs); err != nil {
+			return err
+		}
+		if t.Unions, err = structLikes(t.Unions); err != nil {
+			return err
+		}
+		if t.Exceptions, err = structLikes(t.Exceptions); err != nil {
+			return err
+		}
+		t.Typedefs, t.Constants, t.Enums, t.Services = tds, consts, enums, svcs
+	}
+
+	for _, t := range asts {
+		depends := func(what string, typ *parser.Type) error {
+			if n := removed.check(t, typ); n != "" {
+				return fmt.Errorf("%s: %s refers to %q, which is omitted by profile %q", t.Filename, what, n, profile)
+			}
+			return nil
+		}
+		for _, v := range t.Typedefs {
+			if err := depends(fmt.Sprintf("typedef %q", v.Alias), v.Type); err != nil {
+				return err
+			}
+		}
+		for _, v := range t.Constants {
+			if err := depends(fmt.Sprintf("constant %q", v.Name), v.Type); err != nil {
+				return err
+			}
+		}
+		for _, s := range t.GetStructLikes() {
+			for _, f := range s.Fields {
+				if err := depends(fmt.Sprintf("field %q of %s %q", f.Name, s.Category, s.Name), f.Type); err != nil {
+					return err
+				}
+			}
+		}
+		for _, svc := range t.Services {
+			if svc.Extends != "" {
+				base, name := t, svc.Extends
+				if ref := svc.Reference; ref != nil {
+					base, name = t.Includes[ref.Index].Reference, ref.Name
+				}
+				if removed[base][name] {
+					return fmt.Errorf("%s: service %q extends %q, which is omitted by profile %q", t.Filename, svc.Name, svc.Extends, profile)
+				}
+			}
+			for _, f := range svc.Functions {
+				what := fmt.Sprintf("method %q of service %q", f.Name, svc.Name)
+				if err := depends(what, f.FunctionType); err != nil {
+					return err
+				}
+				for _, fs := range [][]*parser.Field{f.Arguments, f.Throws} {
+					for _, a := range fs {
+						if err := depends(what, a.Type); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+
+		t.Name2Category = nil
+		for _, inc := range t.Includes {
+			inc.Used = nil
+		}
+	}
+	return semantic.ResolveSymbols(ast)
+}
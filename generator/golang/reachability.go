@@ -0,0 +1,186 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// reachability records the definitions that are reachable from a set of roots.
+type reachability map[interface{}]bool
+
+func (r reachability) markService(ast *parser.Thrift, svc *parser.Service) {
+	if svc == nil || r[svc] {
+		return
+	}
+	r[svc] = true
+	for _, f := range svc.Functions {
+		for _, a := range f.Arguments {
+			r.markType(ast, a.Type)
+		}
+		for _, e := range f.Throws {
+			r.markType(ast, e.Type)
+		}
+		if !f.Void {
+			r.markType(ast, f.FunctionType)
+		}
+	}
+	if svc.Extends == "" {
+		return
+	}
+	base, name := ast, svc.Extends
+	if ref := svc.Reference; ref != nil {
+		base, name = ast.Includes[ref.Index].Reference, ref.Name
+	}
+	s, _ := base.GetService(name)
+	r.markService(base, s)
+}
+
+func (r reachability) markType(ast *parser.Thrift, t *parser.Type) {
+	if t == nil {
+		return
+	}
+	r.markType(ast, t.KeyType)
+	r.markType(ast, t.ValueType)
+
+	target, name := ast, t.Name
+	if ref := t.Reference; ref != nil {
+		target, name = ast.Includes[ref.Index].Reference, ref.Name
+	}
+	switch {
+	case t.Category.IsTypedef() || t.IsTypedef != nil:
+		if td, ok := target.GetTypedef(name); ok && !r[td] {
+			r[td] = true
+			r.markType(target, td.Type)
+		}
+	case t.Category.IsEnum():
+		if e, ok := target.GetEnum(name); ok {
+			r[e] = true
+		}
+	case t.Category.IsStructLike():
+		for _, s := range target.GetStructLikes() {
+			if s.Name == name && !r[s] {
+				r[s] = true
+				for _, f := range s.Fields {
+					r.markType(target, f.Type)
+				}
+			}
+		}
+	}
+}
+
+// isRecursive reports whether the struct-like can reach itself through the
+// types of its fields.
+func isRecursive(ast *parser.Thrift, s *parser.StructLike) bool {
+	r := make(reachability)
+	for _, f := range s.Fields {
+		r.markType(ast, f.Type)
+	}
+	return r[s]
+}
+
+// pruneServices removes the services not listed in names from the AST and its
+// includes, together with the types that are only referenced by those services.
+// Types not referenced by any service are shared and always kept.
+func pruneServices(ast *parser.Thrift, names []string) error {
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[n] = true
+	}
+
+	all, keep := make(reachability), make(reachability)
+	for t := range ast.DepthFirstSearch() {
+		for _, svc := range t.Services {
+			all.markService(t, svc)
+			if wanted[svc.Name] {
+				keep.markService(t, svc)
+				delete(wanted, svc.Name)
+			}
+		}
+		for _, c := range t.Constants {
+			keep.markType(t, c.Type)
+		}
+	}
+	for _, n := range names {
+		if wanted[n] {
+			return fmt.Errorf("only_service: service %q not found", n)
+		}
+	}
+
+	for t := range ast.DepthFirstSearch() {
+		for _, td := range t.Typedefs {
+			if !all[td] {
+				keep[td] = true
+				keep.markType(t, td.Type)
+			}
+		}
+		for _, e := range t.Enums {
+			if !all[e] {
+				keep[e] = true
+			}
+		}
+		for _, s := range t.GetStructLikes() {
+			if !all[s] && !keep[s] {
+				keep[s] = true
+				for _, f := range s.Fields {
+					keep.markType(t, f.Type)
+				}
+			}
+		}
+	}
+
+	for t := range ast.DepthFirstSearch() {
+		var svcs []*parser.Service
+		for _, v := range t.Services {
+			if keep[v] {
+				svcs = append(svcs, v)
+			}
+		}
+		var tds []*parser.Typedef
+		for _, v := range t.Typedefs {
+			if keep[v] {
+				tds = append(tds, v)
+			}
+		}
+		var enums []*parser.Enum
+		for _, v := range t.Enums {
+			if keep[v] {
+				enums = append(enums, v)
+			}
+		}
+		t.Services, t.Typedefs, t.Enums = svcs, tds, enums
+		t.Structs = keep.structLikes(t.Structs)
+		t.Unions = keep.structLikes(t.Unions)
+		t.Exceptions = keep.structLikes(t.Exceptions)
+
+		t.Name2Category = nil
+		for _, inc := range t.Includes {
+			inc.Used = nil
+		}
+	}
+	return semantic.ResolveSymbols(ast)
+}
+
+func (r reachability) structLikes(ss []*parser.StructLike) (res []*parser.StructLike) {
+	for _, s := range ss {
+		if r[s] {
+			res = append(res, s)
+		}
+	}
+	return
+}
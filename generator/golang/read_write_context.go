@@ -28,6 +28,9 @@ type ReadWriteContext struct {
 	TypeName  TypeName // The type name in Go code
 	TypeID    string   // For `thrift.TProtocol.(Read|Write)${TypeID}` methods
 	IsPointer bool     // Whether the target type is a pointer type in Go
+	BaseType  string   // The go type to convert a distinct typedef of a base type from and to, or empty
+
+	PairTypeName TypeName // The type of the key-value pairs if the map is generated as a slice of them, or empty
 
 	KeyCtx *ReadWriteContext // sub-context if the type is map
 	ValCtx *ReadWriteContext // sub-context if the type is container
@@ -39,6 +42,8 @@ type ReadWriteContext struct {
 	ids map[string]int // Prefix => local variable index
 
 	FieldMask string
+
+	Protocol string // The protocol of the codec library for the Append and Read methods, e.g. "Compact"
 }
 
 // GenID returns a local variable with the given name as prefix.
@@ -67,6 +72,18 @@ func (c *ReadWriteContext) NeedFieldMask() bool {
 	return c.FieldMask != ""
 }
 
+// WithProtocol sets the protocol of the context and its sub-contexts.
+func (c *ReadWriteContext) WithProtocol(p string) *ReadWriteContext {
+	c.Protocol = p
+	if c.KeyCtx != nil {
+		c.KeyCtx.WithProtocol(p)
+	}
+	if c.ValCtx != nil {
+		c.ValCtx.WithProtocol(p)
+	}
+	return c
+}
+
 // WithTarget sets the target name.
 func (c *ReadWriteContext) WithTarget(t string) *ReadWriteContext {
 	c.Target = t
@@ -79,6 +96,15 @@ func (c *ReadWriteContext) WithSource(s string) *ReadWriteContext {
 	return c
 }
 
+// CodecTypeID returns the type in the names of the methods of the codec
+// library, which uses "I8" for bytes.
+func (c *ReadWriteContext) CodecTypeID() string {
+	if c.TypeID == typeids.Byte {
+		return "I8"
+	}
+	return c.TypeID
+}
+
 func (c *ReadWriteContext) asKeyCtx() *ReadWriteContext {
 	switch c.TypeID {
 	case typeids.Struct:
@@ -104,6 +130,9 @@ func mkRWCtx(r *Resolver, s *Scope, t *parser.Type, top *ReadWriteContext) (*Rea
 		TypeID:    GetTypeID(t),
 		IsPointer: tn.IsPointer(),
 	}
+	if t.GetIsTypedef() && r.util.IsDistinctTypedef(t) {
+		ctx.BaseType = category2BaseType[t.Category]
+	}
 	if top != nil {
 		ctx.ids = top.ids // share the namespace for temporary variables
 	} else {
@@ -120,6 +149,9 @@ func mkRWCtx(r *Resolver, s *Scope, t *parser.Type, top *ReadWriteContext) (*Rea
 			return nil, err
 		}
 		ctx.KeyCtx.asKeyCtx()
+		if ctx.PairTypeName, err = r.GetPairTypeName(s, t); err != nil {
+			return nil, err
+		}
 	}
 
 	if t.Category.IsContainerType() {
@@ -123,6 +123,10 @@ func (r *Resolver) getTypeName(g *Scope, t *parser.Type) (name string, err error
 }
 
 func (r *Resolver) getContainerTypeName(g *Scope, t *parser.Type) (name string, err error) {
+	if isPairMap(t) {
+		pair, err := r.getPairTypeName(g, t)
+		return "[]" + pair, err
+	}
 	if t.Name == "map" {
 		var k string
 		if t.KeyType.Category == parser.Category_Binary {
@@ -143,6 +147,14 @@ func (r *Resolver) getContainerTypeName(g *Scope, t *parser.Type) (name string,
 		name = "[]" // sets and lists compile into slices
 	}
 
+	v, err := r.getValueTypeName(g, t)
+	if err != nil {
+		return "", err
+	}
+	return name + v, nil // map[k]v or []v
+}
+
+func (r *Resolver) getValueTypeName(g *Scope, t *parser.Type) (string, error) {
 	v, err := r.getTypeName(g, t.ValueType)
 	if err != nil {
 		return "", fmt.Errorf("resolve value type of '%s' failed: %w", t, err)
@@ -151,7 +163,62 @@ func (r *Resolver) getContainerTypeName(g *Scope, t *parser.Type) (name string,
 	if t.ValueType.Category.IsStructLike() && !r.util.Features().ValueTypeForSIC && !checkRefInterfaceType(r.util, g, t.ValueType) {
 		v = "*" + v // generate pointer type for struct-like by default
 	}
-	return name + v, nil // map[k]v or []v
+	return v, nil
+}
+
+// isPairMap reports whether the map type t is generated as a slice of key-value
+// pairs because its keys are lists, sets or maps, which can not be keys of go maps.
+// The type t must not be a typedef.
+func isPairMap(t *parser.Type) bool {
+	return t.Category == parser.Category_Map && t.KeyType != nil && t.KeyType.Category.IsContainerType()
+}
+
+// GetPairTypes returns the types of the keys and the values of the pairs in the
+// slice that the map type t is generated as, or empty strings if t is generated as
+// a go map. The type t must be a parser.Type associated with g.
+func (r *Resolver) GetPairTypes(g *Scope, t *parser.Type) (key, value TypeName, err error) {
+	ast, x, err := semantic.Deref(g.ast, t)
+	if err != nil || !isPairMap(x) {
+		return "", "", err
+	}
+	if ast != g.ast {
+		// the scopes of the includes are built before g
+		g = r.util.scopeCache[ast]
+	}
+	k, v, err := r.getPairTypes(g, x)
+	return TypeName(k), TypeName(v), err
+}
+
+// GetPairTypeName returns the type of the key-value pairs in the slice that the map
+// type t is generated as, or an empty string if t is generated as a go map.
+// The type t must be a parser.Type associated with g.
+func (r *Resolver) GetPairTypeName(g *Scope, t *parser.Type) (TypeName, error) {
+	k, v, err := r.GetPairTypes(g, t)
+	if err != nil || k == "" {
+		return "", err
+	}
+	return TypeName(pairTypeName(string(k), string(v))), nil
+}
+
+func (r *Resolver) getPairTypeName(g *Scope, t *parser.Type) (string, error) {
+	k, v, err := r.getPairTypes(g, t)
+	if err != nil {
+		return "", err
+	}
+	return pairTypeName(k, v), nil
+}
+
+func (r *Resolver) getPairTypes(g *Scope, t *parser.Type) (k, v string, err error) {
+	k, err = r.getTypeName(g, t.KeyType)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve key type of '%s' failed: %w", t, err)
+	}
+	v, err = r.getValueTypeName(g, t)
+	return k, v, err
+}
+
+func pairTypeName(k, v string) string {
+	return fmt.Sprintf("struct{ Key %s; Value %s }", k, v)
 }
 
 // getIDValue returns the literal representation of a const value.
@@ -160,8 +227,8 @@ func (r *Resolver) getContainerTypeName(g *Scope, t *parser.Type) (name string,
 func (r *Resolver) getIDValue(g *Scope, extra *parser.ConstValueExtra) (v string, ok bool) {
 	if extra.Index == -1 {
 		if extra.IsEnum {
-			enum, ok := g.ast.GetEnum(extra.Sel)
-			if !ok {
+			var enum *parser.Enum
+			if g, enum = enumScope(g, extra.Sel); enum == nil {
 				return "", false
 			}
 			if en := g.Enum(enum.Name); en != nil {
@@ -191,6 +258,23 @@ func (r *Resolver) getIDValue(g *Scope, extra *parser.ConstValueExtra) (v string
 	return v, v != ""
 }
 
+// enumScope returns the enum named sel in the scope g and the scope. When sel is
+// a typedef, the typedefs are followed to the enum, which may be defined in
+// another scope.
+func enumScope(g *Scope, sel string) (*Scope, *parser.Enum) {
+	if enum, ok := g.ast.GetEnum(sel); ok {
+		return g, enum
+	}
+	td, ok := g.ast.GetTypedef(sel)
+	if !ok {
+		return nil, nil
+	}
+	if ref := td.Type.Reference; ref != nil {
+		return enumScope(g.includes[ref.Index].Scope, ref.Name)
+	}
+	return enumScope(g, td.Type.Name)
+}
+
 // ResolveConst returns the initialization code for a constant or a default value.
 // The type t must be a parser.Type associated with g.
 func (r *Resolver) ResolveConst(g *Scope, name string, t *parser.Type, v *parser.ConstValue) (Code, error) {
@@ -327,6 +411,13 @@ func (r *Resolver) onEnum(g *Scope, name string, t *parser.Type, v *parser.Const
 		return fmt.Sprintf("%d", v.TypedValue.GetInt()), nil
 	case parser.ConstType_ConstIdentifier:
 		val, ok := r.getIDValue(g, v.Extra)
+		if ok && t.GetIsTypedef() && r.util.IsDistinctTypedef(t) {
+			goType, err := r.getTypeName(g, t)
+			if err != nil {
+				return "", err
+			}
+			val = fmt.Sprintf("%s(%s)", goType, val)
+		}
 		if ok {
 			return val, nil
 		}
@@ -339,6 +430,8 @@ func (r *Resolver) onSetOrList(g *Scope, name string, t *parser.Type, v *parser.
 	if err != nil {
 		return "", err
 	}
+	// the element types are declared by the end of a typedef chain
+	g, t, _ = getUnderlay(g, t)
 	var ss []string
 	switch v.Type {
 	case parser.ConstType_ConstList:
@@ -371,9 +464,12 @@ func (r *Resolver) onMap(g *Scope, name string, t *parser.Type, v *parser.ConstV
 	if err != nil {
 		return "", err
 	}
+	// the element types are declared by the end of a typedef chain
+	g, t, _ = getUnderlay(g, t)
 	var kvs []string
 	switch v.Type {
 	case parser.ConstType_ConstMap:
+		pair := isPairMap(t)
 		for _, mcv := range v.TypedValue.Map {
 			keyName := "key of " + name
 			key, err := r.resolveConst(g, keyName, r.bin2str(t.KeyType), mcv.Key)
@@ -385,7 +481,11 @@ func (r *Resolver) onMap(g *Scope, name string, t *parser.Type, v *parser.ConstV
 			if err != nil {
 				return "", err
 			}
-			kvs = append(kvs, fmt.Sprintf("%s: %s,", key, val))
+			if pair {
+				kvs = append(kvs, fmt.Sprintf("{Key: %s, Value: %s},", key, val))
+			} else {
+				kvs = append(kvs, fmt.Sprintf("%s: %s,", key, val))
+			}
 		}
 		if len(kvs) == 0 {
 			return goType + "{}", nil
@@ -425,7 +525,7 @@ func (r *Resolver) onStructLike(g *Scope, name string, t *parser.Type, v *parser
 		return "", err
 	}
 
-	var kvs []string
+	var kvs, sets []string
 	for _, mcv := range v.TypedValue.Map {
 		if mcv.Key.Type != parser.ConstType_ConstLiteral {
 			return "", fmt.Errorf("expect literals as keys in default value of struct type '%s', got '%s'", name, mcv.Key.Type)
@@ -445,12 +545,18 @@ func (r *Resolver) onStructLike(g *Scope, name string, t *parser.Type, v *parser
 			)
 		}
 
-		key := file.StructLike(st.Name).Field(f.Name).GoName().String()
+		sf := file.StructLike(st.Name).Field(f.Name)
+		key := sf.GoName().String()
 		val, err := r.resolveConst(file, st.Name+"."+f.Name, f.Type, mcv.Value)
 		if err != nil {
 			return "", err
 		}
 
+		if sf.HasPresenceBit() {
+			// the bitset is unexported, so the setters mark the presence
+			sets = append(sets, fmt.Sprintf("p.%s(%s)", sf.Setter(), val))
+			continue
+		}
 		if NeedRedirect(f) {
 			if f.Type.Category.IsBaseType() {
 				// a trick to create pointers without temporary variables
@@ -462,10 +568,14 @@ func (r *Resolver) onStructLike(g *Scope, name string, t *parser.Type, v *parser
 		}
 		kvs = append(kvs, fmt.Sprintf("%s: %s,", key, val))
 	}
-	if len(kvs) == 0 {
-		return "&" + goType + "{}", nil
+	init := "&" + goType + "{}"
+	if len(kvs) > 0 {
+		init = fmt.Sprintf("&%s{\n%s\n}", goType, strings.Join(kvs, "\n"))
+	}
+	if len(sets) > 0 {
+		init = fmt.Sprintf("func() *%s {\np := %s\n%s\nreturn p\n}()", goType, init, strings.Join(sets, "\n"))
 	}
-	return fmt.Sprintf("&%s{\n%s\n}", goType, strings.Join(kvs, "\n")), nil
+	return init, nil
 }
 
 func (r *Resolver) getStructLike(g *Scope, t *parser.Type) (f *Scope, s *parser.StructLike, err error) {
@@ -93,17 +93,39 @@ func BuildScope(cu *CodeUtils, ast *parser.Thrift) (*Scope, error) {
 }
 
 func doBuildScope(cu *CodeUtils, ast *parser.Thrift) (*Scope, error) {
-	scope := newScope(ast)
+	scope := newScope(cu, ast)
 	err := scope.init(cu)
 	if err != nil {
 		return nil, fmt.Errorf("process '%s' failed: %w", ast.Filename, err)
 	}
 	scope.importPath = GetImportPath(cu, ast)
-	scope.importPackage = GetImportPackage(scope.importPath)
+	scope.idlName = cu.uniqueIDLName(scope.importPath, idlBaseName(ast))
+	if scope.importPackage = cu.PinnedPackageName(ast); scope.importPackage != "" {
+		return scope, nil
+	}
+	// The directories chosen by the output template may not name the packages.
+	if ns := cu.GoNamespace(ast); cu.importDirs != nil {
+		scope.importPackage = cu.NamespaceToPackage(ns)
+	} else {
+		scope.importPackage = GetImportPackage(scope.importPath)
+	}
 	return scope, nil
 }
 
+// idlBaseName converts the file name of the IDL to an identifier.
+func idlBaseName(ast *parser.Thrift) string {
+	idlName := strings.TrimSuffix(ast.Filename, ".thrift")
+	arr := strings.Split(idlName, string(filepath.Separator))
+	idlName = snakify(arr[len(arr)-1])
+	idlName = strings.ReplaceAll(idlName, ".", "_")
+	idlName = strings.ReplaceAll(idlName, "-", "_")
+	return idlName
+}
+
 func GetImportPath(cu *CodeUtils, ast *parser.Thrift) string {
+	if _, ok := cu.importDirs[cu.GoNamespace(ast)]; ok {
+		return cu.NamespaceToFullImportPath(cu.GoNamespace(ast))
+	}
 	return cu.CombineOutputPath(cu.packagePrefix, ast)
 }
 
@@ -117,10 +139,12 @@ func GetImportPackage(path string) string {
 type Scope struct {
 	ast           *parser.Thrift
 	globals       namespace.Namespace
+	definitions   map[namespace.Namespace]map[string]string // descriptions of the IDL definitions in each namespace
 	imports       *importManager
 	namespace     string
 	importPath    string
 	importPackage string
+	idlName       string
 
 	includes    []*Include
 	constants   []*Constant
@@ -131,6 +155,7 @@ type Scope struct {
 	exceptions  []*StructLike
 	services    []*Service
 	synthesized []*StructLike
+	interfaces  []*Interface
 	refPath     string
 	refPackage  string
 }
@@ -144,13 +169,14 @@ func (s *Scope) FilePackage() string {
 	return s.importPackage
 }
 
+// IDLName returns the name of the IDL that the per-file declarations like
+// file_<name>_thrift_go_types are named after. It is unique in the package, so
+// the IDLs of the same name in different directories are suffixed with _1, _2, etc.
 func (s *Scope) IDLName() string {
-	idlName := strings.TrimSuffix(s.ast.Filename, ".thrift")
-	arr := strings.Split(idlName, string(filepath.Separator))
-	idlName = snakify(arr[len(arr)-1])
-	idlName = strings.ReplaceAll(idlName, ".", "_")
-	idlName = strings.ReplaceAll(idlName, "-", "_")
-	return idlName
+	if s.idlName == "" {
+		return idlBaseName(s.ast)
+	}
+	return s.idlName
 }
 
 func (s *Scope) MarshalDescriptor() string {
@@ -327,6 +353,12 @@ func (s *Scope) StructLikes() (ss []*StructLike) {
 	return
 }
 
+// Interfaces returns the interfaces declared by the go.implements annotations of
+// the struct-likes in the current scope.
+func (s *Scope) Interfaces() []*Interface {
+	return s.interfaces
+}
+
 // Service returns a service defined in the current scope with the given
 // name. It returns nil if the service is not found.
 func (s *Scope) Service(name string) *Service {
@@ -489,10 +521,26 @@ type Field struct {
 	reader          Name
 	writer          Name
 	getter          Name
+	getterOrDefault Name
 	setter          Name
+	clearer         Name
 	isset           Name
 	deepEqual       Name
 	isNested        bool
+	presence        int // 1 + the index of the presence bit of the field, 0 for none
+
+	// for the maps generated as slices of key-value pairs
+	pairLookup        Name
+	pairPut           Name
+	pairKeyTypeName   TypeName
+	pairValueTypeName TypeName
+
+	// the categories of the type, the key and the element for gen_field_meta
+	metaCategories [3]parser.Category
+
+	// for the fields of unions with union_getters
+	unionFieldID Name
+	unionNew     Name
 }
 
 // GoName returns the name in go code of the field.
@@ -537,11 +585,78 @@ func (f *Field) Getter() Name {
 	return f.getter
 }
 
+// GetterOrDefault returns the name of the getter with a fallback value for the field.
+// It is empty unless the field is an optional scalar and gen_optional_accessors is set.
+func (f *Field) GetterOrDefault() Name {
+	return f.getterOrDefault
+}
+
 // Setter returns the setter method's name for the field.
 func (f *Field) Setter() Name {
 	return f.setter
 }
 
+// Clearer returns the name of the method that unsets the field.
+// It is empty unless the field has a presence bit.
+func (f *Field) Clearer() Name {
+	return f.clearer
+}
+
+// PairLookup returns the name of the method that looks up a key in the field of a
+// map generated as a slice of key-value pairs, or an empty string for other fields.
+func (f *Field) PairLookup() Name {
+	return f.pairLookup
+}
+
+// PairPut returns the name of the method that puts a key-value pair into the field
+// of a map generated as a slice of key-value pairs, or an empty string for other fields.
+func (f *Field) PairPut() Name {
+	return f.pairPut
+}
+
+// PairKeyTypeName returns the type of the keys of a map generated as a slice of
+// key-value pairs.
+func (f *Field) PairKeyTypeName() TypeName {
+	return f.pairKeyTypeName
+}
+
+// PairValueTypeName returns the type of the values of a map generated as a slice of
+// key-value pairs.
+func (f *Field) PairValueTypeName() TypeName {
+	return f.pairValueTypeName
+}
+
+// UnionFieldID returns the name of the constant of the ID of a field of a union
+// with union_getters.
+func (f *Field) UnionFieldID() Name {
+	return f.unionFieldID
+}
+
+// UnionNew returns the name of the function that creates a union with the field
+// set with union_getters.
+func (f *Field) UnionNew() Name {
+	return f.unionNew
+}
+
+// MetaCategory returns the category of the type of the field with typedefs
+// resolved. It is only set with gen_field_meta.
+func (f *Field) MetaCategory() parser.Category {
+	return f.metaCategories[0]
+}
+
+// MetaKeyCategory returns the category of the keys of a map field with typedefs
+// resolved. It is zero for other fields or without gen_field_meta.
+func (f *Field) MetaKeyCategory() parser.Category {
+	return f.metaCategories[1]
+}
+
+// MetaElemCategory returns the category of the elements of a list or set field,
+// or the values of a map field, with typedefs resolved. It is zero for other
+// fields or without gen_field_meta.
+func (f *Field) MetaElemCategory() parser.Category {
+	return f.metaCategories[2]
+}
+
 // IsSetter returns the isset method's name for the field.
 func (f *Field) IsSetter() Name {
 	return f.isset
@@ -557,13 +672,37 @@ func (f *Field) IsNested() bool {
 	return f.isNested
 }
 
+// HasPresenceBit reports whether the presence of the field is stored in a bit
+// of the bitset of its struct-like instead of a pointer (gen_presence=bitset).
+func (f *Field) HasPresenceBit() bool {
+	return f.presence > 0
+}
+
+// PresenceWord returns the index of the word in the bitset that holds the
+// presence bit of the field.
+func (f *Field) PresenceWord() int {
+	return (f.presence - 1) / 64
+}
+
+// PresenceMask returns the mask of the presence bit of the field in its word.
+func (f *Field) PresenceMask() Code {
+	return Code(fmt.Sprintf("1<<%d", (f.presence-1)%64))
+}
+
 // StructLike is a wrapper for the parser.StructLike.
 type StructLike struct {
 	*parser.StructLike
-	scope   namespace.Namespace
-	name    Name
-	fields  []*Field
-	isAlias bool
+	scope        namespace.Namespace
+	name         Name
+	fields       []*Field
+	isAlias      bool
+	recursive    bool
+	errorMessage *Field
+	presenceBits int
+	fieldMeta    Name
+	fieldID      Name
+	exception    Name
+	exceptions   []*Field
 }
 
 // GoName returns the name in go code of the struct-like.
@@ -571,6 +710,42 @@ func (s *StructLike) GoName() Name {
 	return s.name
 }
 
+// FieldMetaName returns the name of the variable that describes the fields of
+// the struct-like with gen_field_meta.
+func (s *StructLike) FieldMetaName() Name {
+	return s.fieldMeta
+}
+
+// FieldIDName returns the name of the type of the field IDs of a union with
+// union_getters.
+func (s *StructLike) FieldIDName() Name {
+	return s.fieldID
+}
+
+// ExceptionName returns the name of the method that returns the exception set
+// in a result struct of a method that has a throws clause.
+func (s *StructLike) ExceptionName() Name {
+	return s.exception
+}
+
+// Exceptions returns the exception fields of a result struct in the order of
+// their IDs.
+func (s *StructLike) Exceptions() []*Field {
+	return s.exceptions
+}
+
+// IsRecursive reports whether a value of the struct-like can contain values of
+// its own type, directly or through other types.
+func (s *StructLike) IsRecursive() bool {
+	return s.recursive
+}
+
+// ErrorMessage returns the string field of an exception that its Error method
+// reports. It returns nil if there is no such field.
+func (s *StructLike) ErrorMessage() *Field {
+	return s.errorMessage
+}
+
 // Field returns a field of the struct-like that has the given name.
 // It returns nil if such a field is not found.
 func (s *StructLike) Field(name string) *Field {
@@ -592,11 +767,41 @@ func (s *StructLike) Namespace() namespace.Namespace {
 	return s.scope
 }
 
+// PresenceWords returns the number of uint64 words in the bitset holding the
+// presence bits of the fields. It is 0 if no field has a presence bit.
+func (s *StructLike) PresenceWords() int {
+	return (s.presenceBits + 63) / 64
+}
+
 // IsAlias returns whether this type is alias of existing type
 func (s *StructLike) IsAlias() bool {
 	return s.isAlias
 }
 
+// HasFixedLayout reports whether the struct-like only consists of non-optional
+// fixed-width scalar fields (bool, byte, integers, double and enums) in ascending
+// ID order. Such a struct-like can be read without dispatching on field IDs.
+func (s *StructLike) HasFixedLayout() bool {
+	if len(s.fields) == 0 {
+		return false
+	}
+	for i, f := range s.fields {
+		if i > 0 && f.ID <= s.fields[i-1].ID {
+			return false
+		}
+		if f.Requiredness.IsOptional() || f.Type.GetIsTypedef() {
+			return false
+		}
+		switch f.Type.Category {
+		case parser.Category_Bool, parser.Category_Byte, parser.Category_I16,
+			parser.Category_I32, parser.Category_I64, parser.Category_Double, parser.Category_Enum:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // Service is a wrapper for the parser.Service.
 type Service struct {
 	*parser.Service
@@ -644,6 +849,28 @@ func (s *Service) Functions() []*Function {
 	return s.functions
 }
 
+// AllFunctions returns the functions defined in the service and the ones inherited
+// from its base services transitively, ordered from the root base service to the
+// current one. A function replaces the inherited one with the same name. The
+// Service of an inherited function is the base service that defines it.
+func (s *Service) AllFunctions() []*Function {
+	var fs []*Function
+	if s.base != nil {
+		fs = append(fs, s.base.AllFunctions()...)
+	}
+next:
+	for _, f := range s.functions {
+		for i, g := range fs {
+			if g.Name == f.Name {
+				fs[i] = f
+				continue next
+			}
+		}
+		fs = append(fs, f)
+	}
+	return fs
+}
+
 // Function is a wrapper for the parser.Function.
 type Function struct {
 	*parser.Function
@@ -697,14 +924,15 @@ func (f *Function) Streaming() *streaming.Streaming {
 	return f.streaming
 }
 
-func buildSynthesized(v *parser.Function) (argType, resType *parser.StructLike) {
+func buildSynthesized(v *parser.Function, onewayResult bool) (argType, resType *parser.StructLike) {
 	argType = &parser.StructLike{
 		Category: "struct",
 		Name:     v.Name + "_args",
 		Fields:   v.Arguments,
 	}
 
-	if !v.Oneway {
+	// a oneway function is void and throws nothing, so its result type is empty
+	if !v.Oneway || onewayResult {
 		resType = &parser.StructLike{
 			Category: "struct",
 			Name:     v.Name + "_result",
@@ -16,9 +16,11 @@ package golang
 
 import (
 	"fmt"
+	"go/token"
 	"log"
 	"os"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/cloudwego/thriftgo/generator/golang/streaming"
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/pkg/namespace"
+	"github.com/cloudwego/thriftgo/semantic"
 )
 
 const (
@@ -37,6 +40,12 @@ const (
 	nestedAnnotation    = "thrift.nested"
 	interfaceAnnotation = "thrift.is_interface"
 	aliasAnnotation     = "thrift.is_alias"
+	// importAliasAnnotation pins the alias to import the package of an IDL with.
+	importAliasAnnotation = "go.import.alias"
+	// packageNameAnnotation pins the name in the package clause of the files of an IDL.
+	packageNameAnnotation = "go.package_name"
+	// fieldNameAnnotation overrides the go name of a field.
+	fieldNameAnnotation = "go.name"
 )
 
 func _p(id string) string {
@@ -44,18 +53,22 @@ func _p(id string) string {
 }
 
 // newScope creates an uninitialized scope from the given IDL.
-func newScope(ast *parser.Thrift) *Scope {
+func newScope(cu *CodeUtils, ast *parser.Thrift) *Scope {
 	return &Scope{
 		ast:       ast,
 		imports:   newImportManager(),
 		globals:   namespace.NewNamespace(namespace.UnderscoreSuffix),
-		namespace: ast.GetNamespaceOrReferenceName("go"),
+		namespace: cu.GoNamespace(ast),
 	}
 }
 
 func (s *Scope) init(cu *CodeUtils) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
+			if e, ok := r.(nameError); ok {
+				err = e.error
+				return
+			}
 			err = fmt.Errorf("err = %v, stack = %s", r, debug.Stack())
 		}
 	}()
@@ -71,7 +84,7 @@ func (s *Scope) init(cu *CodeUtils) (err error) {
 
 	if cu.Features().ReorderFields {
 		for _, x := range s.ast.GetStructLikes() {
-			diff := reorderFields(x)
+			diff := reorderFields(x, cu.PresenceBitset())
 			if diff != nil && diff.original != diff.arranged {
 				cu.Info(fmt.Sprintf("<reorder>(%s) %s: %d -> %d: %.2f%%",
 					s.ast.Filename, x.Name, diff.original, diff.arranged, diff.percent()))
@@ -84,7 +97,19 @@ func (s *Scope) init(cu *CodeUtils) (err error) {
 		return err
 	}
 	s.resolveTypesAndValues(cu)
-	return nil
+	for _, st := range s.StructLikes() {
+		st.recursive = isRecursive(s.ast, st.StructLike)
+	}
+	if err = s.checkPairMaps(cu); err != nil {
+		return err
+	}
+	if err = s.buildErrorMessages(); err != nil {
+		return err
+	}
+	if err = s.checkJSONOmitEmpty(); err != nil {
+		return err
+	}
+	return s.buildInterfaces()
 }
 
 func (s *Scope) buildIncludes(cu *CodeUtils) {
@@ -92,11 +117,15 @@ func (s *Scope) buildIncludes(cu *CodeUtils) {
 	cnt := len(s.ast.Includes)
 	s.includes = make([]*Include, cnt)
 
-	for idx, inc := range s.ast.Includes {
-		if !inc.GetUsed() {
-			continue
+	// includes with pinned aliases are processed first so that the aliases will not
+	// be taken by the automatically generated ones.
+	for _, pinned := range []bool{true, false} {
+		for idx, inc := range s.ast.Includes {
+			if !inc.GetUsed() || (cu.GetImportAlias(inc.Reference) != "") != pinned {
+				continue
+			}
+			s.includes[idx] = s.include(cu, inc.Reference)
 		}
-		s.includes[idx] = s.include(cu, inc.Reference)
 	}
 }
 
@@ -108,7 +137,19 @@ func (s *Scope) include(cu *CodeUtils, t *parser.Thrift) *Include {
 	pth := scope.importPath
 	pkg := scope.importPackage
 	if s.namespace != scope.namespace {
-		pkg = s.imports.Add(pkg, pth)
+		if alias := cu.GetImportAlias(t); alias != "" {
+			if !isImportAlias(alias) {
+				panic(nameError{fmt.Errorf("%s: invalid %s %q, expect a go identifier that is neither a keyword nor a predeclared name",
+					t.Filename, importAliasAnnotation, alias)})
+			}
+			if s.imports.Get(pth) != alias && !s.imports.Reserve(alias, pth) {
+				panic(nameError{fmt.Errorf("%s: import alias %q for %q conflicts with %q",
+					s.ast.Filename, alias, pth, s.imports.ID(alias))})
+			}
+			pkg = alias
+		} else {
+			pkg = s.imports.Add(pkg, pth)
+		}
 	}
 	return &Include{
 		PackageName: pkg,
@@ -143,9 +184,27 @@ func (s *Scope) installNames(cu *CodeUtils) error {
 	for _, v := range s.ast.Typedefs {
 		s.buildTypedef(cu, v)
 	}
+	s.typedefs = sortTypedefs(s.typedefs)
 	for _, v := range s.ast.Constants {
 		s.buildConstant(cu, v)
 	}
+	if cu.Features().GenFieldMeta {
+		// named after the definitions so that the variables do not take their names
+		for _, st := range append(s.StructLikes(), s.synthesized...) {
+			st.fieldMeta = Name(s.globals.Add(string(st.name)+"Fields", _p("fields:"+string(st.name))))
+		}
+	}
+	if cu.Features().UnionGetters {
+		for _, st := range s.unions {
+			sn := string(st.name)
+			st.fieldID = Name(s.globals.Add(sn+"FieldID", _p("fieldid:"+sn)))
+			for _, f := range st.fields {
+				fn := string(f.name)
+				f.unionFieldID = Name(s.globals.Add(string(st.fieldID)+"_"+fn, _p("fieldid:"+sn+"."+f.Name)))
+				f.unionNew = Name(s.globals.Add("New"+sn+"From"+fn, _p("newfrom:"+sn+"."+f.Name)))
+			}
+		}
+	}
 	return nil
 }
 
@@ -159,13 +218,137 @@ func (s *Scope) identify(cu *CodeUtils, raw string) string {
 			name += "_"
 		}
 	}
+	if !strings.HasPrefix(raw, prefix) {
+		name = cu.escapeReserved(name)
+	}
 	return name
 }
 
+// nameError is an error of go names that is reported without the stack.
+type nameError struct{ error }
+
+// addName adds the go name of a definition in IDL to the namespace. The desc
+// describes the definition in error messages and is empty for the synthesized
+// ones. When two definitions collide into the same go name, addName panics unless
+// the suffix_colliding_names feature is enabled and the collision is not caused
+// by the initialisms added by users.
+func (s *Scope) addName(cu *CodeUtils, ns namespace.Namespace, name, raw, desc string) string {
+	res := ns.Add(name, raw)
+	if desc == "" {
+		return res
+	}
+	if s.definitions == nil {
+		s.definitions = make(map[namespace.Namespace]map[string]string)
+	}
+	defs := s.definitions[ns]
+	if defs == nil {
+		defs = make(map[string]string)
+		s.definitions[ns] = defs
+	}
+	defs[raw] = desc
+	if res == name {
+		return res
+	}
+	other := ns.ID(name)
+	if _, ok := defs[other]; !ok {
+		return res
+	}
+	if len(cu.Initialisms()) > 0 {
+		a, ok1 := cu.identifyPlain(other)
+		b, ok2 := cu.identifyPlain(raw)
+		if ok1 && ok2 && a != b {
+			panic(nameError{fmt.Errorf("%s and %s are both named %q in go with the initialisms %s",
+				defs[other], desc, name, strings.Join(cu.Initialisms(), ","))})
+		}
+	}
+	if !cu.Features().SuffixCollidingNames {
+		panic(nameError{fmt.Errorf("%s and %s are both named %q in go, rename one of them or enable suffix_colliding_names",
+			defs[other], desc, name)})
+	}
+	return res
+}
+
+// describe returns the description of a definition with its location for addName.
+func (s *Scope) describe(what string, pos *parser.Position) string {
+	return fmt.Sprintf("%s (%s)", what, s.ast.Locate(pos))
+}
+
+// findPairMap returns the first map in t that is generated as a slice of key-value
+// pairs, see isPairMap. The maps in the typedefs used by t are not searched.
+func findPairMap(t *parser.Type) *parser.Type {
+	if t == nil || !t.Category.IsContainerType() {
+		return nil
+	}
+	if isPairMap(t) {
+		return t
+	}
+	if m := findPairMap(t.KeyType); m != nil {
+		return m
+	}
+	return findPairMap(t.ValueType)
+}
+
+// checkPairMaps warns about the maps declared in the IDL that are generated as slices
+// of key-value pairs, and rejects them with the options that require go maps.
+func (s *Scope) checkPairMaps(cu *CodeUtils) error {
+	check := func(what string, pos *parser.Position, t *parser.Type) error {
+		m := findPairMap(t)
+		if m == nil {
+			return nil
+		}
+		what = s.describe(what, pos)
+		var option string
+		switch {
+		case cu.Features().WithFieldMask:
+			option = "with_field_mask"
+		case cu.Features().FrugalTag:
+			option = "frugal_tag"
+		}
+		if option != "" {
+			return fmt.Errorf("%s: %s has keys of %s, which go maps can not have, and can not be used with %s",
+				what, m, m.KeyType, option)
+		}
+		cu.Warn(fmt.Sprintf("%s: %s is generated as a slice of key-value pairs instead of a go map, "+
+			"which can not have keys of %s", what, m, m.KeyType))
+		return nil
+	}
+	for _, td := range s.ast.Typedefs {
+		if err := check(fmt.Sprintf("typedef %q", td.Alias), td.Position, td.Type); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.ast.Constants {
+		if err := check(fmt.Sprintf("constant %q", c.Name), c.Position, c.Type); err != nil {
+			return err
+		}
+	}
+	for _, st := range s.ast.GetStructLikes() {
+		for _, f := range st.Fields {
+			if err := check(fmt.Sprintf("field %q of %s %q", f.Name, st.Category, st.Name), f.Position, f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	for _, svc := range s.ast.Services {
+		for _, fn := range svc.Functions {
+			what := fmt.Sprintf("function %q of service %q", fn.Name, svc.Name)
+			if err := check("the result of "+what, fn.Position, fn.FunctionType); err != nil {
+				return err
+			}
+			for _, a := range fn.Arguments {
+				if err := check(fmt.Sprintf("argument %q of %s", a.Name, what), a.Position, a.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Scope) buildService(cu *CodeUtils, v *parser.Service) error {
 	// service name
 	sn := s.identify(cu, v.Name)
-	sn = s.globals.Add(sn, v.Name)
+	sn = s.addName(cu, s.globals, sn, v.Name, s.describe(fmt.Sprintf("service %q", v.Name), v.Position))
 
 	svc := &Service{
 		Service: v,
@@ -178,7 +361,10 @@ func (s *Scope) buildService(cu *CodeUtils, v *parser.Service) error {
 	// function names
 	for _, f := range v.Functions {
 		fn := s.identify(cu, f.Name)
-		fn = svc.scope.Add(fn, f.Name)
+		fn = s.addName(cu, svc.scope, fn, f.Name, s.describe(fmt.Sprintf("function %q of service %q", f.Name, v.Name), f.Position))
+		if f.SinkType != nil {
+			return fmt.Errorf("service %s: function %s: the sinks of fbthrift are not supported", v.Name, f.Name)
+		}
 		st, err := streaming.ParseStreaming(f)
 		if err != nil {
 			return fmt.Errorf("service %s: %s", v.Name, err.Error())
@@ -195,17 +381,25 @@ func (s *Scope) buildService(cu *CodeUtils, v *parser.Service) error {
 
 	// install names for argument types and response types
 	for idx, f := range v.Functions {
-		argType, resType := buildSynthesized(f)
+		argType, resType := buildSynthesized(f, cu.Features().GenOnewayResult)
 		an := v.Name + s.identify(cu, _p(f.Name+"_args"))
 		rn := v.Name + s.identify(cu, _p(f.Name+"_result"))
 
 		fun := svc.functions[idx]
 		fun.argType = s.buildStructLike(cu, argType, _p(an))
-		if !f.Oneway {
+		if resType != nil {
 			fun.resType = s.buildStructLike(cu, resType, _p(rn))
 			if !f.Void {
 				fun.resType.fields[0].isResponse = true
 			}
+			if len(f.Throws) > 0 {
+				rt := fun.resType
+				rt.exception = Name(rt.scope.Add("Exception", _p("exception")))
+				rt.exceptions = append(rt.exceptions, rt.fields[len(rt.fields)-len(f.Throws):]...)
+				sort.SliceStable(rt.exceptions, func(i, j int) bool {
+					return rt.exceptions[i].ID < rt.exceptions[j].ID
+				})
+			}
 		}
 
 		s.buildFunction(cu, fun, f)
@@ -216,6 +410,9 @@ func (s *Scope) buildService(cu *CodeUtils, v *parser.Service) error {
 	pn := sn + "Processor"
 	s.globals.MustReserve(cn, _p("client:"+v.Name))
 	s.globals.MustReserve(pn, _p("processor:"+v.Name))
+	if cu.Features().GenServiceIface {
+		s.globals.MustReserve(sn+"Iface", _p("iface:"+v.Name))
+	}
 	return nil
 }
 
@@ -253,7 +450,7 @@ func (s *Scope) buildFunction(cu *CodeUtils, f *Function, v *parser.Function) {
 
 func (s *Scope) buildTypedef(cu *CodeUtils, t *parser.Typedef) {
 	tn := s.identify(cu, t.Alias)
-	tn = s.globals.Add(tn, t.Alias)
+	tn = s.addName(cu, s.globals, tn, t.Alias, s.describe(fmt.Sprintf("typedef %q", t.Alias), t.Position))
 	if t.Type.Category.IsStructLike() {
 		fn := "New" + tn
 		s.globals.MustReserve(fn, _p("new:"+t.Alias))
@@ -264,9 +461,46 @@ func (s *Scope) buildTypedef(cu *CodeUtils, t *parser.Typedef) {
 	})
 }
 
+// sortTypedefs orders typedefs so that the ones referred to by another typedef
+// in the same IDL precede it. The order of independent typedefs is kept.
+func sortTypedefs(tds []*Typedef) []*Typedef {
+	byName := make(map[string]*Typedef, len(tds))
+	for _, td := range tds {
+		byName[td.Alias] = td
+	}
+	res := make([]*Typedef, 0, len(tds))
+	visited := make(map[*Typedef]bool, len(tds))
+	var visit func(td *Typedef)
+	var deps func(t *parser.Type)
+	deps = func(t *parser.Type) {
+		if t == nil {
+			return
+		}
+		if t.GetIsTypedef() && !t.IsSetReference() {
+			if dep := byName[t.Name]; dep != nil {
+				visit(dep)
+			}
+		}
+		deps(t.KeyType)
+		deps(t.ValueType)
+	}
+	visit = func(td *Typedef) {
+		if visited[td] {
+			return
+		}
+		visited[td] = true
+		deps(td.Type)
+		res = append(res, td)
+	}
+	for _, td := range tds {
+		visit(td)
+	}
+	return res
+}
+
 func (s *Scope) buildEnum(cu *CodeUtils, e *parser.Enum) {
 	en := s.identify(cu, e.Name)
-	en = s.globals.Add(en, e.Name)
+	en = s.addName(cu, s.globals, en, e.Name, s.describe(fmt.Sprintf("enum %q", e.Name), e.Position))
 
 	enum := &Enum{
 		Enum:  e,
@@ -291,7 +525,7 @@ func (s *Scope) buildEnum(cu *CodeUtils, e *parser.Enum) {
 
 func (s *Scope) buildConstant(cu *CodeUtils, v *parser.Constant) {
 	cn := s.identify(cu, v.Name)
-	cn = s.globals.Add(cn, v.Name)
+	cn = s.addName(cu, s.globals, cn, v.Name, s.describe(fmt.Sprintf("constant %q", v.Name), v.Position))
 	s.constants = append(s.constants, &Constant{
 		Constant: v,
 		name:     Name(cn),
@@ -313,7 +547,11 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 		nn = usedName[0]
 	}
 	sn := s.identify(cu, nn)
-	sn = s.globals.Add(sn, v.Name)
+	var desc string
+	if len(usedName) == 0 {
+		desc = s.describe(fmt.Sprintf("%s %q", v.Category, v.Name), v.Position)
+	}
+	sn = s.addName(cu, s.globals, sn, v.Name, desc)
 	s.globals.MustReserve("New"+sn, _p("new:"+nn))
 
 	fids := "fieldIDToName_" + sn
@@ -324,6 +562,9 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 	if !strings.HasPrefix(v.Name, prefix) {
 		if v.Category == "union" {
 			funcs = append(funcs, "CountSetFields")
+			if cu.Features().UnionGetters {
+				funcs = append(funcs, "GetSetField")
+			}
 		}
 		if v.Category == "exception" {
 			funcs = append(funcs, "Error")
@@ -335,6 +576,9 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 			funcs = append(funcs, "DeepEqual")
 		}
 	}
+	if f := cu.Features(); f.GenRequiredCheck || f.RequiredCheckShallow || f.RequiredCheckOnWrite {
+		funcs = append(funcs, "Validate")
+	}
 
 	st := &StructLike{
 		StructLike: v,
@@ -355,7 +599,7 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 	}
 	// reserve method names
 	for _, f := range v.Fields {
-		fn := s.identify(cu, f.Name)
+		fn := s.identifyField(cu, v, f)
 		if cu.Features().EnableNestedStruct && isNestedField(f) {
 			// EnableNestedStruct, the type name needs to be used when retrieving the value for getter&setter
 			fn = s.identify(cu, f.Type.Name)
@@ -373,35 +617,85 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 			st.scope.Add("IsSet"+fn, _p("isset:"+f.Name))
 		}
 		id := id2str(f.ID)
-		st.scope.Add("ReadField"+id, _p("read:"+id))
+		if cu.Features().GenSplitRead {
+			st.scope.Add("readField"+id, _p("read:"+id))
+		} else {
+			st.scope.Add("ReadField"+id, _p("read:"+id))
+		}
 		st.scope.Add("writeField"+id, _p("write:"+id))
 		if cu.Features().GenDeepEqual {
 			st.scope.Add("Field"+id+"DeepEqual", _p("deepequal:"+id))
 		}
 	}
 
+	// reserve the accessors after the methods above, so they never rename the existing ones
+	presence := cu.PresenceBitset() && len(usedName) == 0 // the synthesized structs of services keep the pointers
+	if cu.Features().GenOptionalAccessors || presence {
+		for _, f := range v.Fields {
+			if !isOptionalScalar(f) {
+				continue
+			}
+			fn := s.identifyField(cu, v, f)
+			if cu.Features().GenOptionalAccessors {
+				st.scope.Add("Get"+fn+"OrDefault", _p("getordefault:"+f.Name))
+			}
+			if !cu.Features().GenerateSetter {
+				st.scope.Add("Set"+fn, _p("set:"+f.Name))
+			}
+			if presence {
+				st.scope.Add("Clear"+fn, _p("clear:"+f.Name))
+			}
+		}
+	}
+
+	// the accessors of the maps generated as slices of key-value pairs
+	if len(usedName) == 0 {
+		for _, f := range v.Fields {
+			if _, t, err := semantic.Deref(s.ast, f.Type); err == nil && isPairMap(t) {
+				fn := s.identifyField(cu, v, f)
+				st.scope.Add("Lookup"+fn, _p("lookup:"+f.Name))
+				st.scope.Add("Put"+fn, _p("put:"+f.Name))
+			}
+		}
+	}
+
 	// field names
 	for _, f := range v.Fields {
-		fn := s.identify(cu, f.Name)
+		fn := s.identifyField(cu, v, f)
 		isNested := false
 		if cu.Features().EnableNestedStruct && isNestedField(f) {
 			isNested = true
 		}
-		fn = st.scope.Add(fn, f.Name)
+		fn = s.addName(cu, st.scope, fn, f.Name, s.describe(fmt.Sprintf("field %q of %s %q", f.Name, v.Category, v.Name), f.Position))
 		id := id2str(f.ID)
 		st.fields = append(st.fields, &Field{
-			Field:     f,
-			name:      Name(fn),
-			reader:    Name(st.scope.Get(_p("read:" + id))),
-			writer:    Name(st.scope.Get(_p("write:" + id))),
-			getter:    Name(st.scope.Get(_p("get:" + f.Name))),
-			setter:    Name(st.scope.Get(_p("set:" + f.Name))),
-			isset:     Name(st.scope.Get(_p("isset:" + f.Name))),
-			deepEqual: Name(st.scope.Get(_p("deepequal:" + id))),
-			isNested:  isNested,
+			Field:           f,
+			name:            Name(fn),
+			reader:          Name(st.scope.Get(_p("read:" + id))),
+			writer:          Name(st.scope.Get(_p("write:" + id))),
+			getter:          Name(st.scope.Get(_p("get:" + f.Name))),
+			getterOrDefault: Name(st.scope.Get(_p("getordefault:" + f.Name))),
+			setter:          Name(st.scope.Get(_p("set:" + f.Name))),
+			clearer:         Name(st.scope.Get(_p("clear:" + f.Name))),
+			isset:           Name(st.scope.Get(_p("isset:" + f.Name))),
+			deepEqual:       Name(st.scope.Get(_p("deepequal:" + id))),
+			pairLookup:      Name(st.scope.Get(_p("lookup:" + f.Name))),
+			pairPut:         Name(st.scope.Get(_p("put:" + f.Name))),
+			isNested:        isNested,
 		})
 	}
 
+	s.checkFieldNames(cu, v, st)
+
+	if presence {
+		for _, f := range st.fields {
+			if isOptionalScalar(f.Field) {
+				st.presenceBits++
+				f.presence = st.presenceBits
+			}
+		}
+	}
+
 	if cu.Features().NoAliasTypeReflectionMethod && isAliasType(v) {
 		st.isAlias = true
 	}
@@ -423,6 +717,93 @@ func (s *Scope) buildStructLike(cu *CodeUtils, v *parser.StructLike, usedName ..
 	return st
 }
 
+// identifyField returns the go name of a field, which is the value of the
+// go.name annotation if it is present.
+func (s *Scope) identifyField(cu *CodeUtils, v *parser.StructLike, f *parser.Field) string {
+	vs := f.Annotations.Get(fieldNameAnnotation)
+	if len(vs) == 0 {
+		return s.identify(cu, f.Name)
+	}
+	if !token.IsIdentifier(vs[0]) || !token.IsExported(vs[0]) {
+		panic(nameError{fmt.Errorf("%s: %s=%q is not an exported go identifier",
+			s.describe(fmt.Sprintf("field %q of %s %q", f.Name, v.Category, v.Name), f.Position),
+			fieldNameAnnotation, vs[0])})
+	}
+	return vs[0]
+}
+
+// checkFieldNames reports the fields and methods of a struct that do not get
+// the go names they ask for because of the go.name annotation. The names that
+// collide without the annotation are suffixed with underscores as before, but a
+// name given by go.name, or one that another name takes from a field with
+// go.name, is never changed silently.
+func (s *Scope) checkFieldNames(cu *CodeUtils, v *parser.StructLike, st *StructLike) {
+	if cu.Features().SuffixCollidingNames {
+		return
+	}
+	annotated := false
+	for _, f := range v.Fields {
+		if len(f.Annotations.Get(fieldNameAnnotation)) > 0 {
+			annotated = true
+		}
+	}
+	if !annotated {
+		return
+	}
+
+	type owner struct {
+		desc      string
+		annotated bool
+	}
+	owners := make(map[string]owner) // by the ids in the scope of the struct
+	type name struct{ id, want, got string }
+	var names []name
+	for _, f := range st.fields {
+		desc := s.describe(fmt.Sprintf("field %q of %s %q", f.Name, v.Category, v.Name), f.Position)
+		a := len(f.Annotations.Get(fieldNameAnnotation)) > 0
+		owners[f.Name] = owner{desc, a}
+		if f.isNested {
+			continue
+		}
+		fn := s.identifyField(cu, v, f.Field)
+		names = append(names, name{f.Name, fn, f.name.String()})
+		for _, m := range []struct {
+			kind, key, want string
+			got             Name
+		}{
+			{"getter", "get:", "Get" + fn, f.getter},
+			{"setter", "set:", "Set" + fn, f.setter},
+			{"IsSet method", "isset:", "IsSet" + fn, f.isset},
+			{"OrDefault getter", "getordefault:", "Get" + fn + "OrDefault", f.getterOrDefault},
+			{"clearer", "clear:", "Clear" + fn, f.clearer},
+			{"Lookup method", "lookup:", "Lookup" + fn, f.pairLookup},
+			{"Put method", "put:", "Put" + fn, f.pairPut},
+		} {
+			if m.got == "" {
+				continue
+			}
+			id := _p(m.key + f.Name)
+			owners[id] = owner{"the " + m.kind + " of " + desc, a}
+			names = append(names, name{id, m.want, m.got.String()})
+		}
+	}
+	for _, n := range names {
+		if n.got == n.want {
+			continue
+		}
+		other := st.scope.ID(n.want)
+		o, ok := owners[other]
+		if !ok {
+			o = owner{desc: fmt.Sprintf("the method %q of %s %q", n.want, v.Category, v.Name)}
+		}
+		if !owners[n.id].annotated && !o.annotated {
+			continue
+		}
+		panic(nameError{fmt.Errorf("%s and %s are both named %q in go, rename one of them with %s",
+			o.desc, owners[n.id].desc, n.want, fieldNameAnnotation)})
+	}
+}
+
 func (s *Scope) resolveTypesAndValues(cu *CodeUtils) {
 	resolver := NewResolver(s, cu)
 	frugalResolver := NewFrugalResolver(s, cu)
@@ -458,6 +839,9 @@ func (s *Scope) resolveTypesAndValues(cu *CodeUtils) {
 	for f := range ff {
 		v := f.Field
 		f.typeName = ensureType(resolver.ResolveFieldTypeName(v))
+		if f.HasPresenceBit() {
+			f.typeName = f.typeName.Deref()
+		}
 		// This is used to set the real field name for nested struct, ex.
 		// type T struct {
 		// 	*Nested
@@ -470,6 +854,15 @@ func (s *Scope) resolveTypesAndValues(cu *CodeUtils) {
 			}
 			f.name = Name(name)
 		}
+		if f.pairLookup != "" {
+			k, val, err := resolver.GetPairTypes(s, v.Type)
+			f.pairKeyTypeName, f.pairValueTypeName = ensureType(k, err), ensureType(val, err)
+		}
+		if cu.Features().GenFieldMeta {
+			cats, err := fieldMetaCategories(s.ast, v.Type)
+			ensureType("", err)
+			f.metaCategories = cats
+		}
 		f.frugalTypeName = ensureType(frugalResolver.ResolveFrugalTypeName(v.Type))
 		f.defaultTypeName = ensureType(resolver.GetDefaultValueTypeName(v))
 		if f.IsSetDefault() {
@@ -504,7 +897,7 @@ func (s *Scope) resolveTypesAndValues(cu *CodeUtils) {
 				a.name = Name(fun.scope.Get(f.Name))
 				fun.arguments = append(fun.arguments, &a)
 			}
-			if !fun.Oneway {
+			if fun.resType != nil {
 				fs := fun.resType.fields
 				if !fun.Void {
 					fun.responseType = ensureType(resolver.ResolveTypeName(fs[0].Type))
@@ -515,6 +908,9 @@ func (s *Scope) resolveTypesAndValues(cu *CodeUtils) {
 					t.name = Name(fun.scope.Get(f.Name))
 					fun.throws = append(fun.throws, &t)
 				}
+				sort.SliceStable(fun.throws, func(i, j int) bool {
+					return fun.throws[i].ID < fun.throws[j].ID
+				})
 			}
 		}
 	}
@@ -547,3 +943,9 @@ func annotationContainsTrue(annos parser.Annotations, anno string) bool {
 
 	return false
 }
+
+// isOptionalScalar reports whether the field is an optional field of a base type
+// or an enum without a default value, which is a pointer in go.
+func isOptionalScalar(f *parser.Field) bool {
+	return NeedRedirect(f) && IsBaseType(f.Type)
+}
@@ -24,6 +24,7 @@ import (
 // Apache ports functions adapted from the apache thrift go generator.
 // See https://git-wip-us.apache.org/repos/asf?p=thrift.git;a=blob;f=compiler/cpp/src/thrift/generate/t_go_generator.cc
 type Apache struct {
+	initialisms
 	ignoreInitialisms bool
 }
 
@@ -90,6 +91,9 @@ func (a *Apache) camelcase(name string) string {
 			w = a.fixCommonInitialism(w)
 			ws = append(ws, w)
 		} else {
+			if !a.ignoreInitialisms {
+				w = a.fixHumps(w)
+			}
 			ws = append(ws, "_", w)
 		}
 	}
@@ -100,9 +104,10 @@ func (a *Apache) camelcase(name string) string {
 func (a *Apache) fixCommonInitialism(s string) string {
 	if !a.ignoreInitialisms {
 		u := strings.ToUpper(s)
-		if common.IsCommonInitialisms[u] {
+		if a.isInitialism(u) {
 			return u
 		}
+		return a.fixHumps(s)
 	}
 	return s
 }
@@ -23,6 +23,7 @@ import (
 
 // GoLint implements a naming conversion algorithm that similar to https://github.com/golang/lint.
 type GoLint struct {
+	initialisms
 	nolint bool
 }
 
@@ -93,7 +94,7 @@ func (g *GoLint) lintName(name string) string {
 
 	testInitialisms := func(rs []rune) string {
 		w := string(rs)
-		if u := strings.ToUpper(w); common.IsCommonInitialisms[u] {
+		if u := strings.ToUpper(w); g.isInitialism(u) {
 			// If the identifier starts with a lower case rune, we should keep consistent case.
 			if len(words) == 0 && unicode.IsLower(rs[0]) {
 				return strings.ToLower(w)
@@ -0,0 +1,65 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package styles
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/thriftgo/generator/golang/common"
+)
+
+// initialisms holds the initialisms added by users besides the common ones.
+type initialisms struct {
+	extra map[string]bool
+}
+
+// AddInitialisms implements NamingStyle.
+func (i *initialisms) AddInitialisms(words ...string) {
+	if i.extra == nil {
+		i.extra = make(map[string]bool)
+	}
+	for _, w := range words {
+		i.extra[strings.ToUpper(w)] = true
+	}
+}
+
+func (i *initialisms) isInitialism(upper string) bool {
+	return common.IsCommonInitialisms[upper] || i.extra[upper]
+}
+
+// fixHumps converts the humps of a camel-cased word that are initialisms into
+// upper case, e.g. "UserId" into "UserID". To keep the names generated by default
+// stable, it only works when users have added initialisms.
+func (i *initialisms) fixHumps(word string) string {
+	if len(i.extra) == 0 {
+		return word
+	}
+	var sb strings.Builder
+	rs := []rune(word)
+	start := 0
+	for j := 1; j <= len(rs); j++ {
+		if j < len(rs) && !(unicode.IsUpper(rs[j]) && !unicode.IsUpper(rs[j-1])) {
+			continue
+		}
+		hump := string(rs[start:j])
+		if u := strings.ToUpper(hump); i.isInitialism(u) {
+			hump = u
+		}
+		sb.WriteString(hump)
+		start = j
+	}
+	return sb.String()
+}
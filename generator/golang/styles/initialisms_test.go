@@ -0,0 +1,53 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package styles
+
+import (
+	"testing"
+
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestAddInitialisms(t *testing.T) {
+	identify := func(n Naming, name string) string {
+		s, err := n.Identify(name)
+		test.Assert(t, err == nil, err)
+		return s
+	}
+	for _, name := range NamingStyles() {
+		n := NewNamingStyle(name)
+		test.Assert(t, identify(n, "k8s_config") == "K8sConfig", name)
+
+		n.AddInitialisms("k8s", "URL")
+		test.Assert(t, identify(n, "k8s_config") == "K8SConfig", name)
+		test.Assert(t, identify(n, "homepageUrl") == "HomepageURL", name)
+		test.Assert(t, identify(n, "user_id") == "UserID", name)
+
+		n.UseInitialisms(false)
+		test.Assert(t, identify(n, "homepageUrl") == "HomepageUrl", name)
+	}
+
+	// styles are not shared
+	test.Assert(t, identify(NewNamingStyle("thriftgo"), "k8s") == "K8s")
+
+	tg := new(ThriftGo)
+	test.Assert(t, identify(tg, "userId") == "UserId")
+	test.Assert(t, identify(tg, "Id") == "Id")
+	tg.AddInitialisms("ACL")
+	test.Assert(t, identify(tg, "userId") == "UserID")
+	test.Assert(t, identify(tg, "Id") == "ID")
+	test.Assert(t, identify(tg, "Idle") == "Idle")
+	test.Assert(t, identify(tg, "HTTPServer") == "HTTPServer")
+}
@@ -19,26 +19,29 @@ type Naming interface {
 	Name() string
 	Identify(name string) (string, error)
 	UseInitialisms(enable bool)
+	AddInitialisms(words ...string)
 }
 
-var all = []Naming{
-	new(GoLint), new(Apache), new(ThriftGo),
+var all = []func() Naming{
+	func() Naming { return new(GoLint) },
+	func() Naming { return new(Apache) },
+	func() Naming { return new(ThriftGo) },
 }
 
 // NamingStyles returns all supported naming styles.
 func NamingStyles() (ns []string) {
 	for _, n := range all {
-		ns = append(ns, n.Name())
+		ns = append(ns, n().Name())
 	}
 	return
 }
 
-// NewNamingStyle creates a Naming with the given name.
-// If the given name is supported, this function returns nil.
+// NewNamingStyle creates a naming style with the given name.
+// It returns nil if the name is unknown.
 func NewNamingStyle(name string) Naming {
 	for _, n := range all {
-		if n.Name() == name {
-			return n
+		if style := n(); style.Name() == name {
+			return style
 		}
 	}
 	return nil
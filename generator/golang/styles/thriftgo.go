@@ -23,6 +23,7 @@ import (
 
 // ThriftGo is the default naming style of thrifgo.
 type ThriftGo struct {
+	initialisms
 	noInitialisms bool
 }
 
@@ -42,8 +43,11 @@ func (tg *ThriftGo) Identify(name string) (string, error) {
 		if unicode.IsLower([]rune(w)[0]) {
 			w = common.UpperFirstRune(w)
 			w = tg.fixInitialism(w)
-			ws[i] = w
 		}
+		if !tg.noInitialisms {
+			w = tg.fixHumps(w)
+		}
+		ws[i] = w
 	}
 	s := strings.Join(ws, "")
 	return s, nil
@@ -57,7 +61,7 @@ func (tg *ThriftGo) UseInitialisms(enable bool) {
 func (tg *ThriftGo) fixInitialism(s string) string {
 	if !tg.noInitialisms {
 		u := strings.ToUpper(s)
-		if common.IsCommonInitialisms[u] {
+		if tg.isInitialism(u) {
 			return u
 		}
 	}
@@ -0,0 +1,193 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// jsonOmitEmptyAnnotation adds ("true") or removes ("false") the omitempty option
+// of the json tag of a field, overriding omitempty_for_optional.
+const jsonOmitEmptyAnnotation = "go.json.omitempty"
+
+// tagPair is a key and its quoted value in a struct tag.
+type tagPair struct {
+	key   string
+	value string // with the quotes
+}
+
+func (p tagPair) String() string {
+	return p.key + ":" + p.value
+}
+
+// parseStructTag splits a struct tag in the conventional format of
+// reflect.StructTag, i.e. space-separated key:"value" pairs.
+func parseStructTag(tag string) (pairs []tagPair, err error) {
+	if strings.Contains(tag, "`") {
+		return nil, fmt.Errorf("a struct tag can not contain a backtick: %s", tag)
+	}
+	for rest := strings.TrimLeft(tag, " "); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		i := 0
+		for i < len(rest) && rest[i] > ' ' && rest[i] != ':' && rest[i] != '"' && rest[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(rest) || rest[i] != ':' || rest[i+1] != '"' {
+			return nil, fmt.Errorf("expect key:\"value\" at '%s' in struct tag: %s", rest, tag)
+		}
+		key := rest[:i]
+		rest = rest[i+1:]
+
+		i = 1
+		for i < len(rest) && rest[i] != '"' {
+			if rest[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(rest) {
+			return nil, fmt.Errorf("unterminated value of '%s' in struct tag: %s", key, tag)
+		}
+		pairs = append(pairs, tagPair{key: key, value: rest[:i+1]})
+		rest = rest[i+1:]
+		if rest != "" && rest[0] != ' ' {
+			return nil, fmt.Errorf("expect a space after the value of '%s' in struct tag: %s", key, tag)
+		}
+	}
+	return pairs, nil
+}
+
+// parseGoTags parses the go.tag annotations of a field. With unescape_double_quote,
+// the escaped double quotes in an annotation that is not a valid struct tag are
+// unescaped, as IDLs written for older versions expect.
+func (cu *CodeUtils) parseGoTags(gotags []string) (pairs []tagPair, err error) {
+	keys := make(map[string]bool)
+	for _, tag := range gotags {
+		ps, err := parseStructTag(tag)
+		if err != nil && cu.Features().EscapeDoubleInTag {
+			tag = escape.ReplaceAllStringFunc(tag, func(m string) string {
+				if m[1] == '"' {
+					return m[1:]
+				}
+				return m
+			})
+			ps, err = parseStructTag(tag)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			if keys[p.key] {
+				return nil, fmt.Errorf("key '%s' is set more than once by go.tag", p.key)
+			}
+			keys[p.key] = true
+		}
+		pairs = append(pairs, ps...)
+	}
+	return pairs, nil
+}
+
+// mergeTags replaces the tags with the keys in custom and appends the others in
+// custom to the end.
+func mergeTags(tags []string, custom []tagPair) []string {
+	used := make(map[string]bool)
+	for i, t := range tags {
+		key := strings.SplitN(t, ":", 2)[0]
+		for _, p := range custom {
+			if p.key == key {
+				tags[i] = p.String()
+				used[key] = true
+			}
+		}
+	}
+	for _, p := range custom {
+		if !used[p.key] {
+			tags = append(tags, p.String())
+		}
+	}
+	return tags
+}
+
+// jsonOmitEmpty returns whether the go.json.omitempty annotation is set on f and
+// its value.
+func jsonOmitEmpty(f *parser.Field) (set, omit bool, err error) {
+	vs := f.Annotations.Get(jsonOmitEmptyAnnotation)
+	switch {
+	case len(vs) == 0:
+		return false, false, nil
+	case len(vs) > 1:
+		return false, false, fmt.Errorf("%s is set more than once", jsonOmitEmptyAnnotation)
+	case vs[0] == "true":
+		return true, true, nil
+	case vs[0] == "false":
+		return true, false, nil
+	}
+	return false, false, fmt.Errorf(`%s must be "true" or "false", got %q`, jsonOmitEmptyAnnotation, vs[0])
+}
+
+// checkJSONOmitEmpty validates the go.json.omitempty annotations of the fields.
+func (s *Scope) checkJSONOmitEmpty() error {
+	for _, st := range s.StructLikes() {
+		for _, f := range st.fields {
+			if _, _, err := jsonOmitEmpty(f.Field); err != nil {
+				return fmt.Errorf("%s: field %q: %w", s.describeStructLike(st), f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// JSONMarshalCondition returns the condition for the MarshalJSON method of
+// gen_json_methods to write the field, or "" if the field is always written. A
+// field that is not set is skipped if it is optional or a field of a union,
+// unless go.json.omitempty is "false". With go.json.omitempty = "true", a field
+// is also skipped when it has the zero value, as encoding/json does for the
+// omitempty option.
+func JSONMarshalCondition(f *Field, isUnion bool) string {
+	set, omit, _ := jsonOmitEmpty(f.Field)
+	var conds []string
+	isSet := SupportIsSet(f.Field) && (isUnion || f.Requiredness.IsOptional() && (!set || omit))
+	if isSet {
+		conds = append(conds, "p."+f.IsSetter().String()+"()")
+	}
+	if set && omit && !(isSet && f.GoTypeName().IsPointer()) {
+		if c := jsonNonEmpty(f); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	return strings.Join(conds, " && ")
+}
+
+// jsonNonEmpty returns the condition that the field does not have a value that
+// encoding/json treats as empty, or "" for the structs, which are never empty.
+func jsonNonEmpty(f *Field) string {
+	v := "p." + f.GoName().String()
+	if f.GoTypeName().IsPointer() {
+		return v + " != nil"
+	}
+	switch c := f.Type.Category; {
+	case c == parser.Category_Bool:
+		return v
+	case c == parser.Category_String:
+		return v + ` != ""`
+	case c == parser.Category_Binary || c.IsContainerType():
+		return "len(" + v + ") != 0"
+	case c.IsBaseType() || c == parser.Category_Enum:
+		return v + " != 0"
+	}
+	return ""
+}
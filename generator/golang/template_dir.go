@@ -0,0 +1,104 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// rootTemplate is the name for the template of a whole file in a template directory.
+const rootTemplate = "File"
+
+// overrideTemplates replaces templates in the set with the '<name>.tmpl' files
+// in dir. Each file holds the body of the template to replace and may define
+// extra helper templates. Only templates that already exist can be overridden,
+// and every template invoked by an override must exist after all overrides are
+// loaded. The overrides are parsed with the same functions as the defaults and
+// receive the same data when executed.
+func overrideTemplates(set *template.Template, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("template_dir: %w", err)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("template_dir: no '*.tmpl' file found in %q", dir)
+	}
+	var overrides []*template.Template
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		if name == rootTemplate {
+			name = set.Name()
+		}
+		if set.Lookup(name) == nil {
+			return fmt.Errorf("template_dir: %s: unknown template %q", path, name)
+		}
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("template_dir: %w", err)
+		}
+		tpl := set.New(name)
+		if _, err = tpl.Parse(string(bs)); err != nil {
+			return fmt.Errorf("template_dir: %s: %w", path, err)
+		}
+		if tpl.Tree == nil || parse.IsEmptyTree(tpl.Tree.Root) {
+			return fmt.Errorf("template_dir: %s: the template is empty", path)
+		}
+		overrides = append(overrides, tpl)
+	}
+	for _, tpl := range overrides {
+		var missing []string
+		walkTemplateCalls(tpl.Tree.Root, func(name string) {
+			if set.Lookup(name) == nil {
+				missing = append(missing, name)
+			}
+		})
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("template_dir: template %q invokes undefined templates: %s",
+				tpl.Name(), strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// walkTemplateCalls calls f with the name of every template invoked under the node.
+func walkTemplateCalls(node parse.Node, f func(name string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplateCalls(c, f)
+		}
+	case *parse.TemplateNode:
+		f(n.Name)
+	case *parse.IfNode:
+		walkTemplateCalls(n.List, f)
+		walkTemplateCalls(n.ElseList, f)
+	case *parse.RangeNode:
+		walkTemplateCalls(n.List, f)
+		walkTemplateCalls(n.ElseList, f)
+	case *parse.WithNode:
+		walkTemplateCalls(n.List, f)
+		walkTemplateCalls(n.ElseList, f)
+	}
+}
@@ -61,16 +61,93 @@ func New{{$ClientName}}(c thrift.TClient) *{{$ClientName}}{
 	}
 }
 
+{{- if Features.GenClientSingleton}}
+{{- UseStdLibrary "sync" "fmt"}}
+{{- $Singleton := printf "%sSingleton" ($ClientName | Unexport)}}
+
+// {{$Singleton}} creates the client returned by Get{{$ClientName}} at most once.
+type {{$Singleton}} struct {
+	once    sync.Once
+	factory func() (thrift.TTransport, thrift.TProtocolFactory, error)
+	client  *{{$ClientName}}
+	err     error
+}
+
+var (
+	{{$Singleton}}Mu      sync.Mutex
+	{{$Singleton}}Current = new({{$Singleton}})
+)
+
+// Set{{$ClientName}}TransportFactory sets the factory of the transport and the protocol
+// used by Get{{$ClientName}}. The client created with the previous factory is discarded
+// without being closed, and the next call of Get{{$ClientName}} creates a new one, which
+// allows tests to inject their own transports. It is safe for concurrent use.
+func Set{{$ClientName}}TransportFactory(factory func() (thrift.TTransport, thrift.TProtocolFactory, error)) {
+	{{$Singleton}}Mu.Lock()
+	defer {{$Singleton}}Mu.Unlock()
+	{{$Singleton}}Current = &{{$Singleton}}{factory: factory}
+}
+
+// Get{{$ClientName}} returns the client created with the factory set by
+// Set{{$ClientName}}TransportFactory. The factory is called once by the first call,
+// and all the calls, including the concurrent ones, return the same client or error
+// until the factory is set again. It is safe for concurrent use, while the calls of
+// the shared client are not and must be synchronized by the callers.
+func Get{{$ClientName}}() (*{{$ClientName}}, error) {
+	{{$Singleton}}Mu.Lock()
+	s := {{$Singleton}}Current
+	{{$Singleton}}Mu.Unlock()
+	s.once.Do(func() {
+		if s.factory == nil {
+			s.err = fmt.Errorf("the transport factory of {{$ClientName}} is not set")
+			return
+		}
+		t, f, err := s.factory()
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.client = New{{$ClientName}}Factory(t, f)
+	})
+	return s.client, s.err
+}
+{{- end}}
+
 {{if not .Extends}}
 func (p *{{$ClientName}}) Client_() thrift.TClient {
 	return p.c
 }
 {{end}}
 
+{{- $CtxStruct := printf "%sStruct" ($ClientName | Unexport)}}
+{{- if Features.CtxRW}}
+{{- UseStdLibrary "context"}}
+
+// {{$CtxStruct}} binds a context to the arguments or the result of a call
+// so that they can be sent and received as a thrift.TStruct.
+type {{$CtxStruct}} struct {
+	ctx context.Context
+	s   interface {
+		Read(ctx context.Context, iprot thrift.TProtocol) error
+		Write(ctx context.Context, oprot thrift.TProtocol) error
+	}
+}
+
+func (p {{$CtxStruct}}) Read(iprot thrift.TProtocol) error {
+	return p.s.Read(p.ctx, iprot)
+}
+
+func (p {{$CtxStruct}}) Write(oprot thrift.TProtocol) error {
+	return p.s.Write(p.ctx, oprot)
+}
+{{end}}
+
 {{- range .Functions}}
 {{- $Function := .}} 
 {{- $ArgType := .ArgType}} 
 {{- $ResType := .ResType}} 
+{{- with Deprecation .}}
+{{.}}{{end}}
 func (p *{{$ClientName}}) {{- template "FunctionSignature" . -}} {
 	{{if .Streaming.IsStreaming -}}
 	panic("streaming method {{$ServiceName}}.{{.Name}}(mode = {{.Streaming.Mode}}) not available, please use Kitex Thrift Streaming Client.")
@@ -82,20 +159,17 @@ func (p *{{$ClientName}}) {{- template "FunctionSignature" . -}} {
 
 	{{- if .Void}}
 	{{- if .Oneway}}
-	if err = p.Client_().Call(ctx, "{{.Name}}", &_args, nil); err != nil {
+	if {{if ApacheRuntime}}_, {{end}}err = p.Client_().Call(ctx, "{{.Name}}", {{if Features.CtxRW}}{{$CtxStruct}}{ctx, &_args}{{else}}&_args{{end}}, nil); err != nil {
 		return
 	}
 	{{- else}}
 	var _result {{$ResType.GoName}}
-	if err = p.Client_().Call(ctx, "{{.Name}}", &_args, &_result); err != nil {
+	if {{if ApacheRuntime}}_, {{end}}err = p.Client_().Call(ctx, "{{.Name}}", {{if Features.CtxRW}}{{$CtxStruct}}{ctx, &_args}{{else}}&_args{{end}}, {{if Features.CtxRW}}{{$CtxStruct}}{ctx, &_result}{{else}}&_result{{end}}); err != nil {
 		return
 	}
 	{{- if .Throws}}
-	switch {
-	{{- range .Throws}}
-	case _result.{{($ResType.Field .Name).GoName}} != nil:
-		return _result.{{($ResType.Field .Name).GoName}}
-	{{- end}}
+	if err = _result.{{$ResType.ExceptionName}}(); err != nil {
+		return
 	}
 	{{- end}}
 
@@ -103,21 +177,71 @@ func (p *{{$ClientName}}) {{- template "FunctionSignature" . -}} {
 	return nil
 	{{- else}}{{/* If .Void */}}
 	var _result {{$ResType.GoName}}
-	if err = p.Client_().Call(ctx, "{{.Name}}", &_args, &_result); err != nil {
+	if {{if ApacheRuntime}}_, {{end}}err = p.Client_().Call(ctx, "{{.Name}}", {{if Features.CtxRW}}{{$CtxStruct}}{ctx, &_args}{{else}}&_args{{end}}, {{if Features.CtxRW}}{{$CtxStruct}}{ctx, &_result}{{else}}&_result{{end}}); err != nil {
 		return
 	}
 	{{- if .Throws}}
-	switch {
-	{{- range .Throws}}
-	case _result.{{($ResType.Field .Name).GoName}} != nil:
-		return r, _result.{{($ResType.Field .Name).GoName}}
-	{{- end}}
+	if err = _result.{{$ResType.ExceptionName}}(); err != nil {
+		return
 	}
 	{{- end}}
 	return _result.GetSuccess(), nil
 	{{- end}}{{/* If .Void */}}
 	{{- end}}{{/* If .Streaming.IsStreaming */ -}}
 }
+
+{{- if and Features.GenFutureClient (not .Streaming.IsStreaming)}}
+{{- UseStdLibrary "sync" "fmt"}}
+{{- $Future := printf "%s%sFuture" $ServiceName .GoName}}
+
+// {{$Future}} is the pending result of {{$ClientName}}.{{.GoName}}Async.
+type {{$Future}} struct {
+	ctx  context.Context
+	done chan struct{}
+	once sync.Once
+	{{- if not .Void}}
+	r    {{.ResponseGoTypeName}}
+	{{- end}}
+	err  error
+}
+
+// Get waits for the call to finish and returns its result. It returns the error
+// of the context if the context passed to Get or the one of the call is done first.
+// A future can be awaited only once and the following calls of Get return an error.
+func (f *{{$Future}}) Get(ctx context.Context) ({{if not .Void}}r {{.ResponseGoTypeName}}, {{end}}err error) {
+	first := false
+	f.once.Do(func() { first = true })
+	if !first {
+		return {{if not .Void}}r, {{end}}fmt.Errorf("{{$Future}} has already been awaited")
+	}
+	select {
+	case <-f.done:
+		return {{if not .Void}}f.r, {{end}}f.err
+	case <-ctx.Done():
+		return {{if not .Void}}r, {{end}}ctx.Err()
+	case <-f.ctx.Done():
+		return {{if not .Void}}r, {{end}}f.ctx.Err()
+	}
+}
+
+// {{.GoName}}Async calls {{.GoName}} in a new goroutine with ctx and returns a future of its result.
+func (p *{{$ClientName}}) {{.GoName}}Async(ctx context.Context
+	{{- range .Arguments -}}
+		, {{.GoName}} {{.GoTypeName}}
+	{{- end -}}
+	) *{{$Future}} {
+	_future := &{{$Future}}{ctx: ctx, done: make(chan struct{})}
+	go func() {
+		defer close(_future.done)
+		{{if not .Void}}_future.r, {{end}}_future.err = p.{{.GoName}}(ctx
+		{{- range .Arguments -}}
+			, {{.GoName}}
+		{{- end -}}
+		)
+	}()
+	return _future
+}
+{{- end}}{{/* if Features.GenFutureClient */}}
 {{- end}}{{/* range .Functions */}}
 {{- end}}{{/* if not Features.NoProcessor */}}
 
@@ -0,0 +1,285 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+// StructLikeCodec generates the Append and Read methods of a struct-like for
+// each protocol of the protocols option. They encode and decode the same fields
+// as Write and Read with the codec library, in byte slices rather than through
+// a thrift.TProtocol.
+var StructLikeCodec = `
+{{define "StructLikeCodec"}}
+{{- $TypeName := .GoName}}
+{{- range $P := Protocols}}
+{{- UseStdLibrary "codec" "fmt"}}
+{{- $Proto := printf "codec.%s" $P}}
+
+// Append{{$P}} appends p serialized with the {{ToLower $P}} protocol to b and
+// returns the extended slice.
+func (p *{{$TypeName}}) Append{{$P}}(b []byte) (_ []byte, err error) {
+	{{- if eq $.Category "union"}}
+	{{- if Features.CheckUnionAllowZero}}
+	if c := p.CountSetFields{{$TypeName}}(); c > 1 {
+		return b, fmt.Errorf("%T write union: at most one field can be set (%d set).", p, c)
+	}
+	{{- else}}
+	if c := p.CountSetFields{{$TypeName}}(); c != 1 {
+		return b, fmt.Errorf("%T write union: exactly one field must be set (%d set).", p, c)
+	}
+	{{- end}}
+	{{- end}}
+	{{- if Features.RequiredCheckOnWrite}}
+	if err = p.Validate(); err != nil {
+		return b, fmt.Errorf("%T write error: {{ErrWrapVerb}}", p, err)
+	}
+	{{- end}}
+	{{- if $.Fields}}
+	if p != nil {
+		var last int16
+		{{- range $.Fields}}
+		{{- $ctx := (MkRWCtx .).WithProtocol $P}}
+		{{- if .Requiredness.IsOptional}}
+		if p.{{.IsSetter}}() {
+		{{- end}}
+		{{- if eq "Bool" $ctx.TypeID}}
+		b = {{$Proto}}.AppendBoolField(b, {{.ID}}, last, {{template "CodecValue" $ctx}})
+		last = {{.ID}}
+		{{- else}}
+		b = {{$Proto}}.AppendFieldBegin(b, codec.{{.Type | GetTypeIDConstant}}, {{.ID}}, last)
+		last = {{.ID}}
+		{{- template "FieldAppend" $ctx}}
+		{{- end}}
+		{{- if .Requiredness.IsOptional}}
+		}
+		{{- end}}
+		{{- end}}{{/* range $.Fields */}}
+	}
+	{{- end}}
+	return {{$Proto}}.AppendFieldStop(b), nil
+}
+
+// Read{{$P}} decodes p serialized with the {{ToLower $P}} protocol at the start
+// of b and returns the number of bytes it takes. The fields that are not known
+// or have an unexpected type are skipped.
+func (p *{{$TypeName}}) Read{{$P}}(b []byte) (n int, err error) {
+	var f codec.Field
+	var l int
+	{{- range $.Fields}}
+	{{- if .Requiredness.IsRequired}}
+	var isset{{.GoName}} bool
+	{{- end}}
+	{{- end}}
+	for {
+		if f, l, err = {{$Proto}}.ReadFieldBegin(b[n:], f.ID); err != nil {
+			return n, fmt.Errorf("%T read field begin error: {{ErrWrapVerb}}", p, err)
+		}
+		n += l
+		if f.Type == codec.STOP {
+			break
+		}
+		{{- if $.Fields}}
+		switch f.ID {
+		{{- range $.Fields}}
+		{{- $ctx := (MkRWCtx .).WithProtocol $P}}
+		{{- $target := print $ctx.Target}}
+		case {{.ID}}:
+			if f.Type == codec.{{.Type | GetTypeIDConstant}} {
+				{{- $ctx = $ctx.WithDecl.WithTarget "_field"}}
+				{{- if eq "Bool" $ctx.TypeID}}
+				var _field {{$ctx.TypeName}}
+				if v, l, err := {{$Proto}}.ReadBoolField(b[n:], f); err != nil {
+					return n, fmt.Errorf("%T read field %d error: {{ErrWrapVerb}}", p, f.ID, err)
+				} else {
+					n += l
+					{{- template "CodecAssign" $ctx}}
+				}
+				{{- else}}
+				{{- template "FieldDecode" $ctx}}
+				{{- end}}
+				{{$target}} = _field
+				{{- if .HasPresenceBit}}
+				p._presence[{{.PresenceWord}}] |= {{.PresenceMask}}
+				{{- end}}
+				{{- if .Requiredness.IsRequired}}
+				isset{{.GoName}} = true
+				{{- end}}
+				continue
+			}
+		{{- end}}{{/* range $.Fields */}}
+		}
+		{{- end}}
+		if l, err = {{$Proto}}.Skip(b[n:], f.Type); err != nil {
+			return n, fmt.Errorf("%T skip field %d error: {{ErrWrapVerb}}", p, f.ID, err)
+		}
+		n += l
+	}
+	{{- range $.Fields}}
+	{{- if .Requiredness.IsRequired}}
+	if !isset{{.GoName}} {
+		return n, fmt.Errorf("%T read error: required field {{.Name}} is not set", p)
+	}
+	{{- end}}
+	{{- end}}
+	{{- if and (eq $.Category "union") (or Features.CheckUnionOnWrite Features.CheckUnionOnRead Features.CheckUnionAllowZero) (gt (len $.Fields) 1)}}
+	if c := p.CountSetFields{{$TypeName}}(); c > 1 {
+		return n, fmt.Errorf("%T read union: at most one field can be set (%d set)", p, c)
+	}
+	{{- end}}
+	return n, nil
+}
+{{- end}}{{/* range Protocols */}}
+{{- end}}{{/* define "StructLikeCodec" */}}
+`
+
+// CodecValue is the expression of a base type value to append with the codec
+// library.
+var CodecValue = `
+{{define "CodecValue"}}
+{{- $Value := .Target}}
+{{- if .IsPointer}}{{$Value = printf "*%s" $Value}}{{end}}
+{{- if .BaseType}}{{$Value = printf "%s(%s)" .BaseType $Value}}{{end}}
+{{- if .Type.Category.IsEnum}}{{$Value = printf "int32(%s)" $Value}}{{end}}
+{{- if .Type.Category.IsBinary}}{{$Value = printf "[]byte(%s)" $Value}}{{end}}
+{{- $Value}}
+{{- end}}{{/* define "CodecValue" */}}
+`
+
+// CodecAssign assigns a base type value v decoded by the codec library to the
+// target.
+var CodecAssign = `
+{{define "CodecAssign"}}
+{{- $DiffType := or .Type.Category.IsEnum .Type.Category.IsBinary .BaseType}}
+{{- if .IsPointer}}
+	{{- if $DiffType}}
+		tmp := {{.TypeName.Deref}}(v)
+		{{.Target}} = &tmp
+	{{- else}}
+		{{.Target}} = &v
+	{{- end}}
+{{- else if $DiffType}}
+		{{.Target}} = {{.TypeName}}(v)
+{{- else}}
+		{{.Target}} = v
+{{- end}}
+{{- end}}{{/* define "CodecAssign" */}}
+`
+
+// FieldAppend appends a value with the protocol of the context to b.
+var FieldAppend = `
+{{define "FieldAppend"}}
+{{- $Proto := printf "codec.%s" .Protocol}}
+{{- if .Type.Category.IsStructLike}}
+	if b, err = {{.Target}}.Append{{.Protocol}}(b); err != nil {
+		return b, fmt.Errorf("%T write field %d error: {{ErrWrapVerb}}", p, last, err)
+	}
+{{- else if eq "Map" .TypeID}}
+	b = {{$Proto}}.AppendMapBegin(b, codec.{{.KeyCtx.Type | GetTypeIDConstant}}, codec.{{.ValCtx.Type | GetTypeIDConstant}}, len({{.Target}}))
+	{{- if .PairTypeName}}
+	for _, kv := range {{.Target}} {
+		{{- template "FieldAppend" (.KeyCtx.WithTarget "kv.Key")}}
+		{{- template "FieldAppend" (.ValCtx.WithTarget "kv.Value")}}
+	}
+	{{- else}}
+	for k, v := range {{.Target}} {
+		{{- template "FieldAppend" (.KeyCtx.WithTarget "k")}}
+		{{- template "FieldAppend" (.ValCtx.WithTarget "v")}}
+	}
+	{{- end}}
+{{- else if .Type.Category.IsContainerType}}
+	b = {{$Proto}}.Append{{.TypeID}}Begin(b, codec.{{.ValCtx.Type | GetTypeIDConstant}}, len({{.Target}}))
+	for _, v := range {{.Target}} {
+		{{- template "FieldAppend" (.ValCtx.WithTarget "v")}}
+	}
+{{- else}}
+	b = {{$Proto}}.Append{{.CodecTypeID}}(b, {{template "CodecValue" .}})
+{{- end}}
+{{- end}}{{/* define "FieldAppend" */}}
+`
+
+// FieldDecode decodes a value with the protocol of the context at b[n:] to the
+// target and adds its size to n.
+var FieldDecode = `
+{{define "FieldDecode"}}
+{{- $Proto := printf "codec.%s" .Protocol}}
+{{- if .Type.Category.IsStructLike}}
+	{{- if .NeedDecl}}
+	{{.Target}} := {{.TypeName.Deref.NewFunc}}()
+	{{- end}}
+	if l, err := {{.Target}}.Read{{.Protocol}}(b[n:]); err != nil {
+		return n, fmt.Errorf("%T read field %d error: {{ErrWrapVerb}}", p, f.ID, err)
+	} else {
+		n += l
+	}
+{{- else if .Type.Category.IsContainerType}}
+{{- $isStructVal := .ValCtx.Type.Category.IsStructLike}}
+	{{- if eq "Map" .TypeID}}
+	_, _, size, l, err := {{$Proto}}.ReadMapBegin(b[n:])
+	{{- else}}
+	_, size, l, err := {{$Proto}}.Read{{.TypeID}}Begin(b[n:])
+	{{- end}}
+	if err != nil {
+		return n, fmt.Errorf("%T read field %d error: {{ErrWrapVerb}}", p, f.ID, err)
+	}
+	n += l
+	{{.Target}} {{if .NeedDecl}}:{{end}}= make({{.TypeName}}, {{if or .PairTypeName (ne "Map" .TypeID)}}0, {{end}}size)
+	{{- if $isStructVal}}
+	values := make([]{{.ValCtx.TypeName.Deref}}, size)
+	{{- end}}
+	for i := 0; i < size; i++ {
+		{{- $key := ""}}
+		{{- if eq "Map" .TypeID}}
+		{{- $key = .GenID "_key"}}
+		{{- if .PairTypeName}}
+		{{- /* in a block to not conflict with the variables to read the value */}}
+		var {{$key}} {{.KeyCtx.TypeName}}
+		{
+			{{- template "FieldDecode" (.KeyCtx.WithTarget $key)}}
+		}
+		{{- else}}
+		{{- template "FieldDecode" (.KeyCtx.WithDecl.WithTarget $key)}}
+		{{- end}}
+		{{- end}}
+		{{- $val := .GenID "_elem"}}
+		{{- $ctx := .ValCtx.WithTarget $val}}
+		{{- if $isStructVal}}
+		{{$val}} := &values[i]
+		{{$val}}.InitDefault()
+		{{- else}}
+		{{- $ctx = $ctx.WithDecl}}
+		{{- end}}
+		{{- template "FieldDecode" $ctx}}
+		{{- if and $isStructVal Features.ValueTypeForSIC}}
+		{{- $val = printf "*%s" $val}}
+		{{- end}}
+		{{- if not (eq "Map" .TypeID)}}
+		{{.Target}} = append({{.Target}}, {{$val}})
+		{{- else if .PairTypeName}}
+		{{.Target}} = append({{.Target}}, {{.PairTypeName}}{Key: {{$key}}, Value: {{$val}}})
+		{{- else}}
+		{{.Target}}[{{$key}}] = {{$val}}
+		{{- end}}
+	}
+{{- else}}
+	{{- if .NeedDecl}}
+	var {{.Target}} {{.TypeName}}
+	{{- end}}
+	if v, l, err := {{$Proto}}.Read{{.CodecTypeID}}(b[n:]); err != nil {
+		return n, fmt.Errorf("%T read field %d error: {{ErrWrapVerb}}", p, f.ID, err)
+	} else {
+		n += l
+		{{- template "CodecAssign" .}}
+	}
+{{- end}}
+{{- end}}{{/* define "FieldDecode" */}}
+`
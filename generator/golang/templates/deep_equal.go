@@ -25,6 +25,11 @@ func (p *{{$TypeName}}) DeepEqual(ano *{{$TypeName}}) bool {
 		return false
 	}
 	{{- range .Fields}}
+	{{- if .HasPresenceBit}}
+	if p.{{.IsSetter}}() != ano.{{.IsSetter}}() {
+		return false
+	}
+	{{- end}}
 	if !p.{{.DeepEqual}}(ano.{{.GoName}}) {
 		return false
 	}
@@ -83,6 +88,7 @@ var FieldDeepEqualBase = `
 	{{- $tgt := .Target}}
 	{{- $src := .Source}}
 	{{- if .IsPointer}}{{$tgt = printf "*%s" $tgt}}{{$src = printf "*%s" $src}}{{end}}
+	{{- if .BaseType}}{{$tgt = printf "%s(%s)" .BaseType $tgt}}{{$src = printf "%s(%s)" .BaseType $src}}{{end}}
 	{{- if .Type.Category.IsString}}
 		{{- UseStdLibrary "strings"}}
 		if strings.Compare({{$tgt}}, {{$src}}) != 0 {
@@ -116,11 +122,22 @@ var FieldDeepEqualContainer = `
 	}
 	{{- $src := .GenID "_src"}}
 	{{- $idx := "i"}}
+	{{- if .PairTypeName}}
+	{{- /* the pairs are compared in order */}}
+	for i, kv := range {{.Target}} {
+		{{$src}} := {{.Source}}[i]
+		{{- $ctx := (.KeyCtx.WithTarget "kv.Key").WithSource (printf "%s.Key" $src)}}
+		{{- template "FieldDeepEqual" $ctx}}
+		{{- $ctx := (.ValCtx.WithTarget "kv.Value").WithSource (printf "%s.Value" $src)}}
+		{{- template "FieldDeepEqual" $ctx}}
+	}
+	{{- else}}
 	{{- if eq .Type.Category.String "Map" }}{{$idx = "k"}}{{end}}
 	for {{$idx}}, v := range {{.Target}} {
 		{{$src}} := {{.Source}}[{{$idx}}]
 		{{- $ctx := (.ValCtx.WithTarget "v").WithSource $src}}
 		{{- template "FieldDeepEqual" $ctx}}
 	}
+	{{- end}}
 {{- end}}{{/* "FieldDeepEqualContainer" */}}
 `
@@ -19,13 +19,21 @@ var Enum = `
 {{define "Enum"}}
 {{- $EnumType := .GoName}}
 {{InsertionPoint "enum" .Name}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
 type {{$EnumType}} int{{if Features.EnumAsINT32}}32{{else}}64{{end}}
 
 const (
 	{{- range .Values}}
 	{{- if and Features.ReserveComments .ReservedComments}}
-	{{.ReservedComments}}{{end}}
+	{{.ReservedComments}}
+	{{- if Deprecation .}}
+	//{{end}}{{end}}
+	{{- with Deprecation .}}
+	{{.}}{{end}}
 	{{.GoName}} {{$EnumType}} = {{.Value}}
 	{{- end}}
 )
@@ -73,7 +81,92 @@ func (p *{{$EnumType}}) UnmarshalText(text []byte) error {
 }
 {{end}}{{/* if or Features.MarshalEnumToText Features.UnmarshalEnum */}}
 
+{{- if Features.GenJSONMethods}}
+{{- UseStdLibrary "json"}}
+
+// MarshalJSON encodes the enum with its name, or its number if the value is not defined.
+func (p {{$EnumType}}) MarshalJSON() ([]byte, error) {
+	if s := p.String(); s != "<UNSET>" {
+		return json.Marshal(s)
+	}
+	return json.Marshal(int64(p))
+}
+
+// UnmarshalJSON accepts either the name or the number of the enum.
+func (p *{{$EnumType}}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		q, err := {{$EnumType}}FromString(s)
+		if err != nil {
+			return err
+		}
+		*p = q
+		return nil
+	}
+	var v int64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = {{$EnumType}}(v)
+	return nil
+}
+{{end}}{{/* if Features.GenJSONMethods */}}
+
 {{- if Features.ScanValueForEnum}}
+{{- if or Features.GenEnumSQL Features.EnumSQLAsString}}
+{{- UseStdLibrary "driver"}}
+{{- if Features.EnumSQLAsString}}
+
+// Scan implements sql.Scanner. It reads the name of the enum, and 0 for a NULL.
+func (p *{{$EnumType}}) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*p = 0
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		{{- UseStdLibrary "fmt"}}
+		return fmt.Errorf("can not scan %T into {{$EnumType}}", src)
+	}
+	q, err := {{$EnumType}}FromString(s)
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+// Value implements driver.Valuer. It writes the name of the enum.
+func (p {{$EnumType}}) Value() (driver.Value, error) {
+	s := p.String()
+	if s == "<UNSET>" {
+		return nil, fmt.Errorf("not a valid {{$EnumType}} value: %d", int64(p))
+	}
+	return s, nil
+}
+{{- else}}
+{{- UseStdLibrary "sql"}}
+
+// Scan implements sql.Scanner. It reads the number of the enum, and 0 for a NULL.
+func (p *{{$EnumType}}) Scan(src interface{}) error {
+	var v sql.NullInt64
+	if err := v.Scan(src); err != nil {
+		return err
+	}
+	*p = {{$EnumType}}(v.Int64)
+	return nil
+}
+
+// Value implements driver.Valuer. It writes the number of the enum.
+func (p {{$EnumType}}) Value() (driver.Value, error) {
+	return int64(p), nil
+}
+{{- end}}{{/* if Features.EnumSQLAsString */}}
+{{- else}}
 {{- UseStdLibrary "sql" "driver"}}
 func (p *{{$EnumType}}) Scan(value interface{}) (err error) {
 	var result sql.NullInt{{if Features.EnumAsINT32}}32{{else}}64{{end}}
@@ -88,7 +181,8 @@ func (p *{{$EnumType}}) Value() (driver.Value, error) {
 	}
 	return int{{if Features.EnumAsINT32}}32{{else}}64{{end}}(*p), nil
 }
-{{- end}}{{/* if .Features.ScanValueForEnum */}}
+{{- end}}{{/* if or Features.GenEnumSQL Features.EnumSQLAsString */}}
+{{- end}}{{/* if Features.ScanValueForEnum */}}
 
 {{- if Features.GetEnumAnnotation}}
 var annotations_{{$EnumType}} = map[{{$EnumType}}]map[string][]string{
@@ -109,5 +203,24 @@ func (p {{$EnumType}}) GetAnnotation(key string) []string {
     return nil
 }
 {{- end}}{{/* if Features.GenGetEnumAnnotation */}}
+
+{{- if Features.GenEnumValues}}
+
+// {{$EnumType}}Names lists the string representations of all {{$EnumType}} values in declaration order.
+var {{$EnumType}}Names = []string{
+	{{- range .Values}}
+	"{{.GoLiteral}}",
+	{{- end}}
+}
+
+// {{$EnumType}}Values returns all defined {{$EnumType}} values in declaration order.
+func {{$EnumType}}Values() []{{$EnumType}} {
+	return []{{$EnumType}}{
+		{{- range .Values}}
+		{{.GoName}},
+		{{- end}}
+	}
+}
+{{- end}}{{/* if Features.GenEnumValues */}}
 {{end}}
 `
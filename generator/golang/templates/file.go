@@ -46,8 +46,31 @@ import (
 {{template "StructLike" .}}
 {{- end}}
 
+{{- range .Interfaces}}
+{{template "Interface" .}}
+{{- end}}
+
+{{- if Features.GenBufferReuse}}
+{{template "BufferReuse" .}}
+{{- end}}
+
+{{- if Features.GenWriteTo}}
+{{template "WriteTo" .}}
+{{- end}}
+
+{{- if and Features.FastSkip (or .StructLikes .Services)}}
+{{template "FastSkip" .}}
+{{- end}}
+
+{{- if Features.GenTypeRegistry}}
+{{template "TypeRegistration" .}}
+{{- end}}
+
 {{- range .Services}}
 {{template "ThriftService" .}}
+{{- if Features.GenServiceIface}}
+{{template "ServiceIface" .}}
+{{- end}}
 {{template "ThriftClient" .}}
 {{- end}}
 
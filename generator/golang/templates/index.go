@@ -0,0 +1,52 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+// PackageIndex is the template of the file that lists the types generated in a
+// package for all its IDLs with gen_index.
+var PackageIndex = `// Code generated by thriftgo ({{Version}}). DO NOT EDIT.
+{{InsertionPoint "bof"}}
+
+package {{.FilePackage}}
+
+import "reflect"
+
+// ThriftTypes lists the types of the enums, structs, unions and exceptions in
+// this package, by IDL in the order they are generated and by declaration.
+var ThriftTypes = []reflect.Type{
+	{{- range .Scopes}}
+	{{- range .Enums}}
+	reflect.TypeOf((*{{.GoName}})(nil)).Elem(),
+	{{- end}}
+	{{- range .StructLikes}}
+	reflect.TypeOf((*{{.GoName}})(nil)).Elem(),
+	{{- end}}
+	{{- end}}
+}
+
+// ThriftFieldNames maps the field-id paths of the structs, unions and exceptions
+// in this package, "<thrift name of the type>.<field id>", to the go names of
+// the fields.
+var ThriftFieldNames = map[string]string{
+	{{- range .Scopes}}
+	{{- range $s := .StructLikes}}
+	{{- range .Fields}}
+	{{printf "%q" (print $s.Name "." .ID)}}: {{printf "%q" .GoName}},
+	{{- end}}
+	{{- end}}
+	{{- end}}
+}
+{{- define "Imports"}}{{end}}
+`
@@ -32,12 +32,24 @@ func Templates() []string {
 	return []string{
 		File, Imports, Constant, Enum, Typedef,
 		HandleUnknownFields,
+		SkipField,
 		StructLike,
+		ExceptionError,
+		ResultException,
+		FieldMeta,
+		UnionGetters,
 		StructLikeDefault,
 		StructLikeRead,
 		StructLikeReadField,
 		StructLikeWrite,
 		StructLikeWriteField,
+		StructLikeBytesLength,
+		FieldBytesLength,
+		WriteTo,
+		BufferReuse,
+		FastSkip,
+		TypeRegistration,
+		Interface,
 		FieldGetOrSet,
 		FieldIsSet,
 		FieldRead,
@@ -60,6 +72,15 @@ func Templates() []string {
 		FieldDeepEqualBase,
 		FieldDeepEqualContainer,
 		FieldDeepEqualStructLike,
-		FunctionSignature, Service, Client, Processor,
+		StructLikeJSON,
+		StructLikePresenceJSON,
+		PresenceJSONFields,
+		StructLikeValidate,
+		StructLikeCodec,
+		CodecValue,
+		CodecAssign,
+		FieldAppend,
+		FieldDecode,
+		FunctionSignature, Service, ServiceIface, Client, Processor,
 	}
 }
@@ -0,0 +1,205 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+// StructLikeJSON .
+var StructLikeJSON = `
+{{define "StructLikeJSON"}}
+{{- UseStdLibrary "json" "bytes" "fmt"}}
+{{- $TypeName := .GoName}}
+{{- $IsUnion := eq .Category "union"}}
+// MarshalJSON encodes the fields with their names in the IDL.
+{{- if $IsUnion}}
+// Only the field that is set is emitted.
+{{- else}}
+// Optional fields are omitted when they are not set.
+{{- end}}
+func (p *{{$TypeName}}) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	{{- if $IsUnion}}
+	if c := p.CountSetFields{{$TypeName}}(); c > 1 {
+		return nil, fmt.Errorf("{{$TypeName}}: %d fields of the union are set", c)
+	}
+	{{- end}}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	{{- if .Fields}}
+	write := func(key string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("{{$TypeName}}.%s: {{ErrWrapVerb}}", key, err)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\"" + key + "\":")
+		buf.Write(data)
+		return nil
+	}
+	{{- end}}
+	{{- range .Fields}}
+	{{- $Cond := JSONMarshalCondition . $IsUnion}}
+	{{- if $Cond}}
+	if {{$Cond}} {
+		if err := write("{{.Name}}", &p.{{.GoName}}); err != nil {
+			return nil, err
+		}
+	}
+	{{- else}}
+	if err := write("{{.Name}}", &p.{{.GoName}}); err != nil {
+		return nil, err
+	}
+	{{- end}}
+	{{- end}}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON resets the fields to their default values and decodes the fields in data.
+{{- if Features.JSONDisallowUnknownFields}}
+// Unknown fields are rejected.
+{{- else}}
+// Unknown fields are ignored.
+{{- end}}
+func (p *{{$TypeName}}) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("{{$TypeName}}: {{ErrWrapVerb}}", err)
+	}
+	if fields == nil {
+		return nil
+	}
+	*p = *New{{$TypeName}}()
+	{{- range .Fields}}
+	{{- if .Requiredness.IsRequired}}
+	var isset{{.GoName}} bool
+	{{- end}}
+	{{- end}}
+	{{- if .Fields}}
+	for key, raw := range fields {
+		var err error
+		switch key {
+		{{- range .Fields}}
+		case "{{.Name}}":
+			err = json.Unmarshal(raw, &p.{{.GoName}})
+			{{- if .HasPresenceBit}}
+			if err == nil && string(raw) != "null" {
+				p._presence[{{.PresenceWord}}] |= {{.PresenceMask}}
+			}
+			{{- end}}
+			{{- if .Requiredness.IsRequired}}
+			isset{{.GoName}} = true
+			{{- end}}
+		{{- end}}
+		{{- if Features.JSONDisallowUnknownFields}}
+		default:
+			return fmt.Errorf("{{$TypeName}}: unknown field %q", key)
+		{{- end}}
+		}
+		if err != nil {
+			return fmt.Errorf("{{$TypeName}}.%s: {{ErrWrapVerb}}", key, err)
+		}
+	}
+	{{- else if Features.JSONDisallowUnknownFields}}
+	for key := range fields {
+		return fmt.Errorf("{{$TypeName}}: unknown field %q", key)
+	}
+	{{- end}}
+	{{- range .Fields}}
+	{{- if .Requiredness.IsRequired}}
+	if !isset{{.GoName}} {
+		return fmt.Errorf("{{$TypeName}}: required field {{.Name}} is not set")
+	}
+	{{- end}}
+	{{- end}}
+	{{- if $IsUnion}}
+	if c := p.CountSetFields{{$TypeName}}(); c > 1 {
+		return fmt.Errorf("{{$TypeName}}: %d fields of the union are set", c)
+	}
+	{{- end}}
+	return nil
+}
+{{- end}}{{/* define "StructLikeJSON" */}}
+`
+
+// StructLikePresenceJSON .
+var StructLikePresenceJSON = `
+{{define "StructLikePresenceJSON"}}
+{{- UseStdLibrary "json"}}
+{{- $TypeName := .GoName}}
+// MarshalJSON encodes p like encoding/json does, except that the fields with a
+// presence bit are encoded as nil pointers when they are not set.
+func (p *{{$TypeName}}) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	v := struct {
+		{{- template "PresenceJSONFields" .}}
+	}{
+		{{- range .Fields}}
+		{{- if not .HasPresenceBit}}
+		{{.GoName}}: p.{{.GoName}},
+		{{- end}}
+		{{- end}}
+	}
+	{{- range .Fields}}
+	{{- if .HasPresenceBit}}
+	if p.{{.IsSetter}}() {
+		v.{{.GoName}} = &p.{{.GoName}}
+	}
+	{{- end}}
+	{{- end}}
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON decodes data like encoding/json does and sets the presence bits
+// of the fields that are in data and not null.
+func (p *{{$TypeName}}) UnmarshalJSON(data []byte) error {
+	v := struct {
+		{{- template "PresenceJSONFields" .}}
+	}{
+		{{- range .Fields}}
+		{{- if not .HasPresenceBit}}
+		{{.GoName}}: p.{{.GoName}},
+		{{- end}}
+		{{- end}}
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	{{- range .Fields}}
+	{{- if .HasPresenceBit}}
+	if v.{{.GoName}} != nil {
+		p.{{.Setter}}(*v.{{.GoName}})
+	}
+	{{- else}}
+	p.{{.GoName}} = v.{{.GoName}}
+	{{- end}}
+	{{- end}}
+	return nil
+}
+{{- end}}{{/* define "StructLikePresenceJSON" */}}
+`
+
+// PresenceJSONFields .
+var PresenceJSONFields = `
+{{define "PresenceJSONFields"}}
+{{- range .Fields}}
+		{{.GoName}} {{if .HasPresenceBit}}*{{end}}{{.GoTypeName}} {{GenFieldTags . ""}}
+{{- end}}
+{{- end}}{{/* define "PresenceJSONFields" */}}
+`
@@ -62,19 +62,26 @@ func New{{$ProcessorName}}(handler {{$ServiceName}}) *{{$ProcessorName}} {
 {{- if not .Extends}}
 {{- UseStdLibrary "context"}}
 func (p *{{$ProcessorName}}) Process(ctx context.Context, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	name, _, seqId, err := iprot.ReadMessageBegin()
+	{{- if ApacheRuntime}}
+	name, _, seqId, err2 := iprot.ReadMessageBegin({{ProtoCtx}})
+	if err2 != nil {
+		return false, {{TException "err2"}}
+	}
+	{{- else}}
+	name, _, seqId, err := iprot.ReadMessageBegin({{ProtoCtx}})
 	if err != nil {
 		return false, err
 	}
+	{{- end}}
 	if processor, ok := p.GetProcessorFunction(name); ok {
 		return processor.Process(ctx, seqId, iprot, oprot)
 	}
-	iprot.Skip(thrift.STRUCT)
-	iprot.ReadMessageEnd()
+	iprot.Skip({{ProtoCtxArg}}thrift.STRUCT)
+	iprot.ReadMessageEnd({{ProtoCtx}})
 	x := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
-	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
-	x.Write(oprot)
-	oprot.WriteMessageEnd()
+	oprot.WriteMessageBegin({{ProtoCtxArg}}name, thrift.EXCEPTION, seqId)
+	x.Write({{ProtoCtxArg}}oprot)
+	oprot.WriteMessageEnd({{ProtoCtx}})
 	oprot.Flush(ctx)
 	return false, x
 }
@@ -95,23 +102,24 @@ func (p *{{$ProcessName}}) Process(ctx context.Context, seqId int32, iprot, opro
 	panic("streaming method {{$ServiceName}}.{{.Name}}(mode = {{.Streaming.Mode}}) not available, please use Kitex Thrift Streaming Client.")
 	{{else -}}
 	args := {{$ArgType.GoName}}{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
+	{{- $ReadErr := "err"}}{{if ApacheRuntime}}{{$ReadErr = "err2"}}{{end}}
+	if {{$ReadErr}} {{if ApacheRuntime}}:{{end}}= args.Read({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}iprot); {{$ReadErr}} != nil {
+		iprot.ReadMessageEnd({{ProtoCtx}})
 		{{- if not .Oneway}}
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("{{.Name}}", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, {{$ReadErr}}.Error())
+		oprot.WriteMessageBegin({{ProtoCtxArg}}"{{.Name}}", thrift.EXCEPTION, seqId)
+		x.Write({{ProtoCtxArg}}oprot)
+		oprot.WriteMessageEnd({{ProtoCtx}})
 		oprot.Flush(ctx)
 		{{- end}}
-		return false, err
+		return false, {{TException $ReadErr}}
 	}
 
-	iprot.ReadMessageEnd()
+	iprot.ReadMessageEnd({{ProtoCtx}})
 	var err2 error
 	{{- if .Oneway}}
 	if err2 = p.handler.{{$FuncName}}(ctx {{- range .Arguments}}, args.{{($ArgType.Field .Name).GoName}}{{- end}}); err2 != nil {
-		return true, err2
+		return true, {{TException "err2"}}
 	}
 	return true, nil
 	{{- else}}
@@ -131,19 +139,19 @@ func (p *{{$ProcessName}}) Process(ctx context.Context, seqId int32, iprot, opro
 		{{- end}}
 		default:
 			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing {{.Name}}: "+err2.Error())
-			oprot.WriteMessageBegin("{{.Name}}", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
+			oprot.WriteMessageBegin({{ProtoCtxArg}}"{{.Name}}", thrift.EXCEPTION, seqId)
+			x.Write({{ProtoCtxArg}}oprot)
+			oprot.WriteMessageEnd({{ProtoCtx}})
 			oprot.Flush(ctx)
-			return true, err2
+			return true, {{TException "err2"}}
 		}
 		{{- else}}
 		x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing {{.Name}}: "+err2.Error())
-		oprot.WriteMessageBegin("{{.Name}}", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
+		oprot.WriteMessageBegin({{ProtoCtxArg}}"{{.Name}}", thrift.EXCEPTION, seqId)
+		x.Write({{ProtoCtxArg}}oprot)
+		oprot.WriteMessageEnd({{ProtoCtx}})
 		oprot.Flush(ctx)
-		return true, err2
+		return true, {{TException "err2"}}
 		{{- end}}{{/* if .Throws */}}
 	{{- if not .Void}}
 	} else {
@@ -152,17 +160,17 @@ func (p *{{$ProcessName}}) Process(ctx context.Context, seqId int32, iprot, opro
 		{{- end}}
 	{{- end}}
 	}
-	if err2 = oprot.WriteMessageBegin("{{.Name}}", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err2 = oprot.WriteMessageBegin({{ProtoCtxArg}}"{{.Name}}", thrift.REPLY, seqId); err2 != nil {
+		err = {{TException "err2"}}
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err2 = result.Write({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}oprot); err == nil && err2 != nil {
+		err = {{TException "err2"}}
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	if err2 = oprot.WriteMessageEnd({{ProtoCtx}}); err == nil && err2 != nil {
+		err = {{TException "err2"}}
 	}
 	if err2 = oprot.Flush(ctx); err == nil && err2 != nil {
-		err = err2
+		err = {{TException "err2"}}
 	}
 	if err != nil {
 		return
@@ -179,7 +187,7 @@ func (p *{{$ProcessName}}) Process(ctx context.Context, seqId int32, iprot, opro
 {{- $withFieldMask := (SetWithFieldMask false) }}
 {{template "StructLike" $ArgsType}}
 {{- $_ := (SetWithFieldMask $withFieldMask) }}
-{{- if not .Oneway}}
+{{- if .ResType}}
 	{{$ResType := .ResType}}
 	{{- $withFieldMask := (SetWithFieldMask false) }}
 	{{template "StructLike" $ResType}}
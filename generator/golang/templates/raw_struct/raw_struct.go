@@ -30,13 +30,20 @@ var (
 {{define "StructLike"}}
 {{- $TypeName := .GoName}}
 {{InsertionPoint .Category .Name}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
 type {{$TypeName}} struct {
 {{- range .Fields}}
 	{{- InsertionPoint $.Category $.Name .Name}}
 	{{- if and Features.ReserveComments .ReservedComments}}
 	{{.ReservedComments}}
-	{{- end}}
+	{{- if Deprecation .}}
+	//{{end}}{{end}}
+	{{- with Deprecation .}}
+	{{.}}{{end}}
 	{{- if .IsNested}}
 		{{.GoTypeName}} {{GenFieldTags . (InsertionPoint $.Category $.Name .Name "tag")}}
 	{{else}}
@@ -48,6 +55,7 @@ type {{$TypeName}} struct {
 	_unknownFields unknown.Fields
 	{{- end}}
 }
+{{- template "FieldMeta" .}}
 
 {{- end}}{{/* define "StructLike" */}}
 	`
@@ -74,7 +74,7 @@ var New{{$ArgsType}} = {{$RefPackage}}.New{{$ArgsType}}
 		var {{$DefaultVarName}} = {{$RefPackage}}.{{$DefaultVarName}}
 		{{- end}}	
 	{{- end}}
-{{- if not .Oneway}}
+{{- if .ResType}}
 {{$ResType := .ResType.GoName}}
 type {{$ResType}} = {{$RefPackage}}.{{$ResType}}
 var New{{$ResType}} = {{$RefPackage}}.New{{$ResType}}
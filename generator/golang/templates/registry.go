@@ -0,0 +1,56 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+// TypeRegistration is the code template that registers the enums and struct-likes
+// of an IDL to the type registry of its package.
+var TypeRegistration = `
+{{define "TypeRegistration"}}
+{{- if or .Enums .StructLikes}}
+{{- $Namespace := GoNamespace .AST}}
+func init() {
+	{{- range .Enums}}
+	thriftTypeRegistry[{{printf "%q" (print $Namespace "." .Name)}}] = func() interface{} { return new({{.GoName}}) }
+	{{- end}}
+	{{- range .StructLikes}}
+	thriftTypeRegistry[{{printf "%q" (print $Namespace "." .Name)}}] = func() interface{} { return New{{.GoName}}() }
+	{{- end}}
+}
+{{- end}}
+{{- end}}{{/* define "TypeRegistration" */}}
+`
+
+// TypeRegistry is the template of the file shared by the IDLs of a package that
+// holds the type registry.
+var TypeRegistry = `// Code generated by thriftgo ({{Version}}). DO NOT EDIT.
+{{InsertionPoint "bof"}}
+
+package {{.FilePackage}}
+
+// thriftTypeRegistry maps the fully-qualified thrift names of the enums, structs,
+// unions and exceptions in this package to their constructors.
+var thriftTypeRegistry = make(map[string]func() interface{})
+
+// LookupType returns a new value of the type with the fully-qualified thrift name,
+// e.g. "namespace.TypeName". Structs, unions and exceptions are created by their
+// New functions and enums are pointers to zero values.
+func LookupType(name string) (interface{}, bool) {
+	if ctor, ok := thriftTypeRegistry[name]; ok {
+		return ctor(), true
+	}
+	return nil, false
+}
+{{- define "Imports"}}{{end}}
+`
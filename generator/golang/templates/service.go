@@ -45,7 +45,11 @@ var Service = `
 {{- $BaseService := ServiceName .Base}}
 {{- $ServiceName := .GoName}}
 {{InsertionPoint "service" .Name}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
 type {{$ServiceName}} interface {
 	{{- if .Extends}}
 	{{$BasePrefix}}{{$BaseService}}
@@ -53,8 +57,53 @@ type {{$ServiceName}} interface {
 	{{- range .Functions}}
 	{{InsertionPoint "service" $.Name .Name}}
 	{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+	{{- if .Oneway}}
+	// {{.GoName}} is a oneway method: the client sends the request without waiting for a response.
+	{{- end}}
+	{{- if .Throws}}
+	// {{.GoName}} returns the exceptions in its throws clause as the error: {{range $i, $t := .Throws}}{{if $i}}, {{end}}{{$t.GoTypeName}}{{end}}.
+	{{- end}}
+	{{- if and (Deprecation .) (or .Oneway .Throws (and Features.ReserveComments .ReservedComments))}}
+	//{{end}}
+	{{- with Deprecation .}}
+	{{.}}{{end}}
 	{{template "FunctionSignature" .}}
 	{{- end}}
 }
 {{- end}}{{/* define "ThriftService" */}}
 `
+
+// ServiceIface .
+var ServiceIface = `
+{{define "ServiceIface"}}
+{{- $BasePrefix := ServicePrefix .Base}}
+{{- $BaseService := ServiceName .Base}}
+{{- $IfaceName := printf "%s%s" .GoName "Iface"}}
+// {{$IfaceName}} is the transport-free interface of the service {{.Name}}.
+type {{$IfaceName}} interface {
+	{{- if .Extends}}
+	{{$BasePrefix}}{{$BaseService}}Iface
+	{{- end}}
+	{{- range .Functions}}
+	{{- if .Oneway}}
+	{{- UseStdLibrary "context"}}
+	// {{.GoName}} is a oneway method with arguments in {{.ArgType.GoName}}.
+	{{- with Deprecation .}}
+	//
+	{{.}}{{end}}
+	{{.GoName}}(ctx context.Context
+	{{- range .Arguments -}}
+		, {{.GoName}} {{.GoTypeName}}
+	{{- end -}}
+	)
+	{{- else}}
+	// {{.GoName}} has arguments in {{.ArgType.GoName}} and results in {{.ResType.GoName}}.
+	{{- with Deprecation .}}
+	//
+	{{.}}{{end}}
+	{{template "FunctionSignature" .}}
+	{{- end}}
+	{{- end}}
+}
+{{- end}}{{/* define "ServiceIface" */}}
+`
@@ -48,13 +48,20 @@ var (
 {{define "StructLike"}}
 {{- $TypeName := .GoName}}
 {{InsertionPoint .Category .Name}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
 type {{$TypeName}} struct {
 {{- range .Fields}}
 	{{- InsertionPoint $.Category $.Name .Name}}
 	{{- if and Features.ReserveComments .ReservedComments}}
 	{{.ReservedComments}}
-	{{- end}}
+	{{- if Deprecation .}}
+	//{{end}}{{end}}
+	{{- with Deprecation .}}
+	{{.}}{{end}}
 	{{- if .IsNested}}
 		{{.GoTypeName}} {{GenFieldTags . (InsertionPoint $.Category $.Name .Name "tag")}}
 	{{else}}
@@ -73,6 +80,7 @@ func init() {
 	meta.RegisterStruct(New{{$TypeName}}, {{Marshal .}})
 }
 {{- end}}{{/* if Features.GenerateTypeMeta */}}
+{{- template "FieldMeta" .}}
 
 func New{{$TypeName}}() *{{$TypeName}} {
 	return &{{$TypeName}}{
@@ -103,6 +111,9 @@ func (p *{{$TypeName}}) CountSetFields{{$TypeName}}() int {
 	return count
 }
 {{- end}}
+{{- if and (eq .Category "union") Features.UnionGetters}}
+{{template "UnionGetters" .}}
+{{- end}}
 
 {{if Features.KeepUnknownFields}}
 func (p *{{$TypeName}}) CarryingUnknownFields() bool {
@@ -127,9 +138,11 @@ func (p *{{$TypeName}}) String() string {
 }
 
 {{- if eq .Category "exception"}}
-func (p *{{$TypeName}}) Error() string {
-	return p.String()
-}
+{{- template "ExceptionError" .}}
+{{- end}}
+
+{{- if .ExceptionName}}
+{{template "ResultException" .}}
 {{- end}}
 
 {{- if Features.GenDeepEqual}}
@@ -138,6 +151,14 @@ func (p *{{$TypeName}}) Error() string {
 {{template "StructLikeDeepEqualField" .}}
 {{- end}}
 
+{{- if or Features.GenRequiredCheck Features.RequiredCheckShallow Features.RequiredCheckOnWrite}}
+{{template "StructLikeValidate" .}}
+{{- end}}
+
+{{- if Protocols}}
+{{template "StructLikeCodec" .}}
+{{- end}}
+
 {{InsertionPoint "ExtraFieldMap"}}
 {{- end}}{{/* define "StructLike" */}}
 	`
@@ -192,7 +213,7 @@ var fieldIDToName_{{$TypeName}} = map[int16]string{
 	extraStructText = `
 {{$ArgsType := .ArgType}}
 {{template "StructLike" $ArgsType}}
-{{- if not .Oneway}}
+{{- if .ResType}}
 	{{$ResType := .ResType}}	
 	{{template "StructLike" $ResType}}
 {{- end}}
@@ -19,15 +19,30 @@ var StructLike = `
 {{define "StructLike"}}
 {{- $TypeName := .GoName}}
 {{InsertionPoint .Category .Name}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if or .IsRecursive (Deprecation .)}}
+//{{end}}{{end}}
+{{- if .IsRecursive}}
+// {{$TypeName}} is a recursive type. The generated methods that visit nested values,
+// such as Read and Write, recurse once per level of nesting.
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
 type {{$TypeName}} struct {
 {{- range .Fields}}
 	{{- InsertionPoint $.Category $.Name .Name}}
 	{{- if and Features.ReserveComments .ReservedComments}}
 	{{.ReservedComments}}
-	{{- end}}
+	{{- if Deprecation .}}
+	//{{end}}{{end}}
+	{{- with Deprecation .}}
+	{{.}}{{end}}
 	{{(.GoName)}} {{.GoTypeName}} {{GenFieldTags . (InsertionPoint $.Category $.Name .Name "tag")}} 
 {{- end}}
+	{{- if .PresenceWords}}
+	_presence [{{.PresenceWords}}]uint64
+	{{- end}}
 	{{- if Features.KeepUnknownFields}}
 	{{- UseStdLibrary "unknown"}}
 	_unknownFields unknown.Fields
@@ -44,6 +59,7 @@ func init() {
 	meta.RegisterStruct(New{{$TypeName}}, {{Marshal .}})
 }
 {{- end}}{{/* if Features.GenerateTypeMeta */}}
+{{- template "FieldMeta" .}}
 
 func New{{$TypeName}}() *{{$TypeName}} {
 	return &{{$TypeName}}{
@@ -74,6 +90,9 @@ func (p *{{$TypeName}}) CountSetFields{{$TypeName}}() int {
 	return count
 }
 {{- end}}
+{{- if and (eq .Category "union") Features.UnionGetters}}
+{{template "UnionGetters" .}}
+{{- end}}
 
 {{if Features.KeepUnknownFields}}
 func (p *{{$TypeName}}) CarryingUnknownFields() bool {
@@ -122,6 +141,10 @@ var fieldIDToName_{{$TypeName}} = map[int16]string{
 
 {{template "StructLikeWriteField" .}}
 
+{{- if Features.GenByteSize}}
+{{template "StructLikeBytesLength" .}}
+{{- end}}
+
 func (p *{{$TypeName}}) String() string {
 	{{- if Features.JSONStringer}}
 	{{- UseStdLibrary "json_utils"}}
@@ -138,9 +161,11 @@ func (p *{{$TypeName}}) String() string {
 }
 
 {{- if eq .Category "exception"}}
-func (p *{{$TypeName}}) Error() string {
-	return p.String()
-}
+{{- template "ExceptionError" .}}
+{{- end}}
+
+{{- if .ExceptionName}}
+{{template "ResultException" .}}
 {{- end}}
 
 {{- if Features.GenDeepEqual}}
@@ -149,9 +174,134 @@ func (p *{{$TypeName}}) Error() string {
 {{template "StructLikeDeepEqualField" .}}
 {{- end}}
 
+{{- if Features.GenJSONMethods}}
+{{template "StructLikeJSON" .}}
+{{- else if .PresenceWords}}
+{{template "StructLikePresenceJSON" .}}
+{{- end}}
+
+{{- if or Features.GenRequiredCheck Features.RequiredCheckShallow Features.RequiredCheckOnWrite}}
+{{template "StructLikeValidate" .}}
+{{- end}}
+
+{{- if Protocols}}
+{{template "StructLikeCodec" .}}
+{{- end}}
+
 {{- end}}{{/* define "StructLike" */}}
 `
 
+// UnionGetters is the code template for the field IDs, the GetSetField method and
+// the constructors of unions with union_getters.
+var UnionGetters = `
+{{define "UnionGetters"}}
+{{- $TypeName := .GoName}}
+{{- $FieldID := .FieldIDName}}
+// {{$FieldID}} is the ID of a field of {{$TypeName}}.
+type {{$FieldID}} int16
+{{- if .Fields}}
+
+const (
+{{- range .Fields}}
+	{{.UnionFieldID}} {{$FieldID}} = {{.ID}}
+{{- end}}
+)
+{{- end}}
+
+// GetSetField returns the ID of the field that is set, the first one if more
+// than one are set, or 0 if none is set.
+func (p *{{$TypeName}}) GetSetField() {{$FieldID}} {
+	if p == nil {
+		return 0
+	}
+	{{- range .Fields}}
+	{{- if SupportIsSet .Field}}
+	if p.{{.IsSetter}}() {
+		return {{.UnionFieldID}}
+	}
+	{{- end}}
+	{{- end}}
+	return 0
+}
+{{- range .Fields}}
+{{- $FieldName := .GoName}}
+
+// {{.UnionNew}} returns a {{$TypeName}} with {{$FieldName}} set to v.
+{{- if .HasPresenceBit}}
+func {{.UnionNew}}(v {{.GoTypeName}}) *{{$TypeName}} {
+	p := &{{$TypeName}}{}
+	p.{{.Setter}}(v)
+	return p
+}
+{{- else if and (NeedRedirect .Field) (IsBaseType .Type)}}
+func {{.UnionNew}}(v {{.GoTypeName.Deref}}) *{{$TypeName}} {
+	return &{{$TypeName}}{ {{- $FieldName}}: &v}
+}
+{{- else}}
+func {{.UnionNew}}(v {{.GoTypeName}}) *{{$TypeName}} {
+	return &{{$TypeName}}{ {{- $FieldName}}: v}
+}
+{{- end}}
+{{- end}}{{/* range .Fields */}}
+{{- end}}{{/* define "UnionGetters" */}}
+`
+
+// FieldMeta is the code template for the variable that describes the fields of a
+// struct-like with gen_field_meta.
+var FieldMeta = `
+{{define "FieldMeta"}}
+{{- if Features.GenFieldMeta}}
+{{- UseStdLibrary "fieldmeta"}}
+
+var {{.FieldMetaName}} = []fieldmeta.FieldMeta{
+{{- range .Fields}}
+	{ID: {{.ID}}, Name: "{{.Name}}", GoName: "{{.GoName}}", Category: fieldmeta.{{.MetaCategory}},
+	{{- with .MetaKeyCategory}} KeyCategory: fieldmeta.{{.}},{{end}}
+	{{- with .MetaElemCategory}} ElemCategory: fieldmeta.{{.}},{{end}} Requiredness: fieldmeta.{{.Requiredness}}},
+{{- end}}
+}
+{{- end}}
+{{- end}}{{/* define "FieldMeta" */}}
+`
+
+// ExceptionError is the code template for the Error method of exceptions.
+var ExceptionError = `
+{{define "ExceptionError"}}
+{{- $TypeName := .GoName}}
+{{- with .ErrorMessage}}
+func (p *{{$TypeName}}) Error() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return "{{$TypeName}}: " + {{if and .Type.GetIsTypedef (IsDistinctTypedef .Type)}}string(p.{{.Getter}}()){{else}}p.{{.Getter}}(){{end}}
+}
+{{- else}}
+func (p *{{$TypeName}}) Error() string {
+	return p.String()
+}
+{{- end}}
+{{- end}}{{/* define "ExceptionError" */}}
+`
+
+// ResultException is the code template for the method of a result struct that
+// returns the exception declared in the throws clause of its method.
+var ResultException = `
+{{define "ResultException"}}
+{{- $TypeName := .GoName}}
+// {{.ExceptionName}} returns the exception set in the result as an error, or nil
+// if there is none. The exceptions are checked in the order of their field IDs.
+func (p *{{$TypeName}}) {{.ExceptionName}}() error {
+	switch {
+	{{- range .Exceptions}}
+	case p.{{.GoName}} != nil:
+		return p.{{.GoName}}
+	{{- end}}
+	}
+	return nil
+}
+{{- end}}{{/* define "ResultException" */}}
+`
+
 // StructLikeDefault is the code template for structure initialization.
 var StructLikeDefault = `
 {{- define "StructLikeDefault"}}
@@ -166,8 +316,15 @@ var StructLikeDefault = `
 var StructLikeRead = `
 {{define "StructLikeRead"}}
 {{- UseStdLibrary "thrift" "fmt"}}
+{{- if or Features.CtxRW ApacheRuntime}}{{UseStdLibrary "context"}}{{end}}
 {{- $TypeName := .GoName}}
-func (p *{{$TypeName}}) Read(iprot thrift.TProtocol) (err error) {
+{{- $FastRead := and Features.FastRead (not Features.KeepUnknownFields) (not Features.WithFieldMask) .HasFixedLayout}}
+func (p *{{$TypeName}}) Read({{if or Features.CtxRW ApacheRuntime}}ctx context.Context, {{end}}iprot thrift.TProtocol) (err error) {
+	{{- if Features.CtxRW}}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	{{- end}}
 	{{if Features.KeepUnknownFields}}var name string{{end}}
 	var fieldTypeId thrift.TType
 	var fieldId int16
@@ -177,31 +334,64 @@ func (p *{{$TypeName}}) Read(iprot thrift.TProtocol) (err error) {
 	{{- end}}
 	{{- end}}
 
-	if _, err = iprot.ReadStructBegin(); err != nil {
+	if _, err = iprot.ReadStructBegin({{ProtoCtx}}); err != nil {
 		goto ReadStructBeginError
 	}
 
+	{{- if $FastRead}}
+
+	// Fast path: the fields are expected in ascending ID order.
+	if _, fieldTypeId, fieldId, err = iprot.ReadFieldBegin({{ProtoCtx}}); err != nil {
+		goto ReadFieldBeginError
+	}
+	{{- range .Fields}}
+	{{- $ctx := MkRWCtx .}}
+	if fieldId != {{.ID}} || fieldTypeId != thrift.{{.Type | GetTypeIDConstant}} {
+		goto SlowPath
+	}
+	if v, e := iprot.Read{{$ctx.TypeID}}({{ProtoCtx}}); e != nil {
+		err = e
+		goto ReadFieldError
+	} else {
+		p.{{.GoName}} = {{if .Type.Category.IsEnum}}{{$ctx.TypeName}}(v){{else}}v{{end}}
+	}
+	{{- if .Requiredness.IsRequired}}
+	isset{{.GoName}} = true
+	{{- end}}
+	if err = iprot.ReadFieldEnd({{ProtoCtx}}); err != nil {
+		goto ReadFieldEndError
+	}
+	if _, fieldTypeId, fieldId, err = iprot.ReadFieldBegin({{ProtoCtx}}); err != nil {
+		goto ReadFieldBeginError
+	}
+	{{- end}}{{/* range .Fields */}}
+
+SlowPath:
+	for fieldTypeId != thrift.STOP {
+	{{- else}}
+
 	for {
-		{{if Features.KeepUnknownFields}}name{{else}}_{{end}}, fieldTypeId, fieldId, err = iprot.ReadFieldBegin()
+		{{if Features.KeepUnknownFields}}name{{else}}_{{end}}, fieldTypeId, fieldId, err = iprot.ReadFieldBegin({{ProtoCtx}})
 		if err != nil {
 		    goto ReadFieldBeginError
 		}
 		if fieldTypeId == thrift.STOP {
 			break;
 		}
+	{{- end}}{{/* if $FastRead */}}
 		{{if or (gt (len .Fields) 0) Features.KeepUnknownFields}}
 		switch fieldId {
 		{{- range .Fields}}
 		{{- $isBaseVal := .Type | IsBaseType}}
 		case {{.ID}}:
 			if fieldTypeId == thrift.{{.Type | GetTypeIDConstant }} {
-				if err = p.{{.Reader}}(iprot); err != nil {
+				if err = p.{{.Reader}}({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}iprot); err != nil {
 					goto ReadFieldError
 				}
 				{{- if .Requiredness.IsRequired}}
 				isset{{.GoName}} = true
 				{{- end}}
-			} else if err = iprot.Skip(fieldTypeId); err != nil {
+			} else if err = {{template "SkipField"}}; err != nil {
 				goto SkipFieldError
 			}
 		{{- end}}{{/* range .Fields */}}
@@ -209,15 +399,20 @@ func (p *{{$TypeName}}) Read(iprot thrift.TProtocol) (err error) {
 			{{- template "HandleUnknownFields"}}
 		}
 		{{- else -}}
-		if err = iprot.Skip(fieldTypeId); err != nil {
+		if err = {{template "SkipField"}}; err != nil {
 		    goto SkipFieldTypeError
 		}
 		{{- end}}{{/* if len(.Fields) > 0 */}}
-		if err = iprot.ReadFieldEnd(); err != nil {
+		if err = iprot.ReadFieldEnd({{ProtoCtx}}); err != nil {
 		  goto ReadFieldEndError
 		}
+		{{- if $FastRead}}
+		if _, fieldTypeId, fieldId, err = iprot.ReadFieldBegin({{ProtoCtx}}); err != nil {
+			goto ReadFieldBeginError
+		}
+		{{- end}}
 	}
-	if err = iprot.ReadStructEnd(); err != nil {
+	if err = iprot.ReadStructEnd({{ProtoCtx}}); err != nil {
 		goto ReadStructEndError
 	}
 	{{ $RequiredFieldNotSetError := false }}
@@ -230,6 +425,13 @@ func (p *{{$TypeName}}) Read(iprot thrift.TProtocol) (err error) {
 	}
 	{{- end}}
 	{{- end}}{{/* range .Fields */}}
+	{{- $CheckUnion := and (eq .Category "union") (or Features.CheckUnionOnWrite Features.CheckUnionOnRead Features.CheckUnionAllowZero) (gt (len .Fields) 1)}}
+	{{- if $CheckUnion}}
+	if c := p.CountSetFields{{$TypeName}}(); c > 1 {
+		err = fmt.Errorf("%T read union: at most one field can be set (%d set)", p, c)
+		goto CountSetFieldsError
+	}
+	{{- end}}
 	return nil
 ReadStructBeginError:
 	return thrift.PrependError(fmt.Sprintf("%T read struct begin error: ", p), err)
@@ -261,6 +463,10 @@ ReadStructEndError:
 RequiredFieldNotSetError:
 	return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("required field %s is not set", fieldIDToName_{{$TypeName}}[fieldId]))
 {{- end}}{{/* if $RequiredFieldNotSetError */}}
+{{- if $CheckUnion}}
+CountSetFieldsError:
+	return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, err)
+{{- end}}
 }
 {{- end}}{{/* define "StructLikeRead" */}}
 `
@@ -272,7 +478,7 @@ if err = p._unknownFields.Append(iprot, name, fieldTypeId, fieldId); err != nil
 	goto UnknownFieldsAppendError
 }
 {{- else}}
-if err = iprot.Skip(fieldTypeId); err != nil {
+if err = {{template "SkipField"}}; err != nil {
 	goto SkipFieldError
 }
 {{- end}}{{/* if Features.KeepUnknownFields */}}
@@ -287,7 +493,7 @@ var StructLikeReadField = `
 {{- range .Fields}}
 {{$FieldName := .GoName}}
 {{- $isBaseVal := .Type | IsBaseType -}}
-func (p *{{$TypeName}}) {{.Reader}}(iprot thrift.TProtocol) error {
+func (p *{{$TypeName}}) {{.Reader}}({{if or Features.CtxRW ApacheRuntime}}ctx context.Context, {{end}}iprot thrift.TProtocol) error {
 	{{- if Features.WithFieldMask}}
 	if {{if $isBaseVal}}_{{else}}fm{{end}}, ex := p._fieldmask.Field({{.ID}}); ex {
 	{{- end}}
@@ -297,8 +503,11 @@ func (p *{{$TypeName}}) {{.Reader}}(iprot thrift.TProtocol) error {
 	{{- template "FieldRead" $ctx}}
 	{{/* line break */}}
 	{{- $target}} = _field
+	{{- if .HasPresenceBit}}
+	p._presence[{{.PresenceWord}}] |= {{.PresenceMask}}
+	{{- end}}
 	{{- if Features.WithFieldMask}}
-	} else if err := iprot.Skip(thrift.{{.Type | GetTypeIDConstant}}); err != nil {
+	} else if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.Type | GetTypeIDConstant}}); err != nil {
 		return err
 	}
 	{{- end}}
@@ -308,27 +517,193 @@ func (p *{{$TypeName}}) {{.Reader}}(iprot thrift.TProtocol) error {
 {{- end}}{{/* define "StructLikeReadField" */}}
 `
 
+// WriteTo generates the WriteTo methods of all struct-likes in a file. The buffers
+// are pooled per file and reset before they are put back, so a failed
+// serialization leaves nothing behind for the next call. With gen_buffer_reuse,
+// the encoders of BufferReuse are used instead.
+var WriteTo = `
+{{define "WriteTo"}}
+{{- if .StructLikes}}
+{{- UseStdLibrary "thrift" "io"}}
+{{- if or Features.CtxRW ApacheRuntime}}{{UseStdLibrary "context"}}{{end}}
+{{- $Pool := printf "file_%s_thrift_buffer_pool" .IDLName}}
+{{- if Features.GenBufferReuse}}
+{{- $Pool = printf "file_%s_thrift_encoder_pool" .IDLName}}
+{{- else}}
+{{- UseStdLibrary "sync"}}
+var {{$Pool}} = sync.Pool{
+	New: func() interface{} {
+		return thrift.NewTMemoryBufferLen(1024)
+	},
+}
+{{- end}}
+{{- range .StructLikes}}
+
+// WriteTo serializes p with the binary protocol and writes the result to w.
+// The intermediate buffer is reused across calls.
+func (p *{{.GoName}}) WriteTo(w io.Writer) (n int64, err error) {
+	{{- if Features.GenBufferReuse}}
+	e := {{$Pool}}.Get().(*file_{{$.IDLName}}_thrift_encoder)
+	defer e.release()
+	if err = p.Write({{if or Features.CtxRW ApacheRuntime}}context.Background(), {{end}}e.proto); err != nil {
+		return 0, err
+	}
+	return e.buf.WriteTo(w)
+	{{- else}}
+	buf := {{$Pool}}.Get().(*thrift.TMemoryBuffer)
+	defer func() {
+		buf.Reset()
+		{{$Pool}}.Put(buf)
+	}()
+	if err = p.Write({{if or Features.CtxRW ApacheRuntime}}context.Background(), {{end}}thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+	{{- end}}
+}
+{{- end}}{{/* range .StructLikes */}}
+{{- end}}
+{{- end}}{{/* define "WriteTo" */}}
+`
+
+// BufferReuse generates the encoder pool of a file and the AppendBinary methods
+// of its struct-likes when gen_buffer_reuse is enabled. An encoder bundles a
+// buffer with a binary protocol writing to it, whose scratch space holds the
+// length prefixes and the numbers, so neither is allocated per call. An encoder
+// is owned by one goroutine between Get and Put.
+var BufferReuse = `
+{{define "BufferReuse"}}
+{{- if .StructLikes}}
+{{- UseStdLibrary "thrift" "sync"}}
+{{- if or Features.CtxRW ApacheRuntime}}{{UseStdLibrary "context"}}{{end}}
+{{- $Encoder := printf "file_%s_thrift_encoder" .IDLName}}
+{{- $Pool := printf "%s_pool" $Encoder}}
+type {{$Encoder}} struct {
+	buf   *thrift.TMemoryBuffer
+	proto *thrift.TBinaryProtocol
+}
+
+var {{$Pool}} = sync.Pool{
+	New: func() interface{} {
+		buf := thrift.NewTMemoryBufferLen(1024)
+		return &{{$Encoder}}{buf: buf, proto: thrift.NewTBinaryProtocolTransport(buf)}
+	},
+}
+
+// {{$Encoder}}_max_size caps the buffers kept in the pool, so that one large
+// message does not pin its memory for the lifetime of the pool.
+const {{$Encoder}}_max_size = 64 << 10
+
+// release resets the encoder and puts it back to the pool. The buffer is
+// reset even if a write fails, so nothing is left behind for the next call.
+// An encoder whose buffer has grown above the cap is dropped instead.
+func (e *{{$Encoder}}) release() {
+	if e.buf.Cap() > {{$Encoder}}_max_size {
+		return
+	}
+	e.buf.Reset()
+	{{$Pool}}.Put(e)
+}
+{{- range .StructLikes}}
+
+// AppendBinary appends p serialized with the binary protocol to b and returns
+// the extended slice. The encoder is reused across calls, so it allocates
+// nothing but the growth of b.
+func (p *{{.GoName}}) AppendBinary(b []byte) ([]byte, error) {
+	e := {{$Pool}}.Get().(*{{$Encoder}})
+	defer e.release()
+	if err := p.Write({{if or Features.CtxRW ApacheRuntime}}context.Background(), {{end}}e.proto); err != nil {
+		return b, err
+	}
+	return append(b, e.buf.Bytes()...), nil
+}
+{{- end}}{{/* range .StructLikes */}}
+{{- end}}
+{{- end}}{{/* define "BufferReuse" */}}
+`
+
+// FastSkip generates the function of a file that the Read methods skip the
+// fields with when fast_skip is enabled.
+var FastSkip = `
+{{define "FastSkip"}}
+{{- UseStdLibrary "thrift" "skip"}}
+{{- if ApacheRuntime}}{{UseStdLibrary "context"}}{{end}}
+// {{SkipFunc}} skips a value that Read does not keep. The binary protocol of
+// apache thrift reads from its transport as it is when the transport is a
+// thrift.TRichTransport, so the value is discarded from the transport without
+// decoding it. Other protocols skip the value by themselves.
+func {{SkipFunc}}({{if ApacheRuntime}}ctx context.Context, {{end}}iprot thrift.TProtocol, fieldType thrift.TType) error {
+	if p, ok := iprot.(*thrift.TBinaryProtocol); ok {
+		if t, ok := p.Transport().(thrift.TRichTransport); ok {
+			return skip.Binary(t, int(fieldType), skip.DefaultDepth)
+		}
+	}
+	return iprot.Skip({{ProtoCtxArg}}fieldType)
+}
+{{- end}}{{/* define "FastSkip" */}}
+`
+
+// SkipField is the call that skips the value of the field in Read.
+var SkipField = `
+{{define "SkipField"}}
+{{- if Features.FastSkip}}{{SkipFunc}}({{ProtoCtxArg}}iprot, fieldTypeId)
+{{- else}}iprot.Skip({{ProtoCtxArg}}fieldTypeId)
+{{- end}}
+{{- end}}{{/* define "SkipField" */}}
+`
+
+// Interface is the code template for the interfaces declared by go.implements.
+var Interface = `
+{{define "Interface"}}
+{{- $IfaceName := .GoName}}
+// {{$IfaceName}} is the set of getters shared by
+{{- range $i, $s := .Implementations}}{{if $i}},{{end}} {{$s.GoName}}{{end}}.
+type {{$IfaceName}} interface {
+	{{- range .Methods}}
+	{{.GoName}}() {{.TypeName}}
+	{{- end}}
+}
+
+var (
+	{{- range .Implementations}}
+	_ {{$IfaceName}} = (*{{.GoName}})(nil)
+	{{- end}}
+)
+{{- end}}{{/* define "Interface" */}}
+`
+
 // StructLikeWrite .
 var StructLikeWrite = `
 {{define "StructLikeWrite"}}
 {{- UseStdLibrary "thrift" "fmt"}}
+{{- if or Features.CtxRW ApacheRuntime}}{{UseStdLibrary "context"}}{{end}}
 {{- $TypeName := .GoName}}
-func (p *{{$TypeName}}) Write(oprot thrift.TProtocol) (err error) {
+func (p *{{$TypeName}}) Write({{if or Features.CtxRW ApacheRuntime}}ctx context.Context, {{end}}oprot thrift.TProtocol) (err error) {
+	{{- if Features.CtxRW}}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	{{- end}}
 	{{- if gt (len .Fields) 0 }}
 	var fieldId int16
 	{{- end}}
 	{{- if eq .Category "union"}}
 	var c int
-	if c = p.CountSetFields{{$TypeName}}(); c != 1 {
+	if c = p.CountSetFields{{$TypeName}}(); {{if Features.CheckUnionAllowZero}}c > 1{{else}}c != 1{{end}} {
 		goto CountSetFieldsError
 	}
 	{{- end}}
-	if err = oprot.WriteStructBegin("{{.Name}}"); err != nil {
+	{{- if Features.RequiredCheckOnWrite}}
+	if err = p.Validate(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write error: ", p), err)
+	}
+	{{- end}}
+	if err = oprot.WriteStructBegin({{ProtoCtxArg}}"{{.Name}}"); err != nil {
 		goto WriteStructBeginError
 	}
 	if p != nil {
 		{{- range .Fields}}
-		if err = p.{{.Writer}}(oprot); err != nil {
+		if err = p.{{.Writer}}({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}oprot); err != nil {
 			fieldId = {{.ID}}
 			goto WriteFieldError
 		}
@@ -340,16 +715,20 @@ func (p *{{$TypeName}}) Write(oprot thrift.TProtocol) (err error) {
 		}
 		{{- end}}
 	}
-	if err = oprot.WriteFieldStop(); err != nil {
+	if err = oprot.WriteFieldStop({{ProtoCtx}}); err != nil {
 		goto WriteFieldStopError
 	}
-	if err = oprot.WriteStructEnd(); err != nil {
+	if err = oprot.WriteStructEnd({{ProtoCtx}}); err != nil {
 		goto WriteStructEndError
 	}
 	return nil
 {{- if eq .Category "union"}}
 CountSetFieldsError:
+	{{- if Features.CheckUnionAllowZero}}
+	return fmt.Errorf("%T write union: at most one field can be set (%d set).", p, c)
+	{{- else}}
 	return fmt.Errorf("%T write union: exactly one field must be set (%d set).", p, c)
+	{{- end}}
 {{- end}}
 WriteStructBeginError:
 	return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -379,7 +758,7 @@ var StructLikeWriteField = `
 {{- $IsSetName := .IsSetter}}
 {{- $TypeID := .Type | GetTypeIDConstant }}
 {{- $isBaseVal := .Type | IsBaseType }}
-func (p *{{$TypeName}}) {{.Writer}}(oprot thrift.TProtocol) (err error) {
+func (p *{{$TypeName}}) {{.Writer}}({{if or Features.CtxRW ApacheRuntime}}ctx context.Context, {{end}}oprot thrift.TProtocol) (err error) {
 	{{- if .Requiredness.IsOptional}}
 	if p.{{$IsSetName}}() {
 	{{- end}}
@@ -392,22 +771,22 @@ func (p *{{$TypeName}}) {{.Writer}}(oprot thrift.TProtocol) (err error) {
 	if {{if $isBaseVal}}_{{else}}fm{{end}}, ex := p._fieldmask.Field({{.ID}}); ex { 
 	{{- end}}
 	{{- end}}
-	if err = oprot.WriteFieldBegin("{{.Name}}", thrift.{{$TypeID}}, {{.ID}}); err != nil {
+	if err = oprot.WriteFieldBegin({{ProtoCtxArg}}"{{.Name}}", thrift.{{$TypeID}}, {{.ID}}); err != nil {
 		goto WriteFieldBeginError
 	}
 	{{- $ctx := (MkRWCtx .).WithFieldMask "fm"}}
 	{{- template "FieldWrite" $ctx}}
-	if err = oprot.WriteFieldEnd(); err != nil {
+	if err = oprot.WriteFieldEnd({{ProtoCtx}}); err != nil {
 		goto WriteFieldEndError
 	}
 	{{- if Features.WithFieldMask}}
 	{{- if Features.FieldMaskZeroRequired}}
 	} else {
-		if err = oprot.WriteFieldBegin("{{.Name}}", thrift.{{$TypeID}}, {{.ID}}); err != nil {
+		if err = oprot.WriteFieldBegin({{ProtoCtxArg}}"{{.Name}}", thrift.{{$TypeID}}, {{.ID}}); err != nil {
 			goto WriteFieldBeginError
 		}
 		{{ ZeroWriter .Type "oprot" "WriteFieldBeginError" }}
-		if err = oprot.WriteFieldEnd(); err != nil {
+		if err = oprot.WriteFieldEnd({{ProtoCtx}}); err != nil {
 			goto WriteFieldEndError
 		}
 	}
@@ -428,6 +807,95 @@ WriteFieldEndError:
 {{- end}}{{/* define "StructLikeWriteField" */}}
 `
 
+// StructLikeBytesLength generates the BytesLength method of a struct-like when
+// gen_byte_size is enabled. It counts the same fields as Write.
+var StructLikeBytesLength = `
+{{define "StructLikeBytesLength"}}
+{{- $TypeName := .GoName}}
+// BytesLength returns the size of p serialized by Write with the binary
+// protocol.
+func (p *{{$TypeName}}) BytesLength() int {
+	l := 1 // field stop
+	if p == nil {
+		return l
+	}
+	{{- range .Fields}}
+	{{- if .Requiredness.IsOptional}}
+	if p.{{.IsSetter}}() {
+	{{- end}}
+	l += 3 // field begin
+	{{- template "FieldBytesLength" (MkRWCtx .)}}
+	{{- if .Requiredness.IsOptional}}
+	}
+	{{- end}}
+	{{- end}}{{/* range .Fields */}}
+	{{- if Features.KeepUnknownFields}}
+	l += len(p._unknownFields)
+	{{- end}}
+	return l
+}
+{{- end}}{{/* define "StructLikeBytesLength" */}}
+`
+
+// FieldBytesLength adds the size of a value with the binary protocol to l.
+// Containers of fixed-width elements are counted without iterating them.
+var FieldBytesLength = `
+{{define "FieldBytesLength"}}
+{{- $Size := BinaryFixedSize .Type}}
+{{- if gt $Size 0}}
+	l += {{$Size}}
+{{- else if .Type.Category.IsStructLike}}
+	l += {{.Target}}.BytesLength()
+{{- else if eq "Map" .TypeID}}
+	{{- $KeySize := BinaryFixedSize .KeyCtx.Type}}
+	{{- $ValSize := BinaryFixedSize .ValCtx.Type}}
+	l += 6 // key type, value type and size
+	{{- if and (gt $KeySize 0) (gt $ValSize 0)}}
+	l += len({{.Target}}) * ({{$KeySize}} + {{$ValSize}})
+	{{- else}}
+	{{- if gt $KeySize 0}}
+	l += len({{.Target}}) * {{$KeySize}}
+	{{- end}}
+	{{- if gt $ValSize 0}}
+	l += len({{.Target}}) * {{$ValSize}}
+	{{- end}}
+	{{- $Key := "k"}}
+	{{- $Val := "v"}}
+	{{- if .PairTypeName}}
+	{{- $Key = "kv.Key"}}
+	{{- $Val = "kv.Value"}}
+	for _, kv := range {{.Target}} {
+	{{- else if gt $KeySize 0}}
+	for _, v := range {{.Target}} {
+	{{- else if gt $ValSize 0}}
+	for k := range {{.Target}} {
+	{{- else}}
+	for k, v := range {{.Target}} {
+	{{- end}}
+		{{- if eq $KeySize 0}}
+		{{- template "FieldBytesLength" (.KeyCtx.WithTarget $Key)}}
+		{{- end}}
+		{{- if eq $ValSize 0}}
+		{{- template "FieldBytesLength" (.ValCtx.WithTarget $Val)}}
+		{{- end}}
+	}
+	{{- end}}
+{{- else if .Type.Category.IsContainerType}}
+	{{- $ValSize := BinaryFixedSize .ValCtx.Type}}
+	l += 5 // element type and size
+	{{- if gt $ValSize 0}}
+	l += len({{.Target}}) * {{$ValSize}}
+	{{- else}}
+	for _, v := range {{.Target}} {
+		{{- template "FieldBytesLength" (.ValCtx.WithTarget "v")}}
+	}
+	{{- end}}
+{{- else}}{{/* string or binary */}}
+	l += 4 + len({{if .IsPointer}}*{{end}}{{.Target}})
+{{- end}}
+{{- end}}{{/* define "FieldBytesLength" */}}
+`
+
 // FieldGetOrSet .
 var FieldGetOrSet = `
 {{define "FieldGetOrSet"}}
@@ -446,6 +914,11 @@ var {{$DefaultVarName}} {{$DefaultVarTypeName}}
 {{- if .Default}} = {{.DefaultValue}}{{- end}}
 
 func (p *{{$TypeName}}) {{$GetterName}}() (v {{$DefaultVarTypeName}}) {
+	{{- if Features.GenGetters}}
+	if p == nil || !p.{{$IsSetName}}() {
+		return {{$DefaultVarName}}
+	}
+	{{- else}}
 	{{- if Features.NilSafe}}
 	if p == nil {
 		return
@@ -454,7 +927,8 @@ func (p *{{$TypeName}}) {{$GetterName}}() (v {{$DefaultVarTypeName}}) {
 	if !p.{{$IsSetName}}() {
 		return {{$DefaultVarName}}
 	}
-	{{- if and (NeedRedirect .Field) (IsBaseType .Type)}}
+	{{- end}}
+	{{- if and (NeedRedirect .Field) (IsBaseType .Type) (not .HasPresenceBit)}}
 	return *p.{{$FieldName}}
 	{{- else}}
 	return p.{{$FieldName}}
@@ -464,11 +938,15 @@ func (p *{{$TypeName}}) {{$GetterName}}() (v {{$DefaultVarTypeName}}) {
 {{- else}}{{/*if SupportIsSet . */}}
 
 func (p *{{$TypeName}}) {{$GetterName}}() (v {{$FieldTypeName}}) {
-	{{- if Features.NilSafe}}
+	{{- if or Features.NilSafe Features.GenGetters}}
 	if p != nil {
 		return p.{{$FieldName}}
 	}
+	{{- if and Features.GenGetters .Default}}
+	return {{.DefaultValue}}
+	{{- else}}
 	return
+	{{- end}}
 	{{- else}}
 		return p.{{$FieldName}}
 	{{- end}}{{/* if Features.NilSafe */}}
@@ -477,6 +955,81 @@ func (p *{{$TypeName}}) {{$GetterName}}() (v {{$FieldTypeName}}) {
 {{- end}}{{/* if SupportIsSet . */}}
 {{- end}}{{/* range .Fields */}}
 
+{{- if Features.GenOptionalAccessors}}
+{{- range .Fields}}
+{{- if .GetterOrDefault}}
+{{- $FieldName := .GoName}}
+{{- $ValueTypeName := .GoTypeName.Deref}}
+
+func (p *{{$TypeName}}) {{.GetterOrDefault}}(def {{$ValueTypeName}}) {{$ValueTypeName}} {
+	if p == nil || !p.{{.IsSetter}}() {
+		return def
+	}
+	return {{if not .HasPresenceBit}}*{{end}}p.{{$FieldName}}
+}
+{{- if not .HasPresenceBit}}
+
+func (p *{{$TypeName}}) {{.Setter}}(v {{$ValueTypeName}}) {
+	p.{{$FieldName}} = &v
+}
+{{- end}}
+{{- end}}
+{{- end}}{{/* range .Fields */}}
+{{- end}}{{/* if Features.GenOptionalAccessors */}}
+
+{{- range .Fields}}
+{{- if .PairLookup}}
+{{- UseStdLibrary "reflect"}}
+{{- $FieldName := .GoName}}
+
+// {{.PairLookup}} returns the value of the first pair in {{$FieldName}} whose key
+// is equal to the given one by reflect.DeepEqual.
+func (p *{{$TypeName}}) {{.PairLookup}}(key {{.PairKeyTypeName}}) (value {{.PairValueTypeName}}, ok bool) {
+	if p == nil {
+		return value, false
+	}
+	for _, kv := range p.{{$FieldName}} {
+		if reflect.DeepEqual(kv.Key, key) {
+			return kv.Value, true
+		}
+	}
+	return value, false
+}
+
+// {{.PairPut}} sets the value of the first pair in {{$FieldName}} whose key is equal
+// to the given one by reflect.DeepEqual, or appends a new pair if there is none.
+func (p *{{$TypeName}}) {{.PairPut}}(key {{.PairKeyTypeName}}, value {{.PairValueTypeName}}) {
+	for i := range p.{{$FieldName}} {
+		if reflect.DeepEqual(p.{{$FieldName}}[i].Key, key) {
+			p.{{$FieldName}}[i].Value = value
+			return
+		}
+	}
+	p.{{$FieldName}} = append(p.{{$FieldName}}, {{.GoTypeName}}{ {Key: key, Value: value} }...)
+}
+{{- end}}
+{{- end}}{{/* range .Fields */}}
+
+{{- range .Fields}}
+{{- if .HasPresenceBit}}
+{{- $FieldName := .GoName}}
+{{- $Presence := printf "p._presence[%d]" .PresenceWord}}
+{{- if not Features.GenerateSetter}}
+
+func (p *{{$TypeName}}) {{.Setter}}(val {{.GoTypeName}}) {
+	p.{{$FieldName}} = val
+	{{$Presence}} |= {{.PresenceMask}}
+}
+{{- end}}
+
+func (p *{{$TypeName}}) {{.Clearer}}() {
+	var zero {{.GoTypeName}}
+	p.{{$FieldName}} = zero
+	{{$Presence}} &^= {{.PresenceMask}}
+}
+{{- end}}
+{{- end}}{{/* range .Fields */}}
+
 {{- if Features.GenerateSetter}}
 {{- range .Fields}}
 {{- $FieldName := .GoName}}
@@ -489,6 +1042,9 @@ func (p *{{$TypeName}}) {{$SetterName}}(x interface{}) {
 {{- else}}
 func (p *{{$TypeName}}) {{$SetterName}}(val {{$FieldTypeName}}) {
 	p.{{$FieldName}} = val
+	{{- if .HasPresenceBit}}
+	p._presence[{{.PresenceWord}}] |= {{.PresenceMask}}
+	{{- end}}
 }
 {{- end}}
 {{- end}}{{/* range .Fields */}}
@@ -508,7 +1064,9 @@ var FieldIsSet = `
 {{- $DefaultVarName := printf "%s_%s_%s" $TypeName $FieldName "DEFAULT"}}
 {{- if SupportIsSet .Field}}
 func (p *{{$TypeName}}) {{$IsSetName}}() bool {
-	{{- if .IsSetDefault}}
+	{{- if .HasPresenceBit}}
+	return p._presence[{{.PresenceWord}}]&({{.PresenceMask}}) != 0
+	{{- else if .IsSetDefault}}
 		{{- if IsBaseType .Type}}
 			{{- if .Type.Category.IsBinary}}
 				return string(p.{{$FieldName}}) != string({{$DefaultVarName}})
@@ -553,7 +1111,7 @@ var FieldReadStructLike = `
 	{{.Target}}.Set_FieldMask({{.FieldMask}})
 	{{- end}}
 	{{- end}}
-	if err := {{.Target}}.Read(iprot); err != nil {
+	if err := {{.Target}}.Read({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}iprot); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldReadStructLike" */}} 
@@ -562,11 +1120,11 @@ var FieldReadStructLike = `
 // FieldReadBaseType .
 var FieldReadBaseType = `
 {{define "FieldReadBaseType"}}
-	{{- $DiffType := or .Type.Category.IsEnum .Type.Category.IsBinary}}
+	{{- $DiffType := or .Type.Category.IsEnum .Type.Category.IsBinary .BaseType}}
 	{{- if .NeedDecl}}
 	var {{.Target}} {{.TypeName}}
 	{{- end}}
-	if v, err := iprot.Read{{.TypeID}}(); err != nil {
+	if v, err := iprot.Read{{.TypeID}}({{ProtoCtx}}); err != nil {
 		return err
 	} else {
 	{{- if .IsPointer}}
@@ -608,37 +1166,45 @@ var FieldReadMap = `
 {{- $isBaseVal := .ValCtx.Type | IsBaseType -}}
 {{- $curFieldMask := .FieldMask -}}
 {{- $isStructVal := .ValCtx.Type.Category.IsStructLike -}}
-	_, _, size, err := iprot.ReadMapBegin()
+	_, _, size, err := iprot.ReadMapBegin({{ProtoCtx}})
 	if err != nil {
 		return err
 	}
-	{{.Target}} {{if .NeedDecl}}:{{end}}= make({{.TypeName}}, size)
+	{{.Target}} {{if .NeedDecl}}:{{end}}= make({{.TypeName}}, {{if .PairTypeName}}0, {{end}}size)
 	{{- if $isStructVal}}
 	values := make([]{{.ValCtx.TypeName.Deref}}, size)
 	{{- end}}
 	for i := 0; i < size; i++ {
 		{{- $key := .GenID "_key"}}
+		{{- if .PairTypeName}}
+		{{- /* in a block to not conflict with the variables to read the value */}}
+		var {{$key}} {{.KeyCtx.TypeName}}
+		{
+			{{- template "FieldRead" (.KeyCtx.WithTarget $key)}}
+		}
+		{{- else}}
 		{{- $ctx := .KeyCtx.WithDecl.WithTarget $key}}
 		{{- template "FieldRead" $ctx}}
+		{{- end}}
 		{{- if Features.WithFieldMask}}
 		{{- $curFieldMask = "nfm"}}
 		{{- if $isIntKey}}
 		if {{if $isBaseVal}}_{{else}}{{$curFieldMask}}{{end}}, ex := {{.FieldMask}}.Int(int({{$key}})); !ex {
-			if err := iprot.Skip(thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
+			if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
 				return err
 			}
 			continue
 		} else {
 		{{- else if $isStrKey}}
 		if {{if $isBaseVal}}_{{else}}{{$curFieldMask}}{{end}}, ex := {{.FieldMask}}.Str(string({{$key}})); !ex {
-			if err := iprot.Skip(thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
+			if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
 				return err
 			}
 			continue
 		} else {
 		{{- else}}
 		if {{if $isBaseVal}}_{{else}}{{$curFieldMask}}{{end}}, ex := {{.FieldMask}}.Int(0); !ex {
-			if err := iprot.Skip(thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
+			if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
 				return err
 			}
 			continue
@@ -660,12 +1226,16 @@ var FieldReadMap = `
 			{{$val = printf "*%s" $val}}
 		{{end}}
 
+		{{- if .PairTypeName}}
+		{{.Target}} = append({{.Target}}, {{.PairTypeName}}{Key: {{$key}}, Value: {{$val}}})
+		{{- else}}
 		{{.Target}}[{{$key}}] = {{$val}}
+		{{- end}}
 		{{- if and Features.WithFieldMask}}
 		}
 		{{- end}}
 	}
-	if err := iprot.ReadMapEnd(); err != nil {
+	if err := iprot.ReadMapEnd({{ProtoCtx}}); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldReadMap" */}}
@@ -677,7 +1247,7 @@ var FieldReadSet = `
 {{- $isBaseVal := .ValCtx.Type | IsBaseType -}}
 {{- $curFieldMask := .FieldMask -}}
 {{- $isStructVal := .ValCtx.Type.Category.IsStructLike -}}
-	_, size, err := iprot.ReadSetBegin()
+	_, size, err := iprot.ReadSetBegin({{ProtoCtx}})
 	if err != nil {
 		return err
 	}
@@ -690,7 +1260,7 @@ var FieldReadSet = `
 		{{- if Features.WithFieldMask}}
 		{{- $curFieldMask = "nfm"}}
 		if {{if $isBaseVal}}_{{else}}{{$curFieldMask}}{{end}}, ex := {{.FieldMask}}.Int(i); !ex {
-			if err := iprot.Skip(thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
+			if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
 				return err
 			}
 			continue
@@ -714,7 +1284,7 @@ var FieldReadSet = `
 		}
 		{{- end}}
 	}
-	if err := iprot.ReadSetEnd(); err != nil {
+	if err := iprot.ReadSetEnd({{ProtoCtx}}); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldReadSet" */}}
@@ -726,7 +1296,7 @@ var FieldReadList = `
 {{- $isBaseVal := .ValCtx.Type | IsBaseType -}}
 {{- $curFieldMask := .FieldMask -}}
 {{- $isStructVal := .ValCtx.Type.Category.IsStructLike -}}
-	_, size, err := iprot.ReadListBegin()
+	_, size, err := iprot.ReadListBegin({{ProtoCtx}})
 	if err != nil {
 		return err
 	}
@@ -739,7 +1309,7 @@ var FieldReadList = `
 		{{- if Features.WithFieldMask}}
 		{{- $curFieldMask = "nfm"}}
 		if {{if $isBaseVal}}_{{else}}{{$curFieldMask}}{{end}}, ex := {{.FieldMask}}.Int(i); !ex {
-			if err := iprot.Skip(thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
+			if err := iprot.Skip({{ProtoCtxArg}}thrift.{{.ValCtx.Type | GetTypeIDConstant}}); err != nil {
 				return err
 			}
 			continue
@@ -763,7 +1333,7 @@ var FieldReadList = `
 		}
 		{{- end}}
 	}
-	if err := iprot.ReadListEnd(); err != nil {
+	if err := iprot.ReadListEnd({{ProtoCtx}}); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldReadList" */}}
@@ -792,7 +1362,7 @@ var FieldWriteStructLike = `
 	{{.Target}}.Set_FieldMask({{.FieldMask}})
 	{{- end}}
 	{{- end}}
-	if err := {{.Target}}.Write(oprot); err != nil {
+	if err := {{.Target}}.Write({{if or Features.CtxRW ApacheRuntime}}ctx, {{end}}oprot); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldWriteStructLike" */}}
@@ -803,9 +1373,10 @@ var FieldWriteBaseType = `
 {{define "FieldWriteBaseType"}}
 {{- $Value := .Target}}
 {{- if .IsPointer}}{{$Value = printf "*%s" $Value}}{{end}}
+{{- if .BaseType}}{{$Value = printf "%s(%s)" .BaseType $Value}}{{end}}
 {{- if .Type.Category.IsEnum}}{{$Value = printf "int32(%s)" $Value}}{{end}}
 {{- if .Type.Category.IsBinary}}{{$Value = printf "[]byte(%s)" $Value}}{{end}}
-	if err := oprot.Write{{.TypeID}}({{$Value}}); err != nil {
+	if err := oprot.Write{{.TypeID}}({{ProtoCtxArg}}{{$Value}}); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldWriteBaseType" */}}
@@ -845,14 +1416,14 @@ var FieldWriteMap = `
 			}
 			{{- end}}
 		}
-		if err := oprot.WriteMapBegin(thrift.
+		if err := oprot.WriteMapBegin({{ProtoCtxArg}}thrift.
 			{{- .KeyCtx.Type | GetTypeIDConstant -}}
 			, thrift.{{- .ValCtx.Type | GetTypeIDConstant -}}
 			, l); err != nil {
 			return err
 		}
 	} else {
-		if err := oprot.WriteMapBegin(thrift.
+		if err := oprot.WriteMapBegin({{ProtoCtxArg}}thrift.
 			{{- .KeyCtx.Type | GetTypeIDConstant -}}
 			, thrift.{{- .ValCtx.Type | GetTypeIDConstant -}}
 			, len({{.Target}})); err != nil {
@@ -860,14 +1431,19 @@ var FieldWriteMap = `
 		}
 	}
 	{{- else}}
-	if err := oprot.WriteMapBegin(thrift.
+	if err := oprot.WriteMapBegin({{ProtoCtxArg}}thrift.
 		{{- .KeyCtx.Type | GetTypeIDConstant -}}
 		, thrift.{{- .ValCtx.Type | GetTypeIDConstant -}}
 		, len({{.Target}})); err != nil {
 		return err
 	}
 	{{- end}}
+	{{- if .PairTypeName}}
+	for _, kv := range {{.Target}} {
+		k, v := kv.Key, kv.Value
+	{{- else}}
 	for k, v := range {{.Target}} {
+	{{- end}}
 		{{- if Features.WithFieldMask}}
 		{{- $curFieldMask = "nfm"}}
 		{{- if $isIntKey}}
@@ -893,7 +1469,7 @@ var FieldWriteMap = `
 		}
 		{{- end}}
 	}
-	if err := oprot.WriteMapEnd(); err != nil {
+	if err := oprot.WriteMapEnd({{ProtoCtx}}); err != nil {
 		return err
 	}
 {{- end}}{{/* define "FieldWriteMap" */}}
@@ -912,20 +1488,20 @@ var FieldWriteSet = `
 					l--
 				}
 			}
-			if err := oprot.WriteSetBegin(thrift.
+			if err := oprot.WriteSetBegin({{ProtoCtxArg}}thrift.
 			{{- .ValCtx.Type | GetTypeIDConstant -}}
 			, l); err != nil {
 				return err
 			}
 		} else {
-			if err := oprot.WriteSetBegin(thrift.
+			if err := oprot.WriteSetBegin({{ProtoCtxArg}}thrift.
 			{{- .ValCtx.Type | GetTypeIDConstant -}}
 			, len({{.Target}})); err != nil {
 				return err
 			}
 		}
 		{{- else}}
-		if err := oprot.WriteSetBegin(thrift.
+		if err := oprot.WriteSetBegin({{ProtoCtxArg}}thrift.
 		{{- .ValCtx.Type | GetTypeIDConstant -}}
 		, len({{.Target}})); err != nil {
 			return err
@@ -963,7 +1539,7 @@ var FieldWriteSet = `
 			}
 			{{- end}}
 		}
-		if err := oprot.WriteSetEnd(); err != nil {
+		if err := oprot.WriteSetEnd({{ProtoCtx}}); err != nil {
 			return err
 		}
 {{- end}}{{/* define "FieldWriteSet" */}}
@@ -982,20 +1558,20 @@ var FieldWriteList = `
 				l--
 			}
 		}
-		if err := oprot.WriteListBegin(thrift.
+		if err := oprot.WriteListBegin({{ProtoCtxArg}}thrift.
 		{{- .ValCtx.Type | GetTypeIDConstant -}}
 		, l); err != nil {
 			return err
 		}
 	} else {
-		if err := oprot.WriteListBegin(thrift.
+		if err := oprot.WriteListBegin({{ProtoCtxArg}}thrift.
 		{{- .ValCtx.Type | GetTypeIDConstant -}}
 		, len({{.Target}})); err != nil {
 			return err
 		}
 	}
 	{{- else}}
-	if err := oprot.WriteListBegin(thrift.
+	if err := oprot.WriteListBegin({{ProtoCtxArg}}thrift.
 	{{- .ValCtx.Type | GetTypeIDConstant -}}
 	, len({{.Target}})); err != nil {
 		return err
@@ -1014,7 +1590,7 @@ var FieldWriteList = `
 			}
 			{{- end}}
 		}
-		if err := oprot.WriteListEnd(); err != nil {
+		if err := oprot.WriteListEnd({{ProtoCtx}}); err != nil {
 			return err
 		}
 {{- end}}{{/* define "FieldWriteList" */}}
@@ -19,11 +19,15 @@ var Typedef = `
 {{define "Typedef"}}
 {{- $NewTypeName := .GoName}}
 {{- $OldTypeName := .GoTypeName}}
-{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}{{end}}
-{{- if Features.TypedefAsTypeAlias }}
-type {{$NewTypeName}} = {{$OldTypeName}}
-{{- else}}
+{{- if and Features.ReserveComments .ReservedComments}}{{.ReservedComments}}
+{{- if Deprecation .}}
+//{{end}}{{end}}
+{{- with Deprecation .}}
+{{.}}{{end}}
+{{- if IsDistinctTypedef .Type}}
 type {{$NewTypeName}} {{$OldTypeName}}
+{{- else}}
+type {{$NewTypeName}} = {{$OldTypeName}}
 {{- end}}
 
 {{if .Type.Category.IsStructLike}} 
@@ -0,0 +1,69 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+// StructLikeValidate .
+var StructLikeValidate = `
+{{define "StructLikeValidate"}}
+{{- $TypeName := .GoName}}
+{{- $Recursive := not Features.RequiredCheckShallow}}
+// Validate returns an error naming the path of the first required field that
+// is not set{{if $Recursive}}, checking the fields of the nested structs too{{end}}.
+func (p *{{$TypeName}}) Validate() error {
+	if p == nil {
+		return nil
+	}
+	{{- range .Fields}}
+	{{- $ctx := MkRWCtx .}}
+	{{- $Nilable := or $ctx.IsPointer .Type.Category.IsContainerType .Type.Category.IsBinary}}
+	{{- if and .Requiredness.IsRequired $Nilable}}
+	{{- UseStdLibrary "fmt"}}
+	if p.{{.GoName}} == nil {
+		return fmt.Errorf("%s: required field is not set", "{{.Name}}")
+	}
+	{{- end}}
+	{{- if and $Recursive .Type.Category.IsStructLike}}
+	{{- UseStdLibrary "fmt"}}
+	if err := p.{{.GoName}}.Validate(); err != nil {
+		return fmt.Errorf("{{.Name}}.{{ErrWrapVerb}}", err)
+	}
+	{{- else if and $Recursive .Type.Category.IsContainerType $ctx.ValCtx.Type.Category.IsStructLike}}
+	{{- UseStdLibrary "fmt"}}
+	{{- /* only the structs that are the values of the container, not those in nested containers */}}
+	{{- if $ctx.PairTypeName}}
+	for _, kv := range p.{{.GoName}} {
+		if err := kv.Value.Validate(); err != nil {
+			return fmt.Errorf("{{.Name}}[%v].{{ErrWrapVerb}}", kv.Key, err)
+		}
+	}
+	{{- else if eq "Map" $ctx.TypeID}}
+	for k, v := range p.{{.GoName}} {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("{{.Name}}[%v].{{ErrWrapVerb}}", k, err)
+		}
+	}
+	{{- else}}
+	for i, v := range p.{{.GoName}} {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("{{.Name}}[%d].{{ErrWrapVerb}}", i, err)
+		}
+	}
+	{{- end}}
+	{{- end}}
+	{{- end}}{{/* range .Fields */}}
+	return nil
+}
+{{- end}}{{/* define "StructLikeValidate" */}}
+`
@@ -143,6 +143,22 @@ func IsFixedLengthType(t *parser.Type) bool {
 	return parser.Category_Bool <= t.Category && t.Category <= parser.Category_Double
 }
 
+// BinaryFixedSize returns the size of a value of the type encoded with the
+// binary protocol, or 0 if the size depends on the value.
+func BinaryFixedSize(t *parser.Type) int {
+	switch t.Category {
+	case parser.Category_Bool, parser.Category_Byte:
+		return 1
+	case parser.Category_I16:
+		return 2
+	case parser.Category_I32, parser.Category_Enum:
+		return 4
+	case parser.Category_I64, parser.Category_Double:
+		return 8
+	}
+	return 0
+}
+
 // SupportIsSet determines whether a field supports IsSet query.
 func SupportIsSet(f *parser.Field) bool {
 	return f.Type.Category.IsStructLike() || f.Requiredness.IsOptional()
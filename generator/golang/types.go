@@ -78,6 +78,18 @@ var baseTypes = map[string]string{
 	"binary": "[]byte",
 }
 
+// category2BaseType maps the categories of base types to the go types that
+// thrift.TProtocol reads and writes, except enums and binaries.
+var category2BaseType = map[parser.Category]string{
+	parser.Category_Bool:   "bool",
+	parser.Category_Byte:   "int8",
+	parser.Category_I16:    "int16",
+	parser.Category_I32:    "int32",
+	parser.Category_I64:    "int64",
+	parser.Category_Double: "float64",
+	parser.Category_String: "string",
+}
+
 var isContainerTypes = map[string]bool{"map": true, "set": true, "list": true}
 
 var isKeywords = map[string]bool{
@@ -16,12 +16,20 @@ package golang
 
 import (
 	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+	"unicode"
 
 	"golang.org/x/text/language"
 
@@ -33,6 +41,7 @@ import (
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/plugin"
 	"github.com/cloudwego/thriftgo/semantic"
+	"github.com/cloudwego/thriftgo/utils"
 
 	"golang.org/x/text/cases"
 )
@@ -42,10 +51,18 @@ const (
 	DefaultThriftLib    = "github.com/apache/thrift/lib/go/thrift"
 	DefaultUnknownLib   = "github.com/cloudwego/thriftgo/generator/golang/extension/unknown"
 	DefaultMetaLib      = "github.com/cloudwego/thriftgo/generator/golang/extension/meta"
+	DefaultFieldMetaLib = "github.com/cloudwego/thriftgo/generator/golang/extension/fieldmeta"
+	DefaultSkipLib      = "github.com/cloudwego/thriftgo/generator/golang/extension/skip"
+	DefaultCodecLib     = "github.com/cloudwego/thriftgo/generator/golang/extension/codec"
 	ThriftReflectionLib = "github.com/cloudwego/thriftgo/thrift_reflection"
 	ThriftFieldMaskLib  = "github.com/cloudwego/thriftgo/fieldmask"
 	ThriftOptionLib     = "github.com/cloudwego/thriftgo/extension/thrift_option"
 	defaultTemplate     = "default"
+	defaultRuntime      = "cloudwego"
+	apacheRuntime       = "apache"
+	defaultGoVersion    = "1.13"
+	pointerPresence     = "pointer"
+	bitsetPresence      = "bitset"
 	ThriftJSONUtilLib   = "github.com/cloudwego/thriftgo/utils/json_utils"
 	KitexStreamingLib   = "github.com/cloudwego/kitex/pkg/streaming"
 )
@@ -55,14 +72,35 @@ var escape = regexp.MustCompile(`\\.`)
 // CodeUtils contains a set of utility functions.
 type CodeUtils struct {
 	backend.LogFunc
-	packagePrefix string            // Package prefix for all generated codes.
-	importReplace map[string]string // Customized imports, import path => replacement.
-	features      Features          // Available features.
-	namingStyle   styles.Naming     // Naming style.
-	doInitialisms bool              // Make initialisms setting kept event naming style changes.
+	packagePrefix  string             // Package prefix for all generated codes.
+	importReplace  map[string]string  // Customized imports, import path => replacement.
+	importPrefixes map[string]string  // Rewritten imports, import path prefix => replacement.
+	importAlias    map[string]string  // Pinned import aliases, go namespace => alias.
+	packageNames   map[string]string  // Pinned package names, go namespace => name.
+	importDirs     map[string]string  // Directories of the packages under the output location, go namespace => directory.
+	onlyServices   []string           // Services to generate. Empty for all.
+	profile        string             // The profile to generate. Empty for all declarations.
+	protocols      []string           // Protocols to generate the Append and Read methods for. Empty for none.
+	excludes       []string           // Glob patterns of the includes not to generate in recursive mode.
+	fileHeader     *template.Template // Header prepended to each generated file. Nil for none.
+	nsFallback     *template.Template // Go namespace of the IDLs without one. Nil for their file names.
+	templateDir    string             // Directory of the templates overriding the defaults.
+	runtime        string             // Runtime the generated codes work with. Empty for the default one.
+	goVersion      int                // Minor version of the go toolchain the generated codes target.
+	presence       string             // Representation of the presence of optional scalar fields.
+	deprecation    string             // Key of the annotation that marks deprecated definitions. Empty for none.
+	deprecatedAs   *string            // Value of the deprecation annotation that marks deprecated definitions. Nil for any value.
+	features       Features           // Available features.
+	namingStyle    styles.Naming      // Naming style.
+	doInitialisms  bool               // Make initialisms setting kept event naming style changes.
+	initialisms    []string           // Initialisms added by users.
+	plainStyle     styles.Naming      // Naming style without the initialisms added by users.
+	reserved       map[string]bool    // Go names that are escaped with the reserved suffix.
+	reservedSuffix string             // Suffix to escape the reserved go names with. Empty for "_".
 
 	rootScope   *Scope
 	scopeCache  map[*parser.Thrift]*Scope
+	idlNames    map[string]bool // IDL names taken in each package, see Scope.IDLName
 	useTemplate string
 	alternative map[string][]string
 }
@@ -70,13 +108,19 @@ type CodeUtils struct {
 // NewCodeUtils creates a new CodeUtils.
 func NewCodeUtils(log backend.LogFunc) *CodeUtils {
 	cu := &CodeUtils{
-		LogFunc:       log,
-		importReplace: make(map[string]string),
-		features:      defaultFeatures,
-		namingStyle:   styles.NewNamingStyle("thriftgo"),
-		scopeCache:    make(map[*parser.Thrift]*Scope),
-		useTemplate:   defaultTemplate,
-		alternative:   templates.Alternative(),
+		LogFunc:        log,
+		importReplace:  make(map[string]string),
+		importPrefixes: make(map[string]string),
+		importAlias:    make(map[string]string),
+		packageNames:   make(map[string]string),
+		goVersion:      mustParseGoVersion(defaultGoVersion),
+		features:       defaultFeatures,
+		namingStyle:    styles.NewNamingStyle("thriftgo"),
+		plainStyle:     styles.NewNamingStyle("thriftgo"),
+		scopeCache:     make(map[*parser.Thrift]*Scope),
+		idlNames:       make(map[string]bool),
+		useTemplate:    defaultTemplate,
+		alternative:    templates.Alternative(),
 	}
 	return cu
 }
@@ -107,11 +151,489 @@ func (cu *CodeUtils) SetPackagePrefix(pp string) {
 	cu.packagePrefix = pp
 }
 
+// SetImportDir makes the import path of the package of the go namespace the
+// directory, relative to the output location with '/' as the separator, under
+// the package prefix, instead of the path of the namespace.
+func (cu *CodeUtils) SetImportDir(ns, dir string) {
+	if cu.importDirs == nil {
+		cu.importDirs = make(map[string]string)
+	}
+	cu.importDirs[ns] = dir
+}
+
 // UsePackage forces the generated codes to use the specific package.
 func (cu *CodeUtils) UsePackage(path, repl string) {
 	cu.importReplace[path] = repl
 }
 
+// ReplaceImportPrefix rewrites the import path prefix, and the import paths
+// under it, to start with repl instead.
+func (cu *CodeUtils) ReplaceImportPrefix(prefix, repl string) {
+	cu.importPrefixes[prefix] = repl
+}
+
+// SetImportAlias pins the alias used when importing the package of the given go namespace.
+func (cu *CodeUtils) SetImportAlias(ns, alias string) error {
+	if !isImportAlias(alias) {
+		return fmt.Errorf("invalid import alias %q for namespace %q", alias, ns)
+	}
+	if old, ok := cu.importAlias[ns]; ok && old != alias {
+		return fmt.Errorf("conflicting import aliases for namespace %q: %q and %q", ns, old, alias)
+	}
+	cu.importAlias[ns] = alias
+	return nil
+}
+
+// isImportAlias reports whether the name can be the alias of an import, which
+// must be a go identifier that is neither a keyword nor a predeclared name.
+func isImportAlias(name string) bool {
+	return token.IsIdentifier(name) && name != "_" && types.Universe.Lookup(name) == nil
+}
+
+// GetImportAlias returns the pinned import alias for the given IDL. The alias specified
+// by the import_alias option takes precedence over the 'go.import.alias' annotation on
+// the go namespace of the IDL. An empty string is returned if no alias is pinned.
+func (cu *CodeUtils) GetImportAlias(ast *parser.Thrift) string {
+	if alias, ok := cu.importAlias[cu.GoNamespace(ast)]; ok {
+		return alias
+	}
+	for _, ns := range ast.Namespaces {
+		if ns.Language == "go" {
+			if vs := ns.Annotations.Get(importAliasAnnotation); len(vs) > 0 {
+				return vs[0]
+			}
+		}
+	}
+	return ""
+}
+
+// SetPackageName pins the name in the package clause of the files generated for the
+// given go namespace, which is the last element of its import path by default.
+func (cu *CodeUtils) SetPackageName(ns, name string) error {
+	if !token.IsIdentifier(name) || name == "_" {
+		return fmt.Errorf("invalid package name %q for namespace %q", name, ns)
+	}
+	if old, ok := cu.packageNames[ns]; ok && old != name {
+		return fmt.Errorf("conflicting package names for namespace %q: %q and %q", ns, old, name)
+	}
+	cu.packageNames[ns] = name
+	return nil
+}
+
+// collectPackageNames pins the package names set by the 'go.package_name' annotations
+// on the go namespaces of the IDLs. The names set by the package_name option take
+// precedence. The IDLs of a namespace must not set different names.
+func (cu *CodeUtils) collectPackageNames(ast *parser.Thrift) error {
+	annotated := make(map[string]string) // go namespace => the IDL that sets the name
+	for t := range ast.DepthFirstSearch() {
+		for _, ns := range t.Namespaces {
+			if ns.Language != "go" {
+				continue
+			}
+			vs := ns.Annotations.Get(packageNameAnnotation)
+			if len(vs) == 0 {
+				continue
+			}
+			if len(vs) > 1 {
+				return fmt.Errorf("%s: %s is set more than once", t.Filename, packageNameAnnotation)
+			}
+			if !token.IsIdentifier(vs[0]) || vs[0] == "_" {
+				return fmt.Errorf("%s: invalid %s %q, it must be a go identifier", t.Filename, packageNameAnnotation, vs[0])
+			}
+			name := cu.GoNamespace(t)
+			if old, ok := cu.packageNames[name]; ok {
+				if from, ok := annotated[name]; ok && old != vs[0] {
+					return fmt.Errorf("%s: %s %q conflicts with %q in %s", t.Filename, packageNameAnnotation, vs[0], old, from)
+				}
+				continue
+			}
+			cu.packageNames[name] = vs[0]
+			annotated[name] = t.Filename
+		}
+	}
+	return nil
+}
+
+// PinnedPackageName returns the package name pinned for the go namespace of the
+// IDL, or an empty string if there is none.
+func (cu *CodeUtils) PinnedPackageName(ast *parser.Thrift) string {
+	return cu.packageNames[cu.GoNamespace(ast)]
+}
+
+// uniqueIDLName reserves an IDL name in the package of the import path and
+// returns it, suffixed with _1, _2, etc. if the package already has it.
+func (cu *CodeUtils) uniqueIDLName(importPath, name string) string {
+	unique := name
+	for i := 1; cu.idlNames[importPath+"/"+unique]; i++ {
+		unique = name + "_" + strconv.Itoa(i)
+	}
+	cu.idlNames[importPath+"/"+unique] = true
+	return unique
+}
+
+// SetFileHeader loads the template of the header that is prepended to every generated file.
+func (cu *CodeUtils) SetFileHeader(path string) error {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("file_header: %w", err)
+	}
+	tpl, err := template.New("header").Option("missingkey=error").Parse(string(bs))
+	if err != nil {
+		return fmt.Errorf("file_header: %s: %w", path, err)
+	}
+	cu.fileHeader = tpl
+	return nil
+}
+
+// namespaceFallbackData is the data of the namespace_fallback template.
+type namespaceFallbackData struct {
+	FileBase string // Base name of the IDL without the extension, e.g. "user_service".
+	FileName string // Base name of the IDL, e.g. "user_service.thrift".
+	Dir      string // Directory of the IDL with '/' as the separator, e.g. "idl/user".
+	DirBase  string // Last element of the directory, e.g. "user".
+}
+
+func newNamespaceFallbackData(filename string) *namespaceFallbackData {
+	base := filepath.Base(filename)
+	data := &namespaceFallbackData{
+		FileBase: strings.TrimSuffix(base, filepath.Ext(base)),
+		FileName: base,
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		data.Dir, data.DirBase = filepath.ToSlash(dir), filepath.Base(dir)
+	}
+	return data
+}
+
+// SetNamespaceFallback parses the template that computes the go namespace of
+// the IDLs without one, e.g. 'acme.{{.FileBase}}'.
+func (cu *CodeUtils) SetNamespaceFallback(text string) error {
+	tpl, err := template.New("namespace_fallback").Funcs(template.FuncMap{
+		"lower":   strings.ToLower,
+		"replace": strings.ReplaceAll,
+	}).Parse(text)
+	if err == nil {
+		// catch references to unknown fields before generating anything
+		err = tpl.Execute(ioutil.Discard, newNamespaceFallbackData("idl/example.thrift"))
+	}
+	if err != nil {
+		return fmt.Errorf("namespace_fallback: %w", err)
+	}
+	cu.nsFallback = tpl
+	return nil
+}
+
+// GoNamespace returns the go namespace of the IDL. The namespace declared for
+// go or for all languages takes precedence. Otherwise, the namespace_fallback
+// template computes it if set, or the file name of the IDL is used.
+func (cu *CodeUtils) GoNamespace(ast *parser.Thrift) string {
+	if ns, ok := ast.GetNamespace("go"); ok {
+		return ns
+	}
+	if cu.nsFallback == nil {
+		return ast.GetNamespaceOrReferenceName("go")
+	}
+	var buf strings.Builder
+	err := cu.nsFallback.Execute(&buf, newNamespaceFallbackData(ast.Filename))
+	// drop the empty parts, e.g. from an empty .DirBase
+	var parts []string
+	for _, p := range strings.Split(strings.TrimSpace(buf.String()), ".") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	ns := strings.Join(parts, ".")
+	if err != nil || ns == "" {
+		cu.Warn(fmt.Sprintf("namespace_fallback: no namespace for %q, use its file name: %v", ast.Filename, err))
+		return ast.GetNamespaceOrReferenceName("go")
+	}
+	return ns
+}
+
+// SetTemplateDir sets the directory to load override templates from.
+func (cu *CodeUtils) SetTemplateDir(dir string) error {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("template_dir: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("template_dir: %q is not a directory", dir)
+	}
+	cu.templateDir = dir
+	return nil
+}
+
+// TemplateDir returns the directory of override templates. Empty for none.
+func (cu *CodeUtils) TemplateDir() string {
+	return cu.templateDir
+}
+
+// IsDistinctTypedef reports whether a typedef of the given type is generated as a
+// distinct type instead of a type alias.
+func (cu *CodeUtils) IsDistinctTypedef(t *parser.Type) bool {
+	if cu.features.TypedefAsType {
+		return !t.Category.IsStructLike()
+	}
+	return !cu.features.TypedefAsTypeAlias
+}
+
+// SetRuntime sets the runtime that the generated codes work with. Only one
+// runtime can be used in a run.
+func (cu *CodeUtils) SetRuntime(value string) error {
+	if value != defaultRuntime && value != apacheRuntime {
+		return fmt.Errorf("runtime: unknown runtime %q, expect %q or %q", value, defaultRuntime, apacheRuntime)
+	}
+	if cu.runtime != "" && cu.runtime != value {
+		return fmt.Errorf("runtime: %q conflicts with %q, only one runtime can be used", value, cu.runtime)
+	}
+	cu.runtime = value
+	return nil
+}
+
+// Runtime returns the runtime that the generated codes work with.
+func (cu *CodeUtils) Runtime() string {
+	if cu.runtime == "" {
+		return defaultRuntime
+	}
+	return cu.runtime
+}
+
+// SetPresence sets the representation of the presence of optional scalar fields.
+func (cu *CodeUtils) SetPresence(value string) error {
+	if value != pointerPresence && value != bitsetPresence {
+		return fmt.Errorf("gen_presence: unknown representation %q, expect %q or %q", value, pointerPresence, bitsetPresence)
+	}
+	cu.presence = value
+	return nil
+}
+
+// PresenceBitset reports whether the presence of optional scalar fields is
+// stored in a bitset of each struct-like instead of pointers.
+func (cu *CodeUtils) PresenceBitset() bool {
+	return cu.presence == bitsetPresence
+}
+
+// SetDeprecationAnnotation sets the annotation that marks the definitions as
+// deprecated, in the form of 'key' for any value of the key, or 'key=value'
+// for the given value only.
+func (cu *CodeUtils) SetDeprecationAnnotation(spec string) error {
+	key, value := spec, (*string)(nil)
+	if i := strings.Index(spec, "="); i >= 0 {
+		key, value = spec[:i], new(string)
+		*value = spec[i+1:]
+	}
+	if key = strings.TrimSpace(key); key == "" {
+		return fmt.Errorf("deprecated_annotation: empty annotation key")
+	}
+	cu.deprecation, cu.deprecatedAs = key, value
+	return nil
+}
+
+// annotatedNode is a definition in the IDL that can be marked as deprecated.
+type annotatedNode interface {
+	GetAnnotations() parser.Annotations
+}
+
+// Deprecation returns the '// Deprecated:' comment for the definition, whose
+// message is the value of the deprecation annotation unless the annotation is
+// matched by its value. It returns an empty string if the definition is not
+// deprecated.
+func (cu *CodeUtils) Deprecation(node annotatedNode) string {
+	if cu.deprecation == "" {
+		return ""
+	}
+	annos := node.GetAnnotations()
+	vs := annos.Get(cu.deprecation)
+	if len(vs) == 0 {
+		return ""
+	}
+	msg := strings.TrimSpace(vs[0])
+	if cu.deprecatedAs != nil {
+		// the value only marks the definition, it is not a message
+		if !matchAnnotation(vs, *cu.deprecatedAs) {
+			return ""
+		}
+		msg = ""
+	}
+	if msg == "" {
+		msg = "do not use."
+	}
+	return "// Deprecated: " + strings.ReplaceAll(msg, "\n", "\n// ")
+}
+
+func matchAnnotation(values []string, value string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) == strings.TrimSpace(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGoVersion sets the version of the go toolchain that the generated codes
+// target, in the form of '1.N', 'go1.N' or '1.N.P'.
+func (cu *CodeUtils) SetGoVersion(value string) error {
+	minor, err := parseGoVersion(value)
+	if err != nil {
+		return fmt.Errorf("go_version: %w", err)
+	}
+	cu.goVersion = minor
+	return nil
+}
+
+// GoVersion returns the version of the go toolchain that the generated codes target.
+func (cu *CodeUtils) GoVersion() string {
+	return "1." + strconv.Itoa(cu.goVersion)
+}
+
+// GoVersionAtLeast reports whether the generated codes target the given go version
+// or a later one, so the language features and APIs introduced in it can be used.
+func (cu *CodeUtils) GoVersionAtLeast(version string) (bool, error) {
+	minor, err := parseGoVersion(version)
+	if err != nil {
+		return false, err
+	}
+	return cu.goVersion >= minor, nil
+}
+
+// ErrWrapVerb returns the verb of fmt.Errorf to wrap an error with, which is %w
+// since go 1.13 and %v before it.
+func (cu *CodeUtils) ErrWrapVerb() string {
+	if cu.goVersion >= 13 {
+		return "%w"
+	}
+	return "%v"
+}
+
+// parseGoVersion returns the minor version of a go 1 version string.
+func parseGoVersion(value string) (int, error) {
+	parts := strings.Split(strings.TrimPrefix(value, "go"), ".")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "1" {
+		return 0, fmt.Errorf("invalid go version %q, expect the form of '1.N'", value)
+	}
+	for _, p := range parts[1:] {
+		if _, err := strconv.ParseUint(p, 10, 16); err != nil {
+			return 0, fmt.Errorf("invalid go version %q, expect the form of '1.N'", value)
+		}
+	}
+	minor, _ := strconv.Atoi(parts[1])
+	return minor, nil
+}
+
+func mustParseGoVersion(value string) int {
+	minor, err := parseGoVersion(value)
+	if err != nil {
+		panic(err)
+	}
+	return minor
+}
+
+// FileHeader renders the file header for a file generated from the given IDL.
+// The template can refer to .IDL, .Version and .Date, see sourceDate. An empty
+// string is returned if no header is set.
+func (cu *CodeUtils) FileHeader(idl, version string) (string, error) {
+	if cu.fileHeader == nil {
+		return "", nil
+	}
+	date, err := sourceDate()
+	if err != nil {
+		return "", fmt.Errorf("file_header: %w", err)
+	}
+	var buf strings.Builder
+	err = cu.fileHeader.Execute(&buf, map[string]string{
+		"IDL":     idl,
+		"Version": version,
+		"Date":    date.Format("2006-01-02"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("file_header: %w", err)
+	}
+	header := strings.TrimRight(buf.String(), "\n") + "\n"
+	// the header must consist of comments only to sit above the package clause
+	if _, err = goparser.ParseFile(token.NewFileSet(), "", header+"package p\n", goparser.PackageClauseOnly); err != nil {
+		return "", fmt.Errorf("file_header: the rendered header must only contain Go comments: %w", err)
+	}
+	return header + "\n", nil
+}
+
+// sourceDate returns the date of the generated files. It is taken from the
+// SOURCE_DATE_EPOCH environment variable when it is set, so that the outputs
+// can be reproduced, and is the current date otherwise.
+func sourceDate() (time.Time, error) {
+	epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok {
+		return time.Now(), nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// OnlyServices returns the services to generate codes for. An empty result means all services.
+func (cu *CodeUtils) OnlyServices() []string {
+	return cu.onlyServices
+}
+
+// SetProtocols adds a comma separated list of protocols, "binary" or "compact",
+// to generate the Append and Read methods of struct-likes for.
+func (cu *CodeUtils) SetProtocols(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		var proto string
+		switch name {
+		case "":
+			continue
+		case "binary":
+			proto = "Binary"
+		case "compact":
+			proto = "Compact"
+		default:
+			return fmt.Errorf("protocols: unknown protocol %q, expect binary or compact", name)
+		}
+		found := false
+		for _, p := range cu.protocols {
+			found = found || p == proto
+		}
+		if !found {
+			cu.protocols = append(cu.protocols, proto)
+		}
+	}
+	sort.Strings(cu.protocols)
+	return nil
+}
+
+// Protocols returns the names of the protocols to generate the Append and Read
+// methods for, in the order of "Binary" and "Compact".
+func (cu *CodeUtils) Protocols() []string {
+	return cu.protocols
+}
+
+// Profile returns the profile to generate codes for. An empty result means all declarations.
+func (cu *CodeUtils) Profile() string {
+	return cu.profile
+}
+
+// AddExclude adds a glob pattern of the included IDLs to skip in recursive mode.
+func (cu *CodeUtils) AddExclude(pattern string) error {
+	if _, err := utils.MatchGlob(pattern, ""); err != nil {
+		return fmt.Errorf("exclude: invalid pattern %q: %w", pattern, err)
+	}
+	cu.excludes = append(cu.excludes, pattern)
+	return nil
+}
+
+// IsExcluded reports whether the IDL file matches any pattern added by AddExclude.
+func (cu *CodeUtils) IsExcluded(filename string) bool {
+	for _, p := range cu.excludes {
+		if ok, _ := utils.MatchGlob(p, filename); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Template returns the current template name. Empty for the default.
 func (cu *CodeUtils) Template() string {
 	return cu.useTemplate
@@ -135,12 +657,83 @@ func (cu *CodeUtils) NamingStyle() styles.Naming {
 func (cu *CodeUtils) SetNamingStyle(style styles.Naming) {
 	cu.namingStyle = style
 	cu.namingStyle.UseInitialisms(cu.doInitialisms)
+	cu.namingStyle.AddInitialisms(cu.initialisms...)
+	if cu.plainStyle = styles.NewNamingStyle(style.Name()); cu.plainStyle != nil {
+		cu.plainStyle.UseInitialisms(cu.doInitialisms)
+	}
+}
+
+// SetInitialisms adds a comma separated list of initialisms to the naming style.
+func (cu *CodeUtils) SetInitialisms(value string) error {
+	for _, w := range strings.Split(value, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		for _, r := range w {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return fmt.Errorf("initialisms: invalid initialism %q", w)
+			}
+		}
+		cu.initialisms = append(cu.initialisms, strings.ToUpper(w))
+	}
+	cu.namingStyle.AddInitialisms(cu.initialisms...)
+	return nil
+}
+
+// Initialisms returns the initialisms added by users.
+func (cu *CodeUtils) Initialisms() []string {
+	return cu.initialisms
+}
+
+// AddReservedNames adds a comma separated list of go names that the names from
+// the IDL are escaped from.
+func (cu *CodeUtils) AddReservedNames(value string) error {
+	for _, n := range strings.Split(value, ",") {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		if !token.IsIdentifier(n) {
+			return fmt.Errorf("reserved_names: %q is not a go identifier", n)
+		}
+		if cu.reserved == nil {
+			cu.reserved = make(map[string]bool)
+		}
+		cu.reserved[n] = true
+	}
+	return nil
+}
+
+// SetReservedSuffix sets the suffix appended to the reserved go names.
+func (cu *CodeUtils) SetReservedSuffix(value string) error {
+	if value == "" || !token.IsIdentifier("_"+value) {
+		return fmt.Errorf("reserved_suffix: %q can not end a go identifier", value)
+	}
+	cu.reservedSuffix = value
+	return nil
+}
+
+// escapeReserved appends the reserved suffix to the name until it is not one of
+// the reserved go names.
+func (cu *CodeUtils) escapeReserved(name string) string {
+	suffix := cu.reservedSuffix
+	if suffix == "" {
+		suffix = "_"
+	}
+	for cu.reserved[name] {
+		name += suffix
+	}
+	return name
 }
 
 // UseInitialisms sets the naming style's initialisms option.
 func (cu *CodeUtils) UseInitialisms(enable bool) {
 	cu.doInitialisms = enable
 	cu.namingStyle.UseInitialisms(cu.doInitialisms)
+	if cu.plainStyle != nil {
+		cu.plainStyle.UseInitialisms(cu.doInitialisms)
+	}
 }
 
 // Identify converts an raw name from IDL into an exported identifier in go.
@@ -153,6 +746,16 @@ func (cu *CodeUtils) Identify(name string) (s string, err error) {
 	return s, nil
 }
 
+// identifyPlain converts a raw name like Identify, but ignores the initialisms
+// added by users. It returns false if that is not possible.
+func (cu *CodeUtils) identifyPlain(name string) (string, bool) {
+	if cu.plainStyle == nil {
+		return "", false
+	}
+	s, err := cu.plainStyle.Identify(strings.TrimPrefix(name, prefix))
+	return s, err == nil
+}
+
 // Debug prints the given values with println.
 func (cu *CodeUtils) Debug(vs ...interface{}) string {
 	var ss []string
@@ -168,7 +771,7 @@ func (cu *CodeUtils) Debug(vs ...interface{}) string {
 func (cu *CodeUtils) ParseNamespace(ast *parser.Thrift) (ref, pkg, pth string) {
 	ref = filepath.Base(ast.Filename)
 	ref = strings.TrimSuffix(ref, filepath.Ext(ref))
-	ns := ast.GetNamespaceOrReferenceName("go")
+	ns := cu.GoNamespace(ast)
 	pkg = cu.NamespaceToPackage(ns)
 	pth = cu.NamespaceToImportPath(ns)
 	return
@@ -213,7 +816,10 @@ func (cu *CodeUtils) CombineOutputPath(outputPath string, t *parser.Thrift) stri
 
 // GetPackageName returns a go package name for the given thrift AST.
 func (cu *CodeUtils) GetPackageName(ast *parser.Thrift) string {
-	namespace := ast.GetNamespaceOrReferenceName("go")
+	if name := cu.PinnedPackageName(ast); name != "" {
+		return name
+	}
+	namespace := cu.GoNamespace(ast)
 	return cu.NamespaceToPackage(namespace)
 }
 
@@ -234,6 +840,9 @@ func (cu *CodeUtils) NamespaceToImportPath(ns string) string {
 // The result path will contain the package prefix if it is set.
 func (cu *CodeUtils) NamespaceToFullImportPath(ns string) string {
 	pth := cu.NamespaceToImportPath(ns)
+	if dir, ok := cu.importDirs[ns]; ok {
+		pth = dir
+	}
 	if cu.packagePrefix != "" {
 		pth = JoinPath(cu.packagePrefix, pth)
 	}
@@ -242,7 +851,7 @@ func (cu *CodeUtils) NamespaceToFullImportPath(ns string) string {
 
 // Import returns the package name and the full import path for the given AST.
 func (cu *CodeUtils) Import(t *parser.Thrift) (pkg, pth string) {
-	ns := t.GetNamespaceOrReferenceName("go")
+	ns := cu.GoNamespace(t)
 	pkg = cu.NamespaceToPackage(ns)
 	pth = cu.NamespaceToFullImportPath(ns)
 	return
@@ -277,21 +886,12 @@ func (cu *CodeUtils) genFieldTags(f *parser.Field, insertPoint string, extend []
 	tags = append(tags, extend...)
 
 	gotags := f.Annotations.Get("go.tag")
-	if len(gotags) > 0 {
-		tag := gotags[0]
-		if cu.Features().EscapeDoubleInTag {
-			tag = escape.ReplaceAllStringFunc(tag, func(m string) string {
-				if m[1] == '"' {
-					return m[1:]
-				}
-				return m
-			})
-		}
-		tags = append(tags, tag)
-	} else {
-		if cu.Features().GenDatabaseTag {
-			tags = append(tags, fmt.Sprintf(`db:"%s"`, f.Name))
-		}
+	custom, err := cu.parseGoTags(gotags)
+	if err != nil {
+		return "", fmt.Errorf("go.tag of field '%s': %w", f.Name, err)
+	}
+	if len(gotags) == 0 && cu.Features().GenDatabaseTag {
+		tags = append(tags, fmt.Sprintf(`db:"%s"`, f.Name))
 	}
 
 	if len(gotags) == 0 && cu.Features().GenerateJSONTag || cu.Features().AlwaysGenerateJSONTag {
@@ -303,13 +903,21 @@ func (cu *CodeUtils) genFieldTags(f *parser.Field, insertPoint string, extend []
 			id = lowerCamelCase(id)
 		}
 
-		if f.Requiredness.IsOptional() && cu.Features().GenOmitEmptyTag {
+		set, omit, err := jsonOmitEmpty(f)
+		if err != nil {
+			return "", fmt.Errorf("field '%s': %w", f.Name, err)
+		}
+		if !set {
+			omit = f.Requiredness.IsOptional() && cu.Features().GenOmitEmptyTag
+		}
+		if omit {
 			tags = append(tags, fmt.Sprintf(`json:"%s,omitempty"`, id))
 		} else {
 			tags = append(tags, fmt.Sprintf(`json:"%s"`, id))
 		}
 	}
 
+	tags = mergeTags(tags, custom)
 	str := fmt.Sprintf("`%s%s`", strings.Join(tags, " "), insertPoint)
 	return str, nil
 }
@@ -382,20 +990,59 @@ func (cu *CodeUtils) BuildFuncMap() template.FuncMap {
 		"Features":         cu.Features,
 		"SetWithFieldMask": cu.SetWithFieldMask,
 		"GetPackageName":   cu.GetPackageName,
+		"GoNamespace":      cu.GoNamespace,
 		"GenTags":          cu.GenTags,
 		"GenFieldTags":     cu.GenFieldTags,
+		"Protocols":        cu.Protocols,
 		"MkRWCtx": func(f *Field) (*ReadWriteContext, error) {
 			return cu.MkRWCtx(cu.rootScope, f)
 		},
+		"IsDistinctTypedef": cu.IsDistinctTypedef,
+		"GoVersionAtLeast":  cu.GoVersionAtLeast,
+		"ErrWrapVerb":       cu.ErrWrapVerb,
+		"Deprecation":       cu.Deprecation,
+		"ApacheRuntime": func() bool {
+			return cu.Runtime() == apacheRuntime
+		},
+		// ProtoCtx and ProtoCtxArg fill the context parameter of the methods
+		// of thrift.TProtocol, which only the apache runtime has.
+		"ProtoCtx": func() string {
+			if cu.Runtime() == apacheRuntime {
+				return "ctx"
+			}
+			return ""
+		},
+		"ProtoCtxArg": func() string {
+			if cu.Runtime() == apacheRuntime {
+				return "ctx, "
+			}
+			return ""
+		},
+		// TException converts an error to the thrift.TException that the
+		// processors return, which is a distinct interface in the apache runtime.
+		"TException": func(err string) string {
+			if cu.Runtime() == apacheRuntime {
+				return "thrift.WrapTException(" + err + ")"
+			}
+			return err
+		},
+
+		// SkipFunc is the name of the function that the Read methods of the
+		// file skip values with, see fast_skip.
+		"SkipFunc": func() string {
+			return "file_" + cu.rootScope.IDLName() + "_thrift_skip"
+		},
 
-		"IsBaseType":        IsBaseType,
-		"ZeroWriter":        ZeroWriter,
-		"NeedRedirect":      NeedRedirect,
-		"IsFixedLengthType": IsFixedLengthType,
-		"SupportIsSet":      SupportIsSet,
-		"GetTypeIDConstant": GetTypeIDConstant,
-		"IsIntType":         IsIntType,
-		"IsStrType":         IsStrType,
+		"IsBaseType":           IsBaseType,
+		"ZeroWriter":           ZeroWriter,
+		"NeedRedirect":         NeedRedirect,
+		"IsFixedLengthType":    IsFixedLengthType,
+		"BinaryFixedSize":      BinaryFixedSize,
+		"SupportIsSet":         SupportIsSet,
+		"JSONMarshalCondition": JSONMarshalCondition,
+		"GetTypeIDConstant":    GetTypeIDConstant,
+		"IsIntType":            IsIntType,
+		"IsStrType":            IsStrType,
 		"UseStdLibrary": func(libs ...string) string {
 			cu.rootScope.imports.UseStdLibrary(libs...)
 			return ""
@@ -0,0 +1,105 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package idl implements a backend that re-emits the parsed IDLs as canonical thrift source.
+package idl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/thriftgo/generator/backend"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+// IDLBackend formats IDLs. The output files keep their paths relative to the
+// main IDL under the output directory, unless --out-template is given.
+type IDLBackend struct{}
+
+// Name implements the Backend interface.
+func (b *IDLBackend) Name() string {
+	return "idl"
+}
+
+// Lang implements the Backend interface.
+func (b *IDLBackend) Lang() string {
+	return "Thrift"
+}
+
+// Options implements the Backend interface.
+func (b *IDLBackend) Options() []plugin.Option {
+	return nil
+}
+
+// BuiltinPlugins implements the Backend interface.
+func (b *IDLBackend) BuiltinPlugins() []*plugin.Desc {
+	return nil
+}
+
+// GetPlugin implements the Backend interface.
+func (b *IDLBackend) GetPlugin(desc *plugin.Desc) plugin.Plugin {
+	return nil
+}
+
+// Generate implements the Backend interface.
+func (b *IDLBackend) Generate(req *plugin.Request, log backend.LogFunc) *plugin.Response {
+	res := plugin.NewResponse()
+	var outTpl *template.Template
+	if text := req.GetOutputTemplate(); text != "" {
+		tpl, err := backend.ParseOutputTemplate(text)
+		if err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+		outTpl = tpl
+	}
+	root := filepath.Dir(req.AST.Filename)
+	emit := func(ast *parser.Thrift) error {
+		var name string
+		if outTpl != nil {
+			ns := strings.ReplaceAll(ast.GetNamespaceOrReferenceName("idl"), ".", "/")
+			dir, err := backend.OutputDir(outTpl, req.OutputPath, backend.NewOutputDirData(ast.Filename, ns, "idl"))
+			if err != nil {
+				return fmt.Errorf("out-template: %w", err)
+			}
+			name = filepath.Join(dir, filepath.Base(ast.Filename))
+		} else {
+			rel, err := filepath.Rel(root, ast.Filename)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				rel = filepath.Base(ast.Filename)
+			}
+			name = filepath.Join(req.OutputPath, rel)
+		}
+		log.Info("Write", name)
+		res.Contents = append(res.Contents, &plugin.Generated{
+			Content: Format(ast),
+			Name:    &name,
+		})
+		return nil
+	}
+	if !req.Recursive {
+		if err := emit(req.AST); err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+		return res
+	}
+	for ast := range req.AST.DepthFirstSearch() {
+		if err := emit(ast); err != nil {
+			return plugin.BuildErrorResponse(err.Error())
+		}
+	}
+	return res
+}
@@ -0,0 +1,426 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+const indent = "    "
+
+// Format renders the AST as canonical thrift source.
+//
+// Headers come first (namespaces, includes and cpp_includes), followed by the
+// definitions in their order in the source if their positions are recorded, or
+// else grouped by kind: typedefs, constants, enums, senums, structs, unions,
+// exceptions and services. Each field is written on its own line with its ID
+// right-aligned within the definition. Reserved IDs and names are merged into a
+// single 'reserved' statement ahead of the fields. The comments kept by the parser
+// are written above the element they belong to, and so are the structured
+// annotations of fbthrift, except for those of the arguments of functions, which
+// are written before them. Formatting the output again yields the same text.
+//
+// The formatting is lossy: the parser only keeps the comments right above the
+// definitions, fields, enum values and functions, or at the end of their lines,
+// so other comments, e.g. those at the top of the file or among the headers, are
+// dropped, and so is the original layout of each element.
+//
+// In the fb dialect, the response types of functions are written as stream<T>
+// and sink<T, R>, which the parser stores as the streaming.mode annotation and
+// the SinkType of the function.
+func Format(ast *parser.Thrift) string {
+	p := &printer{dialect: parser.CurrentDialect()}
+	p.headers(ast)
+	for _, d := range definitions(p, ast) {
+		p.section()
+		d.print()
+	}
+	return p.String()
+}
+
+// definition is a top-level definition and the way to print it.
+type definition struct {
+	pos   *parser.Position
+	print func()
+}
+
+// definitions returns the top-level definitions of the AST grouped by kind. They
+// are sorted by their positions instead when all of them are recorded.
+func definitions(p *printer, ast *parser.Thrift) (defs []definition) {
+	for _, td := range ast.Typedefs {
+		td := td
+		defs = append(defs, definition{td.Position, func() { p.typedef(td) }})
+	}
+	for _, c := range ast.Constants {
+		c := c
+		defs = append(defs, definition{c.Position, func() { p.constant(c) }})
+	}
+	for _, e := range ast.Enums {
+		e := e
+		defs = append(defs, definition{e.Position, func() { p.enum(e) }})
+	}
+	for _, e := range ast.Senums {
+		e := e
+		defs = append(defs, definition{e.Position, func() { p.senum(e) }})
+	}
+	for _, s := range ast.GetStructLikes() {
+		s := s
+		defs = append(defs, definition{s.Position, func() { p.structLike(s) }})
+	}
+	for _, s := range ast.Services {
+		s := s
+		defs = append(defs, definition{s.Position, func() { p.service(s) }})
+	}
+	for _, d := range defs {
+		if d.pos == nil {
+			return defs
+		}
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		x, y := defs[i].pos, defs[j].pos
+		return x.Line < y.Line || x.Line == y.Line && x.Col < y.Col
+	})
+	return defs
+}
+
+type printer struct {
+	strings.Builder
+	dialect parser.Dialect
+}
+
+func (p *printer) printf(format string, a ...interface{}) {
+	fmt.Fprintf(p, format, a...)
+}
+
+// section separates top-level elements and header groups with a blank line.
+func (p *printer) section() {
+	if p.Len() > 0 {
+		p.WriteString("\n")
+	}
+}
+
+func (p *printer) headers(ast *parser.Thrift) {
+	if len(ast.Namespaces) > 0 {
+		p.section()
+		for _, ns := range ast.Namespaces {
+			p.printf("namespace %s %s%s\n", ns.Language, ns.Name, annotations(ns.Annotations))
+		}
+	}
+	if len(ast.Includes) > 0 {
+		p.section()
+		for _, inc := range ast.Includes {
+			if inc.Alias != "" {
+				p.printf("include %s as %s\n", literal(inc.Path), inc.Alias)
+			} else {
+				p.printf("include %s\n", literal(inc.Path))
+			}
+		}
+	}
+	if len(ast.CppIncludes) > 0 {
+		p.section()
+		for _, inc := range ast.CppIncludes {
+			p.printf("cpp_include %s\n", literal(inc))
+		}
+	}
+}
+
+// comments writes the reserved comments with the given indentation. Lines inside
+// a block comment are written as they are.
+func (p *printer) comments(cmts, prefix string) {
+	if cmts == "" {
+		return
+	}
+	inBlock := false
+	for _, line := range strings.Split(cmts, "\n") {
+		if inBlock {
+			p.WriteString(line + "\n")
+		} else {
+			p.WriteString(prefix + line + "\n")
+			if strings.HasPrefix(line, "//") {
+				continue
+			}
+		}
+		if o, c := strings.LastIndex(line, "/*"), strings.LastIndex(line, "*/"); o > c {
+			inBlock = true
+		} else if c >= 0 {
+			inBlock = false
+		}
+	}
+}
+
+// structured writes the structured annotations of fbthrift, one per line, with
+// the given indentation.
+func (p *printer) structured(sas parser.StructuredAnnotations, prefix string) {
+	for _, sa := range sas {
+		p.printf("%s%s\n", prefix, structuredAnnotation(sa))
+	}
+}
+
+func (p *printer) typedef(td *parser.Typedef) {
+	p.comments(td.ReservedComments, "")
+	p.structured(td.StructuredAnnotations, "")
+	p.printf("typedef %s %s%s\n", typeString(td.Type), td.Alias, annotations(td.Annotations))
+}
+
+func (p *printer) constant(c *parser.Constant) {
+	p.comments(c.ReservedComments, "")
+	p.structured(c.StructuredAnnotations, "")
+	p.printf("const %s %s = %s%s\n", typeString(c.Type), c.Name, constString(c.Value), annotations(c.Annotations))
+}
+
+func (p *printer) enum(e *parser.Enum) {
+	p.comments(e.ReservedComments, "")
+	p.structured(e.StructuredAnnotations, "")
+	p.printf("enum %s {\n", e.Name)
+	for _, v := range e.Values {
+		p.comments(v.ReservedComments, indent)
+		p.printf("%s%s = %d%s\n", indent, v.Name, v.Value, annotations(v.Annotations))
+	}
+	p.printf("}%s\n", annotations(e.Annotations))
+}
+
+func (p *printer) senum(e *parser.Senum) {
+	p.comments(e.ReservedComments, "")
+	p.printf("senum %s {\n", e.Name)
+	for _, v := range e.Values {
+		p.printf("%s%s\n", indent, literal(v))
+	}
+	p.printf("}%s\n", annotations(e.Annotations))
+}
+
+func (p *printer) structLike(s *parser.StructLike) {
+	p.comments(s.ReservedComments, "")
+	p.structured(s.StructuredAnnotations, "")
+	p.printf("%s %s {\n", s.Category, s.Name)
+	if len(s.ReservedIDs) > 0 || len(s.ReservedNames) > 0 {
+		var rs []string
+		for _, id := range s.ReservedIDs {
+			rs = append(rs, strconv.Itoa(int(id)))
+		}
+		for _, n := range s.ReservedNames {
+			rs = append(rs, literal(n))
+		}
+		p.printf("%sreserved %s\n", indent, strings.Join(rs, ", "))
+	}
+	width := idWidth(s.Fields)
+	for _, f := range s.Fields {
+		p.comments(f.ReservedComments, indent)
+		p.structured(f.StructuredAnnotations, indent)
+		p.printf("%s%s\n", indent, fieldString(f, width, parser.FieldType_Default))
+	}
+	p.printf("}%s\n", annotations(s.Annotations))
+}
+
+func (p *printer) service(s *parser.Service) {
+	p.comments(s.ReservedComments, "")
+	p.structured(s.StructuredAnnotations, "")
+	p.printf("service %s ", s.Name)
+	if s.Extends != "" {
+		p.printf("extends %s ", s.Extends)
+	}
+	p.printf("{\n")
+	for _, f := range s.Functions {
+		p.comments(f.ReservedComments, indent)
+		p.structured(f.StructuredAnnotations, indent)
+		p.WriteString(indent)
+		if f.Oneway {
+			p.WriteString("oneway ")
+		}
+		annos := f.Annotations
+		switch {
+		case f.Void:
+			p.WriteString("void")
+		case f.SinkType != nil:
+			p.printf("sink<%s, %s>", typeString(f.SinkType), typeString(f.FunctionType))
+		case p.dialect == parser.DialectFB && isServerStreaming(annos):
+			p.printf("stream<%s>", typeString(f.FunctionType))
+			annos = without(annos, streamingMode)
+		default:
+			p.WriteString(typeString(f.FunctionType))
+		}
+		p.printf(" %s(%s)", f.Name, fieldList(f.Arguments, parser.FieldType_Default))
+		if len(f.Throws) > 0 {
+			p.printf(" throws (%s)", fieldList(f.Throws, parser.FieldType_Optional))
+		}
+		p.printf("%s\n", annotations(annos))
+	}
+	p.printf("}%s\n", annotations(s.Annotations))
+}
+
+// streamingMode is the annotation that the parser stores a stream<T> response as.
+const streamingMode = "streaming.mode"
+
+// isServerStreaming reports whether the annotations of a function are those of
+// a stream<T> response.
+func isServerStreaming(annos parser.Annotations) bool {
+	vs := annos.Get(streamingMode)
+	return len(vs) == 1 && vs[0] == "server"
+}
+
+// without returns the annotations without the key.
+func without(annos parser.Annotations, key string) (res parser.Annotations) {
+	for _, a := range annos {
+		if a.Key != key {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func idWidth(fields []*parser.Field) (width int) {
+	for _, f := range fields {
+		if f.ID == parser.NOTSET {
+			continue
+		}
+		if w := len(strconv.Itoa(int(f.ID))); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// fieldString renders a field. The requiredness is left out if it is implied.
+func fieldString(f *parser.Field, width int, implied parser.FieldType) string {
+	var sb strings.Builder
+	if f.ID != parser.NOTSET {
+		fmt.Fprintf(&sb, "%*d: ", width, f.ID)
+	} else if width > 0 {
+		sb.WriteString(strings.Repeat(" ", width+2))
+	}
+	switch req := f.Requiredness; {
+	case req == implied:
+	case req == parser.FieldType_Required:
+		sb.WriteString("required ")
+	case req == parser.FieldType_Optional:
+		sb.WriteString("optional ")
+	}
+	sb.WriteString(typeString(f.Type) + " " + f.Name)
+	if f.Default != nil {
+		sb.WriteString(" = " + constString(f.Default))
+	}
+	sb.WriteString(annotations(f.Annotations))
+	return sb.String()
+}
+
+func fieldList(fields []*parser.Field, implied parser.FieldType) string {
+	var ss []string
+	for _, f := range fields {
+		var sas []string
+		for _, sa := range f.StructuredAnnotations {
+			sas = append(sas, structuredAnnotation(sa)+" ")
+		}
+		ss = append(ss, strings.Join(sas, "")+fieldString(f, 0, implied))
+	}
+	return strings.Join(ss, ", ")
+}
+
+func typeString(t *parser.Type) string {
+	var s string
+	switch t.Name {
+	case "map":
+		s = "map" + cppType(t.CppType) + "<" + typeString(t.KeyType) + ", " + typeString(t.ValueType) + ">"
+	case "set":
+		s = "set" + cppType(t.CppType) + "<" + typeString(t.ValueType) + ">"
+	case "list":
+		s = "list<" + typeString(t.ValueType) + ">"
+		if t.CppType != "" {
+			s += " " + strings.TrimSpace(cppType(t.CppType))
+		}
+	default:
+		s = t.Name
+	}
+	return s + annotations(t.Annotations)
+}
+
+func cppType(ct string) string {
+	if ct == "" {
+		return ""
+	}
+	return " cpp_type " + literal(ct)
+}
+
+func constString(v *parser.ConstValue) string {
+	tv := v.TypedValue
+	switch v.Type {
+	case parser.ConstType_ConstDouble:
+		s := strconv.FormatFloat(tv.GetDouble(), 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case parser.ConstType_ConstInt:
+		return strconv.FormatInt(tv.GetInt(), 10)
+	case parser.ConstType_ConstLiteral:
+		return literal(tv.GetLiteral())
+	case parser.ConstType_ConstIdentifier:
+		return tv.GetIdentifier()
+	case parser.ConstType_ConstExpr:
+		return constString(tv.Expr.LHS) + " " + tv.Expr.Op + " " + constString(tv.Expr.RHS)
+	case parser.ConstType_ConstList:
+		var ss []string
+		for _, e := range tv.List {
+			ss = append(ss, constString(e))
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+	case parser.ConstType_ConstMap:
+		var ss []string
+		for _, kv := range tv.Map {
+			ss = append(ss, constString(kv.Key)+": "+constString(kv.Value))
+		}
+		return "{" + strings.Join(ss, ", ") + "}"
+	}
+	return ""
+}
+
+func annotations(annos parser.Annotations) string {
+	var ss []string
+	for _, a := range annos {
+		for _, v := range a.Values {
+			ss = append(ss, a.Key+" = "+literal(v))
+		}
+	}
+	if len(ss) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(ss, ", ") + ")"
+}
+
+func structuredAnnotation(sa *parser.StructuredAnnotation) string {
+	if len(sa.Fields) == 0 {
+		return "@" + sa.Name
+	}
+	var ss []string
+	for _, f := range sa.Fields {
+		ss = append(ss, f.Name+" = "+constString(f.Value))
+	}
+	return "@" + sa.Name + "{" + strings.Join(ss, ", ") + "}"
+}
+
+// literal quotes the content of a literal. The parser unescapes the quote
+// that encloses a literal, so the quote is chosen by the content.
+func literal(s string) string {
+	switch {
+	case !strings.Contains(s, `"`):
+		return `"` + s + `"`
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idl
+
+import (
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+const source = `include "base.thrift"
+include "common/error.thrift"   as   errors
+namespace go example.fmt (pkg = "x")
+
+// Status of a request
+enum Status { OK, FAILED = 3 (alias = 'bad') }
+senum Color { "red", 'g"x' }
+
+typedef map<string,list<i64>> Index(k="v")
+const double Ratio = 2.50
+const list<string> Names = ['a', "b\"c", 'd"e', "f'\"g"]
+const map<string, Status> Default = {"x": Status.OK}
+
+/* Request is
+   the input */
+struct Request {
+  reserved 3, "old"
+  1: required string name = "n" (go.tag = 'json:"name"')
+  // the counts
+  10: optional set<i32> counts
+  2: base.Base base, // trailing
+}
+
+union U { reserved 'x'; 1: i32 a; 2: string b } (u = "1")
+
+exception Err { 1: string msg }
+
+service Svc extends base.BaseSvc {
+  Status Call(1: Request req, 2: i32 n) throws (1: Err err) (api.get = "/call")
+  oneway void Fire()
+}
+`
+
+const expected = `namespace go example.fmt (pkg = "x")
+
+include "base.thrift"
+include "common/error.thrift" as errors
+
+// Status of a request
+enum Status {
+    OK = 0
+    FAILED = 3 (alias = "bad")
+}
+
+senum Color {
+    "red"
+    'g"x'
+}
+
+typedef map<string, list<i64>> Index (k = "v")
+
+const double Ratio = 2.5
+
+const list<string> Names = ["a", 'b"c', 'd"e', "f'\"g"]
+
+const map<string, Status> Default = {"x": Status.OK}
+
+/* Request is
+   the input */
+struct Request {
+    reserved 3, "old"
+     1: required string name = "n" (go.tag = 'json:"name"')
+    // the counts
+    10: optional set<i32> counts
+    // trailing
+     2: base.Base base
+}
+
+union U {
+    reserved "x"
+    1: i32 a
+    2: string b
+} (u = "1")
+
+exception Err {
+    1: string msg
+}
+
+service Svc extends base.BaseSvc {
+    Status Call(1: Request req, 2: i32 n) throws (1: Err err) (api.get = "/call")
+    oneway void Fire()
+}
+`
+
+func TestFormat(t *testing.T) {
+	ast, err := parser.ParseString("a.thrift", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Format(ast)
+	if got != expected {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	ast, err = parser.ParseString("a.thrift", got)
+	if err != nil {
+		t.Fatalf("parse formatted output: %v", err)
+	}
+	if again := Format(ast); again != got {
+		t.Fatalf("formatting is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormatResponseTypes(t *testing.T) {
+	const src = `service S {
+    stream<i32> Sub(1: i32 n) (api.get = "/sub")
+    sink<i32, i64> Pub()
+    i32 Get() (streaming.mode = "client")
+}
+`
+	parser.SetDialect(parser.DialectFB)
+	ast, err := parser.ParseString("a.thrift", src)
+	if err != nil {
+		parser.SetDialect(parser.DialectApache)
+		t.Fatal(err)
+	}
+	got := Format(ast)
+	parser.SetDialect(parser.DialectApache)
+	if got != src {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	// the apache dialect has no stream<T>, so the annotation is kept
+	const apache = `service S {
+    i32 Sub(1: i32 n) (api.get = "/sub", streaming.mode = "server")
+}
+`
+	if ast, err = parser.ParseString("a.thrift", apache); err != nil {
+		t.Fatal(err)
+	}
+	if got = Format(ast); got != apache {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestFormatStructuredAnnotations(t *testing.T) {
+	const src = `@cpp.Type{name = "folly::IOBuf"}
+typedef binary IOBuf
+
+@deprecated
+const i32 N = 1
+
+@thrift.Experimental
+enum E {
+    A = 0
+}
+
+// S is annotated
+@cpp.Adapter{name = "A", args = [1, 2]}
+@json
+struct S {
+    @cpp.Ref
+    1: i32 a
+}
+
+@scope.Service
+service Svc {
+    @idempotent
+    void F(@sensitive 1: string key)
+}
+`
+	parser.SetDialect(parser.DialectFB)
+	defer parser.SetDialect(parser.DialectApache)
+	ast, err := parser.ParseString("a.thrift", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Format(ast); got != src {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
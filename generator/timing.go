@@ -0,0 +1,69 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timing accumulates the wall-clock durations of the phases of a run.
+// Phases are reported in the order they are first started. A nil *Timing
+// records nothing, so the timers can be left in place unconditionally.
+type Timing struct {
+	phases    []string
+	durations map[string]time.Duration
+}
+
+// NewTiming creates an empty Timing.
+func NewTiming() *Timing {
+	return &Timing{durations: make(map[string]time.Duration)}
+}
+
+// Start starts timing the phase and returns a function to stop it.
+// Time spent in the same phase multiple times is summed up.
+func (t *Timing) Start(phase string) (stop func()) {
+	if t == nil {
+		return func() {}
+	}
+	begin := time.Now()
+	return func() {
+		t.Add(phase, time.Since(begin))
+	}
+}
+
+// Add adds d to the duration of the phase.
+func (t *Timing) Add(phase string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	if _, ok := t.durations[phase]; !ok {
+		t.phases = append(t.phases, phase)
+	}
+	t.durations[phase] += d
+}
+
+// String returns the breakdown like "parse: 120ms, semantic: 80ms, codegen: 300ms".
+func (t *Timing) String() string {
+	if t == nil {
+		return ""
+	}
+	var ss []string
+	for _, p := range t.phases {
+		ss = append(ss, fmt.Sprintf("%s: %s", p, t.durations[p].Round(time.Microsecond)))
+	}
+	return strings.Join(ss, ", ")
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudwego/thriftgo/generator"
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestTiming(t *testing.T) {
+	var nilTiming *generator.Timing
+	nilTiming.Start("parse")()
+	test.Assert(t, nilTiming.String() == "")
+
+	timing := generator.NewTiming()
+	timing.Add("parse", 120*time.Millisecond)
+	timing.Add("semantic", 80*time.Millisecond)
+	timing.Add("codegen", 100*time.Millisecond)
+	timing.Add("codegen", 200*time.Millisecond)
+	test.Assert(t, timing.String() == "parse: 120ms, semantic: 80ms, codegen: 300ms", timing)
+
+	timing.Start("write")()
+	test.Assert(t, len(timing.String()) > len("parse: 120ms, semantic: 80ms, codegen: 300ms, write: "), timing)
+}
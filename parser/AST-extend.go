@@ -60,17 +60,40 @@ func (a *Annotations) ILocValueByKey(key string, idx int) string {
 	return ""
 }
 
-// IsDefault tells whether a field type is default.
+// Get returns the structured annotation with the given name, or nil if there is none.
+func (s *StructuredAnnotations) Get(name string) *StructuredAnnotation {
+	for _, sa := range *s {
+		if sa.Name == name {
+			return sa
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the field with the given name, or nil if it is not set.
+func (a *StructuredAnnotation) Get(name string) *ConstValue {
+	for _, f := range a.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// IsDefault tells whether a field type is default, i.e. neither required nor
+// optional. Such a field is always written, but its absence is tolerated on read.
 func (r FieldType) IsDefault() bool {
 	return r == FieldType_Default
 }
 
-// IsRequired tells whether a field type is required.
+// IsRequired tells whether a field type is required. Such a field is always
+// written and it is an error if it is absent on read.
 func (r FieldType) IsRequired() bool {
 	return r == FieldType_Required
 }
 
-// IsOptional tells whether a field type is optional.
+// IsOptional tells whether a field type is optional. Such a field is written
+// only when it is set and its absence is tolerated on read.
 func (r FieldType) IsOptional() bool {
 	return r == FieldType_Optional
 }
@@ -89,6 +112,9 @@ func (t *ConstValue) String() string {
 		val = fmt.Sprintf("\"%s\"", *t.TypedValue.Literal)
 	case ConstType_ConstIdentifier:
 		val = *t.TypedValue.Identifier
+	case ConstType_ConstExpr:
+		e := t.TypedValue.Expr
+		val = fmt.Sprintf("%s %s %s", e.LHS, e.Op, e.RHS)
 	case ConstType_ConstList:
 		if len(t.TypedValue.List) == 0 {
 			return "{}"
@@ -120,6 +146,27 @@ func (t *ConstValue) String() string {
 	return fmt.Sprintf("%s(%s)", t.Type, val)
 }
 
+// Eval applies the operator of the expression to the values of its operands.
+func (e *ConstExpr) Eval(lhs, rhs int64) (int64, error) {
+	switch e.Op {
+	case "+":
+		if res := lhs + rhs; (res > lhs) == (rhs > 0) {
+			return res, nil
+		}
+	case "-":
+		if res := lhs - rhs; (res < lhs) == (rhs > 0) {
+			return res, nil
+		}
+	case "|":
+		return lhs | rhs, nil
+	case "&":
+		return lhs & rhs, nil
+	default:
+		return 0, fmt.Errorf("unsupported operator %q in const expression", e.Op)
+	}
+	return 0, fmt.Errorf("integer overflow: %d %s %d", lhs, e.Op, rhs)
+}
+
 func (t *Type) String() string {
 	switch t.Name {
 	case "map":
@@ -169,13 +216,22 @@ func (t *Thrift) DepthFirstSearch() chan *Thrift {
 func (t *Thrift) GetReference(refname string) (*Thrift, bool) {
 	for _, inc := range t.Includes {
 		ref := inc.Reference
-		if ref != nil && refName(inc.Path) == refname {
+		if ref != nil && inc.RefName() == refname {
 			return ref, true
 		}
 	}
 	return nil, false
 }
 
+// RefName returns the name that refers to the included IDL, which is the alias
+// given by 'as' or the base name without extension of the path.
+func (p *Include) RefName() string {
+	if p.Alias != "" {
+		return p.Alias
+	}
+	return refName(p.Path)
+}
+
 // GetNamespace returns a namespace for the language.
 // When both "*" and the language have a namespace defined, the latter is preferred.
 func (t *Thrift) GetNamespace(lang string) (ns string, found bool) {
@@ -140,6 +140,7 @@ const (
 	ConstType_ConstIdentifier ConstType = 3
 	ConstType_ConstList       ConstType = 4
 	ConstType_ConstMap        ConstType = 5
+	ConstType_ConstExpr       ConstType = 6
 )
 
 func (p ConstType) String() string {
@@ -156,6 +157,8 @@ func (p ConstType) String() string {
 		return "ConstList"
 	case ConstType_ConstMap:
 		return "ConstMap"
+	case ConstType_ConstExpr:
+		return "ConstExpr"
 	}
 	return "<UNSET>"
 }
@@ -174,6 +177,8 @@ func ConstTypeFromString(s string) (ConstType, error) {
 		return ConstType_ConstList, nil
 	case "ConstMap":
 		return ConstType_ConstMap, nil
+	case "ConstExpr":
+		return ConstType_ConstExpr, nil
 	}
 	return ConstType(0), fmt.Errorf("not a valid ConstType string")
 }
@@ -242,6 +247,8 @@ func (p *FieldType) Value() (driver.Value, error) {
 
 type Annotations []*Annotation
 
+type StructuredAnnotations []*StructuredAnnotation
+
 type Reference struct {
 	Name  string `thrift:"Name,1" json:"Name"`
 	Index int32  `thrift:"Index,2" json:"Index"`
@@ -285,6 +292,49 @@ func (p *Reference) String() string {
 	return fmt.Sprintf("Reference(%+v)", *p)
 }
 
+type Position struct {
+	Line int32 `thrift:"Line,1" json:"Line"`
+	Col  int32 `thrift:"Col,2" json:"Col"`
+}
+
+func init() {
+	meta.RegisterStruct(NewPosition, []byte{
+		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x8, 0x50,
+		0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0xb,
+		0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74,
+		0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc,
+		0x0, 0x0, 0x0, 0x2, 0x6, 0x0, 0x1, 0x0,
+		0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4,
+		0x4c, 0x69, 0x6e, 0x65, 0x8, 0x0, 0x3, 0x0,
+		0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0x8, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0x2, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0x3, 0x43, 0x6f, 0x6c, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0x8, 0x0,
+		0x0, 0x0,
+	})
+}
+
+func NewPosition() *Position {
+	return &Position{}
+}
+
+func (p *Position) GetLine() (v int32) {
+	return p.Line
+}
+
+func (p *Position) GetCol() (v int32) {
+	return p.Col
+}
+
+func (p *Position) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("Position(%+v)", *p)
+}
+
 type Annotation struct {
 	Key    string   `thrift:"Key,1" json:"Key"`
 	Values []string `thrift:"Values,2" json:"Values"`
@@ -330,6 +380,125 @@ func (p *Annotation) String() string {
 	return fmt.Sprintf("Annotation(%+v)", *p)
 }
 
+type StructuredAnnotation struct {
+	Name     string                       `thrift:"Name,1" json:"Name"`
+	Fields   []*StructuredAnnotationField `thrift:"Fields,2" json:"Fields"`
+	Position *Position                    `thrift:"Position,3,optional" json:"Position,omitempty"`
+}
+
+func init() {
+	meta.RegisterStruct(NewStructuredAnnotation, []byte{
+		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x14, 0x53,
+		0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65,
+		0x64, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+		0x69, 0x6f, 0x6e, 0xb, 0x0, 0x2, 0x0, 0x0,
+		0x0, 0x6, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
+		0xf, 0x0, 0x3, 0xc, 0x0, 0x0, 0x0, 0x3,
+		0x6, 0x0, 0x1, 0x0, 0x1, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x4, 0x4e, 0x61, 0x6d, 0x65,
+		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xb, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x2,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x46,
+		0x69, 0x65, 0x6c, 0x64, 0x73, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc, 0x0,
+		0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x3,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x8, 0x50,
+		0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x8,
+		0x0, 0x3, 0x0, 0x0, 0x0, 0x2, 0xc, 0x0,
+		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x0, 0x0,
+	})
+}
+
+func NewStructuredAnnotation() *StructuredAnnotation {
+	return &StructuredAnnotation{}
+}
+
+func (p *StructuredAnnotation) GetName() (v string) {
+	return p.Name
+}
+
+func (p *StructuredAnnotation) GetFields() (v []*StructuredAnnotationField) {
+	return p.Fields
+}
+
+var StructuredAnnotation_Position_DEFAULT *Position
+
+func (p *StructuredAnnotation) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return StructuredAnnotation_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *StructuredAnnotation) IsSetPosition() bool {
+	return p.Position != nil
+}
+
+func (p *StructuredAnnotation) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("StructuredAnnotation(%+v)", *p)
+}
+
+type StructuredAnnotationField struct {
+	Name  string      `thrift:"Name,1" json:"Name"`
+	Value *ConstValue `thrift:"Value,2" json:"Value"`
+}
+
+func init() {
+	meta.RegisterStruct(NewStructuredAnnotationField, []byte{
+		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x19, 0x53,
+		0x74, 0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65,
+		0x64, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+		0x69, 0x6f, 0x6e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73,
+		0x74, 0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3,
+		0xc, 0x0, 0x0, 0x0, 0x2, 0x6, 0x0, 0x1,
+		0x0, 0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
+		0x4, 0x4e, 0x61, 0x6d, 0x65, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0x2, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x5, 0x56, 0x61, 0x6c, 0x75,
+		0x65, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x0, 0x0,
+	})
+}
+
+func NewStructuredAnnotationField() *StructuredAnnotationField {
+	return &StructuredAnnotationField{}
+}
+
+func (p *StructuredAnnotationField) GetName() (v string) {
+	return p.Name
+}
+
+var StructuredAnnotationField_Value_DEFAULT *ConstValue
+
+func (p *StructuredAnnotationField) GetValue() (v *ConstValue) {
+	if !p.IsSetValue() {
+		return StructuredAnnotationField_Value_DEFAULT
+	}
+	return p.Value
+}
+
+func (p *StructuredAnnotationField) IsSetValue() bool {
+	return p.Value != nil
+}
+
+func (p *StructuredAnnotationField) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("StructuredAnnotationField(%+v)", *p)
+}
+
 type Type struct {
 	Name        string      `thrift:"Name,1" json:"Name"`
 	KeyType     *Type       `thrift:"KeyType,2,optional" json:"KeyType,omitempty"`
@@ -467,6 +636,7 @@ type Namespace struct {
 	Language    string      `thrift:"Language,1" json:"Language"`
 	Name        string      `thrift:"Name,2" json:"Name"`
 	Annotations Annotations `thrift:"Annotations,3" json:"Annotations"`
+	Position    *Position   `thrift:"Position,4,optional" json:"Position,omitempty"`
 }
 
 func init() {
@@ -475,7 +645,7 @@ func init() {
 		0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73,
 		0x74, 0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3,
-		0xc, 0x0, 0x0, 0x0, 0x3, 0x6, 0x0, 0x1,
+		0xc, 0x0, 0x0, 0x0, 0x4, 0x6, 0x0, 0x1,
 		0x0, 0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
 		0x8, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
 		0x65, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
@@ -491,7 +661,12 @@ func init() {
 		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
 		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc,
 		0x0, 0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
-		0xc, 0x0, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x4, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x8,
+		0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x2, 0xc,
+		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -511,6 +686,19 @@ func (p *Namespace) GetAnnotations() (v Annotations) {
 	return p.Annotations
 }
 
+var Namespace_Position_DEFAULT *Position
+
+func (p *Namespace) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Namespace_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Namespace) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Namespace) String() string {
 	if p == nil {
 		return "<nil>"
@@ -519,10 +707,12 @@ func (p *Namespace) String() string {
 }
 
 type Typedef struct {
-	Type             *Type       `thrift:"Type,1,optional" json:"Type,omitempty"`
-	Alias            string      `thrift:"Alias,2" json:"Alias"`
-	Annotations      Annotations `thrift:"Annotations,3" json:"Annotations"`
-	ReservedComments string      `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Type                  *Type                 `thrift:"Type,1,optional" json:"Type,omitempty"`
+	Alias                 string                `thrift:"Alias,2" json:"Alias"`
+	Annotations           Annotations           `thrift:"Annotations,3" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,5,optional" json:"Position,omitempty"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,6" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -531,7 +721,7 @@ func init() {
 		0x79, 0x70, 0x65, 0x64, 0x65, 0x66, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74, 0x72,
 		0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc, 0x0,
-		0x0, 0x0, 0x4, 0x6, 0x0, 0x1, 0x0, 0x1,
+		0x0, 0x0, 0x6, 0x6, 0x0, 0x1, 0x0, 0x1,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4, 0x54,
 		0x79, 0x70, 0x65, 0x8, 0x0, 0x3, 0x0, 0x0,
 		0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
@@ -552,7 +742,20 @@ func init() {
 		0x76, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x65,
 		0x6e, 0x74, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0,
 		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
-		0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x0,
+		0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x6, 0x0,
+		0x1, 0x0, 0x5, 0xb, 0x0, 0x2, 0x0, 0x0,
+		0x0, 0x8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69,
+		0x6f, 0x6e, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
+		0x2, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x0, 0x6, 0x0, 0x1,
+		0x0, 0x6, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
+		0x15, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75,
+		0x72, 0x65, 0x64, 0x41, 0x6e, 0x6e, 0x6f, 0x74,
+		0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc,
+		0x0, 0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -581,10 +784,27 @@ func (p *Typedef) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Typedef_Position_DEFAULT *Position
+
+func (p *Typedef) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Typedef_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Typedef) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
 func (p *Typedef) IsSetType() bool {
 	return p.Type != nil
 }
 
+func (p *Typedef) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Typedef) String() string {
 	if p == nil {
 		return "<nil>"
@@ -597,6 +817,7 @@ type EnumValue struct {
 	Value            int64       `thrift:"Value,2" json:"Value"`
 	Annotations      Annotations `thrift:"Annotations,3" json:"Annotations"`
 	ReservedComments string      `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Position         *Position   `thrift:"Position,5,optional" json:"Position,omitempty"`
 }
 
 func init() {
@@ -605,7 +826,7 @@ func init() {
 		0x6e, 0x75, 0x6d, 0x56, 0x61, 0x6c, 0x75, 0x65,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73,
 		0x74, 0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3,
-		0xc, 0x0, 0x0, 0x0, 0x4, 0x6, 0x0, 0x1,
+		0xc, 0x0, 0x0, 0x0, 0x5, 0x6, 0x0, 0x1,
 		0x0, 0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
 		0x4, 0x4e, 0x61, 0x6d, 0x65, 0x8, 0x0, 0x3,
 		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
@@ -627,7 +848,11 @@ func init() {
 		0x6d, 0x65, 0x6e, 0x74, 0x73, 0x8, 0x0, 0x3,
 		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
 		0x0, 0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0,
-		0x0,
+		0x6, 0x0, 0x1, 0x0, 0x5, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x8, 0x50, 0x6f, 0x73, 0x69,
+		0x74, 0x69, 0x6f, 0x6e, 0x8, 0x0, 0x3, 0x0,
+		0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -651,6 +876,19 @@ func (p *EnumValue) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var EnumValue_Position_DEFAULT *Position
+
+func (p *EnumValue) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return EnumValue_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *EnumValue) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *EnumValue) String() string {
 	if p == nil {
 		return "<nil>"
@@ -659,10 +897,12 @@ func (p *EnumValue) String() string {
 }
 
 type Enum struct {
-	Name             string       `thrift:"Name,1" json:"Name"`
-	Values           []*EnumValue `thrift:"Values,2" json:"Values"`
-	Annotations      Annotations  `thrift:"Annotations,3" json:"Annotations"`
-	ReservedComments string       `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Name                  string                `thrift:"Name,1" json:"Name"`
+	Values                []*EnumValue          `thrift:"Values,2" json:"Values"`
+	Annotations           Annotations           `thrift:"Annotations,3" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,5,optional" json:"Position,omitempty"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,6" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -670,7 +910,7 @@ func init() {
 		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x4, 0x45,
 		0x6e, 0x75, 0x6d, 0xb, 0x0, 0x2, 0x0, 0x0,
 		0x0, 0x6, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
-		0xf, 0x0, 0x3, 0xc, 0x0, 0x0, 0x0, 0x4,
+		0xf, 0x0, 0x3, 0xc, 0x0, 0x0, 0x0, 0x6,
 		0x6, 0x0, 0x1, 0x0, 0x1, 0xb, 0x0, 0x2,
 		0x0, 0x0, 0x0, 0x4, 0x4e, 0x61, 0x6d, 0x65,
 		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc,
@@ -693,7 +933,20 @@ func init() {
 		0x72, 0x76, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
 		0x65, 0x6e, 0x74, 0x73, 0x8, 0x0, 0x3, 0x0,
 		0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0,
-		0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0x5, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0x8, 0x50, 0x6f, 0x73, 0x69, 0x74,
+		0x69, 0x6f, 0x6e, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x6, 0x0,
+		0x1, 0x0, 0x6, 0xb, 0x0, 0x2, 0x0, 0x0,
+		0x0, 0x15, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74,
+		0x75, 0x72, 0x65, 0x64, 0x41, 0x6e, 0x6e, 0x6f,
+		0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x8,
+		0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0,
+		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xf,
+		0xc, 0x0, 0x3, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -717,6 +970,23 @@ func (p *Enum) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Enum_Position_DEFAULT *Position
+
+func (p *Enum) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Enum_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Enum) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
+func (p *Enum) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Enum) String() string {
 	if p == nil {
 		return "<nil>"
@@ -724,6 +994,91 @@ func (p *Enum) String() string {
 	return fmt.Sprintf("Enum(%+v)", *p)
 }
 
+type Senum struct {
+	Name             string      `thrift:"Name,1" json:"Name"`
+	Values           []string    `thrift:"Values,2" json:"Values"`
+	Annotations      Annotations `thrift:"Annotations,3" json:"Annotations"`
+	ReservedComments string      `thrift:"ReservedComments,4" json:"ReservedComments"`
+	Position         *Position   `thrift:"Position,5,optional" json:"Position,omitempty"`
+}
+
+func init() {
+	meta.RegisterStruct(NewSenum, []byte{
+		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x5, 0x53,
+		0x65, 0x6e, 0x75, 0x6d, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0x6, 0x73, 0x74, 0x72, 0x75, 0x63,
+		0x74, 0xf, 0x0, 0x3, 0xc, 0x0, 0x0, 0x0,
+		0x5, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x4, 0x4e, 0x61, 0x6d,
+		0x65, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xb, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x2, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6,
+		0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc,
+		0x0, 0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xb, 0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x3, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0xb,
+		0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+		0x6f, 0x6e, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xf, 0xc, 0x0, 0x3, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
+		0x0, 0x6, 0x0, 0x1, 0x0, 0x4, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x10, 0x52, 0x65, 0x73,
+		0x65, 0x72, 0x76, 0x65, 0x64, 0x43, 0x6f, 0x6d,
+		0x6d, 0x65, 0x6e, 0x74, 0x73, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0x5, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x8, 0x50, 0x6f, 0x73, 0x69,
+		0x74, 0x69, 0x6f, 0x6e, 0x8, 0x0, 0x3, 0x0,
+		0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x0,
+	})
+}
+
+func NewSenum() *Senum {
+	return &Senum{}
+}
+
+func (p *Senum) GetName() (v string) {
+	return p.Name
+}
+
+func (p *Senum) GetValues() (v []string) {
+	return p.Values
+}
+
+func (p *Senum) GetAnnotations() (v Annotations) {
+	return p.Annotations
+}
+
+func (p *Senum) GetReservedComments() (v string) {
+	return p.ReservedComments
+}
+
+var Senum_Position_DEFAULT *Position
+
+func (p *Senum) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Senum_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Senum) IsSetPosition() bool {
+	return p.Position != nil
+}
+
+func (p *Senum) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("Senum(%+v)", *p)
+}
+
 type ConstValueExtra struct {
 	IsEnum bool   `thrift:"IsEnum,1" json:"IsEnum"`
 	Index  int32  `thrift:"Index,2" json:"Index"`
@@ -854,6 +1209,76 @@ func (p *ConstValue) IsSetExtra() bool {
 	return p.Extra != nil
 }
 
+type ConstExpr struct {
+	Op  string      `thrift:"Op,1" json:"Op"`
+	LHS *ConstValue `thrift:"LHS,2" json:"LHS"`
+	RHS *ConstValue `thrift:"RHS,3" json:"RHS"`
+}
+
+func init() {
+	meta.RegisterStruct(NewConstExpr, []byte{
+		0xb, 0x0, 0x1, 0x0, 0x0, 0x0, 0x9, 0x43,
+		0x6f, 0x6e, 0x73, 0x74, 0x45, 0x78, 0x70, 0x72,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73,
+		0x74, 0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3,
+		0xc, 0x0, 0x0, 0x0, 0x3, 0x6, 0x0, 0x1,
+		0x0, 0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
+		0x2, 0x4f, 0x70, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x6, 0x0,
+		0x1, 0x0, 0x2, 0xb, 0x0, 0x2, 0x0, 0x0,
+		0x0, 0x3, 0x4c, 0x48, 0x53, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0x3, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x3, 0x52, 0x48, 0x53, 0x8,
+		0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0,
+		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x0, 0x0,
+	})
+}
+
+func NewConstExpr() *ConstExpr {
+	return &ConstExpr{}
+}
+
+func (p *ConstExpr) GetOp() (v string) {
+	return p.Op
+}
+
+var ConstExpr_LHS_DEFAULT *ConstValue
+
+func (p *ConstExpr) GetLHS() (v *ConstValue) {
+	if !p.IsSetLHS() {
+		return ConstExpr_LHS_DEFAULT
+	}
+	return p.LHS
+}
+
+var ConstExpr_RHS_DEFAULT *ConstValue
+
+func (p *ConstExpr) GetRHS() (v *ConstValue) {
+	if !p.IsSetRHS() {
+		return ConstExpr_RHS_DEFAULT
+	}
+	return p.RHS
+}
+
+func (p *ConstExpr) IsSetLHS() bool {
+	return p.LHS != nil
+}
+
+func (p *ConstExpr) IsSetRHS() bool {
+	return p.RHS != nil
+}
+
+func (p *ConstExpr) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ConstExpr(%+v)", *p)
+}
+
 type MapConstValue struct {
 	Key   *ConstValue `thrift:"Key,1,optional" json:"Key,omitempty"`
 	Value *ConstValue `thrift:"Value,2,optional" json:"Value,omitempty"`
@@ -916,11 +1341,13 @@ func (p *MapConstValue) String() string {
 }
 
 type Constant struct {
-	Name             string      `thrift:"Name,1" json:"Name"`
-	Type             *Type       `thrift:"Type,2,optional" json:"Type,omitempty"`
-	Value            *ConstValue `thrift:"Value,3,optional" json:"Value,omitempty"`
-	Annotations      Annotations `thrift:"Annotations,4" json:"Annotations"`
-	ReservedComments string      `thrift:"ReservedComments,5" json:"ReservedComments"`
+	Name                  string                `thrift:"Name,1" json:"Name"`
+	Type                  *Type                 `thrift:"Type,2,optional" json:"Type,omitempty"`
+	Value                 *ConstValue           `thrift:"Value,3,optional" json:"Value,omitempty"`
+	Annotations           Annotations           `thrift:"Annotations,4" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,5" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,6,optional" json:"Position,omitempty"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,7" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -929,7 +1356,7 @@ func init() {
 		0x6f, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0xb,
 		0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74,
 		0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc,
-		0x0, 0x0, 0x0, 0x5, 0x6, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0x0, 0x7, 0x6, 0x0, 0x1, 0x0,
 		0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4,
 		0x4e, 0x61, 0x6d, 0x65, 0x8, 0x0, 0x3, 0x0,
 		0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0,
@@ -955,7 +1382,20 @@ func init() {
 		0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x8,
 		0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0,
 		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xb,
-		0x0, 0x0, 0x0,
+		0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x6, 0xb,
+		0x0, 0x2, 0x0, 0x0, 0x0, 0x8, 0x50, 0x6f,
+		0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0,
+		0x0, 0x6, 0x0, 0x1, 0x0, 0x7, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x15, 0x53, 0x74, 0x72,
+		0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64, 0x41,
+		0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+		0x6e, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0xf, 0xc, 0x0, 0x3, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x0,
+		0x0,
 	})
 }
 
@@ -993,6 +1433,19 @@ func (p *Constant) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Constant_Position_DEFAULT *Position
+
+func (p *Constant) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Constant_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Constant) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
 func (p *Constant) IsSetType() bool {
 	return p.Type != nil
 }
@@ -1001,6 +1454,10 @@ func (p *Constant) IsSetValue() bool {
 	return p.Value != nil
 }
 
+func (p *Constant) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Constant) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1009,13 +1466,16 @@ func (p *Constant) String() string {
 }
 
 type Field struct {
-	ID               int32       `thrift:"ID,1" json:"ID"`
-	Name             string      `thrift:"Name,2" json:"Name"`
-	Requiredness     FieldType   `thrift:"Requiredness,3" json:"Requiredness"`
-	Type             *Type       `thrift:"Type,4" json:"Type"`
-	Default          *ConstValue `thrift:"Default,5,optional" json:"Default,omitempty"`
-	Annotations      Annotations `thrift:"Annotations,6" json:"Annotations"`
-	ReservedComments string      `thrift:"ReservedComments,7" json:"ReservedComments"`
+	ID                    int32                 `thrift:"ID,1" json:"ID"`
+	Name                  string                `thrift:"Name,2" json:"Name"`
+	Requiredness          FieldType             `thrift:"Requiredness,3" json:"Requiredness"`
+	Type                  *Type                 `thrift:"Type,4" json:"Type"`
+	Default               *ConstValue           `thrift:"Default,5,optional" json:"Default,omitempty"`
+	Annotations           Annotations           `thrift:"Annotations,6" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,7" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,8,optional" json:"Position,omitempty"`
+	ImplicitID            bool                  `thrift:"ImplicitID,9" json:"ImplicitID"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,10" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -1024,7 +1484,7 @@ func init() {
 		0x69, 0x65, 0x6c, 0x64, 0xb, 0x0, 0x2, 0x0,
 		0x0, 0x0, 0x6, 0x73, 0x74, 0x72, 0x75, 0x63,
 		0x74, 0xf, 0x0, 0x3, 0xc, 0x0, 0x0, 0x0,
-		0x7, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb, 0x0,
+		0xa, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x2, 0x49, 0x44, 0x8,
 		0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0,
 		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0x8,
@@ -1059,7 +1519,25 @@ func init() {
 		0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e,
 		0x74, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
 		0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0,
-		0x0, 0x0, 0xb, 0x0, 0x0, 0x0,
+		0x0, 0x0, 0xb, 0x0, 0x0, 0x6, 0x0, 0x1,
+		0x0, 0x8, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
+		0x8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+		0x6e, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x2,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x9, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0xa,
+		0x49, 0x6d, 0x70, 0x6c, 0x69, 0x63, 0x69, 0x74,
+		0x49, 0x44, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0x2, 0x0, 0x0, 0x6, 0x0, 0x1,
+		0x0, 0xa, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0,
+		0x15, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x75,
+		0x72, 0x65, 0x64, 0x41, 0x6e, 0x6e, 0x6f, 0x74,
+		0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc,
+		0x0, 0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -1105,6 +1583,23 @@ func (p *Field) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Field_Position_DEFAULT *Position
+
+func (p *Field) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Field_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Field) GetImplicitID() (v bool) {
+	return p.ImplicitID
+}
+
+func (p *Field) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
 func (p *Field) IsSetType() bool {
 	return p.Type != nil
 }
@@ -1113,6 +1608,10 @@ func (p *Field) IsSetDefault() bool {
 	return p.Default != nil
 }
 
+func (p *Field) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Field) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1121,11 +1620,15 @@ func (p *Field) String() string {
 }
 
 type StructLike struct {
-	Category         string      `thrift:"Category,1" json:"Category"`
-	Name             string      `thrift:"Name,2" json:"Name"`
-	Fields           []*Field    `thrift:"Fields,3" json:"Fields"`
-	Annotations      Annotations `thrift:"Annotations,4" json:"Annotations"`
-	ReservedComments string      `thrift:"ReservedComments,5" json:"ReservedComments"`
+	Category              string                `thrift:"Category,1" json:"Category"`
+	Name                  string                `thrift:"Name,2" json:"Name"`
+	Fields                []*Field              `thrift:"Fields,3" json:"Fields"`
+	Annotations           Annotations           `thrift:"Annotations,4" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,5" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,6,optional" json:"Position,omitempty"`
+	ReservedIDs           []int32               `thrift:"ReservedIDs,7" json:"ReservedIDs"`
+	ReservedNames         []string              `thrift:"ReservedNames,8" json:"ReservedNames"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,9" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -1134,7 +1637,7 @@ func init() {
 		0x74, 0x72, 0x75, 0x63, 0x74, 0x4c, 0x69, 0x6b,
 		0x65, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x6,
 		0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0xf, 0x0,
-		0x3, 0xc, 0x0, 0x0, 0x0, 0x5, 0x6, 0x0,
+		0x3, 0xc, 0x0, 0x0, 0x0, 0x9, 0x6, 0x0,
 		0x1, 0x0, 0x1, 0xb, 0x0, 0x2, 0x0, 0x0,
 		0x0, 0x8, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f,
 		0x72, 0x79, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
@@ -1162,7 +1665,33 @@ func init() {
 		0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
 		0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
 		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
-		0x0, 0xb, 0x0, 0x0, 0x0,
+		0x0, 0xb, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x6, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x8,
+		0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x2, 0xc,
+		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x7,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0xb, 0x52,
+		0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x49,
+		0x44, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0,
+		0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0xf, 0xc, 0x0, 0x3, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0x8, 0x0, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0x8, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0xd, 0x52, 0x65, 0x73, 0x65,
+		0x72, 0x76, 0x65, 0x64, 0x4e, 0x61, 0x6d, 0x65,
+		0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xf, 0xc, 0x0, 0x3, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0x9, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0x15, 0x53, 0x74, 0x72, 0x75, 0x63,
+		0x74, 0x75, 0x72, 0x65, 0x64, 0x41, 0x6e, 0x6e,
+		0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xf, 0xc, 0x0, 0x3, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -1190,6 +1719,31 @@ func (p *StructLike) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var StructLike_Position_DEFAULT *Position
+
+func (p *StructLike) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return StructLike_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *StructLike) GetReservedIDs() (v []int32) {
+	return p.ReservedIDs
+}
+
+func (p *StructLike) GetReservedNames() (v []string) {
+	return p.ReservedNames
+}
+
+func (p *StructLike) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
+func (p *StructLike) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *StructLike) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1198,14 +1752,17 @@ func (p *StructLike) String() string {
 }
 
 type Function struct {
-	Name             string      `thrift:"Name,1" json:"Name"`
-	Oneway           bool        `thrift:"Oneway,2" json:"Oneway"`
-	Void             bool        `thrift:"Void,3" json:"Void"`
-	FunctionType     *Type       `thrift:"FunctionType,4,optional" json:"FunctionType,omitempty"`
-	Arguments        []*Field    `thrift:"Arguments,5" json:"Arguments"`
-	Throws           []*Field    `thrift:"Throws,6" json:"Throws"`
-	Annotations      Annotations `thrift:"Annotations,7" json:"Annotations"`
-	ReservedComments string      `thrift:"ReservedComments,8" json:"ReservedComments"`
+	Name                  string                `thrift:"Name,1" json:"Name"`
+	Oneway                bool                  `thrift:"Oneway,2" json:"Oneway"`
+	Void                  bool                  `thrift:"Void,3" json:"Void"`
+	FunctionType          *Type                 `thrift:"FunctionType,4,optional" json:"FunctionType,omitempty"`
+	Arguments             []*Field              `thrift:"Arguments,5" json:"Arguments"`
+	Throws                []*Field              `thrift:"Throws,6" json:"Throws"`
+	Annotations           Annotations           `thrift:"Annotations,7" json:"Annotations"`
+	ReservedComments      string                `thrift:"ReservedComments,8" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,9,optional" json:"Position,omitempty"`
+	SinkType              *Type                 `thrift:"SinkType,10,optional" json:"SinkType,omitempty"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,11" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -1214,7 +1771,7 @@ func init() {
 		0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0xb,
 		0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74,
 		0x72, 0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc,
-		0x0, 0x0, 0x0, 0x8, 0x6, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0x0, 0xb, 0x6, 0x0, 0x1, 0x0,
 		0x1, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4,
 		0x4e, 0x61, 0x6d, 0x65, 0x8, 0x0, 0x3, 0x0,
 		0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0,
@@ -1258,7 +1815,24 @@ func init() {
 		0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x8, 0x0,
 		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
 		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xb, 0x0,
-		0x0, 0x0,
+		0x0, 0x6, 0x0, 0x1, 0x0, 0x9, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x8, 0x50, 0x6f, 0x73,
+		0x69, 0x74, 0x69, 0x6f, 0x6e, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0xa, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x8, 0x53, 0x69, 0x6e, 0x6b,
+		0x54, 0x79, 0x70, 0x65, 0x8, 0x0, 0x3, 0x0,
+		0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0xb, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0x15, 0x53, 0x74, 0x72, 0x75, 0x63,
+		0x74, 0x75, 0x72, 0x65, 0x64, 0x41, 0x6e, 0x6e,
+		0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xf, 0xc, 0x0, 0x3, 0x8, 0x0, 0x1, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -1303,10 +1877,40 @@ func (p *Function) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Function_Position_DEFAULT *Position
+
+func (p *Function) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Function_Position_DEFAULT
+	}
+	return p.Position
+}
+
+var Function_SinkType_DEFAULT *Type
+
+func (p *Function) GetSinkType() (v *Type) {
+	if !p.IsSetSinkType() {
+		return Function_SinkType_DEFAULT
+	}
+	return p.SinkType
+}
+
+func (p *Function) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
 func (p *Function) IsSetFunctionType() bool {
 	return p.FunctionType != nil
 }
 
+func (p *Function) IsSetPosition() bool {
+	return p.Position != nil
+}
+
+func (p *Function) IsSetSinkType() bool {
+	return p.SinkType != nil
+}
+
 func (p *Function) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1315,12 +1919,14 @@ func (p *Function) String() string {
 }
 
 type Service struct {
-	Name             string      `thrift:"Name,1" json:"Name"`
-	Extends          string      `thrift:"Extends,2" json:"Extends"`
-	Functions        []*Function `thrift:"Functions,3" json:"Functions"`
-	Annotations      Annotations `thrift:"Annotations,4" json:"Annotations"`
-	Reference        *Reference  `thrift:"Reference,5,optional" json:"Reference,omitempty"`
-	ReservedComments string      `thrift:"ReservedComments,6" json:"ReservedComments"`
+	Name                  string                `thrift:"Name,1" json:"Name"`
+	Extends               string                `thrift:"Extends,2" json:"Extends"`
+	Functions             []*Function           `thrift:"Functions,3" json:"Functions"`
+	Annotations           Annotations           `thrift:"Annotations,4" json:"Annotations"`
+	Reference             *Reference            `thrift:"Reference,5,optional" json:"Reference,omitempty"`
+	ReservedComments      string                `thrift:"ReservedComments,6" json:"ReservedComments"`
+	Position              *Position             `thrift:"Position,7,optional" json:"Position,omitempty"`
+	StructuredAnnotations StructuredAnnotations `thrift:"StructuredAnnotations,8" json:"StructuredAnnotations"`
 }
 
 func init() {
@@ -1329,7 +1935,7 @@ func init() {
 		0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74, 0x72,
 		0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc, 0x0,
-		0x0, 0x0, 0x6, 0x6, 0x0, 0x1, 0x0, 0x1,
+		0x0, 0x0, 0x8, 0x6, 0x0, 0x1, 0x0, 0x1,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4, 0x4e,
 		0x61, 0x6d, 0x65, 0x8, 0x0, 0x3, 0x0, 0x0,
 		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
@@ -1362,7 +1968,20 @@ func init() {
 		0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73,
 		0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0xc,
 		0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
-		0xb, 0x0, 0x0, 0x0,
+		0xb, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x7,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x8, 0x50,
+		0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x8,
+		0x0, 0x3, 0x0, 0x0, 0x0, 0x2, 0xc, 0x0,
+		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
+		0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x8, 0xb,
+		0x0, 0x2, 0x0, 0x0, 0x0, 0x15, 0x53, 0x74,
+		0x72, 0x75, 0x63, 0x74, 0x75, 0x72, 0x65, 0x64,
+		0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69,
+		0x6f, 0x6e, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xf, 0xc, 0x0, 0x3, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
+		0x0, 0x0,
 	})
 }
 
@@ -1399,10 +2018,27 @@ func (p *Service) GetReservedComments() (v string) {
 	return p.ReservedComments
 }
 
+var Service_Position_DEFAULT *Position
+
+func (p *Service) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Service_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Service) GetStructuredAnnotations() (v StructuredAnnotations) {
+	return p.StructuredAnnotations
+}
+
 func (p *Service) IsSetReference() bool {
 	return p.Reference != nil
 }
 
+func (p *Service) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Service) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1411,9 +2047,12 @@ func (p *Service) String() string {
 }
 
 type Include struct {
-	Path      string  `thrift:"Path,1" json:"Path"`
-	Reference *Thrift `thrift:"Reference,2,optional" json:"Reference,omitempty"`
-	Used      *bool   `thrift:"Used,3,optional" json:"Used,omitempty"`
+	Path         string    `thrift:"Path,1" json:"Path"`
+	Reference    *Thrift   `thrift:"Reference,2,optional" json:"Reference,omitempty"`
+	Used         *bool     `thrift:"Used,3,optional" json:"Used,omitempty"`
+	Position     *Position `thrift:"Position,4,optional" json:"Position,omitempty"`
+	Alias        string    `thrift:"Alias,5" json:"Alias"`
+	ResolvedPath string    `thrift:"ResolvedPath,6" json:"ResolvedPath"`
 }
 
 func init() {
@@ -1422,7 +2061,7 @@ func init() {
 		0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74, 0x72,
 		0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc, 0x0,
-		0x0, 0x0, 0x3, 0x6, 0x0, 0x1, 0x0, 0x1,
+		0x0, 0x0, 0x6, 0x6, 0x0, 0x1, 0x0, 0x1,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4, 0x50,
 		0x61, 0x74, 0x68, 0x8, 0x0, 0x3, 0x0, 0x0,
 		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
@@ -1436,7 +2075,21 @@ func init() {
 		0x0, 0x4, 0x55, 0x73, 0x65, 0x64, 0x8, 0x0,
 		0x3, 0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4,
 		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0x2, 0x0,
-		0x0, 0x0,
+		0x0, 0x6, 0x0, 0x1, 0x0, 0x4, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x8, 0x50, 0x6f, 0x73,
+		0x69, 0x74, 0x69, 0x6f, 0x6e, 0x8, 0x0, 0x3,
+		0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
+		0x6, 0x0, 0x1, 0x0, 0x5, 0xb, 0x0, 0x2,
+		0x0, 0x0, 0x0, 0x5, 0x41, 0x6c, 0x69, 0x61,
+		0x73, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xb, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0,
+		0x6, 0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0xc,
+		0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64,
+		0x50, 0x61, 0x74, 0x68, 0x8, 0x0, 0x3, 0x0,
+		0x0, 0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0,
+		0x1, 0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -1466,6 +2119,23 @@ func (p *Include) GetUsed() (v bool) {
 	return *p.Used
 }
 
+var Include_Position_DEFAULT *Position
+
+func (p *Include) GetPosition() (v *Position) {
+	if !p.IsSetPosition() {
+		return Include_Position_DEFAULT
+	}
+	return p.Position
+}
+
+func (p *Include) GetAlias() (v string) {
+	return p.Alias
+}
+
+func (p *Include) GetResolvedPath() (v string) {
+	return p.ResolvedPath
+}
+
 func (p *Include) IsSetReference() bool {
 	return p.Reference != nil
 }
@@ -1474,6 +2144,10 @@ func (p *Include) IsSetUsed() bool {
 	return p.Used != nil
 }
 
+func (p *Include) IsSetPosition() bool {
+	return p.Position != nil
+}
+
 func (p *Include) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1494,6 +2168,7 @@ type Thrift struct {
 	Exceptions    []*StructLike       `thrift:"Exceptions,10" json:"Exceptions"`
 	Services      []*Service          `thrift:"Services,11" json:"Services"`
 	Name2Category map[string]Category `thrift:"Name2Category,12" json:"Name2Category"`
+	Senums        []*Senum            `thrift:"Senums,13" json:"Senums"`
 }
 
 func init() {
@@ -1502,7 +2177,7 @@ func init() {
 		0x68, 0x72, 0x69, 0x66, 0x74, 0xb, 0x0, 0x2,
 		0x0, 0x0, 0x0, 0x6, 0x73, 0x74, 0x72, 0x75,
 		0x63, 0x74, 0xf, 0x0, 0x3, 0xc, 0x0, 0x0,
-		0x0, 0xc, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb,
+		0x0, 0xd, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb,
 		0x0, 0x2, 0x0, 0x0, 0x0, 0x8, 0x46, 0x69,
 		0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x8, 0x0,
 		0x3, 0x0, 0x0, 0x0, 0x0, 0xc, 0x0, 0x4,
@@ -1578,6 +2253,12 @@ func init() {
 		0xd, 0xc, 0x0, 0x2, 0x8, 0x0, 0x1, 0x0,
 		0x0, 0x0, 0xb, 0x0, 0xc, 0x0, 0x3, 0x8,
 		0x0, 0x1, 0x0, 0x0, 0x0, 0x8, 0x0, 0x0,
+		0x0, 0x6, 0x0, 0x1, 0x0, 0xd, 0xb, 0x0,
+		0x2, 0x0, 0x0, 0x0, 0x6, 0x53, 0x65, 0x6e,
+		0x75, 0x6d, 0x73, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x0, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xf, 0xc, 0x0, 0x3, 0x8,
+		0x0, 0x1, 0x0, 0x0, 0x0, 0xc, 0x0, 0x0,
 		0x0, 0x0,
 	})
 }
@@ -1634,6 +2315,10 @@ func (p *Thrift) GetName2Category() (v map[string]Category) {
 	return p.Name2Category
 }
 
+func (p *Thrift) GetSenums() (v []*Senum) {
+	return p.Senums
+}
+
 func (p *Thrift) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1648,6 +2333,7 @@ type ConstTypedValue struct {
 	Identifier *string          `thrift:"Identifier,4,optional" json:"Identifier,omitempty"`
 	List       []*ConstValue    `thrift:"List,5,optional" json:"List,omitempty"`
 	Map        []*MapConstValue `thrift:"Map,6,optional" json:"Map,omitempty"`
+	Expr       *ConstExpr       `thrift:"Expr,7,optional" json:"Expr,omitempty"`
 }
 
 func init() {
@@ -1657,7 +2343,7 @@ func init() {
 		0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x5, 0x75, 0x6e, 0x69,
 		0x6f, 0x6e, 0xf, 0x0, 0x3, 0xc, 0x0, 0x0,
-		0x0, 0x6, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb,
+		0x0, 0x7, 0x6, 0x0, 0x1, 0x0, 0x1, 0xb,
 		0x0, 0x2, 0x0, 0x0, 0x0, 0x6, 0x44, 0x6f,
 		0x75, 0x62, 0x6c, 0x65, 0x8, 0x0, 0x3, 0x0,
 		0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0,
@@ -1687,7 +2373,11 @@ func init() {
 		0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4, 0x8,
 		0x0, 0x1, 0x0, 0x0, 0x0, 0xf, 0xc, 0x0,
 		0x3, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
-		0x0, 0x0, 0x0, 0x0,
+		0x0, 0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x7,
+		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x4, 0x45,
+		0x78, 0x70, 0x72, 0x8, 0x0, 0x3, 0x0, 0x0,
+		0x0, 0x2, 0xc, 0x0, 0x4, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xc, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -1749,6 +2439,15 @@ func (p *ConstTypedValue) GetMap() (v []*MapConstValue) {
 	return p.Map
 }
 
+var ConstTypedValue_Expr_DEFAULT *ConstExpr
+
+func (p *ConstTypedValue) GetExpr() (v *ConstExpr) {
+	if !p.IsSetExpr() {
+		return ConstTypedValue_Expr_DEFAULT
+	}
+	return p.Expr
+}
+
 func (p *ConstTypedValue) CountSetFieldsConstTypedValue() int {
 	count := 0
 	if p.IsSetDouble() {
@@ -1769,6 +2468,9 @@ func (p *ConstTypedValue) CountSetFieldsConstTypedValue() int {
 	if p.IsSetMap() {
 		count++
 	}
+	if p.IsSetExpr() {
+		count++
+	}
 	return count
 }
 
@@ -1796,6 +2498,10 @@ func (p *ConstTypedValue) IsSetMap() bool {
 	return p.Map != nil
 }
 
+func (p *ConstTypedValue) IsSetExpr() bool {
+	return p.Expr != nil
+}
+
 func (p *ConstTypedValue) String() string {
 	if p == nil {
 		return "<nil>"
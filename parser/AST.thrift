@@ -15,6 +15,7 @@
 namespace * parser
 
 typedef list<Annotation> Annotations
+typedef list<StructuredAnnotation> StructuredAnnotations
 
 enum Category {
     Constant
@@ -42,11 +43,30 @@ struct Reference {
     2: i32 Index   // The index of the included IDL that contains the referenced type
 }
 
+// Position is the location of a definition in the IDL file that contains it.
+struct Position {
+    1: i32 Line // 1-based line number
+    2: i32 Col  // 1-based column number counted in characters
+}
+
 struct Annotation {
     1: string Key
     2: list<string> Values
 }
 
+// StructuredAnnotation is an annotation of fbthrift written before a definition, a field or a function,
+// such as @cpp.Type{name = "folly::IOBuf"}.
+struct StructuredAnnotation {
+    1: string Name                               // the type of the annotation as written, with its selector if any
+    2: list<StructuredAnnotationField> Fields    // in the order of the IDL, empty if the annotation has no body
+    3: optional Position Position                // points at '@'
+}
+
+struct StructuredAnnotationField {
+    1: string Name
+    2: ConstValue Value // kept as written, the identifiers in it are not resolved
+}
+
 struct Type {
     1: string Name                     // base types | container types | identifier | selector
     2: optional Type KeyType           // if Name is 'map'
@@ -62,6 +82,7 @@ struct Namespace {
     1: string Language
     2: string Name
     3: Annotations Annotations
+    4: optional Position Position // points at the keyword
 }
 
 struct Typedef {
@@ -69,6 +90,8 @@ struct Typedef {
     2: string Alias
     3: Annotations Annotations
     4: string ReservedComments
+    5: optional Position Position // points at the keyword
+    6: StructuredAnnotations StructuredAnnotations
 }
 
 struct EnumValue {
@@ -76,6 +99,7 @@ struct EnumValue {
     2: i64 Value
     3: Annotations Annotations
     4: string ReservedComments
+    5: optional Position Position // points at the name
 }
 
 struct Enum {
@@ -83,6 +107,17 @@ struct Enum {
     2: list<EnumValue> Values
     3: Annotations Annotations
     4: string ReservedComments
+    5: optional Position Position // points at the keyword
+    6: StructuredAnnotations StructuredAnnotations
+}
+
+// Senum is the legacy string enum, whose values are string literals.
+struct Senum {
+    1: string Name
+    2: list<string> Values
+    3: Annotations Annotations
+    4: string ReservedComments
+    5: optional Position Position // points at the keyword
 }
 
 enum ConstType {
@@ -92,6 +127,7 @@ enum ConstType {
     ConstIdentifier
     ConstList
     ConstMap
+    ConstExpr
 }
 
 // ConstValueExtra provides extra information when the Type of a ConstValue is ConstIdentifier.
@@ -115,6 +151,15 @@ union ConstTypedValue {
     4: string Identifier
     5: list<ConstValue> List
     6: list<MapConstValue> Map
+    7: ConstExpr Expr
+}
+
+// ConstExpr is a binary operation in a constant expression. The semantic checker
+// evaluates it and replaces the ConstValue holding it with a ConstInt.
+struct ConstExpr {
+    1: string Op      // one of "+", "-", "|" and "&"
+    2: ConstValue LHS
+    3: ConstValue RHS
 }
 
 struct MapConstValue {
@@ -128,6 +173,8 @@ struct Constant {
     3: optional ConstValue Value
     4: Annotations Annotations
     5: string ReservedComments
+    6: optional Position Position // points at the keyword
+    7: StructuredAnnotations StructuredAnnotations
 }
 
 enum FieldType {
@@ -144,6 +191,9 @@ struct Field {
     5: optional ConstValue Default // ConstValue
     6: Annotations Annotations
     7: string ReservedComments
+    8: optional Position Position // points at the field ID or the first token if the ID is absent
+    9: bool ImplicitID // the IDL does not give the ID, which is the ID of the previous field plus 1
+    10: StructuredAnnotations StructuredAnnotations
 }
 
 struct StructLike {
@@ -152,6 +202,10 @@ struct StructLike {
     3: list<Field> Fields
     4: Annotations Annotations
     5: string ReservedComments
+    6: optional Position Position // points at the keyword
+    7: list<i32> ReservedIDs      // field IDs declared by 'reserved' statements
+    8: list<string> ReservedNames // field names declared by 'reserved' statements
+    9: StructuredAnnotations StructuredAnnotations
 }
 
 struct Function {
@@ -163,6 +217,9 @@ struct Function {
     6: list<Field> Throws
     7: Annotations Annotations
     8: string ReservedComments
+    9: optional Position Position // points at 'oneway' or the response type
+    10: optional Type SinkType // the type of the elements that the client sends to a sink<T, R> of fbthrift
+    11: StructuredAnnotations StructuredAnnotations
 }
 
 struct Service {
@@ -176,12 +233,17 @@ struct Service {
     5: optional Reference Reference
 
     6: string ReservedComments
+    7: optional Position Position // points at the keyword
+    8: StructuredAnnotations StructuredAnnotations
 }
 
 struct Include {
-    1: string Path               // The path literal in the include statement.
-    2: optional Thrift Reference // The parsed AST of the included IDL.
-    3: optional bool Used        // If this include is used in the IDL
+    1: string Path                // The path literal in the include statement.
+    2: optional Thrift Reference  // The parsed AST of the included IDL.
+    3: optional bool Used         // If this include is used in the IDL
+    4: optional Position Position // points at the keyword
+    5: string Alias               // The name given by 'as', which replaces the file name in references.
+    6: string ResolvedPath        // The path of the included IDL found by the search, empty if not resolved.
 }
 
 // Thrift is the AST of the current IDL with symbols sorted.
@@ -200,4 +262,6 @@ struct Thrift {
 
     // Name2Category keeps a mapping for all global names with their **direct** category.
     12: map<string, Category> Name2Category
+
+    13: list<Senum> Senums // Kept for diagnostics and translations, see semantic.Options.
 }
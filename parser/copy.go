@@ -0,0 +1,79 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import "reflect"
+
+// DeepCopy returns a copy of the AST and the ASTs it includes, which can be
+// modified without affecting t. The nodes shared in t, e.g. an IDL included by
+// several others, are shared in the copy as well. Positions are not copied since
+// they are never modified.
+func (t *Thrift) DeepCopy() *Thrift {
+	c := &copier{copied: make(map[pointerKey]reflect.Value)}
+	return c.copy(reflect.ValueOf(t)).Interface().(*Thrift)
+}
+
+var positionType = reflect.TypeOf((*Position)(nil))
+
+type pointerKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type copier struct {
+	copied map[pointerKey]reflect.Value // the copies of the pointers met so far
+}
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type() == positionType {
+			return v
+		}
+		key := pointerKey{v.Pointer(), v.Type()}
+		if cp, ok := c.copied[key]; ok {
+			return cp
+		}
+		cp := reflect.New(v.Type().Elem())
+		c.copied[key] = cp
+		cp.Elem().Set(c.copy(v.Elem()))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			cp.Field(i).Set(c.copy(v.Field(i)))
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			cp.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return cp
+	}
+	return v
+}
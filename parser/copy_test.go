@@ -0,0 +1,52 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestDeepCopy(t *testing.T) {
+	base, err := ParseString("base.thrift", "struct Base { 1: i32 id }")
+	test.Assert(t, err == nil, err)
+	other, err := ParseString("other.thrift", `include "base.thrift"`)
+	test.Assert(t, err == nil, err)
+	ast, err := ParseString("main.thrift", `
+include "base.thrift"
+include "other.thrift"
+struct S { 1: base.Base x (k = "v") 2: list<base.Base> y }
+service Svc { void f(1: S s) }`)
+	test.Assert(t, err == nil, err)
+	ast.Includes[0].Reference, ast.Includes[1].Reference = base, other
+	other.Includes[0].Reference = base
+
+	cp := ast.DeepCopy()
+	test.Assert(t, reflect.DeepEqual(cp, ast))
+	test.Assert(t, cp != ast && cp.Structs[0] != ast.Structs[0] && cp.Structs[0].Fields[0] != ast.Structs[0].Fields[0])
+	// the shared nodes are still shared
+	ref := cp.Includes[0].Reference
+	test.Assert(t, ref != base && ref == cp.Includes[1].Reference.Includes[0].Reference)
+	test.Assert(t, cp.Structs[0].Position == ast.Structs[0].Position)
+
+	cp.Structs[0].Fields = cp.Structs[0].Fields[:1]
+	cp.Structs[0].Fields[0].Annotations[0].Values[0] = "w"
+	ref.Structs = nil
+	cp.Services[0].Functions = nil
+	test.Assert(t, len(ast.Structs[0].Fields) == 2 && ast.Structs[0].Fields[0].Annotations[0].Values[0] == "v")
+	test.Assert(t, len(base.Structs) == 1 && len(ast.Services[0].Functions) == 1)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dialect is a variant of the thrift IDL. The constructs of the other dialects
+// are reported as errors when an IDL is parsed.
+type Dialect int
+
+// The dialects of the thrift IDL.
+const (
+	// DialectApache is the IDL of Apache Thrift, which is the default. It has the
+	// legacy senums.
+	DialectApache Dialect = iota
+	// DialectFB is the IDL of fbthrift. It has the stream<T> and sink<T, R>
+	// response types of functions and the structured annotations, e.g.
+	// @cpp.Type{name = "folly::IOBuf"}.
+	DialectFB
+)
+
+var dialectNames = []string{"apache", "fb"}
+
+// String implements the flag.Value interface.
+func (d Dialect) String() string {
+	if d < 0 || int(d) >= len(dialectNames) {
+		return fmt.Sprintf("Dialect(%d)", int(d))
+	}
+	return dialectNames[d]
+}
+
+// Set implements the flag.Value interface.
+func (d *Dialect) Set(value string) error {
+	for i, n := range dialectNames {
+		if n == value {
+			*d = Dialect(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("expect one of %s, got %q", strings.Join(dialectNames, ", "), value)
+}
+
+var dialect = struct {
+	sync.Mutex
+	d Dialect
+}{}
+
+// SetDialect changes the dialect that the IDLs are parsed in.
+func SetDialect(d Dialect) {
+	dialect.Lock()
+	defer dialect.Unlock()
+	dialect.d = d
+}
+
+// CurrentDialect returns the dialect that the IDLs are parsed in.
+func CurrentDialect() Dialect {
+	dialect.Lock()
+	defer dialect.Unlock()
+	return dialect.d
+}
+
+// checkDialect reports an error if the construct at node is not in the dialect
+// being parsed.
+func (p *parser) checkDialect(node *node32, construct string, in Dialect) error {
+	if p.dialect == in {
+		return nil
+	}
+	pos := p.position(node)
+	return fmt.Errorf("%d:%d: %s is not supported in dialect %s, it is a construct of dialect %s",
+		pos.Line, pos.Col, construct, p.dialect, in)
+}
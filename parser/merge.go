@@ -0,0 +1,255 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ParseFiles parses the thrift files and the IDLs they include recursively. An
+// IDL included by several of them is parsed once and shared by their ASTs.
+func ParseFiles(paths []string, includeDirs []string) ([]*Thrift, error) {
+	thriftMap := make(map[string]*Thrift)
+	var asts []*Thrift
+	for _, path := range paths {
+		t, err := parseFileRecursively(path, "", includeDirs, thriftMap)
+		if err != nil {
+			return nil, err
+		}
+		asts = append(asts, t)
+	}
+	return asts, nil
+}
+
+// Merge combines the ASTs of several IDLs into a single one, as if their
+// definitions were written in the first IDL, so that they can refer to each
+// other without including each other. The ASTs must not have been checked by
+// the semantic package yet.
+//
+// The merged AST has the filename of the first IDL, the includes of all the
+// IDLs and their definitions in the order of the IDLs. Includes of the same IDL
+// are kept once. Each definition remembers the IDL it comes from, which Locate
+// reports for its positions. It is an error if
+//   - a name is defined in more than one IDL,
+//   - the IDLs declare different namespaces for the same language,
+//   - an include name refers to different IDLs, or
+//   - one of the IDLs is included by the others, directly or not.
+func Merge(asts ...*Thrift) (*Thrift, error) {
+	if len(asts) == 0 {
+		return nil, errors.New("no IDL to merge")
+	}
+	merged := make(map[*Thrift]bool)
+	for _, t := range asts {
+		merged[t] = true
+	}
+	m := &merger{
+		Thrift:   &Thrift{Filename: asts[0].Filename},
+		defs:     make(map[string]string),
+		includes: make(map[string]*Include),
+		ns:       make(map[string]*Namespace),
+	}
+	for _, t := range asts {
+		for _, inc := range t.Includes {
+			if path := searchMerged(inc.Reference, merged, []string{t.Filename}); path != "" {
+				return nil, fmt.Errorf("%s can not be merged with the IDLs that include it: %s", inc.Reference.Filename, path)
+			}
+		}
+	}
+	for _, t := range asts {
+		if err := m.add(t); err != nil {
+			return nil, err
+		}
+	}
+	h := sha256.New()
+	for _, t := range asts {
+		sum, ok := t.SourceDigest()
+		if !ok {
+			return m.Thrift, nil
+		}
+		h.Write(sum)
+	}
+	digests.Store(m.Filename, h.Sum(nil))
+	return m.Thrift, nil
+}
+
+// searchMerged returns the include path from the first file of nodes to an IDL in
+// merged, or an empty string if there is none.
+func searchMerged(cur *Thrift, merged map[*Thrift]bool, nodes []string) string {
+	if cur == nil {
+		return ""
+	}
+	for _, node := range nodes {
+		if node == cur.Filename { // an include circle, which is reported by CircleDetect
+			return ""
+		}
+	}
+	nodes = append(nodes, cur.Filename)
+	if merged[cur] {
+		return strings.Join(nodes, " -> ")
+	}
+	for _, inc := range cur.Includes {
+		if path := searchMerged(inc.Reference, merged, nodes); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+type merger struct {
+	*Thrift
+	defs     map[string]string   // the location of each name defined so far
+	includes map[string]*Include // by RefName
+	ns       map[string]*Namespace
+}
+
+func (m *merger) add(t *Thrift) error {
+	for _, inc := range t.Includes {
+		name := inc.RefName()
+		if prev, ok := m.includes[name]; ok {
+			if !sameInclude(prev, inc) {
+				return fmt.Errorf("%s: include %q refers to %s, but to %s in the other IDLs",
+					t.Locate(inc.Position), name, includedPath(inc), includedPath(prev))
+			}
+			continue
+		}
+		m.includes[name] = inc
+		m.Includes = append(m.Includes, inc)
+	}
+	for _, ns := range t.Namespaces {
+		if prev, ok := m.ns[ns.Language]; ok {
+			if prev.Name != ns.Name {
+				return fmt.Errorf("%s: namespace %s %s conflicts with %s in the other IDLs",
+					t.Locate(ns.Position), ns.Language, ns.Name, prev.Name)
+			}
+			continue
+		}
+		m.ns[ns.Language] = ns
+		m.Namespaces = append(m.Namespaces, ns)
+	}
+	m.CppIncludes = append(m.CppIncludes, t.CppIncludes...)
+	if err := Walk(t, VisitorFunc(func(node interface{}) error {
+		if pos := positionOf(node); pos != nil {
+			origins.Store(pos, t.Filename)
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	// the duplicates in one IDL are left to the semantic checker
+	local := make(map[string]string)
+	define := func(kind, name string, pos *Position) error {
+		if prev, ok := m.defs[name]; ok {
+			return fmt.Errorf("%s: %s %q is also defined at %s", t.Locate(pos), kind, name, prev)
+		}
+		if _, ok := local[name]; !ok {
+			local[name] = t.Locate(pos)
+		}
+		return nil
+	}
+	for _, v := range t.Typedefs {
+		if err := define("typedef", v.Alias, v.Position); err != nil {
+			return err
+		}
+	}
+	for _, v := range t.Constants {
+		if err := define("const", v.Name, v.Position); err != nil {
+			return err
+		}
+	}
+	for _, v := range t.Enums {
+		if err := define("enum", v.Name, v.Position); err != nil {
+			return err
+		}
+	}
+	for _, v := range t.Senums {
+		if err := define("senum", v.Name, v.Position); err != nil {
+			return err
+		}
+	}
+	for _, v := range t.GetStructLikes() {
+		if err := define(v.Category, v.Name, v.Position); err != nil {
+			return err
+		}
+	}
+	for _, v := range t.Services {
+		if err := define("service", v.Name, v.Position); err != nil {
+			return err
+		}
+	}
+	for name, loc := range local {
+		m.defs[name] = loc
+	}
+
+	m.Typedefs = append(m.Typedefs, t.Typedefs...)
+	m.Constants = append(m.Constants, t.Constants...)
+	m.Enums = append(m.Enums, t.Enums...)
+	m.Senums = append(m.Senums, t.Senums...)
+	m.Structs = append(m.Structs, t.Structs...)
+	m.Unions = append(m.Unions, t.Unions...)
+	m.Exceptions = append(m.Exceptions, t.Exceptions...)
+	m.Services = append(m.Services, t.Services...)
+	return nil
+}
+
+// sameInclude tells whether the includes refer to the same IDL.
+func sameInclude(a, b *Include) bool {
+	if a.Reference != nil || b.Reference != nil {
+		return a.Reference == b.Reference
+	}
+	return a.Path == b.Path
+}
+
+func includedPath(inc *Include) string {
+	if inc.ResolvedPath != "" {
+		return inc.ResolvedPath
+	}
+	return inc.Path
+}
+
+// origins keeps the filenames of the IDLs that the positions in merged ASTs come from.
+var origins sync.Map // *Position => string
+
+// positionOf returns the position of a node visited by Walk, or nil if it has none.
+func positionOf(node interface{}) *Position {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if f := v.Elem().FieldByName("Position"); f.IsValid() {
+		pos, _ := f.Interface().(*Position)
+		return pos
+	}
+	return nil
+}
+
+// Locate returns the location of a position in the IDL in the form of
+// "filename:line:col", or the filename if pos is nil. For a position in an AST
+// produced by Merge, the filename is the one of the IDL it comes from.
+func (t *Thrift) Locate(pos *Position) string {
+	if pos == nil {
+		return t.Filename
+	}
+	filename := t.Filename
+	if v, ok := origins.Load(pos); ok {
+		filename = v.(string)
+	}
+	return fmt.Sprintf("%s:%d:%d", filename, pos.Line, pos.Col)
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+func TestMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := func(p string) string { return filepath.Join(dir, p) }
+	writeFile(t, path("base.thrift"), "struct Base {}")
+	writeFile(t, path("other/base.thrift"), "struct Base {}")
+	writeFile(t, path("a.thrift"), `namespace go example
+include "base.thrift"
+struct User { 1: Role role 2: base.Base b }`)
+	writeFile(t, path("b.thrift"), `namespace go example
+namespace py example
+include "base.thrift"
+enum Role { ADMIN = 1 }
+service S { User get() }`)
+
+	parse := func(names ...string) (*parser.Thrift, error) {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, path(name))
+		}
+		asts, err := parser.ParseFiles(paths, nil)
+		if err != nil {
+			return nil, err
+		}
+		return parser.Merge(asts...)
+	}
+
+	ast, err := parse("a.thrift", "b.thrift")
+	test.Assert(t, err == nil, err)
+	test.Assert(t, filepath.Base(ast.Filename) == "a.thrift", ast.Filename)
+	// the filenames in the ASTs are normalized
+	rel := filepath.Dir(ast.Filename)
+	name := func(p string) string { return filepath.Join(rel, p) }
+	test.Assert(t, len(ast.Includes) == 1 && ast.Includes[0].Reference.Structs[0].Name == "Base", ast.Includes)
+	test.Assert(t, len(ast.Namespaces) == 2 && ast.Namespaces[1].Language == "py", ast.Namespaces)
+	test.Assert(t, ast.Structs[0].Name == "User" && ast.Enums[0].Name == "Role" && ast.Services[0].Name == "S")
+	// the positions are located in the IDLs they come from
+	test.Assert(t, ast.Locate(ast.Structs[0].Position) == name("a.thrift")+":3:1", ast.Locate(ast.Structs[0].Position))
+	test.Assert(t, ast.Locate(ast.Enums[0].Values[0].Position) == name("b.thrift")+":4:13", ast.Locate(ast.Enums[0].Values[0].Position))
+	test.Assert(t, ast.Locate(ast.Services[0].Functions[0].Position) == name("b.thrift")+":5:13")
+	test.Assert(t, ast.Locate(nil) == ast.Filename)
+
+	writeFile(t, path("h.thrift"), "\nstruct Dup { 1: i32 a 1: i32 b }")
+	ast, err = parse("a.thrift", "h.thrift")
+	test.Assert(t, err == nil, err)
+	_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	test.Assert(t, err != nil && strings.HasPrefix(err.Error(), name("h.thrift")+":2:"), err)
+
+	writeFile(t, path("c.thrift"), "typedef i32 I\n\nexception User {}")
+	_, err = parse("a.thrift", "c.thrift")
+	test.Assert(t, err != nil && err.Error() == name("c.thrift")+`:3:1: exception "User" is also defined at `+name("a.thrift")+":3:1", err)
+
+	writeFile(t, path("d.thrift"), "namespace go other")
+	_, err = parse("a.thrift", "d.thrift")
+	test.Assert(t, err != nil && err.Error() == name("d.thrift")+":1:1: namespace go other conflicts with example in the other IDLs", err)
+
+	writeFile(t, path("e.thrift"), `include "other/base.thrift"`)
+	_, err = parse("a.thrift", "e.thrift")
+	test.Assert(t, err != nil && strings.HasPrefix(err.Error(), name("e.thrift")+`:1:1: include "base" refers to `), err)
+
+	writeFile(t, path("f.thrift"), `include "base.thrift" as b`)
+	ast, err = parse("a.thrift", "f.thrift")
+	test.Assert(t, err == nil && len(ast.Includes) == 2 && ast.Includes[0].Reference == ast.Includes[1].Reference, err)
+
+	writeFile(t, path("g.thrift"), `include "a.thrift"`)
+	_, err = parse("a.thrift", "g.thrift")
+	test.Assert(t, err != nil && err.Error() == name("a.thrift")+" can not be merged with the IDLs that include it: "+
+		name("g.thrift")+" -> "+name("a.thrift"), err)
+}
@@ -17,13 +17,16 @@
 package parser
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // NOTSET is a value to express 'not set'.
@@ -34,7 +37,11 @@ type parser struct {
 	Thrift
 	IncludeDirs               []string
 	Annotations               *Annotations
+	StructuredAnnotations     *StructuredAnnotations
 	DefinitionReservedComment string
+	lineStarts                []uint32 // offsets of the beginning of each line in the buffer
+	lineOffset, colOffset     int32    // where the buffer begins in the IDL when it is a part of it
+	dialect                   Dialect
 }
 
 func exists(path string) bool {
@@ -45,17 +52,87 @@ func exists(path string) bool {
 	return !fi.IsDir()
 }
 
+// SearchOptions configures how includes are searched in the file system.
+type SearchOptions struct {
+	// Log receives a message for each include found by searching, listing the
+	// candidates in the order they are tried and the one that is used.
+	Log func(msg string)
+	// StrictDuplicates makes an include found at more than one candidate an
+	// error instead of using the first one.
+	StrictDuplicates bool
+}
+
+var searchOptions = struct {
+	sync.Mutex
+	opts SearchOptions
+}{}
+
+// SetSearchOptions changes the options for searching includes.
+func SetSearchOptions(opts SearchOptions) {
+	searchOptions.Lock()
+	defer searchOptions.Unlock()
+	searchOptions.opts = opts
+}
+
+// search finds the file included by an IDL in dir. A relative path is tried in
+// dir, then in each of the include dirs in order, and at last in the working
+// directory. The main IDL is searched with an empty dir, which stands for the
+// working directory.
 func search(file, dir string, includeDirs []string) (string, error) {
-	ps := []string{file, filepath.Join(dir, file)}
-	for _, inc := range includeDirs {
-		ps = append(ps, filepath.Join(inc, file))
+	switch {
+	case isRemote(file):
+		return fetchRemote(file)
+	case isModule(file):
+		return resolveModule(file, dir)
+	case isRemote(dir) && !filepath.IsAbs(file):
+		// relative includes in a remote IDL are resolved against its URL
+		return fetchRemote(joinURL(dir, file))
+	}
+	ps := []string{filepath.Join(dir, file)}
+	if !filepath.IsAbs(file) {
+		for _, inc := range includeDirs {
+			ps = append(ps, filepath.Join(inc, file))
+		}
+		ps = append(ps, file)
 	}
+
+	searchOptions.Lock()
+	opts := searchOptions.opts
+	searchOptions.Unlock()
+
+	var found []string
+	tried := make([]string, 0, len(ps))
+	seen := make(map[string]bool, len(ps))
 	for _, p := range ps {
-		if exists(p) {
-			return normalizeFilename(p), nil
+		p = normalizeFilename(p)
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if !exists(p) {
+			tried = append(tried, p+" (not found)")
+			continue
 		}
+		if len(found) > 0 {
+			tried = append(tried, p+" (shadowed)")
+		} else {
+			tried = append(tried, p+" (found)")
+		}
+		found = append(found, p)
+	}
+	if len(found) == 0 {
+		return file, &os.PathError{Op: "search", Path: file, Err: os.ErrNotExist}
+	}
+	if dir == "" {
+		dir = "."
 	}
-	return file, &os.PathError{Op: "search", Path: file, Err: os.ErrNotExist}
+	if opts.Log != nil {
+		opts.Log(fmt.Sprintf("search %q from %q: %s, using %s", file, dir, strings.Join(tried, ", "), found[0]))
+	}
+	if opts.StrictDuplicates && len(found) > 1 {
+		return file, fmt.Errorf("search %q from %q: found at more than one place: %s", file, dir, strings.Join(found, ", "))
+	}
+	return found[0], nil
 }
 
 // ParseBatchString parses a group of string content and returns an AST.
@@ -89,6 +166,7 @@ func parseBatchStringRecursively(path string, includeDirs []string, thriftMap ma
 			return nil, err
 		}
 		inc.Reference = t
+		inc.ResolvedPath = incPath
 	}
 	return t, nil
 }
@@ -98,8 +176,7 @@ func parseBatchStringRecursively(path string, includeDirs []string, thriftMap ma
 func ParseFile(path string, includeDirs []string, recursive bool) (*Thrift, error) {
 	if recursive {
 		thriftMap := make(map[string]*Thrift)
-		dir := filepath.Dir(normalizeFilename(path))
-		return parseFileRecursively(path, dir, includeDirs, thriftMap)
+		return parseFileRecursively(path, "", includeDirs, thriftMap)
 	}
 	bs, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -126,12 +203,16 @@ func parseFileRecursively(file, dir string, includeDirs []string, thriftMap map[
 	}
 	thriftMap[path] = t
 	dir = filepath.Dir(path)
+	if u, ok := remoteDir(path); ok {
+		dir = u
+	}
 	for _, inc := range t.Includes {
 		t, err := parseFileRecursively(inc.Path, dir, includeDirs, thriftMap)
 		if err != nil {
 			return nil, err
 		}
 		inc.Reference = t
+		inc.ResolvedPath = t.Filename
 	}
 	return t, nil
 }
@@ -144,9 +225,10 @@ func ParseString(path, content string) (*Thrift, error) {
 func parseString(path, content string, includeDirs []string) (*Thrift, error) {
 	p := &parser{
 		IncludeDirs: includeDirs,
+		dialect:     CurrentDialect(),
 	}
 	p.Filename = path
-	p.Buffer = content
+	p.Buffer = NormalizeSource(content)
 	p.Init()
 	if err := p.ThriftIDL.Parse(); err != nil {
 		return nil, err
@@ -154,9 +236,27 @@ func parseString(path, content string, includeDirs []string) (*Thrift, error) {
 	if err := p.parse(); err != nil {
 		return nil, err
 	}
+	sum := sha256.Sum256([]byte(p.Buffer))
+	digests.Store(path, sum[:])
 	return &p.Thrift, nil
 }
 
+// digests keeps the digests of the sources parsed so far by the file names of
+// the IDLs, see SourceDigest.
+var digests sync.Map // string => []byte
+
+// SourceDigest returns the SHA-256 digest of the source that the AST is parsed
+// from, normalized like NormalizeSource does. The digest of an AST produced by
+// Merge covers the sources of all the merged IDLs. It returns false if the source
+// is unknown, e.g. when the AST is not built by the parser in this process.
+func (t *Thrift) SourceDigest() ([]byte, bool) {
+	v, ok := digests.Load(t.Filename)
+	if !ok {
+		return nil, false
+	}
+	return v.([]byte), true
+}
+
 func (p *parser) parse() (err error) {
 	root := p.AST()
 	if root == nil || root.pegRule != ruleDocument {
@@ -182,6 +282,29 @@ func (p *parser) parse() (err error) {
 	return nil
 }
 
+// position returns the 1-based line and column in the buffer where the node begins.
+func (p *parser) position(node *node32) *Position {
+	if p.lineStarts == nil {
+		p.lineStarts = append(p.lineStarts, 0)
+		for i, r := range p.buffer {
+			if r == '\n' {
+				p.lineStarts = append(p.lineStarts, uint32(i+1))
+			}
+		}
+	}
+	line := sort.Search(len(p.lineStarts), func(i int) bool {
+		return p.lineStarts[i] > node.begin
+	})
+	col := int32(node.begin-p.lineStarts[line-1]) + 1
+	if line == 1 {
+		col += p.colOffset
+	}
+	return &Position{
+		Line: int32(line) + p.lineOffset,
+		Col:  col,
+	}
+}
+
 func (p *parser) pegText(node *node32) string {
 	for n := node; n != nil; n = n.next {
 		if s := p.pegText(n.up); s != "" {
@@ -243,20 +366,32 @@ func (p *parser) parseHeader(node *node32) (err error) {
 }
 
 func (p *parser) parseInclude(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleInclude)
 	if err != nil {
 		return err
 	}
+	// INCLUDE Literal (AS Identifier)?
 	filename := p.pegText(node)
 	if filename == "" {
 		return
 	}
+	var alias string
+	if node = node.next.next; node != nil && node.pegRule == ruleAS { // ignore INCLUDE Literal
+		alias = p.pegText(node.next)
+		if strings.Contains(alias, ".") {
+			return fmt.Errorf("%d:%d: invalid alias %q of include %q, an alias can not contain '.'", pos.Line, pos.Col, alias, filename)
+		}
+	}
 	for _, inc := range p.Includes {
 		if inc.Path == filename {
+			if inc.Alias != alias {
+				return fmt.Errorf("%d:%d: include %q is repeated with a different alias", pos.Line, pos.Col, filename)
+			}
 			return
 		}
 	}
-	p.Includes = append(p.Includes, &Include{Path: filename})
+	p.Includes = append(p.Includes, &Include{Path: filename, Position: pos, Alias: alias})
 	return nil
 }
 
@@ -270,7 +405,7 @@ func (p *parser) parseCppInclude(node *node32) (err error) {
 }
 
 func (p *parser) parseNamespace(node *node32) (err error) {
-	ns := Namespace{}
+	ns := Namespace{Position: p.position(node)}
 	node, err = checkrule(node, ruleNamespace)
 	if err != nil {
 		return err
@@ -296,7 +431,7 @@ func (p *parser) parseDefinition(node *node32) (err error) {
 		return err
 	}
 	p.DefinitionReservedComment = ""
-	// ReservedComments Skip (Const / Typedef / Enum / Struct / Union / Service / Exception) Annotations? SkipLine
+	// ReservedComments Skip (Const / Typedef / Enum / Senum / Struct / Union / Service / Exception) Annotations? SkipLine
 	if node.pegRule == ruleReservedComments {
 		reservedComments, err := p.parseReservedComments(node)
 		if err != nil {
@@ -309,6 +444,15 @@ func (p *parser) parseDefinition(node *node32) (err error) {
 	if node.pegRule == ruleSkip {
 		node = node.next
 	}
+	var sas StructuredAnnotations
+	for ; node.pegRule == ruleStructuredAnnotation; node = node.next {
+		sa, err := p.parseStructuredAnnotation(node)
+		if err != nil {
+			return err
+		}
+		sas = append(sas, sa)
+	}
+	p.StructuredAnnotations = nil
 	switch node.pegRule {
 	case ruleConst:
 		if err := p.parseConst(node); err != nil {
@@ -322,6 +466,10 @@ func (p *parser) parseDefinition(node *node32) (err error) {
 		if err := p.parseEnum(node); err != nil {
 			return err
 		}
+	case ruleSenum:
+		if err := p.parseSenum(node); err != nil {
+			return err
+		}
 	case ruleUnion:
 		if err := p.parseUnion(node); err != nil {
 			return err
@@ -341,6 +489,9 @@ func (p *parser) parseDefinition(node *node32) (err error) {
 	default:
 		return fmt.Errorf("unknown rule: " + rul3s[node.pegRule])
 	}
+	if p.StructuredAnnotations != nil {
+		*p.StructuredAnnotations = sas
+	}
 	node = node.next
 	if node != nil && node.pegRule == ruleAnnotations {
 		ann, err := p.parseAnnotations(node)
@@ -353,6 +504,7 @@ func (p *parser) parseDefinition(node *node32) (err error) {
 }
 
 func (p *parser) parseConst(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleConst)
 	if err != nil {
 		return err
@@ -370,10 +522,11 @@ func (p *parser) parseConst(node *node32) (err error) {
 	if err != nil {
 		return err
 	}
-	c := &Constant{Name: name, Type: ft, Value: value}
+	c := &Constant{Name: name, Type: ft, Value: value, Position: pos}
 	c.ReservedComments = p.DefinitionReservedComment
 	p.Constants = append(p.Constants, c)
 	p.Annotations = &c.Annotations
+	p.StructuredAnnotations = &c.StructuredAnnotations
 	return nil
 }
 
@@ -464,23 +617,16 @@ func (p *parser) parseConstValue(node *node32) (cv *ConstValue, err error) {
 	if err != nil {
 		return nil, err
 	}
-	// DoubleConstant / IntConstant / Literal / Identifier / ConstList / ConstMap
+	// DoubleConstant / ConstExpr / Literal / ConstList / ConstMap
 	switch node.pegRule {
 	case ruleDoubleConstant:
 		double, _ := strconv.ParseFloat(p.pegText(node), 64)
 		return &ConstValue{Type: ConstType_ConstDouble, TypedValue: &ConstTypedValue{Double: &double}}, nil
-	case ruleIntConstant:
-		i, err := strconv.ParseInt(p.pegText(node), 0, 64)
-		if err != nil {
-			return nil, fmt.Errorf("parseConstValue failed at '%s': %w", p.pegText(node), err)
-		}
-		return &ConstValue{Type: ConstType_ConstInt, TypedValue: &ConstTypedValue{Int: &i}}, nil
+	case ruleConstExpr:
+		return p.parseConstExpr(node)
 	case ruleLiteral:
 		literal := p.pegText(node)
 		return &ConstValue{Type: ConstType_ConstLiteral, TypedValue: &ConstTypedValue{Literal: &literal}}, nil
-	case ruleIdentifier:
-		identifier := p.pegText(node)
-		return &ConstValue{Type: ConstType_ConstIdentifier, TypedValue: &ConstTypedValue{Identifier: &identifier}}, nil
 	case ruleConstList:
 		// LBRK (ConstValue ListSeparator?)* RBRK
 		ret := []*ConstValue{} // important: can't not be nil
@@ -519,7 +665,94 @@ func (p *parser) parseConstValue(node *node32) (cv *ConstValue, err error) {
 	}
 }
 
+var constOperators = map[pegRule]string{
+	rulePLUS:   "+",
+	ruleMINUS:  "-",
+	ruleBITOR:  "|",
+	ruleBITAND: "&",
+}
+
+// parseConstExpr parses a constant expression. An expression without any
+// operator results in the value of its only term.
+func (p *parser) parseConstExpr(node *node32) (cv *ConstValue, err error) {
+	node, err = checkrule(node, ruleConstExpr)
+	if err != nil {
+		return nil, err
+	}
+	// ConstAndExpr ((PLUS / MINUS / BITOR / UNSUPPORTED) ConstAndExpr)* Indent*
+	if cv, err = p.parseConstAndExpr(node); err != nil {
+		return nil, err
+	}
+	for n := node.next; n != nil && n.pegRule != ruleIndent; n = n.next.next {
+		op, ok := constOperators[n.pegRule]
+		if !ok {
+			pos := p.position(n)
+			for c := n.up; c != nil; c = c.next {
+				if c.pegRule == rulePegText {
+					pos = p.position(c)
+				}
+			}
+			return nil, fmt.Errorf("%d:%d: unsupported operator %q in const expression, only +, -, | and & are supported",
+				pos.Line, pos.Col, p.pegText(n))
+		}
+		rhs, err := p.parseConstAndExpr(n.next)
+		if err != nil {
+			return nil, err
+		}
+		cv = newConstExpr(op, cv, rhs)
+	}
+	return cv, nil
+}
+
+func (p *parser) parseConstAndExpr(node *node32) (cv *ConstValue, err error) {
+	node, err = checkrule(node, ruleConstAndExpr)
+	if err != nil {
+		return nil, err
+	}
+	// ConstTerm (BITAND ConstTerm)*
+	if cv, err = p.parseConstTerm(node); err != nil {
+		return nil, err
+	}
+	for n := node.next; n != nil; n = n.next.next {
+		rhs, err := p.parseConstTerm(n.next)
+		if err != nil {
+			return nil, err
+		}
+		cv = newConstExpr(constOperators[n.pegRule], cv, rhs)
+	}
+	return cv, nil
+}
+
+func (p *parser) parseConstTerm(node *node32) (cv *ConstValue, err error) {
+	node, err = checkrule(node, ruleConstTerm)
+	if err != nil {
+		return nil, err
+	}
+	// Skip <IntConstant / Identifier>
+	//
+	// A term does not skip the spaces after it like IntConstant and Identifier, so
+	// the operator right after it, e.g. the '-' of 3-1, is told from the sign of a
+	// value that follows a space, e.g. the -1 of [3 -1].
+	text := p.pegText(node)
+	if c := text[0]; c >= '0' && c <= '9' || c == '+' || c == '-' {
+		i, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parseConstValue failed at '%s': %w", text, err)
+		}
+		return &ConstValue{Type: ConstType_ConstInt, TypedValue: &ConstTypedValue{Int: &i}}, nil
+	}
+	return &ConstValue{Type: ConstType_ConstIdentifier, TypedValue: &ConstTypedValue{Identifier: &text}}, nil
+}
+
+func newConstExpr(op string, lhs, rhs *ConstValue) *ConstValue {
+	return &ConstValue{
+		Type:       ConstType_ConstExpr,
+		TypedValue: &ConstTypedValue{Expr: &ConstExpr{Op: op, LHS: lhs, RHS: rhs}},
+	}
+}
+
 func (p *parser) parseTypedef(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleTypedef)
 	if err != nil {
 		return err
@@ -532,20 +765,23 @@ func (p *parser) parseTypedef(node *node32) (err error) {
 	}
 	var typd Typedef
 	typd.Type = ft
+	typd.Position = pos
 	node = node.next
 	typd.Alias = p.pegText(node)
 	typd.ReservedComments = p.DefinitionReservedComment
 	p.Typedefs = append(p.Typedefs, &typd)
 	p.Annotations = &typd.Annotations
+	p.StructuredAnnotations = &typd.StructuredAnnotations
 	return nil
 }
 
 func (p *parser) parseEnum(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleEnum)
 	if err != nil {
 		return err
 	}
-	// ENUM Identifier LWING ( ReservedComments Identifier (EQUAL IntConstant)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)* RWING
+	// ENUM Identifier LWING ( ReservedComments Identifier (EQUAL ConstExpr)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)* RWING
 	node = node.next // ignore ENUM
 	name := p.pegText(node)
 	var values []*EnumValue
@@ -562,9 +798,17 @@ func (p *parser) parseEnum(node *node32) (err error) {
 			var v EnumValue
 			v.ReservedComments = valueComments
 			v.Name = p.pegText(n)
+			v.Position = p.position(n)
 			if n.next.pegRule == ruleEQUAL {
 				n = n.next.next
-				v.Value, _ = strconv.ParseInt(p.pegText(n), 0, 64)
+				cv, err := p.parseConstExpr(n)
+				if err != nil {
+					return err
+				}
+				v.Value, err = evalEnumValue(name, values, cv)
+				if err != nil {
+					return fmt.Errorf("enum %s: value of %s: %w", name, v.Name, err)
+				}
 			} else {
 				if len(values) == 0 {
 					v.Value = 0
@@ -593,25 +837,90 @@ func (p *parser) parseEnum(node *node32) (err error) {
 			values = append(values, &v)
 		}
 	}
-	e := &Enum{Name: name, Values: values}
+	e := &Enum{Name: name, Values: values, Position: pos}
 	e.ReservedComments = p.DefinitionReservedComment
 	p.Enums = append(p.Enums, e)
 	p.Annotations = &e.Annotations
+	p.StructuredAnnotations = &e.StructuredAnnotations
 	return nil
 }
 
+func (p *parser) parseSenum(node *node32) (err error) {
+	pos := p.position(node)
+	node, err = checkrule(node, ruleSenum)
+	if err != nil {
+		return err
+	}
+	if err = p.checkDialect(node, "senum", DialectApache); err != nil {
+		return err
+	}
+	// SENUM Identifier LWING (Literal ListSeparator?)* RWING
+	node = node.next // ignore SENUM
+	e := &Senum{Name: p.pegText(node), Position: pos}
+	for n := node.next.next; n != nil; n = n.next {
+		if n.pegRule == ruleLiteral {
+			e.Values = append(e.Values, p.pegText(n))
+		}
+	}
+	e.ReservedComments = p.DefinitionReservedComment
+	p.Senums = append(p.Senums, e)
+	p.Annotations = &e.Annotations
+	return nil
+}
+
+// evalEnumValue evaluates the value assigned to an enum value. Besides integers, it
+// can refer to the values defined before it in the same enum, with or without the
+// name of the enum as a selector.
+func evalEnumValue(enum string, values []*EnumValue, cv *ConstValue) (int64, error) {
+	switch cv.Type {
+	case ConstType_ConstInt:
+		return cv.TypedValue.GetInt(), nil
+	case ConstType_ConstIdentifier:
+		id := cv.TypedValue.GetIdentifier()
+		name := strings.TrimPrefix(id, enum+".")
+		for _, v := range values {
+			if v.Name == name {
+				return v.Value, nil
+			}
+		}
+		return 0, fmt.Errorf("%q is not a value defined before it in the same enum", id)
+	case ConstType_ConstExpr:
+		expr := cv.TypedValue.GetExpr()
+		lhs, err := evalEnumValue(enum, values, expr.LHS)
+		if err != nil {
+			return 0, err
+		}
+		rhs, err := evalEnumValue(enum, values, expr.RHS)
+		if err != nil {
+			return 0, err
+		}
+		return expr.Eval(lhs, rhs)
+	}
+	return 0, fmt.Errorf("unexpected value %s", cv)
+}
+
 func (p *parser) parseUnion(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleUnion)
 	if err != nil {
 		return err
 	}
-	// UNION Identifier LWING Field* RWING
+	// UNION Identifier LWING (Reserved / Field)* RWING
 	node = node.next // ignore UNION
 	name := p.pegText(node)
 	node = node.next
 	var fields []*Field
+	var reservedIDs []int32
+	var reservedNames []string
 	for n := node.next; n != nil; n = n.next {
 		switch n.pegRule {
+		case ruleReserved:
+			ids, names, err := p.parseReserved(n)
+			if err != nil {
+				return err
+			}
+			reservedIDs = append(reservedIDs, ids...)
+			reservedNames = append(reservedNames, names...)
 		case ruleField:
 			field, err := p.parseField(n)
 			if err != nil {
@@ -627,25 +936,37 @@ func (p *parser) parseUnion(node *node32) (err error) {
 			fields = append(fields, field)
 		}
 	}
-	u := &StructLike{Category: "union", Name: name, Fields: fields}
+	u := &StructLike{Category: "union", Name: name, Fields: fields, Position: pos}
+	u.ReservedIDs, u.ReservedNames = reservedIDs, reservedNames
 	u.ReservedComments = p.DefinitionReservedComment
 	p.Unions = append(p.Unions, u)
 	p.Annotations = &u.Annotations
+	p.StructuredAnnotations = &u.StructuredAnnotations
 	return nil
 }
 
 func (p *parser) parseStruct(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleStruct)
 	if err != nil {
 		return err
 	}
-	// STRUCT Identifier LWING Field* RWING
+	// STRUCT Identifier LWING (Reserved / Field)* RWING
 	node = node.next // ignore STRUCT
 	name := p.pegText(node)
 	node = node.next
 	var fields []*Field
+	var reservedIDs []int32
+	var reservedNames []string
 	for n := node.next; n != nil; n = n.next {
 		switch n.pegRule {
+		case ruleReserved:
+			ids, names, err := p.parseReserved(n)
+			if err != nil {
+				return err
+			}
+			reservedIDs = append(reservedIDs, ids...)
+			reservedNames = append(reservedNames, names...)
 		case ruleField:
 			field, err := p.parseField(n)
 			if err != nil {
@@ -661,24 +982,37 @@ func (p *parser) parseStruct(node *node32) (err error) {
 			fields = append(fields, field)
 		}
 	}
-	s := &StructLike{Category: "struct", Name: name, Fields: fields}
+	s := &StructLike{Category: "struct", Name: name, Fields: fields, Position: pos}
+	s.ReservedIDs, s.ReservedNames = reservedIDs, reservedNames
 	s.ReservedComments = p.DefinitionReservedComment
 	p.Structs = append(p.Structs, s)
 	p.Annotations = &s.Annotations
+	p.StructuredAnnotations = &s.StructuredAnnotations
 	return nil
 }
 
 func (p *parser) parseException(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleException)
 	if err != nil {
 		return err
 	}
-	// EXCEPTION Identifier LWING Field* RWING
+	// EXCEPTION Identifier LWING (Reserved / Field)* RWING
 	node = node.next // ignore EXCEPTION
 	name := p.pegText(node)
 	var fields []*Field
+	var reservedIDs []int32
+	var reservedNames []string
 	for n := node.next; n != nil; n = n.next {
-		if n.pegRule == ruleField {
+		switch n.pegRule {
+		case ruleReserved:
+			ids, names, err := p.parseReserved(n)
+			if err != nil {
+				return err
+			}
+			reservedIDs = append(reservedIDs, ids...)
+			reservedNames = append(reservedNames, names...)
+		case ruleField:
 			field, err := p.parseField(n)
 			if err != nil {
 				return err
@@ -693,13 +1027,36 @@ func (p *parser) parseException(node *node32) (err error) {
 			fields = append(fields, field)
 		}
 	}
-	e := &StructLike{Category: "exception", Name: name, Fields: fields}
+	e := &StructLike{Category: "exception", Name: name, Fields: fields, Position: pos}
+	e.ReservedIDs, e.ReservedNames = reservedIDs, reservedNames
 	e.ReservedComments = p.DefinitionReservedComment
 	p.Exceptions = append(p.Exceptions, e)
 	p.Annotations = &e.Annotations
+	p.StructuredAnnotations = &e.StructuredAnnotations
 	return nil
 }
 
+func (p *parser) parseReserved(node *node32) (ids []int32, names []string, err error) {
+	node, err = checkrule(node, ruleReserved)
+	if err != nil {
+		return nil, nil, err
+	}
+	// RESERVED (IntConstant !COLON / Literal) (COMMA (IntConstant !COLON / Literal))* ListSeparator? SkipLine
+	for ; node != nil; node = node.next {
+		switch node.pegRule {
+		case ruleIntConstant:
+			id, err := strconv.ParseInt(p.pegText(node), 0, 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid reserved field ID '%s': %w", p.pegText(node), err)
+			}
+			ids = append(ids, int32(id))
+		case ruleLiteral:
+			names = append(names, p.pegText(node))
+		}
+	}
+	return ids, names, nil
+}
+
 func (p *parser) parseField(node *node32) (field *Field, err error) {
 	node, err = checkrule(node, ruleField)
 	if err != nil {
@@ -709,6 +1066,13 @@ func (p *parser) parseField(node *node32) (field *Field, err error) {
 	var f Field
 	f.ID = NOTSET
 	for ; node != nil; node = node.next {
+		switch node.pegRule {
+		case ruleSkip, ruleSkipLine, ruleReservedComments, ruleStructuredAnnotation:
+		default:
+			if f.Position == nil {
+				f.Position = p.position(node)
+			}
+		}
 		switch node.pegRule {
 		case ruleSkip, ruleSkipLine:
 			continue
@@ -727,6 +1091,12 @@ func (p *parser) parseField(node *node32) (field *Field, err error) {
 			if f.ReservedComments == "" {
 				f.ReservedComments = reservedComments
 			}
+		case ruleStructuredAnnotation:
+			sa, err := p.parseStructuredAnnotation(node)
+			if err != nil {
+				return nil, err
+			}
+			f.StructuredAnnotations = append(f.StructuredAnnotations, sa)
 		case ruleFieldId:
 			i, _ := strconv.Atoi(p.pegText(node))
 			f.ID = int32(i)
@@ -758,6 +1128,7 @@ func (p *parser) parseField(node *node32) (field *Field, err error) {
 			}
 		}
 	}
+	f.ImplicitID = f.ID == NOTSET
 	return &f, nil
 }
 
@@ -795,12 +1166,13 @@ func (p *parser) parseAnnotation(node *node32) (k, v string, err error) {
 }
 
 func (p *parser) parseService(node *node32) (err error) {
+	pos := p.position(node)
 	node, err = checkrule(node, ruleService)
 	if err != nil {
 		return err
 	}
 	// SERVICE Identifier ( EXTENDS Identifier )? LWING Function* RWING
-	var s Service
+	s := Service{Position: pos}
 	node = node.next // ignore SERVICE
 	s.Name = p.pegText(node)
 	node = node.next
@@ -820,6 +1192,7 @@ func (p *parser) parseService(node *node32) (err error) {
 	s.ReservedComments = p.DefinitionReservedComment
 	p.Services = append(p.Services, &s)
 	p.Annotations = &s.Annotations
+	p.StructuredAnnotations = &s.StructuredAnnotations
 	return nil
 }
 
@@ -830,7 +1203,15 @@ func (p *parser) parseFunction(node *node32) (fu *Function, err error) {
 	}
 	// ReservedComments ONEWAY? FunctionType Identifier LPAR Field* RPAR Throws? Annotations? ListSeparator?
 	var f Function
+	var stream bool
 	for ; node != nil; node = node.next {
+		switch node.pegRule {
+		case ruleSkip, ruleReservedComments, ruleStructuredAnnotation:
+		default:
+			if f.Position == nil {
+				f.Position = p.position(node)
+			}
+		}
 		switch node.pegRule {
 		case ruleReservedComments:
 			reservedComments, err := p.parseReservedComments(node)
@@ -838,18 +1219,47 @@ func (p *parser) parseFunction(node *node32) (fu *Function, err error) {
 				return nil, err
 			}
 			f.ReservedComments = reservedComments
+		case ruleStructuredAnnotation:
+			sa, err := p.parseStructuredAnnotation(node)
+			if err != nil {
+				return nil, err
+			}
+			f.StructuredAnnotations = append(f.StructuredAnnotations, sa)
 		case ruleONEWAY:
 			f.Oneway = true
 		case ruleFunctionType:
 			n := node.up
-			if n.pegRule == ruleFieldType {
+			switch n.pegRule {
+			case ruleFieldType:
 				f.FunctionType, err = p.parseFieldType(n)
 				if err != nil {
 					return nil, err
 				}
-			} else if n.pegRule == ruleVOID {
+			case ruleVOID:
 				f.Void = true
 				f.FunctionType = &Type{Name: "void"}
+			case ruleStreamType: // STREAM LPOINT FieldType RPOINT
+				if err := p.checkDialect(n, "stream", DialectFB); err != nil {
+					return nil, err
+				}
+				stream = true
+				f.FunctionType, err = p.parseFieldType(n.up.next.next)
+				if err != nil {
+					return nil, err
+				}
+			case ruleSinkType: // SINK LPOINT FieldType COMMA FieldType RPOINT
+				if err := p.checkDialect(n, "sink", DialectFB); err != nil {
+					return nil, err
+				}
+				t := n.up.next.next
+				f.SinkType, err = p.parseFieldType(t)
+				if err != nil {
+					return nil, err
+				}
+				f.FunctionType, err = p.parseFieldType(t.next.next)
+				if err != nil {
+					return nil, err
+				}
 			}
 		case ruleIdentifier:
 			f.Name = p.pegText(node)
@@ -871,9 +1281,48 @@ func (p *parser) parseFunction(node *node32) (fu *Function, err error) {
 			}
 		}
 	}
+	if stream {
+		// a stream<T> of fbthrift is the server streaming of the streaming.mode annotation
+		if len(f.Annotations.Get(streamingModeAnnotation)) > 0 {
+			return nil, fmt.Errorf("%d:%d: function %s: stream can not be used with the %s annotation",
+				f.Position.Line, f.Position.Col, f.Name, streamingModeAnnotation)
+		}
+		f.Annotations.Append(streamingModeAnnotation, "server")
+	}
 	return &f, nil
 }
 
+// streamingModeAnnotation is the annotation of the streaming functions.
+const streamingModeAnnotation = "streaming.mode"
+
+// parseStructuredAnnotation parses a structured annotation of fbthrift.
+func (p *parser) parseStructuredAnnotation(node *node32) (*StructuredAnnotation, error) {
+	// AT Identifier (LWING (Identifier EQUAL ConstValue ListSeparator?)* RWING)?
+	sa := &StructuredAnnotation{Name: p.pegText(node.up.next), Position: p.position(node)}
+	if err := p.checkDialect(node, "structured annotation @"+sa.Name, DialectFB); err != nil {
+		return nil, err
+	}
+	for n := node.up.next.next; n != nil; n = n.next {
+		if n.pegRule != ruleIdentifier {
+			continue
+		}
+		name := p.pegText(n)
+		for _, f := range sa.Fields {
+			if f.Name == name {
+				return nil, fmt.Errorf("%d:%d: structured annotation @%s: field %q is set more than once",
+					sa.Position.Line, sa.Position.Col, sa.Name, name)
+			}
+		}
+		n = n.next.next // ignore EQUAL
+		value, err := p.parseConstValue(n)
+		if err != nil {
+			return nil, err
+		}
+		sa.Fields = append(sa.Fields, &StructuredAnnotationField{Name: name, Value: value})
+	}
+	return sa, nil
+}
+
 func (p *parser) parseThrows(node *node32) (fs []*Field, err error) {
 	node, err = checkrule(node, ruleThrows)
 	if err != nil {
@@ -15,10 +15,15 @@
 package parser_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
 )
 
 const testAnnotation = `
@@ -425,6 +430,63 @@ func TestEnumValue(t *testing.T) {
 	test.Assert(t, e3.Values[5].Value == 3)
 }
 
+const testByte = `
+typedef byte B
+const byte C = 1
+struct S {
+	1: byte b
+	2: map<byte, i8> m
+}
+`
+
+func TestByteAsI8(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", testByte)
+	test.Assert(t, err == nil, err)
+	// the spelling in the source is kept
+	test.Assert(t, ast.Typedefs[0].Type.Name == "byte")
+	test.Assert(t, ast.Constants[0].Type.Name == "byte")
+	fs := ast.Structs[0].Fields
+	test.Assert(t, fs[0].Type.Name == "byte")
+	test.Assert(t, fs[1].Type.KeyType.Name == "byte")
+	test.Assert(t, fs[1].Type.ValueType.Name == "i8")
+
+	// and both are resolved to the same category
+	test.Assert(t, semantic.ResolveSymbols(ast) == nil)
+	test.Assert(t, fs[0].Type.Category == parser.Category_Byte)
+	test.Assert(t, fs[1].Type.KeyType.Category == parser.Category_Byte)
+	test.Assert(t, fs[1].Type.ValueType.Category == parser.Category_Byte)
+}
+
+const testEnumValueAnnotation = `
+enum Status {
+	OK (doc = "fine"),
+	// comment
+	DEPRECATED = 3 (status = "deprecated", deprecated = "use OK")
+	ALIAS = DEPRECATED | 4 (alias = "")
+	LAST
+}
+`
+
+func TestEnumValueAnnotation(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", testEnumValueAnnotation)
+	test.Assert(t, err == nil, err)
+	vs := ast.Enums[0].Values
+	test.Assert(t, len(vs) == 4, vs)
+
+	test.Assert(t, vs[0].Value == 0)
+	test.Assert(t, len(vs[0].Annotations) == 1 && vs[0].Annotations.Get("doc")[0] == "fine", vs[0].Annotations)
+
+	test.Assert(t, vs[1].Value == 3)
+	test.Assert(t, len(vs[1].Annotations) == 2, vs[1].Annotations)
+	test.Assert(t, vs[1].Annotations.Get("status")[0] == "deprecated")
+	test.Assert(t, vs[1].Annotations.Get("deprecated")[0] == "use OK")
+
+	test.Assert(t, vs[2].Value == 7)
+	test.Assert(t, len(vs[2].Annotations) == 1 && vs[2].Annotations.Get("alias")[0] == "", vs[2].Annotations)
+
+	test.Assert(t, vs[3].Value == 8 && len(vs[3].Annotations) == 0)
+}
+
 const testNamespace = `
 namespace * whatever
 namespace go golang
@@ -442,3 +504,408 @@ func TestNamespace(t *testing.T) {
 	test.Assert(t, ast.Namespaces[2].Language == "py")
 	test.Assert(t, ast.Namespaces[2].Name == "python.org")
 }
+
+func TestIncludeAlias(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `
+include "a.thrift"
+include "dir/b.thrift" as c
+include "dir/b.thrift" as c
+include "as.thrift" as as
+`)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(ast.Includes) == 3, ast.Includes)
+	test.Assert(t, ast.Includes[0].Alias == "" && ast.Includes[0].RefName() == "a")
+	test.Assert(t, ast.Includes[1].Alias == "c" && ast.Includes[1].RefName() == "c")
+	test.Assert(t, ast.Includes[2].Alias == "as" && ast.Includes[2].RefName() == "as")
+
+	_, err = parser.ParseString("main.thrift", `include "a.thrift" as x.y`)
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `1:1: invalid alias "x.y" of include "a.thrift", an alias can not contain '.'`), err)
+
+	_, err = parser.ParseString("main.thrift", "include \"a.thrift\"\ninclude \"a.thrift\" as x")
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `2:1: include "a.thrift" is repeated with a different alias`), err)
+}
+
+func TestPosition(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `namespace go a
+ include "b.thrift"
+// comment
+struct S {
+	// field comment
+	1: required i32 a
+	   string b, /* c */ 3: i64 c
+}
+
+union U {}
+  exception E {}
+
+service Svc {
+	void Ping(
+		1: string x)
+	/* comment */ oneway void Fire()
+}
+typedef i32 T
+	const T C = 1
+enum E {
+	/* comment */ A,
+	B = 2
+}
+`)
+	test.Assert(t, err == nil, err)
+
+	pos := func(p *parser.Position) [2]int32 { return [2]int32{p.GetLine(), p.GetCol()} }
+	s := ast.Structs[0]
+	test.Assert(t, pos(s.Position) == [2]int32{4, 1}, s.Position)
+	test.Assert(t, pos(s.Fields[0].Position) == [2]int32{6, 2}, s.Fields[0].Position)
+	test.Assert(t, pos(s.Fields[1].Position) == [2]int32{7, 5}, s.Fields[1].Position)
+	test.Assert(t, pos(s.Fields[2].Position) == [2]int32{7, 23}, s.Fields[2].Position)
+	test.Assert(t, pos(ast.Unions[0].Position) == [2]int32{10, 1}, ast.Unions[0].Position)
+	test.Assert(t, pos(ast.Exceptions[0].Position) == [2]int32{11, 3}, ast.Exceptions[0].Position)
+
+	fs := ast.Services[0].Functions
+	test.Assert(t, pos(fs[0].Position) == [2]int32{14, 2}, fs[0].Position)
+	test.Assert(t, pos(fs[0].Arguments[0].Position) == [2]int32{15, 3}, fs[0].Arguments[0].Position)
+	test.Assert(t, pos(fs[1].Position) == [2]int32{16, 16}, fs[1].Position)
+
+	test.Assert(t, pos(ast.Namespaces[0].Position) == [2]int32{1, 1}, ast.Namespaces[0].Position)
+	test.Assert(t, pos(ast.Includes[0].Position) == [2]int32{2, 2}, ast.Includes[0].Position)
+	test.Assert(t, pos(ast.Services[0].Position) == [2]int32{13, 1}, ast.Services[0].Position)
+	test.Assert(t, pos(ast.Typedefs[0].Position) == [2]int32{18, 1}, ast.Typedefs[0].Position)
+	test.Assert(t, pos(ast.Constants[0].Position) == [2]int32{19, 2}, ast.Constants[0].Position)
+	e := ast.Enums[0]
+	test.Assert(t, pos(e.Position) == [2]int32{20, 1}, e.Position)
+	test.Assert(t, pos(e.Values[0].Position) == [2]int32{21, 16}, e.Values[0].Position)
+	test.Assert(t, pos(e.Values[1].Position) == [2]int32{22, 2}, e.Values[1].Position)
+}
+
+func TestLineEndings(t *testing.T) {
+	src := `namespace go a
+// comment
+struct S {
+	1: string a // end of line
+	/* b */ 2: i32 b
+}
+`
+	_, want := parser.ParseString("main.thrift", strings.Replace(src, "i32 b", "i32 b c", 1))
+	test.Assert(t, want != nil)
+	for name, content := range map[string]string{
+		"BOM":       "\uFEFF" + src,
+		"CRLF":      strings.ReplaceAll(src, "\n", "\r\n"),
+		"CR":        strings.ReplaceAll(src, "\n", "\r"),
+		"BOM, CRLF": "\uFEFF" + strings.ReplaceAll(src, "\n", "\r\n"),
+	} {
+		ast, err := parser.ParseString("main.thrift", content)
+		test.Assert(t, err == nil, name, err)
+
+		pos := func(p *parser.Position) [2]int32 { return [2]int32{p.GetLine(), p.GetCol()} }
+		s := ast.Structs[0]
+		test.Assert(t, pos(ast.Namespaces[0].Position) == [2]int32{1, 1}, name, ast.Namespaces[0].Position)
+		test.Assert(t, pos(s.Position) == [2]int32{3, 1}, name, s.Position)
+		test.Assert(t, pos(s.Fields[0].Position) == [2]int32{4, 2}, name, s.Fields[0].Position)
+		test.Assert(t, pos(s.Fields[1].Position) == [2]int32{5, 10}, name, s.Fields[1].Position)
+		test.Assert(t, s.ReservedComments == "// comment", name, s.ReservedComments)
+		test.Assert(t, s.Fields[0].ReservedComments == "// end of line", name, s.Fields[0].ReservedComments)
+
+		_, err = parser.ParseString("main.thrift", strings.Replace(content, "i32 b", "i32 b c", 1))
+		test.Assert(t, err != nil && err.Error() == want.Error(), name, err)
+	}
+}
+
+func TestReserved(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `
+struct S {
+	reserved 2, 0x10, "old_name";
+	1: i32 a
+	reserved 'legacy'
+	3: i32 b
+}
+union U { reserved 1 2: string s }
+exception E {
+	reserved "code", 4,
+	1: string msg
+}
+struct R { 1: reserved reserved }
+`)
+	test.Assert(t, err == nil, err)
+
+	s := ast.Structs[0]
+	test.Assert(t, len(s.Fields) == 2 && s.Fields[1].ID == 3, s.Fields)
+	test.Assert(t, len(s.ReservedIDs) == 2 && s.ReservedIDs[0] == 2 && s.ReservedIDs[1] == 16, s.ReservedIDs)
+	test.Assert(t, len(s.ReservedNames) == 2 && s.ReservedNames[0] == "old_name" && s.ReservedNames[1] == "legacy", s.ReservedNames)
+
+	u := ast.Unions[0]
+	test.Assert(t, len(u.Fields) == 1 && len(u.ReservedIDs) == 1 && u.ReservedIDs[0] == 1, u)
+
+	e := ast.Exceptions[0]
+	test.Assert(t, len(e.Fields) == 1 && e.ReservedIDs[0] == 4 && e.ReservedNames[0] == "code", e)
+
+	r := ast.Structs[1]
+	test.Assert(t, len(r.Fields) == 1 && r.Fields[0].Type.Name == "reserved" && r.Fields[0].Name == "reserved", r.Fields)
+	test.Assert(t, len(r.ReservedIDs) == 0 && len(r.ReservedNames) == 0, r)
+}
+
+func TestTrailingSeparators(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `
+const list<i32> L = [1, 2,]
+const map<string, i32> M = {"a": 1; "b": 2;}
+enum E { A, B = 3, }
+struct S {
+	1: i32 a,
+	2: list<i32> b = [1,] (k1 = "v1", k2 = "v2",),
+}
+service Svc {
+	void f(1: i32 a, 2: i32 b,) throws (1: X x,),
+	void g(),
+}
+`)
+	test.Assert(t, err == nil, err)
+
+	cs := ast.Constants
+	test.Assert(t, len(cs[0].Value.TypedValue.List) == 2, cs[0].Value)
+	test.Assert(t, len(cs[1].Value.TypedValue.Map) == 2, cs[1].Value)
+
+	e := ast.Enums[0]
+	test.Assert(t, len(e.Values) == 2 && e.Values[1].Value == 3, e.Values)
+
+	s := ast.Structs[0]
+	test.Assert(t, len(s.Fields) == 2, s.Fields)
+	test.Assert(t, len(s.Fields[1].Default.TypedValue.List) == 1, s.Fields[1].Default)
+	test.Assert(t, len(s.Fields[1].Annotations) == 2, s.Fields[1].Annotations)
+
+	fs := ast.Services[0].Functions
+	test.Assert(t, len(fs) == 2, fs)
+	test.Assert(t, len(fs[0].Arguments) == 2 && len(fs[0].Throws) == 1, fs[0])
+
+	for _, src := range []string{
+		"const list<i32> L = [,]",
+		"const list<i32> L = [1,,]",
+		"const map<i32, i32> M = {,}",
+		"struct S { 1: i32 a,, }",
+		"service Svc { void f(1: i32 a,,) }",
+	} {
+		_, err = parser.ParseString("main.thrift", src)
+		test.Assert(t, err != nil, src)
+	}
+}
+
+func TestConstExpr(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `
+enum Flags {
+	A = 1
+	B = 2
+	AB = A | B
+	ALL = Flags.AB | 4
+	MASKED = 0xff & 0x0f + 1
+}
+const i32 C = X + 1 - 2
+const i32 D = A | B & C
+const list<i32> L = [1 -1, 2 - 1]
+const i32 E = A+1
+const list<i32> M = [3-1 -1, 3--1]
+`)
+	test.Assert(t, err == nil, err)
+
+	vs := ast.Enums[0].Values
+	test.Assert(t, vs[2].Value == 3 && vs[3].Value == 7 && vs[4].Value == 16, vs)
+
+	// left-associative: (X + 1) - 2
+	c := ast.Constants[0].Value
+	test.Assert(t, c.Type == parser.ConstType_ConstExpr && c.TypedValue.Expr.Op == "-", c)
+	lhs := c.TypedValue.Expr.LHS
+	test.Assert(t, lhs.TypedValue.Expr.Op == "+" && lhs.TypedValue.Expr.LHS.TypedValue.GetIdentifier() == "X", lhs)
+
+	// & binds tighter than |: A | (B & C)
+	d := ast.Constants[1].Value.TypedValue.Expr
+	test.Assert(t, d.Op == "|" && d.LHS.Type == parser.ConstType_ConstIdentifier, d)
+	test.Assert(t, d.RHS.TypedValue.Expr.Op == "&", d.RHS)
+
+	// a sign directly followed by digits is not an operator
+	l := ast.Constants[2].Value.TypedValue.List
+	test.Assert(t, len(l) == 3 && l[1].TypedValue.GetInt() == -1, l)
+	test.Assert(t, l[2].Type == parser.ConstType_ConstExpr, l[2])
+
+	// an operator right after a term is never a sign
+	e := ast.Constants[3].Value.TypedValue.Expr
+	test.Assert(t, e.Op == "+" && e.LHS.TypedValue.GetIdentifier() == "A" && e.RHS.TypedValue.GetInt() == 1, e)
+	m := ast.Constants[4].Value.TypedValue.List
+	test.Assert(t, len(m) == 3 && m[0].TypedValue.Expr.Op == "-" && m[1].TypedValue.GetInt() == -1, m)
+	test.Assert(t, m[2].TypedValue.Expr.Op == "-" && m[2].TypedValue.Expr.RHS.TypedValue.GetInt() == -1, m[2])
+
+	_, err = parser.ParseString("main.thrift", "const i32 C = 1 << 2")
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `1:17: unsupported operator "<<"`), err)
+	_, err = parser.ParseString("main.thrift", "const i32 C = 1<<2")
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `1:16: unsupported operator "<<"`), err)
+
+	_, err = parser.ParseString("main.thrift", "enum E { A = B, B = 1 }")
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `enum E: value of A: "B" is not a value defined before it`), err)
+}
+
+func TestSenum(t *testing.T) {
+	ast, err := parser.ParseString("main.thrift", `
+// colors
+senum Color {
+	"red",
+	'green'; "blue"
+	/* comment */
+} (a = "b")
+senum Empty {}
+struct S { 1: Color c }
+`)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(ast.Senums) == 2, ast.Senums)
+	c := ast.Senums[0]
+	test.Assert(t, c.Name == "Color" && strings.Join(c.Values, ",") == "red,green,blue", c)
+	test.Assert(t, c.Annotations.Get("a")[0] == "b" && c.Position.Line == 3, c)
+	test.Assert(t, ast.Senums[1].Name == "Empty" && len(ast.Senums[1].Values) == 0, ast.Senums[1])
+	test.Assert(t, len(ast.Structs) == 1, ast.Structs)
+
+	_, err = parser.ParseString("main.thrift", "senum Color { red }")
+	test.Assert(t, err != nil, err)
+}
+
+func TestDialect(t *testing.T) {
+	src := `
+@thrift.Experimental
+struct Chunk {
+	@cpp.Type{name = "folly::IOBuf", template = 1}
+	1: binary data
+}
+service Feed {
+	stream<Chunk> subscribe(1: string topic)
+	@hack.Name{name = "upload"}
+	string put(1: string topic) throws (1: Err e)
+	sink<Chunk, i64> publish(1: string topic)
+	stream f()
+}
+`
+	_, err := parser.ParseString("main.thrift", src)
+	test.Assert(t, err != nil && err.Error() == "2:1: structured annotation @thrift.Experimental is not supported in dialect apache, it is a construct of dialect fb", err)
+	_, err = parser.ParseString("main.thrift", "service S { stream<i32> f() }")
+	test.Assert(t, err != nil && err.Error() == "1:13: stream is not supported in dialect apache, it is a construct of dialect fb", err)
+	_, err = parser.ParseString("main.thrift", "service S {\n\tsink<i32, i64> f()\n}")
+	test.Assert(t, err != nil && err.Error() == "2:2: sink is not supported in dialect apache, it is a construct of dialect fb", err)
+	// a type named stream is still a type
+	ast, err := parser.ParseString("main.thrift", "typedef i32 stream\nservice S { stream f() }")
+	test.Assert(t, err == nil && ast.Services[0].Functions[0].FunctionType.Name == "stream", err)
+
+	parser.SetDialect(parser.DialectFB)
+	defer parser.SetDialect(parser.DialectApache)
+	ast, err = parser.ParseString("main.thrift", src)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, ast.Structs[0].Position.Line == 3 && ast.Structs[0].Fields[0].Position.Line == 5, ast.Structs[0])
+	fs := ast.Services[0].Functions
+	test.Assert(t, len(fs) == 4, fs)
+	test.Assert(t, fs[0].FunctionType.Name == "Chunk" && fs[0].SinkType == nil, fs[0])
+	test.Assert(t, fs[0].Annotations.ILocValueByKey("streaming.mode", 0) == "server", fs[0].Annotations)
+	test.Assert(t, fs[1].Name == "put" && fs[1].Position.Line == 10 && len(fs[1].Annotations) == 0, fs[1])
+	test.Assert(t, fs[2].FunctionType.Name == "i64" && fs[2].SinkType.Name == "Chunk" && len(fs[2].Annotations) == 0, fs[2])
+	test.Assert(t, fs[3].FunctionType.Name == "stream", fs[3])
+
+	_, err = parser.ParseString("main.thrift", `service S { stream<i32> f() (streaming.mode = "server") }`)
+	test.Assert(t, err != nil && err.Error() == "1:13: function f: stream can not be used with the streaming.mode annotation", err)
+	_, err = parser.ParseString("main.thrift", "\nsenum Color {}")
+	test.Assert(t, err != nil && err.Error() == "2:1: senum is not supported in dialect fb, it is a construct of dialect apache", err)
+
+	// the definitions of a stream are cut before their structured annotations
+	var names []string
+	err = parser.ParseStream(strings.NewReader(src), parser.VisitorFunc(func(n interface{}) error {
+		if s, ok := n.(*parser.StructLike); ok {
+			names = append(names, s.Name)
+		}
+		return nil
+	}))
+	test.Assert(t, err == nil, err)
+	test.Assert(t, strings.Join(names, ",") == "Chunk", names)
+}
+
+func TestStructuredAnnotations(t *testing.T) {
+	parser.SetDialect(parser.DialectFB)
+	defer parser.SetDialect(parser.DialectApache)
+	ast, err := parser.ParseString("main.thrift", `
+@thrift.Experimental
+@cpp.Adapter{name = "Adapter", types = ["a", "b"]}
+struct S {
+	@cpp.Type{name = "folly::IOBuf"} 1: binary data (go.tag = 'json:"d"')
+}
+@hack.Name{name = E.A}
+enum E { A }
+service Svc {
+	@thrift.Priority{level = 1,}
+	void f()
+}`)
+	test.Assert(t, err == nil, err)
+	s := ast.Structs[0]
+	test.Assert(t, len(s.StructuredAnnotations) == 2 && s.Position.Line == 4, s)
+	test.Assert(t, s.StructuredAnnotations[0].Name == "thrift.Experimental" && len(s.StructuredAnnotations[0].Fields) == 0)
+	sa := s.StructuredAnnotations.Get("cpp.Adapter")
+	test.Assert(t, sa != nil && sa.Position.Line == 3 && sa.Position.Col == 1, sa)
+	test.Assert(t, sa.Get("name").TypedValue.GetLiteral() == "Adapter", sa.Fields[0])
+	test.Assert(t, len(sa.Get("types").TypedValue.GetList()) == 2 && sa.Get("x") == nil, sa.Fields[1])
+
+	f := s.Fields[0]
+	test.Assert(t, f.StructuredAnnotations.Get("cpp.Type").Get("name").TypedValue.GetLiteral() == "folly::IOBuf", f)
+	test.Assert(t, f.ID == 1 && f.Position.Col == 35 && f.Annotations.Get("go.tag")[0] == `json:"d"`, f)
+
+	id := ast.Enums[0].StructuredAnnotations.Get("hack.Name").Get("name")
+	test.Assert(t, id.Type == parser.ConstType_ConstIdentifier && id.TypedValue.GetIdentifier() == "E.A", id)
+	fn := ast.Services[0].Functions[0]
+	test.Assert(t, fn.StructuredAnnotations.Get("thrift.Priority").Get("level").TypedValue.GetInt() == 1, fn)
+	test.Assert(t, len(ast.Services[0].StructuredAnnotations) == 0)
+
+	var visited []string
+	err = parser.Walk(ast, parser.VisitorFunc(func(n interface{}) error {
+		if sa, ok := n.(*parser.StructuredAnnotation); ok {
+			visited = append(visited, sa.Name)
+		}
+		return nil
+	}))
+	test.Assert(t, err == nil, err)
+	test.Assert(t, strings.Join(visited, ",") == "hack.Name,cpp.Type,thrift.Experimental,cpp.Adapter,thrift.Priority", visited)
+
+	_, err = parser.ParseString("main.thrift", "\n@a.B{x = 1, x = 2}\nstruct S {}")
+	test.Assert(t, err != nil && err.Error() == `2:1: structured annotation @a.B: field "x" is set more than once`, err)
+}
+
+func TestIncludeSearch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := func(p string) string { return filepath.Join(dir, p) }
+	writeFile(t, path("main/main.thrift"), `include "a.thrift"
+include "b.thrift"`)
+	writeFile(t, path("main/b.thrift"), "struct B {}")
+	writeFile(t, path("i1/a.thrift"), "struct A {}")
+	writeFile(t, path("i2/a.thrift"), "struct A {}")
+	writeFile(t, path("i2/b.thrift"), "struct B {}")
+
+	var logs []string
+	parser.SetSearchOptions(parser.SearchOptions{Log: func(msg string) { logs = append(logs, msg) }})
+	defer parser.SetSearchOptions(parser.SearchOptions{})
+
+	// the directory of the IDL first, then the include dirs in order
+	ast, err := parser.ParseFile(path("main/main.thrift"), []string{path("i1"), path("i2")}, true)
+	test.Assert(t, err == nil, err)
+	resolved := func(i int) string {
+		abs, err := filepath.Abs(ast.Includes[i].ResolvedPath)
+		test.Assert(t, err == nil, err)
+		return abs
+	}
+	test.Assert(t, resolved(0) == path("i1/a.thrift"), ast.Includes[0].ResolvedPath)
+	test.Assert(t, resolved(1) == path("main/b.thrift"), ast.Includes[1].ResolvedPath)
+	test.Assert(t, ast.Includes[0].ResolvedPath == ast.Includes[0].Reference.Filename)
+
+	test.Assert(t, len(logs) == 3, logs)
+	test.Assert(t, strings.Contains(logs[1], `search "a.thrift" from `), logs[1])
+	test.Assert(t, strings.Contains(logs[1], "a.thrift (not found), "), logs[1])
+	test.Assert(t, strings.Contains(logs[1], "a.thrift (found), "), logs[1])
+	test.Assert(t, strings.Contains(logs[1], "a.thrift (shadowed), "), logs[1])
+
+	parser.SetSearchOptions(parser.SearchOptions{StrictDuplicates: true})
+	_, err = parser.ParseFile(path("main/main.thrift"), []string{path("i1"), path("i2")}, true)
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `search "a.thrift" from `) &&
+		strings.Contains(err.Error(), "found at more than one place"), err)
+
+	_, err = parser.ParseFile(path("main/main.thrift"), []string{path("i1")}, true)
+	test.Assert(t, err == nil, err)
+}
@@ -0,0 +1,329 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schemes of includes that are not plain filesystem paths.
+const (
+	schemeHTTP   = "http://"
+	schemeHTTPS  = "https://"
+	schemeModule = "module://"
+
+	checksumPrefix = "sha256="
+)
+
+// RemoteOptions configures how URL-form includes (http:// and https://) and
+// go-module includes (module://) are resolved.
+//
+// A URL-form include may carry a checksum as its fragment, for example:
+//
+//	include "https://idl.example.com/common.thrift#sha256=<hex digest>"
+//
+// The fetched content is rejected if it does not match the checksum.
+type RemoteOptions struct {
+	// CacheDir is the directory to store fetched IDLs.
+	// Defaults to "thriftgo/idl" under the user cache directory.
+	CacheDir string
+	// Client is used to fetch URL-form includes. Defaults to a client with a 30s timeout.
+	Client *http.Client
+}
+
+// RemoteError is returned when a remote include can not be resolved.
+type RemoteError struct {
+	Include  string
+	NotFound bool // true if the server or the module reports that the IDL does not exist
+	Err      error
+}
+
+func (e *RemoteError) Error() string {
+	if e.NotFound {
+		return fmt.Sprintf("remote include %q not found: %v", e.Include, e.Err)
+	}
+	return fmt.Sprintf("fetch remote include %q: %v", e.Include, e.Err)
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+var remote = struct {
+	sync.Mutex
+	opts    RemoteOptions
+	origins map[string]string // cached file => URL
+}{
+	origins: make(map[string]string),
+}
+
+// SetRemoteOptions changes the options for resolving remote includes.
+func SetRemoteOptions(opts RemoteOptions) {
+	remote.Lock()
+	defer remote.Unlock()
+	remote.opts = opts
+}
+
+func isRemote(file string) bool {
+	return strings.HasPrefix(file, schemeHTTP) || strings.HasPrefix(file, schemeHTTPS)
+}
+
+func isModule(file string) bool {
+	return strings.HasPrefix(file, schemeModule)
+}
+
+// remoteDir returns the URL of the directory containing the given cached file
+// if the file is fetched from a remote include.
+func remoteDir(file string) (string, bool) {
+	remote.Lock()
+	defer remote.Unlock()
+	u, ok := remote.origins[file]
+	if !ok {
+		return "", false
+	}
+	return u[:strings.LastIndex(u, "/")+1], true
+}
+
+// joinURL resolves a relative include against the directory URL of a remote IDL.
+func joinURL(dir, file string) string {
+	base, err := url.Parse(dir)
+	if err != nil {
+		return dir + file
+	}
+	ref, err := url.Parse(file)
+	if err != nil {
+		return dir + file
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func cacheDir() (string, error) {
+	if remote.opts.CacheDir != "" {
+		return remote.opts.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no cache dir for remote includes: %w", err)
+	}
+	return filepath.Join(dir, "thriftgo", "idl"), nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// fetchRemote downloads the URL-form include into the cache directory and
+// returns the path of the cached file. A cached file is reused as long as it
+// matches the checksum recorded when it was fetched.
+func fetchRemote(include string) (string, error) {
+	remote.Lock()
+	defer remote.Unlock()
+
+	addr, want := include, ""
+	if idx := strings.Index(include, "#"); idx >= 0 {
+		addr, want = include[:idx], include[idx+1:]
+		if !strings.HasPrefix(want, checksumPrefix) {
+			return "", &RemoteError{Include: include, Err: fmt.Errorf("unsupported checksum %q, expect '%s<hex>'", want, checksumPrefix)}
+		}
+		want = strings.ToLower(strings.TrimPrefix(want, checksumPrefix))
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(strings.SplitN(addr, "?", 2)[0])
+	file := filepath.Join(dir, checksum([]byte(addr))[:16], name)
+	sumFile := file + ".sha256"
+
+	if bs, err := ioutil.ReadFile(file); err == nil {
+		recorded, _ := ioutil.ReadFile(sumFile)
+		got := checksum(bs)
+		if got == strings.TrimSpace(string(recorded)) && (want == "" || want == got) {
+			remote.origins[file] = addr
+			return file, nil
+		}
+	}
+
+	client := remote.opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Get(addr)
+	if err != nil {
+		return "", &RemoteError{Include: include, Err: err}
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return "", &RemoteError{Include: include, NotFound: true, Err: errors.New(resp.Status)}
+	case resp.StatusCode != http.StatusOK:
+		return "", &RemoteError{Include: include, Err: errors.New(resp.Status)}
+	}
+	bs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", &RemoteError{Include: include, Err: err}
+	}
+	if got := checksum(bs); want != "" && got != want {
+		return "", &RemoteError{Include: include, Err: fmt.Errorf("checksum mismatch: expect %s, got %s", want, got)}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return "", err
+	}
+	if err = ioutil.WriteFile(file, bs, 0o644); err != nil {
+		return "", err
+	}
+	if err = ioutil.WriteFile(sumFile, []byte(checksum(bs)+"\n"), 0o644); err != nil {
+		return "", err
+	}
+	remote.origins[file] = addr
+	return file, nil
+}
+
+// resolveModule maps a go-module include "module://<module path>/<file>" to a local
+// file. The module is looked up in the go.mod file nearest to dir: the main module is
+// resolved to its own directory and the required modules to the module cache.
+func resolveModule(include, dir string) (string, error) {
+	target := strings.TrimPrefix(include, schemeModule)
+	gomod, err := findGoMod(dir)
+	if err != nil {
+		return "", &RemoteError{Include: include, Err: err}
+	}
+	mods, err := readGoMod(gomod)
+	if err != nil {
+		return "", &RemoteError{Include: include, Err: err}
+	}
+
+	var mod, rel string
+	for m := range mods {
+		if (target == m || strings.HasPrefix(target, m+"/")) && len(m) > len(mod) {
+			mod, rel = m, strings.TrimPrefix(target[len(m):], "/")
+		}
+	}
+	if mod == "" {
+		return "", &RemoteError{Include: include, NotFound: true, Err: fmt.Errorf("no module in %s provides it", gomod)}
+	}
+
+	root := mods[mod]
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(filepath.Dir(gomod), root)
+	}
+	if mods[mod] == "" {
+		root, err = moduleCacheDir(mod, gomod)
+		if err != nil {
+			return "", &RemoteError{Include: include, Err: err}
+		}
+	}
+	file := filepath.Join(root, filepath.FromSlash(rel))
+	if !exists(file) {
+		return "", &RemoteError{Include: include, NotFound: true, Err: os.ErrNotExist}
+	}
+	return normalizeFilename(file), nil
+}
+
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if p := filepath.Join(dir, "go.mod"); exists(p) {
+			return p, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// readGoMod returns the main module and the required ones in the go.mod file.
+// A module maps to a local directory if it is the main module or is replaced by a
+// local path; otherwise it maps to an empty string.
+func readGoMod(gomod string) (map[string]string, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mods := make(map[string]string)
+	directive := func(verb string, args []string) {
+		switch verb {
+		case "module":
+			mods[strings.Trim(args[0], `"`)] = "."
+		case "require":
+			if _, ok := mods[args[0]]; !ok {
+				mods[args[0]] = ""
+			}
+		case "replace":
+			for i := 0; i+1 < len(args); i++ {
+				if p := args[i+1]; args[i] == "=>" && (strings.HasPrefix(p, ".") || filepath.IsAbs(p)) {
+					mods[args[0]] = p
+				}
+			}
+		}
+	}
+
+	var block string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.SplitN(scanner.Text(), "//", 2)[0])
+		switch {
+		case len(fields) == 0:
+		case block != "" && fields[0] == ")":
+			block = ""
+		case block != "":
+			directive(block, fields)
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+		case len(fields) >= 2:
+			directive(fields[0], fields[1:])
+		}
+	}
+	return mods, scanner.Err()
+}
+
+// moduleCacheDir asks the go command for the directory of a required module.
+func moduleCacheDir(mod, gomod string) (string, error) {
+	cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", mod)
+	cmd.Dir = filepath.Dir(gomod)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go list -m %s: %w", mod, err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if dir == "" {
+		return "", fmt.Errorf("module %s is not downloaded", mod)
+	}
+	return dir, nil
+}
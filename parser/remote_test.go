@@ -0,0 +1,122 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteInclude(t *testing.T) {
+	const common = `include "base.thrift"
+struct Common { 1: base.Base b }`
+	const base = `struct Base {}`
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/idl/common.thrift":
+			w.Write([]byte(common))
+		case "/idl/base.thrift":
+			w.Write([]byte(base))
+		case "/broken.thrift":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	parser.SetRemoteOptions(parser.RemoteOptions{CacheDir: filepath.Join(dir, "cache")})
+	defer parser.SetRemoteOptions(parser.RemoteOptions{})
+
+	sum := sha256.Sum256([]byte(common))
+	main := filepath.Join(dir, "main.thrift")
+	writeFile(t, main, `include "`+srv.URL+`/idl/common.thrift#sha256=`+hex.EncodeToString(sum[:])+`"
+struct Main { 1: common.Common c }`)
+
+	ast, err := parser.ParseFile(main, nil, true)
+	test.Assert(t, err == nil, err)
+	ref := ast.Includes[0].Reference
+	test.Assert(t, ref.Structs[0].Name == "Common")
+	test.Assert(t, ref.Includes[0].Reference.Structs[0].Name == "Base")
+	test.Assert(t, hits == 2, hits)
+
+	// cached
+	_, err = parser.ParseFile(main, nil, true)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, hits == 2, hits)
+
+	var re *parser.RemoteError
+	writeFile(t, main, `include "`+srv.URL+`/idl/common.thrift#sha256=0000"`)
+	_, err = parser.ParseFile(main, nil, true)
+	test.Assert(t, errors.As(err, &re) && !re.NotFound, err)
+
+	writeFile(t, main, `include "`+srv.URL+`/missing.thrift"`)
+	_, err = parser.ParseFile(main, nil, true)
+	test.Assert(t, errors.As(err, &re) && re.NotFound, err)
+
+	writeFile(t, main, `include "`+srv.URL+`/broken.thrift"`)
+	_, err = parser.ParseFile(main, nil, true)
+	test.Assert(t, errors.As(err, &re) && !re.NotFound, err)
+}
+
+func TestModuleInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\nrequire (\n\texample.com/idl v1.0.0\n)\n\nreplace example.com/idl => ./third_party/idl\n")
+	writeFile(t, filepath.Join(dir, "idl", "base.thrift"), `struct Base {}`)
+	writeFile(t, filepath.Join(dir, "third_party", "idl", "shared.thrift"), `struct Shared {}`)
+	main := filepath.Join(dir, "service", "main.thrift")
+	writeFile(t, main, `include "module://example.com/app/idl/base.thrift"
+include "module://example.com/idl/shared.thrift"`)
+
+	ast, err := parser.ParseFile(main, nil, true)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, ast.Includes[0].Reference.Structs[0].Name == "Base")
+	test.Assert(t, ast.Includes[1].Reference.Structs[0].Name == "Shared")
+
+	var re *parser.RemoteError
+	writeFile(t, main, `include "module://example.com/app/idl/missing.thrift"`)
+	_, err = parser.ParseFile(main, nil, true)
+	test.Assert(t, errors.As(err, &re) && re.NotFound, err)
+}
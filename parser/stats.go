@@ -0,0 +1,95 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import "sort"
+
+// Stats is the number of each kind of definitions in IDLs.
+type Stats struct {
+	Typedefs   int `json:"typedefs"`
+	Constants  int `json:"constants"`
+	Enums      int `json:"enums"`
+	EnumValues int `json:"enum_values"` // the values of the enums
+	Structs    int `json:"structs"`
+	Unions     int `json:"unions"`
+	Exceptions int `json:"exceptions"`
+	Fields     int `json:"fields"` // the fields of the structs, unions and exceptions
+	Services   int `json:"services"`
+	Methods    int `json:"methods"` // the functions of the services
+}
+
+// FileStats is the Stats of an IDL.
+type FileStats struct {
+	Filename string `json:"file"`
+	Stats
+}
+
+// Count returns the Stats of the definitions in the IDL, without its includes.
+func Count(t *Thrift) Stats {
+	s := Stats{
+		Typedefs:   len(t.Typedefs),
+		Constants:  len(t.Constants),
+		Enums:      len(t.Enums),
+		Structs:    len(t.Structs),
+		Unions:     len(t.Unions),
+		Exceptions: len(t.Exceptions),
+		Services:   len(t.Services),
+	}
+	for _, e := range t.Enums {
+		s.EnumValues += len(e.Values)
+	}
+	for _, st := range t.GetStructLikes() {
+		s.Fields += len(st.Fields)
+	}
+	for _, svc := range t.Services {
+		s.Methods += len(svc.Functions)
+	}
+	return s
+}
+
+// Add adds the numbers of o to s.
+func (s *Stats) Add(o Stats) {
+	s.Typedefs += o.Typedefs
+	s.Constants += o.Constants
+	s.Enums += o.Enums
+	s.Structs += o.Structs
+	s.Unions += o.Unions
+	s.Exceptions += o.Exceptions
+	s.Services += o.Services
+	s.Methods += o.Methods
+	s.Fields += o.Fields
+	s.EnumValues += o.EnumValues
+}
+
+// CountFiles returns the Stats of the IDL, and of the IDLs it includes directly
+// or not if recursive is true, sorted by their filenames, and the total of them.
+func CountFiles(t *Thrift, recursive bool) (files []*FileStats, total Stats) {
+	asts := []*Thrift{t}
+	if recursive {
+		asts = nil
+		for ast := range t.DepthFirstSearch() {
+			asts = append(asts, ast)
+		}
+	}
+	for _, ast := range asts {
+		s := Count(ast)
+		files = append(files, &FileStats{Filename: ast.Filename, Stats: s})
+		total.Add(s)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+	return files, total
+}
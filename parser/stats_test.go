@@ -0,0 +1,66 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestCountFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thriftgo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := func(p string) string { return filepath.Join(dir, p) }
+	writeFile(t, path("z.thrift"), `typedef i64 ID
+const i32 N = 1
+enum E { A, B, C }`)
+	writeFile(t, path("b.thrift"), `include "z.thrift"
+struct S { 1: z.ID id 2: string name }
+union U { 1: i32 a }
+exception X { 1: string msg }
+service Base { void ping() }
+service Svc extends Base { S get(1: z.ID id), oneway void put(1: S s) }`)
+	writeFile(t, path("a.thrift"), `include "b.thrift"
+include "z.thrift"
+struct T { 1: b.S s }`)
+
+	ast, err := parser.ParseFile(path("a.thrift"), nil, true)
+	test.Assert(t, err == nil, err)
+
+	files, total := parser.CountFiles(ast, false)
+	test.Assert(t, len(files) == 1 && filepath.Base(files[0].Filename) == "a.thrift", files)
+	test.Assert(t, total == parser.Stats{Structs: 1, Fields: 1}, total)
+
+	files, total = parser.CountFiles(ast, true)
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.Filename))
+	}
+	test.Assert(t, len(names) == 3 && names[0] == "a.thrift" && names[1] == "b.thrift" && names[2] == "z.thrift", names)
+	test.Assert(t, files[1].Stats == parser.Stats{Structs: 1, Unions: 1, Exceptions: 1, Fields: 4, Services: 2, Methods: 3}, files[1].Stats)
+	test.Assert(t, files[2].Stats == parser.Stats{Typedefs: 1, Constants: 1, Enums: 1, EnumValues: 3}, files[2].Stats)
+	test.Assert(t, total == parser.Stats{
+		Typedefs: 1, Constants: 1, Enums: 1, EnumValues: 3, Structs: 2, Unions: 1,
+		Exceptions: 1, Fields: 5, Services: 2, Methods: 3,
+	}, total)
+}
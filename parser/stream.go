@@ -0,0 +1,361 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+)
+
+// ParseStream parses an IDL read from r and calls Walk with the visitor on each
+// include, namespace and definition as soon as it is parsed, in the order of
+// the IDL. Only the definition being parsed is kept in memory, so tools that
+// analyze a large IDL do not need the whole AST to be resident.
+//
+// The nodes are not semantically resolved and the includes are not read. The
+// checks that span several definitions, such as the ones for duplicate names,
+// are not done. A cpp_include is not a node and is not visited.
+//
+// If the visitor returns an error other than SkipChildren, the parsing stops
+// and the error is returned.
+func ParseStream(r io.Reader, visitor Visitor) error {
+	s := &streamer{r: bufio.NewReader(r), line: 1, col: 1, tok: -1, anno: -1, dialect: CurrentDialect()}
+	for {
+		chunk, line, col, err := s.next()
+		if err != nil {
+			return err
+		}
+		if chunk == nil {
+			return nil
+		}
+		if err := s.parse(chunk, line, col, visitor); err != nil {
+			return err
+		}
+	}
+}
+
+// streamKeywords are the keywords that start a header or a definition.
+var streamKeywords = map[string]bool{
+	"include":     true,
+	"cpp_include": true,
+	"namespace":   true,
+	"const":       true,
+	"typedef":     true,
+	"enum":        true,
+	"senum":       true,
+	"struct":      true,
+	"union":       true,
+	"exception":   true,
+	"service":     true,
+}
+
+const (
+	inCode = iota
+	inString
+	inLineComment
+	inBlockComment
+)
+
+// streamer splits an IDL into chunks of a header or a definition each. A chunk
+// ends at the last token before the keyword of the next one, so that the
+// comments in between go along with the definition that they precede.
+type streamer struct {
+	r       *bufio.Reader
+	started bool
+	peeked  bool // peek is read ahead and returned by the next readRune
+	peek    rune
+	eof     bool
+	defined bool // a definition has been parsed
+
+	buf       []rune
+	line, col int // the position of buf[0]
+	sigEnd    int // the end of the last token in buf
+	tok       int // the beginning of the word being read, or -1
+	tokSig    int // sigEnd before the word being read
+	anno      int // the beginning of the structured annotations before a definition, or -1
+	annoSig   int // sigEnd before the structured annotations
+	dialect   Dialect
+
+	state int
+	quote rune
+	depth int
+}
+
+// next returns the next chunk and its position, or a nil chunk at the end.
+func (s *streamer) next() (chunk []rune, line, col int, err error) {
+	for chunk == nil && !s.eof {
+		c, err := s.readRune()
+		if err == io.EOF {
+			s.eof = true
+			chunk = s.endWord()
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		chunk = s.feed(c)
+	}
+	if chunk == nil && s.sigEnd > 0 {
+		chunk, s.buf, s.sigEnd = s.buf, nil, 0
+	}
+	if chunk == nil {
+		return nil, 0, 0, nil
+	}
+	line, col = s.line, s.col
+	for _, c := range chunk {
+		if c == '\n' {
+			s.line, s.col = s.line+1, 1
+		} else {
+			s.col++
+		}
+	}
+	return chunk, line, col, nil
+}
+
+// readRune reads a rune with the source normalized as NormalizeSource does.
+func (s *streamer) readRune() (rune, error) {
+	if s.peeked {
+		s.peeked = false
+		return s.peek, nil
+	}
+	c, _, err := s.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if !s.started {
+		s.started = true
+		if c == '\uFEFF' {
+			return s.readRune()
+		}
+	}
+	if c == '\r' {
+		if n, _, err := s.r.ReadRune(); err == nil && n != '\n' {
+			s.r.UnreadRune()
+		}
+		c = '\n'
+	}
+	return c, nil
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// feed appends c to the buffer and returns the chunk that it completes if any.
+func (s *streamer) feed(c rune) (chunk []rune) {
+	switch s.state {
+	case inString:
+		s.buf = append(s.buf, c)
+		s.sigEnd = len(s.buf)
+		if c == '\\' {
+			if n, err := s.readRune(); err == nil {
+				s.buf = append(s.buf, n)
+				s.sigEnd = len(s.buf)
+			}
+		} else if c == s.quote {
+			s.state = inCode
+		}
+		return nil
+	case inLineComment:
+		s.buf = append(s.buf, c)
+		if c == '\n' {
+			s.state = inCode
+		}
+		return nil
+	case inBlockComment:
+		s.buf = append(s.buf, c)
+		if c == '/' && len(s.buf) > 1 && s.buf[len(s.buf)-2] == '*' {
+			s.state = inCode
+		}
+		return nil
+	}
+
+	if s.tok >= 0 && !isWordRune(c) {
+		chunk = s.endWord()
+	}
+	s.buf = append(s.buf, c)
+	switch {
+	case unicode.IsSpace(c):
+	case c == '#':
+		s.state = inLineComment
+	case c == '/':
+		if n, err := s.readRune(); err == nil {
+			if n == '/' || n == '*' {
+				s.buf = append(s.buf, n)
+				s.state = inLineComment
+				if n == '*' {
+					s.state = inBlockComment
+				}
+				return chunk
+			}
+			s.peeked, s.peek = true, n
+		}
+		s.sigEnd = len(s.buf)
+	case c == '"' || c == '\'':
+		s.state, s.quote = inString, c
+		s.sigEnd = len(s.buf)
+	case isWordRune(c):
+		if s.tok < 0 {
+			s.tok, s.tokSig = len(s.buf)-1, s.sigEnd
+		}
+	default:
+		switch c {
+		case '{', '(', '[':
+			s.depth++
+		case '}', ')', ']':
+			s.depth--
+		case '@':
+			if s.depth == 0 && s.anno < 0 {
+				s.anno, s.annoSig = len(s.buf)-1, s.sigEnd
+			}
+		}
+		s.sigEnd = len(s.buf)
+	}
+	return chunk
+}
+
+// endWord finishes the word being read. When it is a keyword that starts a new
+// header or definition, the buffer before it, or before the structured
+// annotations of the definition, is cut off and returned.
+func (s *streamer) endWord() (chunk []rune) {
+	if s.tok < 0 {
+		return nil
+	}
+	tok, tokSig, end := s.tok, s.tokSig, len(s.buf)
+	s.tok, s.sigEnd = -1, end
+	if s.depth != 0 || !streamKeywords[string(s.buf[tok:end])] {
+		return nil
+	}
+	if s.anno >= 0 {
+		tok, tokSig, s.anno = s.anno, s.annoSig, -1
+	}
+	if tokSig == 0 {
+		return nil
+	}
+	cut := skipLine(s.buf[:tok], tokSig)
+	chunk = s.buf[:cut:cut]
+	s.buf = append([]rune(nil), s.buf[cut:]...)
+	s.sigEnd -= cut
+	return chunk
+}
+
+// skipLine returns the end of the spaces and comments from i to the end of the
+// line in buf, which the grammar (SkipLine) attaches to the preceding definition.
+func skipLine(buf []rune, i int) int {
+	for i < len(buf) {
+		switch c := buf[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '#' || c == '/' && i+1 < len(buf) && buf[i+1] == '/':
+			for i < len(buf) && buf[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(buf) && buf[i+1] == '*':
+			i += 2
+			for i < len(buf) && !(buf[i-1] == '*' && buf[i] == '/') {
+				i++
+			}
+			i++
+		default:
+			return i
+		}
+	}
+	return len(buf)
+}
+
+// parse parses a chunk that begins at the line and column of the IDL and
+// visits the nodes in it.
+func (s *streamer) parse(chunk []rune, line, col int, visitor Visitor) error {
+	p := &parser{lineOffset: int32(line - 1), colOffset: int32(col - 1), dialect: s.dialect}
+	p.Buffer = string(chunk)
+	p.Init()
+	if err := p.ThriftIDL.Parse(); err != nil {
+		if pe, ok := err.(*parseError); ok {
+			return shiftParseError(pe, line, col)
+		}
+		return err
+	}
+	if err := p.parse(); err != nil {
+		return err
+	}
+
+	t := &p.Thrift
+	var nodes []interface{}
+	for _, v := range t.Includes {
+		nodes = append(nodes, v)
+	}
+	for _, v := range t.Namespaces {
+		nodes = append(nodes, v)
+	}
+	headers := len(nodes)
+	if s.defined && headers+len(t.CppIncludes) > 0 {
+		if headers > 0 {
+			switch n := nodes[0].(type) {
+			case *Include:
+				line, col = int(n.Position.Line), int(n.Position.Col)
+			case *Namespace:
+				line, col = int(n.Position.Line), int(n.Position.Col)
+			}
+		}
+		return fmt.Errorf("%d:%d: headers must precede the definitions", line, col)
+	}
+	for _, v := range t.Typedefs {
+		nodes = append(nodes, v)
+	}
+	for _, v := range t.Constants {
+		nodes = append(nodes, v)
+	}
+	for _, v := range t.Enums {
+		nodes = append(nodes, v)
+	}
+	for _, v := range t.Senums {
+		nodes = append(nodes, v)
+	}
+	for _, ss := range [][]*StructLike{t.Structs, t.Unions, t.Exceptions} {
+		for _, v := range ss {
+			nodes = append(nodes, v)
+		}
+	}
+	for _, v := range t.Services {
+		nodes = append(nodes, v)
+	}
+	s.defined = s.defined || len(nodes) > headers
+	for _, n := range nodes {
+		if err := Walk(n, visitor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shiftParseError formats a parse error of a chunk with the positions in the
+// IDL that the chunk begins at line and col of.
+func shiftParseError(e *parseError, line, col int) error {
+	begin, end := int(e.max.begin), int(e.max.end)
+	ts := translatePositions(e.p.buffer, []int{begin, end})
+	shift := func(t textPosition) (int, int) {
+		if t.line == 1 {
+			return t.line + line - 1, t.symbol + col - 1
+		}
+		return t.line + line - 1, t.symbol
+	}
+	bl, bs := shift(ts[begin])
+	el, es := shift(ts[end])
+	return fmt.Errorf("\nparse error near %v (line %v symbol %v - line %v symbol %v):\n%v\n",
+		rul3s[e.max.pegRule], bl, bs, el, es, strconv.Quote(string(e.p.buffer[begin:end])))
+}
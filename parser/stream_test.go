@@ -0,0 +1,127 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestParseStream(t *testing.T) {
+	idl := "\uFEFFinclude \"b.thrift\"\r\n" + `namespace go a // struct in a comment
+typedef map<string, list<i32>> M (k = "struct {")
+const string S = "enum E {"; const i32 N = 1 /* service */
+# const i32 X = 2
+enum E { A = 1, B = 2 }
+/**
+ * The struct.
+ */
+struct T {
+	1: required string s = 'union' (k = "v")
+	2: M m
+} (a = "b") struct U { 1: T t }
+union V { 1: i32 i }
+exception X { 1: string msg }
+service Svc {
+	T get(1: T t) throws (1: X x)
+}
+// the end
+`
+	ast, err := parser.ParseString("a.thrift", idl)
+	test.Assert(t, err == nil, err)
+
+	name := func(node interface{}) string {
+		var pos *parser.Position
+		var s string
+		switch n := node.(type) {
+		case *parser.Include:
+			pos, s = n.Position, "Include:"+n.Path
+		case *parser.Namespace:
+			pos, s = n.Position, "Namespace:"+n.Name
+		case *parser.Typedef:
+			pos, s = n.Position, "Typedef:"+n.Alias
+		case *parser.Constant:
+			pos, s = n.Position, "Constant:"+n.Name
+		case *parser.Enum:
+			pos, s = n.Position, "Enum:"+n.Name+n.ReservedComments
+		case *parser.StructLike:
+			pos, s = n.Position, n.Category+":"+n.Name+n.ReservedComments
+		case *parser.Field:
+			pos, s = n.Position, "Field:"+n.Name
+		case *parser.Service:
+			pos, s = n.Position, "Service:"+n.Name
+		case *parser.Function:
+			pos, s = n.Position, "Function:"+n.Name
+		case *parser.Annotation:
+			return "Annotation:" + n.Key + "=" + strings.Join(n.Values, ",")
+		default:
+			return fmt.Sprintf("%T", node)
+		}
+		return fmt.Sprintf("%s@%d:%d", s, pos.Line, pos.Col)
+	}
+	var want, got []string
+	err = parser.Walk(ast, parser.VisitorFunc(func(node interface{}) error {
+		if _, ok := node.(*parser.Thrift); !ok {
+			want = append(want, name(node))
+		}
+		return nil
+	}))
+	test.Assert(t, err == nil, err)
+	err = parser.ParseStream(strings.NewReader(idl), parser.VisitorFunc(func(node interface{}) error {
+		got = append(got, name(node))
+		return nil
+	}))
+	test.Assert(t, err == nil, err)
+	test.Assert(t, strings.Join(got, "\n") == strings.Join(want, "\n"), strings.Join(want, "\n"))
+
+	// SkipChildren skips the children of a definition only.
+	got = nil
+	err = parser.ParseStream(strings.NewReader(idl), parser.VisitorFunc(func(node interface{}) error {
+		got = append(got, name(node))
+		return parser.SkipChildren
+	}))
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(got) == 11, strings.Join(got, "\n"))
+
+	// Any other error stops the parsing.
+	stop := fmt.Errorf("stop")
+	got = nil
+	err = parser.ParseStream(strings.NewReader(idl), parser.VisitorFunc(func(node interface{}) error {
+		got = append(got, name(node))
+		if _, ok := node.(*parser.Enum); ok {
+			return stop
+		}
+		return nil
+	}))
+	test.Assert(t, err == stop, err)
+	test.Assert(t, strings.HasPrefix(got[len(got)-1], "Enum:E"), got)
+
+	// The errors are positioned in the whole IDL as ParseString does.
+	for _, src := range []string{
+		"struct A {}\nstruct B {\n\t1: i32\n}",
+		"struct A {} struct B { 1: }",
+		"struct A {}\n\nconst i32 C = 1 << 2",
+	} {
+		_, want := parser.ParseString("", src)
+		err = parser.ParseStream(strings.NewReader(src), parser.VisitorFunc(func(interface{}) error { return nil }))
+		test.Assert(t, want != nil && err != nil && err.Error() == want.Error(), err, want)
+	}
+	err = parser.ParseStream(strings.NewReader("struct A {}\n  include \"b.thrift\""), parser.VisitorFunc(func(interface{}) error { return nil }))
+	test.Assert(t, err != nil && err.Error() == "2:3: headers must precede the definitions", err)
+}
@@ -28,15 +28,19 @@ const (
 	ruleConst
 	ruleTypedef
 	ruleEnum
+	ruleSenum
 	ruleService
 	ruleStruct
 	ruleUnion
 	ruleException
+	ruleReserved
 	ruleField
 	ruleFieldId
 	ruleFieldReq
 	ruleFunction
 	ruleFunctionType
+	ruleStreamType
+	ruleSinkType
 	ruleThrows
 	ruleFieldType
 	ruleBaseType
@@ -46,11 +50,15 @@ const (
 	ruleListType
 	ruleCppType
 	ruleConstValue
+	ruleConstExpr
+	ruleConstAndExpr
+	ruleConstTerm
 	ruleIntConstant
 	ruleDoubleConstant
 	ruleExponent
 	ruleAnnotations
 	ruleAnnotation
+	ruleStructuredAnnotation
 	ruleConstList
 	ruleConstMap
 	ruleEscapeLiteralChar
@@ -94,10 +102,16 @@ const (
 	ruleSTRUCT
 	ruleUNION
 	ruleENUM
+	ruleSENUM
 	ruleINCLUDE
 	ruleCPPINCLUDE
 	ruleNAMESPACE
 	ruleCPPTYPE
+	ruleRESERVED
+	ruleAS
+	ruleSTREAM
+	ruleSINK
+	ruleAT
 	ruleLBRK
 	ruleRBRK
 	ruleLWING
@@ -109,6 +123,11 @@ const (
 	ruleLPAR
 	ruleRPAR
 	ruleCOLON
+	rulePLUS
+	ruleMINUS
+	ruleBITOR
+	ruleBITAND
+	ruleUNSUPPORTED
 	rulePegText
 )
 
@@ -124,15 +143,19 @@ var rul3s = [...]string{
 	"Const",
 	"Typedef",
 	"Enum",
+	"Senum",
 	"Service",
 	"Struct",
 	"Union",
 	"Exception",
+	"Reserved",
 	"Field",
 	"FieldId",
 	"FieldReq",
 	"Function",
 	"FunctionType",
+	"StreamType",
+	"SinkType",
 	"Throws",
 	"FieldType",
 	"BaseType",
@@ -142,11 +165,15 @@ var rul3s = [...]string{
 	"ListType",
 	"CppType",
 	"ConstValue",
+	"ConstExpr",
+	"ConstAndExpr",
+	"ConstTerm",
 	"IntConstant",
 	"DoubleConstant",
 	"Exponent",
 	"Annotations",
 	"Annotation",
+	"StructuredAnnotation",
 	"ConstList",
 	"ConstMap",
 	"EscapeLiteralChar",
@@ -190,10 +217,16 @@ var rul3s = [...]string{
 	"STRUCT",
 	"UNION",
 	"ENUM",
+	"SENUM",
 	"INCLUDE",
 	"CPPINCLUDE",
 	"NAMESPACE",
 	"CPPTYPE",
+	"RESERVED",
+	"AS",
+	"STREAM",
+	"SINK",
+	"AT",
 	"LBRK",
 	"RBRK",
 	"LWING",
@@ -205,6 +238,11 @@ var rul3s = [...]string{
 	"LPAR",
 	"RPAR",
 	"COLON",
+	"PLUS",
+	"MINUS",
+	"BITOR",
+	"BITAND",
+	"UNSUPPORTED",
 	"PegText",
 }
 
@@ -320,7 +358,7 @@ func (t *tokens32) Tokens() []token32 {
 type ThriftIDL struct {
 	Buffer string
 	buffer []rune
-	rules  [93]func() bool
+	rules  [112]func() bool
 	parse  func(rule ...int) error
 	reset  func()
 	Pretty bool
@@ -582,7 +620,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position7, tokenIndex7
 			return false
 		},
-		/* 2 Include <- <(INCLUDE Literal)> */
+		/* 2 Include <- <(INCLUDE Literal (AS Identifier)?)> */
 		func() bool {
 			position12, tokenIndex12 := position, tokenIndex
 			{
@@ -593,6 +631,19 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 				if !_rules[ruleLiteral]() {
 					goto l12
 				}
+				{
+					position626, tokenIndex626 := position, tokenIndex
+					if !_rules[ruleAS]() {
+						goto l626
+					}
+					if !_rules[ruleIdentifier]() {
+						goto l626
+					}
+					goto l627
+				l626:
+					position, tokenIndex = position626, tokenIndex626
+				}
+			l627:
 				add(ruleInclude, position13)
 			}
 			return true
@@ -692,7 +743,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position20, tokenIndex20
 			return false
 		},
-		/* 6 Definition <- <(ReservedComments Skip (Const / Typedef / Enum / Service / Struct / Union / Exception) Annotations? SkipLine)> */
+		/* 6 Definition <- <(ReservedComments Skip StructuredAnnotation* (Const / Typedef / Enum / Senum / Service / Struct / Union / Exception) Annotations? SkipLine)> */
 		func() bool {
 			position27, tokenIndex27 := position, tokenIndex
 			{
@@ -703,6 +754,16 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 				if !_rules[ruleSkip]() {
 					goto l27
 				}
+			l700:
+				{
+					position701, tokenIndex701 := position, tokenIndex
+					if !_rules[ruleStructuredAnnotation]() {
+						goto l701
+					}
+					goto l700
+				l701:
+					position, tokenIndex = position701, tokenIndex701
+				}
 				{
 					position29, tokenIndex29 := position, tokenIndex
 					if !_rules[ruleConst]() {
@@ -722,6 +783,12 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 					}
 					goto l29
 				l32:
+					position, tokenIndex = position29, tokenIndex29
+					if !_rules[ruleSenum]() {
+						goto l633
+					}
+					goto l29
+				l633:
 					position, tokenIndex = position29, tokenIndex29
 					if !_rules[ruleService]() {
 						goto l33
@@ -824,7 +891,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position42, tokenIndex42
 			return false
 		},
-		/* 9 Enum <- <(ENUM Identifier LWING (ReservedComments Identifier (EQUAL IntConstant)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)* RWING)> */
+		/* 9 Enum <- <(ENUM Identifier LWING (ReservedComments Identifier (EQUAL ConstExpr)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)* RWING)> */
 		func() bool {
 			position44, tokenIndex44 := position, tokenIndex
 			{
@@ -852,7 +919,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 						if !_rules[ruleEQUAL]() {
 							goto l48
 						}
-						if !_rules[ruleIntConstant]() {
+						if !_rules[ruleConstExpr]() {
 							goto l48
 						}
 						goto l49
@@ -900,7 +967,51 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position44, tokenIndex44
 			return false
 		},
-		/* 10 Service <- <(SERVICE Identifier (EXTENDS Identifier)? LWING Function* RWING)> */
+		/* 10 Senum <- <(SENUM Identifier LWING (Literal ListSeparator?)* RWING)> */
+		func() bool {
+			position634, tokenIndex634 := position, tokenIndex
+			{
+				position635 := position
+				if !_rules[ruleSENUM]() {
+					goto l634
+				}
+				if !_rules[ruleIdentifier]() {
+					goto l634
+				}
+				if !_rules[ruleLWING]() {
+					goto l634
+				}
+			l636:
+				{
+					position637, tokenIndex637 := position, tokenIndex
+					if !_rules[ruleLiteral]() {
+						goto l637
+					}
+					{
+						position638, tokenIndex638 := position, tokenIndex
+						if !_rules[ruleListSeparator]() {
+							goto l638
+						}
+						goto l639
+					l638:
+						position, tokenIndex = position638, tokenIndex638
+					}
+				l639:
+					goto l636
+				l637:
+					position, tokenIndex = position637, tokenIndex637
+				}
+				if !_rules[ruleRWING]() {
+					goto l634
+				}
+				add(ruleSenum, position635)
+			}
+			return true
+		l634:
+			position, tokenIndex = position634, tokenIndex634
+			return false
+		},
+		/* 11 Service <- <(SERVICE Identifier (EXTENDS Identifier)? LWING Function* RWING)> */
 		func() bool {
 			position54, tokenIndex54 := position, tokenIndex
 			{
@@ -947,7 +1058,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position54, tokenIndex54
 			return false
 		},
-		/* 11 Struct <- <(STRUCT Identifier LWING Field* RWING)> */
+		/* 12 Struct <- <(STRUCT Identifier LWING (Reserved / Field)* RWING)> */
 		func() bool {
 			position60, tokenIndex60 := position, tokenIndex
 			{
@@ -964,9 +1075,19 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			l62:
 				{
 					position63, tokenIndex63 := position, tokenIndex
-					if !_rules[ruleField]() {
-						goto l63
+					{
+						position537, tokenIndex537 := position, tokenIndex
+						if !_rules[ruleReserved]() {
+							goto l538
+						}
+						goto l537
+					l538:
+						position, tokenIndex = position537, tokenIndex537
+						if !_rules[ruleField]() {
+							goto l63
+						}
 					}
+				l537:
 					goto l62
 				l63:
 					position, tokenIndex = position63, tokenIndex63
@@ -981,7 +1102,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position60, tokenIndex60
 			return false
 		},
-		/* 12 Union <- <(UNION Identifier LWING Field* RWING)> */
+		/* 13 Union <- <(UNION Identifier LWING (Reserved / Field)* RWING)> */
 		func() bool {
 			position64, tokenIndex64 := position, tokenIndex
 			{
@@ -998,9 +1119,19 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			l66:
 				{
 					position67, tokenIndex67 := position, tokenIndex
-					if !_rules[ruleField]() {
-						goto l67
+					{
+						position539, tokenIndex539 := position, tokenIndex
+						if !_rules[ruleReserved]() {
+							goto l540
+						}
+						goto l539
+					l540:
+						position, tokenIndex = position539, tokenIndex539
+						if !_rules[ruleField]() {
+							goto l67
+						}
 					}
+				l539:
 					goto l66
 				l67:
 					position, tokenIndex = position67, tokenIndex67
@@ -1015,7 +1146,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position64, tokenIndex64
 			return false
 		},
-		/* 13 Exception <- <(EXCEPTION Identifier LWING Field* RWING)> */
+		/* 14 Exception <- <(EXCEPTION Identifier LWING (Reserved / Field)* RWING)> */
 		func() bool {
 			position68, tokenIndex68 := position, tokenIndex
 			{
@@ -1032,9 +1163,19 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			l70:
 				{
 					position71, tokenIndex71 := position, tokenIndex
-					if !_rules[ruleField]() {
-						goto l71
+					{
+						position541, tokenIndex541 := position, tokenIndex
+						if !_rules[ruleReserved]() {
+							goto l542
+						}
+						goto l541
+					l542:
+						position, tokenIndex = position541, tokenIndex541
+						if !_rules[ruleField]() {
+							goto l71
+						}
 					}
+				l541:
 					goto l70
 				l71:
 					position, tokenIndex = position71, tokenIndex71
@@ -1049,7 +1190,89 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position68, tokenIndex68
 			return false
 		},
-		/* 14 Field <- <(ReservedComments Skip FieldId? FieldReq? FieldType Identifier (EQUAL ConstValue)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)> */
+		/* 15 Reserved <- <(RESERVED ((IntConstant !COLON) / Literal) (COMMA ((IntConstant !COLON) / Literal))* ListSeparator? SkipLine)> */
+		func() bool {
+			position527, tokenIndex527 := position, tokenIndex
+			{
+				position528 := position
+				if !_rules[ruleRESERVED]() {
+					goto l527
+				}
+				{
+					position529, tokenIndex529 := position, tokenIndex
+					if !_rules[ruleIntConstant]() {
+						goto l530
+					}
+					{
+						position549, tokenIndex549 := position, tokenIndex
+						if !_rules[ruleCOLON]() {
+							goto l549
+						}
+						goto l530
+					l549:
+						position, tokenIndex = position549, tokenIndex549
+					}
+					goto l529
+				l530:
+					position, tokenIndex = position529, tokenIndex529
+					if !_rules[ruleLiteral]() {
+						goto l527
+					}
+				}
+			l529:
+			l531:
+				{
+					position532, tokenIndex532 := position, tokenIndex
+					if !_rules[ruleCOMMA]() {
+						goto l532
+					}
+					{
+						position533, tokenIndex533 := position, tokenIndex
+						if !_rules[ruleIntConstant]() {
+							goto l534
+						}
+						{
+							position550, tokenIndex550 := position, tokenIndex
+							if !_rules[ruleCOLON]() {
+								goto l550
+							}
+							goto l534
+						l550:
+							position, tokenIndex = position550, tokenIndex550
+						}
+						goto l533
+					l534:
+						position, tokenIndex = position533, tokenIndex533
+						if !_rules[ruleLiteral]() {
+							goto l532
+						}
+					}
+				l533:
+					goto l531
+				l532:
+					position, tokenIndex = position532, tokenIndex532
+				}
+				{
+					position535, tokenIndex535 := position, tokenIndex
+					if !_rules[ruleListSeparator]() {
+						goto l535
+					}
+					goto l536
+				l535:
+					position, tokenIndex = position535, tokenIndex535
+				}
+			l536:
+				if !_rules[ruleSkipLine]() {
+					goto l527
+				}
+				add(ruleReserved, position528)
+			}
+			return true
+		l527:
+			position, tokenIndex = position527, tokenIndex527
+			return false
+		},
+		/* 16 Field <- <(ReservedComments Skip StructuredAnnotation* FieldId? FieldReq? FieldType Identifier (EQUAL ConstValue)? Annotations? ListSeparator? ReservedEndLineComments SkipLine)> */
 		func() bool {
 			position72, tokenIndex72 := position, tokenIndex
 			{
@@ -1060,6 +1283,16 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 				if !_rules[ruleSkip]() {
 					goto l72
 				}
+			l702:
+				{
+					position703, tokenIndex703 := position, tokenIndex
+					if !_rules[ruleStructuredAnnotation]() {
+						goto l703
+					}
+					goto l702
+				l703:
+					position, tokenIndex = position703, tokenIndex703
+				}
 				{
 					position74, tokenIndex74 := position, tokenIndex
 					if !_rules[ruleFieldId]() {
@@ -1132,7 +1365,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position72, tokenIndex72
 			return false
 		},
-		/* 15 FieldId <- <(Skip IntConstant COLON Indent*)> */
+		/* 17 FieldId <- <(Skip IntConstant COLON Indent*)> */
 		func() bool {
 			position84, tokenIndex84 := position, tokenIndex
 			{
@@ -1163,7 +1396,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position84, tokenIndex84
 			return false
 		},
-		/* 16 FieldReq <- <(Skip <(('r' 'e' 'q' 'u' 'i' 'r' 'e' 'd') / ('o' 'p' 't' 'i' 'o' 'n' 'a' 'l'))> Indent*)> */
+		/* 18 FieldReq <- <(Skip <(('r' 'e' 'q' 'u' 'i' 'r' 'e' 'd') / ('o' 'p' 't' 'i' 'o' 'n' 'a' 'l'))> Indent*)> */
 		func() bool {
 			position88, tokenIndex88 := position, tokenIndex
 			{
@@ -1263,7 +1496,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position88, tokenIndex88
 			return false
 		},
-		/* 17 Function <- <(ReservedComments Skip ONEWAY? FunctionType Identifier LPAR Field* RPAR Throws? Annotations? ListSeparator? SkipLine)> */
+		/* 19 Function <- <(ReservedComments Skip StructuredAnnotation* ONEWAY? FunctionType Identifier LPAR Field* RPAR Throws? Annotations? ListSeparator? SkipLine)> */
 		func() bool {
 			position95, tokenIndex95 := position, tokenIndex
 			{
@@ -1274,6 +1507,16 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 				if !_rules[ruleSkip]() {
 					goto l95
 				}
+			l704:
+				{
+					position705, tokenIndex705 := position, tokenIndex
+					if !_rules[ruleStructuredAnnotation]() {
+						goto l705
+					}
+					goto l704
+				l705:
+					position, tokenIndex = position705, tokenIndex705
+				}
 				{
 					position97, tokenIndex97 := position, tokenIndex
 					if !_rules[ruleONEWAY]() {
@@ -1346,7 +1589,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position95, tokenIndex95
 			return false
 		},
-		/* 18 FunctionType <- <(VOID / FieldType)> */
+		/* 20 FunctionType <- <(VOID / StreamType / SinkType / FieldType)> */
 		func() bool {
 			position107, tokenIndex107 := position, tokenIndex
 			{
@@ -1358,6 +1601,18 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 					}
 					goto l109
 				l110:
+					position, tokenIndex = position109, tokenIndex109
+					if !_rules[ruleStreamType]() {
+						goto l706
+					}
+					goto l109
+				l706:
+					position, tokenIndex = position109, tokenIndex109
+					if !_rules[ruleSinkType]() {
+						goto l707
+					}
+					goto l109
+				l707:
 					position, tokenIndex = position109, tokenIndex109
 					if !_rules[ruleFieldType]() {
 						goto l107
@@ -1371,7 +1626,61 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position107, tokenIndex107
 			return false
 		},
-		/* 19 Throws <- <(THROWS LPAR Field* RPAR)> */
+		/* 21 StreamType <- <(STREAM LPOINT FieldType RPOINT)> */
+		func() bool {
+			position710, tokenIndex710 := position, tokenIndex
+			{
+				position711 := position
+				if !_rules[ruleSTREAM]() {
+					goto l710
+				}
+				if !_rules[ruleLPOINT]() {
+					goto l710
+				}
+				if !_rules[ruleFieldType]() {
+					goto l710
+				}
+				if !_rules[ruleRPOINT]() {
+					goto l710
+				}
+				add(ruleStreamType, position711)
+			}
+			return true
+		l710:
+			position, tokenIndex = position710, tokenIndex710
+			return false
+		},
+		/* 22 SinkType <- <(SINK LPOINT FieldType COMMA FieldType RPOINT)> */
+		func() bool {
+			position712, tokenIndex712 := position, tokenIndex
+			{
+				position713 := position
+				if !_rules[ruleSINK]() {
+					goto l712
+				}
+				if !_rules[ruleLPOINT]() {
+					goto l712
+				}
+				if !_rules[ruleFieldType]() {
+					goto l712
+				}
+				if !_rules[ruleCOMMA]() {
+					goto l712
+				}
+				if !_rules[ruleFieldType]() {
+					goto l712
+				}
+				if !_rules[ruleRPOINT]() {
+					goto l712
+				}
+				add(ruleSinkType, position713)
+			}
+			return true
+		l712:
+			position, tokenIndex = position712, tokenIndex712
+			return false
+		},
+		/* 23 Throws <- <(THROWS LPAR Field* RPAR)> */
 		func() bool {
 			position111, tokenIndex111 := position, tokenIndex
 			{
@@ -1402,7 +1711,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position111, tokenIndex111
 			return false
 		},
-		/* 20 FieldType <- <((ContainerType / BaseType / Identifier) Annotations?)> */
+		/* 24 FieldType <- <((ContainerType / BaseType / Identifier) Annotations?)> */
 		func() bool {
 			position115, tokenIndex115 := position, tokenIndex
 			{
@@ -1443,7 +1752,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position115, tokenIndex115
 			return false
 		},
-		/* 21 BaseType <- <(BOOL / BYTE / I8 / I16 / I32 / I64 / DOUBLE / STRING / BINARY)> */
+		/* 25 BaseType <- <(BOOL / BYTE / I8 / I16 / I32 / I64 / DOUBLE / STRING / BINARY)> */
 		func() bool {
 			position122, tokenIndex122 := position, tokenIndex
 			{
@@ -1510,7 +1819,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position122, tokenIndex122
 			return false
 		},
-		/* 22 ContainerType <- <(MapType / SetType / ListType)> */
+		/* 26 ContainerType <- <(MapType / SetType / ListType)> */
 		func() bool {
 			position133, tokenIndex133 := position, tokenIndex
 			{
@@ -1541,7 +1850,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position133, tokenIndex133
 			return false
 		},
-		/* 23 MapType <- <(MAP CppType? LPOINT FieldType COMMA FieldType RPOINT)> */
+		/* 27 MapType <- <(MAP CppType? LPOINT FieldType COMMA FieldType RPOINT)> */
 		func() bool {
 			position138, tokenIndex138 := position, tokenIndex
 			{
@@ -1581,7 +1890,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position138, tokenIndex138
 			return false
 		},
-		/* 24 SetType <- <(SET CppType? LPOINT FieldType RPOINT)> */
+		/* 28 SetType <- <(SET CppType? LPOINT FieldType RPOINT)> */
 		func() bool {
 			position142, tokenIndex142 := position, tokenIndex
 			{
@@ -1615,7 +1924,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position142, tokenIndex142
 			return false
 		},
-		/* 25 ListType <- <(LIST LPOINT FieldType RPOINT CppType?)> */
+		/* 29 ListType <- <(LIST LPOINT FieldType RPOINT CppType?)> */
 		func() bool {
 			position146, tokenIndex146 := position, tokenIndex
 			{
@@ -1649,7 +1958,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position146, tokenIndex146
 			return false
 		},
-		/* 26 CppType <- <(CPPTYPE Literal)> */
+		/* 30 CppType <- <(CPPTYPE Literal)> */
 		func() bool {
 			position150, tokenIndex150 := position, tokenIndex
 			{
@@ -1667,7 +1976,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position150, tokenIndex150
 			return false
 		},
-		/* 27 ConstValue <- <(DoubleConstant / IntConstant / Literal / Identifier / ConstList / ConstMap)> */
+		/* 31 ConstValue <- <(DoubleConstant / ConstExpr / Literal / ConstList / ConstMap)> */
 		func() bool {
 			position152, tokenIndex152 := position, tokenIndex
 			{
@@ -1680,7 +1989,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 					goto l154
 				l155:
 					position, tokenIndex = position154, tokenIndex154
-					if !_rules[ruleIntConstant]() {
+					if !_rules[ruleConstExpr]() {
 						goto l156
 					}
 					goto l154
@@ -1691,12 +2000,6 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 					}
 					goto l154
 				l157:
-					position, tokenIndex = position154, tokenIndex154
-					if !_rules[ruleIdentifier]() {
-						goto l158
-					}
-					goto l154
-				l158:
 					position, tokenIndex = position154, tokenIndex154
 					if !_rules[ruleConstList]() {
 						goto l159
@@ -1716,7 +2019,272 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position152, tokenIndex152
 			return false
 		},
-		/* 28 IntConstant <- <(Skip <(('0' 'x' ([0-9] / [A-Z] / [a-z])+) / ('0' 'o' Digit+) / (('+' / '-')? Digit+))> Indent*)> */
+		/* 32 ConstExpr <- <(ConstAndExpr ((PLUS / MINUS / BITOR / UNSUPPORTED) ConstAndExpr)* Indent*)> */
+		func() bool {
+			position551, tokenIndex551 := position, tokenIndex
+			{
+				position552 := position
+				if !_rules[ruleConstAndExpr]() {
+					goto l551
+				}
+			l553:
+				{
+					position554, tokenIndex554 := position, tokenIndex
+					{
+						position555, tokenIndex555 := position, tokenIndex
+						if !_rules[rulePLUS]() {
+							goto l556
+						}
+						goto l555
+					l556:
+						position, tokenIndex = position555, tokenIndex555
+						if !_rules[ruleMINUS]() {
+							goto l557
+						}
+						goto l555
+					l557:
+						position, tokenIndex = position555, tokenIndex555
+						if !_rules[ruleBITOR]() {
+							goto l600
+						}
+						goto l555
+					l600:
+						position, tokenIndex = position555, tokenIndex555
+						if !_rules[ruleUNSUPPORTED]() {
+							goto l554
+						}
+					}
+				l555:
+					if !_rules[ruleConstAndExpr]() {
+						goto l554
+					}
+					goto l553
+				l554:
+					position, tokenIndex = position554, tokenIndex554
+				}
+			l890:
+				{
+					position891, tokenIndex891 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l891
+					}
+					goto l890
+				l891:
+					position, tokenIndex = position891, tokenIndex891
+				}
+				add(ruleConstExpr, position552)
+			}
+			return true
+		l551:
+			position, tokenIndex = position551, tokenIndex551
+			return false
+		},
+		/* 33 ConstAndExpr <- <(ConstTerm (BITAND ConstTerm)*)> */
+		func() bool {
+			position558, tokenIndex558 := position, tokenIndex
+			{
+				position559 := position
+				if !_rules[ruleConstTerm]() {
+					goto l558
+				}
+			l560:
+				{
+					position561, tokenIndex561 := position, tokenIndex
+					if !_rules[ruleBITAND]() {
+						goto l561
+					}
+					if !_rules[ruleConstTerm]() {
+						goto l561
+					}
+					goto l560
+				l561:
+					position, tokenIndex = position561, tokenIndex561
+				}
+				add(ruleConstAndExpr, position559)
+			}
+			return true
+		l558:
+			position, tokenIndex = position558, tokenIndex558
+			return false
+		},
+		/* 34 ConstTerm <- <(Skip <(('0' 'x' ([0-9] / [A-Z] / [a-z])+) / ('0' 'o' Digit+) / (('+' / '-')? Digit+) / (Letter (Letter / Digit / '.')*))>)> */
+		func() bool {
+			position562, tokenIndex562 := position, tokenIndex
+			{
+				position563 := position
+				if !_rules[ruleSkip]() {
+					goto l562
+				}
+				{
+					position762 := position
+					{
+						position763, tokenIndex763 := position, tokenIndex
+						if buffer[position] != rune('0') {
+							goto l764
+						}
+						position++
+						if buffer[position] != rune('x') {
+							goto l764
+						}
+						position++
+						{
+							position767, tokenIndex767 := position, tokenIndex
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l768
+							}
+							position++
+							goto l767
+						l768:
+							position, tokenIndex = position767, tokenIndex767
+							if c := buffer[position]; c < rune('A') || c > rune('Z') {
+								goto l769
+							}
+							position++
+							goto l767
+						l769:
+							position, tokenIndex = position767, tokenIndex767
+							if c := buffer[position]; c < rune('a') || c > rune('z') {
+								goto l764
+							}
+							position++
+						}
+					l767:
+					l765:
+						{
+							position766, tokenIndex766 := position, tokenIndex
+							{
+								position770, tokenIndex770 := position, tokenIndex
+								if c := buffer[position]; c < rune('0') || c > rune('9') {
+									goto l771
+								}
+								position++
+								goto l770
+							l771:
+								position, tokenIndex = position770, tokenIndex770
+								if c := buffer[position]; c < rune('A') || c > rune('Z') {
+									goto l772
+								}
+								position++
+								goto l770
+							l772:
+								position, tokenIndex = position770, tokenIndex770
+								if c := buffer[position]; c < rune('a') || c > rune('z') {
+									goto l766
+								}
+								position++
+							}
+						l770:
+							goto l765
+						l766:
+							position, tokenIndex = position766, tokenIndex766
+						}
+						goto l763
+					l764:
+						position, tokenIndex = position763, tokenIndex763
+						if buffer[position] != rune('0') {
+							goto l773
+						}
+						position++
+						if buffer[position] != rune('o') {
+							goto l773
+						}
+						position++
+						if !_rules[ruleDigit]() {
+							goto l773
+						}
+					l774:
+						{
+							position775, tokenIndex775 := position, tokenIndex
+							if !_rules[ruleDigit]() {
+								goto l775
+							}
+							goto l774
+						l775:
+							position, tokenIndex = position775, tokenIndex775
+						}
+						goto l763
+					l773:
+						position, tokenIndex = position763, tokenIndex763
+						{
+							position776, tokenIndex776 := position, tokenIndex
+							{
+								position778, tokenIndex778 := position, tokenIndex
+								if buffer[position] != rune('+') {
+									goto l779
+								}
+								position++
+								goto l778
+							l779:
+								position, tokenIndex = position778, tokenIndex778
+								if buffer[position] != rune('-') {
+									goto l776
+								}
+								position++
+							}
+						l778:
+							goto l777
+						l776:
+							position, tokenIndex = position776, tokenIndex776
+						}
+					l777:
+						if !_rules[ruleDigit]() {
+							goto l784
+						}
+					l780:
+						{
+							position781, tokenIndex781 := position, tokenIndex
+							if !_rules[ruleDigit]() {
+								goto l781
+							}
+							goto l780
+						l781:
+							position, tokenIndex = position781, tokenIndex781
+						}
+						goto l763
+					l784:
+						position, tokenIndex = position763, tokenIndex763
+						if !_rules[ruleLetter]() {
+							goto l562
+						}
+					l854:
+						{
+							position855, tokenIndex855 := position, tokenIndex
+							{
+								position856, tokenIndex856 := position, tokenIndex
+								if !_rules[ruleLetter]() {
+									goto l857
+								}
+								goto l856
+							l857:
+								position, tokenIndex = position856, tokenIndex856
+								if !_rules[ruleDigit]() {
+									goto l858
+								}
+								goto l856
+							l858:
+								position, tokenIndex = position856, tokenIndex856
+								if buffer[position] != rune('.') {
+									goto l855
+								}
+								position++
+							}
+						l856:
+							goto l854
+						l855:
+							position, tokenIndex = position855, tokenIndex855
+						}
+					}
+				l763:
+					add(rulePegText, position762)
+				}
+				add(ruleConstTerm, position563)
+			}
+			return true
+		l562:
+			position, tokenIndex = position562, tokenIndex562
+			return false
+		},
+		/* 35 IntConstant <- <(Skip <(('0' 'x' ([0-9] / [A-Z] / [a-z])+) / ('0' 'o' Digit+) / (('+' / '-')? Digit+))> Indent*)> */
 		func() bool {
 			position160, tokenIndex160 := position, tokenIndex
 			{
@@ -1870,7 +2438,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position160, tokenIndex160
 			return false
 		},
-		/* 29 DoubleConstant <- <(Skip <(('+' / '-')? ((Digit* '.' Digit+ Exponent?) / (Digit+ Exponent)))> Indent*)> */
+		/* 36 DoubleConstant <- <(Skip <(('+' / '-')? ((Digit* '.' Digit+ Exponent?) / (Digit+ Exponent)))> Indent*)> */
 		func() bool {
 			position184, tokenIndex184 := position, tokenIndex
 			{
@@ -1981,7 +2549,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position184, tokenIndex184
 			return false
 		},
-		/* 30 Exponent <- <(('e' / 'E') IntConstant)> */
+		/* 37 Exponent <- <(('e' / 'E') IntConstant)> */
 		func() bool {
 			position203, tokenIndex203 := position, tokenIndex
 			{
@@ -2011,7 +2579,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position203, tokenIndex203
 			return false
 		},
-		/* 31 Annotations <- <(LPAR Annotation* RPAR)> */
+		/* 38 Annotations <- <(LPAR Annotation* RPAR)> */
 		func() bool {
 			position207, tokenIndex207 := position, tokenIndex
 			{
@@ -2039,7 +2607,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position207, tokenIndex207
 			return false
 		},
-		/* 32 Annotation <- <(Identifier EQUAL Literal ListSeparator?)> */
+		/* 39 Annotation <- <(Identifier EQUAL Literal ListSeparator?)> */
 		func() bool {
 			position211, tokenIndex211 := position, tokenIndex
 			{
@@ -2070,33 +2638,93 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position211, tokenIndex211
 			return false
 		},
-		/* 33 ConstList <- <(LBRK (ConstValue ListSeparator?)* RBRK)> */
+		/* 40 StructuredAnnotation <- <(AT Identifier (LWING (Identifier EQUAL ConstValue ListSeparator?)* RWING)? Skip)> */
 		func() bool {
-			position215, tokenIndex215 := position, tokenIndex
+			position720, tokenIndex720 := position, tokenIndex
 			{
-				position216 := position
-				if !_rules[ruleLBRK]() {
-					goto l215
+				position721 := position
+				if !_rules[ruleAT]() {
+					goto l720
+				}
+				if !_rules[ruleIdentifier]() {
+					goto l720
 				}
-			l217:
 				{
-					position218, tokenIndex218 := position, tokenIndex
-					if !_rules[ruleConstValue]() {
-						goto l218
+					position722, tokenIndex722 := position, tokenIndex
+					if !_rules[ruleLWING]() {
+						goto l722
 					}
+				l724:
 					{
-						position219, tokenIndex219 := position, tokenIndex
-						if !_rules[ruleListSeparator]() {
-							goto l219
+						position725, tokenIndex725 := position, tokenIndex
+						if !_rules[ruleIdentifier]() {
+							goto l725
 						}
-						goto l220
-					l219:
-						position, tokenIndex = position219, tokenIndex219
-					}
-				l220:
-					goto l217
-				l218:
-					position, tokenIndex = position218, tokenIndex218
+						if !_rules[ruleEQUAL]() {
+							goto l725
+						}
+						if !_rules[ruleConstValue]() {
+							goto l725
+						}
+						{
+							position726, tokenIndex726 := position, tokenIndex
+							if !_rules[ruleListSeparator]() {
+								goto l726
+							}
+							goto l727
+						l726:
+							position, tokenIndex = position726, tokenIndex726
+						}
+					l727:
+						goto l724
+					l725:
+						position, tokenIndex = position725, tokenIndex725
+					}
+					if !_rules[ruleRWING]() {
+						goto l722
+					}
+					goto l723
+				l722:
+					position, tokenIndex = position722, tokenIndex722
+				}
+			l723:
+				if !_rules[ruleSkip]() {
+					goto l720
+				}
+				add(ruleStructuredAnnotation, position721)
+			}
+			return true
+		l720:
+			position, tokenIndex = position720, tokenIndex720
+			return false
+		},
+		/* 41 ConstList <- <(LBRK (ConstValue ListSeparator?)* RBRK)> */
+		func() bool {
+			position215, tokenIndex215 := position, tokenIndex
+			{
+				position216 := position
+				if !_rules[ruleLBRK]() {
+					goto l215
+				}
+			l217:
+				{
+					position218, tokenIndex218 := position, tokenIndex
+					if !_rules[ruleConstValue]() {
+						goto l218
+					}
+					{
+						position219, tokenIndex219 := position, tokenIndex
+						if !_rules[ruleListSeparator]() {
+							goto l219
+						}
+						goto l220
+					l219:
+						position, tokenIndex = position219, tokenIndex219
+					}
+				l220:
+					goto l217
+				l218:
+					position, tokenIndex = position218, tokenIndex218
 				}
 				if !_rules[ruleRBRK]() {
 					goto l215
@@ -2108,7 +2736,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position215, tokenIndex215
 			return false
 		},
-		/* 34 ConstMap <- <(LWING (ConstValue COLON ConstValue ListSeparator?)* RWING)> */
+		/* 42 ConstMap <- <(LWING (ConstValue COLON ConstValue ListSeparator?)* RWING)> */
 		func() bool {
 			position221, tokenIndex221 := position, tokenIndex
 			{
@@ -2152,7 +2780,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position221, tokenIndex221
 			return false
 		},
-		/* 35 EscapeLiteralChar <- <('\\' ('"' / '\''))> */
+		/* 43 EscapeLiteralChar <- <('\\' ('"' / '\''))> */
 		func() bool {
 			position227, tokenIndex227 := position, tokenIndex
 			{
@@ -2183,7 +2811,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position227, tokenIndex227
 			return false
 		},
-		/* 36 Literal <- <((Skip '"' <(EscapeLiteralChar / (!'"' .))*> '"' Indent*) / (Skip '\'' <(EscapeLiteralChar / (!'\'' .))*> '\'' Indent*))> */
+		/* 44 Literal <- <((Skip '"' <(EscapeLiteralChar / (!'"' .))*> '"' Indent*) / (Skip '\'' <(EscapeLiteralChar / (!'\'' .))*> '\'' Indent*))> */
 		func() bool {
 			position231, tokenIndex231 := position, tokenIndex
 			{
@@ -2312,7 +2940,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position231, tokenIndex231
 			return false
 		},
-		/* 37 Identifier <- <(Skip <(Letter (Letter / Digit / '.')*)> Indent*)> */
+		/* 45 Identifier <- <(Skip <(Letter (Letter / Digit / '.')*)> Indent*)> */
 		func() bool {
 			position251, tokenIndex251 := position, tokenIndex
 			{
@@ -2371,7 +2999,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position251, tokenIndex251
 			return false
 		},
-		/* 38 ListSeparator <- <(Skip (',' / ';') Indent*)> */
+		/* 46 ListSeparator <- <(Skip (',' / ';') Indent*)> */
 		func() bool {
 			position261, tokenIndex261 := position, tokenIndex
 			{
@@ -2411,7 +3039,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position261, tokenIndex261
 			return false
 		},
-		/* 39 Letter <- <([A-Z] / [a-z] / '_')> */
+		/* 47 Letter <- <([A-Z] / [a-z] / '_')> */
 		func() bool {
 			position267, tokenIndex267 := position, tokenIndex
 			{
@@ -2445,7 +3073,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position267, tokenIndex267
 			return false
 		},
-		/* 40 LetterOrDigit <- <([a-z] / [A-Z] / [0-9] / ('_' / '$'))> */
+		/* 48 LetterOrDigit <- <([a-z] / [A-Z] / [0-9] / ('_' / '$'))> */
 		func() bool {
 			position272, tokenIndex272 := position, tokenIndex
 			{
@@ -2497,7 +3125,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position272, tokenIndex272
 			return false
 		},
-		/* 41 Digit <- <[0-9]> */
+		/* 49 Digit <- <[0-9]> */
 		func() bool {
 			position280, tokenIndex280 := position, tokenIndex
 			{
@@ -2513,7 +3141,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position280, tokenIndex280
 			return false
 		},
-		/* 42 ReservedComments <- <Skip> */
+		/* 50 ReservedComments <- <Skip> */
 		func() bool {
 			position282, tokenIndex282 := position, tokenIndex
 			{
@@ -2528,7 +3156,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position282, tokenIndex282
 			return false
 		},
-		/* 43 ReservedEndLineComments <- <SkipLine> */
+		/* 51 ReservedEndLineComments <- <SkipLine> */
 		func() bool {
 			position284, tokenIndex284 := position, tokenIndex
 			{
@@ -2543,7 +3171,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position284, tokenIndex284
 			return false
 		},
-		/* 44 Skip <- <(Space / Comment)*> */
+		/* 52 Skip <- <(Space / Comment)*> */
 		func() bool {
 			{
 				position287 := position
@@ -2571,7 +3199,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			}
 			return true
 		},
-		/* 45 SkipLine <- <(Indent / Comment)*> */
+		/* 53 SkipLine <- <(Indent / Comment)*> */
 		func() bool {
 			{
 				position293 := position
@@ -2599,7 +3227,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			}
 			return true
 		},
-		/* 46 Space <- <(Indent / CarriageReturnLineFeed)+> */
+		/* 54 Space <- <(Indent / CarriageReturnLineFeed)+> */
 		func() bool {
 			position298, tokenIndex298 := position, tokenIndex
 			{
@@ -2644,7 +3272,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position298, tokenIndex298
 			return false
 		},
-		/* 47 Indent <- <(' ' / '\t' / '\v')> */
+		/* 55 Indent <- <(' ' / '\t' / '\v')> */
 		func() bool {
 			position306, tokenIndex306 := position, tokenIndex
 			{
@@ -2678,7 +3306,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position306, tokenIndex306
 			return false
 		},
-		/* 48 CarriageReturnLineFeed <- <('\r' / '\n')> */
+		/* 56 CarriageReturnLineFeed <- <('\r' / '\n')> */
 		func() bool {
 			position311, tokenIndex311 := position, tokenIndex
 			{
@@ -2705,7 +3333,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position311, tokenIndex311
 			return false
 		},
-		/* 49 Comment <- <(LongComment / LineComment / UnixComment)> */
+		/* 57 Comment <- <(LongComment / LineComment / UnixComment)> */
 		func() bool {
 			position315, tokenIndex315 := position, tokenIndex
 			{
@@ -2736,7 +3364,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position315, tokenIndex315
 			return false
 		},
-		/* 50 LongComment <- <('/' '*' (!('*' '/') .)* ('*' '/'))> */
+		/* 58 LongComment <- <('/' '*' (!('*' '/') .)* ('*' '/'))> */
 		func() bool {
 			position320, tokenIndex320 := position, tokenIndex
 			{
@@ -2788,7 +3416,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position320, tokenIndex320
 			return false
 		},
-		/* 51 LineComment <- <('/' '/' (!('\r' / '\n') .)*)> */
+		/* 59 LineComment <- <('/' '/' (!('\r' / '\n') .)*)> */
 		func() bool {
 			position325, tokenIndex325 := position, tokenIndex
 			{
@@ -2839,7 +3467,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position325, tokenIndex325
 			return false
 		},
-		/* 52 UnixComment <- <('#' (!('\r' / '\n') .)*)> */
+		/* 60 UnixComment <- <('#' (!('\r' / '\n') .)*)> */
 		func() bool {
 			position332, tokenIndex332 := position, tokenIndex
 			{
@@ -2886,7 +3514,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position332, tokenIndex332
 			return false
 		},
-		/* 53 BOOL <- <(Skip <('b' 'o' 'o' 'l')> !LetterOrDigit Indent*)> */
+		/* 61 BOOL <- <(Skip <('b' 'o' 'o' 'l')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position339, tokenIndex339 := position, tokenIndex
 			{
@@ -2940,7 +3568,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position339, tokenIndex339
 			return false
 		},
-		/* 54 BYTE <- <(Skip <('b' 'y' 't' 'e')> !LetterOrDigit Indent*)> */
+		/* 62 BYTE <- <(Skip <('b' 'y' 't' 'e')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position345, tokenIndex345 := position, tokenIndex
 			{
@@ -2994,7 +3622,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position345, tokenIndex345
 			return false
 		},
-		/* 55 I8 <- <(Skip <('i' '8')> !LetterOrDigit Indent*)> */
+		/* 63 I8 <- <(Skip <('i' '8')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position351, tokenIndex351 := position, tokenIndex
 			{
@@ -3040,7 +3668,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position351, tokenIndex351
 			return false
 		},
-		/* 56 I16 <- <(Skip <('i' '1' '6')> !LetterOrDigit Indent*)> */
+		/* 64 I16 <- <(Skip <('i' '1' '6')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position357, tokenIndex357 := position, tokenIndex
 			{
@@ -3090,7 +3718,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position357, tokenIndex357
 			return false
 		},
-		/* 57 I32 <- <(Skip <('i' '3' '2')> !LetterOrDigit Indent*)> */
+		/* 65 I32 <- <(Skip <('i' '3' '2')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position363, tokenIndex363 := position, tokenIndex
 			{
@@ -3140,7 +3768,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position363, tokenIndex363
 			return false
 		},
-		/* 58 I64 <- <(Skip <('i' '6' '4')> !LetterOrDigit Indent*)> */
+		/* 66 I64 <- <(Skip <('i' '6' '4')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position369, tokenIndex369 := position, tokenIndex
 			{
@@ -3190,7 +3818,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position369, tokenIndex369
 			return false
 		},
-		/* 59 DOUBLE <- <(Skip <('d' 'o' 'u' 'b' 'l' 'e')> !LetterOrDigit Indent*)> */
+		/* 67 DOUBLE <- <(Skip <('d' 'o' 'u' 'b' 'l' 'e')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position375, tokenIndex375 := position, tokenIndex
 			{
@@ -3252,7 +3880,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position375, tokenIndex375
 			return false
 		},
-		/* 60 STRING <- <(Skip <('s' 't' 'r' 'i' 'n' 'g')> !LetterOrDigit Indent*)> */
+		/* 68 STRING <- <(Skip <('s' 't' 'r' 'i' 'n' 'g')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position381, tokenIndex381 := position, tokenIndex
 			{
@@ -3314,7 +3942,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position381, tokenIndex381
 			return false
 		},
-		/* 61 BINARY <- <(Skip <('b' 'i' 'n' 'a' 'r' 'y')> !LetterOrDigit Indent*)> */
+		/* 69 BINARY <- <(Skip <('b' 'i' 'n' 'a' 'r' 'y')> !LetterOrDigit Indent*)> */
 		func() bool {
 			position387, tokenIndex387 := position, tokenIndex
 			{
@@ -3376,7 +4004,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position387, tokenIndex387
 			return false
 		},
-		/* 62 CONST <- <(Skip ('c' 'o' 'n' 's' 't') !LetterOrDigit Indent*)> */
+		/* 70 CONST <- <(Skip ('c' 'o' 'n' 's' 't') !LetterOrDigit Indent*)> */
 		func() bool {
 			position393, tokenIndex393 := position, tokenIndex
 			{
@@ -3430,7 +4058,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position393, tokenIndex393
 			return false
 		},
-		/* 63 ONEWAY <- <(Skip ('o' 'n' 'e' 'w' 'a' 'y') !LetterOrDigit Indent*)> */
+		/* 71 ONEWAY <- <(Skip ('o' 'n' 'e' 'w' 'a' 'y') !LetterOrDigit Indent*)> */
 		func() bool {
 			position398, tokenIndex398 := position, tokenIndex
 			{
@@ -3488,7 +4116,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position398, tokenIndex398
 			return false
 		},
-		/* 64 TYPEDEF <- <(Skip ('t' 'y' 'p' 'e' 'd' 'e' 'f') !LetterOrDigit Indent*)> */
+		/* 72 TYPEDEF <- <(Skip ('t' 'y' 'p' 'e' 'd' 'e' 'f') !LetterOrDigit Indent*)> */
 		func() bool {
 			position403, tokenIndex403 := position, tokenIndex
 			{
@@ -3550,7 +4178,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position403, tokenIndex403
 			return false
 		},
-		/* 65 MAP <- <(Skip ('m' 'a' 'p') !LetterOrDigit Indent*)> */
+		/* 73 MAP <- <(Skip ('m' 'a' 'p') !LetterOrDigit Indent*)> */
 		func() bool {
 			position408, tokenIndex408 := position, tokenIndex
 			{
@@ -3596,7 +4224,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position408, tokenIndex408
 			return false
 		},
-		/* 66 SET <- <(Skip ('s' 'e' 't') !LetterOrDigit Indent*)> */
+		/* 74 SET <- <(Skip ('s' 'e' 't') !LetterOrDigit Indent*)> */
 		func() bool {
 			position413, tokenIndex413 := position, tokenIndex
 			{
@@ -3642,7 +4270,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position413, tokenIndex413
 			return false
 		},
-		/* 67 LIST <- <(Skip ('l' 'i' 's' 't') !LetterOrDigit Indent*)> */
+		/* 75 LIST <- <(Skip ('l' 'i' 's' 't') !LetterOrDigit Indent*)> */
 		func() bool {
 			position418, tokenIndex418 := position, tokenIndex
 			{
@@ -3692,7 +4320,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position418, tokenIndex418
 			return false
 		},
-		/* 68 VOID <- <(Skip ('v' 'o' 'i' 'd') !LetterOrDigit Indent*)> */
+		/* 76 VOID <- <(Skip ('v' 'o' 'i' 'd') !LetterOrDigit Indent*)> */
 		func() bool {
 			position423, tokenIndex423 := position, tokenIndex
 			{
@@ -3742,7 +4370,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position423, tokenIndex423
 			return false
 		},
-		/* 69 THROWS <- <(Skip ('t' 'h' 'r' 'o' 'w' 's') !LetterOrDigit Indent*)> */
+		/* 77 THROWS <- <(Skip ('t' 'h' 'r' 'o' 'w' 's') !LetterOrDigit Indent*)> */
 		func() bool {
 			position428, tokenIndex428 := position, tokenIndex
 			{
@@ -3800,7 +4428,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position428, tokenIndex428
 			return false
 		},
-		/* 70 EXCEPTION <- <(Skip ('e' 'x' 'c' 'e' 'p' 't' 'i' 'o' 'n') !LetterOrDigit Indent*)> */
+		/* 78 EXCEPTION <- <(Skip ('e' 'x' 'c' 'e' 'p' 't' 'i' 'o' 'n') !LetterOrDigit Indent*)> */
 		func() bool {
 			position433, tokenIndex433 := position, tokenIndex
 			{
@@ -3870,7 +4498,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position433, tokenIndex433
 			return false
 		},
-		/* 71 EXTENDS <- <(Skip ('e' 'x' 't' 'e' 'n' 'd' 's') !LetterOrDigit Indent*)> */
+		/* 79 EXTENDS <- <(Skip ('e' 'x' 't' 'e' 'n' 'd' 's') !LetterOrDigit Indent*)> */
 		func() bool {
 			position438, tokenIndex438 := position, tokenIndex
 			{
@@ -3932,7 +4560,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position438, tokenIndex438
 			return false
 		},
-		/* 72 SERVICE <- <(Skip ('s' 'e' 'r' 'v' 'i' 'c' 'e') !LetterOrDigit Indent*)> */
+		/* 80 SERVICE <- <(Skip ('s' 'e' 'r' 'v' 'i' 'c' 'e') !LetterOrDigit Indent*)> */
 		func() bool {
 			position443, tokenIndex443 := position, tokenIndex
 			{
@@ -3994,7 +4622,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position443, tokenIndex443
 			return false
 		},
-		/* 73 STRUCT <- <(Skip ('s' 't' 'r' 'u' 'c' 't') !LetterOrDigit Indent*)> */
+		/* 81 STRUCT <- <(Skip ('s' 't' 'r' 'u' 'c' 't') !LetterOrDigit Indent*)> */
 		func() bool {
 			position448, tokenIndex448 := position, tokenIndex
 			{
@@ -4052,7 +4680,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position448, tokenIndex448
 			return false
 		},
-		/* 74 UNION <- <(Skip ('u' 'n' 'i' 'o' 'n') !LetterOrDigit Indent*)> */
+		/* 82 UNION <- <(Skip ('u' 'n' 'i' 'o' 'n') !LetterOrDigit Indent*)> */
 		func() bool {
 			position453, tokenIndex453 := position, tokenIndex
 			{
@@ -4106,7 +4734,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position453, tokenIndex453
 			return false
 		},
-		/* 75 ENUM <- <(Skip ('e' 'n' 'u' 'm') !LetterOrDigit Indent*)> */
+		/* 83 ENUM <- <(Skip ('e' 'n' 'u' 'm') !LetterOrDigit Indent*)> */
 		func() bool {
 			position458, tokenIndex458 := position, tokenIndex
 			{
@@ -4156,7 +4784,61 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position458, tokenIndex458
 			return false
 		},
-		/* 76 INCLUDE <- <(Skip ('i' 'n' 'c' 'l' 'u' 'd' 'e') !LetterOrDigit Indent*)> */
+		/* 84 SENUM <- <(Skip ('s' 'e' 'n' 'u' 'm') !LetterOrDigit Indent*)> */
+		func() bool {
+			position640, tokenIndex640 := position, tokenIndex
+			{
+				position641 := position
+				if !_rules[ruleSkip]() {
+					goto l640
+				}
+				if buffer[position] != rune('s') {
+					goto l640
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l640
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l640
+				}
+				position++
+				if buffer[position] != rune('u') {
+					goto l640
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l640
+				}
+				position++
+				{
+					position642, tokenIndex642 := position, tokenIndex
+					if !_rules[ruleLetterOrDigit]() {
+						goto l642
+					}
+					goto l640
+				l642:
+					position, tokenIndex = position642, tokenIndex642
+				}
+			l643:
+				{
+					position644, tokenIndex644 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l644
+					}
+					goto l643
+				l644:
+					position, tokenIndex = position644, tokenIndex644
+				}
+				add(ruleSENUM, position641)
+			}
+			return true
+		l640:
+			position, tokenIndex = position640, tokenIndex640
+			return false
+		},
+		/* 85 INCLUDE <- <(Skip ('i' 'n' 'c' 'l' 'u' 'd' 'e') !LetterOrDigit Indent*)> */
 		func() bool {
 			position463, tokenIndex463 := position, tokenIndex
 			{
@@ -4218,7 +4900,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position463, tokenIndex463
 			return false
 		},
-		/* 77 CPPINCLUDE <- <(Skip ('c' 'p' 'p' '_' 'i' 'n' 'c' 'l' 'u' 'd' 'e') !LetterOrDigit Indent*)> */
+		/* 86 CPPINCLUDE <- <(Skip ('c' 'p' 'p' '_' 'i' 'n' 'c' 'l' 'u' 'd' 'e') !LetterOrDigit Indent*)> */
 		func() bool {
 			position468, tokenIndex468 := position, tokenIndex
 			{
@@ -4296,7 +4978,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position468, tokenIndex468
 			return false
 		},
-		/* 78 NAMESPACE <- <(Skip ('n' 'a' 'm' 'e' 's' 'p' 'a' 'c' 'e') !LetterOrDigit Indent*)> */
+		/* 87 NAMESPACE <- <(Skip ('n' 'a' 'm' 'e' 's' 'p' 'a' 'c' 'e') !LetterOrDigit Indent*)> */
 		func() bool {
 			position473, tokenIndex473 := position, tokenIndex
 			{
@@ -4366,7 +5048,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position473, tokenIndex473
 			return false
 		},
-		/* 79 CPPTYPE <- <(Skip ('c' 'p' 'p' '_' 't' 'y' 'p' 'e') !LetterOrDigit Indent*)> */
+		/* 88 CPPTYPE <- <(Skip ('c' 'p' 'p' '_' 't' 'y' 'p' 'e') !LetterOrDigit Indent*)> */
 		func() bool {
 			position478, tokenIndex478 := position, tokenIndex
 			{
@@ -4432,7 +5114,252 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position478, tokenIndex478
 			return false
 		},
-		/* 80 LBRK <- <(Skip '[' Indent*)> */
+		/* 89 RESERVED <- <(Skip ('r' 'e' 's' 'e' 'r' 'v' 'e' 'd') !LetterOrDigit Indent*)> */
+		func() bool {
+			position543, tokenIndex543 := position, tokenIndex
+			{
+				position544 := position
+				if !_rules[ruleSkip]() {
+					goto l543
+				}
+				if buffer[position] != rune('r') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('v') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l543
+				}
+				position++
+				if buffer[position] != rune('d') {
+					goto l543
+				}
+				position++
+				{
+					position545, tokenIndex545 := position, tokenIndex
+					if !_rules[ruleLetterOrDigit]() {
+						goto l545
+					}
+					goto l543
+				l545:
+					position, tokenIndex = position545, tokenIndex545
+				}
+			l546:
+				{
+					position547, tokenIndex547 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l547
+					}
+					goto l546
+				l547:
+					position, tokenIndex = position547, tokenIndex547
+				}
+				add(ruleRESERVED, position544)
+			}
+			return true
+		l543:
+			position, tokenIndex = position543, tokenIndex543
+			return false
+		},
+		/* 90 AS <- <(Skip ('a' 's') !LetterOrDigit Indent*)> */
+		func() bool {
+			position628, tokenIndex628 := position, tokenIndex
+			{
+				position629 := position
+				if !_rules[ruleSkip]() {
+					goto l628
+				}
+				if buffer[position] != rune('a') {
+					goto l628
+				}
+				position++
+				if buffer[position] != rune('s') {
+					goto l628
+				}
+				position++
+				{
+					position630, tokenIndex630 := position, tokenIndex
+					if !_rules[ruleLetterOrDigit]() {
+						goto l630
+					}
+					goto l628
+				l630:
+					position, tokenIndex = position630, tokenIndex630
+				}
+			l631:
+				{
+					position632, tokenIndex632 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l632
+					}
+					goto l631
+				l632:
+					position, tokenIndex = position632, tokenIndex632
+				}
+				add(ruleAS, position629)
+			}
+			return true
+		l628:
+			position, tokenIndex = position628, tokenIndex628
+			return false
+		},
+		/* 91 STREAM <- <(Skip ('s' 't' 'r' 'e' 'a' 'm') !LetterOrDigit Indent*)> */
+		func() bool {
+			position730, tokenIndex730 := position, tokenIndex
+			{
+				position731 := position
+				if !_rules[ruleSkip]() {
+					goto l730
+				}
+				if buffer[position] != rune('s') {
+					goto l730
+				}
+				position++
+				if buffer[position] != rune('t') {
+					goto l730
+				}
+				position++
+				if buffer[position] != rune('r') {
+					goto l730
+				}
+				position++
+				if buffer[position] != rune('e') {
+					goto l730
+				}
+				position++
+				if buffer[position] != rune('a') {
+					goto l730
+				}
+				position++
+				if buffer[position] != rune('m') {
+					goto l730
+				}
+				position++
+				{
+					position732, tokenIndex732 := position, tokenIndex
+					if !_rules[ruleLetterOrDigit]() {
+						goto l732
+					}
+					goto l730
+				l732:
+					position, tokenIndex = position732, tokenIndex732
+				}
+			l733:
+				{
+					position734, tokenIndex734 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l734
+					}
+					goto l733
+				l734:
+					position, tokenIndex = position734, tokenIndex734
+				}
+				add(ruleSTREAM, position731)
+			}
+			return true
+		l730:
+			position, tokenIndex = position730, tokenIndex730
+			return false
+		},
+		/* 92 SINK <- <(Skip ('s' 'i' 'n' 'k') !LetterOrDigit Indent*)> */
+		func() bool {
+			position735, tokenIndex735 := position, tokenIndex
+			{
+				position736 := position
+				if !_rules[ruleSkip]() {
+					goto l735
+				}
+				if buffer[position] != rune('s') {
+					goto l735
+				}
+				position++
+				if buffer[position] != rune('i') {
+					goto l735
+				}
+				position++
+				if buffer[position] != rune('n') {
+					goto l735
+				}
+				position++
+				if buffer[position] != rune('k') {
+					goto l735
+				}
+				position++
+				{
+					position737, tokenIndex737 := position, tokenIndex
+					if !_rules[ruleLetterOrDigit]() {
+						goto l737
+					}
+					goto l735
+				l737:
+					position, tokenIndex = position737, tokenIndex737
+				}
+			l738:
+				{
+					position739, tokenIndex739 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l739
+					}
+					goto l738
+				l739:
+					position, tokenIndex = position739, tokenIndex739
+				}
+				add(ruleSINK, position736)
+			}
+			return true
+		l735:
+			position, tokenIndex = position735, tokenIndex735
+			return false
+		},
+		/* 93 AT <- <(Skip '@' Indent*)> */
+		func() bool {
+			position740, tokenIndex740 := position, tokenIndex
+			{
+				position741 := position
+				if !_rules[ruleSkip]() {
+					goto l740
+				}
+				if buffer[position] != rune('@') {
+					goto l740
+				}
+				position++
+			l742:
+				{
+					position743, tokenIndex743 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l743
+					}
+					goto l742
+				l743:
+					position, tokenIndex = position743, tokenIndex743
+				}
+				add(ruleAT, position741)
+			}
+			return true
+		l740:
+			position, tokenIndex = position740, tokenIndex740
+			return false
+		},
+		/* 94 LBRK <- <(Skip '[' Indent*)> */
 		func() bool {
 			position483, tokenIndex483 := position, tokenIndex
 			{
@@ -4461,7 +5388,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position483, tokenIndex483
 			return false
 		},
-		/* 81 RBRK <- <(Skip ']' Indent*)> */
+		/* 95 RBRK <- <(Skip ']' Indent*)> */
 		func() bool {
 			position487, tokenIndex487 := position, tokenIndex
 			{
@@ -4490,7 +5417,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position487, tokenIndex487
 			return false
 		},
-		/* 82 LWING <- <(Skip '{' Indent*)> */
+		/* 96 LWING <- <(Skip '{' Indent*)> */
 		func() bool {
 			position491, tokenIndex491 := position, tokenIndex
 			{
@@ -4519,7 +5446,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position491, tokenIndex491
 			return false
 		},
-		/* 83 RWING <- <(Skip '}' Indent*)> */
+		/* 97 RWING <- <(Skip '}' Indent*)> */
 		func() bool {
 			position495, tokenIndex495 := position, tokenIndex
 			{
@@ -4548,7 +5475,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position495, tokenIndex495
 			return false
 		},
-		/* 84 EQUAL <- <(Skip '=' Indent*)> */
+		/* 98 EQUAL <- <(Skip '=' Indent*)> */
 		func() bool {
 			position499, tokenIndex499 := position, tokenIndex
 			{
@@ -4577,7 +5504,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position499, tokenIndex499
 			return false
 		},
-		/* 85 LPOINT <- <(Skip '<' Indent*)> */
+		/* 99 LPOINT <- <(Skip '<' Indent*)> */
 		func() bool {
 			position503, tokenIndex503 := position, tokenIndex
 			{
@@ -4606,7 +5533,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position503, tokenIndex503
 			return false
 		},
-		/* 86 RPOINT <- <(Skip '>' Indent*)> */
+		/* 100 RPOINT <- <(Skip '>' Indent*)> */
 		func() bool {
 			position507, tokenIndex507 := position, tokenIndex
 			{
@@ -4635,7 +5562,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position507, tokenIndex507
 			return false
 		},
-		/* 87 COMMA <- <(Skip ',' Indent*)> */
+		/* 101 COMMA <- <(Skip ',' Indent*)> */
 		func() bool {
 			position511, tokenIndex511 := position, tokenIndex
 			{
@@ -4664,7 +5591,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position511, tokenIndex511
 			return false
 		},
-		/* 88 LPAR <- <(Skip '(' Indent*)> */
+		/* 102 LPAR <- <(Skip '(' Indent*)> */
 		func() bool {
 			position515, tokenIndex515 := position, tokenIndex
 			{
@@ -4693,7 +5620,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position515, tokenIndex515
 			return false
 		},
-		/* 89 RPAR <- <(Skip ')' Indent*)> */
+		/* 103 RPAR <- <(Skip ')' Indent*)> */
 		func() bool {
 			position519, tokenIndex519 := position, tokenIndex
 			{
@@ -4722,7 +5649,7 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position519, tokenIndex519
 			return false
 		},
-		/* 90 COLON <- <(Skip ':' Indent*)> */
+		/* 104 COLON <- <(Skip ':' Indent*)> */
 		func() bool {
 			position523, tokenIndex523 := position, tokenIndex
 			{
@@ -4751,6 +5678,326 @@ func (p *ThriftIDL) Init(options ...func(*ThriftIDL) error) error {
 			position, tokenIndex = position523, tokenIndex523
 			return false
 		},
+		/* 105 PLUS <- <(('+' / (Skip '+' !Digit)) Indent*)> */
+		func() bool {
+			position566, tokenIndex566 := position, tokenIndex
+			{
+				position567 := position
+				{
+					position880, tokenIndex880 := position, tokenIndex
+					if buffer[position] != rune('+') {
+						goto l881
+					}
+					position++
+					goto l880
+				l881:
+					position, tokenIndex = position880, tokenIndex880
+					if !_rules[ruleSkip]() {
+						goto l566
+					}
+					if buffer[position] != rune('+') {
+						goto l566
+					}
+					position++
+					{
+						position568, tokenIndex568 := position, tokenIndex
+						if !_rules[ruleDigit]() {
+							goto l568
+						}
+						goto l566
+					l568:
+						position, tokenIndex = position568, tokenIndex568
+					}
+				}
+			l880:
+			l569:
+				{
+					position570, tokenIndex570 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l570
+					}
+					goto l569
+				l570:
+					position, tokenIndex = position570, tokenIndex570
+				}
+				add(rulePLUS, position567)
+			}
+			return true
+		l566:
+			position, tokenIndex = position566, tokenIndex566
+			return false
+		},
+		/* 106 MINUS <- <(('-' / (Skip '-' !Digit)) Indent*)> */
+		func() bool {
+			position571, tokenIndex571 := position, tokenIndex
+			{
+				position572 := position
+				{
+					position882, tokenIndex882 := position, tokenIndex
+					if buffer[position] != rune('-') {
+						goto l883
+					}
+					position++
+					goto l882
+				l883:
+					position, tokenIndex = position882, tokenIndex882
+					if !_rules[ruleSkip]() {
+						goto l571
+					}
+					if buffer[position] != rune('-') {
+						goto l571
+					}
+					position++
+					{
+						position573, tokenIndex573 := position, tokenIndex
+						if !_rules[ruleDigit]() {
+							goto l573
+						}
+						goto l571
+					l573:
+						position, tokenIndex = position573, tokenIndex573
+					}
+				}
+			l882:
+			l574:
+				{
+					position575, tokenIndex575 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l575
+					}
+					goto l574
+				l575:
+					position, tokenIndex = position575, tokenIndex575
+				}
+				add(ruleMINUS, position572)
+			}
+			return true
+		l571:
+			position, tokenIndex = position571, tokenIndex571
+			return false
+		},
+		/* 107 BITOR <- <(Skip '|' Indent*)> */
+		func() bool {
+			position576, tokenIndex576 := position, tokenIndex
+			{
+				position577 := position
+				if !_rules[ruleSkip]() {
+					goto l576
+				}
+				if buffer[position] != rune('|') {
+					goto l576
+				}
+				position++
+			l578:
+				{
+					position579, tokenIndex579 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l579
+					}
+					goto l578
+				l579:
+					position, tokenIndex = position579, tokenIndex579
+				}
+				add(ruleBITOR, position577)
+			}
+			return true
+		l576:
+			position, tokenIndex = position576, tokenIndex576
+			return false
+		},
+		/* 108 BITAND <- <(Skip '&' Indent*)> */
+		func() bool {
+			position580, tokenIndex580 := position, tokenIndex
+			{
+				position581 := position
+				if !_rules[ruleSkip]() {
+					goto l580
+				}
+				if buffer[position] != rune('&') {
+					goto l580
+				}
+				position++
+			l582:
+				{
+					position583, tokenIndex583 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l583
+					}
+					goto l582
+				l583:
+					position, tokenIndex = position583, tokenIndex583
+				}
+				add(ruleBITAND, position581)
+			}
+			return true
+		l580:
+			position, tokenIndex = position580, tokenIndex580
+			return false
+		},
+		/* 109 UNSUPPORTED <- <(Skip <(('*' / '/' / '%' / '^' / '~' / '<' / '>' / '!' / '='))+> Indent*)> */
+		func() bool {
+			position601, tokenIndex601 := position, tokenIndex
+			{
+				position602 := position
+				if !_rules[ruleSkip]() {
+					goto l601
+				}
+				{
+					position603 := position
+					{
+						position604, tokenIndex604 := position, tokenIndex
+						if buffer[position] != rune('*') {
+							goto l605
+						}
+						position++
+						goto l604
+					l605:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('/') {
+							goto l606
+						}
+						position++
+						goto l604
+					l606:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('%') {
+							goto l607
+						}
+						position++
+						goto l604
+					l607:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('^') {
+							goto l608
+						}
+						position++
+						goto l604
+					l608:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('~') {
+							goto l609
+						}
+						position++
+						goto l604
+					l609:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('<') {
+							goto l610
+						}
+						position++
+						goto l604
+					l610:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('>') {
+							goto l611
+						}
+						position++
+						goto l604
+					l611:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('!') {
+							goto l612
+						}
+						position++
+						goto l604
+					l612:
+						position, tokenIndex = position604, tokenIndex604
+						if buffer[position] != rune('=') {
+							goto l601
+						}
+						position++
+					}
+				l604:
+				l613:
+					{
+						position614, tokenIndex614 := position, tokenIndex
+						{
+							position615, tokenIndex615 := position, tokenIndex
+							if buffer[position] != rune('*') {
+								goto l616
+							}
+							position++
+							goto l615
+						l616:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('/') {
+								goto l617
+							}
+							position++
+							goto l615
+						l617:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('%') {
+								goto l618
+							}
+							position++
+							goto l615
+						l618:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('^') {
+								goto l619
+							}
+							position++
+							goto l615
+						l619:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('~') {
+								goto l620
+							}
+							position++
+							goto l615
+						l620:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('<') {
+								goto l621
+							}
+							position++
+							goto l615
+						l621:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('>') {
+								goto l622
+							}
+							position++
+							goto l615
+						l622:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('!') {
+								goto l623
+							}
+							position++
+							goto l615
+						l623:
+							position, tokenIndex = position615, tokenIndex615
+							if buffer[position] != rune('=') {
+								goto l614
+							}
+							position++
+						}
+					l615:
+						goto l613
+					l614:
+						position, tokenIndex = position614, tokenIndex614
+					}
+					add(rulePegText, position603)
+				}
+			l624:
+				{
+					position625, tokenIndex625 := position, tokenIndex
+					if !_rules[ruleIndent]() {
+						goto l625
+					}
+					goto l624
+				l625:
+					position, tokenIndex = position625, tokenIndex625
+				}
+				add(ruleUNSUPPORTED, position602)
+			}
+			return true
+		l601:
+			position, tokenIndex = position601, tokenIndex601
+			return false
+		},
 		nil,
 	}
 	p.rules = _rules
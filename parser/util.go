@@ -38,6 +38,18 @@ func normalizeFilename(fn string) string {
 	return ref
 }
 
+// NormalizeSource strips the leading UTF-8 BOM of an IDL and replaces the CRLF
+// and CR line endings with LF, so files authored on Windows are parsed and
+// positioned the same as others.
+func NormalizeSource(content string) string {
+	content = strings.TrimPrefix(content, "\uFEFF")
+	if strings.Contains(content, "\r") {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		content = strings.ReplaceAll(content, "\r", "\n")
+	}
+	return content
+}
+
 func refName(filename string) string {
 	n := strings.Split(filepath.Base(filename), ".")
 	return strings.Join(n[:len(n)-1], ".")
@@ -0,0 +1,294 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import "errors"
+
+// SkipChildren is used as a return value from a Visitor to indicate that the
+// children of the current node are to be skipped. It is not returned as an
+// error by Walk.
+var SkipChildren = errors.New("skip children")
+
+// Visitor is called by Walk on each node of an AST. The node is one of *Thrift,
+// *Include, *Namespace, *Typedef, *Constant, *Enum, *EnumValue, *StructLike,
+// *Field, *Service, *Function, *Type, *ConstValue, *MapConstValue, *ConstExpr,
+// *Annotation and *StructuredAnnotation.
+//
+// If Visit returns SkipChildren, the children of the node are not visited.
+// Any other non-nil error stops the walk and is returned by Walk.
+type Visitor interface {
+	Visit(node interface{}) error
+}
+
+// VisitorFunc is an adapter to use an ordinary function as a Visitor.
+type VisitorFunc func(node interface{}) error
+
+// Visit calls f(node).
+func (f VisitorFunc) Visit(node interface{}) error {
+	return f(node)
+}
+
+// Walk traverses the node and its children in depth-first order and calls
+// v.Visit on each node before its children. The children of a node are
+// visited in the order of the fields of its type, and the elements of a list
+// in the order of their declaration. For a *Thrift, they are the includes,
+// namespaces, typedefs, constants, enums, structs, unions, exceptions and
+// services.
+//
+// Walk does not enter the ASTs of includes. Use WalkIncludes for that.
+func Walk(node interface{}, v Visitor) error {
+	w := &walker{v: v}
+	return w.walk(node)
+}
+
+// WalkIncludes is like Walk, but it also traverses the AST referenced by each
+// *Include right after visiting the include. Each AST is traversed at most
+// once, even if it is included several times.
+func WalkIncludes(node interface{}, v Visitor) error {
+	w := &walker{v: v, visited: make(map[*Thrift]bool)}
+	return w.walk(node)
+}
+
+type walker struct {
+	v       Visitor
+	visited map[*Thrift]bool // nil when the includes are not followed
+}
+
+func (w *walker) walk(node interface{}) error {
+	if t, ok := node.(*Thrift); ok && w.visited != nil {
+		if w.visited[t] {
+			return nil
+		}
+		w.visited[t] = true
+	}
+	if err := w.v.Visit(node); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	return w.walkChildren(node)
+}
+
+func (w *walker) walkChildren(node interface{}) (err error) {
+	switch n := node.(type) {
+	case *Thrift:
+		for _, v := range n.Includes {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		for _, v := range n.Namespaces {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		for _, v := range n.Typedefs {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		for _, v := range n.Constants {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		for _, v := range n.Enums {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		for _, ss := range [][]*StructLike{n.Structs, n.Unions, n.Exceptions} {
+			for _, v := range ss {
+				if err = w.walk(v); err != nil {
+					return err
+				}
+			}
+		}
+		for _, v := range n.Services {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+	case *Include:
+		if w.visited != nil && n.Reference != nil {
+			return w.walk(n.Reference)
+		}
+	case *Namespace:
+		return w.walkAnnotations(n.Annotations)
+	case *Typedef:
+		if n.Type != nil {
+			if err = w.walk(n.Type); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Constant:
+		if n.Type != nil {
+			if err = w.walk(n.Type); err != nil {
+				return err
+			}
+		}
+		if n.Value != nil {
+			if err = w.walk(n.Value); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Enum:
+		for _, v := range n.Values {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *EnumValue:
+		return w.walkAnnotations(n.Annotations)
+	case *StructLike:
+		for _, v := range n.Fields {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Field:
+		if n.Type != nil {
+			if err = w.walk(n.Type); err != nil {
+				return err
+			}
+		}
+		if n.Default != nil {
+			if err = w.walk(n.Default); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Service:
+		for _, v := range n.Functions {
+			if err = w.walk(v); err != nil {
+				return err
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Function:
+		if n.FunctionType != nil {
+			if err = w.walk(n.FunctionType); err != nil {
+				return err
+			}
+		}
+		for _, fs := range [][]*Field{n.Arguments, n.Throws} {
+			for _, v := range fs {
+				if err = w.walk(v); err != nil {
+					return err
+				}
+			}
+		}
+		if err = w.walkAnnotations(n.Annotations); err != nil {
+			return err
+		}
+		return w.walkStructuredAnnotations(n.StructuredAnnotations)
+	case *Type:
+		if n.KeyType != nil {
+			if err = w.walk(n.KeyType); err != nil {
+				return err
+			}
+		}
+		if n.ValueType != nil {
+			if err = w.walk(n.ValueType); err != nil {
+				return err
+			}
+		}
+		return w.walkAnnotations(n.Annotations)
+	case *StructuredAnnotation:
+		for _, f := range n.Fields {
+			if f.Value != nil {
+				if err = w.walk(f.Value); err != nil {
+					return err
+				}
+			}
+		}
+	case *ConstValue:
+		if tv := n.TypedValue; tv != nil {
+			for _, v := range tv.List {
+				if err = w.walk(v); err != nil {
+					return err
+				}
+			}
+			for _, v := range tv.Map {
+				if err = w.walk(v); err != nil {
+					return err
+				}
+			}
+			if tv.Expr != nil {
+				return w.walk(tv.Expr)
+			}
+		}
+	case *MapConstValue:
+		if n.Key != nil {
+			if err = w.walk(n.Key); err != nil {
+				return err
+			}
+		}
+		if n.Value != nil {
+			return w.walk(n.Value)
+		}
+	case *ConstExpr:
+		if n.LHS != nil {
+			if err = w.walk(n.LHS); err != nil {
+				return err
+			}
+		}
+		if n.RHS != nil {
+			return w.walk(n.RHS)
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkAnnotations(annos Annotations) error {
+	for _, v := range annos {
+		if err := w.walk(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkStructuredAnnotations(sas StructuredAnnotations) error {
+	for _, v := range sas {
+		if err := w.walk(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
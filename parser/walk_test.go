@@ -0,0 +1,170 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+)
+
+func TestWalk(t *testing.T) {
+	idls := map[string]string{
+		"a.thrift": `
+include "b.thrift"
+include "c.thrift"
+namespace go a
+const list<i32> L = [1, b.B]
+struct S {
+	1: b.T t (k = "v")
+	2: map<string, i32> m = {"x": 1}
+}
+service Svc {
+	void f(1: S s) throws (1: b.E e)
+}`,
+		"b.thrift": `
+include "c.thrift"
+const i32 B = 1
+typedef i32 T
+exception E {}`,
+		"c.thrift": `
+enum C { X }`,
+	}
+	ast, err := parser.ParseBatchString("a.thrift", idls, nil)
+	test.Assert(t, err == nil, err)
+
+	name := func(node interface{}) string {
+		switch n := node.(type) {
+		case *parser.Thrift:
+			return "Thrift:" + n.Filename
+		case *parser.Include:
+			return "Include:" + n.Path
+		case *parser.Namespace:
+			return "Namespace:" + n.Name
+		case *parser.Typedef:
+			return "Typedef:" + n.Alias
+		case *parser.Constant:
+			return "Constant:" + n.Name
+		case *parser.Enum:
+			return "Enum:" + n.Name
+		case *parser.EnumValue:
+			return "EnumValue:" + n.Name
+		case *parser.StructLike:
+			return n.Category + ":" + n.Name
+		case *parser.Field:
+			return "Field:" + n.Name
+		case *parser.Service:
+			return "Service:" + n.Name
+		case *parser.Function:
+			return "Function:" + n.Name
+		case *parser.Type:
+			return "Type:" + n.Name
+		case *parser.ConstValue:
+			return "ConstValue:" + n.Type.String()
+		case *parser.MapConstValue:
+			return "MapConstValue"
+		case *parser.Annotation:
+			return "Annotation:" + n.Key
+		}
+		return fmt.Sprintf("%T", node)
+	}
+	collect := func(walk func(interface{}, parser.Visitor) error, skip func(interface{}) bool) string {
+		var names []string
+		err := walk(ast, parser.VisitorFunc(func(node interface{}) error {
+			names = append(names, name(node))
+			if skip != nil && skip(node) {
+				return parser.SkipChildren
+			}
+			return nil
+		}))
+		test.Assert(t, err == nil, err)
+		return strings.Join(names, "\n")
+	}
+
+	test.Assert(t, collect(parser.Walk, nil) == strings.Join([]string{
+		"Thrift:a.thrift",
+		"Include:b.thrift",
+		"Include:c.thrift",
+		"Namespace:a",
+		"Constant:L",
+		"Type:list",
+		"Type:i32",
+		"ConstValue:ConstList",
+		"ConstValue:ConstInt",
+		"ConstValue:ConstIdentifier",
+		"struct:S",
+		"Field:t",
+		"Type:b.T",
+		"Annotation:k",
+		"Field:m",
+		"Type:map",
+		"Type:string",
+		"Type:i32",
+		"ConstValue:ConstMap",
+		"MapConstValue",
+		"ConstValue:ConstLiteral",
+		"ConstValue:ConstInt",
+		"Service:Svc",
+		"Function:f",
+		"Type:void",
+		"Field:s",
+		"Type:S",
+		"Field:e",
+		"Type:b.E",
+	}, "\n"), collect(parser.Walk, nil))
+
+	// c.thrift is included twice but only traversed once
+	pruned := collect(parser.WalkIncludes, func(node interface{}) bool {
+		switch node.(type) {
+		case *parser.Constant, *parser.StructLike, *parser.Service:
+			return true
+		}
+		return false
+	})
+	test.Assert(t, pruned == strings.Join([]string{
+		"Thrift:a.thrift",
+		"Include:b.thrift",
+		"Thrift:b.thrift",
+		"Include:c.thrift",
+		"Thrift:c.thrift",
+		"Enum:C",
+		"EnumValue:X",
+		"Typedef:T",
+		"Type:i32",
+		"Constant:B",
+		"exception:E",
+		"Include:c.thrift",
+		"Namespace:a",
+		"Constant:L",
+		"struct:S",
+		"Service:Svc",
+	}, "\n"), pruned)
+
+	stop := errors.New("stop")
+	var fields int
+	err = parser.Walk(ast, parser.VisitorFunc(func(node interface{}) error {
+		if _, ok := node.(*parser.Field); ok {
+			if fields++; fields == 2 {
+				return stop
+			}
+		}
+		return nil
+	}))
+	test.Assert(t, err == stop && fields == 2, err)
+}
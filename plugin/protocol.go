@@ -10,13 +10,15 @@ import (
 )
 
 type Request struct {
-	Version             string         `thrift:"Version,1,required" json:"Version"`
-	GeneratorParameters []string       `thrift:"GeneratorParameters,2,required" json:"GeneratorParameters"`
-	PluginParameters    []string       `thrift:"PluginParameters,3,required" json:"PluginParameters"`
-	Language            string         `thrift:"Language,4,required" json:"Language"`
-	OutputPath          string         `thrift:"OutputPath,5,required" json:"OutputPath"`
-	Recursive           bool           `thrift:"Recursive,6,required" json:"Recursive"`
-	AST                 *parser.Thrift `thrift:"AST,7,required" json:"AST"`
+	Version             string            `thrift:"Version,1,required" json:"Version"`
+	GeneratorParameters []string          `thrift:"GeneratorParameters,2,required" json:"GeneratorParameters"`
+	PluginParameters    []string          `thrift:"PluginParameters,3,required" json:"PluginParameters"`
+	Language            string            `thrift:"Language,4,required" json:"Language"`
+	OutputPath          string            `thrift:"OutputPath,5,required" json:"OutputPath"`
+	Recursive           bool              `thrift:"Recursive,6,required" json:"Recursive"`
+	AST                 *parser.Thrift    `thrift:"AST,7,required" json:"AST"`
+	GeneratorOptions    map[string]string `thrift:"GeneratorOptions,8,optional" json:"GeneratorOptions,omitempty"`
+	OutputTemplate      *string           `thrift:"OutputTemplate,9,optional" json:"OutputTemplate,omitempty"`
 }
 
 func init() {
@@ -25,7 +27,7 @@ func init() {
 		0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0xb, 0x0,
 		0x2, 0x0, 0x0, 0x0, 0x6, 0x73, 0x74, 0x72,
 		0x75, 0x63, 0x74, 0xf, 0x0, 0x3, 0xc, 0x0,
-		0x0, 0x0, 0x7, 0x6, 0x0, 0x1, 0x0, 0x1,
+		0x0, 0x0, 0x9, 0x6, 0x0, 0x1, 0x0, 0x1,
 		0xb, 0x0, 0x2, 0x0, 0x0, 0x0, 0x7, 0x56,
 		0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x8, 0x0,
 		0x3, 0x0, 0x0, 0x0, 0x1, 0xc, 0x0, 0x4,
@@ -64,7 +66,21 @@ func init() {
 		0x0, 0x0, 0x0, 0x3, 0x41, 0x53, 0x54, 0x8,
 		0x0, 0x3, 0x0, 0x0, 0x0, 0x1, 0xc, 0x0,
 		0x4, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xc,
-		0x0, 0x0, 0x0,
+		0x0, 0x0, 0x6, 0x0, 0x1, 0x0, 0x8, 0xb,
+		0x0, 0x2, 0x0, 0x0, 0x0, 0x10, 0x47, 0x65,
+		0x6e, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x4f,
+		0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x8, 0x0,
+		0x3, 0x0, 0x0, 0x0, 0x2, 0xc, 0x0, 0x4,
+		0x8, 0x0, 0x1, 0x0, 0x0, 0x0, 0xd, 0xc,
+		0x0, 0x2, 0x8, 0x0, 0x1, 0x0, 0x0, 0x0,
+		0xb, 0x0, 0xc, 0x0, 0x3, 0x8, 0x0, 0x1,
+		0x0, 0x0, 0x0, 0xb, 0x0, 0x0, 0x0, 0x6,
+		0x0, 0x1, 0x0, 0x9, 0xb, 0x0, 0x2, 0x0,
+		0x0, 0x0, 0xe, 0x4f, 0x75, 0x74, 0x70, 0x75,
+		0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+		0x65, 0x8, 0x0, 0x3, 0x0, 0x0, 0x0, 0x2,
+		0xc, 0x0, 0x4, 0x8, 0x0, 0x1, 0x0, 0x0,
+		0x0, 0xb, 0x0, 0x0, 0x0,
 	})
 }
 
@@ -105,10 +121,36 @@ func (p *Request) GetAST() (v *parser.Thrift) {
 	return p.AST
 }
 
+var Request_GeneratorOptions_DEFAULT map[string]string
+
+func (p *Request) GetGeneratorOptions() (v map[string]string) {
+	if !p.IsSetGeneratorOptions() {
+		return Request_GeneratorOptions_DEFAULT
+	}
+	return p.GeneratorOptions
+}
+
+var Request_OutputTemplate_DEFAULT string
+
+func (p *Request) GetOutputTemplate() (v string) {
+	if !p.IsSetOutputTemplate() {
+		return Request_OutputTemplate_DEFAULT
+	}
+	return *p.OutputTemplate
+}
+
 func (p *Request) IsSetAST() bool {
 	return p.AST != nil
 }
 
+func (p *Request) IsSetGeneratorOptions() bool {
+	return p.GeneratorOptions != nil
+}
+
+func (p *Request) IsSetOutputTemplate() bool {
+	return p.OutputTemplate != nil
+}
+
 func (p *Request) String() string {
 	if p == nil {
 		return "<nil>"
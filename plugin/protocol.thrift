@@ -38,6 +38,14 @@ struct Request {
 
     // The abstract syntax trees of the parsed thrift IDL.
     7: required AST.Thrift AST,
+
+    // The options of the generator after being resolved by the backend, keyed by
+    // the option names listed by `thriftgo --help`. See docs/plugin-generator-options.md.
+    8: optional map<string, string> GeneratorOptions,
+
+    // The template given by `--out-template` that computes the output directory of
+    // the files generated for each IDL under `OutputPath`. See docs/output-template.md.
+    9: optional string OutputTemplate,
 }
 
 struct Generated {
@@ -15,8 +15,14 @@
 package sdk
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/cloudwego/thriftgo/generator/golang"
+	"github.com/cloudwego/thriftgo/generator/idl"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	targs "github.com/cloudwego/thriftgo/args"
 	"github.com/cloudwego/thriftgo/generator"
@@ -28,6 +34,7 @@ import (
 
 func init() {
 	_ = g.RegisterBackend(new(golang.GoBackend))
+	_ = g.RegisterBackend(new(idl.IDLBackend))
 }
 
 var (
@@ -52,10 +59,40 @@ func InvokeThriftgo(SDKPlugins []plugin.SDKPlugin, args ...string) (err error) {
 		return nil
 	}
 
+	if a.ListBackends != "" {
+		return listBackends(a.ListBackends)
+	}
+
 	// todo check log
 	log := a.MakeLogFunc()
 
-	ast, err := parser.ParseFile(a.IDL, a.Includes, true)
+	var timing *generator.Timing
+	if a.Timing {
+		timing = generator.NewTiming()
+		if !a.Quiet {
+			defer func() {
+				fmt.Fprintln(os.Stderr, timing)
+			}()
+		}
+	}
+
+	parser.SetRemoteOptions(parser.RemoteOptions{CacheDir: a.IDLCacheDir})
+	parser.SetDialect(a.Dialect)
+	parser.SetSearchOptions(parser.SearchOptions{
+		Log:              func(msg string) { log.Info(msg) },
+		StrictDuplicates: a.StrictIncludePaths,
+	})
+	stop := timing.Start("parse")
+	var ast *parser.Thrift
+	if a.Merge {
+		var asts []*parser.Thrift
+		if asts, err = parser.ParseFiles(a.IDLs, a.Includes); err == nil {
+			ast, err = parser.Merge(asts...)
+		}
+	} else {
+		ast, err = parser.ParseFile(a.IDL, a.Includes, true)
+	}
+	stop()
 	if err != nil {
 		return err
 	}
@@ -64,7 +101,18 @@ func InvokeThriftgo(SDKPlugins []plugin.SDKPlugin, args ...string) (err error) {
 		return fmt.Errorf("found include circle:\n\t%s", path)
 	}
 
-	checker := semantic.NewChecker(semantic.Options{FixWarnings: true})
+	if a.Stats != "" {
+		return printStats(ast, a.Recursive, string(a.Stats))
+	}
+
+	stop = timing.Start("semantic")
+	checker := semantic.NewChecker(semantic.Options{
+		FixWarnings:           true,
+		WarnFieldIDGaps:       a.WarnFieldIDGaps,
+		StrictExceptionFields: a.StrictExceptionFields,
+		ImplicitFieldIDs:      a.ImplicitFieldIDs,
+		TranslateSenums:       a.TranslateSenums,
+	})
 	// todo no warnings when sdk?
 	warns, err := checker.CheckAll(ast)
 	log.MultiWarn(warns)
@@ -73,6 +121,10 @@ func InvokeThriftgo(SDKPlugins []plugin.SDKPlugin, args ...string) (err error) {
 	}
 
 	err = semantic.ResolveSymbols(ast)
+	if err == nil && a.InheritTypedefAnnos {
+		err = semantic.InheritTypedefAnnotations(ast)
+	}
+	stop()
 	if err != nil {
 		return err
 	}
@@ -104,14 +156,100 @@ func InvokeThriftgo(SDKPlugins []plugin.SDKPlugin, args ...string) (err error) {
 		out.SDKPlugins = SDKPlugins
 		req.Language = out.Language
 		req.OutputPath = a.Output(out.Language)
+		if a.OutputTemplate != "" {
+			req.OutputTemplate = &a.OutputTemplate
+		}
 
-		arg := &generator.Arguments{Out: out, Req: req, Log: log}
+		arg := &generator.Arguments{Out: out, Req: req, Log: log, Timing: timing, PostFormat: a.PostFormat, NoFormat: a.NoFormat}
 		res := g.Generate(arg)
 
-		err = g.Persist(res)
+		if a.Clean {
+			removed, unowned, err := g.Clean(res, a.IDL, a.Verify || a.DryRun)
+			if err != nil {
+				return err
+			}
+			if len(unowned) > 0 && !a.Quiet {
+				fmt.Fprintf(os.Stderr, "[NOTICE] %d file(s) generated by thriftgo have no '%s' line and are kept by --clean:\n\t%s\n"+
+					"They are likely generated by an older thriftgo. Regenerate once with --clean to record the IDLs of "+
+					"the ones still generated, so that later runs can clean them up, and remove the others by hand.\n",
+					len(unowned), generator.OwnerPrefix, strings.Join(unowned, "\n\t"))
+			}
+			if a.Verify && len(removed) > 0 {
+				return fmt.Errorf("%d file(s) are no longer generated and would be removed by --clean:\n\t%s",
+					len(removed), strings.Join(removed, "\n\t"))
+			}
+			for _, f := range removed {
+				if a.DryRun {
+					fmt.Println("remove", f)
+				} else {
+					log.Info("Removed", f)
+				}
+			}
+		}
+		switch {
+		case a.Verify:
+			err = g.Verify(res)
+		case a.DryRun:
+			err = printWrites(res)
+		default:
+			err = g.Persist(res)
+		}
 		if err != nil {
 			return err
 		}
 	}
+	return a.CheckWarnings()
+}
+
+// printWrites prints the files that Persist would write for the response.
+func printWrites(res *plugin.Response) error {
+	if err := res.GetError(); err != "" {
+		return errors.New(err)
+	}
+	for _, c := range res.Contents {
+		fmt.Println("write", c.GetName())
+	}
 	return nil
 }
+
+// listBackends prints the backends and their options to stdout in the format.
+func listBackends(format string) error {
+	if format != "json" {
+		return fmt.Errorf("unsupported format for --list-backends: %q, expect 'json'", format)
+	}
+	bs, err := json.MarshalIndent(g.DescribeBackends(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(bs))
+	return err
+}
+
+// printStats prints the number of each kind of definitions in the IDL, and in its
+// includes if recursive is true, to stdout in the format.
+func printStats(ast *parser.Thrift, recursive bool, format string) error {
+	files, total := parser.CountFiles(ast, recursive)
+	if format == "json" {
+		bs, err := json.MarshalIndent(struct {
+			Files []*parser.FileStats `json:"files"`
+			Total parser.Stats        `json:"total"`
+		}{files, total}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(bs))
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	row := func(name string, s parser.Stats) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", name,
+			s.Typedefs, s.Constants, s.Enums, s.EnumValues, s.Structs, s.Unions,
+			s.Exceptions, s.Fields, s.Services, s.Methods)
+	}
+	fmt.Fprintln(w, "file\ttypedefs\tconstants\tenums\tenum values\tstructs\tunions\texceptions\tfields\tservices\tmethods")
+	for _, f := range files {
+		row(f.Filename, f.Stats)
+	}
+	row("total", total)
+	return w.Flush()
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// TypedefAnnotations returns the annotations of the typedefs that t refers to,
+// directly or through a chain of typedefs, including the ones of other IDLs.
+// A typedef overrides the annotations with the same keys of the typedefs that it
+// refers to. The AST must be semantically resolved.
+func TypedefAnnotations(ast *parser.Thrift, t *parser.Type) (parser.Annotations, error) {
+	var res parser.Annotations
+	for {
+		tast, td, err := typedefOf(ast, t)
+		if td == nil || err != nil {
+			return res, err
+		}
+		res = mergeAnnotations(res, td.Annotations)
+		ast, t = tast, td.Type
+	}
+}
+
+// FieldAnnotations returns the annotations of the field merged with the ones
+// of the typedefs that its type refers to, see TypedefAnnotations. The
+// annotations of the field override the ones of the typedefs with the same keys.
+func FieldAnnotations(ast *parser.Thrift, f *parser.Field) (parser.Annotations, error) {
+	inherited, err := TypedefAnnotations(ast, f.Type)
+	if err != nil {
+		return nil, err
+	}
+	return mergeAnnotations(f.Annotations, inherited), nil
+}
+
+// InheritTypedefAnnotations replaces the annotations of each field of the
+// struct-likes and the functions, i.e. the arguments and the exceptions thrown,
+// of the AST and the ASTs it includes with the result of FieldAnnotations, so
+// that the backends and the plugins see the annotations that a field inherits
+// from its typedefs. The AST must be semantically resolved.
+func InheritTypedefAnnotations(ast *parser.Thrift) error {
+	for a := range ast.DepthFirstSearch() {
+		var fields []*parser.Field
+		for _, s := range a.GetStructLikes() {
+			fields = append(fields, s.Fields...)
+		}
+		for _, s := range a.Services {
+			for _, f := range s.Functions {
+				fields = append(fields, f.Arguments...)
+				fields = append(fields, f.Throws...)
+			}
+		}
+		for _, f := range fields {
+			annos, err := FieldAnnotations(a, f)
+			if err != nil {
+				return fmt.Errorf("%s: field %q: %w", location(a, f.Position), f.Name, err)
+			}
+			f.Annotations = annos
+		}
+	}
+	return nil
+}
+
+// typedefOf returns the typedef that t refers to and the AST it belongs to, or
+// a nil typedef if t is not a typedef.
+func typedefOf(ast *parser.Thrift, t *parser.Type) (*parser.Thrift, *parser.Typedef, error) {
+	name := t.Name
+	if t.IsSetReference() {
+		ref := t.GetReference()
+		if ref.Index < 0 || ref.Index >= int32(len(ast.Includes)) {
+			return nil, nil, fmt.Errorf("(%+v) invalid ref.Index for %q: %d", t, ast.Filename, ref.Index)
+		}
+		ast, name = ast.Includes[ref.Index].Reference, ref.Name
+		if ast.Name2Category == nil {
+			return nil, nil, fmt.Errorf("AST %q is not semantically resolved", ast.Filename)
+		}
+		if ast.Name2Category[name] != parser.Category_Typedef {
+			return ast, nil, nil
+		}
+	} else if !t.GetIsTypedef() {
+		return ast, nil, nil
+	}
+	td, ok := ast.GetTypedef(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("expect %q a typedef in %q", name, ast.Filename)
+	}
+	return ast, td, nil
+}
+
+// mergeAnnotations returns the annotations of high followed by the ones of low
+// whose keys are not in high. The values of a key are never merged.
+func mergeAnnotations(high, low parser.Annotations) parser.Annotations {
+	if len(low) == 0 {
+		return high
+	}
+	keys := make(map[string]bool, len(high))
+	for _, a := range high {
+		keys[a.Key] = true
+	}
+	res := make(parser.Annotations, 0, len(high)+len(low))
+	res = append(res, high...)
+	for _, a := range low {
+		if keys[a.Key] {
+			continue
+		}
+		res = append(res, &parser.Annotation{
+			Key:    a.Key,
+			Values: append([]string(nil), a.Values...),
+		})
+	}
+	return res
+}
@@ -15,9 +15,14 @@
 package semantic
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/cloudwego/thriftgo/parser"
 )
@@ -31,6 +36,49 @@ type Checker interface {
 // Options controls the behavior of the default checker.
 type Options struct {
 	FixWarnings bool
+	// WarnFieldIDGaps reports struct-likes whose field IDs do not start at 1 or are not contiguous.
+	WarnFieldIDGaps bool
+	// StrictExceptionFields reports an error instead of a warning when an exception
+	// is the type of a field in a struct or a union.
+	StrictExceptionFields bool
+	// TranslateSenums turns each senum into a string typedef and a constant for
+	// each of its values instead of reporting it as an error.
+	TranslateSenums bool
+	// ImplicitFieldIDs reports the fields of struct-likes that do not have an
+	// explicit ID in the IDL.
+	ImplicitFieldIDs CheckLevel
+}
+
+// CheckLevel is how a configurable check reports what it finds. It implements
+// flag.Value with the names "off", "warn" and "error".
+type CheckLevel int
+
+// The levels of a configurable check.
+const (
+	CheckOff CheckLevel = iota
+	CheckWarn
+	CheckError
+)
+
+var checkLevelNames = []string{"off", "warn", "error"}
+
+// String implements the flag.Value interface.
+func (l CheckLevel) String() string {
+	if l < 0 || int(l) >= len(checkLevelNames) {
+		return fmt.Sprintf("CheckLevel(%d)", int(l))
+	}
+	return checkLevelNames[l]
+}
+
+// Set implements the flag.Value interface.
+func (l *CheckLevel) Set(value string) error {
+	for i, n := range checkLevelNames {
+		if n == value {
+			*l = CheckLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("expect one of %s, got %q", strings.Join(checkLevelNames, ", "), value)
 }
 
 type checker struct {
@@ -50,11 +98,20 @@ func (c *checker) ResolveSymbols(t *parser.Thrift) error {
 // CheckAll implements the Checker interface.
 func (c *checker) CheckAll(t *parser.Thrift) (warns []string, err error) {
 	checks := []func(t *parser.Thrift) ([]string, error){
+		c.CheckSenums,
 		c.CheckGlobals,
+		c.CheckIncludes,
 		c.CheckEnums,
 		c.CheckStructLikes,
 		c.CheckUnions,
 		c.CheckFunctions,
+		c.CheckExceptionFields,
+	}
+	if c.WarnFieldIDGaps {
+		checks = append(checks, c.CheckFieldIDGaps)
+	}
+	if c.ImplicitFieldIDs != CheckOff {
+		checks = append(checks, c.CheckImplicitFieldIDs)
 	}
 	for tt := range t.DepthFirstSearch() {
 		for _, f := range checks {
@@ -68,30 +125,106 @@ func (c *checker) CheckAll(t *parser.Thrift) (warns []string, err error) {
 	return warns, nil
 }
 
-func (c *checker) CheckGlobals(t *parser.Thrift) (warns []string, err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			err = fmt.Errorf("duplicated names in global scope: %s", e)
+// CheckSenums reports the legacy senums, which are deprecated. With the
+// TranslateSenums option, a senum is replaced in the AST by a string typedef with
+// its name and a constant named <senum>_<value> for each of its values.
+func (c *checker) CheckSenums(t *parser.Thrift) (warns []string, err error) {
+	for _, e := range t.Senums {
+		if !c.TranslateSenums {
+			return warns, fmt.Errorf("%s: senum %q is deprecated, rewrite it as an enum or as string constants, or translate it with --translate-senums",
+				location(t, e.Position), e.Name)
+		}
+		for _, v := range e.Values {
+			if !senumValue.MatchString(v) {
+				return warns, fmt.Errorf("%s: value %q of senum %q can not be translated into a constant name",
+					location(t, e.Position), v, e.Name)
+			}
 		}
-	}()
+		t.Typedefs = append(t.Typedefs, &parser.Typedef{
+			Type:             &parser.Type{Name: "string"},
+			Alias:            e.Name,
+			Annotations:      e.Annotations,
+			ReservedComments: e.ReservedComments,
+			Position:         e.Position,
+		})
+		for _, v := range e.Values {
+			literal := v
+			t.Constants = append(t.Constants, &parser.Constant{
+				Name: e.Name + "_" + v,
+				Type: &parser.Type{Name: e.Name},
+				Value: &parser.ConstValue{
+					Type:       parser.ConstType_ConstLiteral,
+					TypedValue: &parser.ConstTypedValue{Literal: &literal},
+				},
+				Position: e.Position,
+			})
+		}
+		warns = append(warns, fmt.Sprintf("%s: senum %q is deprecated, translated into a string typedef and %d constant(s)",
+			location(t, e.Position), e.Name, len(e.Values)))
+	}
+	// the senums are replaced by their translations
+	t.Senums = nil
+	return
+}
+
+var senumValue = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+func (c *checker) CheckGlobals(t *parser.Thrift) (warns []string, err error) {
 	globals := make(map[string]bool)
-	check := func(s string) {
-		if globals[s] {
-			panic(s)
+	check := func(s string, pos *parser.Position) {
+		if err == nil && globals[s] {
+			err = fmt.Errorf("%s: duplicated names in global scope: %s", location(t, pos), s)
 		}
 		globals[s] = true
 	}
 	for _, v := range t.Typedefs {
-		check(v.Alias)
+		check(v.Alias, v.Position)
+	}
+	for _, v := range t.Constants {
+		check(v.Name, v.Position)
+	}
+	for _, v := range t.GetStructLikes() {
+		check(v.Name, v.Position)
+	}
+	for _, v := range t.Services {
+		check(v.Name, v.Position)
+	}
+	return
+}
+
+// CheckIncludes checks the aliases of includes. An alias can not shadow a name
+// defined in the IDL or the name of another include.
+func (c *checker) CheckIncludes(t *parser.Thrift) (warns []string, err error) {
+	defined := make(map[string]bool)
+	for _, v := range t.Typedefs {
+		defined[v.Alias] = true
 	}
 	for _, v := range t.Constants {
-		check(v.Name)
+		defined[v.Name] = true
+	}
+	for _, v := range t.Enums {
+		defined[v.Name] = true
 	}
 	for _, v := range t.GetStructLikes() {
-		check(v.Name)
+		defined[v.Name] = true
 	}
 	for _, v := range t.Services {
-		check(v.Name)
+		defined[v.Name] = true
+	}
+	// includes of files with the same name are only rejected when an alias is
+	// involved, because they were allowed before aliases were supported
+	names := make(map[string]*parser.Include)
+	for _, inc := range t.Includes {
+		name := inc.RefName()
+		if inc.Alias != "" && defined[name] {
+			return warns, fmt.Errorf("%s: alias %q of include %q shadows the definition with the same name",
+				location(t, inc.Position), name, inc.Path)
+		}
+		if other, ok := names[name]; ok && (inc.Alias != "" || other.Alias != "") {
+			return warns, fmt.Errorf("%s: includes %q and %q are both referred to as %q",
+				location(t, inc.Position), other.Path, inc.Path, name)
+		}
+		names[name] = inc
 	}
 	return
 }
@@ -102,13 +235,13 @@ func (c *checker) CheckEnums(t *parser.Thrift) (warns []string, err error) {
 		v2n := make(map[int64]string)
 		for _, v := range e.Values {
 			if exist[v.Name] {
-				err = fmt.Errorf("enum %s has duplicated value: %s", e.Name, v.Name)
+				err = fmt.Errorf("%s: enum %s has duplicated value: %s", location(t, v.Position), e.Name, v.Name)
 			}
 			exist[v.Name] = true
 			if n, ok := v2n[v.Value]; ok && n != v.Name {
 				err = fmt.Errorf(
-					"enum %s: duplicate value %d between '%s' and '%s'",
-					e.Name, v.Value, n, v.Name,
+					"%s: enum %s: duplicate value %d between '%s' and '%s'",
+					location(t, v.Position), e.Name, v.Value, n, v.Name,
 				)
 			}
 			v2n[v.Value] = v.Name
@@ -128,45 +261,175 @@ func (c *checker) CheckStructLikes(t *parser.Thrift) (warns []string, err error)
 	for _, s := range t.GetStructLikes() {
 		fieldIDs := make(map[int32]bool)
 		names := make(map[string]bool)
+		reservedIDs := make(map[int32]bool)
+		reservedNames := make(map[string]bool)
+		for _, id := range s.ReservedIDs {
+			reservedIDs[id] = true
+		}
+		for _, n := range s.ReservedNames {
+			reservedNames[n] = true
+		}
 		for _, f := range s.Fields {
+			if reservedIDs[f.ID] {
+				err = fmt.Errorf("%s: field %q in %s %q uses reserved ID %d",
+					location(t, f.Position), f.Name, s.Category, s.Name, f.ID)
+				return
+			}
+			if reservedNames[f.Name] {
+				err = fmt.Errorf("%s: field %d in %s %q uses reserved name %q",
+					location(t, f.Position), f.ID, s.Category, s.Name, f.Name)
+				return
+			}
 			if fieldIDs[f.ID] {
-				err = fmt.Errorf("duplicated field ID %d in %s %q",
-					f.ID, s.Category, s.Name)
+				err = fmt.Errorf("%s: duplicated field ID %d in %s %q",
+					location(t, f.Position), f.ID, s.Category, s.Name)
 				return
 			}
 			if names[f.Name] {
-				err = fmt.Errorf("duplicated field name %q in %s %q",
-					f.Name, s.Category, s.Name)
+				err = fmt.Errorf("%s: duplicated field name %q in %s %q",
+					location(t, f.Position), f.Name, s.Category, s.Name)
 				return
 			}
 			fieldIDs[f.ID] = true
 			names[f.Name] = true
 			if f.ID <= 0 {
-				warns = append(warns, fmt.Sprintf("non-positive ID %d of field %q in %q",
-					f.ID, f.Name, s.Name))
+				warns = append(warns, fmt.Sprintf("%s: non-positive ID %d of field %q in %q",
+					location(t, f.Position), f.ID, f.Name, s.Name))
+			}
+		}
+	}
+	return
+}
+
+// CheckFieldIDGaps reports the IDs missing from 1 to the largest field ID of each struct-like.
+func (c *checker) CheckFieldIDGaps(t *parser.Thrift) (warns []string, err error) {
+	for _, s := range t.GetStructLikes() {
+		var ids []int
+		for _, f := range s.Fields {
+			if f.ID > 0 {
+				ids = append(ids, int(f.ID))
+			}
+		}
+		sort.Ints(ids)
+		var gaps []string
+		next, first := 1, 0
+		for _, id := range ids {
+			if id > next && first == 0 {
+				first = next
+			}
+			switch {
+			case id == next+1:
+				gaps = append(gaps, strconv.Itoa(next))
+			case id > next+1:
+				gaps = append(gaps, fmt.Sprintf("%d-%d", next, id-1))
+			}
+			next = id + 1
+		}
+		if len(gaps) > 0 {
+			warns = append(warns, fmt.Sprintf("%s: field IDs of %s %q are not contiguous, missing %s; the next available ID is %d",
+				location(t, s.Position), s.Category, s.Name, strings.Join(gaps, ", "), first))
+		}
+	}
+	return
+}
+
+// CheckImplicitFieldIDs reports the fields of struct-likes without explicit IDs,
+// whose IDs change when the fields before them are reordered, added or removed.
+func (c *checker) CheckImplicitFieldIDs(t *parser.Thrift) (warns []string, err error) {
+	for _, s := range t.GetStructLikes() {
+		for _, f := range s.Fields {
+			if !f.ImplicitID {
+				continue
 			}
+			msg := fmt.Sprintf("%s: field %q in %s %q has no explicit ID, it is assigned %d",
+				location(t, f.Position), f.Name, s.Category, s.Name, f.ID)
+			if c.ImplicitFieldIDs == CheckError {
+				return warns, errors.New(msg)
+			}
+			warns = append(warns, msg)
 		}
 	}
 	return
 }
 
-// CheckUnions checks the semantics of union nodes.
+// CheckExceptionFields reports the fields of structs and unions whose types are
+// exceptions, following typedefs. Exceptions are expected only in throws clauses.
+func (c *checker) CheckExceptionFields(t *parser.Thrift) (warns []string, err error) {
+	// a fresh slice, as appending to t.Structs may overwrite its spare capacity
+	structLikes := make([]*parser.StructLike, 0, len(t.Structs)+len(t.Unions))
+	structLikes = append(append(structLikes, t.Structs...), t.Unions...)
+	for _, s := range structLikes {
+		for _, f := range s.Fields {
+			exc := exceptionOf(t, f.Type.Name, nil)
+			if exc == "" {
+				continue
+			}
+			msg := fmt.Sprintf("%s: field %q in %s %q has the exception type %q, exceptions should only be used in throws clauses",
+				location(t, f.Position), f.Name, s.Category, s.Name, exc)
+			if exc != f.Type.Name {
+				msg = fmt.Sprintf("%s: field %q in %s %q has type %q, which refers to the exception %q, exceptions should only be used in throws clauses",
+					location(t, f.Position), f.Name, s.Category, s.Name, f.Type.Name, exc)
+			}
+			if c.StrictExceptionFields {
+				return warns, errors.New(msg)
+			}
+			warns = append(warns, msg)
+		}
+	}
+	return
+}
+
+// exceptionOf returns the name of the exception that the type name refers to
+// in ast, or an empty string if it is not an exception. It works before the
+// symbols are resolved, so undefined names are left for the resolver to report.
+func exceptionOf(ast *parser.Thrift, name string, visited map[*parser.Typedef]bool) string {
+	if _, ok := ast.GetException(name); ok {
+		return name
+	}
+	if td, ok := ast.GetTypedef(name); ok {
+		if visited == nil {
+			visited = make(map[*parser.Typedef]bool)
+		}
+		if visited[td] {
+			return ""
+		}
+		visited[td] = true
+		return exceptionOf(ast, td.Type.Name, visited)
+	}
+	if tmp := SplitType(name); len(tmp) == 2 {
+		for _, inc := range ast.Includes {
+			if inc.Reference != nil && inc.RefName() == tmp[0] {
+				if exc := exceptionOf(inc.Reference, tmp[1], visited); exc != "" {
+					return tmp[0] + "." + exc
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// CheckUnions checks the semantics of union nodes. All fields of a union are
+// optional, so a required field is an error. At most one field can have a default
+// value, which sets the union to that field by default.
 func (c *checker) CheckUnions(t *parser.Thrift) (warns []string, err error) {
 	for _, u := range t.Unions {
-		var hasDefault bool
+		var withDefault string
 		for _, f := range u.Fields {
 			if f.Requiredness == parser.FieldType_Required {
-				msg := fmt.Sprintf(
-					"union %s field %s: union members must be optional, ignoring specified requiredness.",
-					u.Name, f.Name)
-				warns = append(warns, msg)
+				err = fmt.Errorf("%s: field %q in union %q is required, but the fields of a union are always optional",
+					location(t, f.Position), f.Name, u.Name)
+				return warns, err
 			}
 
 			if f.GetDefault() != nil {
-				if hasDefault {
-					err = fmt.Errorf("field %s provides another default value for union %s", f.Name, u.Name)
+				if withDefault != "" {
+					err = fmt.Errorf("%s: fields %q and %q in union %q both have default values, but only one field of a union can be set",
+						location(t, f.Position), withDefault, f.Name, u.Name)
 					return warns, err
 				}
+				withDefault = f.Name
+				warns = append(warns, fmt.Sprintf("%s: field %q in union %q has a default value, which makes the union set to it by default",
+					location(t, f.Position), f.Name, u.Name))
 			}
 
 			if c.FixWarnings {
@@ -184,17 +447,23 @@ func (c *checker) CheckFunctions(t *parser.Thrift) (warns []string, err error) {
 		defined := make(map[string]bool)
 		for _, f := range svc.Functions {
 			if defined[f.Name] {
-				err = fmt.Errorf("duplicated function name in %q: %q", svc.Name, f.Name)
+				err = fmt.Errorf("%s: duplicated function name in %q: %q", location(t, f.Position), svc.Name, f.Name)
 				return
 			}
 			defined[f.Name] = true
 
 			if f.Oneway && !f.Void {
-				err = fmt.Errorf("%s.%s: oneway function must be void type", svc.Name, f.Name)
+				err = fmt.Errorf("%s: %s.%s: oneway function must be void type, but returns %s",
+					location(t, f.Position), svc.Name, f.Name, f.FunctionType.Name)
 				return
 			}
 			if f.Oneway && len(f.Throws) > 0 {
-				err = fmt.Errorf("%s.%s: oneway methods can't throw exceptions", svc.Name, f.Name)
+				var names []string
+				for _, x := range f.Throws {
+					names = append(names, x.Type.Name)
+				}
+				err = fmt.Errorf("%s: %s.%s: oneway methods can't throw exceptions, but throws %s",
+					location(t, f.Position), svc.Name, f.Name, strings.Join(names, ", "))
 				return
 			}
 			for _, a := range f.Arguments {
@@ -205,15 +474,15 @@ func (c *checker) CheckFunctions(t *parser.Thrift) (warns []string, err error) {
 					}
 				}
 				if a.ID <= 0 {
-					warns = append(warns, fmt.Sprintf("non-positive ID %d of argument %q in %q.%q",
-						a.ID, a.Name, svc.Name, f.Name))
+					warns = append(warns, fmt.Sprintf("%s: non-positive ID %d of argument %q in %q.%q",
+						location(t, a.Position), a.ID, a.Name, svc.Name, f.Name))
 				}
 			}
 			for _, a := range f.Throws {
 				switch a.Requiredness {
 				case parser.FieldType_Required:
-					warns = append(warns, fmt.Sprintf("exception %q in %q.%q: throw field must be optional, ignoring specified requiredness.",
-						a.Name, svc.Name, f.Name))
+					warns = append(warns, fmt.Sprintf("%s: exception %q in %q.%q: throw field must be optional, ignoring specified requiredness.",
+						location(t, a.Position), a.Name, svc.Name, f.Name))
 					if !c.FixWarnings {
 						continue
 					}
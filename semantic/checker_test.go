@@ -0,0 +1,193 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic_test
+
+import (
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+func TestFieldIDGaps(t *testing.T) {
+	ast, err := parser.ParseString("a.thrift", `
+struct Dense { 1: i32 a; 2: i32 b }
+struct Sparse { 3: i32 a; 4: i32 b; 6: i32 c; 10: i32 d }
+exception Late { 2: string msg }
+`)
+	test.Assert(t, err == nil, err)
+
+	warns, err := semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 0, warns)
+
+	warns, err = semantic.NewChecker(semantic.Options{WarnFieldIDGaps: true}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 2, warns)
+	test.Assert(t, warns[0] == `a.thrift:3:1: field IDs of struct "Sparse" are not contiguous, missing 1-2, 5, 7-9; the next available ID is 1`, warns[0])
+	test.Assert(t, warns[1] == `a.thrift:4:1: field IDs of exception "Late" are not contiguous, missing 1; the next available ID is 1`, warns[1])
+}
+
+func TestImplicitFieldIDs(t *testing.T) {
+	ast, err := parser.ParseString("a.thrift", `
+struct Explicit { 1: i32 a; 2: i32 b }
+struct Legacy {
+	i32 a
+	5: i32 b
+	string c
+}
+service S { void f(i32 x) }
+`)
+	test.Assert(t, err == nil, err)
+
+	warns, err := semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 0, warns)
+
+	warns, err = semantic.NewChecker(semantic.Options{ImplicitFieldIDs: semantic.CheckWarn}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 2, warns)
+	test.Assert(t, warns[0] == `a.thrift:4:2: field "a" in struct "Legacy" has no explicit ID, it is assigned 1`, warns[0])
+	test.Assert(t, warns[1] == `a.thrift:6:2: field "c" in struct "Legacy" has no explicit ID, it is assigned 6`, warns[1])
+
+	_, err = semantic.NewChecker(semantic.Options{ImplicitFieldIDs: semantic.CheckError}).CheckAll(ast)
+	test.Assert(t, err != nil && err.Error() == warns[0], err)
+}
+
+func TestReservedFields(t *testing.T) {
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+		return err
+	}
+	test.Assert(t, check(`struct S { reserved 2, "b"; 1: i32 a; 3: i32 c }`) == nil)
+
+	err := check(`struct S { reserved 2, "b"; 1: i32 a; 2: i32 c }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:39: field "c" in struct "S" uses reserved ID 2`, err)
+
+	err = check(`union U { reserved "b"; 1: i32 a; 2: i32 b }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:35: field 2 in union "U" uses reserved name "b"`, err)
+}
+
+func TestUnionFields(t *testing.T) {
+	check := func(src string) ([]string, error) {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		return semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	}
+	warns, err := check(`union U { 1: i32 a; 2: optional string b }`)
+	test.Assert(t, err == nil && len(warns) == 0, warns, err)
+
+	_, err = check(`union U { 1: i32 a; 2: required string b }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:21: field "b" in union "U" is required, but the fields of a union are always optional`, err)
+
+	warns, err = check(`union U { 1: i32 a = 1; 2: string b }`)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 1 && warns[0] == `a.thrift:1:11: field "a" in union "U" has a default value, which makes the union set to it by default`, warns)
+
+	_, err = check(`union U { 1: i32 a = 1; 2: string b = "b" }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:25: fields "a" and "b" in union "U" both have default values, but only one field of a union can be set`, err)
+}
+
+func TestOnewayFunctions(t *testing.T) {
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+		return err
+	}
+	test.Assert(t, check(`service S { oneway void f(1: i32 x) }`) == nil)
+
+	err := check(`service S { oneway list<i32> f() }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:13: S.f: oneway function must be void type, but returns list`, err)
+
+	err = check(`exception E {} exception F {} service S { oneway void f() throws (1: E e, 2: F f) }`)
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:43: S.f: oneway methods can't throw exceptions, but throws E, F`, err)
+}
+
+func TestExceptionFields(t *testing.T) {
+	ast, err := parser.ParseBatchString("main.thrift", map[string]string{
+		"main.thrift": `
+include "base.thrift"
+exception Local {}
+typedef base.Alias Alias
+struct S { 1: Local l; 2: Alias a; 3: list<Local> ls; 4: i32 ok }
+union U { 1: base.Err e }
+exception E { 1: Local cause }
+service Svc { void f() throws (1: Local l) }`,
+		"base.thrift": `
+exception Err {}
+typedef Err Alias`,
+	}, nil)
+	test.Assert(t, err == nil, err)
+
+	warns, err := semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 3, warns)
+	test.Assert(t, warns[0] == `main.thrift:5:12: field "l" in struct "S" has the exception type "Local", exceptions should only be used in throws clauses`, warns[0])
+	test.Assert(t, warns[1] == `main.thrift:5:24: field "a" in struct "S" has type "Alias", which refers to the exception "base.Err", exceptions should only be used in throws clauses`, warns[1])
+	test.Assert(t, warns[2] == `main.thrift:6:11: field "e" in union "U" has the exception type "base.Err", exceptions should only be used in throws clauses`, warns[2])
+
+	_, err = semantic.NewChecker(semantic.Options{StrictExceptionFields: true}).CheckAll(ast)
+	test.Assert(t, err != nil && err.Error() == warns[0], err)
+}
+
+func TestDuplicatedNames(t *testing.T) {
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+		return err
+	}
+	err := check("struct S {}\n  typedef i32 S")
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:1: duplicated names in global scope: S`, err)
+
+	err = check("struct S {}\n  service S {}")
+	test.Assert(t, err != nil && err.Error() == `a.thrift:2:3: duplicated names in global scope: S`, err)
+
+	err = check("enum E {\n  A = 1\n  B = 1\n}")
+	test.Assert(t, err != nil && err.Error() == `a.thrift:3:3: enum E: duplicate value 1 between 'A' and 'B'`, err)
+}
+
+func TestSenums(t *testing.T) {
+	parse := func(src string) *parser.Thrift {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		return ast
+	}
+	src := "senum Color { \"red\", \"green\" }\nstruct S { 1: Color c = Color_red }"
+
+	_, err := semantic.NewChecker(semantic.Options{}).CheckAll(parse(src))
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:1: senum "Color" is deprecated, rewrite it as an enum or as string constants, or translate it with --translate-senums`, err)
+
+	ast := parse(src)
+	warns, err := semantic.NewChecker(semantic.Options{TranslateSenums: true}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(warns) == 1 && warns[0] == `a.thrift:1:1: senum "Color" is deprecated, translated into a string typedef and 2 constant(s)`, warns)
+	test.Assert(t, len(ast.Senums) == 0, ast.Senums)
+	test.Assert(t, len(ast.Typedefs) == 1 && ast.Typedefs[0].Alias == "Color" && ast.Typedefs[0].Type.Name == "string", ast.Typedefs)
+	test.Assert(t, len(ast.Constants) == 2, ast.Constants)
+	test.Assert(t, ast.Constants[1].Name == "Color_green" && ast.Constants[1].Type.Name == "Color", ast.Constants[1])
+	test.Assert(t, ast.Constants[1].Value.TypedValue.GetLiteral() == "green", ast.Constants[1].Value)
+	test.Assert(t, semantic.ResolveSymbols(ast) == nil)
+
+	_, err = semantic.NewChecker(semantic.Options{TranslateSenums: true}).CheckAll(parse(`senum Color { "dark red" }`))
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:1: value "dark red" of senum "Color" can not be translated into a constant name`, err)
+
+	_, err = semantic.NewChecker(semantic.Options{TranslateSenums: true}).CheckAll(parse("senum Color { \"red\" }\nconst string Color_red = \"x\""))
+	test.Assert(t, err != nil && err.Error() == `a.thrift:1:1: duplicated names in global scope: Color_red`, err)
+}
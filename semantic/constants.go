@@ -0,0 +1,122 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// CheckConstValues reports duplicate keys in the map values and duplicate
+// elements in the set values of constants and default values, which would
+// be dropped silently or break the generated codes.
+func (r *resolver) CheckConstValues() error {
+	for _, v := range r.ast.Constants {
+		if err := r.checkDuplicates(r.ast, v.Type, v.Value); err != nil {
+			return fmt.Errorf("%s: value of constant %q: %w", location(r.ast, v.Position), v.Name, err)
+		}
+	}
+	for _, s := range r.ast.GetStructLikes() {
+		for _, f := range s.Fields {
+			if !f.IsSetDefault() {
+				continue
+			}
+			if err := r.checkDuplicates(r.ast, f.Type, f.Default); err != nil {
+				return fmt.Errorf("%s: default value of %q of %q: %w", location(r.ast, f.Position), f.Name, s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDuplicates checks the value v of type t, which is defined in ast.
+func (r *resolver) checkDuplicates(ast *parser.Thrift, t *parser.Type, v *parser.ConstValue) error {
+	ast, t, err := Deref(ast, t)
+	if err != nil {
+		return err
+	}
+	switch {
+	case v.Type == parser.ConstType_ConstMap && t.Category == parser.Category_Map:
+		seen := make(map[string]bool)
+		for _, kv := range v.TypedValue.Map {
+			k := r.constKey(kv.Key)
+			if seen[k] {
+				return fmt.Errorf("duplicate key %s in map", valueString(kv.Key))
+			}
+			seen[k] = true
+			if err := r.checkDuplicates(ast, t.KeyType, kv.Key); err != nil {
+				return err
+			}
+			if err := r.checkDuplicates(ast, t.ValueType, kv.Value); err != nil {
+				return err
+			}
+		}
+	case v.Type == parser.ConstType_ConstList && (t.Category == parser.Category_List || t.Category == parser.Category_Set):
+		seen := make(map[string]bool)
+		for _, e := range v.TypedValue.List {
+			if t.Category == parser.Category_Set {
+				k := r.constKey(e)
+				if seen[k] {
+					return fmt.Errorf("duplicate element %s in set", valueString(e))
+				}
+				seen[k] = true
+			}
+			if err := r.checkDuplicates(ast, t.ValueType, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// constKey returns a string that is equal for the values equal in the generated codes.
+// Identifiers referring to integers, e.g. enum values, are compared by their values.
+func (r *resolver) constKey(v *parser.ConstValue) string {
+	switch v.Type {
+	case parser.ConstType_ConstInt:
+		return strconv.FormatInt(v.TypedValue.GetInt(), 10)
+	case parser.ConstType_ConstDouble:
+		return strconv.FormatFloat(v.TypedValue.GetDouble(), 'g', -1, 64)
+	case parser.ConstType_ConstLiteral:
+		return strconv.Quote(v.TypedValue.GetLiteral())
+	case parser.ConstType_ConstIdentifier:
+		if i, err := r.evalConstInt(v, make(map[*parser.Constant]bool)); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		if x := v.Extra; x != nil && !x.IsEnum {
+			ref := r.ast
+			if x.Index >= 0 {
+				ref = r.ast.Includes[x.Index].Reference
+			}
+			if c, ok := ref.GetConstant(x.Name); ok && c.Value.Type == parser.ConstType_ConstLiteral {
+				return strconv.Quote(c.Value.TypedValue.GetLiteral())
+			}
+		}
+	}
+	return v.String()
+}
+
+// valueString returns the value of a scalar as it is written in the IDL.
+func valueString(v *parser.ConstValue) string {
+	switch v.Type {
+	case parser.ConstType_ConstLiteral:
+		return strconv.Quote(v.TypedValue.GetLiteral())
+	case parser.ConstType_ConstDouble:
+		return strconv.FormatFloat(v.TypedValue.GetDouble(), 'g', -1, 64)
+	}
+	return exprString(v)
+}
@@ -124,11 +124,17 @@ func (r *resolver) ResolveAST() (err error) {
 	r.RegisterNames()
 
 	r.ast.ForEachTypedef(func(v *parser.Typedef) bool {
-		return guard(r.ResolveType(v.Type))
+		if err := r.ResolveType(v.Type); err != nil {
+			panic(fmt.Errorf("%s: resolve typedef %q: %w", location(r.ast, v.Position), v.Alias, err))
+		}
+		return true
 	})
 
 	r.ast.ForEachConstant(func(v *parser.Constant) bool {
-		return guard(r.ResolveType(v.Type)) && guard(r.ResolveConstValue(v.Value))
+		if err := r.ResolveType(v.Type); err != nil {
+			panic(fmt.Errorf("%s: resolve type of constant %q: %w", location(r.ast, v.Position), v.Name, err))
+		}
+		return guard(r.ResolveConstValue(v.Value))
 	})
 
 	r.ast.ForEachStructLike(func(v *parser.StructLike) bool {
@@ -154,6 +160,12 @@ func (r *resolver) ResolveAST() (err error) {
 	})
 
 	guard(r.ResolveTypedefs())
+	guard(r.CheckConstValues())
+
+	r.ast.ForEachService(func(v *parser.Service) bool {
+		_, err := AllFunctions(r.ast, v)
+		return guard(err)
+	})
 	return
 }
 
@@ -163,10 +175,10 @@ func (r *resolver) ResolveBaseService(v *parser.Service) error {
 		if c, exist := r.ast.Name2Category[tmp[0]]; exist && c == parser.Category_Service {
 			break
 		}
-		return fmt.Errorf("base service %q not found for %q", v.Extends, v.Name)
+		return fmt.Errorf("%s: base service %q not found for %q", location(r.ast, v.Position), v.Extends, v.Name)
 	case 2:
 		for idx, inc := range r.ast.Includes {
-			if IDLPrefix(inc.Path) == tmp[0] {
+			if inc.RefName() == tmp[0] {
 				if c, exist := inc.Reference.Name2Category[tmp[1]]; exist && c == parser.Category_Service {
 					v.Reference = &parser.Reference{
 						Name:  tmp[1],
@@ -178,7 +190,7 @@ func (r *resolver) ResolveBaseService(v *parser.Service) error {
 			}
 		}
 		if v.Reference == nil {
-			return fmt.Errorf("base service %q not found for %q", v.Extends, v.Name)
+			return fmt.Errorf("%s: base service %q not found for %q", location(r.ast, v.Position), v.Extends, v.Name)
 		}
 	}
 	return nil
@@ -186,11 +198,11 @@ func (r *resolver) ResolveBaseService(v *parser.Service) error {
 
 func (r *resolver) ResolveStructField(s string, f *parser.Field) (err error) {
 	if err = r.ResolveType(f.Type); err != nil {
-		return fmt.Errorf("resolve field %q of %q: %w", f.Name, s, err)
+		return fmt.Errorf("%s: resolve field %q of %q: %w", location(r.ast, f.Position), f.Name, s, err)
 	}
 	if f.IsSetDefault() {
 		if err = r.ResolveConstValue(f.Default); err != nil {
-			return fmt.Errorf("resolve default value of %q of %q: %w", f.Name, s, err)
+			return fmt.Errorf("%s: resolve default value of %q of %q: %w", location(r.ast, f.Position), f.Name, s, err)
 		}
 	}
 	return
@@ -199,17 +211,17 @@ func (r *resolver) ResolveStructField(s string, f *parser.Field) (err error) {
 func (r *resolver) ResolveFunction(s string, f *parser.Function) (err error) {
 	if !f.Void {
 		if err = r.ResolveType(f.FunctionType); err != nil {
-			return fmt.Errorf("function %q of service %q: %w", f.Name, s, err)
+			return fmt.Errorf("%s: resolve response of function %q of service %q: %w", location(r.ast, f.Position), f.Name, s, err)
 		}
 	}
 	for _, v := range f.Arguments {
 		if err := r.ResolveType(v.Type); err != nil {
-			return fmt.Errorf("resolve argument %q of %q of %q: %w", v.Name, f.Name, s, err)
+			return fmt.Errorf("%s: resolve argument %q of function %q of service %q: %w", location(r.ast, v.Position), v.Name, f.Name, s, err)
 		}
 	}
 	for _, v := range f.Throws {
 		if err := r.ResolveType(v.Type); err != nil {
-			return fmt.Errorf("resolve exception %q of %q of %q: %w", v.Name, f.Name, s, err)
+			return fmt.Errorf("%s: resolve exception %q of function %q of service %q: %w", location(r.ast, v.Position), v.Name, f.Name, s, err)
 		}
 	}
 	return
@@ -263,35 +275,44 @@ func (r *resolver) ResolveType(t *parser.Type) (err error) {
 				}
 				return fmt.Errorf("unexpected type category '%s' of type '%s'", c, t.Name)
 			}
-			return fmt.Errorf("undefined type: %q", t.Name)
+			return r.undefinedType(t.Name)
 		case 2: // an external type
+			found := -1
 			for i, inc := range r.ast.Includes {
-				if IDLPrefix(inc.Path) != tmp[0] {
+				if inc.RefName() != tmp[0] {
 					continue
 				}
 				if c, exist := inc.Reference.Name2Category[tmp[1]]; exist {
 					if c >= parser.Category_Enum && c <= parser.Category_Typedef {
-						if c == parser.Category_Typedef {
-							r.typedefs = append(r.typedefs, &typedefPair{
-								Type: t,
-								AST:  inc.Reference,
-								Name: tmp[1],
-							})
-							t.IsTypedef = &yes
-						}
-						t.Category = c
-						t.Reference = &parser.Reference{
-							Name:  tmp[1],
-							Index: int32(i),
+						if found < 0 {
+							found = i
+						} else if prev := r.ast.Includes[found]; prev.Reference != inc.Reference {
+							// includes of files with the same name and no alias
+							return fmt.Errorf("ambiguous type: %q is defined in both %q and %q, give one of the includes an alias",
+								t.Name, prev.Path, inc.Path)
 						}
-						r.ast.Includes[i].Used = &yes
-						break
 					}
 				}
 			}
-			if t.Reference == nil {
-				return fmt.Errorf("undefined type: %q", t.Name)
+			if found < 0 {
+				return r.undefinedType(t.Name)
 			}
+			inc := r.ast.Includes[found]
+			c := inc.Reference.Name2Category[tmp[1]]
+			if c == parser.Category_Typedef {
+				r.typedefs = append(r.typedefs, &typedefPair{
+					Type: t,
+					AST:  inc.Reference,
+					Name: tmp[1],
+				})
+				t.IsTypedef = &yes
+			}
+			t.Category = c
+			t.Reference = &parser.Reference{
+				Name:  tmp[1],
+				Index: int32(found),
+			}
+			inc.Used = &yes
 		default:
 			return fmt.Errorf("invalid type name %q", t.Name)
 		}
@@ -300,36 +321,32 @@ func (r *resolver) ResolveType(t *parser.Type) (err error) {
 }
 
 // getEnum searches in the given AST and its includes an enum definition that matches the
-// given name. If the name refers to a typedef, then its original type will be considered.
-// The return value contains the target enum definition and the index of the **first**
-// included IDL or -1 if the enum is defined in the given AST.
-// When such an enum is not found, getEnum returns (nil, -1).
-func getEnum(ast *parser.Thrift, name string) (enum *parser.Enum, includeIndex int32) {
+// given name. If the name refers to a typedef, the chain of typedefs is followed to the
+// original type, which may be defined in an IDL included indirectly.
+// When such an enum is not found, getEnum returns nil.
+func getEnum(ast *parser.Thrift, name string) *parser.Enum {
 	c, exist := ast.Name2Category[name]
 	if !exist {
-		return nil, -1
+		return nil
 	}
 	if c == parser.Category_Enum {
 		x, ok := ast.GetEnum(name)
 		if !ok {
 			panic(fmt.Errorf("expect %q to be an enum in %q, not found", name, ast.Filename))
 		}
-		return x, -1
+		return x
 	}
 	if c == parser.Category_Typedef {
 		if x, ok := ast.GetTypedef(name); !ok {
 			panic(fmt.Errorf("expect %q to be an typedef in %q, not found", name, ast.Filename))
 		} else {
 			if r := x.Type.Reference; r != nil {
-				e, _ := getEnum(ast.Includes[r.Index].Reference, r.Name)
-				if e != nil {
-					return e, r.Index
-				}
+				return getEnum(ast.Includes[r.Index].Reference, r.Name)
 			}
 			return getEnum(ast, x.Type.Name)
 		}
 	}
-	return nil, -1
+	return nil
 }
 
 func (r *resolver) ResolveConstValue(t *parser.ConstValue) (err error) {
@@ -341,6 +358,7 @@ func (r *resolver) ResolveConstValue(t *parser.ConstValue) (err error) {
 		}
 		sss := SplitValue(id)
 		var ref []*parser.ConstValueExtra
+		var missing error // the selector is an enum without the value
 		for _, ss := range sss {
 			switch len(ss) {
 			case 1: // constant
@@ -353,20 +371,23 @@ func (r *resolver) ResolveConstValue(t *parser.ConstValue) (err error) {
 				}
 				continue
 			case 2: // enum.value or someinclude.constant
-				// TODO: if enum.value is written in typedef.value?
-
-				// enum.value
-				if enum, idx := getEnum(r.ast, ss[0]); enum != nil {
+				// enum.value or typedef.value, the Sel names a local enum or typedef
+				if enum := getEnum(r.ast, ss[0]); enum != nil {
+					found := false
 					for _, v := range enum.Values {
 						if v.Name == ss[1] {
 							ref = append(ref, &parser.ConstValueExtra{
-								IsEnum: true, Index: idx, Name: ss[1], Sel: ss[0],
+								IsEnum: true, Index: -1, Name: ss[1], Sel: ss[0],
 							})
+							found = true
 						}
 					}
+					if !found {
+						missing = undefinedEnumValue(id, enum, ss[1])
+					}
 				}
 				for idx, inc := range r.ast.Includes {
-					if IDLPrefix(inc.Path) != ss[0] {
+					if inc.RefName() != ss[0] {
 						continue
 					}
 					if c, exist := inc.Reference.Name2Category[ss[1]]; exist {
@@ -380,30 +401,53 @@ func (r *resolver) ResolveConstValue(t *parser.ConstValue) (err error) {
 				}
 			case 3: // someinclude.enum.value
 				for idx, inc := range r.ast.Includes {
-					if IDLPrefix(inc.Path) != ss[0] {
+					if inc.RefName() != ss[0] {
 						continue
 					}
-					if enum, _ := getEnum(inc.Reference, ss[1]); enum != nil {
+					if enum := getEnum(inc.Reference, ss[1]); enum != nil {
+						found := false
 						for _, v := range enum.Values {
 							if v.Name == ss[2] {
 								ref = append(ref, &parser.ConstValueExtra{
 									IsEnum: true, Index: int32(idx), Name: ss[2], Sel: ss[1],
 								})
 								r.ast.Includes[idx].Used = &yes
+								found = true
 							}
 						}
+						if !found {
+							missing = undefinedEnumValue(id, enum, ss[2])
+						}
 					}
 				}
 			}
 		}
 		switch len(ref) {
 		case 0:
+			if missing != nil {
+				return missing
+			}
 			return fmt.Errorf("undefined value: %q", t.TypedValue.GetIdentifier())
 		case 1:
 			t.Extra = ref[0]
 		default:
 			return fmt.Errorf("ambiguous const value %q (%d possible explainations)", t.TypedValue.GetIdentifier(), len(ref))
 		}
+	case parser.ConstType_ConstExpr:
+		// the folded value does not refer to the includes used by the expression
+		used := make([]*bool, len(r.ast.Includes))
+		for i, inc := range r.ast.Includes {
+			used[i] = inc.Used
+		}
+		v, err := r.evalConstInt(t, make(map[*parser.Constant]bool))
+		if err != nil {
+			return fmt.Errorf("const expression %q: %w", exprString(t), err)
+		}
+		for i, inc := range r.ast.Includes {
+			inc.Used = used[i]
+		}
+		t.Type = parser.ConstType_ConstInt
+		t.TypedValue = &parser.ConstTypedValue{Int: &v}
 	case parser.ConstType_ConstList:
 		for _, v := range t.TypedValue.List {
 			if err = r.ResolveConstValue(v); err != nil {
@@ -511,3 +555,88 @@ func Deref(ast *parser.Thrift, t *parser.Type) (*parser.Thrift, *parser.Type, er
 		return nil, nil, fmt.Errorf("(%+v) unexpected category for %q: %v", t, ast.Filename, cat)
 	}
 }
+
+// evalConstInt evaluates an integer in a constant expression. The visiting set
+// holds the constants being evaluated to detect circular references.
+func (r *resolver) evalConstInt(v *parser.ConstValue, visiting map[*parser.Constant]bool) (int64, error) {
+	switch v.Type {
+	case parser.ConstType_ConstInt:
+		return v.TypedValue.GetInt(), nil
+	case parser.ConstType_ConstExpr:
+		expr := v.TypedValue.GetExpr()
+		lhs, err := r.evalConstInt(expr.LHS, visiting)
+		if err != nil {
+			return 0, err
+		}
+		rhs, err := r.evalConstInt(expr.RHS, visiting)
+		if err != nil {
+			return 0, err
+		}
+		return expr.Eval(lhs, rhs)
+	case parser.ConstType_ConstIdentifier:
+		if v.Extra == nil {
+			if err := r.ResolveConstValue(v); err != nil {
+				return 0, err
+			}
+		}
+		id := v.TypedValue.GetIdentifier()
+		x := v.Extra
+		if x == nil { // true or false
+			return 0, fmt.Errorf("%q is not an integer", id)
+		}
+		if x.IsEnum {
+			return r.enumValue(x)
+		}
+		ref := r
+		if x.Index >= 0 {
+			ref = &resolver{ast: r.ast.Includes[x.Index].Reference}
+		}
+		c, ok := ref.ast.GetConstant(x.Name)
+		if !ok {
+			return 0, fmt.Errorf("undefined value: %q", id)
+		}
+		switch c.Value.Type {
+		case parser.ConstType_ConstInt, parser.ConstType_ConstIdentifier, parser.ConstType_ConstExpr:
+		default:
+			return 0, fmt.Errorf("%q is not an integer", id)
+		}
+		if visiting[c] {
+			return 0, fmt.Errorf("circular reference to %q", id)
+		}
+		visiting[c] = true
+		defer delete(visiting, c)
+		return ref.evalConstInt(c.Value, visiting)
+	}
+	return 0, fmt.Errorf("%s is not an integer", v)
+}
+
+func (r *resolver) enumValue(x *parser.ConstValueExtra) (int64, error) {
+	var enum *parser.Enum
+	if x.Index >= 0 {
+		enum = getEnum(r.ast.Includes[x.Index].Reference, x.Sel)
+	}
+	if enum == nil {
+		enum = getEnum(r.ast, x.Sel)
+	}
+	if enum != nil {
+		for _, v := range enum.Values {
+			if v.Name == x.Name {
+				return v.Value, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("undefined value: %q", x.Sel+"."+x.Name)
+}
+
+func exprString(v *parser.ConstValue) string {
+	switch v.Type {
+	case parser.ConstType_ConstInt:
+		return fmt.Sprint(v.TypedValue.GetInt())
+	case parser.ConstType_ConstIdentifier:
+		return v.TypedValue.GetIdentifier()
+	case parser.ConstType_ConstExpr:
+		expr := v.TypedValue.GetExpr()
+		return exprString(expr.LHS) + " " + expr.Op + " " + exprString(expr.RHS)
+	}
+	return v.String()
+}
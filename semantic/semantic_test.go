@@ -0,0 +1,267 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+func TestConstExpr(t *testing.T) {
+	ast, err := parser.ParseString("a.thrift", `
+enum Flags { READ = 1, WRITE = 2, EXEC = 4 }
+const i32 A = LATER + 1
+const i32 LATER = 0x10
+const i64 MASK = 0xff & 0x0f | 0x30
+const i32 NEG = A - -2
+const Flags RX = Flags.READ | Flags.EXEC
+const list<i32> L = [A | 1]
+struct S { 1: i32 f = A & 0x3 }
+`)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, semantic.ResolveSymbols(ast) == nil)
+
+	expected := []int64{17, 16, 0x3f, 19, 5}
+	for i, c := range ast.Constants[:len(expected)] {
+		test.Assert(t, c.Value.Type == parser.ConstType_ConstInt, c.Name, c.Value)
+		test.Assert(t, c.Value.TypedValue.GetInt() == expected[i], c.Name, c.Value)
+	}
+	test.Assert(t, ast.Constants[5].Value.TypedValue.List[0].TypedValue.GetInt() == 17)
+	test.Assert(t, ast.Structs[0].Fields[0].Default.TypedValue.GetInt() == 1)
+
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		return semantic.ResolveSymbols(ast)
+	}
+	errs := map[string]string{
+		`const string S = "s"
+		 const i32 C = S + 1`: `const expression "S + 1": "S" is not an integer`,
+		`const i32 C = true | 1`: `const expression "true | 1": "true" is not an integer`,
+		`const i32 A = B + 1
+		 const i32 B = A`: `const expression "B + 1": circular reference to "B"`,
+		`const i64 C = 0x7fffffffffffffff + 1`: `const expression "9223372036854775807 + 1": integer overflow: 9223372036854775807 + 1`,
+		`const i32 C = X | 1`:                  `const expression "X | 1": undefined value: "X"`,
+	}
+	for src, msg := range errs {
+		err := check(src)
+		test.Assert(t, err != nil && err.Error() == msg, src, err)
+	}
+}
+
+func TestUndefinedType(t *testing.T) {
+	base, err := parser.ParseString("base.thrift", `
+struct Location { 1: string city }
+enum Level { LOW, HIGH }
+`)
+	test.Assert(t, err == nil, err)
+
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		ast.Includes = append(ast.Includes, &parser.Include{Path: "base.thrift", Reference: base})
+		return semantic.ResolveSymbols(ast)
+	}
+	errs := map[string]string{
+		"struct User { 1: string name }\nstruct S {\n  1: map<string, list<Usr>> users\n}": `a.thrift:3:3: resolve field "users" of "S": undefined type: "Usr", did you mean "User"?`,
+		"struct User {}\nservice Svc {\n  void add(1: i32 id, 2: set<Usr> users)\n}":       `a.thrift:3:23: resolve argument "users" of function "add" of service "Svc": undefined type: "Usr", did you mean "User"?`,
+		"struct S { 1: list<map<base.Locaton, i32>> m }":                                   `a.thrift:1:12: resolve field "m" of "S": undefined type: "base.Locaton", did you mean "base.Location"?`,
+		"struct S { 1: bsae.Level l }":                                                     `a.thrift:1:12: resolve field "l" of "S": undefined type: "bsae.Level", did you mean "base.Level"?`,
+		"struct Item {}\nstruct Iten {}\ntypedef list<Itex> Items":                         `a.thrift:3:1: resolve typedef "Items": undefined type: "Itex", did you mean "Item" or "Iten"?`,
+		"const Missing M = 1":        `a.thrift:1:1: resolve type of constant "M": undefined type: "Missing"`,
+		"struct S { 1: Location l }": `a.thrift:1:12: resolve field "l" of "S": undefined type: "Location", it is defined in an include, did you mean "base.Location"?`,
+	}
+	for src, msg := range errs {
+		err := check(src)
+		test.Assert(t, err != nil && err.Error() == msg, src, err)
+	}
+}
+
+func TestAmbiguousTypes(t *testing.T) {
+	idls := map[string]string{
+		"user/common.thrift":  "enum Status { ACTIVE }",
+		"order/common.thrift": "enum Status { PAID }\nstruct Order {}",
+	}
+	resolve := func(src string) (*parser.Thrift, error) {
+		idls["main.thrift"] = src
+		ast, err := parser.ParseBatchString("main.thrift", idls, nil)
+		test.Assert(t, err == nil, err)
+		return ast, semantic.ResolveSymbols(ast)
+	}
+
+	// without aliases, both includes are referred to as "common"
+	_, err := resolve("include \"user/common.thrift\"\ninclude \"order/common.thrift\"\nstruct S { 1: common.Status s }")
+	test.Assert(t, err != nil && strings.HasSuffix(err.Error(),
+		`ambiguous type: "common.Status" is defined in both "user/common.thrift" and "order/common.thrift", give one of the includes an alias`), err)
+	ast, err := resolve("include \"user/common.thrift\"\ninclude \"order/common.thrift\"\nstruct S { 1: common.Order o }")
+	test.Assert(t, err == nil && ast.Structs[0].Fields[0].Type.Reference.Index == 1, err)
+
+	src := "include \"user/common.thrift\" as user\ninclude \"order/common.thrift\" as order\n"
+	ast, err = resolve(src + "struct S { 1: user.Status u 2: order.Status o }")
+	test.Assert(t, err == nil, err)
+	fs := ast.Structs[0].Fields
+	test.Assert(t, fs[0].Type.Reference.Index == 0 && fs[1].Type.Reference.Index == 1, fs)
+
+	_, err = resolve(src + "struct S { 1: Status s }")
+	test.Assert(t, err != nil && err.Error() == `main.thrift:3:12: resolve field "s" of "S": undefined type: "Status", `+
+		`it is defined in more than one include, qualify it as "user.Status" or "order.Status"`, err)
+}
+
+func TestDuplicateConstValues(t *testing.T) {
+	check := func(src string) error {
+		ast, err := parser.ParseString("a.thrift", src)
+		test.Assert(t, err == nil, err)
+		return semantic.ResolveSymbols(ast)
+	}
+	test.Assert(t, check(`
+enum Color { RED = 1, GREEN = 2 }
+typedef set<Color> Colors
+const map<i8, byte> M = {1: 1, 2: 1}
+const list<i32> L = [1, 1]
+const Colors S = [Color.RED, Color.GREEN]
+struct T { 1: map<string, set<i32>> m = {"a": [1, 2], "b": [1, 2]} }
+`) == nil)
+
+	errs := map[string]string{
+		`const map<string, i32> M = {"a": 1, "b": 2, "a": 3}`:                    `a.thrift:1:1: value of constant "M": duplicate key "a" in map`,
+		"enum Color { RED = 1 }\nconst map<Color, i32> M = {Color.RED: 1, 1: 2}": `a.thrift:2:1: value of constant "M": duplicate key 1 in map`,
+		"const i32 ONE = 1\ntypedef set<i64> Set\nconst Set S = [ONE, 2, 1]":     `a.thrift:3:1: value of constant "S": duplicate element 1 in set`,
+		"struct T {\n  1: map<i32, set<string>> m = {1: [\"x\", \"x\"]}\n}":      `a.thrift:2:3: default value of "m" of "T": duplicate element "x" in set`,
+		"enum Color { RED = 1, GREEN = 2 }\nconst Color C = Color.GREN":          `undefined value: "Color.GREN", enum "Color" has no value "GREN", did you mean "GREEN"?`,
+		"enum Color { RED = 1 }\nconst list<Color> L = [Color.BLUE]":             `undefined value: "Color.BLUE", enum "Color" has no value "BLUE"`,
+	}
+	for src, msg := range errs {
+		err := check(src)
+		test.Assert(t, err != nil && err.Error() == msg, src, err)
+	}
+}
+
+func TestIncludeAlias(t *testing.T) {
+	idls := map[string]string{
+		"main.thrift": `
+include "common/base.thrift" as b
+include "other.thrift"
+struct S {
+	1: b.Location loc
+	2: b.Level level = b.Level.HIGH
+	3: i32 max = b.MAX
+	4: other.Location other
+}
+service Svc extends b.BaseSvc {}`,
+		"common/base.thrift": `
+struct Location { 1: string city }
+enum Level { LOW, HIGH }
+const i32 MAX = 10
+service BaseSvc {}`,
+		"other.thrift": `
+struct Location {}`,
+	}
+	ast, err := parser.ParseBatchString("main.thrift", idls, nil)
+	test.Assert(t, err == nil, err)
+	_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, semantic.ResolveSymbols(ast) == nil)
+
+	fs := ast.Structs[0].Fields
+	test.Assert(t, fs[0].Type.Reference.Index == 0 && fs[0].Type.Reference.Name == "Location", fs[0].Type.Reference)
+	test.Assert(t, fs[1].Default.Extra.Index == 0 && fs[1].Default.Extra.Name == "HIGH", fs[1].Default.Extra)
+	test.Assert(t, fs[2].Default.Extra.Index == 0 && fs[2].Default.Extra.Name == "MAX", fs[2].Default.Extra)
+	test.Assert(t, fs[3].Type.Reference.Index == 1, fs[3].Type.Reference)
+	test.Assert(t, ast.Services[0].Reference.Index == 0, ast.Services[0].Reference)
+	ref, ok := ast.GetReference("b")
+	test.Assert(t, ok && ref.Filename == "common/base.thrift", ref)
+
+	// the file name no longer refers to an aliased include
+	idls["main.thrift"] = `
+include "common/base.thrift" as b
+struct S { 1: base.Location loc }`
+	ast, err = parser.ParseBatchString("main.thrift", idls, nil)
+	test.Assert(t, err == nil, err)
+	err = semantic.ResolveSymbols(ast)
+	test.Assert(t, err != nil && strings.Contains(err.Error(), `undefined type: "base.Location"`), err)
+
+	errs := map[string]string{
+		"include \"common/base.thrift\" as S\nstruct S {}":                  `main.thrift:1:1: alias "S" of include "common/base.thrift" shadows the definition with the same name`,
+		"include \"other.thrift\"\ninclude \"common/base.thrift\" as other": `main.thrift:2:1: includes "other.thrift" and "common/base.thrift" are both referred to as "other"`,
+	}
+	for src, msg := range errs {
+		idls["main.thrift"] = src
+		ast, err = parser.ParseBatchString("main.thrift", idls, nil)
+		test.Assert(t, err == nil, err)
+		_, err = semantic.NewChecker(semantic.Options{}).CheckAll(ast)
+		test.Assert(t, err != nil && err.Error() == msg, src, err)
+	}
+}
+
+func TestInheritTypedefAnnotations(t *testing.T) {
+	idls := map[string]string{
+		"base.thrift": `
+typedef string Email (vt.pattern = "^.+@.+$", go.tag = 'json:"email"')
+typedef i64 ID (vt.gt = "0")`,
+		"main.thrift": `
+include "base.thrift"
+typedef base.Email WorkEmail (vt.max_size = "64", vt.pattern = "@acme.com$")
+struct User {
+	1: base.ID id
+	2: WorkEmail email (vt.pattern = "^admin@")
+	3: list<WorkEmail> others
+	4: string name (vt.min_size = "1")
+}
+service Svc { void add(1: WorkEmail email) throws (1: Error err) }
+exception Error { 1: base.Email contact }`,
+	}
+	ast, err := parser.ParseBatchString("main.thrift", idls, nil)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, semantic.ResolveSymbols(ast) == nil)
+
+	fs := ast.Structs[0].Fields
+	annos, err := semantic.TypedefAnnotations(ast, fs[1].Type)
+	test.Assert(t, err == nil, err)
+	test.Assert(t, len(annos) == 3, annos)
+	test.Assert(t, annos.Get("vt.pattern")[0] == "@acme.com$", annos)
+	test.Assert(t, annos.Get("go.tag")[0] == `json:"email"`, annos)
+
+	test.Assert(t, semantic.InheritTypedefAnnotations(ast) == nil)
+	expected := []map[string]string{
+		{"vt.gt": "0"},
+		{"vt.pattern": "^admin@", "vt.max_size": "64", "go.tag": `json:"email"`},
+		{},
+		{"vt.min_size": "1"},
+	}
+	for i, f := range fs {
+		test.Assert(t, len(f.Annotations) == len(expected[i]), f.Name, f.Annotations)
+		for k, v := range expected[i] {
+			vs := f.Annotations.Get(k)
+			test.Assert(t, len(vs) == 1 && vs[0] == v, f.Name, k, vs)
+		}
+	}
+	// the field annotations come first
+	test.Assert(t, fs[1].Annotations[0].Key == "vt.pattern", fs[1].Annotations)
+
+	fn := ast.Services[0].Functions[0]
+	test.Assert(t, len(fn.Arguments[0].Annotations) == 3, fn.Arguments[0].Annotations)
+	test.Assert(t, len(ast.Exceptions[0].Fields[0].Annotations) == 2, ast.Exceptions[0].Fields[0].Annotations)
+	test.Assert(t, len(fn.Throws[0].Annotations) == 0, fn.Throws[0].Annotations)
+
+	// the typedefs are left unchanged and merging again changes nothing
+	td, _ := ast.GetTypedef("WorkEmail")
+	test.Assert(t, len(td.Annotations) == 2, td.Annotations)
+	test.Assert(t, semantic.InheritTypedefAnnotations(ast) == nil)
+	test.Assert(t, len(fs[1].Annotations) == 3, fs[1].Annotations)
+}
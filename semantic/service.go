@@ -0,0 +1,143 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// BaseService returns the service that svc extends and the AST it belongs to.
+// The returned service is nil if svc extends nothing.
+// The AST must be resolved by ResolveSymbols.
+func BaseService(ast *parser.Thrift, svc *parser.Service) (*parser.Thrift, *parser.Service, error) {
+	if svc.Extends == "" {
+		return nil, nil, nil
+	}
+	base, name := ast, svc.Extends
+	if ref := svc.GetReference(); ref != nil {
+		if ref.Index < 0 || ref.Index >= int32(len(ast.Includes)) {
+			return nil, nil, fmt.Errorf("invalid ref.Index of base service %q for %q: %d", svc.Extends, svc.Name, ref.Index)
+		}
+		base, name = ast.Includes[ref.Index].Reference, ref.Name
+	}
+	if s, ok := base.GetService(name); ok {
+		return base, s, nil
+	}
+	return nil, nil, fmt.Errorf("base service %q not found for %q", svc.Extends, svc.Name)
+}
+
+// AllFunctions returns the complete function set of svc, including the ones inherited
+// through 'extends' transitively across includes. The functions are ordered from the
+// root base service to svc. A function overrides an inherited function with the same
+// name, which is an error if their signatures are incompatible.
+// The AST must be resolved by ResolveSymbols.
+func AllFunctions(ast *parser.Thrift, svc *parser.Service) ([]*parser.Function, error) {
+	type node struct {
+		ast *parser.Thrift
+		svc *parser.Service
+	}
+	var chain []node
+	visited := make(map[*parser.Service]bool)
+	for cur, t := svc, ast; cur != nil; {
+		if visited[cur] {
+			var path []string
+			for _, n := range chain {
+				path = append(path, n.svc.Name)
+			}
+			return nil, fmt.Errorf("found extends circle: %s -> %s", strings.Join(path, " -> "), cur.Name)
+		}
+		visited[cur] = true
+		chain = append(chain, node{t, cur})
+
+		base, next, err := BaseService(t, cur)
+		if err != nil {
+			return nil, err
+		}
+		cur, t = next, base
+	}
+
+	var funcs []*parser.Function
+	owners := make(map[string]node)
+	index := make(map[string]int)
+	for i := len(chain) - 1; i >= 0; i-- {
+		n := chain[i]
+		for _, f := range n.svc.Functions {
+			idx, exist := index[f.Name]
+			if !exist {
+				index[f.Name] = len(funcs)
+				owners[f.Name] = n
+				funcs = append(funcs, f)
+				continue
+			}
+			o := owners[f.Name]
+			if err := compatible(o.ast, funcs[idx], n.ast, f); err != nil {
+				return nil, fmt.Errorf("%q.%q overrides %q.%q with an incompatible signature: %w",
+					n.svc.Name, f.Name, o.svc.Name, f.Name, err)
+			}
+			owners[f.Name] = n
+			funcs[idx] = f
+		}
+	}
+	return funcs, nil
+}
+
+// compatible checks whether function g can override function f.
+func compatible(fa *parser.Thrift, f *parser.Function, ga *parser.Thrift, g *parser.Function) error {
+	if f.Oneway != g.Oneway {
+		return fmt.Errorf("oneway mismatch")
+	}
+	if f.Void != g.Void {
+		return fmt.Errorf("void mismatch")
+	}
+	if !f.Void {
+		if x, y := typeID(fa, f.FunctionType), typeID(ga, g.FunctionType); x != y {
+			return fmt.Errorf("response type mismatch: %s vs %s", x, y)
+		}
+	}
+	if len(f.Arguments) != len(g.Arguments) {
+		return fmt.Errorf("argument count mismatch: %d vs %d", len(f.Arguments), len(g.Arguments))
+	}
+	for i, a := range f.Arguments {
+		b := g.Arguments[i]
+		if a.ID != b.ID {
+			return fmt.Errorf("argument %d ID mismatch: %d vs %d", i, a.ID, b.ID)
+		}
+		if x, y := typeID(fa, a.Type), typeID(ga, b.Type); x != y {
+			return fmt.Errorf("argument %q type mismatch: %s vs %s", b.Name, x, y)
+		}
+	}
+	return nil
+}
+
+// typeID returns a string that identifies a type regardless of where it is referenced.
+func typeID(ast *parser.Thrift, t *parser.Type) string {
+	ast, t, err := Deref(ast, t)
+	if err != nil {
+		return err.Error()
+	}
+	switch t.Category {
+	case parser.Category_Map:
+		return fmt.Sprintf("map<%s,%s>", typeID(ast, t.KeyType), typeID(ast, t.ValueType))
+	case parser.Category_List, parser.Category_Set:
+		return fmt.Sprintf("%s<%s>", t.Name, typeID(ast, t.ValueType))
+	case parser.Category_Enum, parser.Category_Struct, parser.Category_Union, parser.Category_Exception:
+		return ast.Filename + ":" + t.Name
+	default:
+		return t.Category.String()
+	}
+}
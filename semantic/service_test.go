@@ -0,0 +1,97 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/pkg/test"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+func parseAndResolve(t *testing.T, main string, others ...string) (*parser.Thrift, error) {
+	idls := map[string]string{"main.thrift": main}
+	for i := 0; i+1 < len(others); i += 2 {
+		idls[others[i]] = others[i+1]
+	}
+	ast, err := parser.ParseBatchString("main.thrift", idls, nil)
+	test.Assert(t, err == nil, err)
+	return ast, semantic.ResolveSymbols(ast)
+}
+
+const baseIDL = `
+struct Req {}
+typedef Req ReqAlias
+service Root {
+	void Hello()
+}
+service Base extends Root {
+	string Ping(1: Req req)
+	oneway void Fire()
+}`
+
+func TestAllFunctions(t *testing.T) {
+	ast, err := parseAndResolve(t, `
+include "base.thrift"
+typedef base.Req Req
+service Foo extends base.Base {
+	string Ping(1: Req request)
+	void Do()
+}`, "base.thrift", baseIDL)
+	test.Assert(t, err == nil, err)
+
+	svc, _ := ast.GetService("Foo")
+	funcs, err := semantic.AllFunctions(ast, svc)
+	test.Assert(t, err == nil, err)
+	var names []string
+	for _, f := range funcs {
+		names = append(names, f.Name)
+	}
+	test.Assert(t, strings.Join(names, ",") == "Hello,Ping,Fire,Do", names)
+	test.Assert(t, funcs[1] == svc.Functions[0])
+
+	_, base, err := semantic.BaseService(ast, svc)
+	test.Assert(t, err == nil && base.Name == "Base", err)
+}
+
+func TestIncompatibleOverride(t *testing.T) {
+	for _, method := range []string{
+		"i32 Ping(1: base.Req req)",
+		"string Ping(2: base.Req req)",
+		"string Ping(1: base.Req req, 2: i32 x)",
+		"oneway void Fire(1: i32 x)",
+		"void Fire()",
+	} {
+		_, err := parseAndResolve(t, `
+include "base.thrift"
+service Foo extends base.Base {
+	`+method+`
+}`, "base.thrift", baseIDL)
+		test.Assert(t, err != nil && strings.Contains(err.Error(), "incompatible signature"), method, err)
+	}
+}
+
+func TestExtendsCircle(t *testing.T) {
+	_, err := parseAndResolve(t, `
+service A extends C {}
+service B extends A {}
+service C extends B {}`)
+	test.Assert(t, err != nil && strings.Contains(err.Error(), "extends circle"), err)
+
+	_, err = parseAndResolve(t, `service A extends A {}`)
+	test.Assert(t, err != nil && strings.Contains(err.Error(), "extends circle"), err)
+}
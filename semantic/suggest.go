@@ -0,0 +1,154 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semantic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// maxSuggestions limits the number of candidates listed in an error.
+const maxSuggestions = 3
+
+// undefinedType creates the error for a type name that can not be resolved.
+// It suggests the type names in the current AST and its includes that are
+// the closest to the given name.
+//
+// An unqualified name defined in the includes is not resolved, and the error
+// lists the qualified names instead.
+func (r *resolver) undefinedType(name string) error {
+	if !strings.Contains(name, ".") {
+		var qualified []string
+		seen := make(map[string]bool)
+		for _, inc := range r.ast.Includes {
+			n := inc.RefName() + "." + name
+			if inc.Reference == nil || seen[n] {
+				continue
+			}
+			if c, ok := inc.Reference.Name2Category[name]; ok && c >= parser.Category_Enum && c <= parser.Category_Typedef {
+				seen[n] = true
+				qualified = append(qualified, fmt.Sprintf("%q", n))
+			}
+		}
+		switch len(qualified) {
+		case 0:
+		case 1:
+			return fmt.Errorf("undefined type: %q, it is defined in an include, did you mean %s?", name, qualified[0])
+		default:
+			return fmt.Errorf("undefined type: %q, it is defined in more than one include, qualify it as %s",
+				name, strings.Join(qualified, " or "))
+		}
+	}
+	var names []string
+	collect := func(prefix string, ast *parser.Thrift) {
+		for n, c := range ast.Name2Category {
+			if c >= parser.Category_Enum && c <= parser.Category_Typedef {
+				names = append(names, prefix+n)
+			}
+		}
+	}
+	collect("", r.ast)
+	for _, inc := range r.ast.Includes {
+		if inc.Reference != nil {
+			collect(inc.RefName()+".", inc.Reference)
+		}
+	}
+	if ss := suggest(name, names); len(ss) > 0 {
+		return fmt.Errorf("undefined type: %q, did you mean %s?", name, strings.Join(ss, " or "))
+	}
+	return fmt.Errorf("undefined type: %q", name)
+}
+
+// undefinedEnumValue creates the error for a value that refers to a missing
+// member of an enum. It suggests the closest members of the enum.
+func undefinedEnumValue(id string, enum *parser.Enum, name string) error {
+	var names []string
+	for _, v := range enum.Values {
+		names = append(names, v.Name)
+	}
+	if ss := suggest(name, names); len(ss) > 0 {
+		return fmt.Errorf("undefined value: %q, enum %q has no value %q, did you mean %s?", id, enum.Name, name, strings.Join(ss, " or "))
+	}
+	return fmt.Errorf("undefined value: %q, enum %q has no value %q", id, enum.Name, name)
+}
+
+// location returns the file name of the IDL that pos comes from and the line
+// and column of pos if it is known.
+func location(ast *parser.Thrift, pos *parser.Position) string {
+	return ast.Locate(pos)
+}
+
+// suggest returns the quoted candidates that have the smallest edit distance
+// to name. Candidates that differ in more than a third of name are ignored.
+func suggest(name string, candidates []string) (res []string) {
+	limit := len(name) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	for _, c := range candidates {
+		d := editDistance(name, c)
+		if d == 0 || d > limit {
+			continue
+		}
+		if d < limit {
+			limit, res = d, res[:0]
+		}
+		res = append(res, c)
+	}
+	sort.Strings(res)
+	if len(res) > maxSuggestions {
+		res = res[:maxSuggestions]
+	}
+	for i := range res {
+		res[i] = fmt.Sprintf("%q", res[i])
+	}
+	return res
+}
+
+// editDistance calculates the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	row := make([]int, len(t)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur := row[j]
+			row[j] = min3(row[j]+1, row[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return row[len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,30 @@
+namespace go apache
+
+enum Kind { A = 1, B = 2 }
+
+struct Item {
+    1: required i64 id
+    2: optional string name
+    3: list<Kind> kinds
+    4: map<string, Item> children
+    5: optional binary data
+}
+
+union Choice {
+    1: i32 n
+    2: Item item
+}
+
+exception NotFound {
+    1: string key
+}
+
+service Base {
+    string Ping(1: string msg)
+}
+
+service Store extends Base {
+    Item Get(1: i64 id) throws (1: NotFound nf)
+    void Put(1: Item item, 2: Choice c)
+    oneway void Touch(1: i64 id)
+}
@@ -0,0 +1,5 @@
+module github.com/cloudwego/thriftgo/test/golang/apache_runtime
+
+go 1.20
+
+require github.com/apache/thrift v0.14.0
@@ -0,0 +1,129 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apache_runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/apache_runtime/gen-go/apache"
+)
+
+// loopback is a thrift.TClient that hands the requests to a processor.
+type loopback struct {
+	p thrift.TProcessor
+}
+
+func (l *loopback) Call(ctx context.Context, method string, args, result thrift.TStruct) (thrift.ResponseMeta, error) {
+	in, out := thrift.NewTMemoryBuffer(), thrift.NewTMemoryBuffer()
+	iprot := thrift.NewTBinaryProtocolTransport(in)
+	if err := iprot.WriteMessageBegin(ctx, method, thrift.CALL, 1); err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	if err := args.Write(ctx, iprot); err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	if err := iprot.WriteMessageEnd(ctx); err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	oprot := thrift.NewTBinaryProtocolTransport(out)
+	// Like the servers of apache thrift, go on with the reply when the
+	// processor fails after it has written an exception.
+	if ok, err := l.p.Process(ctx, iprot, oprot); !ok {
+		return thrift.ResponseMeta{}, err
+	}
+	if result == nil {
+		return thrift.ResponseMeta{}, nil
+	}
+	_, typeID, _, err := oprot.ReadMessageBegin(ctx)
+	if err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	if typeID == thrift.EXCEPTION {
+		x := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "")
+		if err := x.Read(ctx, oprot); err != nil {
+			return thrift.ResponseMeta{}, err
+		}
+		return thrift.ResponseMeta{}, x
+	}
+	if err := result.Read(ctx, oprot); err != nil {
+		return thrift.ResponseMeta{}, err
+	}
+	return thrift.ResponseMeta{}, oprot.ReadMessageEnd(ctx)
+}
+
+type store struct {
+	items   map[int64]*apache.Item
+	touched []int64
+}
+
+func (s *store) Ping(ctx context.Context, msg string) (string, error) {
+	if msg == "fail" {
+		return "", errors.New("ping failed")
+	}
+	return "pong: " + msg, nil
+}
+
+func (s *store) Get(ctx context.Context, id int64) (*apache.Item, error) {
+	if item, ok := s.items[id]; ok {
+		return item, nil
+	}
+	return nil, &apache.NotFound{Key: "missing"}
+}
+
+func (s *store) Put(ctx context.Context, item *apache.Item, c *apache.Choice) error {
+	s.items[item.ID] = item
+	return nil
+}
+
+func (s *store) Touch(ctx context.Context, id int64) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	s := &store{items: map[int64]*apache.Item{}}
+	c := apache.NewStoreClient(&loopback{p: apache.NewStoreProcessor(s)})
+
+	if r, err := c.Ping(ctx, "hi"); err != nil || r != "pong: hi" {
+		t.Fatalf("Ping: %q, %v", r, err)
+	}
+	var x thrift.TApplicationException
+	if _, err := c.Ping(ctx, "fail"); !errors.As(err, &x) || x.TypeId() != thrift.INTERNAL_ERROR {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	name := "a"
+	item := &apache.Item{ID: 1, Name: &name, Kinds: []apache.Kind{apache.Kind_A, apache.Kind_B}}
+	if err := c.Put(ctx, item, &apache.Choice{Item: item}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, err := c.Get(ctx, 1)
+	if err != nil || got.GetName() != "a" || len(got.Kinds) != 2 {
+		t.Fatalf("Get: %+v, %v", got, err)
+	}
+	var nf *apache.NotFound
+	if _, err := c.Get(ctx, 2); !errors.As(err, &nf) || nf.Key != "missing" {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if err := c.Touch(ctx, 3); err != nil || len(s.touched) != 1 || s.touched[0] != 3 {
+		t.Fatalf("Touch: %v, %v", s.touched, err)
+	}
+}
@@ -0,0 +1,31 @@
+#! /bin/bash
+
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+set -e
+if [ -d gen-go ]; then
+    rm -rf gen-go
+fi
+mkdir -p gen-go
+thriftgo -r -g "go:package_prefix=github.com/cloudwego/thriftgo/test/golang/apache_runtime/gen-go,runtime=apache" -o gen-go a.thrift
+
+# runtime=apache supports github.com/apache/thrift v0.14.0 and later.
+for v in v0.14.0 v0.15.0 v0.16.0 v0.17.0 v0.18.1 v0.19.0 v0.20.0 v0.21.0 v0.22.0; do
+    echo "github.com/apache/thrift $v"
+    go mod edit -require=github.com/apache/thrift@$v
+    go mod tidy
+    go test -count=1 ./...
+done
+go mod edit -go=1.20 -require=github.com/apache/thrift@v0.14.0
+go mod tidy
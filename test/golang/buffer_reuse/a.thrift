@@ -0,0 +1,29 @@
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#   http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+namespace go reuse
+
+struct Item {
+    1: i64 id
+    2: string name
+    3: list<string> tags
+}
+
+// Order nests items in a list, a map and an optional field.
+struct Order {
+    1: string id
+    2: list<Item> items
+    3: map<string, Item> index
+    4: optional Item main
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer_reuse
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/buffer_reuse/gen-reuse/reuse"
+)
+
+func sampleOrder(n int) *reuse.Order {
+	o := &reuse.Order{ID: "order-" + strconv.Itoa(n), Index: make(map[string]*reuse.Item)}
+	for i := 0; i < 8; i++ {
+		it := &reuse.Item{ID: int64(n*100 + i), Name: "item-" + strconv.Itoa(i), Tags: []string{"a", "b"}}
+		o.Items = append(o.Items, it)
+		o.Index[it.Name] = it
+	}
+	o.Main = o.Items[0]
+	return o
+}
+
+// encode serializes obj the way callers do without gen_buffer_reuse.
+func encode(t testing.TB, obj thrift.TStruct) []byte {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	if err := obj.Write(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decode reads an order, as the maps are not written in a stable order.
+func decode(data []byte) (*reuse.Order, error) {
+	buf := thrift.NewTMemoryBufferLen(len(data))
+	buf.Write(data)
+	o := reuse.NewOrder()
+	return o, o.Read(thrift.NewTBinaryProtocolTransport(buf))
+}
+
+func TestAppendBinary(t *testing.T) {
+	o := sampleOrder(1)
+	prefix := []byte("prefix")
+	got, err := o.AppendBinary(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(got, prefix) || len(got) != len(prefix)+len(encode(t, o)) {
+		t.Fatalf("unexpected result: %v", got)
+	}
+	if d, err := decode(got[len(prefix):]); err != nil || !reflect.DeepEqual(d, o) {
+		t.Fatalf("unexpected result: %+v, %v", d, err)
+	}
+
+	var w bytes.Buffer
+	if _, err = o.WriteTo(&w); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := decode(w.Bytes()); err != nil || !reflect.DeepEqual(d, o) {
+		t.Fatalf("unexpected result: %+v, %v", d, err)
+	}
+}
+
+// An encoder that has grown above the cap of the pool is dropped, and the
+// messages after it are still serialized correctly.
+func TestAppendBinaryLarge(t *testing.T) {
+	large := sampleOrder(1)
+	large.ID = strings.Repeat("x", 1<<20)
+	for _, o := range []*reuse.Order{large, sampleOrder(2), large, sampleOrder(3)} {
+		got, err := o.AppendBinary(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d, err := decode(got); err != nil || !reflect.DeepEqual(d, o) {
+			t.Fatalf("unexpected result: %v", err)
+		}
+	}
+}
+
+// The pooled encoders are never shared by goroutines at the same time.
+func TestAppendBinaryConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			o := sampleOrder(g)
+			var b []byte
+			for i := 0; i < 100; i++ {
+				var err error
+				if b, err = o.AppendBinary(b[:0]); err != nil {
+					t.Error(err)
+					return
+				}
+				if d, err := decode(b); err != nil || !reflect.DeepEqual(d, o) {
+					t.Errorf("goroutine %d: unexpected result", g)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkWriteOrder(b *testing.B) {
+	o := sampleOrder(1)
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encode(b, o)
+		}
+	})
+	b.Run("gen_buffer_reuse", func(b *testing.B) {
+		var out []byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var err error
+			if out, err = o.AppendBinary(out[:0]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
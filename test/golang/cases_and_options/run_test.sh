@@ -45,6 +45,36 @@ features=(\
     frugal_tag \
     unescape_double_quote \
     json_stringer \ 
+    gen_enum_values \
+    gen_service_iface \
+    gen_json_methods \
+    gen_json_methods,json_disallow_unknown_fields \
+    fast_read \
+    gen_write_to \
+    ctx_rw \
+    gen_client_singleton \
+    gen_future_client \
+    gen_getters \
+    gen_optional_accessors \
+    gen_presence=bitset \
+    gen_presence=pointer \
+    gen_oneway_result \
+    gen_split_read \
+    gen_type_registry \
+    gen_field_meta \
+    union_getters \
+    check_union_on_read \
+    check_union_on_write \
+    check_union_allow_zero \
+    fast_skip \
+    gen_byte_size \
+    gen_buffer_reuse,gen_write_to \
+    gen_enum_sql \
+    enum_sql_as_string \
+    gen_index \
+    gen_required_check \
+    required_check_on_write \
+    protocols=binary,compact \
 )
 
 run_cases() {
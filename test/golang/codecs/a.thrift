@@ -0,0 +1,50 @@
+namespace go codecs
+
+enum Color {
+    RED = 1
+    GREEN = 2
+}
+
+struct Item {
+    1: required i64 id
+    2: optional string name
+    3: list<string> tags
+    4: optional i8 level
+}
+
+union Choice {
+    1: i32 num
+    2: string text
+    3: Item item
+}
+
+struct Order {
+    1: required string id
+    2: required Item main
+    3: optional Color color
+    4: list<Item> items
+    5: map<string, Item> index
+    6: optional Choice choice
+    7: set<i32> codes
+    8: binary data
+}
+
+// OrderV2 is a newer version of Order with the fields that Order skips.
+struct OrderV2 {
+    1: required string id
+    2: required Item main
+    3: optional Color color
+    4: list<Item> items
+    5: map<string, Item> index
+    6: optional Choice choice
+    7: set<i32> codes
+    8: binary data
+    9: list<i64> history
+    10: map<string, list<string>> labels
+    11: Item extra
+}
+
+struct Shipment {
+    1: required Order order
+    2: list<Order> parts
+}
@@ -0,0 +1,35 @@
+namespace go strict
+
+enum Color {
+    RED = 1
+    GREEN = 2
+}
+
+struct Item {
+    1: required i64 id
+    2: optional string name
+    3: list<string> tags
+}
+
+union Choice {
+    1: i32 num
+    2: string text
+    3: Item item
+}
+
+struct Order {
+    1: required string id
+    2: required Item main
+    3: optional Color color
+    4: list<Item> items
+    5: map<string, Item> index
+    6: optional Choice choice
+    7: set<i32> codes
+    8: binary data
+}
+
+
+struct Shipment {
+    1: required Order order
+    2: list<Order> parts
+}
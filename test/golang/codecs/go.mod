@@ -0,0 +1,12 @@
+module github.com/cloudwego/thriftgo/test/golang/codecs
+
+go 1.20
+
+replace github.com/apache/thrift => github.com/apache/thrift v0.13.0
+
+require (
+	github.com/apache/thrift v0.13.0
+	github.com/cloudwego/thriftgo v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/cloudwego/thriftgo => ../../..
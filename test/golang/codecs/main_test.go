@@ -0,0 +1,290 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/codecs/gen-codecs/codecs"
+	"github.com/cloudwego/thriftgo/test/golang/codecs/gen-strict/strict"
+)
+
+func sampleOrder() *codecs.Order {
+	name := "first"
+	main := &codecs.Item{ID: 1, Name: &name, Tags: []string{"a", "b"}}
+	other := &codecs.Item{ID: 2, Tags: []string{}}
+	return &codecs.Order{
+		ID:     "order-1",
+		Main:   main,
+		Color:  codecs.ColorPtr(codecs.Color_GREEN),
+		Items:  []*codecs.Item{main, other},
+		Index:  map[string]*codecs.Item{"first": main, "other": other},
+		Choice: &codecs.Choice{Item: other},
+		Codes:  []int32{3, 1, 2},
+		Data:   []byte("data"),
+	}
+}
+
+func encode(t testing.TB, obj thrift.TStruct, factory thrift.TProtocolFactory) []byte {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	if err := obj.Write(factory.GetProtocol(buf)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decode(data []byte, obj thrift.TStruct, factory thrift.TProtocolFactory) error {
+	buf := thrift.NewTMemoryBufferLen(len(data))
+	buf.Write(data)
+	return obj.Read(factory.GetProtocol(buf))
+}
+
+var binary = thrift.NewTBinaryProtocolFactoryDefault()
+
+func TestJSONMethods(t *testing.T) {
+	o := sampleOrder()
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"id":"order-1"`, `"color":"GREEN"`, `"choice":{"item":{"id":2,"tags":[]}}`} {
+		if !strings.Contains(string(data), s) {
+			t.Fatalf("expect %s in %s", s, data)
+		}
+	}
+	var got codecs.Order
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, o) {
+		t.Fatalf("unexpected result: %+v", &got)
+	}
+
+	// optional fields are omitted when they are not set
+	o.Color, o.Choice = nil, nil
+	if data, err = json.Marshal(o); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"color"`) || strings.Contains(string(data), `"choice"`) {
+		t.Fatalf("unexpected optional fields in %s", data)
+	}
+
+	// unknown fields are ignored unless json_disallow_unknown_fields is set
+	if err = json.Unmarshal([]byte(`{"id":"x","main":{"id":1},"unknown":1}`), &got); err != nil || got.ID != "x" {
+		t.Fatalf("unexpected result: %+v, %v", &got, err)
+	}
+	var s strict.Order
+	if err = json.Unmarshal([]byte(`{"id":"x","main":{"id":1},"unknown":1}`), &s); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	o := sampleOrder()
+	var w bytes.Buffer
+	if n, err := o.WriteTo(&w); err != nil || int(n) != len(encode(t, o, binary)) {
+		t.Fatalf("unexpected result: %d, %v", n, err)
+	}
+	got := codecs.NewOrder()
+	if err := decode(w.Bytes(), got, binary); err != nil || !reflect.DeepEqual(got, o) {
+		t.Fatalf("unexpected result: %v, %v", got, err)
+	}
+}
+
+func TestTypeRegistry(t *testing.T) {
+	v, ok := codecs.LookupType("codecs.Order")
+	if o, _ := v.(*codecs.Order); !ok || o == nil {
+		t.Fatalf("unexpected result: %#v, %v", v, ok)
+	}
+	v, ok = codecs.LookupType("codecs.Color")
+	if c, _ := v.(*codecs.Color); !ok || c == nil {
+		t.Fatalf("unexpected result: %#v, %v", v, ok)
+	}
+	if _, ok = codecs.LookupType("Order"); ok {
+		t.Fatal("expect no type without the namespace")
+	}
+}
+
+func TestUnionGetters(t *testing.T) {
+	for _, c := range []struct {
+		union *codecs.Choice
+		id    codecs.ChoiceFieldID
+	}{
+		{codecs.NewChoiceFromNum(0), codecs.ChoiceFieldID_Num},
+		{codecs.NewChoiceFromText("text"), codecs.ChoiceFieldID_Text},
+		{codecs.NewChoiceFromItem(&codecs.Item{ID: 1}), codecs.ChoiceFieldID_Item},
+		{codecs.NewChoice(), 0},
+	} {
+		if id := c.union.GetSetField(); id != c.id {
+			t.Fatalf("%v: GetSetField() = %d, expect %d", c.union, id, c.id)
+		}
+		if c.id == 0 {
+			continue
+		}
+		got := codecs.NewChoice()
+		if err := decode(encode(t, c.union, binary), got, binary); err != nil || got.GetSetField() != c.id {
+			t.Fatalf("unexpected result: %v, %v", got, err)
+		}
+	}
+}
+
+func TestCheckUnionOnRead(t *testing.T) {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	p := thrift.NewTBinaryProtocolTransport(buf)
+	p.WriteStructBegin("Choice")
+	p.WriteFieldBegin("num", thrift.I32, 1)
+	p.WriteI32(1)
+	p.WriteFieldEnd()
+	p.WriteFieldBegin("text", thrift.STRING, 2)
+	p.WriteString("text")
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+
+	err := decode(buf.Bytes(), codecs.NewChoice(), binary)
+	if e, ok := err.(thrift.TProtocolException); !ok || e.TypeId() != thrift.INVALID_DATA {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "at most one field can be set (2 set)") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckUnionAllowZero(t *testing.T) {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	err := codecs.NewChoice().Write(thrift.NewTBinaryProtocolTransport(buf))
+	if err == nil || !strings.Contains(err.Error(), "exactly one field must be set (0 set)") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// check_union_allow_zero writes an empty union and still rejects two fields
+	got := strict.NewChoice()
+	if err = decode(encode(t, strict.NewChoice(), binary), got, binary); err != nil || got.CountSetFieldsChoice() != 0 {
+		t.Fatalf("unexpected result: %v, %v", got, err)
+	}
+	err = (&strict.Choice{Num: thrift.Int32Ptr(1), Text: thrift.StringPtr("text")}).Write(thrift.NewTBinaryProtocolTransport(buf))
+	if err == nil || !strings.Contains(err.Error(), "at most one field can be set (2 set)") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Order skips the fields of OrderV2 that it does not know, with fast_skip for
+// the binary protocol and as before for the others.
+func TestFastSkip(t *testing.T) {
+	o := sampleOrder()
+	v2 := &codecs.OrderV2{
+		ID: o.ID, Main: o.Main, Color: o.Color, Items: o.Items, Index: o.Index,
+		Choice: o.Choice, Codes: o.Codes, Data: o.Data,
+		History: []int64{1, 2, 3},
+		Labels:  map[string][]string{"a": {"x", "y"}, "b": nil},
+		Extra:   &codecs.Item{ID: 3, Tags: []string{"z"}},
+	}
+	for _, factory := range []thrift.TProtocolFactory{binary, thrift.NewTCompactProtocolFactory()} {
+		got := codecs.NewOrder()
+		if err := decode(encode(t, v2, factory), got, factory); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, o) {
+			t.Fatalf("%T: unexpected result: %v", factory, got)
+		}
+	}
+}
+
+func TestBytesLength(t *testing.T) {
+	o := sampleOrder()
+	for _, obj := range []interface {
+		thrift.TStruct
+		BytesLength() int
+	}{o, o.Main, o.Choice, &codecs.Order{}, &codecs.Shipment{Order: o, Parts: []*codecs.Order{o, o}}} {
+		if n, data := obj.BytesLength(), encode(t, obj, binary); n != len(data) {
+			t.Fatalf("%T: BytesLength() = %d, but %d bytes are written", obj, n, len(data))
+		}
+	}
+}
+
+// gen_buffer_reuse shares the pooled encoders between WriteTo and AppendBinary.
+func TestAppendBinary(t *testing.T) {
+	s := &strict.Order{ID: "order-1", Main: &strict.Item{ID: 1}, Color: strict.ColorPtr(strict.Color_RED)}
+	var w bytes.Buffer
+	if _, err := s.WriteTo(&w); err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.AppendBinary([]byte("prefix"))
+	if err != nil || !bytes.Equal(b, append([]byte("prefix"), w.Bytes()...)) {
+		t.Fatalf("unexpected result: %q, %v", b, err)
+	}
+	got := strict.NewOrder()
+	if err = decode(b[len("prefix"):], got, binary); err != nil || got.ID != s.ID || got.GetColor() != strict.Color_RED {
+		t.Fatalf("unexpected result: %v, %v", got, err)
+	}
+}
+
+func TestEnumSQL(t *testing.T) {
+	var c codecs.Color
+	if err := c.Scan(int64(2)); err != nil || c != codecs.Color_GREEN {
+		t.Fatalf("unexpected result: %v, %v", c, err)
+	}
+	if err := c.Scan(nil); err != nil || c != 0 {
+		t.Fatalf("unexpected result: %v, %v", c, err)
+	}
+	if v, err := codecs.Color_RED.Value(); err != nil || v != int64(1) {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+
+	// enum_sql_as_string stores the names
+	var s strict.Color
+	if err := s.Scan([]byte("GREEN")); err != nil || s != strict.Color_GREEN {
+		t.Fatalf("unexpected result: %v, %v", s, err)
+	}
+	if err := s.Scan("BLUE"); err == nil {
+		t.Fatal("expect an error for an unknown name")
+	}
+	if v, err := strict.Color_RED.Value(); err != nil || v != "RED" {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+	if _, err := strict.Color(3).Value(); err == nil {
+		t.Fatal("expect an error for an unknown value")
+	}
+}
+
+func TestRequiredCheck(t *testing.T) {
+	s := &codecs.Shipment{Order: sampleOrder()}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s.Order.Main = nil
+	if err := s.Validate(); err == nil || err.Error() != "order.main: required field is not set" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Order = nil
+	if err := s.Validate(); err == nil || err.Error() != "order: required field is not set" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// required_check_on_write rejects the struct before writing anything
+	buf := thrift.NewTMemoryBufferLen(1024)
+	if err := (&strict.Order{ID: "x"}).Write(thrift.NewTBinaryProtocolTransport(buf)); err == nil || !strings.Contains(err.Error(), "main: required field is not set") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected %d bytes written", buf.Len())
+	}
+}
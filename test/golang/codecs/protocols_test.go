@@ -0,0 +1,90 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	proto "github.com/cloudwego/thriftgo/test/golang/codecs/gen-proto/codecs"
+)
+
+func sampleProtoOrder() *proto.Order {
+	name := "first"
+	main := &proto.Item{ID: 1, Name: &name, Tags: []string{"a", "b"}, Level: thrift.Int8Ptr(-2)}
+	other := &proto.Item{ID: 2, Tags: []string{}}
+	return &proto.Order{
+		ID:     "order-1",
+		Main:   main,
+		Color:  proto.ColorPtr(proto.Color_GREEN),
+		Items:  []*proto.Item{main, other},
+		Index:  map[string]*proto.Item{"first": main, "other": other},
+		Choice: &proto.Choice{Text: thrift.StringPtr("text")},
+		Codes:  []int32{3, -1, 1 << 20},
+		Data:   []byte("data"),
+	}
+}
+
+// protoCodec is a protocol of the protocols option with its reference implementation.
+type protoCodec struct {
+	name    string
+	factory thrift.TProtocolFactory
+	append  func(o *proto.Order, b []byte) ([]byte, error)
+	read    func(o *proto.Order, b []byte) (int, error)
+}
+
+var protoCodecs = []protoCodec{
+	{"binary", binary, (*proto.Order).AppendBinary, (*proto.Order).ReadBinary},
+	{"compact", thrift.NewTCompactProtocolFactory(), (*proto.Order).AppendCompact, (*proto.Order).ReadCompact},
+}
+
+// The generated Append methods write what the reference protocols read.
+func TestProtocolsAppend(t *testing.T) {
+	o := sampleProtoOrder()
+	for _, c := range protoCodecs {
+		b, err := c.append(o, []byte("prefix"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := proto.NewOrder()
+		if err = decode(b[len("prefix"):], got, c.factory); err != nil || !reflect.DeepEqual(got, o) {
+			t.Fatalf("%s: unexpected result: %v, %v", c.name, got, err)
+		}
+	}
+}
+
+// The generated Read methods read what the reference protocols write, and
+// skip the fields they do not know.
+func TestProtocolsRead(t *testing.T) {
+	o := sampleProtoOrder()
+	v2 := &proto.OrderV2{
+		ID: o.ID, Main: o.Main, Color: o.Color, Items: o.Items, Index: o.Index,
+		Choice: o.Choice, Codes: o.Codes, Data: o.Data,
+		History: []int64{1, -2, 1 << 40},
+		Labels:  map[string][]string{"a": {"x", "y"}, "b": {}},
+		Extra:   &proto.Item{ID: 3, Tags: []string{"z"}},
+	}
+	for _, c := range protoCodecs {
+		for _, obj := range []thrift.TStruct{o, v2} {
+			data := encode(t, obj, c.factory)
+			got := proto.NewOrder()
+			if n, err := c.read(got, data); err != nil || n != len(data) || !reflect.DeepEqual(got, o) {
+				t.Fatalf("%s: %T: unexpected result: %v, %d of %d bytes, %v", c.name, obj, got, n, len(data), err)
+			}
+		}
+	}
+}
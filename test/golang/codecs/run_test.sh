@@ -0,0 +1,32 @@
+#! /bin/bash
+
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+generate () {
+    out=gen-$1
+    opt="go:package_prefix=github.com/cloudwego/thriftgo/test/golang/codecs/$out,$2"
+    if [ -d $out ]; then
+        rm -rf $out
+    fi
+    mkdir -p $out
+
+    echo "thriftgo -g $opt -o $out $3"
+    thriftgo -g "$opt" -o $out $3
+}
+
+generate codecs "gen_json_methods,gen_write_to,gen_type_registry,union_getters,check_union_on_write,fast_skip,gen_byte_size,gen_enum_sql,gen_required_check" a.thrift
+generate strict "gen_json_methods,json_disallow_unknown_fields,gen_buffer_reuse,gen_write_to,enum_sql_as_string,required_check_on_write,check_union_allow_zero" b.thrift
+generate proto "protocols=binary,compact" a.thrift
+go mod tidy
+go test -v ./...
@@ -0,0 +1,28 @@
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#   http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+namespace go enum_key
+
+include "base.thrift"
+
+enum Kind {
+    A = 1
+    B = 2
+}
+
+struct Maps {
+    1: map<Kind, string> local
+    2: map<base.Color, list<Kind>> remote
+    3: map<Kind, map<base.Color, i32>> nested = {Kind.A: {base.Color.RED: 1}}
+}
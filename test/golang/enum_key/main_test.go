@@ -0,0 +1,46 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enum_key
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/enum_key/gen-go/base"
+	"github.com/cloudwego/thriftgo/test/golang/enum_key/gen-go/enum_key"
+)
+
+func TestEnumKeys(t *testing.T) {
+	// the keys are typed with the enums, so the literal only compiles if they are
+	exp := &enum_key.Maps{
+		Local:  map[enum_key.Kind]string{enum_key.Kind_A: "a", enum_key.Kind_B: "b"},
+		Remote: map[base.Color][]enum_key.Kind{base.Color_BLUE: {enum_key.Kind_B}},
+		Nested: map[enum_key.Kind]map[base.Color]int32{enum_key.Kind_B: {base.Color_RED: 2}},
+	}
+
+	buf := thrift.NewTMemoryBuffer()
+	if err := exp.Write(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+	got := enum_key.NewMaps()
+	if err := got.Read(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exp, got) {
+		t.Fatalf("expect %+v, got %+v", exp, got)
+	}
+}
@@ -0,0 +1,37 @@
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#   http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+namespace go fast
+
+enum Kind {
+    A = 1
+    B = 2
+}
+
+// Point only has fixed-width scalar fields in ascending ID order.
+struct Point {
+    1: required i32 x
+    2: i64 y
+    3: double z
+    4: bool ok
+    5: byte b
+    6: i16 s
+    7: Kind kind
+}
+
+// Named has a string field so it always uses the generic reader.
+struct Named {
+    1: i32 id
+    2: string name
+}
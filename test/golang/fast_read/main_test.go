@@ -0,0 +1,107 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fast_read
+
+import (
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	fast "github.com/cloudwego/thriftgo/test/golang/fast_read/gen-fast/fast"
+	slow "github.com/cloudwego/thriftgo/test/golang/fast_read/gen-slow/fast"
+)
+
+func samplePoint() *slow.Point {
+	return &slow.Point{X: 1, Y: 2, Z: 3.5, Ok: true, B: 5, S: 6, Kind: slow.Kind_B}
+}
+
+func encode(t testing.TB, obj thrift.TStruct) []byte {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	if err := obj.Write(thrift.NewTBinaryProtocol(buf, true, true)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decode(t testing.TB, data []byte, obj thrift.TStruct) error {
+	buf := thrift.NewTMemoryBufferLen(len(data))
+	buf.Write(data)
+	return obj.Read(thrift.NewTBinaryProtocol(buf, true, true))
+}
+
+func TestFastRead(t *testing.T) {
+	exp := samplePoint()
+	got := fast.NewPoint()
+	if err := decode(t, encode(t, exp), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.X != exp.X || got.Y != exp.Y || got.Z != exp.Z || got.Ok != exp.Ok ||
+		got.B != exp.B || got.S != exp.S || int64(got.Kind) != int64(exp.Kind) {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+}
+
+// An encoder may emit fields in any order, which is handled by the generic reader.
+func TestFastReadOutOfOrder(t *testing.T) {
+	buf := thrift.NewTMemoryBufferLen(1024)
+	p := thrift.NewTBinaryProtocol(buf, true, true)
+	p.WriteStructBegin("Point")
+	p.WriteFieldBegin("y", thrift.I64, 2)
+	p.WriteI64(20)
+	p.WriteFieldEnd()
+	p.WriteFieldBegin("extra", thrift.STRING, 100)
+	p.WriteString("ignored")
+	p.WriteFieldEnd()
+	p.WriteFieldBegin("x", thrift.I32, 1)
+	p.WriteI32(10)
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+
+	got := fast.NewPoint()
+	if err := decode(t, buf.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.X != 10 || got.Y != 20 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+}
+
+func TestFastReadMissingRequired(t *testing.T) {
+	data := encode(t, &slow.Named{Name: "x"})
+	data[2] = 2 // renumber Named.id to 2 so that Point.x is absent
+	if err := decode(t, data, fast.NewPoint()); err == nil {
+		t.Fatal("expect an error for the missing required field")
+	}
+}
+
+func BenchmarkReadPoint(b *testing.B) {
+	data := encode(b, samplePoint())
+	read := func(b *testing.B, obj thrift.TStruct) {
+		buf := thrift.NewTMemoryBufferLen(len(data))
+		p := thrift.NewTBinaryProtocol(buf, true, true)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buf.Reset()
+			buf.Write(data)
+			if err := obj.Read(p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("generic", func(b *testing.B) { read(b, slow.NewPoint()) })
+	b.Run("fast_read", func(b *testing.B) { read(b, fast.NewPoint()) })
+}
@@ -0,0 +1,34 @@
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#   http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+namespace go presence
+
+enum Kind {
+    A = 1
+    B = 2
+}
+
+struct Item {
+    1: optional i64 id
+    2: optional i32 count
+    3: optional i16 small
+    4: optional byte tiny
+    5: optional bool flag
+    6: optional double score
+    7: optional string name
+    8: optional Kind kind
+    9: optional i64 created = 10
+    10: string plain
+    11: optional i32 level (go.json.omitempty = "true")
+}
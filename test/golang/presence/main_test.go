@@ -0,0 +1,180 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package presence
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/presence/gen-bitset/presence"
+	pointer "github.com/cloudwego/thriftgo/test/golang/presence/gen-go/presence"
+)
+
+func roundTrip(t testing.TB, src, dst thrift.TStruct) {
+	buf := thrift.NewTMemoryBuffer()
+	if err := src.Write(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Read(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	p := presence.NewItem()
+	if p.IsSetID() || p.IsSetFlag() || p.IsSetName() {
+		t.Fatal("fields of a new struct must not be set")
+	}
+	// the zero values are set explicitly, so they must be kept
+	p.SetID(0)
+	p.SetFlag(false)
+	p.SetName("")
+	p.SetKind(presence.Kind_B)
+	if !p.IsSetID() || !p.IsSetFlag() || !p.IsSetName() || !p.IsSetKind() {
+		t.Fatal("fields must be set after SetXXX")
+	}
+	if p.IsSetCount() || p.IsSetScore() {
+		t.Fatal("other fields must not be set")
+	}
+
+	got := presence.NewItem()
+	roundTrip(t, p, got)
+	if !reflect.DeepEqual(p, got) {
+		t.Fatalf("expect %+v, got %+v", p, got)
+	}
+
+	p.ClearKind()
+	if p.IsSetKind() || p.Kind != 0 {
+		t.Fatal("field must be unset after ClearXXX")
+	}
+	got = presence.NewItem()
+	roundTrip(t, p, got)
+	if got.IsSetKind() {
+		t.Fatal("unset field must not be written")
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	// both representations encode the same bytes
+	p := presence.NewItem()
+	p.SetCount(3)
+	p.SetScore(0)
+	p.Plain = "plain"
+
+	q := pointer.NewItem()
+	roundTrip(t, p, q)
+	if q.Count == nil || *q.Count != 3 || q.Score == nil || *q.Score != 0 || q.ID != nil {
+		t.Fatalf("unexpected %+v", q)
+	}
+
+	got := presence.NewItem()
+	roundTrip(t, q, got)
+	if !reflect.DeepEqual(p, got) {
+		t.Fatalf("expect %+v, got %+v", p, got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	p := presence.NewItem()
+	p.SetCount(0)
+	p.SetFlag(false)
+	p.SetLevel(0)
+	p.Plain = "plain"
+	q := pointer.NewItem()
+	q.Count, q.Flag, q.Level = thrift.Int32Ptr(0), thrift.BoolPtr(false), thrift.Int32Ptr(0)
+	q.Plain = "plain"
+
+	// the fields that are set are encoded even if omitempty would drop their
+	// zero values, and the others are encoded like nil pointers
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(expect) {
+		t.Fatalf("expect %s, got %s", expect, data)
+	}
+
+	got := presence.NewItem()
+	if err = json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, got) {
+		t.Fatalf("expect %+v, got %+v", p, got)
+	}
+	if got.IsSetID() || got.IsSetName() {
+		t.Fatalf("null fields must not be set: %+v", got)
+	}
+}
+
+func newPointerItem() *pointer.Item {
+	p := pointer.NewItem()
+	p.ID, p.Count, p.Small, p.Tiny = thrift.Int64Ptr(1), thrift.Int32Ptr(2), new(int16), new(int8)
+	p.Flag, p.Score, p.Name = thrift.BoolPtr(true), thrift.Float64Ptr(3), thrift.StringPtr("name")
+	p.Kind = pointer.KindPtr(pointer.Kind_A)
+	return p
+}
+
+func newBitsetItem() *presence.Item {
+	p := presence.NewItem()
+	p.SetID(1)
+	p.SetCount(2)
+	p.SetSmall(0)
+	p.SetTiny(0)
+	p.SetFlag(true)
+	p.SetScore(3)
+	p.SetName("name")
+	p.SetKind(presence.Kind_A)
+	return p
+}
+
+func BenchmarkPointer(b *testing.B) {
+	b.ReportAllocs()
+	b.ReportMetric(float64(unsafe.Sizeof(pointer.Item{})), "struct-bytes")
+	buf := thrift.NewTMemoryBuffer()
+	newPointerItem().Write(thrift.NewTBinaryProtocolTransport(buf))
+	data := buf.Bytes()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.Write(data)
+		p := pointer.NewItem()
+		if err := p.Read(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBitset(b *testing.B) {
+	b.ReportAllocs()
+	b.ReportMetric(float64(unsafe.Sizeof(presence.Item{})), "struct-bytes")
+	buf := thrift.NewTMemoryBuffer()
+	newBitsetItem().Write(thrift.NewTBinaryProtocolTransport(buf))
+	data := buf.Bytes()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.Write(data)
+		p := presence.NewItem()
+		if err := p.Read(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
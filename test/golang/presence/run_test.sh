@@ -0,0 +1,25 @@
+#! /bin/bash
+
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+# http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+for out in gen-go gen-bitset; do
+    if [ -d $out ]; then
+        rm -rf $out
+    fi
+    mkdir -p $out
+done
+thriftgo -r -g "go:package_prefix=github.com/cloudwego/thriftgo/test/golang/presence/gen-go" -o gen-go a.thrift
+thriftgo -r -g "go:package_prefix=github.com/cloudwego/thriftgo/test/golang/presence/gen-bitset,gen_presence=bitset" -o gen-bitset a.thrift
+go mod tidy
+go test -v -bench . -benchmem ./...
@@ -0,0 +1,32 @@
+# Copyright 2024 CloudWeGo Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#   http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+namespace go recursive
+
+struct Node {
+    1: string name
+    2: list<Node> children
+    3: optional Node next
+}
+
+union Expr {
+    1: i64 literal
+    2: Binary binary
+}
+
+struct Binary {
+    1: string op
+    2: Expr left
+    3: Expr right
+}
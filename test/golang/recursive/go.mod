@@ -0,0 +1,7 @@
+module github.com/cloudwego/thriftgo/test/golang/recursive
+
+go 1.20
+
+replace github.com/apache/thrift => github.com/apache/thrift v0.13.0
+
+require github.com/apache/thrift v0.13.0
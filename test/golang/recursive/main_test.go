@@ -0,0 +1,127 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recursive
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/cloudwego/thriftgo/test/golang/recursive/gen-go/recursive"
+)
+
+// depth is deep enough to need a few megabytes of stack in each helper.
+const depth = 10000
+
+// jsonDepth is the depth for encoding/json, which limits the nesting of objects
+// and arrays to 10000 and validates the output of each nested MarshalJSON.
+const jsonDepth = 1000
+
+func roundTrip(t testing.TB, src, dst thrift.TStruct) {
+	buf := thrift.NewTMemoryBuffer()
+	if err := src.Write(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Read(thrift.NewTBinaryProtocolTransport(buf)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// deepTree returns a tree whose nodes have one child down to the depth and are
+// linked to the next node at the same level of another tree.
+func deepTree(depth int) (root, leaf *recursive.Node) {
+	root = recursive.NewNode()
+	root.Name = "0"
+	leaf = root
+	for i := 1; i < depth; i++ {
+		n := recursive.NewNode()
+		n.Name = strconv.Itoa(i)
+		n.Next = &recursive.Node{Name: "next-" + n.Name}
+		leaf.Children = []*recursive.Node{n}
+		leaf = n
+	}
+	return root, leaf
+}
+
+func TestDeepTree(t *testing.T) {
+	root, leaf := deepTree(depth)
+
+	got := recursive.NewNode()
+	roundTrip(t, root, got)
+	if !root.DeepEqual(got) {
+		t.Fatal("the tree must be equal after a round trip")
+	}
+	n := got
+	for i := 1; i < depth; i++ {
+		n = n.Children[0]
+	}
+	if n.Name != leaf.Name || n.Next.Name != leaf.Next.Name || len(n.Children) != 0 {
+		t.Fatalf("expect the leaf %+v, got %+v", leaf, n)
+	}
+
+	n.Name = "changed"
+	if root.DeepEqual(got) {
+		t.Fatal("the trees must differ after changing the leaf")
+	}
+
+	// String prints the nested nodes as pointers, so it does not recurse.
+	if s := root.String(); s == "" {
+		t.Fatal("expect a string")
+	}
+
+	root, _ = deepTree(jsonDepth)
+	bs, err := json.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = recursive.NewNode()
+	if err = json.Unmarshal(bs, got); err != nil {
+		t.Fatal(err)
+	}
+	if !root.DeepEqual(got) {
+		t.Fatal("the tree must be equal after a json round trip")
+	}
+}
+
+func TestDeepExpr(t *testing.T) {
+	expr := &recursive.Expr{Literal: thrift.Int64Ptr(0)}
+	for i := 1; i < depth; i++ {
+		v := int64(i)
+		expr = &recursive.Expr{Binary: &recursive.Binary{
+			Op:    "+",
+			Left:  expr,
+			Right: &recursive.Expr{Literal: &v},
+		}}
+	}
+
+	got := recursive.NewExpr()
+	roundTrip(t, expr, got)
+	if !expr.DeepEqual(got) {
+		t.Fatal("the expression must be equal after a round trip")
+	}
+	var sum int64
+	for e := got; ; e = e.Binary.Left {
+		if e.IsSetLiteral() {
+			sum += e.GetLiteral()
+			break
+		}
+		sum += e.Binary.Right.GetLiteral()
+	}
+	if sum != depth*(depth-1)/2 {
+		t.Fatalf("expect the sum %d, got %d", depth*(depth-1)/2, sum)
+	}
+}
@@ -258,12 +258,22 @@ func (s *ServiceDescriptor) GetParent() *ServiceDescriptor {
 	return GetGlobalDescriptor(s).LookupFD(s.Filepath).GetServiceDescriptor(s.Base)
 }
 
+// GetAllMethods returns the methods of the service and the inherited ones.
+// A method overrides the inherited method with the same name.
 func (s *ServiceDescriptor) GetAllMethods() []*MethodDescriptor {
 	allMethods := []*MethodDescriptor{}
+	names := make(map[string]bool)
+	visited := make(map[*ServiceDescriptor]bool)
 
 	svc := s
-	for svc != nil {
-		allMethods = append(allMethods, svc.GetMethods()...)
+	for svc != nil && !visited[svc] {
+		visited[svc] = true
+		for _, m := range svc.GetMethods() {
+			if !names[m.GetName()] {
+				names[m.GetName()] = true
+				allMethods = append(allMethods, m)
+			}
+		}
 		svc = svc.GetParent()
 	}
 	return allMethods
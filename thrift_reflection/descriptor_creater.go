@@ -15,8 +15,6 @@
 package thrift_reflection
 
 import (
-	"strings"
-
 	"github.com/cloudwego/thriftgo/parser"
 	"github.com/cloudwego/thriftgo/utils"
 )
@@ -55,10 +53,7 @@ func GetFileDescriptor(ast *parser.Thrift) *FileDescriptor {
 
 	includesMap := map[string]string{}
 	for _, inc := range ast.Includes {
-		path := inc.GetReference().Filename
-		arr := strings.Split(path, "/")
-		alias := strings.TrimSuffix(arr[len(arr)-1], ".thrift")
-		includesMap[alias] = path
+		includesMap[inc.RefName()] = inc.GetReference().Filename
 	}
 
 	namespaceMap := map[string]string{}
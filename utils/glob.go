@@ -0,0 +1,58 @@
+// Copyright 2024 CloudWeGo Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MatchGlob reports whether the file path matches the glob pattern. The
+// pattern has the syntax of path.Match, in which '*' does not match '/',
+// and a '**' element matches any number of directories, including none.
+// For example, 'vendor/**' matches all files under the vendor directory
+// and '**/common.thrift' matches common.thrift in any directory.
+func MatchGlob(pattern, name string) (bool, error) {
+	ps := strings.Split(path.Clean(pattern), "/")
+	ns := strings.Split(path.Clean(filepath.ToSlash(name)), "/")
+	for _, p := range ps {
+		if _, err := path.Match(p, ""); err != nil {
+			return false, err
+		}
+	}
+	return matchElems(ps, ns), nil
+}
+
+func matchElems(ps, ns []string) bool {
+	for len(ps) > 0 {
+		if ps[0] == "**" {
+			for i := 0; i <= len(ns); i++ {
+				if matchElems(ps[1:], ns[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(ns) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(ps[0], ns[0]); !ok {
+			return false
+		}
+		ps, ns = ps[1:], ns[1:]
+	}
+	return len(ns) == 0
+}
@@ -120,6 +120,33 @@ func TestParseArr(t *testing.T) {
 	assert(t, arr[3] == "{e,f}")
 }
 
+func TestMatchGlob(t *testing.T) {
+	for _, c := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"vendor/**", "vendor/google/common.thrift", true},
+		{"vendor/**", "vendor/common.thrift", true},
+		{"vendor/**", "idl/vendor/common.thrift", false},
+		{"**/vendor/**", "idl/vendor/common.thrift", true},
+		{"vendor/*", "vendor/google/common.thrift", false},
+		{"vendor/*.thrift", "vendor/common.thrift", true},
+		{"**/common.thrift", "common.thrift", true},
+		{"**/common.thrift", "a/b/common.thrift", true},
+		{"a/**/b/*.thrift", "a/b/x.thrift", true},
+		{"a/**/b/*.thrift", "a/x/y/b/x.thrift", true},
+		{"a/**/b/*.thrift", "a/x/y/c/x.thrift", false},
+		{"./vendor/**", "vendor/x.thrift", true},
+	} {
+		ok, err := MatchGlob(c.pattern, c.name)
+		assert(t, err == nil, err)
+		assert(t, ok == c.match, c.pattern, c.name)
+	}
+
+	_, err := MatchGlob("vendor/[", "vendor/x")
+	assert(t, err != nil)
+}
+
 func assert(t *testing.T, cond bool, val ...interface{}) {
 	t.Helper()
 	if !cond {